@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/containerupdate/agent"
+)
+
+var listen string
+var runtimeEndpoint string
+var crictl string
+var tokenFile string
+
+// The Tilt agent. Runs on each node as a daemonset, and live-updates
+// containers through the CRI socket, for clusters that don't allow pods/exec.
+//
+// See tilt-agent.yaml in this directory for the daemonset. It mounts the CRI
+// socket and the token secret, and sets NODE_NAME from the downward API
+// (spec.nodeName).
+func main() {
+	flag.StringVar(&listen, "listen", fmt.Sprintf("127.0.0.1:%d", agent.Port),
+		"Address to listen on. Tilt connects through a port-forward, so loopback is enough")
+	flag.StringVar(&runtimeEndpoint, "runtime-endpoint", agent.DefaultRuntimeEndpoint, "CRI socket of the container runtime")
+	flag.StringVar(&crictl, "crictl", "crictl", "Path to crictl")
+	flag.StringVar(&tokenFile, "token-file", "/etc/tilt-agent/token", "File with the token that callers must present")
+	flag.Parse()
+
+	err := run()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("reading token: %v", err)
+	}
+
+	s, err := agent.NewGRPCServer(strings.TrimSpace(string(token)))
+	if err != nil {
+		return err
+	}
+
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	agent.RegisterServer(s, agent.NewCRIServer(os.Getenv("NODE_NAME"), runtimeEndpoint, crictl))
+	log.Printf("Tilt agent listening on %s", lis.Addr())
+	return s.Serve(lis)
+}
@@ -132,6 +132,12 @@ func (ta *TiltAnalytics) SetTiltfileOpt(opt analytics.Opt) {
 	ta.opt.tiltfile = opt
 }
 
+// OptOutForSession turns analytics off until Tilt exits, like the
+// environment variables do, without changing the user's saved choice.
+func (ta *TiltAnalytics) OptOutForSession() {
+	ta.opt.env = analytics.OptOut
+}
+
 func (ta *TiltAnalytics) WithoutGlobalTags() analytics.Analytics {
 	return &TiltAnalytics{
 		opter:       ta.opter,
@@ -0,0 +1,71 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Resolves build args that are read from files or command output,
+// and appends them to the args passed to the docker build.
+//
+// Because the resolved values are part of the build args, a change
+// in value invalidates the Docker cache and changes the image digest.
+//
+// Values may be secrets (like an .npmrc with a token), so only the
+// name and source of each arg are logged.
+func resolveDynamicArgs(ctx context.Context, execer localexec.Execer, ps *PipelineState,
+	spec v1alpha1.DockerImageSpec) (v1alpha1.DockerImageSpec, error) {
+	if len(spec.DynamicArgs) == 0 {
+		return spec, nil
+	}
+
+	args := append([]string(nil), spec.Args...)
+	for _, arg := range spec.DynamicArgs {
+		val, err := resolveDynamicArg(ctx, execer, arg)
+		if err != nil {
+			return spec, fmt.Errorf("resolving build arg %s: %v", arg.Name, err)
+		}
+		ps.Printf(ctx, "Resolved build arg %s from %s", arg.Name, dynamicArgSource(arg))
+		args = append(args, fmt.Sprintf("%s=%s", arg.Name, val))
+	}
+	spec.Args = args
+	return spec, nil
+}
+
+func dynamicArgSource(arg v1alpha1.DockerImageDynamicArg) string {
+	if arg.Path != "" {
+		return arg.Path
+	}
+	return fmt.Sprintf("%q", dynamicArgCmd(arg).String())
+}
+
+func dynamicArgCmd(arg v1alpha1.DockerImageDynamicArg) model.Cmd {
+	return model.Cmd{Argv: arg.Command, Dir: arg.Dir}
+}
+
+func resolveDynamicArg(ctx context.Context, execer localexec.Execer, arg v1alpha1.DockerImageDynamicArg) (string, error) {
+	if arg.Path != "" {
+		contents, err := os.ReadFile(arg.Path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(contents), "\r\n"), nil
+	}
+
+	cmd := dynamicArgCmd(arg)
+	result, err := localexec.OneShot(ctx, execer, cmd)
+	if err != nil {
+		return "", fmt.Errorf("running %q: %v", cmd.String(), err)
+	}
+	if result.ExitCode != 0 {
+		return "", fmt.Errorf("running %q: exit status %d: %s",
+			cmd.String(), result.ExitCode, strings.TrimSpace(string(result.Stderr)))
+	}
+	return strings.TrimSpace(string(result.Stdout)), nil
+}
@@ -0,0 +1,80 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestResolveDynamicArgs(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	npmrc := filepath.Join(t.TempDir(), ".npmrc.ci")
+	require.NoError(t, os.WriteFile(npmrc, []byte("registry=example\n"), 0644))
+
+	execer := localexec.NewFakeExecer(t)
+	version := model.ToHostCmd("git describe --tags")
+	execer.RegisterCommand(version.String(), 0, "  v1.2.3  ", "")
+
+	spec := v1alpha1.DockerImageSpec{
+		Args: []string{"STATIC=hello"},
+		DynamicArgs: []v1alpha1.DockerImageDynamicArg{
+			{Name: "NPMRC", Path: npmrc},
+			{Name: "VERSION", Command: version.Argv},
+		},
+	}
+	resolved, err := resolveDynamicArgs(ctx, execer, ps, spec)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"STATIC=hello", "NPMRC=registry=example", "VERSION=v1.2.3"}, resolved.Args)
+	assert.Equal(t, []string{"STATIC=hello"}, spec.Args)
+
+	// Values may be secrets, so only the source is logged.
+	assert.Contains(t, out.String(), "Resolved build arg NPMRC from "+npmrc)
+	assert.Contains(t, out.String(), `Resolved build arg VERSION from "git describe --tags"`)
+	assert.NotContains(t, out.String(), "registry=example")
+	assert.NotContains(t, out.String(), "v1.2.3")
+}
+
+func TestResolveDynamicArgsCmdFailure(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, &bytes.Buffer{}))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	execer := localexec.NewFakeExecer(t)
+	version := model.ToHostCmd("git describe --tags")
+	execer.RegisterCommand(version.String(), 128, "", "fatal: no names found")
+
+	_, err := resolveDynamicArgs(ctx, execer, ps, v1alpha1.DockerImageSpec{
+		DynamicArgs: []v1alpha1.DockerImageDynamicArg{
+			{Name: "VERSION", Command: version.Argv},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resolving build arg VERSION")
+	assert.Contains(t, err.Error(), "exit status 128: fatal: no names found")
+}
+
+func TestResolveDynamicArgsMissingFile(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, &bytes.Buffer{}))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	_, err := resolveDynamicArgs(ctx, localexec.NewFakeExecer(t), ps, v1alpha1.DockerImageSpec{
+		DynamicArgs: []v1alpha1.DockerImageDynamicArg{
+			{Name: "NPMRC", Path: filepath.Join(t.TempDir(), "missing")},
+		},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "resolving build arg NPMRC")
+}
@@ -0,0 +1,119 @@
+package build
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// BuildKit names the steps of a Dockerfile build like "[builder 2/5] RUN make"
+// or, for a Dockerfile with only one stage, "[2/5] RUN make".
+var stepNameRegexp = regexp.MustCompile(`^\[(?:(\S+) )?(\d+/\d+)\] `)
+
+// parseStepName returns the stage and the step number (e.g., "2/5") from a
+// BuildKit vertex name, or empty strings if the vertex isn't a step of a
+// Dockerfile stage (e.g., "exporting to image").
+func parseStepName(name string) (stage string, step string) {
+	match := stepNameRegexp.FindStringSubmatch(name)
+	if match == nil {
+		return "", ""
+	}
+	return match[1], match[2]
+}
+
+// The steps of a build that belong to one Dockerfile stage.
+type BuildStage struct {
+	// The stage name, e.g., "builder" or "stage-1" for an unnamed stage of a
+	// multi-stage Dockerfile.
+	//
+	// Empty for a Dockerfile with one stage, and for steps outside of any
+	// stage, like exporting the image.
+	Name string `json:"name"`
+
+	// When the first step started, and the last step finished.
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+
+	// From StartedAt to FinishedAt, so that stages that ran in parallel
+	// aren't double-counted.
+	DurationMs int64 `json:"durationMs"`
+
+	// Whether every step was cached.
+	Cached bool `json:"cached"`
+
+	// The error of the step that failed, if any.
+	Error string `json:"error,omitempty"`
+
+	Steps []BuildStep `json:"steps"`
+}
+
+// One step of a build, usually a Dockerfile instruction.
+type BuildStep struct {
+	// The step as BuildKit names it, e.g., "[builder 2/5] RUN make".
+	Name string `json:"name"`
+
+	// The step number in its stage, e.g., "2/5". Empty for steps outside of
+	// a stage.
+	Step string `json:"step,omitempty"`
+
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+	Cached     bool       `json:"cached"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// BuildStages groups the statuses of a DockerImage build by Dockerfile
+// stage, in the order the stages started.
+func BuildStages(statuses []v1alpha1.DockerImageStageStatus) []BuildStage {
+	var result []BuildStage
+	index := map[string]int{}
+	for _, status := range statuses {
+		stageName, stepNum := parseStepName(status.Name)
+		step := BuildStep{
+			Name:   status.Name,
+			Step:   stepNum,
+			Cached: status.Cached,
+			Error:  status.Error,
+		}
+		if status.StartedAt != nil {
+			t := status.StartedAt.Time
+			step.StartedAt = &t
+		}
+		if status.FinishedAt != nil {
+			t := status.FinishedAt.Time
+			step.FinishedAt = &t
+		}
+		step.DurationMs = durationMs(step.StartedAt, step.FinishedAt)
+
+		i, ok := index[stageName]
+		if !ok {
+			i = len(result)
+			index[stageName] = i
+			result = append(result, BuildStage{Name: stageName, Cached: true})
+		}
+
+		stage := &result[i]
+		stage.Steps = append(stage.Steps, step)
+		stage.Cached = stage.Cached && step.Cached
+		if step.Error != "" {
+			stage.Error = step.Error
+		}
+		if step.StartedAt != nil && (stage.StartedAt == nil || step.StartedAt.Before(*stage.StartedAt)) {
+			stage.StartedAt = step.StartedAt
+		}
+		if step.FinishedAt != nil && (stage.FinishedAt == nil || step.FinishedAt.After(*stage.FinishedAt)) {
+			stage.FinishedAt = step.FinishedAt
+		}
+		stage.DurationMs = durationMs(stage.StartedAt, stage.FinishedAt)
+	}
+	return result
+}
+
+func durationMs(start, finish *time.Time) int64 {
+	if start == nil || finish == nil {
+		return 0
+	}
+	return finish.Sub(*start).Milliseconds()
+}
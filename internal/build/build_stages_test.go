@@ -0,0 +1,77 @@
+package build
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestParseStepName(t *testing.T) {
+	for _, tc := range []struct {
+		name, stage, step string
+	}{
+		{"[builder 2/5] RUN make", "builder", "2/5"},
+		{"[stage-1 1/3] FROM docker.io/library/busybox", "stage-1", "1/3"},
+		{"[2/4] WORKDIR /usr/src/app", "", "2/4"},
+		{"[internal] load build context", "", ""},
+		{"exporting to image", "", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stage, step := parseStepName(tc.name)
+			assert.Equal(t, tc.stage, stage)
+			assert.Equal(t, tc.step, step)
+		})
+	}
+}
+
+func TestBuildStages(t *testing.T) {
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *metav1.MicroTime {
+		t := metav1.NewMicroTime(t0.Add(d))
+		return &t
+	}
+
+	stages := BuildStages([]v1alpha1.DockerImageStageStatus{
+		{Name: "[builder 1/2] FROM golang", Cached: true, StartedAt: at(0), FinishedAt: at(time.Second)},
+		{Name: "[stage-1 1/2] FROM alpine", Cached: true, StartedAt: at(0), FinishedAt: at(time.Second)},
+		{Name: "[builder 2/2] RUN go build", StartedAt: at(time.Second), FinishedAt: at(5 * time.Second)},
+		{Name: "[stage-1 2/2] COPY --from=builder /app /app", Cached: true, StartedAt: at(5 * time.Second), FinishedAt: at(6 * time.Second)},
+		{Name: "exporting to image", StartedAt: at(6 * time.Second)},
+	})
+
+	require.Len(t, stages, 3)
+
+	assert.Equal(t, "builder", stages[0].Name)
+	assert.Equal(t, t0, *stages[0].StartedAt)
+	assert.Equal(t, int64(5000), stages[0].DurationMs)
+	assert.False(t, stages[0].Cached)
+	require.Len(t, stages[0].Steps, 2)
+	assert.Equal(t, "2/2", stages[0].Steps[1].Step)
+	assert.Equal(t, int64(4000), stages[0].Steps[1].DurationMs)
+
+	assert.Equal(t, "stage-1", stages[1].Name)
+	assert.True(t, stages[1].Cached)
+	assert.Equal(t, int64(6000), stages[1].DurationMs)
+
+	// Not finished yet.
+	assert.Equal(t, "", stages[2].Name)
+	assert.Nil(t, stages[2].FinishedAt)
+	assert.Equal(t, int64(0), stages[2].DurationMs)
+}
+
+func TestBuildStagesError(t *testing.T) {
+	stages := BuildStages([]v1alpha1.DockerImageStageStatus{
+		{Name: "[1/2] FROM alpine"},
+		{Name: "[2/2] RUN exit 1", Error: "exit code: 1"},
+	})
+
+	require.Len(t, stages, 1)
+	assert.Equal(t, "", stages[0].Name)
+	assert.Equal(t, "exit code: 1", stages[0].Error)
+	assert.Equal(t, "exit code: 1", stages[0].Steps[1].Error)
+}
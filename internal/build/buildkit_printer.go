@@ -18,6 +18,9 @@ type buildkitPrinter struct {
 	logger logger.Logger
 	vData  map[digest.Digest]*vertexAndLogs
 	vOrder []digest.Digest
+
+	// The stages that have printed a header.
+	stagesPrinted map[string]bool
 }
 
 type vertex struct {
@@ -68,6 +71,18 @@ func (v *vertex) stageName() string {
 	return match
 }
 
+// The fields that mark which Dockerfile stage and step a line of output came from.
+func (v *vertex) stepFields() logger.Fields {
+	stage, step := parseStepName(v.name)
+	if step == "" {
+		return logger.Fields{}
+	}
+	return logger.Fields{
+		logger.FieldNameBuildStage: stage,
+		logger.FieldNameBuildStep:  step,
+	}
+}
+
 type vertexAndLogs struct {
 	vertex      *vertex
 	logs        []*vertexLog
@@ -124,6 +139,8 @@ func newBuildkitPrinter(l logger.Logger) *buildkitPrinter {
 		logger: l,
 		vData:  map[digest.Digest]*vertexAndLogs{},
 		vOrder: []digest.Digest{},
+
+		stagesPrinted: map[string]bool{},
 	}
 }
 
@@ -153,6 +170,31 @@ func (b *buildkitPrinter) toStageStatuses() []v1alpha1.DockerImageStageStatus {
 	return result
 }
 
+// runningStep returns the name and output of the first Dockerfile step that
+// started and hasn't finished, e.g., the step a build is stuck on.
+func (b *buildkitPrinter) runningStep() (name string, output string, ok bool) {
+	for _, d := range b.vOrder {
+		vl, ok := b.vData[d]
+		if !ok {
+			continue
+		}
+		v := vl.vertex
+		if !v.started || v.completed || v.isError() || v.shouldHide() {
+			continue
+		}
+		if _, step := parseStepName(v.name); step == "" {
+			continue
+		}
+
+		var sb strings.Builder
+		for _, l := range vl.logs {
+			sb.Write(l.msg)
+		}
+		return v.name, sb.String(), true
+	}
+	return "", "", false
+}
+
 func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, statuses []*vertexStatus) error {
 	for _, v := range vertexes {
 		if vl, ok := b.vData[v.digest]; ok {
@@ -175,7 +217,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			b.vData[v.digest] = &vertexAndLogs{
 				vertex: v,
 				logs:   []*vertexLog{},
-				logger: logger.NewPrefixedLogger(logPrefix, b.logger),
+				logger: logger.NewPrefixedLogger(logPrefix, b.logger).WithFields(v.stepFields()),
 			}
 
 			b.vOrder = append(b.vOrder, v.digest)
@@ -204,19 +246,22 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 		}
 
 		v := vl.vertex
+		stepFields := v.stepFields()
 		if v.started && !v.startPrinted && !v.shouldHide() {
 			cacheSuffix := ""
 			if v.cached {
 				cacheSuffix = " [cached]"
 			}
-			b.logger.WithFields(logger.Fields{logger.FieldNameProgressID: v.stageName()}).
+			b.printStageHeader(v)
+			b.logger.WithFields(withProgressID(stepFields, v.stageName())).
 				Infof("%s%s", v.humanName(), cacheSuffix)
 			v.startPrinted = true
 		}
 
 		if v.isError() && !v.errorPrinted {
 			// TODO(nick): Should this be logger.Errorf?
-			b.logger.Infof("\nERROR IN: %s", v.humanName())
+			b.printStageHeader(v)
+			b.logger.WithFields(stepFields).Infof("\nERROR IN: %s", v.humanName())
 			v.errorPrinted = true
 		}
 
@@ -263,7 +308,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 					v.durationPrinted < v.duration)
 
 			doneSuffix := ""
-			fields := logger.Fields{logger.FieldNameProgressID: v.stageName()}
+			fields := withProgressID(stepFields, v.stageName())
 			if shouldPrintCompletion {
 				doneSuffix = fmt.Sprintf(" [done: %s]", v.duration.Truncate(time.Millisecond))
 				v.completePrinted = true
@@ -272,6 +317,7 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 			}
 
 			if shouldPrintCompletion || shouldPrintProgress {
+				b.printStageHeader(v)
 				b.logger.WithFields(fields).
 					Infof("%s%s%s", v.humanName(), progressInBytes, doneSuffix)
 
@@ -283,7 +329,34 @@ func (b *buildkitPrinter) parseAndPrint(vertexes []*vertex, logs []*vertexLog, s
 	return nil
 }
 
+// printStageHeader prints a header before the first output of each stage of
+// a multi-stage Dockerfile.
+//
+// BuildKit runs independent stages in parallel, so their output may
+// interleave after the header; the step names and fields say which stage
+// each line belongs to.
+func (b *buildkitPrinter) printStageHeader(v *vertex) {
+	stage, _ := parseStepName(v.name)
+	if stage == "" || b.stagesPrinted[stage] {
+		return
+	}
+	b.stagesPrinted[stage] = true
+	b.logger.WithFields(logger.Fields{logger.FieldNameBuildStage: stage}).
+		Infof("── stage %s ──", stage)
+}
+
+func withProgressID(fields logger.Fields, progressID string) logger.Fields {
+	result := logger.Fields{logger.FieldNameProgressID: progressID}
+	for k, v := range fields {
+		result[k] = v
+	}
+	return result
+}
+
 func (b *buildkitPrinter) flushLogs(vl *vertexAndLogs) {
+	if vl.logsPrinted < len(vl.logs) {
+		b.printStageHeader(vl.vertex)
+	}
 	for vl.logsPrinted < len(vl.logs) {
 		l := vl.logs[vl.logsPrinted]
 		vl.logsPrinted++
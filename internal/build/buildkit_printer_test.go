@@ -38,6 +38,45 @@ func (c buildkitTestCase) readResponse(reader io.Reader) ([]controlapi.StatusRes
 	return result, nil
 }
 
+func TestBuildkitPrinterStepFields(t *testing.T) {
+	f, err := os.Open("testdata/TestBuildkitPrinter/multistage-fail-run.response.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	responses, err := buildkitTestCase{}.readResponse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fieldsByLine := map[string]logger.Fields{}
+	l := logger.NewFuncLogger(false, logger.InfoLvl, func(level logger.Level, fields logger.Fields, buf []byte) error {
+		for _, line := range strings.Split(strings.TrimSpace(string(buf)), "\n") {
+			fieldsByLine[line] = fields
+		}
+		return nil
+	})
+	p := newBuildkitPrinter(l)
+	for _, resp := range responses {
+		err := p.parseAndPrint(toVertexes(resp))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Equal(t, logger.Fields{logger.FieldNameBuildStage: "builder"},
+		fieldsByLine["── stage builder ──"])
+	assert.Equal(t, "builder", fieldsByLine["[builder 3/3] RUN echo hi > hi.txt"][logger.FieldNameBuildStage])
+	assert.Equal(t, "3/3", fieldsByLine["[builder 3/3] RUN echo hi > hi.txt"][logger.FieldNameBuildStep])
+	assert.Equal(t, logger.Fields{logger.FieldNameBuildStage: "stage-1", logger.FieldNameBuildStep: "4/4"},
+		fieldsByLine["→ hi"])
+	assert.Equal(t, logger.Fields{logger.FieldNameBuildStage: "stage-1", logger.FieldNameBuildStep: "4/4"},
+		fieldsByLine["ERROR IN: [stage-1 4/4] RUN cat hi.txt && exit 1"])
+}
+
 func TestBuildkitPrinter(t *testing.T) {
 	cases := []buildkitTestCase{
 		{"add-success", "add-success.response.txt"},
@@ -45,7 +45,8 @@ ADD dir/c.txt .
 	refs, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -80,7 +81,8 @@ ADD $some_variable_name /test.txt`)
 	refs, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -110,7 +112,8 @@ ADD a.txt .`)
 	refs, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -140,7 +143,8 @@ RUN echo 'failed to create LLB definition: failed commit on ref "unknown-sha256:
 	_, _, err := f.b.BuildImage(ctx, ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	assert.Error(t, err)
 	assert.Contains(t, out.String(), "Detected Buildkit corruption. Rebuilding without Buildkit")
 	assert.Contains(t, out.String(), "[1/2] FROM docker.io/library/alpine")                     // buildkit-style output
@@ -161,7 +165,8 @@ FROM alpine
 	refs, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	require.NoError(t, err)
 	f.assertImageHasLabels(refs.LocalRef, docker.BuiltLabelSet)
 }
@@ -180,7 +185,8 @@ FROM alpine
 	_, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec,
 		defaultCluster,
 		nil,
-		model.EmptyMatcher)
+		model.EmptyMatcher,
+		false)
 	if assert.Error(t, err) {
 		assert.Contains(t, err.Error(), "reading build context: stat unknown-dir: no such file or directory")
 	}
@@ -2,6 +2,7 @@ package build
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
@@ -20,6 +21,10 @@ import (
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
+// Set to 1 for a custom_build script that should build without its cache,
+// because the user reset the resource.
+const NoCacheEnvVar = "TILT_NO_CACHE"
+
 type CustomBuilder struct {
 	dCli  docker.Client
 	clock Clock
@@ -37,7 +42,8 @@ func NewCustomBuilder(dCli docker.Client, clock Clock, cmds *cmd.Controller) *Cu
 func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 	spec v1alpha1.CmdImageSpec,
 	cmd *v1alpha1.Cmd,
-	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap) (container.TaggedRefs, error) {
+	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap,
+	noCache bool) (container.TaggedRefs, error) {
 	expectedTag := spec.OutputTag
 	outputsImageRefTo := spec.OutputsImageRefTo
 	var registryHost string
@@ -96,6 +102,10 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 			fmt.Sprintf("EXPECTED_REGISTRY=%s", registryHost))
 	}
 
+	if noCache {
+		extraEnvVars = append(extraEnvVars, fmt.Sprintf("%s=1", NoCacheEnvVar))
+	}
+
 	extraEnvVars = append(extraEnvVars, b.dCli.Env().AsEnviron()...)
 
 	if len(extraEnvVars) == 0 {
@@ -123,8 +133,9 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 			model.ArgListToString(cmd.Spec.Args), status.Terminated.Reason)
 	}
 
+	var configDigest digest.Digest
 	if outputsImageRefTo != "" {
-		expectedBuildRefs, err = b.readImageRef(ctx, outputsImageRefTo, reg)
+		expectedBuildRefs, configDigest, err = b.readImageRef(ctx, outputsImageRefTo, reg)
 		if err != nil {
 			return container.TaggedRefs{}, err
 		}
@@ -133,10 +144,22 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 
 	// If the command skips the local docker registry, then we don't expect the image
 	// to be available (because the command has its own registry).
+	//
+	// If the script reported a digest, we keep it, so that the cluster pulls exactly
+	// the image (or image index) that was pushed.
 	if spec.OutputMode == v1alpha1.CmdImageOutputRemote {
 		return expectedBuildRefs, nil
 	}
 
+	// The local Docker image store only has the image for one platform, and doesn't
+	// know about any image index (or attestations) that the builder pushed.
+	// So we look it up by tag.
+	expectedBuildRefs, err = withoutDigest(expectedBuildRefs)
+	if err != nil {
+		return container.TaggedRefs{}, errors.Wrap(err, "custom_build")
+	}
+	expectedBuildResult = expectedBuildRefs.LocalRef
+
 	inspect, _, err := b.dCli.ImageInspectWithRaw(ctx, expectedBuildResult.String())
 	if err != nil {
 		return container.TaggedRefs{}, errors.Wrap(err, "Could not find image in Docker\n"+
@@ -146,13 +169,20 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 	}
 
 	if outputsImageRefTo != "" {
+		if configDigest != "" && digest.Digest(inspect.ID) != configDigest {
+			return container.TaggedRefs{}, fmt.Errorf(
+				"Image %s in Docker has ID %s, but the custom_build script built %s.\n"+
+					"If the build pushed a multi-platform image, make sure it loaded the image for the cluster platform.",
+				container.FamiliarString(expectedBuildResult), inspect.ID, configDigest)
+		}
+
 		// If we're using a custom_build-determined build ref, we don't use content-based tags.
 		return expectedBuildRefs, nil
 	}
 
 	dig := digest.Digest(inspect.ID)
 
-	tag, err := digestAsTag(dig)
+	tag, err := DigestAsTag(dig)
 	if err != nil {
 		return container.TaggedRefs{}, errors.Wrap(err, "custom_build")
 	}
@@ -171,16 +201,55 @@ func (b *CustomBuilder) Build(ctx context.Context, refs container.RefSet,
 	return taggedWithDigest, nil
 }
 
-func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo string, reg *v1alpha1.RegistryHosting) (container.TaggedRefs, error) {
+// The metadata file written by `docker buildx build --metadata-file`.
+//
+// When the build creates an image index (e.g., multiple platforms, or
+// provenance attestations), containerimage.digest is the digest of the index,
+// and containerimage.config.digest is the ID of the image for the platform
+// that was built locally. Other fields (like the provenance itself) are ignored.
+type buildxMetadata struct {
+	ImageName    string        `json:"image.name"`
+	Digest       digest.Digest `json:"containerimage.digest"`
+	ConfigDigest digest.Digest `json:"containerimage.config.digest"`
+}
+
+// Reads the image ref that the custom_build script wrote.
+//
+// The file may contain either an image ref (optionally with a digest),
+// or the metadata file from `docker buildx build --metadata-file`.
+//
+// Returns the refs and, if known, the ID of the image in the local image store.
+func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo string, reg *v1alpha1.RegistryHosting) (container.TaggedRefs, digest.Digest, error) {
 	contents, err := os.ReadFile(outputsImageRefTo)
 	if err != nil {
-		return container.TaggedRefs{}, fmt.Errorf("Could not find image ref in output. Your custom_build script should have written to %s: %v", outputsImageRefTo, err)
+		return container.TaggedRefs{}, "", fmt.Errorf("Could not find image ref in output. Your custom_build script should have written to %s: %v", outputsImageRefTo, err)
 	}
 
 	refStr := strings.TrimSpace(string(contents))
-	ref, err := container.ParseNamedTagged(refStr)
+	var configDigest digest.Digest
+	if strings.HasPrefix(refStr, "{") {
+		var metadata buildxMetadata
+		err := json.Unmarshal([]byte(refStr), &metadata)
+		if err != nil {
+			return container.TaggedRefs{}, "", fmt.Errorf("Output image metadata in file %s was invalid: %v",
+				outputsImageRefTo, err)
+		}
+
+		// image.name is a comma-separated list if the build had multiple tags.
+		refStr = strings.TrimSpace(strings.Split(metadata.ImageName, ",")[0])
+		if refStr == "" {
+			return container.TaggedRefs{}, "", fmt.Errorf("Output image metadata in file %s has no image.name",
+				outputsImageRefTo)
+		}
+		if metadata.Digest != "" && !strings.Contains(refStr, "@") {
+			refStr = fmt.Sprintf("%s@%s", refStr, metadata.Digest)
+		}
+		configDigest = metadata.ConfigDigest
+	}
+
+	ref, err := parseOutputImageRef(refStr)
 	if err != nil {
-		return container.TaggedRefs{}, fmt.Errorf("Output image ref in file %s was invalid: %v",
+		return container.TaggedRefs{}, "", fmt.Errorf("Output image ref in file %s was invalid: %v",
 			outputsImageRefTo, err)
 	}
 
@@ -188,16 +257,82 @@ func (b *CustomBuilder) readImageRef(ctx context.Context, outputsImageRefTo stri
 	if reg != nil && reg.HostFromContainerRuntime != "" {
 		replacedName, err := container.ParseNamed(strings.Replace(ref.Name(), reg.Host, reg.HostFromContainerRuntime, 1))
 		if err != nil {
-			return container.TaggedRefs{}, fmt.Errorf("Error converting image ref for cluster: %w", err)
+			return container.TaggedRefs{}, "", fmt.Errorf("Error converting image ref for cluster: %w", err)
 		}
-		clusterRef, err = reference.WithTag(replacedName, ref.Tag())
+		clusterRef, err = withTagAndDigest(replacedName, ref.Tag(), refDigest(ref))
 		if err != nil {
-			return container.TaggedRefs{}, fmt.Errorf("Error converting image ref for cluster: %w", err)
+			return container.TaggedRefs{}, "", fmt.Errorf("Error converting image ref for cluster: %w", err)
 		}
 	}
 
 	return container.TaggedRefs{
 		LocalRef:   ref,
 		ClusterRef: clusterRef,
-	}, nil
+	}, configDigest, nil
+}
+
+// Parses an image ref with a tag, a digest, or both.
+//
+// If the ref only has a digest (e.g., the digest of an image index
+// pushed by `docker buildx`), we derive a tag from the digest, so that
+// the rest of Tilt can treat it like any other tagged ref.
+func parseOutputImageRef(refStr string) (reference.NamedTagged, error) {
+	ref, err := container.ParseNamed(refStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if tagged, ok := ref.(reference.NamedTagged); ok {
+		return tagged, nil
+	}
+
+	dgst := refDigest(ref)
+	if dgst == "" {
+		return nil, fmt.Errorf("Expected reference %q to contain a tag", refStr)
+	}
+
+	tag, err := DigestAsTag(dgst)
+	if err != nil {
+		return nil, err
+	}
+	return withTagAndDigest(reference.TrimNamed(ref), tag, dgst)
+}
+
+func withoutDigest(refs container.TaggedRefs) (container.TaggedRefs, error) {
+	localRef, err := reference.WithTag(reference.TrimNamed(refs.LocalRef), refs.LocalRef.Tag())
+	if err != nil {
+		return container.TaggedRefs{}, err
+	}
+	clusterRef, err := reference.WithTag(reference.TrimNamed(refs.ClusterRef), refs.ClusterRef.Tag())
+	if err != nil {
+		return container.TaggedRefs{}, err
+	}
+	return container.TaggedRefs{LocalRef: localRef, ClusterRef: clusterRef}, nil
+}
+
+func refDigest(ref reference.Named) digest.Digest {
+	if digested, ok := ref.(reference.Digested); ok {
+		return digested.Digest()
+	}
+	return ""
+}
+
+func withTagAndDigest(name reference.Named, tag string, dgst digest.Digest) (reference.NamedTagged, error) {
+	tagged, err := reference.WithTag(name, tag)
+	if err != nil {
+		return nil, err
+	}
+	if dgst == "" {
+		return tagged, nil
+	}
+
+	withDigest, err := reference.WithDigest(tagged, dgst)
+	if err != nil {
+		return nil, err
+	}
+	result, ok := withDigest.(reference.NamedTagged)
+	if !ok {
+		return nil, fmt.Errorf("internal error: %s lost its tag", withDigest)
+	}
+	return result, nil
 }
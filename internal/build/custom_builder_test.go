@@ -221,6 +221,115 @@ func TestCustomBuildOutputsToImageRef_DifferentClusterHost(t *testing.T) {
 	assert.Equal(f.t, container.MustParseNamed(myClusterTag), refs.ClusterRef)
 }
 
+func TestCustomBuildOutputsToImageRefWithIndexDigest(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	myRef := "gcr.io/foo/bar:dev@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f"
+	cb := f.customBuild(fmt.Sprintf("echo %s > ref.txt", myRef))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("ref.txt")
+	cb.CmdImageSpec.OutputMode = v1alpha1.CmdImageOutputRemote
+	refs, err := f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.NoError(t, err)
+	assert.Equal(f.t, myRef, refs.LocalRef.String())
+	assert.Equal(f.t, myRef, refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefDigestOnly(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	myRef := "localhost:5000/foo/bar@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f"
+	cb := f.customBuild(fmt.Sprintf("echo %s > ref.txt", myRef))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("ref.txt")
+	cb.CmdImageSpec.OutputMode = v1alpha1.CmdImageOutputRemote
+	reg := &v1alpha1.RegistryHosting{Host: "localhost:5000", HostFromContainerRuntime: "registry:5000"}
+	refs, err := f.Build(refSetWithRegistryFromString("localhost:5000/foo/bar", reg), cb, nil)
+	require.NoError(t, err)
+	assert.Equal(f.t,
+		"localhost:5000/foo/bar:tilt-8d4b9e3a3d3f6d2c@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f",
+		refs.LocalRef.String())
+	assert.Equal(f.t,
+		"registry:5000/foo/bar:tilt-8d4b9e3a3d3f6d2c@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f",
+		refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefIndexDigestLocalDocker(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	// The local image store only has the platform image, so we look it up by tag.
+	myTag := "gcr.io/foo/bar:dev"
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	f.dCli.Images[myTag] = types.ImageInspect{ID: string(sha)}
+	cb := f.customBuild(fmt.Sprintf("echo %s@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f > ref.txt", myTag))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("ref.txt")
+	refs, err := f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.NoError(t, err)
+	assert.Equal(f.t, myTag, refs.LocalRef.String())
+	assert.Equal(f.t, myTag, refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefBuildxMetadataPush(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	metadata, err := filepath.Abs(filepath.Join("testdata", "buildx-metadata-push.json"))
+	require.NoError(t, err)
+	cb := f.customBuild(fmt.Sprintf("cp %s metadata.json", metadata))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("metadata.json")
+	cb.CmdImageSpec.OutputMode = v1alpha1.CmdImageOutputRemote
+	refs, err := f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.NoError(t, err)
+
+	// The index digest is preserved, so that the cluster pulls the index
+	// (and picks its own platform), ignoring the provenance.
+	expected := "gcr.io/foo/bar:dev@sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f"
+	assert.Equal(f.t, expected, refs.LocalRef.String())
+	assert.Equal(f.t, expected, refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefBuildxMetadataLoad(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	myTag := "gcr.io/foo/bar:dev"
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa")
+	f.dCli.Images[myTag] = types.ImageInspect{ID: string(sha)}
+
+	metadata, err := filepath.Abs(filepath.Join("testdata", "buildx-metadata-load.json"))
+	require.NoError(t, err)
+	cb := f.customBuild(fmt.Sprintf("cp %s metadata.json", metadata))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("metadata.json")
+	refs, err := f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.NoError(t, err)
+	assert.Equal(f.t, myTag, refs.LocalRef.String())
+	assert.Equal(f.t, myTag, refs.ClusterRef.String())
+}
+
+func TestCustomBuildOutputsToImageRefBuildxMetadataWrongImage(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	// The image in Docker isn't the one that the build loaded
+	// (e.g., it was built for a different platform).
+	myTag := "gcr.io/foo/bar:dev"
+	sha := digest.Digest("sha256:22cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aa")
+	f.dCli.Images[myTag] = types.ImageInspect{ID: string(sha)}
+
+	metadata, err := filepath.Abs(filepath.Join("testdata", "buildx-metadata-load.json"))
+	require.NoError(t, err)
+	cb := f.customBuild(fmt.Sprintf("cp %s metadata.json", metadata))
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("metadata.json")
+	_, err = f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "but the custom_build script built sha256:11cd0eb38bc3ceb9")
+}
+
+func TestCustomBuildOutputsToImageRefBuildxMetadataMissingName(t *testing.T) {
+	f := newFakeCustomBuildFixture(t)
+
+	cb := f.customBuild(`echo '{"containerimage.digest": "sha256:8d4b9e3a3d3f6d2c5e0b1f3a7c9e2d4b6a8c0e2f4a6b8d0c2e4f6a8b0c2d4e6f"}' > metadata.json`)
+	cb.CmdImageSpec.OutputsImageRefTo = f.JoinPath("metadata.json")
+	_, err := f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "has no image.name")
+}
+
 func TestCustomBuildImageDep(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("no sh on windows")
@@ -274,6 +383,29 @@ func TestEnvVars(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestNoCacheEnvVar(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh on windows")
+	}
+
+	f := newFakeCustomBuildFixture(t)
+	sha := digest.Digest("sha256:11cd0eb38bc3ceb958ffb2f9bd70be3fb317ce7d255c8a4c3f4af30e298aa1aab")
+	f.dCli.Images["gcr.io/foo/bar:tilt-build-1551202573"] = types.ImageInspect{ID: string(sha)}
+	cb := f.customBuild(`if [ "${TILT_NO_CACHE}" != "1" ]; then >&2 echo "TILT_NO_CACHE not set"; exit 1; fi`)
+
+	_, err := f.cb.Build(f.ctx, refSetFromString("gcr.io/foo/bar"), cb.CmdImageSpec, &v1alpha1.Cmd{
+		ObjectMeta: metav1.ObjectMeta{Name: "img"},
+		Spec: v1alpha1.CmdSpec{
+			Args: cb.CmdImageSpec.Args,
+			Dir:  cb.CmdImageSpec.Dir,
+		},
+	}, nil, true)
+	require.NoError(t, err)
+
+	_, err = f.Build(refSetFromString("gcr.io/foo/bar"), cb, nil)
+	require.Error(t, err)
+}
+
 func TestEnvVars_ConfigRefWithLocalRegistry(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("no sh on windows")
@@ -356,7 +488,7 @@ func (f *fakeCustomBuildFixture) Build(refs container.RefSet, cb model.CustomBui
 			Args: cb.CmdImageSpec.Args,
 			Dir:  cb.CmdImageSpec.Dir,
 		},
-	}, imageMaps)
+	}, imageMaps, false)
 }
 
 func refSetFromString(s string) container.RefSet {
@@ -72,7 +72,7 @@ func (d *DockerBuilder) DumpImageDeployRef(ctx context.Context, ref string) (ref
 	}
 	dig := digest.Digest(data.ID)
 
-	tag, err := digestAsTag(dig)
+	tag, err := DigestAsTag(dig)
 	if err != nil {
 		return nil, errors.Wrap(err, "DumpImageDeployRef")
 	}
@@ -87,7 +87,7 @@ func (d *DockerBuilder) DumpImageDeployRef(ctx context.Context, ref string) (ref
 
 // Tag the digest with the given name and wm-tilt tag.
 func (d *DockerBuilder) TagRefs(ctx context.Context, refs container.RefSet, dig digest.Digest) (container.TaggedRefs, error) {
-	tag, err := digestAsTag(dig)
+	tag, err := DigestAsTag(dig)
 	if err != nil {
 		return container.TaggedRefs{}, errors.Wrap(err, "TagImage")
 	}
@@ -149,7 +149,8 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 	spec v1alpha1.DockerImageSpec,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[ktypes.NamespacedName]*v1alpha1.ImageMap,
-	filter model.PathMatcher) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	filter model.PathMatcher,
+	noCache bool) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
 	spec = InjectClusterPlatform(spec, cluster)
 	spec, err := InjectImageDependencies(spec, imageMaps)
 	if err != nil {
@@ -161,11 +162,18 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 		platformSuffix = fmt.Sprintf(" for platform %s", spec.Platform)
 	}
 	logger.Get(ctx).Infof("Building Dockerfile%s:\n%s\n", platformSuffix, indent(spec.DockerfileContents, "  "))
+	if noCache {
+		logger.Get(ctx).Infof("Building without the build cache (--no-cache)")
+	}
+
+	if d.dCli.BuilderVersion() != types.BuilderBuildKit {
+		warnIfRequiresBuildKit(ctx, spec.DockerfileContents)
+	}
 
 	ps.StartBuildStep(ctx, "Building image")
 	allowBuildkit := true
 	ctx = ps.AttachLogger(ctx)
-	digest, stages, err := d.buildToDigest(ctx, spec, filter, allowBuildkit)
+	digest, stages, err := d.buildToDigest(ctx, spec, filter, allowBuildkit, noCache)
 	if err != nil {
 		isMysteriousCorruption := strings.Contains(err.Error(), "failed precondition") &&
 			strings.Contains(err.Error(), "failed commit on ref")
@@ -186,7 +194,7 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 			// If this happens, just try again without buildkit.
 			allowBuildkit = false
 			logger.Get(ctx).Infof("Detected Buildkit corruption. Rebuilding without Buildkit")
-			digest, stages, err = d.buildToDigest(ctx, spec, filter, allowBuildkit)
+			digest, stages, err = d.buildToDigest(ctx, spec, filter, allowBuildkit, noCache)
 		}
 
 		if err != nil {
@@ -202,34 +210,40 @@ func (d *DockerBuilder) BuildImage(ctx context.Context, ps *PipelineState, refs
 	return tagged, stages, nil
 }
 
+// Warns about instructions that the legacy builder can't build, so that the
+// user knows to turn on BuildKit rather than puzzling over Docker's error.
+func warnIfRequiresBuildKit(ctx context.Context, df string) {
+	ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(df))
+	if err != nil {
+		return
+	}
+	reqs, err := ast.RequiresBuildKit()
+	if err != nil || len(reqs) == 0 {
+		return
+	}
+
+	lines := []string{"Docker is using the legacy builder, but this Dockerfile needs BuildKit:"}
+	for _, r := range reqs {
+		lines = append(lines, fmt.Sprintf("  line %d: %s", r.Line, r.Message()))
+	}
+	lines = append(lines, "Set DOCKER_BUILDKIT=1 to build with BuildKit.")
+	logger.Get(ctx).Warnf("%s", strings.Join(lines, "\n"))
+}
+
 // A helper function that builds the paths to the given docker image,
 // then returns the output digest.
-func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerImageSpec, filter model.PathMatcher, allowBuildkit bool) (digest.Digest, []v1alpha1.DockerImageStageStatus, error) {
+func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerImageSpec, filter model.PathMatcher, allowBuildkit bool, noCache bool) (digest.Digest, []v1alpha1.DockerImageStageStatus, error) {
 	ctx, cancelBuildSession := context.WithCancel(ctx)
 	defer cancelBuildSession()
 
 	g, ctx := errgroup.WithContext(ctx)
 	var contextReader io.Reader
 
-	buildContext := spec.Context
-
-	// Treat context: "-" as an empty context.
-	if buildContext == "-" {
-		emptyContextDir, err := os.MkdirTemp("", "tilt-dockercontext-")
+	if spec.Context != "-" {
+		_, err := os.Stat(spec.Context)
 		if err != nil {
-			return "", nil, fmt.Errorf("creating context directory: %v", err)
+			return "", nil, fmt.Errorf("reading build context: %v", err)
 		}
-
-		defer func() {
-			_ = os.RemoveAll(emptyContextDir)
-		}()
-
-		buildContext = emptyContextDir
-	}
-
-	_, err := os.Stat(buildContext)
-	if err != nil {
-		return "", nil, fmt.Errorf("reading build context: %v", err)
 	}
 
 	// Buildkit allows us to use a fs sync server instead of uploading up-front.
@@ -241,13 +255,7 @@ func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerI
 
 		// TODO(nick): Express tarring as a build stage.
 		g.Go(func() error {
-			paths := []PathMapping{
-				{
-					LocalPath:     buildContext,
-					ContainerPath: "/",
-				},
-			}
-			err := tarContextAndUpdateDf(ctx, w, dockerfile.Dockerfile(spec.DockerfileContents), paths, filter)
+			err := WriteContext(ctx, w, spec, filter)
 			if err != nil {
 				_ = pipeWriter.CloseWithError(err)
 			} else {
@@ -264,7 +272,24 @@ func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerI
 	}
 
 	options := Options(contextReader, spec)
+	options.NoCache = noCache
 	if useFSSync {
+		buildContext := spec.Context
+
+		// Treat context: "-" as an empty context.
+		if buildContext == "-" {
+			emptyContextDir, err := os.MkdirTemp("", "tilt-dockercontext-")
+			if err != nil {
+				return "", nil, fmt.Errorf("creating context directory: %v", err)
+			}
+
+			defer func() {
+				_ = os.RemoveAll(emptyContextDir)
+			}()
+
+			buildContext = emptyContextDir
+		}
+
 		dockerfileDir, err := writeTempDockerfileSyncdir(spec.DockerfileContents)
 		if err != nil {
 			return "", nil, err
@@ -282,6 +307,7 @@ func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerI
 
 	var digest digest.Digest
 	var status []v1alpha1.DockerImageStageStatus
+	var buildErr error
 	g.Go(func() error {
 		defer cancelBuildSession()
 		imageBuildResponse, err := d.dCli.ImageBuild(
@@ -301,11 +327,18 @@ func (d *DockerBuilder) buildToDigest(ctx context.Context, spec v1alpha1.DockerI
 			}
 		}()
 
-		digest, status, err = d.getDigestFromBuildOutput(ctx, imageBuildResponse.Body)
-		return err
+		digest, status, buildErr = d.getDigestFromBuildOutput(ctx, imageBuildResponse.Body)
+		return buildErr
 	})
 
-	err = g.Wait()
+	err := g.Wait()
+
+	// When the build times out, the build session may fail first, but the
+	// build output knows which step was stuck.
+	var timeoutErr TimeoutError
+	if errors.As(buildErr, &timeoutErr) {
+		err = timeoutErr
+	}
 	return digest, status, err
 }
 
@@ -362,17 +395,33 @@ type dockerMessageID string
 // but you can find it implemented in Docker here:
 // https://github.com/moby/moby/blob/1da7d2eebf0a7a60ce585f89a05cebf7f631019c/pkg/jsonmessage/jsonmessage.go#L139
 func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, []v1alpha1.DockerImageStageStatus, error) {
+	b := newBuildkitPrinter(logger.Get(ctx))
+	result, err := readDockerMessages(ctx, reader, b)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// Name the step that the build was stuck on, so that the user
+		// doesn't have to dig through the log for it.
+		timeoutErr := TimeoutError{}
+		step, output, ok := b.runningStep()
+		if ok {
+			timeoutErr.Step = step
+			timeoutErr.Output = lastLines(output, timeoutOutputLines)
+		}
+		err = timeoutErr
+	}
+	return result, b.toStageStatuses(), err
+}
+
+func readDockerMessages(ctx context.Context, reader io.Reader, b *buildkitPrinter) (dockerOutput, error) {
 	progressLastPrinted := make(map[dockerMessageID]time.Time)
 
 	result := dockerOutput{}
 	decoder := json.NewDecoder(reader)
-	b := newBuildkitPrinter(logger.Get(ctx))
 
 	for decoder.More() {
 		message := jsonmessage.JSONMessage{}
 		err := decoder.Decode(&message)
 		if err != nil {
-			return dockerOutput{}, b.toStageStatuses(), errors.Wrap(err, "decoding docker output")
+			return dockerOutput{}, errors.Wrap(err, "decoding docker output")
 		}
 
 		if len(message.Stream) > 0 {
@@ -388,11 +437,11 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, []v1
 		}
 
 		if message.ErrorMessage != "" {
-			return dockerOutput{}, b.toStageStatuses(), errors.New(cleanupDockerBuildError(message.ErrorMessage))
+			return dockerOutput{}, errors.New(cleanupDockerBuildError(message.ErrorMessage))
 		}
 
 		if message.Error != nil {
-			return dockerOutput{}, b.toStageStatuses(), errors.New(cleanupDockerBuildError(message.Error.Message))
+			return dockerOutput{}, errors.New(cleanupDockerBuildError(message.Error.Message))
 		}
 
 		id := dockerMessageID(message.ID)
@@ -418,7 +467,7 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, []v1
 		if messageIsFromBuildkit(message) {
 			err := toBuildkitStatus(message.Aux, b)
 			if err != nil {
-				return dockerOutput{}, b.toStageStatuses(), err
+				return dockerOutput{}, err
 			}
 		}
 
@@ -428,9 +477,9 @@ func readDockerOutput(ctx context.Context, reader io.Reader) (dockerOutput, []v1
 	}
 
 	if ctx.Err() != nil {
-		return dockerOutput{}, b.toStageStatuses(), ctx.Err()
+		return dockerOutput{}, ctx.Err()
 	}
-	return result, b.toStageStatuses(), nil
+	return result, nil
 }
 
 func toBuildkitStatus(aux *json.RawMessage, b *buildkitPrinter) error {
@@ -523,7 +572,9 @@ func getDigestFromAux(aux json.RawMessage) (digest.Digest, error) {
 	return digest.Digest(id), nil
 }
 
-func digestAsTag(d digest.Digest) (string, error) {
+// DigestAsTag returns the tag that Tilt gives an image it built: the
+// ImageTagPrefix and the start of the image's digest.
+func DigestAsTag(d digest.Digest) (string, error) {
 	str := d.Encoded()
 	if len(str) < 16 {
 		return "", fmt.Errorf("digest too short: %s", str)
@@ -1,7 +1,9 @@
 package build
 
 import (
+	"archive/tar"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
@@ -14,12 +16,15 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
 func TestDigestAsTag(t *testing.T) {
 	dig := digest.Digest("sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1")
-	tag, err := digestAsTag(dig)
+	tag, err := DigestAsTag(dig)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -32,7 +37,7 @@ func TestDigestAsTag(t *testing.T) {
 
 func TestDigestMatchesRef(t *testing.T) {
 	dig := digest.Digest("sha256:cc5f4c463f81c55183d8d737ba2f0d30b3e6f3670dbe2da68f0aac168e93fbb1")
-	tag, err := digestAsTag(dig)
+	tag, err := DigestAsTag(dig)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,7 +58,7 @@ func TestDigestNotMatchesRef(t *testing.T) {
 
 func TestDigestAsTagToShort(t *testing.T) {
 	dig := digest.Digest("sha256:cc")
-	_, err := digestAsTag(dig)
+	_, err := DigestAsTag(dig)
 	expected := "too short"
 	if err == nil || !strings.Contains(err.Error(), expected) {
 		t.Errorf("expected error %q, actual: %v", expected, err)
@@ -173,3 +178,54 @@ func TestCleanUpBuildKitErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteContextMatchesBuild(t *testing.T) {
+	f := newFakeDockerBuildFixture(t)
+	f.WriteFile("main.go", "package main")
+	f.WriteFile("docs/README.md", "docs")
+	f.WriteFile("node_modules/left-pad/index.js", "module.exports = {}")
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM alpine\nCOPY . /app",
+		Context:            f.Path(),
+		ContextIgnores: []v1alpha1.IgnoreDef{
+			{BasePath: f.JoinPath("node_modules")},
+		},
+	}
+	filter := ignore.CreateBuildContextFilter(spec.ContextIgnores)
+
+	_, _, err := f.b.BuildImage(f.ctx, f.ps, f.getNameFromTest(), spec, nil, nil, filter, false)
+	require.NoError(t, err)
+
+	var dumped bytes.Buffer
+	err = WriteContext(f.ctx, &dumped, spec, filter)
+	require.NoError(t, err)
+	assert.Equal(t, f.fakeDocker.BuildContext.Bytes(), dumped.Bytes())
+
+	// Writing again gives the same bytes.
+	var again bytes.Buffer
+	err = WriteContext(f.ctx, &again, spec, filter)
+	require.NoError(t, err)
+	assert.Equal(t, dumped.Bytes(), again.Bytes())
+
+	testutils.AssertFilesInTar(t, tar.NewReader(&dumped), []expectedFile{
+		{Path: "main.go", Contents: "package main"},
+		{Path: "docs/README.md", Contents: "docs"},
+		{Path: "Dockerfile", Contents: "FROM alpine\nCOPY . /app"},
+		{Path: "node_modules/left-pad/index.js", Missing: true},
+	})
+}
+
+func TestWarnIfRequiresBuildKit(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+
+	warnIfRequiresBuildKit(ctx, "FROM alpine\nCOPY --chown=1000 a /a\nCOPY --chmod=755 b /b\n")
+	assert.Contains(t, out.String(), "Docker is using the legacy builder, but this Dockerfile needs BuildKit:\n"+
+		"  line 3: COPY --chmod requires BuildKit (the legacy builder supports --chown, but not --chmod)\n"+
+		"Set DOCKER_BUILDKIT=1 to build with BuildKit.")
+
+	out.Reset()
+	warnIfRequiresBuildKit(ctx, "FROM alpine\nCOPY --chown=1000 a /a\n")
+	assert.Empty(t, out.String())
+}
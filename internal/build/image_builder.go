@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"k8s.io/apimachinery/pkg/types"
@@ -12,22 +13,28 @@ import (
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 type ImageBuilder struct {
-	db    *DockerBuilder
-	custb *CustomBuilder
-	kl    KINDLoader
+	db      *DockerBuilder
+	custb   *CustomBuilder
+	kl      KINDLoader
+	execer  localexec.Execer
+	offline offline.Mode
 }
 
-func NewImageBuilder(db *DockerBuilder, custb *CustomBuilder, kl KINDLoader) *ImageBuilder {
+func NewImageBuilder(db *DockerBuilder, custb *CustomBuilder, kl KINDLoader, execer localexec.Execer, offline offline.Mode) *ImageBuilder {
 	return &ImageBuilder{
-		db:    db,
-		custb: custb,
-		kl:    kl,
+		db:      db,
+		custb:   custb,
+		kl:      kl,
+		execer:  execer,
+		offline: offline,
 	}
 }
 
@@ -55,7 +62,7 @@ func (ib *ImageBuilder) Build(ctx context.Context,
 	cluster *v1alpha1.Cluster,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
 	ps *PipelineState) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
-	refs, stages, err := ib.buildOnly(ctx, iTarget, customBuildCmd, cluster, imageMaps, ps)
+	refs, stages, err := ib.buildWithTimeout(ctx, iTarget, customBuildCmd, cluster, imageMaps, ps)
 	if err != nil {
 		return refs, stages, err
 	}
@@ -66,9 +73,40 @@ func (ib *ImageBuilder) Build(ctx context.Context,
 	}
 
 	if pushStage != nil && pushStage.Error != "" {
-		err = errors.New(pushStage.Error)
+		return refs, stages, errors.New(pushStage.Error)
 	}
 
+	if bd, ok := iTarget.BuildDetails.(model.DockerBuild); ok && bd.PublishPath != "" {
+		err = ib.publish(ctx, ps, refs, bd.PublishPath)
+	}
+
+	return refs, stages, err
+}
+
+// Build the image, canceling the build if it runs past the image's timeout.
+func (ib *ImageBuilder) buildWithTimeout(ctx context.Context,
+	iTarget model.ImageTarget,
+	customBuildCmd *v1alpha1.Cmd,
+	cluster *v1alpha1.Cluster,
+	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
+	ps *PipelineState,
+) (container.TaggedRefs, []v1alpha1.DockerImageStageStatus, error) {
+	if iTarget.BuildTimeout <= 0 {
+		return ib.buildOnly(ctx, iTarget, customBuildCmd, cluster, imageMaps, ps)
+	}
+
+	buildCtx, cancel := context.WithTimeout(ctx, iTarget.BuildTimeout)
+	defer cancel()
+
+	refs, stages, err := ib.buildOnly(buildCtx, iTarget, customBuildCmd, cluster, imageMaps, ps)
+	if err != nil && errors.Is(buildCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		// The Docker build names the step that was running. Other
+		// builds only know that they timed out.
+		var timeoutErr TimeoutError
+		_ = errors.As(err, &timeoutErr)
+		timeoutErr.Timeout = iTarget.BuildTimeout
+		err = timeoutErr
+	}
 	return refs, stages, err
 }
 
@@ -92,16 +130,37 @@ func (ib *ImageBuilder) buildOnly(ctx context.Context,
 		ps.StartPipelineStep(ctx, "Building Dockerfile: [%s]", userFacingRefName)
 		defer ps.EndPipelineStep(ctx)
 
-		filter := ignore.CreateBuildContextFilter(bd.DockerImageSpec.ContextIgnores)
-		return ib.db.BuildImage(ctx, ps, refs, bd.DockerImageSpec,
+		if bd.GoDeps != nil {
+			ps.Printf(ctx, "Watching %d Go packages imported by %s (refreshed %s)",
+				bd.GoDeps.Packages, bd.GoDeps.Main, bd.GoDeps.RefreshedAt.Format(time.Kitchen))
+		}
+
+		spec, err := resolveDynamicArgs(ctx, ib.execer, ps, bd.DockerImageSpec)
+		if err != nil {
+			return container.TaggedRefs{}, nil, err
+		}
+
+		spec, err = injectPublishedBaseImages(ctx, ps, spec)
+		if err != nil {
+			return container.TaggedRefs{}, nil, err
+		}
+
+		if spec.Pull && ib.offline.Enabled {
+			ps.Printf(ctx, "Skipping pull of base images: Tilt is in offline mode (%s)", ib.offline.Reason)
+			spec.Pull = false
+		}
+
+		filter := ignore.CreateBuildContextFilter(spec.ContextIgnores)
+		return ib.db.BuildImage(ctx, ps, refs, spec,
 			cluster,
 			imageMaps,
-			filter)
+			filter,
+			iTarget.NoCache)
 
 	case model.CustomBuild:
 		ps.StartPipelineStep(ctx, "Building Custom Build: [%s]", userFacingRefName)
 		defer ps.EndPipelineStep(ctx)
-		refs, err := ib.custb.Build(ctx, refs, bd.CmdImageSpec, customBuildCmd, imageMaps)
+		refs, err := ib.custb.Build(ctx, refs, bd.CmdImageSpec, customBuildCmd, imageMaps, iTarget.NoCache)
 		return refs, nil, err
 	}
 
@@ -165,6 +224,17 @@ func (ib *ImageBuilder) push(ctx context.Context, refs container.TaggedRefs, ps
 		return stage
 	}
 
+	registry := reference.Domain(refs.LocalRef)
+	if ib.offline.Enabled && !offline.IsLocalRegistry(registry) {
+		endTime := apis.NowMicro()
+		return &v1alpha1.DockerImageStageStatus{
+			Name:       "docker push",
+			StartedAt:  &startTime,
+			FinishedAt: &endTime,
+			Error:      ib.offline.Errorf("docker push: can't reach registry %s", registry).Error(),
+		}
+	}
+
 	ps.Printf(ctx, "Pushing with Docker client")
 	err = ib.db.PushImage(ps.AttachLogger(ctx), refs.LocalRef)
 
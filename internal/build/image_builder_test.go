@@ -0,0 +1,95 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/offline"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPushOffline(t *testing.T) {
+	f := newImageBuilderFixture(t, offline.Mode{Enabled: true, Reason: "--offline"})
+
+	stage := f.push("gcr.io/acme/app:tilt-abc123", nil)
+	require.NotNil(t, stage)
+	assert.Equal(t, "docker push: can't reach registry gcr.io: Tilt is in offline mode (--offline)", stage.Error)
+	assert.Equal(t, 0, f.dCli.PushCount)
+}
+
+func TestPushOfflineLocalRegistry(t *testing.T) {
+	f := newImageBuilderFixture(t, offline.Mode{Enabled: true, Reason: "--offline"})
+
+	stage := f.push("localhost:5005/app:tilt-abc123", nil)
+	require.NotNil(t, stage)
+	assert.Equal(t, "", stage.Error)
+	assert.Equal(t, 1, f.dCli.PushCount)
+}
+
+func TestPushOfflineKINDLoad(t *testing.T) {
+	f := newImageBuilderFixture(t, offline.Mode{Enabled: true, Reason: "--offline"})
+
+	stage := f.push("gcr.io/acme/app:tilt-abc123", &v1alpha1.Cluster{
+		Status: v1alpha1.ClusterStatus{
+			Connection: &v1alpha1.ClusterConnectionStatus{
+				Kubernetes: &v1alpha1.KubernetesClusterConnectionStatus{
+					Product: string(clusterid.ProductKIND),
+				},
+			},
+		},
+	})
+	require.NotNil(t, stage)
+	assert.Equal(t, "kind load", stage.Name)
+	assert.Equal(t, "", stage.Error)
+	assert.Equal(t, 1, f.kl.loadCount)
+	assert.Equal(t, 0, f.dCli.PushCount)
+}
+
+type imageBuilderFixture struct {
+	ctx  context.Context
+	dCli *docker.FakeClient
+	kl   *fakeKINDLoader
+	ib   *ImageBuilder
+}
+
+func newImageBuilderFixture(t *testing.T, mode offline.Mode) *imageBuilderFixture {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, &bytes.Buffer{}))
+	dCli := docker.NewFakeClient()
+	kl := &fakeKINDLoader{}
+	return &imageBuilderFixture{
+		ctx:  ctx,
+		dCli: dCli,
+		kl:   kl,
+		ib:   NewImageBuilder(NewDockerBuilder(dCli, nil), nil, kl, nil, mode),
+	}
+}
+
+func (f *imageBuilderFixture) push(ref string, cluster *v1alpha1.Cluster) *v1alpha1.DockerImageStageStatus {
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("app")).
+		WithBuildDetails(model.DockerBuild{
+			DockerImageSpec: v1alpha1.DockerImageSpec{ClusterNeeds: v1alpha1.ClusterImageNeedsPush},
+		})
+	named := container.MustParseNamedTagged(ref)
+	refs := container.TaggedRefs{LocalRef: named, ClusterRef: named}
+	ps := NewPipelineState(f.ctx, 1, fakeClock{})
+	return f.ib.push(f.ctx, refs, ps, iTarget, cluster)
+}
+
+type fakeKINDLoader struct {
+	loadCount int
+}
+
+func (kl *fakeKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Cluster, ref reference.NamedTagged) error {
+	kl.loadCount++
+	return nil
+}
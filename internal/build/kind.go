@@ -8,6 +8,7 @@ import (
 	"github.com/docker/distribution/reference"
 
 	"github.com/tilt-dev/clusterid"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
@@ -17,6 +18,7 @@ type KINDLoader interface {
 }
 
 type cmdKINDLoader struct {
+	supervisor *localexec.Supervisor
 }
 
 func (kl *cmdKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Cluster, ref reference.NamedTagged) error {
@@ -33,9 +35,9 @@ func (kl *cmdKINDLoader) LoadToKIND(ctx context.Context, cluster *v1alpha1.Clust
 	cmd.Stdout = w
 	cmd.Stderr = w
 
-	return cmd.Run()
+	return kl.supervisor.Run(cmd)
 }
 
-func NewKINDLoader() KINDLoader {
-	return &cmdKINDLoader{}
+func NewKINDLoader(env *localexec.Env) KINDLoader {
+	return &cmdKINDLoader{supervisor: env.Supervisor()}
 }
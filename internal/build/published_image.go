@@ -0,0 +1,155 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// An image that a Tilt session built with docker_build(published=...), as
+// written to its published file.
+type PublishedImage struct {
+	// The image name + tag, as the publishing session's Docker refers to it.
+	// The tag is derived from the digest, so it changes on every new image.
+	Ref string `json:"ref"`
+
+	// The image name + tag, as the cluster's container runtime refers to it.
+	ClusterRef string `json:"clusterRef,omitempty"`
+
+	// The image ID, e.g., sha256:abc123...
+	Digest string `json:"digest"`
+
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+func ReadPublishedImage(path string) (PublishedImage, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return PublishedImage{}, err
+	}
+
+	var result PublishedImage
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return PublishedImage{}, fmt.Errorf("%s: %v", path, err)
+	}
+	if result.Ref == "" {
+		return PublishedImage{}, fmt.Errorf("%s: missing ref", path)
+	}
+	return result, nil
+}
+
+// WritePublishedImage writes the published file.
+//
+// The file is written to a temp file and renamed, so that a session watching
+// it never reads a partial file.
+func WritePublishedImage(path string, img PublishedImage) error {
+	contents, err := json.MarshalIndent(img, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, append(contents, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Rewrites the Dockerfile to build on the latest images published by other
+// Tilt sessions.
+//
+// If a base image hasn't been published yet, the Dockerfile uses it as
+// written.
+func injectPublishedBaseImages(ctx context.Context, ps *PipelineState,
+	spec v1alpha1.DockerImageSpec) (v1alpha1.DockerImageSpec, error) {
+	if len(spec.BaseImages) == 0 {
+		return spec, nil
+	}
+
+	ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(spec.DockerfileContents))
+	if err != nil {
+		return spec, errors.Wrap(err, "injectPublishedBaseImages")
+	}
+
+	modified := false
+	for _, dep := range spec.BaseImages {
+		img, err := ReadPublishedImage(dep.Path)
+		if os.IsNotExist(err) {
+			ps.Printf(ctx, "Base image %s hasn't been published to %s yet. Using it as written.", dep.Ref, dep.Path)
+			continue
+		} else if err != nil {
+			return spec, fmt.Errorf("reading published base image %s: %v", dep.Ref, err)
+		}
+
+		ref, err := container.ParseNamedTagged(img.Ref)
+		if err != nil {
+			return spec, fmt.Errorf("reading published base image %s: %s: %v", dep.Ref, dep.Path, err)
+		}
+
+		depRef, err := container.ParseNamed(dep.Ref)
+		if err != nil {
+			return spec, fmt.Errorf("base image %s: %v", dep.Ref, err)
+		}
+
+		ok, err := ast.InjectImageDigest(container.NewRefSelector(depRef), ref, spec.Args)
+		if err != nil {
+			return spec, errors.Wrap(err, "injectPublishedBaseImages")
+		}
+		if ok {
+			ps.Printf(ctx, "Using published base image %s (%s)", container.FamiliarString(ref), img.Digest)
+			modified = true
+		}
+	}
+
+	if !modified {
+		return spec, nil
+	}
+
+	df, err := ast.Print()
+	if err != nil {
+		return spec, errors.Wrap(err, "injectPublishedBaseImages")
+	}
+	spec.DockerfileContents = df.String()
+	return spec, nil
+}
+
+// Writes the ref and digest of a new image to its published file, so that
+// other Tilt sessions rebuild the images that use it.
+func (ib *ImageBuilder) publish(ctx context.Context, ps *PipelineState, refs container.TaggedRefs, path string) error {
+	inspect, _, err := ib.db.dCli.ImageInspectWithRaw(ctx, refs.LocalRef.String())
+	if err != nil {
+		return fmt.Errorf("publishing %s: %v", container.FamiliarString(refs.LocalRef), err)
+	}
+
+	img := PublishedImage{
+		Ref:         refs.LocalRef.String(),
+		Digest:      inspect.ID,
+		PublishedAt: time.Now(),
+	}
+	if refs.ClusterRef != nil {
+		img.ClusterRef = refs.ClusterRef.String()
+	}
+
+	err = WritePublishedImage(path, img)
+	if err != nil {
+		return fmt.Errorf("publishing %s: %v", container.FamiliarString(refs.LocalRef), err)
+	}
+	ps.Printf(ctx, "Published %s to %s", container.FamiliarString(refs.LocalRef), path)
+	return nil
+}
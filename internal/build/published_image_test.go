@@ -0,0 +1,95 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestWriteAndReadPublishedImage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "published", "base.json")
+	img := PublishedImage{
+		Ref:         "gcr.io/acme/base:tilt-abc123",
+		ClusterRef:  "localhost:5005/gcr.io_acme_base:tilt-abc123",
+		Digest:      "sha256:abc123",
+		PublishedAt: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, WritePublishedImage(path, img))
+
+	actual, err := ReadPublishedImage(path)
+	require.NoError(t, err)
+	assert.Equal(t, img, actual)
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestReadPublishedImageMissingRef(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "base.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"digest": "sha256:abc123"}`), 0644))
+
+	_, err := ReadPublishedImage(path)
+	assert.EqualError(t, err, path+": missing ref")
+}
+
+func TestInjectPublishedBaseImages(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	path := filepath.Join(t.TempDir(), "base.json")
+	require.NoError(t, WritePublishedImage(path, PublishedImage{
+		Ref:    "gcr.io/acme/base:tilt-abc123",
+		Digest: "sha256:abc123",
+	}))
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM gcr.io/acme/base:latest\nRUN make\n",
+		BaseImages:         []v1alpha1.DockerImageBaseImage{{Ref: "gcr.io/acme/base", Path: path}},
+	}
+	resolved, err := injectPublishedBaseImages(ctx, ps, spec)
+	require.NoError(t, err)
+	assert.Equal(t, "FROM gcr.io/acme/base:tilt-abc123\nRUN make\n", resolved.DockerfileContents)
+	assert.Contains(t, out.String(), "Using published base image gcr.io/acme/base:tilt-abc123 (sha256:abc123)")
+}
+
+func TestInjectPublishedBaseImagesNotPublished(t *testing.T) {
+	out := &bytes.Buffer{}
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	path := filepath.Join(t.TempDir(), "base.json")
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM gcr.io/acme/base:latest\n",
+		BaseImages:         []v1alpha1.DockerImageBaseImage{{Ref: "gcr.io/acme/base", Path: path}},
+	}
+	resolved, err := injectPublishedBaseImages(ctx, ps, spec)
+	require.NoError(t, err)
+	assert.Equal(t, spec, resolved)
+	assert.Contains(t, out.String(), "Base image gcr.io/acme/base hasn't been published")
+}
+
+func TestInjectPublishedBaseImagesCorrupt(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, &bytes.Buffer{}))
+	ps := NewPipelineState(ctx, 1, fakeClock{})
+
+	path := filepath.Join(t.TempDir(), "base.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+
+	spec := v1alpha1.DockerImageSpec{
+		DockerfileContents: "FROM gcr.io/acme/base:latest\n",
+		BaseImages:         []v1alpha1.DockerImageBaseImage{{Ref: "gcr.io/acme/base", Path: path}},
+	}
+	_, err := injectPublishedBaseImages(ctx, ps, spec)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reading published base image gcr.io/acme/base")
+}
@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/build/moby"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -50,15 +52,17 @@ func clearUIDAndGID(h *tar.Header) {
 	h.Gid = 0
 }
 
+// The Dockerfile gets a fixed timestamp, so that the context only changes
+// when the files do.
+var dockerfileModTime = time.Unix(0, 0)
+
 func (a *ArchiveBuilder) archiveDf(ctx context.Context, df dockerfile.Dockerfile) error {
 	tarHeader := &tar.Header{
-		Name:       "Dockerfile",
-		Typeflag:   tar.TypeReg,
-		Size:       int64(len(df)),
-		Mode:       0644,
-		ModTime:    time.Now(),
-		AccessTime: time.Now(),
-		ChangeTime: time.Now(),
+		Name:     "Dockerfile",
+		Typeflag: tar.TypeReg,
+		Size:     int64(len(df)),
+		Mode:     0644,
+		ModTime:  dockerfileModTime,
 	}
 	clearUIDAndGID(tarHeader)
 	err := a.tw.WriteHeader(tarHeader)
@@ -285,6 +289,29 @@ func (a *ArchiveBuilder) writeEntry(entry archiveEntry) error {
 	return nil
 }
 
+// WriteContext writes the build context that Tilt sends to the Docker daemon
+// for the image: the files in the context directory that pass the filter,
+// followed by the Dockerfile.
+//
+// The output only depends on the files on disk, so it's the same on every
+// call until they change.
+func WriteContext(ctx context.Context, w io.Writer, spec v1alpha1.DockerImageSpec, filter model.PathMatcher) error {
+	var paths []PathMapping
+
+	// Treat context: "-" as an empty context.
+	if spec.Context != "-" {
+		_, err := os.Stat(spec.Context)
+		if err != nil {
+			return fmt.Errorf("reading build context: %v", err)
+		}
+		paths = append(paths, PathMapping{
+			LocalPath:     spec.Context,
+			ContainerPath: "/",
+		})
+	}
+	return tarContextAndUpdateDf(ctx, w, dockerfile.Dockerfile(spec.DockerfileContents), paths, filter)
+}
+
 func tarContextAndUpdateDf(ctx context.Context, writer io.Writer, df dockerfile.Dockerfile, paths []PathMapping, filter model.PathMatcher) error {
 	ab := NewArchiveBuilder(writer, filter)
 	err := ab.ArchivePathsIfExist(ctx, paths)
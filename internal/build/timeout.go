@@ -0,0 +1,83 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How many lines of the stuck step's output a TimeoutError includes.
+const timeoutOutputLines = 50
+
+// TimeoutError indicates that a build was canceled because it ran longer
+// than its timeout.
+type TimeoutError struct {
+	// The build timeout. May be 0 if the error came from a layer that
+	// doesn't know it.
+	Timeout time.Duration
+
+	// The step that was running when the build timed out, as BuildKit names
+	// it (e.g., "[builder 7/12] RUN npm ci"). Empty if Tilt doesn't know,
+	// like for a custom build.
+	Step string
+
+	// The last lines of the step's output.
+	Output []string
+}
+
+func (e TimeoutError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("timed out")
+	if e.Timeout > 0 {
+		fmt.Fprintf(&sb, " after %s", shortDuration(e.Timeout))
+	}
+
+	if e.Step != "" {
+		stage, step := parseStepName(e.Step)
+		if step == "" {
+			fmt.Fprintf(&sb, " during %s", e.Step)
+		} else {
+			instruction := e.Step[stepNameRegexp.FindStringIndex(e.Step)[1]:]
+			fmt.Fprintf(&sb, " during step %s %s", step, instruction)
+			if stage != "" {
+				fmt.Fprintf(&sb, " (stage %s)", stage)
+			}
+		}
+	}
+
+	if len(e.Output) > 0 {
+		fmt.Fprintf(&sb, "\nLast %d lines of output:", len(e.Output))
+		for _, line := range e.Output {
+			sb.WriteString("\n  ")
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// Formats a duration without zero units, e.g., "10m" instead of "10m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// lastLines splits output into lines and returns the last n.
+func lastLines(output string, n int) []string {
+	output = strings.TrimRight(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	if output == "" {
+		return nil
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+var _ error = TimeoutError{}
@@ -0,0 +1,73 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestTimeoutErrorMessage(t *testing.T) {
+	err := TimeoutError{
+		Timeout: 10 * time.Minute,
+		Step:    "[7/12] RUN npm ci",
+		Output:  []string{"npm WARN deprecated", "fetching..."},
+	}
+	assert.Equal(t, "timed out after 10m during step 7/12 RUN npm ci\n"+
+		"Last 2 lines of output:\n"+
+		"  npm WARN deprecated\n"+
+		"  fetching...", err.Error())
+
+	err = TimeoutError{Timeout: 90 * time.Minute, Step: "[builder 3/5] RUN make"}
+	assert.Equal(t, "timed out after 1h30m during step 3/5 RUN make (stage builder)", err.Error())
+
+	err = TimeoutError{Timeout: time.Hour}
+	assert.Equal(t, "timed out after 1h", err.Error())
+}
+
+func TestBuildkitPrinterRunningStep(t *testing.T) {
+	p := newBuildkitPrinter(logger.NewTestLogger(&strings.Builder{}))
+	start := time.Now()
+	end := start.Add(time.Second)
+
+	var output []*vertexLog
+	for i := 0; i < 60; i++ {
+		output = append(output, &vertexLog{vertex: "run", msg: []byte(fmt.Sprintf("line %d\n", i))})
+	}
+
+	err := p.parseAndPrint([]*vertex{
+		{digest: "ctx", name: "[internal] load metadata for docker.io/library/node:20", started: true, startedTime: &start},
+		{digest: "from", name: "[1/3] FROM docker.io/library/node:20", started: true, startedTime: &start, completed: true, completedTime: &end},
+		{digest: "run", name: "[2/3] RUN npm ci", started: true, startedTime: &start},
+		{digest: "copy", name: "[3/3] COPY . .", started: false},
+	}, output, nil)
+	assert.NoError(t, err)
+
+	name, out, ok := p.runningStep()
+	assert.True(t, ok)
+	assert.Equal(t, "[2/3] RUN npm ci", name)
+
+	lines := lastLines(out, timeoutOutputLines)
+	assert.Len(t, lines, timeoutOutputLines)
+	assert.Equal(t, "line 10", lines[0])
+	assert.Equal(t, "line 59", lines[len(lines)-1])
+}
+
+func TestBuildkitPrinterNoRunningStep(t *testing.T) {
+	p := newBuildkitPrinter(logger.NewTestLogger(&strings.Builder{}))
+	start := time.Now()
+	end := start.Add(time.Second)
+
+	err := p.parseAndPrint([]*vertex{
+		{digest: "from", name: "[1/2] FROM docker.io/library/node:20", started: true, startedTime: &start, completed: true, completedTime: &end},
+		{digest: "export", name: "exporting to image", started: true, startedTime: &start},
+	}, nil, nil)
+	assert.NoError(t, err)
+
+	_, _, ok := p.runningStep()
+	assert.False(t, ok)
+}
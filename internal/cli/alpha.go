@@ -19,6 +19,7 @@ The APIs of these commands may change frequently.
 	addCommand(result, newUpdogCmd(streams))
 	addCommand(result, newGetCmd(streams))
 	addCommand(result, newApiresourcesCmd(streams))
+	addCommand(result, newInjectCmd(streams))
 
 	return result
 }
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newAnalyzeCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	result := &cobra.Command{
+		Use:   "analyze",
+		Short: "Analyze how Tilt has been building your project",
+	}
+
+	addCommand(result, newAnalyzeTriggersCmd(streams))
+
+	return result
+}
+
+type analyzeTriggersCmd struct {
+	streams genericclioptions.IOStreams
+	base    xdg.Base
+	sortBy  string
+	limit   int
+	output  string
+}
+
+func newAnalyzeTriggersCmd(streams genericclioptions.IOStreams) *analyzeTriggersCmd {
+	return &analyzeTriggersCmd{
+		streams: streams,
+		base:    xdg.NewTiltDevBase(),
+	}
+}
+
+func (c *analyzeTriggersCmd) name() model.TiltSubcommand { return "analyze-triggers" }
+
+func (c *analyzeTriggersCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "triggers",
+		Short: "Show the changed files that triggered the most builds",
+		Long: `Show the changed files that triggered the most builds.
+
+Tilt records the files that triggered each build, and how long the build took,
+across all sessions. Use this report to find files (like a shared proto or a
+root package.json) that cause a disproportionate amount of rebuilding.
+
+A build triggered by several files counts towards each of them.`,
+		Example: `# Files that triggered the most builds
+tilt analyze triggers
+
+# Files that cost the most build time
+tilt analyze triggers --sort=seconds --limit=10`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().StringVar(&c.sortBy, "sort", string(triggerstats.SortByBuilds),
+		fmt.Sprintf("How to rank files: %q or %q", triggerstats.SortByBuilds, triggerstats.SortBySeconds))
+	cmd.Flags().IntVar(&c.limit, "limit", 20, "The max number of files to show (0 for all)")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+func (c *analyzeTriggersCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.analyze-triggers", nil)
+	defer a.Flush(time.Second)
+
+	sortBy, err := triggerstats.ParseSortBy(c.sortBy)
+	if err != nil {
+		return err
+	}
+	if c.output != "" && c.output != "json" {
+		return fmt.Errorf("invalid output format %q: must be json", c.output)
+	}
+
+	stats, err := triggerstats.Load(c.base)
+	if statefile.IsCorrupt(err) {
+		_, _ = fmt.Fprintf(c.streams.ErrOut, "Warning: %v\n", err)
+	} else if err != nil {
+		return err
+	}
+	report := triggerstats.NewReport(stats, sortBy, c.limit)
+
+	out := c.streams.Out
+	if c.output == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	if len(report.Files) == 0 {
+		_, _ = fmt.Fprintln(out, "No builds triggered by file changes have been recorded yet.")
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "Builds triggered by file changes since %s:\n\n", report.Since.Format(time.RFC1123))
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "FILE\tBUILDS\tBUILD TIME\tLAST BUILD")
+	for _, f := range report.Files {
+		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+			displayPath(f.Path), f.Builds,
+			(time.Duration(f.BuildSeconds * float64(time.Second))).Round(time.Second),
+			f.LastBuild.Format(time.RFC1123))
+	}
+	return w.Flush()
+}
+
+// displayPath shows paths under the working directory relative to it.
+func displayPath(p string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(wd, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return p
+	}
+	return rel
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+func TestAnalyzeTriggers(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	err := triggerstats.Record(base, []triggerstats.Build{
+		{Files: []string{"/src/api.proto"}, StartTime: t0, FinishTime: t0.Add(90 * time.Second)},
+		{Files: []string{"/src/main.go"}, StartTime: t0, FinishTime: t0.Add(5 * time.Second)},
+		{Files: []string{"/src/main.go"}, StartTime: t0, FinishTime: t0.Add(5 * time.Second)},
+	})
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newAnalyzeTriggersCmd(streams)
+	cmd.register()
+	cmd.base = base
+	cmd.sortBy = "seconds"
+	cmd.limit = 1
+
+	err = cmd.run(ctx, nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "since Sun, 01 Jan 2023 12:00:00 UTC")
+	assert.Contains(t, out.String(), "/src/api.proto  1       1m30s")
+	assert.NotContains(t, out.String(), "main.go")
+}
+
+func TestAnalyzeTriggersEmpty(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newAnalyzeTriggersCmd(streams)
+	cmd.register()
+	cmd.base = xdg.FakeBase{Dir: t.TempDir()}
+
+	err := cmd.run(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "No builds triggered by file changes have been recorded yet.")
+}
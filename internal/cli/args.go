@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/kubectl/pkg/cmd/util/editor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
@@ -51,11 +52,17 @@ If no args are explicitly specified, (i.e., just "tilt args"), it opens the curr
 the editor defined by your TILT_EDITOR or EDITOR environment variables, or fall back to
 an OS-appropriate default.
 
+If the Tiltfile fails to load with the new args (e.g., they don't pass
+config.parse()), Tilt goes back to the previous args.
+
 Note that Tiltfile arguments do not affect built-in Tilt args (i.e., the things that show up in "tilt up --help", such as "--legacy", "--port"), and they
 are defined after built-in args, following a "--".`,
 		Example: `# Set new args
 tilt args frontend_service backend_service -- --debug on
 
+# Replace the args, without listing any before the "--"
+tilt args set -- --services=api,db
+
 # Edit the current args
 tilt args
 
@@ -66,9 +73,63 @@ EDITOR=nano tilt args`,
 	addConnectServerFlags(cmd)
 	cmd.Flags().BoolVar(&c.clear, "clear", false, "Clear the Tiltfile args, as if you'd run tilt with no args")
 
+	addCommand(cmd, newArgsSetCmd(c.streams))
+
 	return cmd
 }
 
+type argsSetCmd struct {
+	streams genericclioptions.IOStreams
+}
+
+func newArgsSetCmd(streams genericclioptions.IOStreams) *argsSetCmd {
+	return &argsSetCmd{
+		streams: streams,
+	}
+}
+
+func (c *argsSetCmd) name() model.TiltSubcommand { return "args-set" }
+
+func (c *argsSetCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "set -- <Tiltfile args>",
+		DisableFlagsInUseLine: true,
+		Short:                 "Replaces the Tiltfile args in use by a running Tilt",
+		Long: `Replaces the Tiltfile args in use by a running Tilt.
+
+Tilt re-executes the Tiltfile with the new args, and applies the changes to
+your resources the same way it would for an edit to the Tiltfile. Resources
+that didn't change keep running, with their build caches intact.
+
+If the Tiltfile fails to load with the new args (e.g., they don't pass
+config.parse()), Tilt goes back to the previous args.`,
+		Example: `tilt args set -- --services=api,db`,
+		Args:    cobra.MinimumNArgs(1),
+	}
+
+	addConnectServerFlags(cmd)
+
+	return cmd
+}
+
+func (c *argsSetCmd) run(ctx context.Context, args []string) error {
+	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), c.streams.ErrOut))
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var tf v1alpha1.Tiltfile
+	err = ctrlclient.Get(ctx, types.NamespacedName{Name: model.MainTiltfileManifestName.String()}, &tf)
+	if err != nil {
+		return err
+	}
+
+	tags := engineanalytics.CmdTags{"set": "true"}
+	return updateTiltfileArgs(ctx, ctrlclient, &tf, args, tags)
+}
+
 func parseEditResult(b []byte) ([]string, error) {
 	sc := bufio.NewScanner(bytes.NewReader(b))
 	var argsLine *string
@@ -132,6 +193,10 @@ func (c *argsCmd) run(ctx context.Context, args []string) error {
 		tags["set"] = "true"
 	}
 
+	return updateTiltfileArgs(ctx, ctrlclient, &tf, args, tags)
+}
+
+func updateTiltfileArgs(ctx context.Context, ctrlclient client.Client, tf *v1alpha1.Tiltfile, args []string, tags engineanalytics.CmdTags) error {
 	a := analytics.Get(ctx)
 	a.Incr("cmd.args", tags.AsMap())
 	defer a.Flush(time.Second)
@@ -140,14 +205,15 @@ func (c *argsCmd) run(ctx context.Context, args []string) error {
 		logger.Get(ctx).Infof("Tilt is already running with those args -- no action taken")
 		return nil
 	}
+	prevArgs := tf.Spec.Args
 	tf.Spec.Args = args
 
-	err = ctrlclient.Update(ctx, &tf)
+	err := ctrlclient.Update(ctx, tf)
 	if err != nil {
 		return err
 	}
 
-	logger.Get(ctx).Infof("Changed config args for Tilt running at %s to %v", apiHost(), args)
+	logger.Get(ctx).Infof("Changed config args for Tilt running at %s from %v to %v", apiHost(), prevArgs, args)
 
 	return nil
 }
@@ -55,6 +55,34 @@ func TestArgsNewValue(t *testing.T) {
 
 }
 
+func TestArgsSet(t *testing.T) {
+	f := newServerFixture(t)
+
+	createTiltfile(f, []string{"--services=api,web"})
+
+	cmd := newArgsSetCmd(genericclioptions.NewTestIOStreamsDiscard())
+	c := cmd.register()
+	err := c.Flags().Parse([]string{"--", "--services=api,db"})
+	require.NoError(t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"--services=api,db"}, getTiltfile(f).Spec.Args)
+	require.Equal(t, []analytics.CountEvent{
+		{Name: "cmd.args", Tags: map[string]string{"set": "true"}, N: 1},
+	}, f.analytics.Counts)
+}
+
+func TestArgsSetIsSubcommand(t *testing.T) {
+	cmd := newArgsCmd(genericclioptions.NewTestIOStreamsDiscard())
+	c := cmd.register()
+
+	sub, args, err := c.Find([]string{"set", "--", "--services=api"})
+	require.NoError(t, err)
+	require.Equal(t, "set", sub.Name())
+	require.Equal(t, []string{"--", "--services=api"}, args)
+}
+
 func TestArgsClearAndNewValue(t *testing.T) {
 	f := newServerFixture(t)
 
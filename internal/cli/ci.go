@@ -69,6 +69,8 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 
 	log.SetFlags(log.Flags() &^ (log.Ldate | log.Ltime))
 
+	reclaimPorts(ctx)
+
 	webHost := provideWebHost()
 	webURL, _ := provideWebURL(webHost, provideWebPort())
 	startLine := prompt.StartStatusLine(webURL, webHost)
@@ -94,6 +96,8 @@ func (c *ciCmd) run(ctx context.Context, args []string) error {
 		defer cmdCIDeps.Snapshotter.WriteSnapshot(ctx, c.outputSnapshotOnExit)
 	}
 
+	openProcessRegistry(ctx, cmdCIDeps.LocalEnv, cmdCIDeps.Base, cmdCIDeps.APIServerName, cmdCIDeps.APIServerPort)
+
 	err = upper.Start(ctx, args, cmdCIDeps.TiltBuild,
 		c.fileName, store.TerminalModeStream, a.UserOpt(), cmdCIDeps.Token,
 		string(cmdCIDeps.CloudAddress))
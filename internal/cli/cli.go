@@ -68,6 +68,7 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	addCommand(rootCmd, newDescribeCmd(streams))
 	addCommand(rootCmd, newGetCmd(streams))
 	addCommand(rootCmd, newExplainCmd(streams))
+	addCommand(rootCmd, newExplainPathCmd(streams))
 	addCommand(rootCmd, newEditCmd(streams))
 	addCommand(rootCmd, newApiresourcesCmd(streams))
 	addCommand(rootCmd, newDeleteCmd(streams))
@@ -79,8 +80,14 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	addCommand(rootCmd, newEnableCmd())
 	addCommand(rootCmd, newDisableCmd())
 	addCommand(rootCmd, newTriggerCmd(streams))
+	addCommand(rootCmd, newResetCmd(streams))
+	addCommand(rootCmd, newRefreshConnectionsCmd(streams))
+	addCommand(rootCmd, newExportCmd(streams))
+	addCommand(rootCmd, newStatusCmd(streams))
+	addCommand(rootCmd, newFsckCmd(streams))
 
 	rootCmd.AddCommand(analytics.NewCommand())
+	rootCmd.AddCommand(newAnalyzeCmd(streams))
 	rootCmd.AddCommand(newDumpCmd(rootCmd, streams))
 	rootCmd.AddCommand(newAlphaCmd(streams))
 	rootCmd.AddCommand(newLspCmd())
@@ -90,6 +97,10 @@ up-to-date in real-time. Think 'docker build && kubectl apply' or 'docker-compos
 	globalFlags.BoolVarP(&debug, "debug", "d", false, "Enable debug logging")
 	globalFlags.BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
 	globalFlags.IntVar(&klogLevel, "klog", 0, "Enable Kubernetes API logging. Uses klog v-levels (0-4 are debug logs, 5-9 are tracing logs)")
+	globalFlags.StringVar(&sandboxLocalCommands, "sandbox-local-commands", "",
+		"Check commands that the Tiltfile runs with local() before they run. Values: deny (fail to load), prompt (ask for approval), allow (run, and record in an audit log)")
+	globalFlags.BoolVar(&sandboxLocalResources, "sandbox-local-resources", false,
+		"Also check the cmd and serve_cmd of each local_resource. Only applies with --sandbox-local-commands")
 
 	ctx, cleanup := createContext()
 	defer cleanup()
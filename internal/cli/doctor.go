@@ -12,6 +12,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
@@ -108,7 +109,7 @@ func (c *doctorCmd) run(ctx context.Context, args []string) error {
 	if localDockerErr == nil {
 		composeEnv = docker.LocalEnv(localDocker.Env())
 	}
-	dcCli := dockercompose.NewDockerComposeClient(composeEnv)
+	dcCli := dockercompose.NewDockerComposeClient(composeEnv, localexec.EmptyEnv())
 	// errors getting the version aren't generally useful; in many cases it'll just mean that
 	// the command couldn't exec since Docker Compose isn't installed, for example, so they
 	// are just ignored and the field skipped
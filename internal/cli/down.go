@@ -227,7 +227,7 @@ func deleteK8sEntities(ctx context.Context, manifests []model.Manifest, updateSe
 }
 
 func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, error) {
-	var allEntities []k8s.K8sEntity
+	var allEntities, crds []k8s.K8sEntity
 	var deleteCmds []model.Cmd
 	for _, m := range manifests {
 		if !m.IsK8s() {
@@ -246,8 +246,17 @@ func k8sToDelete(manifests ...model.Manifest) ([]k8s.K8sEntity, []model.Cmd, err
 			if err != nil {
 				return nil, nil, err
 			}
-			allEntities = append(allEntities, k8s.ReverseSortedEntities(entities)...)
+			for _, e := range k8s.ReverseSortedEntities(entities) {
+				if e.GVK().Kind == "CustomResourceDefinition" {
+					crds = append(crds, e)
+				} else {
+					allEntities = append(allEntities, e)
+				}
+			}
 		}
 	}
-	return allEntities, deleteCmds, nil
+
+	// Delete CRDs last, in case the custom resources of that kind
+	// are in a different resource.
+	return append(allEntities, crds...), deleteCmds, nil
 }
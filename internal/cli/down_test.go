@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
@@ -105,6 +106,24 @@ func TestDownDeletesEntitiesInReverseOrder(t *testing.T) {
 	require.Equal(t, "Namespace", entities[1].GVK().Kind)
 }
 
+func TestDownDeletesCRDsLast(t *testing.T) {
+	f := newDownFixture(t)
+
+	crdYAML, crYAML, ok := strings.Cut(testyaml.CRDYAML, "---")
+	require.True(t, ok)
+	f.tfl.Result = newTiltfileLoadResult(
+		model.Manifest{Name: "project"}.WithDeployTarget(k8s.MustTarget("project", crYAML)),
+		model.Manifest{Name: "crd"}.WithDeployTarget(k8s.MustTarget("crd", crdYAML)))
+	err := f.cmd.down(f.ctx, f.deps, nil)
+	require.NoError(t, err)
+
+	entities, err := k8s.ParseYAMLFromString(f.kCli.DeletedYaml)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(entities))
+	require.Equal(t, "Project", entities[0].GVK().Kind)
+	require.Equal(t, "CustomResourceDefinition", entities[1].GVK().Kind)
+}
+
 func TestDownDeletesInDependentOrder(t *testing.T) {
 	f := newDownFixture(t)
 
@@ -7,15 +7,20 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -39,6 +44,8 @@ and may change frequently.
 	result.AddCommand(newDumpLogStoreCmd())
 	result.AddCommand(newDumpCliDocsCmd(rootCmd))
 	result.AddCommand(newDumpImageDeployRefCmd())
+	result.AddCommand(newDumpTiltfileDepsCmd())
+	result.AddCommand(newDumpContextCmd())
 	addCommand(result, newOpenapiCmd(streams))
 
 	return result
@@ -205,6 +212,55 @@ func dumpImageDeployRef(cmd *cobra.Command, args []string) {
 	fmt.Printf("%s", container.FamiliarString(ref))
 }
 
+func newDumpTiltfileDepsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tiltfile-deps",
+		Short: "dump the files that trigger a Tiltfile reload",
+		Long: `Dumps the files that the running Tilt watches to decide when to reload
+the main Tiltfile, one per line.
+
+This includes the Tiltfile itself, every file it load()s (including
+extensions), and every file it reads.
+`,
+		Run:  dumpTiltfileDeps,
+		Args: cobra.NoArgs,
+	}
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func dumpTiltfileDeps(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump tiltfile-deps: %v", err))
+	}
+
+	err = writeTiltfileDeps(ctx, ctrlclient, os.Stdout)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump tiltfile-deps: %v", err))
+	}
+}
+
+func writeTiltfileDeps(ctx context.Context, ctrlclient client.Client, w io.Writer) error {
+	id := fmt.Sprintf("%s:%s", model.TargetTypeConfigs, model.MainTiltfileManifestName)
+	var fw v1alpha1.FileWatch
+	err := ctrlclient.Get(ctx, types.NamespacedName{Name: apis.SanitizeName(id)}, &fw)
+	if err != nil {
+		return err
+	}
+
+	paths := append([]string{}, fw.Spec.WatchedPaths...)
+	sort.Strings(paths)
+	for _, p := range paths {
+		_, err := fmt.Fprintln(w, p)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func dumpWebview(cmd *cobra.Command, args []string) {
 	body := apiGet("view")
 
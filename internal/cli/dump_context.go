@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+type dumpContextCmd struct {
+	out  string
+	list bool
+}
+
+func newDumpContextCmd() *cobra.Command {
+	c := &dumpContextCmd{}
+	cmd := &cobra.Command{
+		Use:   "context IMAGE_REF",
+		Short: "Dump the build context that Tilt sends for an image",
+		Long: `Dumps the build context that the running Tilt would send to Docker
+for the given docker_build() image, as a tar.
+
+The context has the only=, ignore=, and .dockerignore filters applied,
+and the Dockerfile that Tilt builds with. It's useful when a build behaves
+differently under Tilt than under 'docker build'.
+
+With BuildKit, Tilt streams the same files instead of sending a tar.
+`,
+		Example: `tilt dump context my-app --out ctx.tar
+tilt dump context my-app --list`,
+		Args: cobra.ExactArgs(1),
+		Run:  c.run,
+	}
+	cmd.Flags().StringVarP(&c.out, "out", "o", "", "File to write the tar to. Defaults to stdout.")
+	cmd.Flags().BoolVar(&c.list, "list", false, "Print the files in the context with their sizes, instead of the tar")
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *dumpContextCmd) run(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump context: %v", err))
+	}
+
+	var w io.Writer = os.Stdout
+	if c.list {
+		pr, pw := io.Pipe()
+		go func() {
+			_ = pw.CloseWithError(writeBuildContext(ctx, ctrlclient, args[0], pw))
+		}()
+		err = listBuildContext(pr, os.Stdout)
+		if err != nil {
+			cmdFail(fmt.Errorf("dump context: %v", err))
+		}
+		return
+	}
+
+	if c.out != "" {
+		f, err := os.Create(c.out)
+		if err != nil {
+			cmdFail(fmt.Errorf("dump context: %v", err))
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+		w = f
+	}
+
+	err = writeBuildContext(ctx, ctrlclient, args[0], w)
+	if err != nil {
+		cmdFail(fmt.Errorf("dump context: %v", err))
+	}
+}
+
+// writeBuildContext writes the context tar for the image with the given ref.
+func writeBuildContext(ctx context.Context, ctrlclient client.Client, ref string, w io.Writer) error {
+	di, err := findDockerImage(ctx, ctrlclient, ref)
+	if err != nil {
+		return err
+	}
+
+	imageMaps := make(map[types.NamespacedName]*v1alpha1.ImageMap)
+	for _, name := range di.Spec.ImageMaps {
+		var im v1alpha1.ImageMap
+		nn := types.NamespacedName{Name: name}
+		err := ctrlclient.Get(ctx, nn, &im)
+		if err != nil {
+			return err
+		}
+		imageMaps[nn] = &im
+	}
+
+	spec, err := build.InjectImageDependencies(di.Spec, imageMaps)
+	if err != nil {
+		return err
+	}
+
+	return build.WriteContext(ctx, w, spec, ignore.CreateBuildContextFilter(spec.ContextIgnores))
+}
+
+func findDockerImage(ctx context.Context, ctrlclient client.Client, ref string) (*v1alpha1.DockerImage, error) {
+	named, err := container.ParseNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var list v1alpha1.DockerImageList
+	err = ctrlclient.List(ctx, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, di := range list.Items {
+		diNamed, err := container.ParseNamed(di.Spec.Ref)
+		if err != nil {
+			continue
+		}
+		if diNamed.String() == named.String() {
+			return di.DeepCopy(), nil
+		}
+	}
+	return nil, fmt.Errorf("no docker_build() image %q", ref)
+}
+
+// listBuildContext prints each file in a context tar with its size, then
+// the total.
+func listBuildContext(r io.Reader, w io.Writer) error {
+	tr := tar.NewReader(r)
+	count := 0
+	var total int64
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		count++
+		total += h.Size
+		_, err = fmt.Fprintf(w, "%10s  %s\n", units.HumanSize(float64(h.Size)), h.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d files, %s\n", count, units.HumanSize(float64(total)))
+	return err
+}
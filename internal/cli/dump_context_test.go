@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestDumpContext(t *testing.T) {
+	ctx := context.Background()
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("main.go", "package main")
+	f.WriteFile("tmp/cache", "cache")
+
+	cli := fake.NewFakeTiltClient()
+	err := cli.Create(ctx, &v1alpha1.DockerImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-app"},
+		Spec: v1alpha1.DockerImageSpec{
+			Ref:                "my-app",
+			DockerfileContents: "FROM alpine",
+			Context:            f.Path(),
+			ContextIgnores: []v1alpha1.IgnoreDef{
+				{BasePath: f.JoinPath("tmp")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	var tarBuf bytes.Buffer
+	err = writeBuildContext(ctx, cli, "docker.io/library/my-app", &tarBuf)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	err = listBuildContext(&tarBuf, &out)
+	require.NoError(t, err)
+	assert.Equal(t, `       12B  main.go
+       11B  Dockerfile
+2 files, 23B
+`, out.String())
+}
+
+func TestDumpContextNotFound(t *testing.T) {
+	ctx := context.Background()
+	cli := fake.NewFakeTiltClient()
+
+	err := writeBuildContext(ctx, cli, "my-app", &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no docker_build() image "my-app"`)
+}
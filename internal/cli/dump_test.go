@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestDumpTiltfileDeps(t *testing.T) {
+	ctx := context.Background()
+	cli := fake.NewFakeTiltClient()
+	err := cli.Create(ctx, &v1alpha1.FileWatch{
+		ObjectMeta: metav1.ObjectMeta{Name: "configs:(Tiltfile)"},
+		Spec: v1alpha1.FileWatchSpec{
+			WatchedPaths: []string{
+				"/src/Tiltfile",
+				"/src/.tiltignore",
+				"/src/tilt_extensions/helpers/Tiltfile",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	out := bytes.NewBuffer(nil)
+	err = writeTiltfileDeps(ctx, cli, out)
+	require.NoError(t, err)
+	assert.Equal(t, `/src/.tiltignore
+/src/Tiltfile
+/src/tilt_extensions/helpers/Tiltfile
+`, out.String())
+}
+
+func TestDumpTiltfileDepsNotFound(t *testing.T) {
+	ctx := context.Background()
+	cli := fake.NewFakeTiltClient()
+
+	err := writeTiltfileDeps(ctx, cli, bytes.NewBuffer(nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
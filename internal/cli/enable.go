@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -129,6 +130,11 @@ func changeEnabledResources(
 			return fmt.Errorf("%s cannot be enabled or disabled", name)
 		}
 		selectedResourcesByName[name] = true
+
+		// selecting a resource group selects its members
+		for _, member := range uiresource.GroupMembers(&uir) {
+			selectedResourcesByName[member] = true
+		}
 	}
 
 	for _, uir := range uirs.Items {
@@ -137,6 +143,12 @@ func changeEnabledResources(
 			continue
 		}
 
+		// a resource group's disable sources are its members' sources, so
+		// enabling or disabling its members is enough
+		if len(uiresource.GroupMembers(&uir)) > 0 {
+			continue
+		}
+
 		var enable bool
 		if selectedResourcesByName[uir.Name] {
 			enable = opts.enable
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -95,6 +96,19 @@ func TestEnable(t *testing.T) {
 	}
 }
 
+func TestEnableResourceGroup(t *testing.T) {
+	f := newEnableFixture(t)
+	f.createResources()
+	f.createResourceGroup("grp", "disabled_a", "disabled_b")
+
+	cmd := enableCmd{}
+	c := cmd.register()
+	require.NoError(t, c.Flags().Parse([]string{"--only", "grp"}))
+	require.NoError(t, cmd.run(f.ctx, c.Flags().Args()))
+
+	require.ElementsMatch(t, []string{"disabled_a", "disabled_b", "grp", "(Tiltfile)"}, f.enabledResources())
+}
+
 type enableFixture struct {
 	*serverFixture
 }
@@ -139,6 +153,20 @@ func (f enableFixture) createResources() {
 	require.NoError(f.T(), err)
 }
 
+// makes a resource group with the given members, which must already exist
+func (f enableFixture) createResourceGroup(name string, members ...string) {
+	uir := uiresourcebuilder.New(name).Build()
+	for _, m := range members {
+		var member v1alpha1.UIResource
+		err := f.client.Get(f.ctx, types.NamespacedName{Name: m}, &member)
+		require.NoError(f.T(), err)
+		uir.Status.DisableStatus.Sources = append(uir.Status.DisableStatus.Sources, member.Status.DisableStatus.Sources...)
+	}
+	uir.Annotations = map[string]string{uiresource.AnnotationGroupMembers: strings.Join(members, ",")}
+	err := f.client.Create(f.ctx, uir)
+	require.NoError(f.T(), err)
+}
+
 func (f enableFixture) enabledResources() []string {
 	var result []string
 
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type explainPathCmd struct {
+	streams    genericclioptions.IOStreams
+	dockerfile string
+	context    string
+}
+
+func newExplainPathCmd(streams genericclioptions.IOStreams) *explainPathCmd {
+	return &explainPathCmd{
+		streams: streams,
+	}
+}
+
+func (c *explainPathCmd) name() model.TiltSubcommand { return "explain-path" }
+
+func (c *explainPathCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "explain-path PATH",
+		Short: "Show which Dockerfile steps re-run when a file changes",
+		Long: `Show which Dockerfile steps re-run when a file changes.
+
+Finds the first COPY or ADD in each stage that includes the file, and lists
+every step in the stage from that point on, since those steps will miss the
+build cache.`,
+		Example: `# Which steps re-run when go.mod changes?
+tilt explain-path go.mod
+
+# Use a different Dockerfile and build context
+tilt explain-path --dockerfile=Dockerfile.api --context=api api/go.mod`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	cmd.Flags().StringVarP(&c.dockerfile, "dockerfile", "f", "Dockerfile", "Path to the Dockerfile")
+	cmd.Flags().StringVar(&c.context, "context", ".", "Path to the build context")
+
+	return cmd
+}
+
+func (c *explainPathCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.explain-path", nil)
+	defer a.Flush(time.Second)
+
+	contents, err := os.ReadFile(c.dockerfile)
+	if err != nil {
+		return fmt.Errorf("reading dockerfile: %v", err)
+	}
+
+	ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(contents))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %v", c.dockerfile, err)
+	}
+
+	contextPath, err := filepath.Abs(c.context)
+	if err != nil {
+		return err
+	}
+	filePath, err := filepath.Abs(args[0])
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(contextPath, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("%s is not inside the build context %s", args[0], c.context)
+	}
+
+	steps, err := ast.CacheImpact(filepath.ToSlash(rel))
+	if err != nil {
+		return err
+	}
+
+	out := c.streams.Out
+	dfName := filepath.Base(c.dockerfile)
+	_, _ = fmt.Fprintf(out, "changing %s re-runs %d of %d steps in %s\n",
+		args[0], len(steps), ast.StepCount(), dfName)
+	for _, step := range steps {
+		marker := " "
+		if step.Trigger {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(out, "%s %s:%d: %s\n", marker, dfName, step.Line, step.Original)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestExplainPath(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("Dockerfile.api", `
+FROM golang:1.21
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN go build ./...
+`)
+	f.WriteFile("go.mod", "module example.com/api")
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newExplainPathCmd(streams)
+	cmd.register()
+	cmd.dockerfile = f.JoinPath("Dockerfile.api")
+	cmd.context = f.Path()
+
+	err := cmd.run(ctx, []string{f.JoinPath("go.mod")})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "re-runs 4 of 6 steps in Dockerfile.api")
+	assert.Contains(t, out.String(), "* Dockerfile.api:4: COPY go.mod go.sum ./")
+	assert.Contains(t, out.String(), "  Dockerfile.api:7: RUN go build ./...")
+}
+
+func TestExplainPathOutsideContext(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.WriteFile("api/Dockerfile", "FROM alpine\nCOPY . .\n")
+	f.WriteFile("web/index.html", "hi")
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := newExplainPathCmd(streams)
+	cmd.register()
+	cmd.dockerfile = f.JoinPath("api", "Dockerfile")
+	cmd.context = f.JoinPath("api")
+
+	err := cmd.run(ctx, []string{f.JoinPath("web", "index.html")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not inside the build context")
+}
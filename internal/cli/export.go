@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	analytics2 "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type exportCmd struct {
+	streams genericclioptions.IOStreams
+
+	out string
+}
+
+var _ tiltCmd = &exportCmd{}
+
+func newExportCmd(streams genericclioptions.IOStreams) *exportCmd {
+	return &exportCmd{
+		streams: streams,
+	}
+}
+
+func (c *exportCmd) name() model.TiltSubcommand { return "export" }
+
+func (c *exportCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export RESOURCE_NAME [--out bundle.tgz]",
+		Short: "Export the effective configuration of a resource as a bundle",
+		Long: `Export the effective configuration of a resource, as the running Tilt
+sees it, to a gzipped tar.
+
+The bundle has everything you need to reproduce the resource's build and
+deploy outside of Tilt, or to attach to a bug report:
+
+  - the Dockerfile of each image, with the images it depends on injected
+  - the files in each build context after filtering, with their hashes
+  - the build args and other settings of each image
+  - the live_update config
+  - the YAML that Tilt applied, with the images it built injected
+  - the most recent builds, with their logs
+
+Secrets are scrubbed from every file. manifest.json at the root of the
+bundle describes the rest, and has a schema version for tools that read it.
+
+The resource page in the web UI downloads the same bundle.
+`,
+		Example: `tilt export frontend
+tilt export frontend --out /tmp/frontend.tgz`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	addConnectServerFlags(cmd)
+	cmd.Flags().StringVarP(&c.out, "out", "o", "",
+		"File to write the bundle to. Defaults to RESOURCE_NAME-bundle.tgz")
+	return cmd
+}
+
+func (c *exportCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.export", analytics2.CmdTags{})
+	defer a.Flush(time.Second)
+
+	resource := args[0]
+	out := c.out
+	if out == "" {
+		out = fmt.Sprintf("%s-bundle.tgz", strings.ReplaceAll(resource, "/", "_"))
+	}
+
+	u := apiURL(fmt.Sprintf("export?resource=%s", url.QueryEscape(resource)))
+	res, err := http.Get(u)
+	if err != nil {
+		return fmt.Errorf("Could not connect to Tilt at %s: %v", u, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(res.Body)
+		return fmt.Errorf("(%d): %s", res.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, res.Body)
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "error reading response from tilt api")
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.streams.Out, "Exported resource %q to %s\n", resource, out)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+)
+
+func TestExportDefaultOut(t *testing.T) {
+	f := newExportFixture(t)
+	out, err := f.run("fe")
+	require.NoError(t, err)
+
+	require.Equal(t, "Exported resource \"fe\" to fe-bundle.tgz\n", out)
+	require.Equal(t, "fe", f.resource)
+	b, err := os.ReadFile(filepath.Join(f.dir.Path(), "fe-bundle.tgz"))
+	require.NoError(t, err)
+	require.Equal(t, "bundle", string(b))
+}
+
+func TestExportOut(t *testing.T) {
+	f := newExportFixture(t)
+	out, err := f.run("fe", "--out", "bundles/fe.tgz")
+	require.Error(t, err)
+	require.Equal(t, "", out)
+
+	f.dir.MkdirAll("bundles")
+	out, err = f.run("fe", "--out", "bundles/fe.tgz")
+	require.NoError(t, err)
+	require.Equal(t, "Exported resource \"fe\" to bundles/fe.tgz\n", out)
+	b, err := os.ReadFile(filepath.Join(f.dir.Path(), "bundles", "fe.tgz"))
+	require.NoError(t, err)
+	require.Equal(t, "bundle", string(b))
+}
+
+func TestExportNotFound(t *testing.T) {
+	f := newExportFixture(t)
+	f.responseBody = "resource \"fe\" not found"
+	f.responseStatus = http.StatusNotFound
+	out, err := f.run("fe")
+	require.EqualError(t, err, "(404): resource \"fe\" not found")
+	require.Equal(t, "", out)
+
+	_, err = os.Stat(filepath.Join(f.dir.Path(), "fe-bundle.tgz"))
+	require.True(t, os.IsNotExist(err))
+}
+
+type exportFixture struct {
+	t              *testing.T
+	ctx            context.Context
+	dir            *tempdir.TempDirFixture
+	resource       string
+	responseBody   string
+	responseStatus int
+}
+
+func newExportFixture(t *testing.T) *exportFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	dir := tempdir.NewTempDirFixture(t)
+	dir.Chdir()
+
+	f := &exportFixture{
+		t:              t,
+		ctx:            ctx,
+		dir:            dir,
+		responseBody:   "bundle",
+		responseStatus: http.StatusOK,
+	}
+
+	l, port := listenOnFreePort(t)
+	origPort := defaultWebPort
+	defaultWebPort = port
+	t.Cleanup(func() {
+		defaultWebPort = origPort
+	})
+
+	mux := &http.ServeMux{}
+	mux.HandleFunc("/api/export", func(w http.ResponseWriter, req *http.Request) {
+		f.resource = req.URL.Query().Get("resource")
+		w.WriteHeader(f.responseStatus)
+		_, _ = w.Write([]byte(f.responseBody))
+	})
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", defaultWebPort),
+		Handler: mux,
+	}
+
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(func() {
+		_ = srv.Shutdown(ctx)
+	})
+
+	return f
+}
+
+func (f *exportFixture) run(args ...string) (string, error) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newExportCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse(args)
+	require.NoError(f.t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	return out.String(), err
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -77,6 +79,25 @@ func addNamespaceFlag(cmd *cobra.Command) {
 
 var kubeContextOverride string
 
+var sandboxLocalCommands = ""
+var sandboxLocalResources = false
+
+func provideSandboxConfig() (sandbox.Config, error) {
+	mode, err := sandbox.ParseMode(sandboxLocalCommands)
+	if err != nil {
+		return sandbox.Config{}, errors.Wrap(err, "--sandbox-local-commands")
+	}
+	return sandbox.Config{Mode: mode, IncludeLocalResources: sandboxLocalResources}, nil
+}
+
+// Resolved once by the up command, from --offline or by checking for a
+// network route, so that every component sees the same mode.
+var offlineMode offline.Mode
+
+func provideOfflineMode() offline.Mode {
+	return offlineMode
+}
+
 func ProvideKubeContextOverride() k8s.KubeContextOverride {
 	return k8s.KubeContextOverride(kubeContextOverride)
 }
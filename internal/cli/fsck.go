@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/fsck"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type fsckCmd struct {
+	streams genericclioptions.IOStreams
+	base    xdg.Base
+	dir     *dirs.TiltDevDir
+	repair  bool
+	output  string
+}
+
+func newFsckCmd(streams genericclioptions.IOStreams) *fsckCmd {
+	return &fsckCmd{
+		streams: streams,
+		base:    xdg.NewTiltDevBase(),
+	}
+}
+
+func (c *fsckCmd) name() model.TiltSubcommand { return "fsck" }
+
+func (c *fsckCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fsck",
+		Short: "Check the state that Tilt keeps on disk for corrupt files",
+		Long: `Check the state that Tilt keeps on disk for corrupt files.
+
+Checks the build trigger stats, the local command approvals and audit log,
+the extension cache, the apiserver certs, the token, and the API configs.
+Reports files that can't be read, and files left behind by interrupted
+writes or downloads.
+
+With --repair, corrupt state is moved aside (with a .corrupt-<time> suffix),
+so that Tilt starts over with defaults, and broken caches are removed, so
+that Tilt rebuilds them.
+
+Tilt also moves corrupt state aside on its own when it reads it, so a
+corrupt file never stops 'tilt up'. Moved-aside files are listed, but never
+deleted; delete them once you've looked at them.`,
+		Example: `# Check for problems
+tilt fsck
+
+# Fix them
+tilt fsck --repair`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().BoolVar(&c.repair, "repair", false, "Quarantine corrupt files and remove broken caches")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+func (c *fsckCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.fsck", map[string]string{"repair": fmt.Sprintf("%v", c.repair)})
+	defer a.Flush(time.Second)
+
+	if c.output != "" && c.output != "json" {
+		return fmt.Errorf("invalid output format %q: must be json", c.output)
+	}
+
+	dir := c.dir
+	if dir == nil {
+		var err error
+		dir, err = dirs.UseTiltDevDir()
+		if err != nil {
+			return err
+		}
+	}
+
+	problems, err := fsck.NewChecker(c.base, dir).Check()
+	if err != nil {
+		return err
+	}
+
+	unfixed := 0
+	repaired := make([]string, len(problems))
+	for i, p := range problems {
+		if p.Kind == fsck.KindQuarantined {
+			continue
+		}
+		if !c.repair || p.Fix == fsck.FixNone {
+			unfixed++
+			continue
+		}
+		repaired[i], err = fsck.Repair(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.output == "json" {
+		encoder := json.NewEncoder(c.streams.Out)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(problems)
+		if err != nil {
+			return err
+		}
+	} else {
+		err = c.print(problems, repaired)
+		if err != nil {
+			return err
+		}
+	}
+
+	if unfixed > 0 {
+		if c.repair {
+			return fmt.Errorf("%d problem(s) could not be repaired", unfixed)
+		}
+		return fmt.Errorf("found %d problem(s); run 'tilt fsck --repair' to fix them", unfixed)
+	}
+	return nil
+}
+
+func (c *fsckCmd) print(problems []fsck.Problem, repaired []string) error {
+	out := c.streams.Out
+	if len(problems) == 0 {
+		_, _ = fmt.Fprintln(out, "No problems found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STORE\tKIND\tPATH\tPROBLEM")
+	for i, p := range problems {
+		msg := p.Message
+		if repaired[i] != "" {
+			msg = fmt.Sprintf("%s: %s", msg, repaired[i])
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Store, p.Kind, p.Path, msg)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
+	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+func TestFsckRepair(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	p, err := triggerstats.StatsPath(base)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(p, []byte(`{"files":`), 0644))
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newFsckCmd(streams)
+	cmd.register()
+	cmd.base = base
+	cmd.dir = dirs.NewTiltDevDirAt(t.TempDir())
+
+	err = cmd.run(ctx, nil)
+	require.EqualError(t, err, "found 1 problem(s); run 'tilt fsck --repair' to fix them")
+	assert.Contains(t, out.String(), "build history  corrupt")
+
+	out.Reset()
+	cmd.repair = true
+	err = cmd.run(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "moved to "+p+".corrupt-")
+
+	out.Reset()
+	cmd.repair = false
+	err = cmd.run(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "build history  quarantined")
+}
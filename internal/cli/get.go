@@ -56,6 +56,14 @@ func (c *getCmd) register() *cobra.Command {
 		Use:                   "get TYPE [NAME | -l label]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Display one or many resources",
+		Example: `# List all resources, with their API version, as JSON
+tilt get uiresources -o json
+
+# Print the status of each resource
+tilt get uiresources -o custom-columns=NAME:.metadata.name,RUNTIME:.status.runtimeStatus,UPDATE:.status.updateStatus
+
+# Print a single field
+tilt get uiresource my-app -o jsonpath='{.status.updateStatus}'`,
 	}
 	c.cmd = cmd
 	o := c.options
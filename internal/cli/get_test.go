@@ -47,6 +47,42 @@ func TestGet(t *testing.T) {
 my-sleep`)
 }
 
+func TestGetOutputFormats(t *testing.T) {
+	f := newServerFixture(t)
+
+	err := f.client.Create(f.ctx, &v1alpha1.Cmd{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-sleep"},
+		Spec: v1alpha1.CmdSpec{
+			Args: []string{"sleep", "1"},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		output   string
+		expected string
+	}{
+		{"json", `"apiVersion": "tilt.dev/v1alpha1",
+    "kind": "Cmd",`},
+		{"yaml", `apiVersion: tilt.dev/v1alpha1
+kind: Cmd`},
+		{"jsonpath={.spec.args}", `["sleep","1"]`},
+		{"custom-columns=NAME:.metadata.name,ARGS:.spec.args", `NAME       ARGS
+my-sleep   [sleep 1]`},
+	} {
+		t.Run(tc.output, func(t *testing.T) {
+			streams, _, out, _ := genericclioptions.NewTestIOStreams()
+			get := newGetCmd(streams)
+			cmd := get.register()
+			require.NoError(t, cmd.Flags().Set("output", tc.output))
+
+			err := get.run(f.ctx, []string{"cmd", "my-sleep"})
+			require.NoError(t, err)
+			assert.Contains(t, out.String(), tc.expected)
+		})
+	}
+}
+
 type serverFixture struct {
 	*tempdir.TempDirFixture
 	ctx       context.Context
@@ -0,0 +1,316 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/container"
+	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A digest that's obviously fake, for when the user doesn't supply one.
+var fakeInjectDigest = digest.Digest("sha256:" + strings.Repeat("0", 64))
+
+type injectCmd struct {
+	streams genericclioptions.IOStreams
+
+	fileName string
+	dryRun   bool
+	digest   string
+	output   string
+}
+
+var _ tiltCmd = &injectCmd{}
+
+func newInjectCmd(streams genericclioptions.IOStreams) *injectCmd {
+	return &injectCmd{
+		streams: streams,
+	}
+}
+
+func (c *injectCmd) name() model.TiltSubcommand { return "inject" }
+
+func (c *injectCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inject IMAGE --dry-run",
+		Short: "Show what Tilt would rewrite when it injects a built image",
+		Long: `Show what Tilt would rewrite when it injects a built image.
+
+Loads the Tiltfile, pretends that IMAGE was built with the given digest,
+and prints what Tilt would rewrite to use it: the lines of the Dockerfiles
+of images built on top of IMAGE, and the fields of the Kubernetes objects
+that deploy it (including env vars, for images with match_in_env_vars).
+Nothing is built or deployed.
+
+Registries that come from the cluster (e.g., a local registry) aren't known
+without a cluster, so only the Tiltfile's default_registry is applied.
+Resources deployed with k8s_custom_deploy, and image_ref() placeholders,
+aren't shown.`,
+		Example: `# What would a new build of gcr.io/my-app rewrite?
+tilt alpha inject gcr.io/my-app --dry-run
+
+# As JSON, with a specific digest
+tilt alpha inject gcr.io/my-app --dry-run --digest=sha256:4d3f... -o json`,
+		Args: cobra.ExactArgs(1),
+	}
+
+	addTiltfileFlag(cmd, &c.fileName)
+	addKubeContextFlag(cmd)
+	cmd.Flags().BoolVar(&c.dryRun, "dry-run", false, "Print what would be rewritten, without rewriting it. Required.")
+	cmd.Flags().StringVar(&c.digest, "digest", fakeInjectDigest.String(), "The digest to pretend the image was built with")
+	cmd.Flags().StringVarP(&c.output, "output", "o", "", "Output format. One of: json")
+
+	return cmd
+}
+
+func (c *injectCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.inject", nil)
+	defer a.Flush(time.Second)
+
+	if !c.dryRun {
+		return fmt.Errorf("only --dry-run is supported: Tilt injects images itself during 'tilt up'")
+	}
+	if c.output != "" && c.output != "json" {
+		return fmt.Errorf("invalid output format %q: must be json", c.output)
+	}
+	dig, err := digest.Parse(c.digest)
+	if err != nil {
+		return fmt.Errorf("invalid --digest %q: %v", c.digest, err)
+	}
+
+	// Keep stdout for the report.
+	ctx = logger.WithLogger(ctx, logger.NewLogger(logger.Get(ctx).Level(), c.streams.ErrOut))
+
+	deps, err := wireTiltfileResult(ctx, a, "alpha inject")
+	if err != nil {
+		return errors.Wrap(err, "wiring dependencies")
+	}
+
+	tlr := deps.tfl.Load(ctx, ctrltiltfile.MainTiltfile(c.fileName, nil), nil)
+	if tlr.Error != nil {
+		return tlr.Error
+	}
+
+	report, err := newInjectReport(tlr.Manifests, tlr.DefaultRegistry, args[0], dig)
+	if err != nil {
+		return err
+	}
+
+	if c.output == "json" {
+		return encodeJSON(c.streams.Out, report)
+	}
+	report.print(c.streams.Out)
+	return nil
+}
+
+// What injecting an image would rewrite.
+type injectReport struct {
+	Image string `json:"image"`
+
+	// The refs that would be injected into Dockerfiles and into the
+	// cluster's objects.
+	LocalRef   string `json:"localRef"`
+	ClusterRef string `json:"clusterRef"`
+
+	MatchInEnvVars bool `json:"matchInEnvVars"`
+
+	Dockerfiles []dockerfileInjection `json:"dockerfiles"`
+	Resources   []resourceInjection   `json:"resources"`
+}
+
+// The Dockerfile of an image built on top of the injected image.
+type dockerfileInjection struct {
+	Image         string                    `json:"image"`
+	Substitutions []dockerfile.Substitution `json:"substitutions"`
+}
+
+// The objects of a resource that deploys the injected image.
+type resourceInjection struct {
+	Resource string              `json:"resource"`
+	Fields   []k8s.InjectedField `json:"fields"`
+}
+
+func newInjectReport(manifests []model.Manifest, reg *v1alpha1.RegistryHosting, image string, dig digest.Digest) (injectReport, error) {
+	ref, err := container.ParseNamed(image)
+	if err != nil {
+		return injectReport{}, err
+	}
+
+	var target model.ImageTarget
+	var known []string
+	found := false
+	for _, m := range manifests {
+		for _, it := range m.ImageTargets {
+			selector, err := container.SelectorFromImageMap(it.ImageMapSpec)
+			if err != nil {
+				continue
+			}
+			if selector.Matches(ref) {
+				target = it
+				found = true
+				break
+			}
+			known = append(known, container.FamiliarString(selector))
+		}
+		if found {
+			break
+		}
+	}
+	if !found {
+		if len(known) == 0 {
+			return injectReport{}, fmt.Errorf("no image %q: the Tiltfile doesn't build any images", image)
+		}
+		return injectReport{}, fmt.Errorf("no image %q in the Tiltfile. Images: %s", image, strings.Join(known, ", "))
+	}
+
+	selector, err := container.SelectorFromImageMap(target.ImageMapSpec)
+	if err != nil {
+		return injectReport{}, err
+	}
+	refs, err := container.NewRefSet(selector, reg)
+	if err != nil {
+		return injectReport{}, err
+	}
+	tag, err := build.DigestAsTag(dig)
+	if err != nil {
+		return injectReport{}, err
+	}
+	tagged, err := refs.AddTagSuffix(tag)
+	if err != nil {
+		return injectReport{}, err
+	}
+
+	report := injectReport{
+		Image:          container.FamiliarString(selector),
+		LocalRef:       container.FamiliarString(tagged.LocalRef),
+		ClusterRef:     container.FamiliarString(tagged.ClusterRef),
+		MatchInEnvVars: target.MatchInEnvVars,
+		Dockerfiles:    []dockerfileInjection{},
+		Resources:      []resourceInjection{},
+	}
+
+	name := target.ImageMapName()
+	seenImages := map[string]bool{}
+	for _, m := range manifests {
+		for _, it := range m.ImageTargets {
+			if seenImages[it.ImageMapName()] || !it.IsDockerBuild() || !containsString(it.ImageMapDeps(), name) {
+				continue
+			}
+			seenImages[it.ImageMapName()] = true
+
+			spec := it.DockerBuildInfo().DockerImageSpec
+			ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(spec.DockerfileContents))
+			if err != nil {
+				return injectReport{}, errors.Wrapf(err, "Dockerfile of %s", it.Selector)
+			}
+			subs, err := ast.InjectImageDigestReport(selector, tagged.LocalRef, spec.Args)
+			if err != nil {
+				return injectReport{}, errors.Wrapf(err, "Dockerfile of %s", it.Selector)
+			}
+			if subs == nil {
+				subs = []dockerfile.Substitution{}
+			}
+			report.Dockerfiles = append(report.Dockerfiles, dockerfileInjection{
+				Image:         it.Selector,
+				Substitutions: subs,
+			})
+		}
+
+		if !m.IsK8s() {
+			continue
+		}
+		kTarget := m.K8sTarget()
+		spec := kTarget.KubernetesApplySpec
+		if spec.YAML == "" || !containsString(kTarget.ImageMaps, name) {
+			continue
+		}
+
+		entities, err := k8s.ParseYAMLFromString(spec.YAML)
+		if err != nil {
+			return injectReport{}, errors.Wrapf(err, "resource %s", m.Name)
+		}
+		locators, err := k8s.ParseImageLocators(spec.ImageLocators)
+		if err != nil {
+			return injectReport{}, errors.Wrapf(err, "resource %s", m.Name)
+		}
+
+		fields := []k8s.InjectedField{}
+		for _, e := range entities {
+			_, f, err := k8s.InjectImageDigestReport(e, spec.ImageContainers, selector, tagged.ClusterRef,
+				locators, target.MatchInEnvVars, "")
+			if err != nil {
+				return injectReport{}, errors.Wrapf(err, "resource %s", m.Name)
+			}
+			fields = append(fields, f...)
+		}
+		report.Resources = append(report.Resources, resourceInjection{
+			Resource: m.Name.String(),
+			Fields:   fields,
+		})
+	}
+	return report, nil
+}
+
+func (r injectReport) print(out io.Writer) {
+	_, _ = fmt.Fprintf(out, "Injecting %s as %s (dry run)\n", r.Image, r.ClusterRef)
+	if r.LocalRef != r.ClusterRef {
+		_, _ = fmt.Fprintf(out, "Dockerfiles use %s\n", r.LocalRef)
+	}
+	if r.MatchInEnvVars {
+		_, _ = fmt.Fprintln(out, "Env vars are matched too (match_in_env_vars)")
+	}
+
+	if len(r.Dockerfiles) == 0 && len(r.Resources) == 0 {
+		_, _ = fmt.Fprintln(out, "\nNo Dockerfiles or resources use this image.")
+		return
+	}
+
+	for _, df := range r.Dockerfiles {
+		_, _ = fmt.Fprintf(out, "\nDockerfile of %s:\n", df.Image)
+		if len(df.Substitutions) == 0 {
+			_, _ = fmt.Fprintln(out, "  nothing to rewrite (the build would fail)")
+		}
+		for _, s := range df.Substitutions {
+			_, _ = fmt.Fprintf(out, "  line %d:\n    - %s\n    + %s\n", s.Line, s.Before, s.After)
+		}
+	}
+
+	for _, res := range r.Resources {
+		_, _ = fmt.Fprintf(out, "\nResource %s:\n", res.Resource)
+		if len(res.Fields) == 0 {
+			_, _ = fmt.Fprintln(out, "  nothing to rewrite (the deploy would fail)")
+		}
+		for _, f := range res.Fields {
+			obj := fmt.Sprintf("%s/%s", f.Kind, f.Name)
+			if f.Container != "" {
+				obj = fmt.Sprintf("%s container %s", obj, f.Container)
+			}
+			_, _ = fmt.Fprintf(out, "  %s: %s\n    - %s\n    + %s\n", obj, f.Path, f.Old, f.New)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestInjectReport(t *testing.T) {
+	base := model.MustNewImageTarget(container.MustParseSelector(testyaml.SanchoImage)).
+		WithDockerImage(v1alpha1.DockerImageSpec{DockerfileContents: "FROM alpine\n"})
+	sidecar := model.MustNewImageTarget(container.MustParseSelector(testyaml.SanchoSidecarImage)).
+		WithDockerImage(v1alpha1.DockerImageSpec{DockerfileContents: "FROM " + testyaml.SanchoImage + "\n"}).
+		WithImageMapDeps([]string{base.ImageMapName()})
+	kTarget := k8s.MustTarget("sancho", testyaml.SanchoSidecarYAML).
+		WithImageDependencies([]string{base.ImageMapName(), sidecar.ImageMapName()})
+	m := model.Manifest{Name: "sancho"}.
+		WithImageTargets([]model.ImageTarget{base, sidecar}).
+		WithDeployTarget(kTarget)
+
+	reg := &v1alpha1.RegistryHosting{Host: "localhost:5000"}
+	report, err := newInjectReport([]model.Manifest{m}, reg, testyaml.SanchoImage, fakeInjectDigest)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:5000/gcr.io_some-project-162817_sancho:tilt-0000000000000000", report.LocalRef)
+	assert.Equal(t, report.LocalRef, report.ClusterRef)
+
+	require.Len(t, report.Dockerfiles, 1)
+	assert.Equal(t, testyaml.SanchoSidecarImage, report.Dockerfiles[0].Image)
+	require.Len(t, report.Dockerfiles[0].Substitutions, 1)
+	assert.Equal(t, "FROM "+report.LocalRef, report.Dockerfiles[0].Substitutions[0].After)
+
+	require.Len(t, report.Resources, 1)
+	require.Len(t, report.Resources[0].Fields, 1)
+	field := report.Resources[0].Fields[0]
+	assert.Equal(t, "sancho", field.Container)
+	assert.Equal(t, "spec.template.spec.containers[0].image", field.Path)
+	assert.Equal(t, report.ClusterRef, field.New)
+
+	out := &bytes.Buffer{}
+	report.print(out)
+	assert.Contains(t, out.String(), "Resource sancho:\n  Deployment/sancho container sancho: spec.template.spec.containers[0].image")
+}
+
+func TestInjectReportUnknownImage(t *testing.T) {
+	it := model.MustNewImageTarget(container.MustParseSelector(testyaml.SanchoImage))
+	m := model.Manifest{Name: "sancho"}.WithImageTargets([]model.ImageTarget{it})
+
+	_, err := newInjectReport([]model.Manifest{m}, nil, "gcr.io/other", fakeInjectDigest)
+	require.EqualError(t, err, `no image "gcr.io/other" in the Tiltfile. Images: gcr.io/some-project-162817/sancho`)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	analytics2 "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type refreshConnectionsCmd struct {
+	streams genericclioptions.IOStreams
+}
+
+var _ tiltCmd = &refreshConnectionsCmd{}
+
+func newRefreshConnectionsCmd(streams genericclioptions.IOStreams) *refreshConnectionsCmd {
+	return &refreshConnectionsCmd{
+		streams: streams,
+	}
+}
+
+func (c *refreshConnectionsCmd) name() model.TiltSubcommand { return "refresh-connections" }
+
+func (c *refreshConnectionsCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh-connections [CLUSTER_NAME...]",
+		Short: "Reconnect to the Kubernetes cluster and Docker",
+		Long: `Reconnect to the Kubernetes cluster and Docker, without restarting Tilt.
+
+Tilt re-reads your kubeconfig (or Docker context), creates new clients,
+and swaps them in once they're connected. Watches on the cluster start
+over on the new clients.
+
+Tilt already does this on its own when your kubeconfig or Docker config
+changes, or when the cluster says that your credentials have expired.
+This is for when it doesn't notice, e.g., when your credentials are
+refreshed somewhere Tilt doesn't look.
+
+Each refresh is recorded in the Tiltfile log, with its cause.
+
+With no names, refreshes every cluster connection.
+`,
+		Example: `# Refresh every connection
+tilt refresh-connections
+
+# Refresh the connection to the default Kubernetes cluster
+tilt refresh-connections default`,
+	}
+
+	addConnectServerFlags(cmd)
+	return cmd
+}
+
+func (c *refreshConnectionsCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.refresh-connections", analytics2.CmdTags{"count": fmt.Sprintf("%d", len(args))})
+	defer a.Flush(time.Second)
+
+	ctrlclient, err := newClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var clusters v1alpha1.ClusterList
+	err = ctrlclient.List(ctx, &clusters)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]v1alpha1.Cluster, len(clusters.Items))
+	for _, cluster := range clusters.Items {
+		byName[cluster.Name] = cluster
+	}
+
+	names := args
+	if len(names) == 0 {
+		for _, cluster := range clusters.Items {
+			names = append(names, cluster.Name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if _, ok := byName[name]; !ok {
+			return fmt.Errorf("no such cluster %q", name)
+		}
+	}
+
+	if len(names) == 0 {
+		_, _ = fmt.Fprintln(c.streams.Out, "No connections to refresh")
+		return nil
+	}
+
+	requestedAt := time.Now().Format(time.RFC3339Nano)
+	for _, name := range names {
+		cluster := byName[name]
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[v1alpha1.AnnotationRefreshConnection] = requestedAt
+		err := ctrlclient.Update(ctx, &cluster)
+		if err != nil {
+			return fmt.Errorf("refreshing connection to cluster %q: %v", name, err)
+		}
+		_, _ = fmt.Fprintf(c.streams.Out, "Refreshing connection to cluster %q\n", name)
+	}
+	return nil
+}
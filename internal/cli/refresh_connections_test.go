@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestRefreshConnectionsAll(t *testing.T) {
+	f := newRefreshConnectionsFixture(t)
+	out, err := f.run()
+	require.NoError(t, err)
+
+	require.Equal(t, "Refreshing connection to cluster \"default\"\nRefreshing connection to cluster \"docker\"\n", out)
+	require.NotEmpty(t, f.refreshRequest("default"))
+	require.NotEmpty(t, f.refreshRequest("docker"))
+}
+
+func TestRefreshConnectionsByName(t *testing.T) {
+	f := newRefreshConnectionsFixture(t)
+	out, err := f.run("docker")
+	require.NoError(t, err)
+
+	require.Equal(t, "Refreshing connection to cluster \"docker\"\n", out)
+	require.Empty(t, f.refreshRequest("default"))
+	require.NotEmpty(t, f.refreshRequest("docker"))
+}
+
+func TestRefreshConnectionsNotFound(t *testing.T) {
+	f := newRefreshConnectionsFixture(t)
+	_, err := f.run("docker", "foo")
+	require.EqualError(t, err, "no such cluster \"foo\"")
+	require.Empty(t, f.refreshRequest("docker"))
+}
+
+type refreshConnectionsFixture struct {
+	*serverFixture
+}
+
+func newRefreshConnectionsFixture(t *testing.T) refreshConnectionsFixture {
+	f := refreshConnectionsFixture{newServerFixture(t)}
+	for _, name := range []string{v1alpha1.ClusterNameDefault, v1alpha1.ClusterNameDocker} {
+		err := f.client.Create(f.ctx, &v1alpha1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		})
+		require.NoError(t, err)
+	}
+	return f
+}
+
+func (f refreshConnectionsFixture) run(args ...string) (string, error) {
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newRefreshConnectionsCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse(args)
+	require.NoError(f.T(), err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	return out.String(), err
+}
+
+func (f refreshConnectionsFixture) refreshRequest(name string) string {
+	var cluster v1alpha1.Cluster
+	err := f.client.Get(f.ctx, types.NamespacedName{Name: name}, &cluster)
+	require.NoError(f.T(), err)
+	return cluster.Annotations[v1alpha1.AnnotationRefreshConnection]
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/build"
+	analytics2 "github.com/tilt-dev/tilt/internal/engine/analytics"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type resetCmd struct {
+	streams genericclioptions.IOStreams
+
+	all bool
+	yes bool
+}
+
+var _ tiltCmd = &resetCmd{}
+
+func newResetCmd(streams genericclioptions.IOStreams) *resetCmd {
+	return &resetCmd{
+		streams: streams,
+	}
+}
+
+func (c *resetCmd) name() model.TiltSubcommand { return "reset" }
+
+func (c *resetCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset [RESOURCE_NAME...] | --all",
+		Short: "Rebuild resources from scratch",
+		Long: fmt.Sprintf(`Rebuild resources from scratch.
+
+For when a resource gets into a weird state that a normal trigger
+doesn't fix. Tilt forgets what it built for the resource, deletes its
+Kubernetes objects (or Docker Compose service), rebuilds its images
+without the build cache, re-applies its objects, and starts tracking its
+pods and logs over.
+
+Docker builds run with --no-cache. custom_build scripts get %s=1,
+and should build without their cache when it's set.
+
+Each reset is recorded in the resource's log.
+
+With --all, resets every enabled resource, after asking for confirmation.
+`, build.NoCacheEnvVar),
+		Example: `# Reset one resource
+tilt reset api
+
+# Reset everything, without asking first
+tilt reset --all --yes`,
+	}
+
+	addConnectServerFlags(cmd)
+	cmd.Flags().BoolVar(&c.all, "all", false, "Reset every enabled resource")
+	cmd.Flags().BoolVarP(&c.yes, "yes", "y", false, "Don't ask for confirmation before resetting every resource")
+	return cmd
+}
+
+func (c *resetCmd) run(ctx context.Context, args []string) error {
+	a := analytics.Get(ctx)
+	a.Incr("cmd.reset", analytics2.CmdTags{"all": fmt.Sprintf("%t", c.all)})
+	defer a.Flush(time.Second)
+
+	if c.all == (len(args) > 0) {
+		return fmt.Errorf("specify either resource names or --all")
+	}
+
+	if c.all && !c.yes {
+		ok, err := c.confirm("Reset all resources? Tilt will delete their objects and rebuild them without the build cache. [y/N] ")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			_, _ = fmt.Fprintln(c.streams.Out, "Reset canceled")
+			return nil
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"manifest_names": args,
+		"all":            c.all,
+	})
+	if err != nil {
+		return err
+	}
+
+	r, status := apiPostJson("reset", payload)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "error reading response from tilt api")
+	}
+	_ = r.Close()
+
+	if status != http.StatusOK {
+		return fmt.Errorf("(%d): %s", status, strings.TrimSpace(string(b)))
+	}
+
+	var resp struct {
+		ManifestNames []string `json:"manifest_names"`
+	}
+	err = json.Unmarshal(b, &resp)
+	if err != nil {
+		return errors.Wrap(err, "error parsing response from tilt api")
+	}
+
+	if len(resp.ManifestNames) == 0 {
+		_, _ = fmt.Fprintln(c.streams.Out, "No resources to reset")
+		return nil
+	}
+	for _, name := range resp.ManifestNames {
+		_, _ = fmt.Fprintf(c.streams.Out, "Resetting resource: %q\n", name)
+	}
+	return nil
+}
+
+func (c *resetCmd) confirm(prompt string) (bool, error) {
+	_, _ = fmt.Fprint(c.streams.Out, prompt)
+	line, err := bufio.NewReader(c.streams.In).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestResetSuccess(t *testing.T) {
+	f := newResetFixture(t)
+	out, err := f.run("foo")
+	require.NoError(t, err)
+
+	require.Equal(t, "Resetting resource: \"foo\"\n", out)
+	require.JSONEq(t, `{"manifest_names": ["foo"], "all": false}`, f.requestBody)
+}
+
+func TestResetNotFound(t *testing.T) {
+	f := newResetFixture(t)
+	f.responseBody = "resource \"foo\" does not exist"
+	f.responseStatus = http.StatusNotFound
+	out, err := f.run("foo")
+	require.EqualError(t, err, "(404): resource \"foo\" does not exist")
+	require.Equal(t, "", out)
+}
+
+func TestResetRequiresNamesOrAll(t *testing.T) {
+	f := newResetFixture(t)
+	_, err := f.run()
+	require.EqualError(t, err, "specify either resource names or --all")
+
+	_, err = f.run("--all", "foo")
+	require.EqualError(t, err, "specify either resource names or --all")
+	require.Equal(t, "", f.requestBody)
+}
+
+func TestResetAllConfirmed(t *testing.T) {
+	f := newResetFixture(t)
+	f.responseBody = `{"manifest_names": ["foo", "bar"]}`
+	f.in = "y\n"
+	out, err := f.run("--all")
+	require.NoError(t, err)
+
+	require.True(t, strings.HasPrefix(out, "Reset all resources?"), out)
+	require.True(t, strings.HasSuffix(out, "Resetting resource: \"foo\"\nResetting resource: \"bar\"\n"), out)
+	require.JSONEq(t, `{"manifest_names": [], "all": true}`, f.requestBody)
+}
+
+func TestResetAllCanceled(t *testing.T) {
+	f := newResetFixture(t)
+	f.in = "n\n"
+	out, err := f.run("--all")
+	require.NoError(t, err)
+
+	require.True(t, strings.HasSuffix(out, "Reset canceled\n"), out)
+	require.Equal(t, "", f.requestBody)
+}
+
+func TestResetAllYes(t *testing.T) {
+	f := newResetFixture(t)
+	f.responseBody = `{"manifest_names": []}`
+	out, err := f.run("--all", "--yes")
+	require.NoError(t, err)
+
+	require.Equal(t, "No resources to reset\n", out)
+}
+
+type resetFixture struct {
+	t              *testing.T
+	ctx            context.Context
+	in             string
+	requestBody    string
+	responseBody   string
+	responseStatus int
+}
+
+func newResetFixture(t *testing.T) *resetFixture {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f := &resetFixture{
+		t:              t,
+		ctx:            ctx,
+		responseBody:   `{"manifest_names": ["foo"]}`,
+		responseStatus: http.StatusOK,
+	}
+
+	l, port := listenOnFreePort(t)
+	origPort := defaultWebPort
+	defaultWebPort = port
+	t.Cleanup(func() {
+		defaultWebPort = origPort
+	})
+
+	mux := &http.ServeMux{}
+	mux.HandleFunc("/api/reset", func(w http.ResponseWriter, req *http.Request) {
+		b, _ := io.ReadAll(req.Body)
+		f.requestBody = string(b)
+		w.WriteHeader(f.responseStatus)
+		_, _ = w.Write([]byte(f.responseBody))
+	})
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", defaultWebPort),
+		Handler: mux,
+	}
+
+	go func() { _ = srv.Serve(l) }()
+	t.Cleanup(func() {
+		_ = srv.Shutdown(ctx)
+	})
+
+	return f
+}
+
+func (f *resetFixture) run(args ...string) (string, error) {
+	streams, in, out, _ := genericclioptions.NewTestIOStreams()
+	in.WriteString(f.in)
+	cmd := newResetCmd(streams)
+	c := cmd.register()
+	err := c.Flags().Parse(args)
+	require.NoError(f.t, err)
+	err = cmd.run(f.ctx, c.Flags().Args())
+	return out.String(), err
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type statusCmd struct {
+	streams genericclioptions.IOStreams
+	short   bool
+	timeout time.Duration
+
+	// Overridden in tests.
+	url string
+	now func() time.Time
+}
+
+var _ tiltCmd = &statusCmd{}
+
+func newStatusCmd(streams genericclioptions.IOStreams) *statusCmd {
+	return &statusCmd{
+		streams: streams,
+		now:     time.Now,
+	}
+}
+
+func (c *statusCmd) name() model.TiltSubcommand { return "status" }
+
+func (c *statusCmd) register() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show how many resources are up-to-date, building, pending, or failing",
+		Long: `Show how many resources are up-to-date, building, pending, or failing.
+
+Reads the summary from a running Tilt's in-memory state, without contacting
+the cluster, so it's cheap enough to run on every shell prompt.
+
+With --short, prints a single line like:
+
+  error: 1 error, 1 pending, 3 ok (updated 2m ago)
+
+Exits with an error if Tilt isn't running.`,
+		Example: `# In a prompt, with nothing printed when Tilt isn't running
+tilt status --short --timeout=100ms 2>/dev/null`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.Flags().BoolVar(&c.short, "short", false, "Print a one-line summary, suitable for a shell prompt")
+	cmd.Flags().DurationVar(&c.timeout, "timeout", time.Second, "How long to wait for Tilt to respond")
+	addConnectServerFlags(cmd)
+
+	return cmd
+}
+
+func (c *statusCmd) run(ctx context.Context, args []string) error {
+	url := c.url
+	if url == "" {
+		url = apiURL("summary")
+	}
+
+	summary, err := c.fetchSummary(ctx, url)
+	if err != nil {
+		return err
+	}
+
+	if c.short {
+		_, _ = fmt.Fprintln(c.streams.Out, c.shortLine(summary))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.streams.Out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "State:\t%s\n", summary.State)
+	_, _ = fmt.Fprintf(w, "Last update:\t%s\n", c.lastUpdateText(summary))
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "OK:\t%d\n", summary.OK)
+	_, _ = fmt.Fprintf(w, "Building:\t%d\n", summary.Building)
+	_, _ = fmt.Fprintf(w, "Pending:\t%d\n", summary.Pending)
+	_, _ = fmt.Fprintf(w, "Error:\t%d\n", summary.Error)
+	_, _ = fmt.Fprintf(w, "Disabled:\t%d\n", summary.Disabled)
+	return w.Flush()
+}
+
+func (c *statusCmd) fetchSummary(ctx context.Context, url string) (server.Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return server.Summary{}, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return server.Summary{}, fmt.Errorf("Could not connect to Tilt at %s: %v", url, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(res.Body)
+		return server.Summary{}, fmt.Errorf("Request to %s failed with status %q: %s",
+			url, res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var summary server.Summary
+	err = json.NewDecoder(res.Body).Decode(&summary)
+	if err != nil {
+		return server.Summary{}, fmt.Errorf("Error decoding response from %s: %v", url, err)
+	}
+	return summary, nil
+}
+
+// The one-line summary, listing only the states that have resources in them.
+func (c *statusCmd) shortLine(summary server.Summary) string {
+	counts := []struct {
+		n    int
+		name string
+	}{
+		{summary.Error, "error"},
+		{summary.Building, "building"},
+		{summary.Pending, "pending"},
+		{summary.OK, "ok"},
+		{summary.Disabled, "disabled"},
+	}
+
+	var parts []string
+	for _, count := range counts {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.name))
+		}
+	}
+
+	line := summary.State
+	if len(parts) > 0 {
+		line = fmt.Sprintf("%s: %s", line, strings.Join(parts, ", "))
+	}
+	if summary.LastUpdateTime != nil {
+		line = fmt.Sprintf("%s (updated %s)", line, c.lastUpdateText(summary))
+	}
+	return line
+}
+
+func (c *statusCmd) lastUpdateText(summary server.Summary) string {
+	if summary.LastUpdateTime == nil {
+		return "never"
+	}
+
+	d := c.now().Sub(*summary.LastUpdateTime)
+	if d < 0 {
+		d = 0
+	}
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	}
+}
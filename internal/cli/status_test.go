@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestStatusShort(t *testing.T) {
+	out := runStatus(t, true, `{"ok": 3, "building": 0, "pending": 1, "error": 1, "disabled": 0,
+  "state": "error", "last_update_time": "2023-01-01T12:00:00Z"}`)
+	assert.Equal(t, "error: 1 error, 1 pending, 3 ok (updated 2m ago)\n", out)
+}
+
+func TestStatusShortNoUpdates(t *testing.T) {
+	out := runStatus(t, true, `{"ok": 0, "building": 0, "pending": 1, "error": 0, "disabled": 0, "state": "pending"}`)
+	assert.Equal(t, "pending: 1 pending\n", out)
+}
+
+func TestStatusLong(t *testing.T) {
+	out := runStatus(t, false, `{"ok": 3, "building": 1, "pending": 0, "error": 0, "disabled": 2,
+  "state": "building", "last_update_time": "2023-01-01T12:01:50Z"}`)
+	assert.Contains(t, out, "State:        building\n")
+	assert.Contains(t, out, "Last update:  10s ago\n")
+	assert.Contains(t, out, "Disabled:  2\n")
+}
+
+func TestStatusNotRunning(t *testing.T) {
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, _, _ := genericclioptions.NewTestIOStreams()
+	cmd := newStatusCmd(streams)
+	cmd.register()
+	cmd.url = "http://localhost:1/api/summary"
+
+	err := cmd.run(ctx, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not connect to Tilt")
+}
+
+func runStatus(t *testing.T, short bool, body string) string {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/summary", r.URL.Path)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	streams, _, out, _ := genericclioptions.NewTestIOStreams()
+	cmd := newStatusCmd(streams)
+	cmd.register()
+	cmd.short = short
+	cmd.url = ts.URL + "/api/summary"
+	cmd.now = func() time.Time { return time.Date(2023, 1, 1, 12, 2, 0, 0, time.UTC) }
+
+	err := cmd.run(ctx, nil)
+	require.NoError(t, err)
+	return out.String()
+}
@@ -18,8 +18,12 @@ import (
 	"github.com/tilt-dev/tilt/internal/analytics"
 	engineanalytics "github.com/tilt-dev/tilt/internal/engine/analytics"
 	"github.com/tilt-dev/tilt/internal/hud/prompt"
+	"github.com/tilt-dev/tilt/internal/hud/server"
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -43,8 +47,9 @@ type upCmd struct {
 	fileName             string
 	outputSnapshotOnExit string
 
-	legacy bool
-	stream bool
+	legacy  bool
+	stream  bool
+	offline bool
 }
 
 func (c *upCmd) name() model.TiltSubcommand { return "up" }
@@ -79,6 +84,8 @@ local resources--i.e. those using serve_cmd--are terminated when you exit Tilt.
 	cmd.Flags().BoolVar(&c.legacy, "legacy", false, "If true, tilt will open in legacy terminal mode.")
 	cmd.Flags().BoolVar(&c.stream, "stream", false, "If true, tilt will stream logs in the terminal.")
 	cmd.Flags().BoolVar(&logActionsFlag, "logactions", false, "log all actions and state changes")
+	cmd.Flags().BoolVar(&c.offline, "offline", false,
+		"If true, skip network operations that Tilt doesn't strictly need, like pushes to remote registries and extension fetches. Turned on automatically when there's no network route.")
 	addStartServerFlags(cmd)
 	addDevServerFlags(cmd)
 	addTiltfileFlag(cmd, &c.fileName)
@@ -106,6 +113,13 @@ func (c *upCmd) initialTermMode(isTerminal bool) store.TerminalMode {
 	return store.TerminalModePrompt
 }
 
+func (c *upCmd) offlineMode() offline.Mode {
+	if c.offline {
+		return offline.Mode{Enabled: true, Reason: "--offline"}
+	}
+	return offline.Detect()
+}
+
 func (c *upCmd) run(ctx context.Context, args []string) error {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -117,6 +131,11 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	isTTY := isatty.IsTerminal(os.Stdout.Fd())
 	termMode := c.initialTermMode(isTTY)
 
+	offlineMode = c.offlineMode()
+	if offlineMode.Enabled {
+		a.OptOutForSession()
+	}
+
 	cmdUpTags := engineanalytics.CmdTags(map[string]string{
 		"update_mode": updateModeFlag, // before 7/8/20 this was just called "mode"
 		"term_mode":   strconv.Itoa(int(termMode)),
@@ -135,6 +154,8 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	deferred := logger.NewDeferredLogger(ctx)
 	ctx = redirectLogs(ctx, deferred)
 
+	reclaimPorts(ctx)
+
 	webHost := provideWebHost()
 	webURL, _ := provideWebURL(webHost, provideWebPort())
 	startLine := prompt.StartStatusLine(webURL, webHost)
@@ -145,6 +166,13 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		log.Printf("Tilt analytics disabled: %s", reason)
 	}
 
+	if offlineMode.Enabled {
+		log.Printf("Tilt is in offline mode (%s). Skipping:", offlineMode.Reason)
+		for _, d := range offline.Degraded {
+			log.Printf("  • %s", d)
+		}
+	}
+
 	cmdUpDeps, err := wireCmdUp(ctx, a, cmdUpTags, "up")
 	if err != nil {
 		deferred.SetOutput(deferred.Original())
@@ -165,6 +193,8 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 		defer cmdUpDeps.Snapshotter.WriteSnapshot(ctx, c.outputSnapshotOnExit)
 	}
 
+	openProcessRegistry(ctx, cmdUpDeps.LocalEnv, cmdUpDeps.Base, cmdUpDeps.APIServerName, cmdUpDeps.APIServerPort)
+
 	err = upper.Start(ctx, args, cmdUpDeps.TiltBuild,
 		c.fileName, termMode, a.UserOpt(), cmdUpDeps.Token, string(cmdUpDeps.CloudAddress))
 	if err != context.Canceled {
@@ -174,6 +204,51 @@ func (c *upCmd) run(ctx context.Context, args []string) error {
 	}
 }
 
+// reclaimPorts frees the ports left held by the processes of a previous
+// session, before this one listens on them. If the web UI port stays held,
+// switches to another one.
+//
+// The API server port needs no fallback, since it's picked at random.
+func reclaimPorts(ctx context.Context) {
+	l := logger.Get(ctx)
+	webPort := provideWebPort()
+	file, err := localexec.RegistryFile(xdg.NewTiltDevBase(), model.ProvideAPIServerName(webPort))
+	if err != nil {
+		l.Debugf("Reclaiming ports: %v", err)
+		return
+	}
+
+	for _, p := range localexec.ReclaimPorts(ctx, file) {
+		if webPort == 0 || p.Port != int(webPort) {
+			continue
+		}
+		alt, err := localexec.AlternatePort(p.Port)
+		if err != nil {
+			l.Infof("Can't switch the web UI to another port: %v", err)
+			return
+		}
+		l.Infof("Using port %d for the web UI instead. Pass --port=%d to other Tilt commands to reach this session", alt, alt)
+		webPortFlag = alt
+	}
+}
+
+// Kills any processes left running by a previous session of this Tilt
+// instance, and starts tracking this session's.
+func openProcessRegistry(ctx context.Context, env *localexec.Env, base xdg.Base, name model.APIServerName, apiPort server.APIServerPort) {
+	file, err := localexec.RegistryFile(base, name)
+	if err != nil {
+		logger.Get(ctx).Debugf("Tracking local processes: %v", err)
+		return
+	}
+	supervisor := env.Supervisor()
+	supervisor.Open(ctx, file)
+
+	if webPort := provideWebPort(); webPort != 0 {
+		supervisor.AddPort(localexec.RegisteredPort{Port: int(webPort), Purpose: "web UI"})
+	}
+	supervisor.AddPort(localexec.RegisteredPort{Port: int(apiPort), Purpose: "API server", Dynamic: true})
+}
+
 func redirectLogs(ctx context.Context, l logger.Logger) context.Context {
 	ctx = logger.WithLogger(ctx, l)
 	log.SetOutput(l.Writer(logger.InfoLvl))
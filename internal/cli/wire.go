@@ -40,6 +40,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/local"
 	"github.com/tilt-dev/tilt/internal/engine/session"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
 	"github.com/tilt-dev/tilt/internal/feature"
@@ -52,6 +53,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/openurl"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/tiltfile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/internal/token"
 	"github.com/tilt-dev/tilt/internal/tracer"
 	"github.com/tilt-dev/tilt/internal/xdg"
@@ -71,6 +73,7 @@ var K8sWireSet = wire.NewSet(
 	k8s.ProvideConfigNamespace,
 	k8s.ProvideContainerRuntime,
 	k8s.ProvideServerVersion,
+	k8s.ProvideSchemaValidator,
 	k8s.ProvideK8sClient,
 	ProvideKubeContextOverride,
 	ProvideNamespaceOverride)
@@ -118,6 +121,7 @@ var BaseWireSet = wire.NewSet(
 	wire.Bind(new(store.Dispatcher), new(*store.Store)),
 
 	dockerprune.NewDockerPruner,
+	triggerstats.NewSubscriber,
 
 	provideTiltInfo,
 	engine.NewUpper,
@@ -130,6 +134,9 @@ var BaseWireSet = wire.NewSet(
 	controllers.WireSet,
 
 	provideCITimeoutFlag,
+	provideSandboxConfig,
+	sandbox.ProvideSandbox,
+	provideOfflineMode,
 	provideWebVersion,
 	provideWebMode,
 	provideWebURL,
@@ -186,6 +193,11 @@ type CmdUpDeps struct {
 	CloudAddress cloudurl.Address
 	Prompt       *prompt.TerminalPrompt
 	Snapshotter  *cloud.Snapshotter
+
+	LocalEnv      *localexec.Env
+	APIServerName model.APIServerName
+	APIServerPort server.APIServerPort
+	Base          xdg.Base
 }
 
 func wireCmdCI(ctx context.Context, analytics *analytics.TiltAnalytics, subcommand model.TiltSubcommand) (CmdCIDeps, error) {
@@ -204,6 +216,11 @@ type CmdCIDeps struct {
 	Token        token.Token
 	CloudAddress cloudurl.Address
 	Snapshotter  *cloud.Snapshotter
+
+	LocalEnv      *localexec.Env
+	APIServerName model.APIServerName
+	APIServerPort server.APIServerPort
+	Base          xdg.Base
 }
 
 func wireCmdUpdog(ctx context.Context,
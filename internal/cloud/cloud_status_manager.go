@@ -12,6 +12,7 @@ import (
 	"github.com/jonboulle/clockwork"
 
 	"github.com/tilt-dev/tilt/internal/cloud/cloudurl"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/token"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -24,8 +25,8 @@ const timeoutAfterError = 5 * time.Minute
 // how frequently we'll refresh cloud status, even if nothing changes
 const refreshPeriod = time.Hour
 
-func NewStatusManager(client HttpClient, clock clockwork.Clock) *CloudStatusManager {
-	return &CloudStatusManager{client: client, clock: clock}
+func NewStatusManager(client HttpClient, clock clockwork.Clock, offline offline.Mode) *CloudStatusManager {
+	return &CloudStatusManager{client: client, clock: clock, offline: offline}
 }
 
 // if any of these fields change, we know we need to do a fresh lookup
@@ -36,8 +37,9 @@ type statusRequestKey struct {
 }
 
 type CloudStatusManager struct {
-	client HttpClient
-	clock  clockwork.Clock
+	client  HttpClient
+	clock   clockwork.Clock
+	offline offline.Mode
 
 	mu sync.Mutex
 
@@ -139,6 +141,12 @@ func (c *CloudStatusManager) needsLookup(requestKey statusRequestKey) bool {
 }
 
 func (c *CloudStatusManager) OnChange(ctx context.Context, st store.RStore, _ store.ChangeSummary) error {
+	// The lookup is also how Tilt finds out about new versions, so offline
+	// mode skips update checks too.
+	if c.offline.Enabled {
+		return nil
+	}
+
 	state := st.RLockState()
 	defer st.RUnlockState()
 
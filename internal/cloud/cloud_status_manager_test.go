@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/internal/testutils/httptest"
@@ -80,6 +81,19 @@ func TestStatusRefresh(t *testing.T) {
 	require.Equal(t, expected, a)
 }
 
+func TestOfflineSkipsLookup(t *testing.T) {
+	f := newCloudStatusManagerTestFixture(t)
+	f.um.offline = offline.Mode{Enabled: true, Reason: "--offline"}
+
+	f.httpClient.SetResponse(`{"SuggestedTiltVersion": "10.0.0"}`)
+	f.Run(func(state *store.EngineState) {
+		state.TiltBuildInfo.Version = "test tilt version"
+	})
+
+	require.Empty(t, f.httpClient.Requests())
+	store.AssertNoActionOfType(t, reflect.TypeOf(store.TiltCloudStatusReceivedAction{}), f.st.Actions)
+}
+
 type cloudStatusManagerTestFixture struct {
 	um         *CloudStatusManager
 	httpClient *httptest.FakeClient
@@ -102,7 +116,7 @@ func newCloudStatusManagerTestFixture(t *testing.T) *cloudStatusManagerTestFixtu
 		st:         st,
 		httpClient: httpClient,
 		clock:      clock,
-		um:         NewStatusManager(httpClient, clock),
+		um:         NewStatusManager(httpClient, clock, offline.Mode{}),
 		ctx:        ctx,
 		t:          t,
 	}
@@ -5,3 +5,12 @@ const IstioSidecarContainerName = Name("istio-proxy")
 
 const LinkerdInitContainerName = Name("linkerd-init")
 const LinkerdSidecarContainerName = Name("linkerd-proxy")
+
+// The service mesh containers that are left out of a resource's logs unless
+// the Tiltfile says otherwise.
+var DefaultIgnoredLogContainers = []Name{
+	IstioInitContainerName,
+	IstioSidecarContainerName,
+	LinkerdSidecarContainerName,
+	LinkerdInitContainerName,
+}
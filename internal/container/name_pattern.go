@@ -0,0 +1,32 @@
+package container
+
+import (
+	"fmt"
+	"path"
+)
+
+// MatchesAny reports whether the container name matches one of the
+// patterns. A pattern is a container name, or a glob like "*-proxy" with
+// the syntax of path.Match.
+func (n Name) MatchesAny(patterns []string) bool {
+	for _, p := range patterns {
+		ok, err := path.Match(p, string(n))
+		if err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateNamePatterns checks that container name patterns are well-formed.
+func ValidateNamePatterns(patterns []string) error {
+	for _, p := range patterns {
+		if p == "" {
+			return fmt.Errorf("container name pattern must not be empty")
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid container name pattern %q: %v", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameMatchesAny(t *testing.T) {
+	assert.True(t, Name("api").MatchesAny([]string{"api"}))
+	assert.True(t, Name("istio-proxy").MatchesAny([]string{"api", "*-proxy"}))
+	assert.True(t, Name("worker-2").MatchesAny([]string{"worker-?"}))
+	assert.False(t, Name("api-server").MatchesAny([]string{"api"}))
+	assert.False(t, Name("api").MatchesAny(nil))
+	assert.False(t, Name("api").MatchesAny([]string{"[api"}))
+}
+
+func TestValidateNamePatterns(t *testing.T) {
+	assert.NoError(t, ValidateNamePatterns([]string{"api", "*-proxy", "worker-[0-9]"}))
+	assert.EqualError(t, ValidateNamePatterns([]string{"[api"}),
+		`invalid container name pattern "[api": syntax error in pattern`)
+	assert.EqualError(t, ValidateNamePatterns([]string{""}),
+		"container name pattern must not be empty")
+}
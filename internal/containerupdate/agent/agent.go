@@ -0,0 +1,206 @@
+// Package agent defines the Tilt agent: a small gRPC service, deployed as a
+// daemonset, that updates containers on its node through the CRI socket.
+//
+// Live update normally runs its steps with the Kubernetes exec API. Shared
+// clusters often forbid pods/exec. The agent gives Tilt another way in: Tilt
+// port-forwards to the agent on the container's node, and the agent writes
+// files and runs commands with crictl.
+//
+// The agent can run any command in any container on its node, so it's locked
+// down three ways:
+//   - It listens on the pod's loopback interface, so it's only reachable
+//     through a port-forward, which needs pods/portforward permission in the
+//     agent namespace.
+//   - Every call must carry the token from the agent's Secret.
+//   - It only touches containers in pods that Tilt deployed.
+//
+// The messages are plain Go structs, encoded as JSON on the wire, so the
+// service doesn't need generated protobuf code.
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// The namespace and labels of the agent daemonset.
+	Namespace = "tilt-agent"
+	LabelName = "app.kubernetes.io/name"
+	LabelApp  = "tilt-agent"
+
+	// The port that the agent listens on.
+	Port = 9876
+
+	// The daemonset manifest.
+	ManifestURL = "https://raw.githubusercontent.com/tilt-dev/tilt/master/cmd/tilt-agent/tilt-agent.yaml"
+
+	// The Secret, in the agent namespace, that holds the token that callers
+	// must present.
+	TokenSecretName = "tilt-agent"
+	TokenSecretKey  = "token"
+
+	serviceName = "tilt.agent.v1.Agent"
+	codecName   = "tilt-agent-json"
+	authHeader  = "authorization"
+)
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return codecName }
+
+type CapabilitiesRequest struct{}
+
+type CapabilitiesResponse struct {
+	// The node the agent runs on.
+	Node string `json:"node"`
+
+	// The container runtime, e.g., "containerd://1.6.8".
+	Runtime string `json:"runtime"`
+}
+
+// Copies files into a container, after deleting old ones.
+type UpdateRequest struct {
+	ContainerID string `json:"containerID"`
+
+	// Paths to delete before copying.
+	Delete []string `json:"delete,omitempty"`
+
+	// A tar archive, extracted at the container root.
+	Archive []byte `json:"archive,omitempty"`
+}
+
+type UpdateResponse struct {
+	Output string `json:"output,omitempty"`
+}
+
+// Runs a command in a container.
+type ExecRequest struct {
+	ContainerID string   `json:"containerID"`
+	Argv        []string `json:"argv"`
+}
+
+type ExecResponse struct {
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output,omitempty"`
+}
+
+// Server is the interface that an agent implements.
+//
+// If the container isn't on the agent's node, methods return a gRPC
+// NotFound error, so that clients can try another agent. If the container's
+// pod wasn't deployed by Tilt, they return PermissionDenied.
+type Server interface {
+	Capabilities(ctx context.Context, req *CapabilitiesRequest) (*CapabilitiesResponse, error)
+	Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error)
+	Exec(ctx context.Context, req *ExecRequest) (*ExecResponse, error)
+}
+
+// NewGRPCServer creates a gRPC server that rejects calls without the token.
+func NewGRPCServer(token string) (*grpc.Server, error) {
+	if token == "" {
+		return nil, errors.New("agent token is empty")
+	}
+	return grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(token))), nil
+}
+
+func authInterceptor(token string) grpc.UnaryServerInterceptor {
+	want := []byte("Bearer " + token)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		got := md.Get(authHeader)
+		if len(got) != 1 || subtle.ConstantTimeCompare([]byte(got[0]), want) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid agent token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+func RegisterServer(s *grpc.Server, srv Server) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+func unaryHandler[Req any, Resp any](method string, call func(Server, context.Context, *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return call(srv.(Server), ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fmt.Sprintf("/%s/%s", serviceName, method)}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(Server), ctx, req.(*Req))
+			})
+		},
+	}
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*Server)(nil),
+	Methods: []grpc.MethodDesc{
+		unaryHandler("Capabilities", Server.Capabilities),
+		unaryHandler("Update", Server.Update),
+		unaryHandler("Exec", Server.Exec),
+	},
+}
+
+// Client talks to an agent.
+type Client struct {
+	conn  grpc.ClientConnInterface
+	token string
+}
+
+func NewClient(conn grpc.ClientConnInterface, token string) Client {
+	return Client{conn: conn, token: token}
+}
+
+func (c Client) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	ctx = metadata.AppendToOutgoingContext(ctx, authHeader, "Bearer "+c.token)
+	return c.conn.Invoke(ctx, fmt.Sprintf("/%s/%s", serviceName, method), req, resp,
+		grpc.CallContentSubtype(codecName))
+}
+
+func (c Client) Capabilities(ctx context.Context) (*CapabilitiesResponse, error) {
+	resp := &CapabilitiesResponse{}
+	return resp, c.invoke(ctx, "Capabilities", &CapabilitiesRequest{}, resp)
+}
+
+func (c Client) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	resp := &UpdateResponse{}
+	return resp, c.invoke(ctx, "Update", req, resp)
+}
+
+func (c Client) Exec(ctx context.Context, req *ExecRequest) (*ExecResponse, error) {
+	resp := &ExecResponse{}
+	return resp, c.invoke(ctx, "Exec", req, resp)
+}
+
+// IsNotFound reports whether the agent doesn't have the container.
+func IsNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+// IsUnauthenticated reports whether the agent rejected the token.
+func IsUnauthenticated(err error) bool {
+	return status.Code(err) == codes.Unauthenticated
+}
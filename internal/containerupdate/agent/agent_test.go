@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestCapabilities(t *testing.T) {
+	f := newFixture(t)
+	f.output["version"] = "Version:  0.1.0\nRuntimeName:  containerd\nRuntimeVersion:  v1.6.8\n"
+
+	resp, err := f.client.Capabilities(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", resp.Node)
+	assert.Equal(t, "containerd://1.6.8", resp.Runtime)
+}
+
+func TestUpdate(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := f.client.Update(context.Background(), &UpdateRequest{
+		ContainerID: "c1",
+		Delete:      []string{"/app/old.txt"},
+		Archive:     []byte("archive"),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, f.calls, 4)
+	assert.Equal(t, "inspect", f.calls[0].argv[3])
+	assert.Equal(t, "inspectp", f.calls[1].argv[3])
+	assert.Equal(t, "sandbox-1", f.calls[1].argv[len(f.calls[1].argv)-1])
+	assert.Equal(t, "exec c1 rm -rf /app/old.txt", strings.Join(f.calls[2].argv[3:], " "))
+	assert.Equal(t, "exec -i c1 tar -C / -x -f -", strings.Join(f.calls[3].argv[3:], " "))
+	assert.Equal(t, "archive", f.calls[3].stdin)
+}
+
+func TestUpdateContainerNotOnNode(t *testing.T) {
+	f := newFixture(t)
+	f.exitCode["inspect"] = 1
+
+	_, err := f.client.Update(context.Background(), &UpdateRequest{ContainerID: "c1"})
+	assert.True(t, IsNotFound(err), "expected NotFound, got: %v", err)
+}
+
+func TestUpdateContainerNotManagedByTilt(t *testing.T) {
+	f := newFixture(t)
+	f.output["inspectp"] = "helm"
+
+	_, err := f.client.Update(context.Background(), &UpdateRequest{ContainerID: "c1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected PermissionDenied, got: %v", err)
+	assert.Len(t, f.calls, 2)
+}
+
+func TestWrongToken(t *testing.T) {
+	f := newFixture(t)
+
+	_, err := NewClient(f.conn, "wrong-token").Exec(context.Background(), &ExecRequest{
+		ContainerID: "c1",
+		Argv:        []string{"make", "build"},
+	})
+	assert.True(t, IsUnauthenticated(err), "expected Unauthenticated, got: %v", err)
+	assert.Empty(t, f.calls)
+}
+
+func TestExec(t *testing.T) {
+	f := newFixture(t)
+	f.output["exec"] = "oops"
+	f.exitCode["exec"] = 2
+
+	resp, err := f.client.Exec(context.Background(), &ExecRequest{
+		ContainerID: "c1",
+		Argv:        []string{"make", "build"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.ExitCode)
+	assert.Equal(t, "oops", resp.Output)
+	assert.Equal(t, "exec c1 make build", strings.Join(f.calls[2].argv[3:], " "))
+}
+
+type call struct {
+	argv  []string
+	stdin string
+}
+
+type fixture struct {
+	t      *testing.T
+	conn   *grpc.ClientConn
+	client Client
+
+	// Keyed by crictl subcommand.
+	output   map[string]string
+	exitCode map[string]int
+	calls    []call
+}
+
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{
+		t: t,
+		output: map[string]string{
+			"inspect":  "sandbox-1",
+			"inspectp": "tilt",
+		},
+		exitCode: make(map[string]int),
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	s, err := NewGRPCServer("test-token")
+	require.NoError(t, err)
+	RegisterServer(s, NewCRIServerWithRunner("node-1", DefaultRuntimeEndpoint, "crictl", f.run))
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	f.conn = conn
+	f.client = NewClient(conn, "test-token")
+	return f
+}
+
+func (f *fixture) run(ctx context.Context, argv []string, stdin io.Reader, out io.Writer) (int, error) {
+	c := call{argv: argv}
+	if stdin != nil {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return -1, err
+		}
+		c.stdin = string(b)
+	}
+	f.calls = append(f.calls, c)
+
+	// argv is [crictl --runtime-endpoint ENDPOINT subcommand ...]
+	sub := argv[3]
+	_, _ = io.WriteString(out, f.output[sub])
+	return f.exitCode[sub], nil
+}
@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The default CRI socket for containerd.
+const DefaultRuntimeEndpoint = "unix:///run/containerd/containerd.sock"
+
+// The label that Tilt adds to every pod it deploys. Kept in sync with
+// k8s.ManagedByLabel, without pulling the k8s package into the agent.
+const (
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "tilt"
+)
+
+// Runs a command on the node, returning its exit code.
+type Runner func(ctx context.Context, argv []string, stdin io.Reader, out io.Writer) (int, error)
+
+// CRIServer is the agent implementation. It updates containers with crictl,
+// talking to the container runtime over the CRI socket.
+type CRIServer struct {
+	node            string
+	runtimeEndpoint string
+	crictl          string
+	run             Runner
+}
+
+var _ Server = &CRIServer{}
+
+func NewCRIServer(node, runtimeEndpoint, crictl string) *CRIServer {
+	return NewCRIServerWithRunner(node, runtimeEndpoint, crictl, runOnNode)
+}
+
+func NewCRIServerWithRunner(node, runtimeEndpoint, crictl string, run Runner) *CRIServer {
+	return &CRIServer{
+		node:            node,
+		runtimeEndpoint: runtimeEndpoint,
+		crictl:          crictl,
+		run:             run,
+	}
+}
+
+func (s *CRIServer) crictlArgv(args ...string) []string {
+	return append([]string{s.crictl, "--runtime-endpoint", s.runtimeEndpoint}, args...)
+}
+
+func (s *CRIServer) Capabilities(ctx context.Context, _ *CapabilitiesRequest) (*CapabilitiesResponse, error) {
+	var out bytes.Buffer
+	code, err := s.run(ctx, s.crictlArgv("version"), nil, &out)
+	if err != nil || code != 0 {
+		return nil, status.Errorf(codes.Unavailable, "crictl version: %s", failure(code, err, out.String()))
+	}
+
+	// Output looks like:
+	// RuntimeName:  containerd
+	// RuntimeVersion:  v1.6.8
+	fields := map[string]string{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if ok {
+			fields[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return &CapabilitiesResponse{
+		Node:    s.node,
+		Runtime: fmt.Sprintf("%s://%s", fields["RuntimeName"], strings.TrimPrefix(fields["RuntimeVersion"], "v")),
+	}, nil
+}
+
+// checkContainer returns a NotFound error if the container isn't on this
+// node, and a PermissionDenied error if Tilt didn't deploy its pod.
+func (s *CRIServer) checkContainer(ctx context.Context, id string) error {
+	if id == "" {
+		return status.Error(codes.InvalidArgument, "missing container ID")
+	}
+	var out bytes.Buffer
+	code, err := s.run(ctx, s.crictlArgv("inspect", "--output", "go-template", "--template", "{{.info.sandboxID}}", id), nil, &out)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "crictl inspect: %v", err)
+	}
+	if code != 0 {
+		return status.Errorf(codes.NotFound, "container %s not found on node %s", id, s.node)
+	}
+
+	sandboxID := strings.TrimSpace(out.String())
+	out.Reset()
+	template := fmt.Sprintf("{{index .status.labels %q}}", managedByLabel)
+	code, err = s.run(ctx, s.crictlArgv("inspectp", "--output", "go-template", "--template", template, sandboxID), nil, &out)
+	if err != nil || code != 0 {
+		return status.Errorf(codes.Unavailable, "crictl inspectp: %s", failure(code, err, out.String()))
+	}
+	if strings.TrimSpace(out.String()) != managedByValue {
+		return status.Errorf(codes.PermissionDenied, "container %s is not in a pod deployed by Tilt (missing label %s=%s)",
+			id, managedByLabel, managedByValue)
+	}
+	return nil
+}
+
+func (s *CRIServer) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
+	err := s.checkContainer(ctx, req.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if len(req.Delete) > 0 {
+		argv := s.crictlArgv(append([]string{"exec", req.ContainerID, "rm", "-rf"}, req.Delete...)...)
+		code, err := s.run(ctx, argv, nil, &out)
+		if err != nil || code != 0 {
+			return nil, status.Errorf(codes.Internal, "removing old files: %s", failure(code, err, out.String()))
+		}
+	}
+
+	if len(req.Archive) > 0 {
+		argv := s.crictlArgv("exec", "-i", req.ContainerID, "tar", "-C", "/", "-x", "-f", "-")
+		code, err := s.run(ctx, argv, bytes.NewReader(req.Archive), &out)
+		if err != nil || code != 0 {
+			return nil, status.Errorf(codes.Internal, "copying changed files: %s", failure(code, err, out.String()))
+		}
+	}
+	return &UpdateResponse{Output: out.String()}, nil
+}
+
+func (s *CRIServer) Exec(ctx context.Context, req *ExecRequest) (*ExecResponse, error) {
+	err := s.checkContainer(ctx, req.ContainerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Argv) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing command")
+	}
+
+	var out bytes.Buffer
+	argv := s.crictlArgv(append([]string{"exec", req.ContainerID}, req.Argv...)...)
+	code, err := s.run(ctx, argv, nil, &out)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "crictl exec: %v", err)
+	}
+	return &ExecResponse{ExitCode: code, Output: out.String()}, nil
+}
+
+func failure(code int, err error, out string) string {
+	if err != nil {
+		return err.Error()
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return fmt.Sprintf("exit status %d", code)
+	}
+	return fmt.Sprintf("exit status %d: %s", code, out)
+}
+
+func runOnNode(ctx context.Context, argv []string, stdin io.Reader, out io.Writer) (int, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = out
+	cmd.Stderr = out
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
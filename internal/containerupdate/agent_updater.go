@@ -0,0 +1,233 @@
+package containerupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/containerupdate/agent"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store/liveupdates"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+var podGVK = v1.SchemeGroupVersion.WithKind("Pod")
+
+// Connects to the agent in the given pod, authenticating with the token.
+// Returns a function that closes the connection.
+type AgentDialer func(ctx context.Context, podID k8s.PodID, token string) (agent.Client, func(), error)
+
+// AgentUpdater updates containers through the Tilt agent daemonset, for
+// clusters that don't allow pods/exec.
+type AgentUpdater struct {
+	kCli k8s.Client
+	dial AgentDialer
+
+	mu sync.Mutex
+
+	// The agent that last updated each container. A container never moves
+	// between nodes, so we try that agent first.
+	agentFor map[container.ID]k8s.PodID
+}
+
+var _ ContainerUpdater = &AgentUpdater{}
+
+func NewAgentUpdater(kCli k8s.Client) *AgentUpdater {
+	return NewAgentUpdaterWithDialer(kCli, portForwardDialer(kCli))
+}
+
+func NewAgentUpdaterWithDialer(kCli k8s.Client, dial AgentDialer) *AgentUpdater {
+	return &AgentUpdater{
+		kCli:     kCli,
+		dial:     dial,
+		agentFor: make(map[container.ID]k8s.PodID),
+	}
+}
+
+// Agents lists the agent pods in the cluster.
+func (cu *AgentUpdater) Agents(ctx context.Context) ([]k8s.PodID, error) {
+	pods, err := cu.kCli.ListMeta(ctx, podGVK, k8s.Namespace(agent.Namespace))
+	if err != nil {
+		return nil, fmt.Errorf("listing Tilt agents: %v", err)
+	}
+
+	var result []k8s.PodID
+	for _, pod := range pods {
+		if pod.GetLabels()[agent.LabelName] != agent.LabelApp || pod.GetDeletionTimestamp() != nil {
+			continue
+		}
+		result = append(result, k8s.PodID(pod.GetName()))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
+func (cu *AgentUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Container,
+	archiveToCopy io.Reader, filesToDelete []string, cmds []model.Cmd, hotReload bool) error {
+	if !hotReload {
+		return fmt.Errorf("the Tilt agent does not support the `restart_container()` step. " +
+			"See https://github.com/tilt-dev/tilt-extensions/tree/master/restart_process for a workaround")
+	}
+
+	archive, err := io.ReadAll(archiveToCopy)
+	if err != nil {
+		return fmt.Errorf("reading changed files: %v", err)
+	}
+
+	client, closeFn, err := cu.agentWithContainer(ctx, cInfo, &agent.UpdateRequest{
+		ContainerID: cInfo.ContainerID.String(),
+		Delete:      filesToDelete,
+		Archive:     archive,
+	})
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	l := logger.Get(ctx)
+	w := l.Writer(logger.InfoLvl)
+	for i, c := range cmds {
+		l.Infof("[CMD %d/%d] %s", i+1, len(cmds), strings.Join(c.Argv, " "))
+		var resp *agent.ExecResponse
+		err := runWithTimeout(ctx, c, func(ctx context.Context) error {
+			var err error
+			resp, err = client.Exec(ctx, &agent.ExecRequest{
+				ContainerID: cInfo.ContainerID.String(),
+				Argv:        c.Argv,
+			})
+			return err
+		})
+		var timeoutErr ExecTimeoutError
+		if errors.As(err, &timeoutErr) {
+			return fmt.Errorf(
+				"executing on container %s: %w",
+				cInfo.ContainerID.ShortStr(),
+				wrapRunStepError(err),
+			)
+		}
+		if err != nil {
+			return fmt.Errorf("executing on container %s: %v", cInfo.ContainerID.ShortStr(), err)
+		}
+		_, _ = io.WriteString(w, resp.Output)
+		if resp.ExitCode != 0 {
+			return fmt.Errorf(
+				"executing on container %s: %w",
+				cInfo.ContainerID.ShortStr(),
+				wrapRunStepError(NewExecError(c, resp.ExitCode)),
+			)
+		}
+	}
+	return nil
+}
+
+// agentWithContainer sends the update to each agent until it finds the
+// one on the container's node, and returns a connection to that agent.
+func (cu *AgentUpdater) agentWithContainer(ctx context.Context, cInfo liveupdates.Container, req *agent.UpdateRequest) (agent.Client, func(), error) {
+	agents, err := cu.Agents(ctx)
+	if err != nil {
+		return agent.Client{}, nil, err
+	}
+	if len(agents) == 0 {
+		return agent.Client{}, nil, fmt.Errorf("no Tilt agent pods found in namespace %q", agent.Namespace)
+	}
+
+	token, err := cu.kCli.SecretValue(ctx, k8s.Namespace(agent.Namespace), agent.TokenSecretName, agent.TokenSecretKey)
+	if err != nil {
+		return agent.Client{}, nil, fmt.Errorf("reading Tilt agent token from secret %s/%s: %v",
+			agent.Namespace, agent.TokenSecretName, err)
+	}
+
+	cu.mu.Lock()
+	last, ok := cu.agentFor[cInfo.ContainerID]
+	cu.mu.Unlock()
+	if ok {
+		for i, podID := range agents {
+			if podID == last {
+				agents[0], agents[i] = agents[i], agents[0]
+				break
+			}
+		}
+	}
+
+	w := logger.Get(ctx).Writer(logger.InfoLvl)
+	for _, podID := range agents {
+		client, closeFn, err := cu.dial(ctx, podID, strings.TrimSpace(string(token)))
+		if err != nil {
+			return agent.Client{}, nil, fmt.Errorf("connecting to Tilt agent %s: %v", podID, err)
+		}
+
+		resp, err := client.Update(ctx, req)
+		if agent.IsNotFound(err) {
+			closeFn()
+			continue
+		}
+		if agent.IsUnauthenticated(err) {
+			closeFn()
+			return agent.Client{}, nil, fmt.Errorf("Tilt agent %s rejected the token in secret %s/%s. "+
+				"Restart the agent daemonset if the secret changed", podID, agent.Namespace, agent.TokenSecretName)
+		}
+		if err != nil {
+			closeFn()
+			return agent.Client{}, nil, fmt.Errorf("updating container %s with Tilt agent %s: %v",
+				cInfo.ContainerID.ShortStr(), podID, err)
+		}
+		_, _ = io.WriteString(w, resp.Output)
+
+		cu.mu.Lock()
+		cu.agentFor[cInfo.ContainerID] = podID
+		cu.mu.Unlock()
+		return client, closeFn, nil
+	}
+
+	return agent.Client{}, nil, fmt.Errorf("none of the %d Tilt agents found container %s (pod %s). "+
+		"Is the agent daemonset running on every node?", len(agents), cInfo.ContainerID.ShortStr(), cInfo.PodID)
+}
+
+// portForwardDialer connects to agents through a port-forward, so Tilt
+// doesn't need network access to the nodes.
+func portForwardDialer(kCli k8s.Client) AgentDialer {
+	return func(ctx context.Context, podID k8s.PodID, token string) (agent.Client, func(), error) {
+		ctx, cancel := context.WithCancel(ctx)
+		pf, err := kCli.CreatePortForwarder(ctx, k8s.Namespace(agent.Namespace), podID, 0, agent.Port, "")
+		if err != nil {
+			cancel()
+			return agent.Client{}, nil, err
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- pf.ForwardPorts()
+		}()
+
+		select {
+		case <-pf.ReadyCh():
+		case err := <-errCh:
+			cancel()
+			return agent.Client{}, nil, fmt.Errorf("port-forward: %v", err)
+		case <-ctx.Done():
+			cancel()
+			return agent.Client{}, nil, ctx.Err()
+		}
+
+		conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", pf.LocalPort()),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			cancel()
+			return agent.Client{}, nil, err
+		}
+		return agent.NewClient(conn, token), func() {
+			_ = conn.Close()
+			cancel()
+		}, nil
+	}
+}
@@ -0,0 +1,180 @@
+package containerupdate
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/containerupdate/agent"
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+func TestAgentUpdaterFindsContainerNode(t *testing.T) {
+	f := newAgentFixture(t)
+	f.addAgent("agent-a")
+	b := f.addAgent("agent-b")
+	b.containers[TestContainerInfo.ContainerID.String()] = true
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), toDelete, cmds, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"boop"}, b.archives)
+	assert.Equal(t, [][]string{cmdA.Argv, cmdB.Argv}, b.execs)
+	assert.Equal(t, k8s.PodID("agent-b"), f.acu.agentFor[TestContainerInfo.ContainerID])
+}
+
+func TestAgentUpdaterCommandFails(t *testing.T) {
+	f := newAgentFixture(t)
+	a := f.addAgent("agent-a")
+	a.containers[TestContainerInfo.ContainerID.String()] = true
+	a.exitCode = 1
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), nil, cmds, true)
+	if assert.Error(t, err) {
+		assert.True(t, build.IsRunStepFailure(err))
+	}
+}
+
+func TestAgentUpdaterNoAgentHasContainer(t *testing.T) {
+	f := newAgentFixture(t)
+	f.addAgent("agent-a")
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), nil, cmds, true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "none of the 1 Tilt agents found container")
+	}
+}
+
+func TestAgentUpdaterWrongToken(t *testing.T) {
+	f := newAgentFixture(t)
+	a := f.addAgent("agent-a")
+	a.containers[TestContainerInfo.ContainerID.String()] = true
+	f.kCli.SecretValues["tilt-agent/tilt-agent/token"] = []byte("stale-token")
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), nil, cmds, true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Tilt agent agent-a rejected the token in secret tilt-agent/tilt-agent")
+	}
+	assert.Empty(t, a.archives)
+}
+
+func TestAgentUpdaterNoToken(t *testing.T) {
+	f := newAgentFixture(t)
+	a := f.addAgent("agent-a")
+	a.containers[TestContainerInfo.ContainerID.String()] = true
+	f.kCli.SecretValues = nil
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), nil, cmds, true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "reading Tilt agent token from secret tilt-agent/tilt-agent")
+	}
+}
+
+func TestAgentUpdaterNoAgents(t *testing.T) {
+	f := newAgentFixture(t)
+
+	err := f.acu.UpdateContainer(f.ctx, TestContainerInfo, newReader("boop"), nil, cmds, true)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `no Tilt agent pods found in namespace "tilt-agent"`)
+	}
+}
+
+const testAgentToken = "test-token"
+
+type fakeAgent struct {
+	containers map[string]bool
+	exitCode   int
+
+	archives []string
+	execs    [][]string
+}
+
+func (a *fakeAgent) Capabilities(ctx context.Context, req *agent.CapabilitiesRequest) (*agent.CapabilitiesResponse, error) {
+	return &agent.CapabilitiesResponse{}, nil
+}
+
+func (a *fakeAgent) Update(ctx context.Context, req *agent.UpdateRequest) (*agent.UpdateResponse, error) {
+	if !a.containers[req.ContainerID] {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	a.archives = append(a.archives, string(req.Archive))
+	return &agent.UpdateResponse{}, nil
+}
+
+func (a *fakeAgent) Exec(ctx context.Context, req *agent.ExecRequest) (*agent.ExecResponse, error) {
+	if !a.containers[req.ContainerID] {
+		return nil, status.Error(codes.NotFound, "not found")
+	}
+	a.execs = append(a.execs, req.Argv)
+	return &agent.ExecResponse{ExitCode: a.exitCode}, nil
+}
+
+type agentUpdaterFixture struct {
+	t      testing.TB
+	ctx    context.Context
+	kCli   *k8s.FakeK8sClient
+	acu    *AgentUpdater
+	agents map[k8s.PodID]*bufconn.Listener
+}
+
+func newAgentFixture(t testing.TB) *agentUpdaterFixture {
+	f := &agentUpdaterFixture{
+		t:      t,
+		kCli:   k8s.NewFakeK8sClient(t),
+		agents: make(map[k8s.PodID]*bufconn.Listener),
+	}
+	f.kCli.SecretValues = map[string][]byte{"tilt-agent/tilt-agent/token": []byte(testAgentToken)}
+	f.ctx, _, _ = testutils.CtxAndAnalyticsForTest()
+	f.acu = NewAgentUpdaterWithDialer(f.kCli, f.dial)
+	return f
+}
+
+func (f *agentUpdaterFixture) addAgent(name string) *fakeAgent {
+	a := &fakeAgent{containers: make(map[string]bool)}
+	lis := bufconn.Listen(1024 * 1024)
+	s, err := agent.NewGRPCServer(testAgentToken)
+	require.NoError(f.t, err)
+	agent.RegisterServer(s, a)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	f.t.Cleanup(s.Stop)
+	f.agents[k8s.PodID(name)] = lis
+
+	f.kCli.Inject(k8s.NewK8sEntity(&v1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: agent.Namespace,
+			UID:       types.UID(name + "-uid"),
+			Labels:    map[string]string{agent.LabelName: agent.LabelApp},
+		},
+	}))
+	return a
+}
+
+func (f *agentUpdaterFixture) dial(ctx context.Context, podID k8s.PodID, token string) (agent.Client, func(), error) {
+	lis := f.agents[podID]
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return agent.Client{}, nil, err
+	}
+	return agent.NewClient(conn, token), func() { _ = conn.Close() }, nil
+}
@@ -81,5 +81,11 @@ func wrapRunStepError(err error) error {
 		}
 		return build.NewRunStepFailure(err)
 	}
+
+	// A run() command that hangs is the user's to fix, like one that fails.
+	var timeoutErr ExecTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return build.NewRunStepFailure(err)
+	}
 	return err
 }
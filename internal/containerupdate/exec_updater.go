@@ -23,6 +23,11 @@ func NewExecUpdater(kCli k8s.Client) *ExecUpdater {
 	return &ExecUpdater{kCli: kCli}
 }
 
+// CanExec checks whether the user may exec into pods in the namespace.
+func (cu *ExecUpdater) CanExec(ctx context.Context, ns k8s.Namespace) (bool, error) {
+	return cu.kCli.CanExec(ctx, ns)
+}
+
 func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Container,
 	archiveToCopy io.Reader, filesToDelete []string, cmds []model.Cmd, hotReload bool) error {
 	if !hotReload {
@@ -60,8 +65,10 @@ func (cu *ExecUpdater) UpdateContainer(ctx context.Context, cInfo liveupdates.Co
 	// run commands
 	for i, c := range cmds {
 		l.Infof("[CMD %d/%d] %s", i+1, len(cmds), strings.Join(c.Argv, " "))
-		err := cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
-			c.Argv, nil, w, w)
+		err := runWithTimeout(ctx, c, func(ctx context.Context) error {
+			return cu.kCli.Exec(ctx, cInfo.PodID, cInfo.ContainerName, cInfo.Namespace,
+				c.Argv, nil, w, w)
+		})
 		if err != nil {
 			return fmt.Errorf(
 				"executing on container %s: %w",
@@ -0,0 +1,47 @@
+package containerupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type execTimeoutKey struct{}
+
+// WithExecTimeout sets how long each command that a live update runs in a
+// container may run before it's canceled. 0 means no timeout.
+func WithExecTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, execTimeoutKey{}, timeout)
+}
+
+// ExecTimeoutError indicates that a live update command ran longer than the
+// exec timeout.
+type ExecTimeoutError struct {
+	Cmd     model.Cmd
+	Timeout time.Duration
+}
+
+func (e ExecTimeoutError) Error() string {
+	return fmt.Sprintf("command %q timed out after %s", e.Cmd.String(), e.Timeout)
+}
+
+// runWithTimeout runs a live update command, canceling it if it runs past
+// the exec timeout.
+func runWithTimeout(ctx context.Context, cmd model.Cmd, run func(ctx context.Context) error) error {
+	timeout, _ := ctx.Value(execTimeoutKey{}).(time.Duration)
+	if timeout <= 0 {
+		return run(ctx)
+	}
+
+	cmdCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := run(cmdCtx)
+	if err != nil && errors.Is(cmdCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return ExecTimeoutError{Cmd: cmd, Timeout: timeout}
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package containerupdate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	cmd := model.Cmd{Argv: []string{"sleep", "60"}}
+	ctx := WithExecTimeout(context.Background(), 10*time.Millisecond)
+
+	err := runWithTimeout(ctx, cmd, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, ExecTimeoutError{Cmd: cmd, Timeout: 10 * time.Millisecond}, err)
+	assert.EqualError(t, err, `command "sleep 60" timed out after 10ms`)
+	assert.True(t, build.IsRunStepFailure(wrapRunStepError(err)))
+}
+
+func TestRunWithTimeoutUnset(t *testing.T) {
+	cmd := model.Cmd{Argv: []string{"make"}}
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		WithExecTimeout(context.Background(), 0),
+	} {
+		err := runWithTimeout(ctx, cmd, func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.False(t, hasDeadline)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestRunWithTimeoutParentCanceled(t *testing.T) {
+	cmd := model.Cmd{Argv: []string{"make"}}
+	ctx, cancel := context.WithCancel(WithExecTimeout(context.Background(), time.Minute))
+	cancel()
+
+	err := runWithTimeout(ctx, cmd, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,53 @@
+package configmap
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The key of the all-log-containers ConfigMap that holds whether to show
+// the logs of all the resource's containers.
+const AllLogContainersKey = "showAll"
+
+// The name of the ConfigMap that turns off a resource's log container
+// filters (i.e., log_containers and ignore_log_containers in the
+// Tiltfile) at runtime, so that the resource's logs include all its
+// containers.
+func AllLogContainersName(resource string) string {
+	return fmt.Sprintf("%s-all-log-containers", resource)
+}
+
+// Whether a resource's logs should include all its containers, ignoring
+// its log container filters. False if the ConfigMap doesn't exist.
+func ShowAllLogContainers(ctx context.Context, client client.Client, resource string) (bool, error) {
+	if resource == "" {
+		return false, nil
+	}
+
+	var cm v1alpha1.ConfigMap
+	err := client.Get(ctx, types.NamespacedName{Name: AllLogContainersName(resource)}, &cm)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	val, ok := cm.Data[AllLogContainersKey]
+	if !ok {
+		return false, nil
+	}
+	showAll, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("ConfigMap/key %q/%q value %q is not a bool: %v",
+			cm.Name, AllLogContainersKey, val, err)
+	}
+	return showAll, nil
+}
@@ -0,0 +1,86 @@
+package imagemap
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/docker/distribution/reference"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+var refPlaceholderRe = regexp.MustCompile(`tilt-image-ref\[([^\]\s"']+)\]`)
+
+// A placeholder for the ref of an image that Tilt builds.
+//
+// Tilt can only inject images into fields it knows about (container
+// images, env vars, and image locators). The placeholder lets users put
+// the built ref anywhere else in the YAML (e.g., a helm value), and the
+// KubernetesApply reconciler replaces it once the image is built.
+func RefPlaceholder(ref reference.Named) string {
+	return fmt.Sprintf("tilt-image-ref[%s]", container.FamiliarString(ref))
+}
+
+// FindRefPlaceholders returns the refs of all the placeholders in s.
+func FindRefPlaceholders(s string) ([]reference.Named, error) {
+	var result []reference.Named
+	for _, match := range refPlaceholderRe.FindAllStringSubmatch(s, -1) {
+		ref, err := container.ParseNamed(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("parsing image ref placeholder %q: %v", match[0], err)
+		}
+		result = append(result, ref)
+	}
+	return result, nil
+}
+
+// InjectRefPlaceholders replaces each placeholder in the YAML with the
+// ref of the matching image, from the point of view of the cluster.
+//
+// Returns the new YAML, and the names of the image maps that were injected.
+func InjectRefPlaceholders(yaml string, imageMapNames []string, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) (string, map[string]bool, error) {
+	injected := map[string]bool{}
+	var injectErr error
+	result := refPlaceholderRe.ReplaceAllStringFunc(yaml, func(placeholder string) string {
+		if injectErr != nil {
+			return placeholder
+		}
+
+		match := refPlaceholderRe.FindStringSubmatch(placeholder)
+		ref, err := container.ParseNamed(match[1])
+		if err != nil {
+			injectErr = fmt.Errorf("parsing image ref placeholder %q: %v", placeholder, err)
+			return placeholder
+		}
+
+		for _, name := range imageMapNames {
+			imageMap, ok := imageMaps[types.NamespacedName{Name: name}]
+			if !ok {
+				continue
+			}
+			selector, err := container.SelectorFromImageMap(imageMap.Spec)
+			if err != nil {
+				injectErr = err
+				return placeholder
+			}
+			if !selector.Matches(ref) {
+				continue
+			}
+			if imageMap.Status.ImageFromCluster == "" {
+				injectErr = fmt.Errorf("internal error: missing image status for %s", name)
+				return placeholder
+			}
+			injected[name] = true
+			return imageMap.Status.ImageFromCluster
+		}
+
+		injectErr = fmt.Errorf("no image build found for %s", placeholder)
+		return placeholder
+	})
+	if injectErr != nil {
+		return "", nil, injectErr
+	}
+	return result, injected, nil
+}
@@ -20,3 +20,10 @@ package liveupdate
 const AnnotationUpdateMode = "tilt.dev/update-mode"
 const UpdateModeAuto = "auto"
 const UpdateModeManual = "manual"
+
+// If set, the live-update steps weren't the ones declared on the image,
+// and this says where they came from (e.g., k8s_resource("worker")).
+//
+// Several resources can deploy the same image with different steps, so
+// this helps explain which steps ran for which pods.
+const AnnotationStepSource = "tilt.dev/live-update-steps"
@@ -0,0 +1,66 @@
+package uibutton
+
+import (
+	"fmt"
+
+	"github.com/kballard/go-shellquote"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// The name of the text input with the new args.
+const ArgsInputName = "args"
+
+func ArgsButtonName(tiltfileName string) string {
+	return fmt.Sprintf("%s-args", tiltfileName)
+}
+
+// ArgsButton replaces the args of a running Tiltfile, like `tilt args`.
+// The input defaults to the current args.
+func ArgsButton(tiltfileName string, args []string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ArgsButtonName(tiltfileName),
+			Annotations: map[string]string{
+				v1alpha1.AnnotationButtonType: v1alpha1.ButtonTypeSetArgs,
+			},
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   tiltfileName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:     "Set Args",
+			IconName: "tune",
+			Inputs: []v1alpha1.UIInputSpec{
+				{
+					Name:  ArgsInputName,
+					Label: "Tiltfile args",
+					Text: &v1alpha1.UITextInputSpec{
+						DefaultValue: shellquote.Join(args...),
+						Placeholder:  "--services=api,db",
+					},
+				},
+			},
+		},
+	}
+}
+
+// ArgsInputValue returns the args the user submitted, if the button has
+// been clicked.
+func ArgsInputValue(b *v1alpha1.UIButton) ([]string, bool, error) {
+	if b.Status.LastClickedAt.IsZero() {
+		return nil, false, nil
+	}
+	for _, input := range b.Status.Inputs {
+		if input.Name == ArgsInputName && input.Text != nil {
+			args, err := shellquote.Split(input.Text.Value)
+			if err != nil {
+				return nil, false, fmt.Errorf("parsing Tiltfile args %q: %v", input.Text.Value, err)
+			}
+			return args, true, nil
+		}
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,35 @@
+package uibutton
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func ResetButtonName(resourceName string) string {
+	return fmt.Sprintf("%s-reset", resourceName)
+}
+
+// ResetButton clears a resource's build state and rebuilds it from scratch.
+// It deletes the resource's objects, so it asks for confirmation.
+func ResetButton(resourceName string) *v1alpha1.UIButton {
+	return &v1alpha1.UIButton{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: ResetButtonName(resourceName),
+			Annotations: map[string]string{
+				v1alpha1.AnnotationButtonType: v1alpha1.ButtonTypeReset,
+			},
+		},
+		Spec: v1alpha1.UIButtonSpec{
+			Location: v1alpha1.UIComponentLocation{
+				ComponentID:   resourceName,
+				ComponentType: v1alpha1.ComponentTypeResource,
+			},
+			Text:                 "Reset",
+			IconName:             "restart_alt",
+			RequiresConfirmation: true,
+		},
+	}
+}
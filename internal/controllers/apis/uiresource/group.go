@@ -0,0 +1,22 @@
+package uiresource
+
+import (
+	"strings"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// AnnotationGroupMembers marks a UIResource as a group of other resources
+// (from resource_group() in the Tiltfile). The value is the comma-separated
+// names of the members.
+const AnnotationGroupMembers = "tilt.dev/resource-group-members"
+
+// GroupMembers returns the members of a resource group, or nil if the
+// UIResource isn't a group.
+func GroupMembers(r *v1alpha1.UIResource) []string {
+	members := r.Annotations[AnnotationGroupMembers]
+	if members == "" {
+		return nil
+	}
+	return strings.Split(members, ",")
+}
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -17,11 +18,19 @@ import (
 )
 
 func NewConnectionManager() *ConnectionManager {
-	return &ConnectionManager{}
+	return &ConnectionManager{changed: make(chan struct{})}
 }
 
 type ConnectionManager struct {
 	connections sync.Map
+
+	mu sync.Mutex
+
+	// Closed (and replaced) whenever a connection is stored.
+	changed chan struct{}
+
+	// Asks the reconciler to rebuild a connection.
+	requestRefresh func(key types.NamespacedName, cause string)
 }
 
 var _ cluster.ClientProvider = &ConnectionManager{}
@@ -55,6 +64,17 @@ type connection struct {
 	serverVersion string
 	registry      *v1alpha1.RegistryHosting
 	connStatus    *v1alpha1.ClusterConnectionStatus
+
+	// The config files the client was created from, and their fingerprint
+	// at the time, so that we can tell when they change.
+	configPaths       []string
+	configFingerprint string
+
+	// The value of the refresh annotation when the connection was created.
+	refreshRequest string
+
+	// When we last failed to replace this connection with a new one.
+	refreshFailedAt time.Time
 }
 
 func (k *ConnectionManager) GetK8sClient(clusterKey types.NamespacedName) (k8s.Client, metav1.MicroTime, error) {
@@ -62,7 +82,13 @@ func (k *ConnectionManager) GetK8sClient(clusterKey types.NamespacedName) (k8s.C
 	if err != nil {
 		return nil, metav1.MicroTime{}, err
 	}
-	return conn.k8sClient, apis.NewMicroTime(conn.createdAt), nil
+	client := refreshingK8sClient{
+		Client:    conn.k8sClient,
+		key:       clusterKey,
+		createdAt: conn.createdAt,
+		manager:   k,
+	}
+	return client, apis.NewMicroTime(conn.createdAt), nil
 }
 
 // GetComposeDockerClient gets the Docker client for the instance that Docker Compose is deploying to.
@@ -96,6 +122,54 @@ func (k *ConnectionManager) validConnOrError(key types.NamespacedName, connType
 
 func (k *ConnectionManager) store(key types.NamespacedName, conn connection) {
 	k.connections.Store(key, conn)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.changed != nil {
+		close(k.changed)
+	}
+	k.changed = make(chan struct{})
+}
+
+func (k *ConnectionManager) setRefreshHandler(f func(key types.NamespacedName, cause string)) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.requestRefresh = f
+}
+
+// Asks for the connection that was created at since to be rebuilt, and
+// waits for a working connection to replace it.
+//
+// Returns false if none does before refreshRetryTimeout.
+func (k *ConnectionManager) refresh(ctx context.Context, key types.NamespacedName, since time.Time, cause string) (connection, bool) {
+	k.mu.Lock()
+	requestRefresh := k.requestRefresh
+	k.mu.Unlock()
+	if requestRefresh == nil {
+		return connection{}, false
+	}
+	requestRefresh(key, cause)
+
+	timeout := time.NewTimer(refreshRetryTimeout)
+	defer timeout.Stop()
+	for {
+		k.mu.Lock()
+		changed := k.changed
+		k.mu.Unlock()
+
+		conn, ok := k.load(key)
+		if ok && conn.initError == "" && conn.createdAt.After(since) {
+			return conn, true
+		}
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			return connection{}, false
+		case <-ctx.Done():
+			return connection{}, false
+		}
+	}
 }
 
 func (k *ConnectionManager) load(key types.NamespacedName) (connection, bool) {
@@ -1,11 +1,17 @@
 package cluster
 
 import (
+	"context"
+	"errors"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
@@ -98,3 +104,59 @@ func TestConnectionManager(t *testing.T) {
 		})
 	}
 }
+
+// A client whose credentials have expired.
+type expiredK8sClient struct {
+	k8s.Client
+}
+
+func (expiredK8sClient) ListMeta(_ context.Context, _ schema.GroupVersionKind, _ k8s.Namespace) ([]metav1.Object, error) {
+	return nil, apierrors.NewUnauthorized("token expired")
+}
+
+func TestConnectionManagerRetriesOnRefreshedClient(t *testing.T) {
+	cm := NewConnectionManager()
+	nn := types.NamespacedName{Name: "default"}
+	createdAt := time.Now()
+	cm.store(nn, connection{
+		connType:  connectionTypeK8s,
+		k8sClient: expiredK8sClient{Client: k8s.NewFakeK8sClient(t)},
+		createdAt: createdAt,
+	})
+
+	var causes []string
+	cm.setRefreshHandler(func(key types.NamespacedName, cause string) {
+		causes = append(causes, cause)
+		go cm.store(key, connection{
+			connType:  connectionTypeK8s,
+			k8sClient: k8s.NewFakeK8sClient(t),
+			createdAt: createdAt.Add(time.Second),
+		})
+	})
+
+	kCli, _, err := cm.GetK8sClient(nn)
+	require.NoError(t, err)
+	_, err = kCli.ListMeta(context.Background(), schema.GroupVersionKind{Version: "v1", Kind: "Node"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{refreshCauseCredentials}, causes)
+}
+
+func TestConnectionManagerNoRetryOnOtherErrors(t *testing.T) {
+	cm := NewConnectionManager()
+	nn := types.NamespacedName{Name: "default"}
+	fakeK8s := k8s.NewFakeK8sClient(t)
+	fakeK8s.UpsertError = errors.New("invalid object")
+	cm.store(nn, connection{
+		connType:  connectionTypeK8s,
+		k8sClient: fakeK8s,
+		createdAt: time.Now(),
+	})
+	cm.setRefreshHandler(func(key types.NamespacedName, cause string) {
+		t.Fatalf("unexpected refresh: %s", cause)
+	})
+
+	kCli, _, err := cm.GetK8sClient(nn)
+	require.NoError(t, err)
+	_, err = kCli.Upsert(context.Background(), nil, time.Second)
+	require.EqualError(t, err, "invalid object")
+}
@@ -68,6 +68,29 @@ func (c *clusterHealthMonitor) UpdateStatus(ctx context.Context, clusterNN types
 	}
 }
 
+// RequestRefresh asks for the cluster's connection to be rebuilt, and
+// requeues the cluster. The first cause wins until the connection is rebuilt.
+func (c *clusterHealthMonitor) RequestRefresh(clusterNN types.NamespacedName, cause string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, ok := c.monitors[clusterNN]
+	if !ok || m.refreshCause != "" {
+		return
+	}
+	m.refreshCause = cause
+	c.monitors[clusterNN] = m
+	c.requeuer.Add(clusterNN)
+}
+
+// GetRefreshCause returns why the connection needs to be rebuilt, or ""
+// if it doesn't.
+func (c *clusterHealthMonitor) GetRefreshCause(clusterNN types.NamespacedName) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.monitors[clusterNN].refreshCause
+}
+
 func (c *clusterHealthMonitor) Stop(clusterNN types.NamespacedName) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -84,24 +107,35 @@ func (c *clusterHealthMonitor) cleanup(clusterNN types.NamespacedName) {
 }
 
 type monitor struct {
-	cancel context.CancelFunc
-	error  string
+	cancel       context.CancelFunc
+	error        string
+	refreshCause string
 }
 
 func (c *clusterHealthMonitor) run(ctx context.Context, clusterNN types.NamespacedName, conn connection) {
-	if conn.connType != connectionTypeK8s {
-		// live connection monitoring for Docker not yet supported
-		return
-	}
-
 	ticker := c.clock.NewTicker(clientHealthPollInterval)
 	defer ticker.Stop()
 	for {
-		err := doKubernetesHealthCheck(ctx, conn.k8sClient)
-		if err != nil {
-			c.UpdateStatus(ctx, clusterNN, err.Error())
-		} else {
-			c.UpdateStatus(ctx, clusterNN, "")
+		// live health checks for Docker not yet supported
+		if conn.connType == connectionTypeK8s {
+			err := doKubernetesHealthCheck(ctx, conn.k8sClient)
+			if err != nil {
+				c.UpdateStatus(ctx, clusterNN, err.Error())
+			} else {
+				c.UpdateStatus(ctx, clusterNN, "")
+			}
+			if isCredentialError(err) && ctx.Err() == nil {
+				c.RequestRefresh(clusterNN, refreshCauseCredentials)
+			}
+		}
+
+		if len(conn.configPaths) != 0 && ctx.Err() == nil &&
+			configFingerprint(conn.configPaths) != conn.configFingerprint {
+			cause := refreshCauseKubeconfig
+			if conn.connType == connectionTypeDocker {
+				cause = refreshCauseDockerConfig
+			}
+			c.RequestRefresh(clusterNN, cause)
 		}
 
 		select {
@@ -62,6 +62,13 @@ type Reconciler struct {
 	wsList           *server.WebsocketList
 
 	clusterHealth *clusterHealthMonitor
+
+	// Where to look for config changes that need a new connection.
+	kubeconfigPaths   func() []string
+	dockerConfigPaths func() []string
+
+	// Reads the docker context again, when the docker config changes.
+	dockerCLIClient func(ctx context.Context) (docker.DaemonClient, error)
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -86,7 +93,7 @@ func NewReconciler(
 ) *Reconciler {
 	requeuer := indexer.NewRequeuer()
 
-	return &Reconciler{
+	r := &Reconciler{
 		globalCtx:           globalCtx,
 		ctrlClient:          ctrlClient,
 		store:               store,
@@ -100,7 +107,12 @@ func NewReconciler(
 		clusterHealth:       newClusterHealthMonitor(globalCtx, clock, requeuer),
 		base:                base,
 		apiServerName:       apiServerName,
+		kubeconfigPaths:     kubeconfigPaths,
+		dockerConfigPaths:   dockerConfigPaths,
+		dockerCLIClient:     docker.RealClientCreator{}.FromCLI,
 	}
+	connManager.setRefreshHandler(r.clusterHealth.RequestRefresh)
+	return r
 }
 
 func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -147,33 +159,13 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	var requeueAfter time.Duration
 	if !hasConnection {
 		// Create the initial connection to the cluster.
-		conn = connection{spec: *obj.Spec.DeepCopy(), createdAt: r.clock.Now()}
-		if obj.Spec.Connection != nil && obj.Spec.Connection.Kubernetes != nil {
-			conn.connType = connectionTypeK8s
-			client, err := r.createKubernetesClient(obj.DeepCopy())
-			if err != nil {
-				var initError string
-				if !clusterRefreshEnabled {
-					initError = fmt.Sprintf(
-						"Tilt encountered an error connecting to your Kubernetes cluster:"+
-							"\n\t%v"+
-							"\nYou will need to restart Tilt after resolving the issue.",
-						err)
-				} else {
-					initError = err.Error()
-				}
-				conn.initError = initError
-			} else {
-				conn.k8sClient = client
-			}
-		} else if obj.Spec.Connection != nil && obj.Spec.Connection.Docker != nil {
-			conn.connType = connectionTypeDocker
-			client, err := r.createDockerClient(obj.Spec.Connection.Docker)
-			if err != nil {
-				conn.initError = err.Error()
-			} else {
-				conn.dockerClient = client
-			}
+		conn = r.connect(&obj, false)
+		if conn.initError != "" && conn.connType == connectionTypeK8s && !clusterRefreshEnabled {
+			conn.initError = fmt.Sprintf(
+				"Tilt encountered an error connecting to your Kubernetes cluster:"+
+					"\n\t%v"+
+					"\nYou will need to restart Tilt after resolving the issue.",
+				conn.initError)
 		}
 
 		if conn.initError != "" {
@@ -184,6 +176,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 			// for reconciliation if its runtime status changes
 			r.clusterHealth.Start(nn, conn)
 		}
+	} else if cause := r.refreshCause(nn, &obj, conn); cause != "" {
+		var ok bool
+		conn, ok = r.refresh(ctx, nn, &obj, conn, cause)
+		if !ok {
+			requeueAfter = clientInitBackoff
+		}
 	}
 
 	r.populateClusterMetadata(ctx, nn, &conn)
@@ -203,11 +201,87 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	return ctrl.Result{RequeueAfter: requeueAfter}, nil
 }
 
+// Creates a connection to the cluster from the spec. If the client can't be
+// created, the connection has an initError.
+//
+// When refreshing, re-reads the docker context, in case it was switched.
+func (r *Reconciler) connect(obj *v1alpha1.Cluster, refreshing bool) connection {
+	conn := connection{
+		spec:           *obj.Spec.DeepCopy(),
+		createdAt:      r.clock.Now(),
+		refreshRequest: obj.Annotations[v1alpha1.AnnotationRefreshConnection],
+	}
+	if obj.Spec.Connection != nil && obj.Spec.Connection.Kubernetes != nil {
+		conn.connType = connectionTypeK8s
+		conn.configPaths = r.kubeconfigPaths()
+		conn.configFingerprint = configFingerprint(conn.configPaths)
+		client, err := r.createKubernetesClient(obj.DeepCopy())
+		if err != nil {
+			conn.initError = err.Error()
+		} else {
+			conn.k8sClient = client
+		}
+	} else if obj.Spec.Connection != nil && obj.Spec.Connection.Docker != nil {
+		conn.connType = connectionTypeDocker
+		if obj.Spec.Connection.Docker.Host == "" {
+			conn.configPaths = r.dockerConfigPaths()
+			conn.configFingerprint = configFingerprint(conn.configPaths)
+		}
+		client, err := r.createDockerClient(obj.Spec.Connection.Docker, refreshing)
+		if err != nil {
+			conn.initError = err.Error()
+		} else {
+			conn.dockerClient = client
+		}
+	}
+	return conn
+}
+
+// Why a working connection needs to be rebuilt, or "" if it doesn't.
+func (r *Reconciler) refreshCause(nn types.NamespacedName, obj *v1alpha1.Cluster, conn connection) string {
+	if conn.initError != "" {
+		// Connections that never worked are retried on their own schedule.
+		return ""
+	}
+	if !conn.refreshFailedAt.IsZero() && r.clock.Now().Before(conn.refreshFailedAt.Add(clientInitBackoff)) {
+		return ""
+	}
+	if obj.Annotations[v1alpha1.AnnotationRefreshConnection] != conn.refreshRequest {
+		return refreshCauseRequested
+	}
+	return r.clusterHealth.GetRefreshCause(nn)
+}
+
+// Rebuilds the connection to the cluster, and swaps in the new one once it
+// works, so that the cluster never looks disconnected in between.
+//
+// If it doesn't work, keeps the old connection (which may still be fine),
+// and returns false so that we try again later.
+func (r *Reconciler) refresh(ctx context.Context, nn types.NamespacedName, obj *v1alpha1.Cluster, old connection, cause string) (connection, bool) {
+	logger.Get(ctx).Infof("Refreshing connection to cluster %q: %s", nn.Name, cause)
+
+	conn := r.connect(obj, true)
+	r.reportRefreshEvent(ctx, obj, cause, conn.initError)
+	if conn.initError != "" {
+		logger.Get(ctx).Warnf("Refreshing connection to cluster %q failed, still using the old connection: %s",
+			nn.Name, conn.initError)
+		old.refreshFailedAt = r.clock.Now()
+		return old, false
+	}
+
+	r.clusterHealth.Start(nn, conn)
+	return conn, true
+}
+
 // Creates a docker connection from the spec.
-func (r *Reconciler) createDockerClient(obj *v1alpha1.DockerClusterConnection) (docker.Client, error) {
+func (r *Reconciler) createDockerClient(obj *v1alpha1.DockerClusterConnection, refreshing bool) (docker.Client, error) {
 	// If no Host is specified, use the default Env from environment variables.
 	env := docker.Env(r.localDockerEnv)
-	if obj.Host != "" {
+	if obj.Host == "" && refreshing {
+		d, err := r.dockerCLIClient(r.globalCtx)
+		env.Client = d
+		env.Error = err
+	} else if obj.Host != "" {
 		d, err := client.NewClientWithOpts(client.WithHost(obj.Host))
 		env.Client = d
 		if err != nil {
@@ -315,6 +389,26 @@ func (r *Reconciler) reportConnectionEvent(ctx context.Context, cluster *v1alpha
 	analytics.Get(ctx).Incr("api.cluster.connect", tags)
 }
 
+func (r *Reconciler) reportRefreshEvent(ctx context.Context, cluster *v1alpha1.Cluster, cause string, initError string) {
+	tags := map[string]string{"cause": cause}
+
+	if cluster.Spec.Connection != nil {
+		if cluster.Spec.Connection.Kubernetes != nil {
+			tags["type"] = "kubernetes"
+		} else if cluster.Spec.Connection.Docker != nil {
+			tags["type"] = "docker"
+		}
+	}
+
+	if initError == "" {
+		tags["status"] = "connected"
+	} else {
+		tags["status"] = "error"
+	}
+
+	analytics.Get(ctx).Incr("api.cluster.refresh", tags)
+}
+
 func (r *Reconciler) populateClusterMetadata(ctx context.Context, clusterNN types.NamespacedName, conn *connection) {
 	if conn.initError != "" {
 		return
@@ -1,8 +1,10 @@
 package cluster
 
 import (
+	"context"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -260,9 +263,173 @@ func TestDockerArch(t *testing.T) {
 	}
 }
 
+func TestKubernetesRefreshRequested(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+	f.assertSteadyState(cluster)
+
+	f.clock.Advance(time.Second)
+	cluster.Annotations = map[string]string{v1alpha1.AnnotationRefreshConnection: "now"}
+	f.Update(cluster)
+
+	f.MustGet(nn, cluster)
+	assert.Empty(t, cluster.Status.Error)
+	assert.True(t, cluster.Status.ConnectedAt.After(connectedAt.Time), "ConnectedAt should be bumped")
+	f.assertSteadyState(cluster)
+	assert.Contains(t, f.ma.Counts, analytics.CountEvent{
+		Name: "api.cluster.refresh",
+		Tags: map[string]string{
+			"type":   "kubernetes",
+			"cause":  "refresh requested",
+			"status": "connected",
+		},
+		N: 1,
+	})
+}
+
+func TestKubernetesRefreshOnKubeconfigChange(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+	f.assertSteadyState(cluster)
+
+	f.tmpf.WriteFile("kubeconfig", "current-context: rotated")
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	assert.Empty(t, cluster.Status.Error)
+	assert.True(t, cluster.Status.ConnectedAt.After(connectedAt.Time), "ConnectedAt should be bumped")
+}
+
+func TestKubernetesRefreshOnExpiredCredentials(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+	f.assertSteadyState(cluster)
+
+	f.k8sClient.ClusterHealthError = apierrors.NewUnauthorized("token expired")
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	f.MustGet(nn, cluster)
+	assert.True(t, cluster.Status.ConnectedAt.After(connectedAt.Time), "ConnectedAt should be bumped")
+}
+
+func TestKubernetesRefreshErrorKeepsConnection(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Kubernetes: &v1alpha1.KubernetesClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+
+	origClientFactory := f.r.k8sClientFactory
+	f.r.k8sClientFactory = FakeKubernetesClientOrError(nil, errors.New("fake error"))
+	f.clock.Advance(time.Second)
+	cluster.Annotations = map[string]string{v1alpha1.AnnotationRefreshConnection: "now"}
+	f.Update(cluster)
+
+	// the old connection still works
+	f.MustGet(nn, cluster)
+	assert.Empty(t, cluster.Status.Error)
+	timecmp.RequireTimeEqual(t, connectedAt, cluster.Status.ConnectedAt)
+	_, _, err := f.r.connManager.GetK8sClient(nn)
+	require.NoError(t, err)
+
+	// and we try again after a while
+	f.r.k8sClientFactory = origClientFactory
+	f.assertSteadyState(cluster)
+	f.clock.Advance(time.Minute)
+	f.MustReconcile(nn)
+	f.MustGet(nn, cluster)
+	assert.True(t, cluster.Status.ConnectedAt.After(connectedAt.Time), "ConnectedAt should be bumped")
+}
+
+func TestDockerRefreshOnContextChange(t *testing.T) {
+	f := newFixture(t)
+	cluster := &v1alpha1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: v1alpha1.ClusterSpec{
+			Connection: &v1alpha1.ClusterConnection{
+				Docker: &v1alpha1.DockerClusterConnection{},
+			},
+		},
+	}
+	nn := apis.Key(cluster)
+
+	var envs []docker.Env
+	f.r.dockerClientFactory = DockerClientFunc(func(_ context.Context, env docker.Env) (docker.Client, error) {
+		envs = append(envs, env)
+		return f.dockerClient, nil
+	})
+	f.r.dockerCLIClient = func(ctx context.Context) (docker.DaemonClient, error) {
+		return nil, errors.New("fake cli error")
+	}
+
+	f.Create(cluster)
+	f.MustGet(nn, cluster)
+	connectedAt := *cluster.Status.ConnectedAt
+	require.Len(t, envs, 1)
+	assert.NoError(t, envs[0].Error)
+
+	f.tmpf.WriteFile(filepath.Join("docker", "config.json"), `{"currentContext": "remote"}`)
+	f.clock.Advance(time.Minute)
+	<-f.requeues
+
+	// the docker context is read again
+	f.MustGet(nn, cluster)
+	assert.True(t, cluster.Status.ConnectedAt.After(connectedAt.Time), "ConnectedAt should be bumped")
+	require.Len(t, envs, 2)
+	assert.EqualError(t, envs[1].Error, "fake cli error")
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r            *Reconciler
+	tmpf         *tempdir.TempDirFixture
 	ma           *analytics.MemoryAnalytics
 	clock        clockwork.FakeClock
 	k8sClient    *k8s.FakeK8sClient
@@ -290,11 +457,14 @@ func newFixture(t *testing.T) *fixture {
 		server.NewWebsocketList(),
 		base,
 		"tilt-default")
+	r.kubeconfigPaths = func() []string { return []string{tmpf.JoinPath("kubeconfig")} }
+	r.dockerConfigPaths = func() []string { return []string{tmpf.JoinPath("docker")} }
 	requeueChan := make(chan indexer.RequeueForTestResult, 1)
 	indexer.StartSourceForTesting(cfb.Context(), r.requeuer, r, requeueChan)
 	return &fixture{
 		ControllerFixture: cfb.Build(r),
 		r:                 r,
+		tmpf:              tmpf,
 		ma:                cfb.Analytics(),
 		clock:             clock,
 		k8sClient:         k8sClient,
@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"time"
+
+	dockerconfig "github.com/docker/cli/cli/config"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+// Why a connection to a cluster gets rebuilt.
+const (
+	refreshCauseKubeconfig   = "kubeconfig changed"
+	refreshCauseDockerConfig = "docker config changed"
+	refreshCauseCredentials  = "credentials expired"
+	refreshCauseRequested    = "refresh requested"
+)
+
+// How long a request that failed on expired credentials waits for a
+// refreshed client before giving up on retrying.
+const refreshRetryTimeout = 10 * time.Second
+
+// The files that a Kubernetes client is created from.
+func kubeconfigPaths() []string {
+	return clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence()
+}
+
+// The files that the docker CLI reads the current context from, and the
+// context store.
+func dockerConfigPaths() []string {
+	return []string{
+		filepath.Join(dockerconfig.Dir(), dockerconfig.ConfigFileName),
+		dockerconfig.ContextStoreDir(),
+	}
+}
+
+// A fingerprint of the files at paths (and the files under any directories),
+// that changes when any of them are written, created, or removed.
+//
+// Compares sizes and modification times instead of contents, so that it's
+// cheap enough to poll.
+func configFingerprint(paths []string) string {
+	h := sha256.New()
+	for _, p := range paths {
+		_ = filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				_, _ = fmt.Fprintf(h, "%s\x00missing\x00", path)
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\x00", path, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Whether the cluster rejected a request because the client's credentials
+// are no good anymore, e.g., an expired token.
+func isCredentialError(err error) bool {
+	return err != nil && apierrors.IsUnauthorized(err)
+}
+
+// Hands out a connection's Kubernetes client, but retries a request once
+// on a refreshed client if it fails on expired credentials.
+//
+// Only requests are retried. Watches and streams are re-established by the
+// controllers that own them when the Cluster's ConnectedAt changes.
+type refreshingK8sClient struct {
+	k8s.Client
+
+	key       types.NamespacedName
+	createdAt time.Time
+	manager   *ConnectionManager
+}
+
+var _ k8s.Client = refreshingK8sClient{}
+
+// Refreshes the connection if err means that the credentials expired, and
+// returns the new client to retry with.
+func (c refreshingK8sClient) retryClient(ctx context.Context, err error) (k8s.Client, bool) {
+	if !isCredentialError(err) {
+		return nil, false
+	}
+	conn, ok := c.manager.refresh(ctx, c.key, c.createdAt, refreshCauseCredentials)
+	if !ok {
+		return nil, false
+	}
+	return conn.k8sClient, true
+}
+
+func (c refreshingK8sClient) Upsert(ctx context.Context, entities []k8s.K8sEntity, timeout time.Duration) ([]k8s.K8sEntity, error) {
+	result, err := c.Client.Upsert(ctx, entities, timeout)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.Upsert(ctx, entities, timeout)
+	}
+	return result, err
+}
+
+func (c refreshingK8sClient) WaitForCRDsEstablished(ctx context.Context, crds []k8s.K8sEntity, timeout time.Duration) error {
+	err := c.Client.WaitForCRDsEstablished(ctx, crds, timeout)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.WaitForCRDsEstablished(ctx, crds, timeout)
+	}
+	return err
+}
+
+func (c refreshingK8sClient) Delete(ctx context.Context, entities []k8s.K8sEntity, wait time.Duration) error {
+	err := c.Client.Delete(ctx, entities, wait)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.Delete(ctx, entities, wait)
+	}
+	return err
+}
+
+func (c refreshingK8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectReference) (metav1.Object, error) {
+	result, err := c.Client.GetMetaByReference(ctx, ref)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.GetMetaByReference(ctx, ref)
+	}
+	return result, err
+}
+
+func (c refreshingK8sClient) ListMeta(ctx context.Context, gvk schema.GroupVersionKind, ns k8s.Namespace) ([]metav1.Object, error) {
+	result, err := c.Client.ListMeta(ctx, gvk, ns)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.ListMeta(ctx, gvk, ns)
+	}
+	return result, err
+}
+
+func (c refreshingK8sClient) SecretValue(ctx context.Context, n k8s.Namespace, name, key string) ([]byte, error) {
+	result, err := c.Client.SecretValue(ctx, n, name, key)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.SecretValue(ctx, n, name, key)
+	}
+	return result, err
+}
+
+func (c refreshingK8sClient) CanExec(ctx context.Context, n k8s.Namespace) (bool, error) {
+	result, err := c.Client.CanExec(ctx, n)
+	if next, ok := c.retryClient(ctx, err); ok {
+		return next.CanExec(ctx, n)
+	}
+	return result, err
+}
@@ -6,7 +6,6 @@ import (
 	"io"
 	"os/exec"
 	"sync"
-	"syscall"
 	"testing"
 	"time"
 
@@ -171,12 +170,11 @@ func (e *processExecer) processRun(ctx context.Context, cmd model.Cmd, w io.Writ
 		return
 	}
 
-	c.SysProcAttr = &syscall.SysProcAttr{}
-	procutil.SetOptNewProcessGroup(c.SysProcAttr)
 	c.Stderr = w
 	c.Stdout = w
 
-	err = c.Start()
+	supervisor := e.localEnv.Supervisor()
+	err = supervisor.Start(c)
 	if err != nil {
 		logger.Get(ctx).Errorf("%s failed to start: %v", cmd.String(), err)
 		statusCh <- statusAndMetadata{
@@ -203,6 +201,7 @@ func (e *processExecer) processRun(ctx context.Context, cmd model.Cmd, w io.Writ
 		// https://github.com/tilt-dev/tilt/issues/4456
 		state, err := c.Process.Wait()
 		procutil.KillProcessGroup(c)
+		supervisor.Done(c)
 
 		if err != nil {
 			processExitCh <- err
@@ -15,6 +15,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 )
 
@@ -57,7 +58,9 @@ func newFixture(t testing.TB) *fixture {
 	ib := build.NewImageBuilder(
 		build.NewDockerBuilder(dockerCli, nil),
 		build.NewCustomBuilder(dockerCli, clock, cmds),
-		build.NewKINDLoader())
+		build.NewKINDLoader(localexec.EmptyEnv()),
+		localexec.NewFakeExecer(t),
+		offline.Mode{})
 
 	r := NewReconciler(cfb.Client, cfb.Store, cfb.Scheme(), docker.NewFakeClient(), ib)
 	return &fixture{
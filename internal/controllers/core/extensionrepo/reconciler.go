@@ -18,6 +18,7 @@ import (
 
 	"github.com/tilt-dev/go-get"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis"
@@ -25,7 +26,8 @@ import (
 	"github.com/tilt-dev/tilt/pkg/logger"
 )
 
-const tiltModulesRelDir = "tilt_modules"
+// Where extension repos are downloaded, in the Tilt data dir.
+const TiltModulesRelDir = "tilt_modules"
 
 type Downloader interface {
 	DestinationPath(pkg string) string
@@ -38,6 +40,7 @@ type Reconciler struct {
 	ctrlClient ctrlclient.Client
 	st         store.RStore
 	dlr        Downloader
+	offline    offline.Mode
 	mu         sync.Mutex
 
 	repoStates map[types.NamespacedName]*repoState
@@ -50,8 +53,8 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	return b, nil
 }
 
-func NewReconciler(ctrlClient ctrlclient.Client, st store.RStore, base xdg.Base) (*Reconciler, error) {
-	dlrPath, err := base.DataFile(tiltModulesRelDir)
+func NewReconciler(ctrlClient ctrlclient.Client, st store.RStore, base xdg.Base, offline offline.Mode) (*Reconciler, error) {
+	dlrPath, err := base.DataFile(TiltModulesRelDir)
 	if err != nil {
 		return nil, fmt.Errorf("creating extensionrepo controller: %v", err)
 	}
@@ -59,6 +62,7 @@ func NewReconciler(ctrlClient ctrlclient.Client, st store.RStore, base xdg.Base)
 		ctrlClient: ctrlClient,
 		st:         st,
 		dlr:        get.NewDownloader(dlrPath),
+		offline:    offline,
 		repoStates: make(map[types.NamespacedName]*repoState),
 	}, nil
 }
@@ -220,6 +224,11 @@ func (r *Reconciler) reconcileDownloaderRepo(ctx context.Context, state *repoSta
 		return ctrl.Result{}
 	}
 
+	if r.offline.Enabled {
+		r.reconcileCachedRepo(ctx, state, importPath, destPath, info, exists)
+		return ctrl.Result{}
+	}
+
 	lastFetch := state.lastFetch
 	lastBackoff := state.backoff
 	if time.Since(lastFetch) < lastBackoff {
@@ -304,6 +313,40 @@ func (r *Reconciler) reconcileDownloaderRepo(ctx context.Context, state *repoSta
 	return ctrl.Result{}
 }
 
+// Reconcile a downloaded repo from what's already on disk, without fetching
+// anything, for offline mode.
+func (r *Reconciler) reconcileCachedRepo(ctx context.Context, state *repoState, importPath string, destPath string, info os.FileInfo, exists bool) {
+	if !exists {
+		state.status = v1alpha1.ExtensionRepoStatus{
+			Error: r.offline.Errorf("%s hasn't been downloaded yet; run Tilt online once to cache it", state.spec.URL).Error(),
+		}
+		return
+	}
+
+	if state.spec.Ref != "" {
+		err := r.dlr.RefSync(importPath, state.spec.Ref)
+		if err != nil {
+			state.status = v1alpha1.ExtensionRepoStatus{
+				Error: r.offline.Errorf("ref %s of %s isn't in the cache: %v", state.spec.Ref, state.spec.URL, err).Error(),
+			}
+			return
+		}
+	}
+
+	ref, err := r.dlr.HeadRef(importPath)
+	if err != nil {
+		state.status = v1alpha1.ExtensionRepoStatus{Error: fmt.Sprintf("determining head: %v", err)}
+		return
+	}
+
+	state.lastSuccessfulDestPath = destPath
+	state.status = v1alpha1.ExtensionRepoStatus{
+		LastFetchedAt: apis.NewTime(info.ModTime()),
+		Path:          destPath,
+		CheckoutRef:   ref,
+	}
+}
+
 // Loosely inspired by controllerutil's Update status algorithm.
 func (r *Reconciler) maybeUpdateStatus(ctx context.Context, repo *v1alpha1.ExtensionRepo, state *repoState) error {
 	if apicmp.DeepEqual(repo.Status, state.status) {
@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -199,6 +200,48 @@ func TestStale(t *testing.T) {
 	require.Contains(t, repo.Status.StaleReason, "fake error")
 }
 
+func TestOfflineUsesCache(t *testing.T) {
+	f := newFixture(t)
+	f.r.offline = offline.Mode{Enabled: true, Reason: "--offline"}
+	f.dlr.Download("github.com/tilt-dev/tilt-extensions")
+
+	key := types.NamespacedName{Name: "default"}
+	repo := v1alpha1.ExtensionRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: key.Name,
+		},
+		Spec: v1alpha1.ExtensionRepoSpec{
+			URL: "https://github.com/tilt-dev/tilt-extensions",
+		},
+	}
+	f.Create(&repo)
+	f.MustGet(key, &repo)
+	require.Equal(t, "", repo.Status.Error)
+	assert.Equal(t, "fake-head", repo.Status.CheckoutRef)
+	assert.Equal(t, 1, f.dlr.downloadCount)
+	f.assertSteadyState(&repo)
+}
+
+func TestOfflineNotCached(t *testing.T) {
+	f := newFixture(t)
+	f.r.offline = offline.Mode{Enabled: true, Reason: "--offline"}
+
+	key := types.NamespacedName{Name: "default"}
+	repo := v1alpha1.ExtensionRepo{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: key.Name,
+		},
+		Spec: v1alpha1.ExtensionRepoSpec{
+			URL: "https://github.com/tilt-dev/tilt-extensions",
+		},
+	}
+	f.Create(&repo)
+	f.MustGet(key, &repo)
+	assert.Equal(t, "https://github.com/tilt-dev/tilt-extensions hasn't been downloaded yet; "+
+		"run Tilt online once to cache it: Tilt is in offline mode (--offline)", repo.Status.Error)
+	assert.Equal(t, 0, f.dlr.downloadCount)
+}
+
 type fixture struct {
 	*fake.ControllerFixture
 	r    *Reconciler
@@ -213,7 +256,7 @@ func newFixture(t *testing.T) *fixture {
 	t.Cleanup(func() { _ = os.RemoveAll(tmpDir.Path()) })
 
 	base := xdg.FakeBase{Dir: tmpDir.Path()}
-	r, err := NewReconciler(cfb.Client, cfb.Store, base)
+	r, err := NewReconciler(cfb.Client, cfb.Store, base, offline.Mode{})
 	require.NoError(t, err)
 
 	dlr := &fakeDownloader{base: base, headRef: "fake-head"}
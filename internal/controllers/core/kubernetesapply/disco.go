@@ -123,6 +123,7 @@ func (r *Reconciler) toDesiredKubernetesDiscovery(ka *v1alpha1.KubernetesApply)
 			ExtraSelectors:           extraSelectors,
 			PodLogStreamTemplateSpec: kapp.PodLogStreamTemplateSpec.DeepCopy(),
 			PortForwardTemplateSpec:  kapp.PortForwardTemplateSpec.DeepCopy(),
+			DiscoveryStrategy:        kapp.DiscoveryStrategy,
 		},
 	}
 
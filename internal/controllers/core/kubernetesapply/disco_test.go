@@ -86,6 +86,41 @@ func TestDiscoveryStrategySelectorsOnly(t *testing.T) {
 	assert.Equal(t, map[string]string{"app": "tilt-site"}, kd.Spec.ExtraSelectors[0].MatchLabels)
 }
 
+func TestDiscoveryStrategySelectorsAndOwners(t *testing.T) {
+	f := newFixture(t)
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:              testyaml.SanchoYAML,
+			DiscoveryStrategy: v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners,
+			KubernetesDiscoveryTemplateSpec: &v1alpha1.KubernetesDiscoveryTemplateSpec{
+				ExtraSelectors: []metav1.LabelSelector{
+					metav1.LabelSelector{
+						MatchLabels: map[string]string{"workflows.argoproj.io/workflow": "dev-pipeline"},
+					},
+				},
+			},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+
+	var kd v1alpha1.KubernetesDiscovery
+	f.MustGet(types.NamespacedName{Name: "a"}, &kd)
+	assert.Equal(f.T(), 1, len(kd.Spec.Watches))
+
+	// Make sure we still watch the UIDs we deployed, and pass the strategy along.
+	assert.Contains(t, ka.Status.ResultYAML, fmt.Sprintf("uid: %s", kd.Spec.Watches[0].UID))
+	assert.Equal(t, "default", kd.Spec.Watches[0].Namespace)
+	assert.Equal(t, v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners, kd.Spec.DiscoveryStrategy)
+	assert.Equal(t, map[string]string{"workflows.argoproj.io/workflow": "dev-pipeline"},
+		kd.Spec.ExtraSelectors[0].MatchLabels)
+}
+
 // https://github.com/tilt-dev/tilt/issues/5773
 func TestApplyCmdDiscoveryStrategySelectorsOnly(t *testing.T) {
 	f := newFixture(t)
@@ -0,0 +1,147 @@
+package kubernetesapply
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/docker/registry"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// Looks up the local credentials for a registry domain.
+type registryCredentialsFunc func(domain string) (username string, password string, err error)
+
+// Reads the credentials from the docker config (~/.docker/config.json),
+// including any credential helpers.
+func dockerConfigCredentials(domain string) (string, string, error) {
+	key := domain
+	if domain == "docker.io" {
+		key = registry.IndexServer
+	}
+
+	auth, err := config.LoadDefaultConfigFile(io.Discard).GetAuthConfig(key)
+	if err != nil {
+		return "", "", err
+	}
+	if auth.Username == "" || auth.Password == "" {
+		return "", "", fmt.Errorf("no docker credentials for %s (try `docker login %s`)", domain, domain)
+	}
+	return auth.Username, auth.Password, nil
+}
+
+// How the apply should reference the default registry's pull secret.
+type pullSecret struct {
+	// The registry that pods pull from.
+	domain string
+
+	// "pod" or "serviceaccount"
+	target string
+
+	// The credentials, read when the apply starts.
+	username string
+	password string
+}
+
+// Reads the pull secret settings from the Cluster, or nil if Tilt
+// shouldn't create a pull secret.
+//
+// The credentials are read on every apply, so that the secret is refreshed
+// when they change (e.g., when a credential helper hands out a new token).
+func (r *Reconciler) pullSecretForCluster(cluster *v1alpha1.Cluster) (*pullSecret, error) {
+	if cluster == nil || cluster.Annotations[k8s.AnnotationPullSecretFrom] == "" {
+		return nil, nil
+	}
+
+	from := cluster.Annotations[k8s.AnnotationPullSecretFrom]
+	if from != k8s.PullSecretFromDockerConfig {
+		return nil, fmt.Errorf("unsupported pull secret source %q", from)
+	}
+
+	reg := cluster.Spec.DefaultRegistry
+	if reg == nil || reg.Host == "" {
+		return nil, nil
+	}
+
+	target := cluster.Annotations[k8s.AnnotationPullSecretTarget]
+	if target == "" {
+		target = k8s.PullSecretTargetPod
+	}
+
+	pullHost := reg.HostFromContainerRuntime
+	if pullHost == "" {
+		pullHost = reg.Host
+	}
+
+	// The local credentials are for the registry we push to.
+	username, password, err := r.registryCredentials(k8s.RegistryDomain(reg.Host))
+	if err != nil {
+		return nil, fmt.Errorf("creating image pull secret: %v", err)
+	}
+
+	return &pullSecret{
+		domain:   k8s.RegistryDomain(pullHost),
+		target:   target,
+		username: username,
+		password: password,
+	}, nil
+}
+
+// Creates or updates the pull secret in each namespace with a pod that
+// pulls from the registry. With the "serviceaccount" target, also adds it
+// to the namespace's default ServiceAccount.
+//
+// These objects are shared by every resource in the namespace, so they're
+// applied separately, and aren't garbage collected with the resource.
+func (r *Reconciler) upsertPullSecrets(ctx context.Context, ps *pullSecret, entities []k8s.K8sEntity, timeout time.Duration) error {
+	namespaces := map[k8s.Namespace]bool{}
+	for _, e := range entities {
+		pods, err := k8s.PodSpecsPullingFrom(e, ps.domain)
+		if err != nil {
+			return err
+		}
+		if len(pods) > 0 {
+			// An empty namespace applies to the same namespace as the pod.
+			namespaces[k8s.Namespace(e.Meta().GetNamespace())] = true
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	sorted := make([]k8s.Namespace, 0, len(namespaces))
+	for ns := range namespaces {
+		sorted = append(sorted, ns)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var toApply []k8s.K8sEntity
+	for _, ns := range sorted {
+		secret, err := k8s.NewPullSecretEntity(ns, ps.domain, ps.username, ps.password)
+		if err != nil {
+			return err
+		}
+
+		// Scrub the credentials before anything can log them.
+		r.st.Dispatch(store.SecretsAction{Secrets: k8s.PullSecretValues(secret)})
+		toApply = append(toApply, secret)
+
+		if ps.target == k8s.PullSecretTargetServiceAccount {
+			toApply = append(toApply, k8s.NewPullSecretServiceAccountEntity(ns))
+		}
+	}
+
+	logger.Get(ctx).Debugf("Updating image pull secret %s for %s", k8s.PullSecretName, ps.domain)
+	_, err := r.k8sClient.Upsert(ctx, toApply, timeout)
+	if err != nil {
+		return fmt.Errorf("applying image pull secret: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package kubernetesapply
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestPullSecretInjectedIntoPodSpec(t *testing.T) {
+	f := newFixture(t)
+	f.setupPullSecret(k8s.PullSecretTargetPod, "hunter2")
+	f.createSanchoWithImage("registry.example.com/sancho:tilt-1")
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "image: registry.example.com/sancho:tilt-1")
+	assert.Contains(t, f.kClient.Yaml, "imagePullSecrets:\n      - name: tilt-registry-pull-secret")
+
+	secrets := f.scrubbedSecrets()
+	assert.Equal(t, "[redacted secret tilt-registry-pull-secret:password]",
+		string(secrets.Scrub([]byte("hunter2"))))
+}
+
+func TestPullSecretNotInjectedForOtherRegistry(t *testing.T) {
+	f := newFixture(t)
+	f.setupPullSecret(k8s.PullSecretTargetPod, "hunter2")
+	f.createSanchoWithImage("gcr.io/some-project-162817/sancho:tilt-1")
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "image: gcr.io/some-project-162817/sancho:tilt-1")
+	assert.NotContains(t, f.kClient.Yaml, "imagePullSecrets")
+}
+
+func TestPullSecretServiceAccountTarget(t *testing.T) {
+	f := newFixture(t)
+	f.setupPullSecret(k8s.PullSecretTargetServiceAccount, "hunter2")
+	f.createSanchoWithImage("registry.example.com/sancho:tilt-1")
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.NotContains(t, f.kClient.Yaml, "imagePullSecrets")
+}
+
+func TestUpsertPullSecrets(t *testing.T) {
+	f := newFixture(t)
+	entities, err := k8s.ParseYAMLFromString(strings.ReplaceAll(testyaml.SanchoYAML,
+		"gcr.io/some-project-162817/sancho", "registry.example.com/sancho:tilt-1"))
+	require.NoError(t, err)
+
+	ps := &pullSecret{
+		domain:   "registry.example.com",
+		target:   k8s.PullSecretTargetServiceAccount,
+		username: "admin",
+		password: "hunter2",
+	}
+	err = f.r.upsertPullSecrets(f.Context(), ps, entities, timeout)
+	require.NoError(t, err)
+
+	assert.Contains(t, f.kClient.Yaml, "kind: Secret")
+	assert.Contains(t, f.kClient.Yaml, "name: tilt-registry-pull-secret")
+	assert.Contains(t, f.kClient.Yaml, "type: kubernetes.io/dockerconfigjson")
+	assert.Contains(t, f.kClient.Yaml, "kind: ServiceAccount")
+	assert.Contains(t, f.kClient.Yaml, "name: default")
+	assert.NotContains(t, f.kClient.Yaml, "hunter2")
+}
+
+func TestPullSecretRefreshedWhenCredentialsChange(t *testing.T) {
+	f := newFixture(t)
+	f.setupPullSecret(k8s.PullSecretTargetPod, "hunter2")
+	f.createSanchoWithImage("registry.example.com/sancho:tilt-1")
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+
+	f.r.registryCredentials = func(domain string) (string, string, error) {
+		return "admin", "hunter3", nil
+	}
+	var im v1alpha1.ImageMap
+	f.MustGet(types.NamespacedName{Name: "image-sancho"}, &im)
+	im.Status.Image = "registry.example.com/sancho:tilt-2"
+	im.Status.ImageFromCluster = "registry.example.com/sancho:tilt-2"
+	f.UpdateStatus(&im)
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+
+	secrets := f.scrubbedSecrets()
+	assert.Equal(t, "[redacted secret tilt-registry-pull-secret:password]",
+		string(secrets.Scrub([]byte("hunter3"))))
+}
+
+func TestPullSecretMissingCredentials(t *testing.T) {
+	f := newFixture(t)
+	f.setupPullSecret(k8s.PullSecretTargetPod, "")
+	f.r.registryCredentials = func(domain string) (string, string, error) {
+		return "", "", fmt.Errorf("no docker credentials for %s", domain)
+	}
+	f.createSanchoWithImage("registry.example.com/sancho:tilt-1")
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+
+	var ka v1alpha1.KubernetesApply
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Equal(t, "creating image pull secret: no docker credentials for registry.example.com", ka.Status.Error)
+}
+
+func (f *fixture) setupPullSecret(target string, password string) {
+	f.T().Helper()
+
+	var cluster v1alpha1.Cluster
+	f.MustGet(types.NamespacedName{Name: "default"}, &cluster)
+	cluster.Annotations = k8s.PullSecretSettings{
+		From:   k8s.PullSecretFromDockerConfig,
+		Target: target,
+	}.Annotations()
+	cluster.Spec.DefaultRegistry = &v1alpha1.RegistryHosting{Host: "registry.example.com"}
+	f.Update(&cluster)
+
+	f.r.registryCredentials = func(domain string) (string, string, error) {
+		return "admin", password, nil
+	}
+}
+
+func (f *fixture) createSanchoWithImage(image string) {
+	f.T().Helper()
+
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "image-sancho"},
+		Spec:       v1alpha1.ImageMapSpec{Selector: "gcr.io/some-project-162817/sancho"},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            image,
+			ImageFromCluster: image,
+		},
+	})
+	f.Create(&v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "a"},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML:      testyaml.SanchoYAML,
+			ImageMaps: []string{"image-sancho"},
+			Cluster:   "default",
+		},
+	})
+}
+
+func (f *fixture) scrubbedSecrets() model.SecretSet {
+	result := model.SecretSet{}
+	for _, action := range f.Store.Actions() {
+		if action, ok := action.(store.SecretsAction); ok {
+			result.AddAll(action.Secrets)
+		}
+	}
+	return result
+}
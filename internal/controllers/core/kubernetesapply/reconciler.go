@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -54,10 +56,18 @@ type Reconciler struct {
 	execer     localexec.Execer
 	requeuer   *indexer.Requeuer
 
+	registryCredentials registryCredentialsFunc
+
 	mu sync.Mutex
 
 	// Protected by the mutex.
 	results map[types.NamespacedName]*Result
+
+	// The CRDs that each enabled KubernetesApply defines, so that custom
+	// resources in other KubernetesApply objects can wait for them.
+	//
+	// Protected by the mutex.
+	crds map[types.NamespacedName][]k8s.K8sEntity
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -88,7 +98,10 @@ func NewReconciler(ctrlClient ctrlclient.Client, k8sClient k8s.Client, scheme *r
 		dkc:        dkc,
 		st:         st,
 		results:    make(map[types.NamespacedName]*Result),
+		crds:       make(map[types.NamespacedName][]k8s.K8sEntity),
 		requeuer:   indexer.NewRequeuer(),
+
+		registryCredentials: dockerConfigCredentials,
 	}
 }
 
@@ -110,6 +123,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		}
 
 		r.recordDelete(nn)
+		r.recordCRDs(nn, nil)
 		toDelete := r.garbageCollect(nn, true)
 		r.bestEffortDelete(ctx, nn, toDelete, "garbage collecting Kubernetes objects")
 		r.clearRecord(nn)
@@ -136,7 +150,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 	if disableStatus.State == v1alpha1.DisableStateDisabled {
 		gcReason = "deleting disabled Kubernetes objects"
 		isDisabling = true
+		r.recordCRDs(nn, nil)
 	} else {
+		r.recordCRDs(nn, crdsInSpec(ka.Spec))
+
 		// Fetch all the objects needed to apply this YAML.
 		var cluster v1alpha1.Cluster
 		if ka.Spec.Cluster != "" {
@@ -301,7 +318,7 @@ func (r *Reconciler) forceApplyHelper(
 	var deployed []k8s.K8sEntity
 	deployCtx := r.indentLogger(ctx)
 	if spec.YAML != "" {
-		deployed, err = r.runYAMLDeploy(deployCtx, spec, imageMaps)
+		deployed, err = r.runYAMLDeploy(deployCtx, nn, spec, cluster, imageMaps)
 		if err != nil {
 			return recordErrorStatus(err)
 		}
@@ -317,6 +334,7 @@ func (r *Reconciler) forceApplyHelper(
 	for _, d := range deployed {
 		d.Clean()
 	}
+	r.logConfigRestarts(ctx, nn, deployed)
 
 	resultYAML, err := k8s.SerializeSpecYAML(deployed)
 	if err != nil {
@@ -340,9 +358,18 @@ func (r *Reconciler) printAppliedReport(ctx context.Context, msg string, deploye
 	}
 }
 
-func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.KubernetesApplySpec, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, error) {
+func (r *Reconciler) runYAMLDeploy(ctx context.Context, nn types.NamespacedName, spec v1alpha1.KubernetesApplySpec, cluster *v1alpha1.Cluster, imageMaps map[types.NamespacedName]*v1alpha1.ImageMap) ([]k8s.K8sEntity, error) {
+	var ps *pullSecret
+	if len(imageMaps) > 0 {
+		var err error
+		ps, err = r.pullSecretForCluster(cluster)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create API objects.
-	newK8sEntities, err := r.createEntitiesToDeploy(ctx, imageMaps, spec)
+	newK8sEntities, err := r.createEntitiesToDeploy(ctx, imageMaps, spec, ps)
 	if err != nil {
 		return newK8sEntities, err
 	}
@@ -354,6 +381,24 @@ func (r *Reconciler) runYAMLDeploy(ctx context.Context, spec v1alpha1.Kubernetes
 		timeout = v1alpha1.KubernetesApplyTimeoutDefault
 	}
 
+	// The pull secret has to exist before the pods that use it.
+	if ps != nil {
+		err := r.upsertPullSecrets(ctx, ps, newK8sEntities, timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Upsert waits for CRDs in the same apply. But the CRD for a custom
+	// resource might be in a different resource.
+	crds := r.crdsToWaitFor(nn, newK8sEntities)
+	if len(crds) > 0 {
+		err := r.k8sClient.WaitForCRDsEstablished(ctx, crds, timeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	deployed, err := r.k8sClient.Upsert(ctx, newK8sEntities, timeout)
 	if err != nil {
 		r.printAppliedReport(ctx, "Tried to apply objects to cluster:", newK8sEntities)
@@ -453,10 +498,17 @@ type injectResult struct {
 
 func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 	imageMaps map[types.NamespacedName]*v1alpha1.ImageMap,
-	spec v1alpha1.KubernetesApplySpec) ([]k8s.K8sEntity, error) {
+	spec v1alpha1.KubernetesApplySpec,
+	ps *pullSecret) ([]k8s.K8sEntity, error) {
 	newK8sEntities := []k8s.K8sEntity{}
 
-	entities, err := k8s.ParseYAMLFromString(spec.YAML)
+	imageMapNames := spec.ImageMaps
+	yaml, injectedImageMaps, err := imagemap.InjectRefPlaceholders(spec.YAML, imageMapNames, imageMaps)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := k8s.ParseYAMLFromString(yaml)
 	if err != nil {
 		return nil, err
 	}
@@ -467,8 +519,6 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 	}
 
 	var injectResults []injectResult
-	imageMapNames := spec.ImageMaps
-	injectedImageMaps := map[string]bool{}
 	for _, e := range entities {
 		e, err = k8s.InjectLabels(e, []model.LabelPair{
 			k8s.TiltManagedByLabel(),
@@ -523,7 +573,7 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 			}
 
 			var replaced bool
-			e, replaced, err = k8s.InjectImageDigest(e, selector, ref, locators, matchInEnvVars, policy)
+			e, replaced, err = k8s.InjectImageDigestForContainers(e, spec.ImageContainers, selector, ref, locators, matchInEnvVars, policy)
 			if err != nil {
 				return nil, err
 			}
@@ -543,6 +593,13 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 			}
 		}
 
+		if ps != nil && ps.target == k8s.PullSecretTargetPod {
+			e, _, err = k8s.InjectImagePullSecret(e, k8s.PullSecretName, ps.domain)
+			if err != nil {
+				return nil, errors.Wrap(err, "injecting image pull secret")
+			}
+		}
+
 		// This needs to be after all the other injections, to ensure the hash includes the Tilt-generated
 		// image tag, etc
 		e, err := k8s.InjectPodTemplateSpecHashes(e)
@@ -555,6 +612,10 @@ func (r *Reconciler) createEntitiesToDeploy(ctx context.Context,
 
 	for _, name := range imageMapNames {
 		if !injectedImageMaps[name] {
+			if len(spec.ImageContainers) > 0 {
+				return nil, fmt.Errorf("Docker image missing from yaml: %s (image_containers: %s)",
+					name, strings.Join(spec.ImageContainers, ", "))
+			}
 			return nil, fmt.Errorf("Docker image missing from yaml: %s", name)
 		}
 	}
@@ -736,6 +797,78 @@ func (r *Reconciler) clearRecord(nn types.NamespacedName) {
 	delete(r.results, nn)
 }
 
+// Record the CRDs that a KubernetesApply defines.
+func (r *Reconciler) recordCRDs(nn types.NamespacedName, crds []k8s.K8sEntity) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(crds) == 0 {
+		delete(r.crds, nn)
+		return
+	}
+	r.crds[nn] = crds
+}
+
+// Returns the CRDs in other KubernetesApply objects that define the kinds
+// of the entities we're about to apply.
+func (r *Reconciler) crdsToWaitFor(nn types.NamespacedName, entities []k8s.K8sEntity) []k8s.K8sEntity {
+	kinds := make(map[schema.GroupKind]bool)
+	for _, e := range entities {
+		kinds[e.GVK().GroupKind()] = true
+	}
+	for _, e := range entities {
+		gk, ok := k8s.CRDGroupKind(e)
+		if ok {
+			delete(kinds, gk)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]types.NamespacedName, 0, len(r.crds))
+	for name := range r.crds {
+		if name != nn {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].String() < names[j].String()
+	})
+
+	var result []k8s.K8sEntity
+	for _, name := range names {
+		for _, crd := range r.crds[name] {
+			gk, _ := k8s.CRDGroupKind(crd)
+			if kinds[gk] {
+				result = append(result, crd)
+				delete(kinds, gk)
+			}
+		}
+	}
+	return result
+}
+
+// Returns the CRDs in the YAML of a KubernetesApply.
+func crdsInSpec(spec v1alpha1.KubernetesApplySpec) []k8s.K8sEntity {
+	if !strings.Contains(spec.YAML, "CustomResourceDefinition") {
+		return nil
+	}
+
+	entities, err := k8s.ParseYAMLFromString(spec.YAML)
+	if err != nil {
+		return nil
+	}
+
+	var result []k8s.K8sEntity
+	for _, e := range entities {
+		if _, ok := k8s.CRDGroupKind(e); ok {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
 // Perform garbage collection for a particular KubernetesApply object.
 //
 // isDeleting: indicates whether this is a full delete or just
@@ -850,6 +983,23 @@ func (r *Reconciler) ForceDelete(ctx context.Context, nn types.NamespacedName,
 	return nil
 }
 
+// Deletes the KubernetesDiscovery that the KubernetesApply owns, which
+// stops its pod watches and log streams. After the next successful apply,
+// the reconciler creates a new one, so tracking starts over.
+func (r *Reconciler) ForceResetDiscovery(ctx context.Context, nn types.NamespacedName) error {
+	var kd v1alpha1.KubernetesDiscovery
+	err := r.ctrlClient.Get(ctx, nn, &kd)
+	if err != nil {
+		return ctrlclient.IgnoreNotFound(err)
+	}
+	err = r.ctrlClient.Delete(ctx, &kd)
+	if err != nil {
+		return ctrlclient.IgnoreNotFound(err)
+	}
+	r.requeuer.Add(nn)
+	return nil
+}
+
 // Update the status if necessary.
 func (r *Reconciler) maybeUpdateStatus(ctx context.Context, nn types.NamespacedName, obj *v1alpha1.KubernetesApply) (*v1alpha1.KubernetesApply, error) {
 	newStatus := v1alpha1.KubernetesApplyStatus{}
@@ -114,6 +114,41 @@ func TestBasicApplyYAML(t *testing.T) {
 	assert.Equal(f.T(), f.kClient.Yaml, "")
 }
 
+func TestApplyYAMLWaitsForCRDInOtherApply(t *testing.T) {
+	f := newFixture(t)
+	crdYAML, crYAML, ok := strings.Cut(testyaml.CRDYAML, "---")
+	require.True(t, ok)
+
+	f.Create(&v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "crd"},
+		Spec:       v1alpha1.KubernetesApplySpec{YAML: crdYAML},
+	})
+	f.MustReconcile(types.NamespacedName{Name: "crd"})
+	assert.Empty(t, f.kClient.WaitedForCRDs)
+
+	f.kClient.WaitForCRDsEstablishedError = errors.New("CustomResourceDefinition projects.example.martin-helmich.de was not established before the timeout")
+	f.Create(&v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "cr"},
+		Spec:       v1alpha1.KubernetesApplySpec{YAML: crYAML},
+	})
+	f.MustReconcile(types.NamespacedName{Name: "cr"})
+	require.Len(t, f.kClient.WaitedForCRDs, 1)
+	assert.Equal(t, "projects.example.martin-helmich.de", f.kClient.WaitedForCRDs[0].Name())
+
+	var ka v1alpha1.KubernetesApply
+	f.MustGet(types.NamespacedName{Name: "cr"}, &ka)
+	assert.Contains(t, ka.Status.Error, "was not established")
+	assert.NotContains(t, f.kClient.Yaml, "example-project")
+
+	// Once the CRD is established, the custom resource is applied.
+	f.kClient.WaitForCRDsEstablishedError = nil
+	f.MustGet(types.NamespacedName{Name: "cr"}, &ka)
+	ka.Spec.Timeout = metav1.Duration{Duration: time.Minute}
+	f.Update(&ka)
+	f.MustReconcile(types.NamespacedName{Name: "cr"})
+	assert.Contains(t, f.kClient.Yaml, "example-project")
+}
+
 func TestBasicApplyCmd(t *testing.T) {
 	f := newFixture(t)
 
@@ -185,6 +220,105 @@ func TestApplyCmdWithImages(t *testing.T) {
 	}
 }
 
+func TestApplyYAMLWithImageRefPlaceholder(t *testing.T) {
+	f := newFixture(t)
+
+	im := v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-api",
+		},
+		Spec: v1alpha1.ImageMapSpec{
+			Selector: "gcr.io/acme/api",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "gcr.io/acme/api:tilt-1",
+			ImageFromCluster: "gcr.io/acme/api:tilt-1",
+		},
+	}
+	f.Create(&im)
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: values
+data:
+  image: tilt-image-ref[gcr.io/acme/api]
+`,
+			ImageMaps: []string{"image-api"},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "image: gcr.io/acme/api:tilt-1")
+
+	// A new image re-applies the YAML with the new ref.
+	f.MustGet(types.NamespacedName{Name: "image-api"}, &im)
+	im.Status.Image = "gcr.io/acme/api:tilt-2"
+	im.Status.ImageFromCluster = "gcr.io/acme/api:tilt-2"
+	f.UpdateStatus(&im)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "image: gcr.io/acme/api:tilt-2")
+}
+
+func TestApplyYAMLWithImageContainers(t *testing.T) {
+	f := newFixture(t)
+
+	f.Create(&v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "image-api",
+		},
+		Spec: v1alpha1.ImageMapSpec{
+			Selector: "gcr.io/acme/api",
+		},
+		Status: v1alpha1.ImageMapStatus{
+			Image:            "gcr.io/acme/api:tilt-1",
+			ImageFromCluster: "gcr.io/acme/api:tilt-1",
+		},
+	})
+
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "a",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: `apiVersion: v1
+kind: Pod
+metadata:
+  name: api
+spec:
+  containers:
+  - name: api
+    image: gcr.io/acme/api
+  - name: migrate
+    image: gcr.io/acme/api
+`,
+			ImageMaps:       []string{"image-api"},
+			ImageContainers: []string{"api"},
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	assert.Contains(t, f.kClient.Yaml, "image: gcr.io/acme/api:tilt-1")
+	assert.Contains(t, f.kClient.Yaml, "image: gcr.io/acme/api\n")
+
+	// No container matches, so the image can't be injected.
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	ka.Spec.ImageContainers = []string{"web"}
+	f.Update(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "a"})
+	f.MustGet(types.NamespacedName{Name: "a"}, &ka)
+	assert.Contains(t, ka.Status.Error, "Docker image missing from yaml: image-api (image_containers: web)")
+}
+
 func TestApplyCmdWithKubeconfig(t *testing.T) {
 	f := newFixture(t)
 
@@ -465,6 +599,51 @@ func TestGarbageCollectPartial(t *testing.T) {
 	assert.Contains(f.T(), f.kClient.DeletedYaml, "name: infra-kafka-zookeeper")
 }
 
+func TestLogConfigRestarts(t *testing.T) {
+	f := newFixture(t)
+	deployment := func(hash string) string {
+		return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+      annotations:
+        tilt.dev/restart-on: configmap/app-config=%s,secret/db=1
+    spec:
+      containers:
+      - name: api
+        image: api
+`, hash)
+	}
+	ka := v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api",
+		},
+		Spec: v1alpha1.KubernetesApplySpec{
+			YAML: deployment("1"),
+		},
+	}
+	f.Create(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "api"})
+	assert.NotContains(t, f.Stdout(), "restarted api")
+
+	f.MustGet(types.NamespacedName{Name: "api"}, &ka)
+	ka.Spec.YAML = deployment("2")
+	f.Update(&ka)
+
+	f.MustReconcile(types.NamespacedName{Name: "api"})
+	assert.Contains(t, f.Stdout(), "restarted api: configmap app-config changed")
+	assert.NotContains(t, f.Stdout(), "secret db changed")
+}
+
 func TestGarbageCollectAfterErrorDuringApply(t *testing.T) {
 	f := newFixture(t)
 	ka := v1alpha1.KubernetesApply{
@@ -0,0 +1,41 @@
+package kubernetesapply
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// logConfigRestarts explains why workloads restarted: when the hash of a
+// ConfigMap or Secret in a workload's restart-on annotation differs from
+// the last apply, the new pod template rolls the pods.
+func (r *Reconciler) logConfigRestarts(ctx context.Context, nn types.NamespacedName, deployed []k8s.K8sEntity) {
+	r.mu.Lock()
+	var last objectRefSet
+	if result, ok := r.results[nn]; ok {
+		last = result.AppliedObjects
+	}
+	r.mu.Unlock()
+
+	l := logger.Get(ctx)
+	for ref, e := range newObjectRefSet(deployed) {
+		lastEntity, ok := last[ref]
+		if !ok {
+			continue
+		}
+		old, err := k8s.ReadRestartOn(lastEntity)
+		if err != nil {
+			continue
+		}
+		hashes, err := k8s.ReadRestartOn(e)
+		if err != nil {
+			continue
+		}
+		for _, changed := range k8s.ChangedConfigs(old, hashes) {
+			l.Infof("restarted %s: %s %s changed", nn.Name, changed.Kind, changed.Name)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package kubernetesdiscovery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/store/k8sconv"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// podNotice is a message about a Pod that a KubernetesDiscovery object
+// discovered with its extra selectors.
+//
+// Notices are logged to the object's manifest once, when they first appear.
+type podNotice struct {
+	podName string
+	level   logger.Level
+	msg     string
+}
+
+// selectsPod checks if the Pod matches any of the extra selectors of the watcher.
+//
+// With the selectors-and-owners strategy, only Pods in the namespaces of the
+// watch refs can match.
+func (w watcher) selectsPod(pod *v1.Pod) bool {
+	if w.spec.DiscoveryStrategy == v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners {
+		namespaces, _ := namespacesAndUIDsFromSpec(w.spec.Watches)
+		if !namespaces[pod.Namespace] {
+			return false
+		}
+	}
+
+	podLabels := labels.Set(pod.Labels)
+	for _, selector := range w.extraSelectors {
+		if selector.Matches(podLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+// adoptPod decides whether a watcher with the selectors-and-owners strategy
+// gets a Pod that matched its extra selectors, and records a notice about it.
+//
+// A Pod that's owned by another watcher (i.e., the Pod or one of its
+// transitive owners is in the watch refs of another watcher) stays with that
+// watcher. If the extra selectors of more than one watcher match the Pod, it
+// goes to the watcher that sorts first by name, so that every watcher agrees.
+//
+// mu must be held by caller.
+func (w *Reconciler) adoptPod(key watcherID, podKey uidKey, pod *v1.Pod, notices map[uidKey]podNotice) bool {
+	if w.isOwnedByOtherWatcher(key, podKey) {
+		return false
+	}
+
+	claimants := []watcherID{key}
+	for otherKey, other := range w.watchers {
+		if otherKey != key && other.cluster == podKey.cluster && other.selectsPod(pod) {
+			claimants = append(claimants, otherKey)
+		}
+	}
+
+	if len(claimants) == 1 {
+		notices[podKey] = podNotice{
+			podName: pod.Name,
+			level:   logger.InfoLvl,
+			msg:     fmt.Sprintf("Discovered pod %s by extra_pod_selectors. It's not owned by this resource.", pod.Name),
+		}
+		return true
+	}
+
+	sort.Slice(claimants, func(i, j int) bool {
+		return claimants[i].String() < claimants[j].String()
+	})
+	names := make([]string, 0, len(claimants))
+	for _, c := range claimants {
+		names = append(names, c.Name)
+	}
+	notices[podKey] = podNotice{
+		podName: pod.Name,
+		level:   logger.WarnLvl,
+		msg: fmt.Sprintf("Pod %s matches the extra_pod_selectors of resources: %s. Assigning it to %s.",
+			pod.Name, strings.Join(names, ", "), claimants[0].Name),
+	}
+	return claimants[0] == key
+}
+
+// isOwnedByOtherWatcher checks if the Pod (or one of its transitive owners)
+// matches a watch ref UID of any watcher other than the given one.
+//
+// mu must be held by caller.
+func (w *Reconciler) isOwnedByOtherWatcher(key watcherID, podKey uidKey) bool {
+	for watchKey, watchers := range w.uidWatchers {
+		if watchKey.cluster != podKey.cluster {
+			continue
+		}
+		if watchKey.uid != podKey.uid && !w.knownDescendentPodUIDs[watchKey].Contains(podKey.uid) {
+			continue
+		}
+		for otherKey := range watchers {
+			if otherKey != key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// logPodNotices logs any notices for the watcher that weren't there the last
+// time its status was built.
+//
+// mu must be held by caller.
+func (w *Reconciler) logPodNotices(kd *v1alpha1.KubernetesDiscovery, key watcherID, notices map[uidKey]podNotice) {
+	prev := w.podNotices[key]
+	if len(notices) == 0 {
+		delete(w.podNotices, key)
+	} else {
+		w.podNotices[key] = notices
+	}
+
+	mn := model.ManifestName(kd.Annotations[v1alpha1.AnnotationManifest])
+	if mn == "" {
+		// log actions are dispatched by manifest, so if this spec isn't associated with a manifest,
+		// there's nowhere to send them
+		return
+	}
+
+	var toLog []podNotice
+	for podKey, notice := range notices {
+		if prev[podKey] != notice {
+			toLog = append(toLog, notice)
+		}
+	}
+	sort.Slice(toLog, func(i, j int) bool {
+		return toLog[i].podName < toLog[j].podName
+	})
+	for _, notice := range toLog {
+		spanID := k8sconv.SpanIDForPod(mn, k8s.PodID(notice.podName))
+		w.st.Dispatch(store.NewLogAction(mn, spanID, notice.level, nil, []byte(notice.msg+"\n")))
+	}
+}
+
+// requeueSelectorsAndOwners requeues the other watchers with the
+// selectors-and-owners strategy on the same cluster, because a change to
+// the given watcher might change which Pods they get.
+//
+// mu must be held by caller.
+func (w *Reconciler) requeueSelectorsAndOwners(key watcherID, cluster clusterKey) {
+	for otherKey, other := range w.watchers {
+		if otherKey == key || other.cluster != cluster ||
+			other.spec.DiscoveryStrategy != v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners {
+			continue
+		}
+		w.requeuer.Add(types.NamespacedName(otherKey))
+	}
+}
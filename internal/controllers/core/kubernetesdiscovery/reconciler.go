@@ -101,6 +101,11 @@ type Reconciler struct {
 	//
 	// If a Pod is in gcPods it MUST exist in known pods.
 	deletedPods map[uidKey]bool
+
+	// podNotices are the notices about discovered Pods that have been logged
+	// for each watcher with the selectors-and-owners strategy, so that each
+	// is only logged once.
+	podNotices map[watcherID]map[uidKey]podNotice
 }
 
 func (w *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -130,6 +135,7 @@ func NewReconciler(ctrlClient ctrlclient.Client, scheme *runtime.Scheme, clients
 		knownPods:              make(map[uidKey]*v1.Pod),
 		knownPodOwnerCreation:  make(map[uidKey]metav1.Time),
 		deletedPods:            make(map[uidKey]bool),
+		podNotices:             make(map[watcherID]map[uidKey]podNotice),
 	}
 }
 
@@ -171,7 +177,9 @@ func (w *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		if hasExisting {
 			w.teardown(key)
 			w.cleanupAbandonedNamespaces()
+			w.requeueSelectorsAndOwners(key, existing.cluster)
 		}
+		delete(w.podNotices, key)
 
 		if err := w.manageOwnedObjects(ctx, request.NamespacedName, nil); err != nil {
 			return ctrl.Result{}, err
@@ -194,6 +202,11 @@ func (w *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	if !hasExisting || needsRefresh || !apicmp.DeepEqual(existing.spec, kd.Spec) {
 		w.addOrReplace(ctx, key, kd, cluster)
+		w.requeueSelectorsAndOwners(key, newClusterKey(cluster))
+	}
+
+	if hasExisting && needsRefresh && existing.cluster.name == apis.Key(cluster) {
+		w.carryOverClusterState(key, existing, newClusterKey(cluster))
 	}
 
 	kd, err = w.maybeUpdateObjectStatus(ctx, kd, key)
@@ -296,6 +309,50 @@ func (w *Reconciler) addOrReplace(ctx context.Context, watcherKey watcherID, kd
 	w.watchers[watcherKey] = newWatcher
 }
 
+// carryOverClusterState keeps what we know about the pods from before the
+// connection to the cluster was refreshed, so that they don't go missing
+// from the status while the new watches catch up. The new watches list the
+// pods again, and replace the old ones.
+//
+// mu must be held by caller.
+func (w *Reconciler) carryOverClusterState(watcherKey watcherID, existing watcher, to clusterKey) {
+	from := existing.cluster
+	if from == to {
+		return
+	}
+
+	for key, pod := range w.knownPods {
+		if key.cluster == from {
+			w.knownPods[uidKey{cluster: to, uid: key.uid}] = pod
+			delete(w.knownPods, key)
+		}
+	}
+	for key, t := range w.knownPodOwnerCreation {
+		if key.cluster == from {
+			w.knownPodOwnerCreation[uidKey{cluster: to, uid: key.uid}] = t
+			delete(w.knownPodOwnerCreation, key)
+		}
+	}
+	for key, uids := range w.knownDescendentPodUIDs {
+		if key.cluster == from {
+			w.knownDescendentPodUIDs[uidKey{cluster: to, uid: key.uid}] = uids
+			delete(w.knownDescendentPodUIDs, key)
+		}
+	}
+	for key := range w.deletedPods {
+		if key.cluster == from {
+			w.deletedPods[uidKey{cluster: to, uid: key.uid}] = true
+			delete(w.deletedPods, key)
+		}
+	}
+
+	// The watch is the same as far as the status is concerned.
+	if current, ok := w.watchers[watcherKey]; ok && current.errorReason == "" && !existing.startTime.IsZero() {
+		current.startTime = existing.startTime
+		w.watchers[watcherKey] = current
+	}
+}
+
 // teardown removes the watcher from all namespace + UIDs it was watching.
 //
 // By design, teardown does NOT clean up any watches for namespaces that no longer have any active watchers.
@@ -396,7 +453,8 @@ func (w *Reconciler) setupUIDWatch(_ context.Context, uidKey uidKey, watcherID w
 // Returns the latest object on success.
 func (w *Reconciler) maybeUpdateObjectStatus(ctx context.Context, kd *v1alpha1.KubernetesDiscovery, watcherID watcherID) (*v1alpha1.KubernetesDiscovery, error) {
 	watcher := w.watchers[watcherID]
-	status := w.buildStatus(ctx, watcher)
+	status, notices := w.buildStatus(ctx, watcherID, watcher)
+	w.logPodNotices(kd, watcherID, notices)
 	if apicmp.DeepEqual(kd.Status, status) {
 		// the status hasn't changed - avoid a spurious update
 		return kd, nil
@@ -428,16 +486,17 @@ func (w *Reconciler) statusError(status v1alpha1.KubernetesDiscoveryStatus) stri
 	return ""
 }
 
-// buildStatus creates the current state for the given KubernetesDiscovery object key.
+// buildStatus creates the current state for the given KubernetesDiscovery object key,
+// and the notices about any Pods it discovered with the selectors-and-owners strategy.
 //
 // mu must be held by caller.
-func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.KubernetesDiscoveryStatus {
+func (w *Reconciler) buildStatus(ctx context.Context, key watcherID, watcher watcher) (v1alpha1.KubernetesDiscoveryStatus, map[uidKey]podNotice) {
 	if watcher.errorReason != "" {
 		return v1alpha1.KubernetesDiscoveryStatus{
 			Waiting: &v1alpha1.KubernetesDiscoveryStateWaiting{
 				Reason: watcher.errorReason,
 			},
-		}
+		}, nil
 	}
 
 	seenPodUIDs := k8s.NewUIDSet()
@@ -470,19 +529,22 @@ func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.
 	}
 
 	// TODO(milas): we should only match against Pods in namespaces referenced by the WatchRefs for this spec
+	// (the selectors-and-owners strategy does, but the others still match against all known Pods)
+	notices := make(map[uidKey]podNotice)
 	if len(watcher.spec.ExtraSelectors) != 0 {
 		for podKey, pod := range w.knownPods {
 			if podKey.cluster != watcher.cluster || seenPodUIDs.Contains(podKey.uid) {
 				// ignore pods that are for other clusters or that we've already seen
 				continue
 			}
-			podLabels := labels.Set(pod.Labels)
-			for _, selector := range watcher.extraSelectors {
-				if selector.Matches(podLabels) {
-					maybeTrackPod(pod, "")
-					break
-				}
+			if !watcher.selectsPod(pod) {
+				continue
+			}
+			if watcher.spec.DiscoveryStrategy == v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners &&
+				!w.adoptPod(key, podKey, pod, notices) {
+				continue
 			}
+			maybeTrackPod(pod, "")
 		}
 	}
 
@@ -495,7 +557,7 @@ func (w *Reconciler) buildStatus(ctx context.Context, watcher watcher) v1alpha1.
 		Running: &v1alpha1.KubernetesDiscoveryStateRunning{
 			StartTime: startTime,
 		},
-	}
+	}, notices
 }
 
 // If a pod was deleted from the cluster, check to make sure if we
@@ -606,18 +668,14 @@ func (w *Reconciler) triagePodTree(nsKey nsKey, pod *v1.Pod, objTree k8s.ObjectR
 	// NOTE(nick): This code might be totally obsolete now that we triage
 	// pods by owner UID. It's meant to handle CRDs, but most CRDs should
 	// set owner reference appropriately.
-	podLabels := labels.Set(pod.ObjectMeta.GetLabels())
 	for key, watcher := range w.watchers {
 		if seenWatchers[key] {
 			continue
 		}
-		for _, selector := range watcher.extraSelectors {
-			if selector.Matches(podLabels) {
-				seenWatchers[key] = true
-				// there is no ancestorUID since this was a label match
-				results = append(results, triageResult{watcherID: key, ancestorUID: ""})
-				break
-			}
+		if watcher.selectsPod(pod) {
+			seenWatchers[key] = true
+			// there is no ancestorUID since this was a label match
+			results = append(results, triageResult{watcherID: key, ancestorUID: ""})
 		}
 	}
 
@@ -25,9 +25,11 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 const stdTimeout = time.Second
@@ -274,6 +276,114 @@ func TestPodDiscoveryDuplicates(t *testing.T) {
 	}, nil)
 }
 
+func TestPodDiscoverySelectorsAndOwners(t *testing.T) {
+	f := newFixture(t)
+
+	ns := k8s.Namespace("ns")
+	otherNS := k8s.Namespace("other-ns")
+	dep, rs := f.buildK8sDeployment(ns, "dep")
+	workflowLabels := labels.Set{"workflows.argoproj.io/workflow": "dev-pipeline"}
+
+	depKey := types.NamespacedName{Namespace: "some-ns", Name: "dep"}
+	depKD := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: depKey.Namespace, Name: depKey.Name},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{Namespace: ns.String(), UID: string(rs.UID)},
+				// make sure pods in the other namespace are known
+				{Namespace: otherNS.String()},
+			},
+		},
+	}
+	f.injectK8sObjects(*depKD, dep, rs)
+	f.Create(depKD)
+
+	workflowKey := types.NamespacedName{Namespace: "some-ns", Name: "workflow"}
+	workflowKD := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   workflowKey.Namespace,
+			Name:        workflowKey.Name,
+			Annotations: map[string]string{v1alpha1.AnnotationManifest: "workflow"},
+		},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{Namespace: ns.String()},
+			},
+			ExtraSelectors: []metav1.LabelSelector{
+				*metav1.SetAsLabelSelector(workflowLabels),
+			},
+			DiscoveryStrategy: v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners,
+		},
+	}
+	f.Create(workflowKD)
+
+	for _, k := range []types.NamespacedName{depKey, workflowKey} {
+		f.requireMonitorStarted(k)
+	}
+
+	// pod1 matches on labels in the workflow namespace
+	// pod2 matches on labels, but is in another namespace
+	// pod3 matches on labels, but is owned by dep
+	pod1 := f.buildPod(ns, "pod1", workflowLabels, nil)
+	pod2 := f.buildPod(otherNS, "pod2", workflowLabels, nil)
+	pod3 := f.buildPod(ns, "pod3", workflowLabels, rs)
+	f.injectK8sObjects(*workflowKD, pod1, pod2, pod3)
+
+	f.requireObservedPods(workflowKey, ancestorMap{pod1.UID: ""}, nil)
+	f.requireObservedPods(depKey, ancestorMap{pod3.UID: rs.UID}, nil)
+	f.requireLogs("workflow", "Discovered pod pod1 by extra_pod_selectors. It's not owned by this resource.")
+}
+
+func TestPodDiscoverySelectorsAndOwnersConflict(t *testing.T) {
+	f := newFixture(t)
+
+	ns := k8s.Namespace("ns")
+	sharedLabels := labels.Set{"app": "shared"}
+
+	var kds []*v1alpha1.KubernetesDiscovery
+	for _, name := range []string{"b", "a"} {
+		key := types.NamespacedName{Namespace: "some-ns", Name: name}
+		kd := &v1alpha1.KubernetesDiscovery{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   key.Namespace,
+				Name:        key.Name,
+				Annotations: map[string]string{v1alpha1.AnnotationManifest: name},
+			},
+			Spec: v1alpha1.KubernetesDiscoverySpec{
+				Watches: []v1alpha1.KubernetesWatchRef{
+					{Namespace: ns.String()},
+				},
+				ExtraSelectors: []metav1.LabelSelector{
+					*metav1.SetAsLabelSelector(sharedLabels),
+				},
+				DiscoveryStrategy: v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners,
+			},
+		}
+		f.Create(kd)
+		kds = append(kds, kd)
+	}
+
+	pod1 := f.buildPod(ns, "pod1", sharedLabels, nil)
+	f.injectK8sObjects(*kds[0], pod1)
+
+	// the pod goes to the resource that sorts first, no matter which was created first
+	f.requireObservedPods(types.NamespacedName{Namespace: "some-ns", Name: "a"}, ancestorMap{pod1.UID: ""}, nil)
+	f.requireObservedPods(types.NamespacedName{Namespace: "some-ns", Name: "b"}, nil, nil)
+
+	warning := "Pod pod1 matches the extra_pod_selectors of resources: a, b. Assigning it to a."
+	f.requireLogs("a", warning)
+	f.requireLogs("b", warning)
+
+	// an update to the pod doesn't repeat the warning
+	pod1.Status.Phase = v1.PodSucceeded
+	f.injectK8sObjects(*kds[0], pod1)
+	f.requireState(types.NamespacedName{Namespace: "some-ns", Name: "a"}, func(kd *v1alpha1.KubernetesDiscovery) bool {
+		return kd != nil && len(kd.Status.Pods) == 1 && kd.Status.Pods[0].Phase == string(v1.PodSucceeded)
+	}, "pod update not observed")
+	assert.Equal(t, 1, strings.Count(f.logsFor("a"), warning))
+	assert.Equal(t, 1, strings.Count(f.logsFor("b"), warning))
+}
+
 func TestReconcileManagesPodLogStream(t *testing.T) {
 	f := newFixture(t)
 
@@ -620,6 +730,55 @@ func TestClusterChange(t *testing.T) {
 		podNameMap{pod1UID: "pod1ClusterB", pod2UID: "pod2ClusterB"})
 }
 
+func TestClusterRefreshKeepsPods(t *testing.T) {
+	f := newFixture(t)
+
+	kd := &v1alpha1.KubernetesDiscovery{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "some-ns", Name: "kd"},
+		Spec: v1alpha1.KubernetesDiscoverySpec{
+			Watches: []v1alpha1.KubernetesWatchRef{
+				{
+					UID:       "pod1-uid",
+					Namespace: "pod-ns",
+				},
+			},
+			Cluster: "cluster",
+		},
+	}
+	key := apis.Key(kd)
+	f.Create(kd)
+	f.requireMonitorStarted(key)
+
+	const pod1UID types.UID = "pod1-uid"
+	pod1 := f.buildPod("pod-ns", "pod1", nil, nil)
+	pod1.UID = pod1UID
+	f.clients.MustK8sClient(clusterNN(*kd)).UpsertPod(pod1)
+	f.requireObservedPods(key, ancestorMap{pod1UID: pod1UID}, podNameMap{pod1UID: "pod1"})
+	f.MustGet(key, kd)
+	startTime := kd.Status.Running.StartTime
+
+	// refresh the connection to a client that hasn't seen the pod yet
+	kCli2 := k8s.NewFakeK8sClient(t)
+	connectedAt2 := f.clients.SetK8sClient(clusterNN(*kd), kCli2)
+	cluster := f.getCluster(clusterNN(*kd))
+	cluster.Status.ConnectedAt = connectedAt2.DeepCopy()
+	require.NoError(f.t, f.Client.Status().Update(f.ctx, cluster))
+	f.MustReconcile(key)
+
+	// the pod we knew about is still there, and the watch didn't restart
+	f.MustGet(key, kd)
+	require.Len(t, kd.Status.Pods, 1)
+	require.Equal(t, "pod1", kd.Status.Pods[0].Name)
+	require.NotNil(t, kd.Status.Running)
+	timecmp.RequireTimeEqual(t, startTime, kd.Status.Running.StartTime)
+
+	// until the new watch catches up
+	pod1Refreshed := pod1.DeepCopy()
+	pod1Refreshed.Name = "pod1-refreshed"
+	kCli2.UpsertPod(pod1Refreshed)
+	f.requireObservedPods(key, ancestorMap{pod1UID: pod1UID}, podNameMap{pod1UID: "pod1-refreshed"})
+}
+
 func TestHangOntoDeletedPodsWhenNoSibling(t *testing.T) {
 	f := newFixture(t)
 
@@ -794,6 +953,24 @@ func (f *fixture) requireObservedPods(key types.NamespacedName, expectedAncestor
 	}, "Expected Pods were not observed for key[%s]: %s", key, &desc)
 }
 
+// logsFor returns the log messages that were dispatched for the manifest.
+func (f *fixture) logsFor(mn model.ManifestName) string {
+	var sb strings.Builder
+	for _, a := range f.Actions() {
+		if la, ok := a.(store.LogAction); ok && la.ManifestName() == mn {
+			sb.Write(la.Message())
+		}
+	}
+	return sb.String()
+}
+
+func (f *fixture) requireLogs(mn model.ManifestName, expected string) {
+	f.t.Helper()
+	require.Eventuallyf(f.t, func() bool {
+		return strings.Contains(f.logsFor(mn), expected)
+	}, stdTimeout, 20*time.Millisecond, "Logs for %s did not contain %q:\n%s", mn, expected, f.logsFor(mn))
+}
+
 func (f *fixture) requireState(key types.NamespacedName, cond func(kd *v1alpha1.KubernetesDiscovery) bool, msg string, args ...interface{}) {
 	f.t.Helper()
 	require.Eventuallyf(f.t, func() bool {
@@ -18,4 +18,8 @@ type Input struct {
 	ChangedFiles []build.PathMapping
 
 	LastFileTimeSynced metav1.MicroTime
+
+	// Derived from the step source annotation, if the steps
+	// were overridden for this resource.
+	StepSource string
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/build"
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/containerupdate"
+	"github.com/tilt-dev/tilt/internal/containerupdate/agent"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
@@ -59,12 +60,17 @@ type Reconciler struct {
 
 	ExecUpdater   containerupdate.ContainerUpdater
 	DockerUpdater containerupdate.ContainerUpdater
+	AgentUpdater  containerupdate.ContainerUpdater
 	updateMode    liveupdates.UpdateMode
 	kubeContext   k8s.KubeContext
 	startedTime   metav1.MicroTime
 
 	monitors map[string]*monitor
 
+	// Whether the user may exec into pods, by namespace. Only read and
+	// written from Reconcile(), so it's guarded by mu.
+	execAllowed map[k8s.Namespace]execCheck
+
 	// We need to be able to map trigger events to known resources while
 	// Reconcile() is running.
 	mu sync.Mutex
@@ -72,11 +78,21 @@ type Reconciler struct {
 
 var _ reconcile.Reconciler = &Reconciler{}
 
+// How long to trust a pods/exec permission check. RBAC can change under us,
+// so we re-check periodically.
+const execCheckTTL = 5 * time.Minute
+
+type execCheck struct {
+	allowed   bool
+	checkedAt time.Time
+}
+
 // Dependency-inject a live update reconciler.
 func NewReconciler(
 	st store.RStore,
 	dcu *containerupdate.DockerUpdater,
 	ecu *containerupdate.ExecUpdater,
+	acu *containerupdate.AgentUpdater,
 	updateMode liveupdates.UpdateMode,
 	kubeContext k8s.KubeContext,
 	client ctrlclient.Client,
@@ -84,6 +100,7 @@ func NewReconciler(
 	return &Reconciler{
 		DockerUpdater: dcu,
 		ExecUpdater:   ecu,
+		AgentUpdater:  acu,
 		updateMode:    updateMode,
 		kubeContext:   kubeContext,
 		client:        client,
@@ -91,6 +108,7 @@ func NewReconciler(
 		store:         st,
 		startedTime:   apis.NowMicro(),
 		monitors:      make(map[string]*monitor),
+		execAllowed:   make(map[k8s.Namespace]execCheck),
 	}
 }
 
@@ -103,6 +121,7 @@ func NewFakeReconciler(
 	return &Reconciler{
 		DockerUpdater: cu,
 		ExecUpdater:   cu,
+		AgentUpdater:  cu,
 		updateMode:    liveupdates.UpdateModeAuto,
 		kubeContext:   k8s.KubeContext("fake-context"),
 		client:        client,
@@ -110,6 +129,7 @@ func NewFakeReconciler(
 		store:         st,
 		startedTime:   apis.NowMicro(),
 		monitors:      make(map[string]*monitor),
+		execAllowed:   make(map[k8s.Namespace]execCheck),
 	}
 }
 
@@ -778,6 +798,7 @@ func (r *Reconciler) maybeSync(ctx context.Context, lu *v1alpha1.LiveUpdate, mon
 				ChangedFiles:       plan.SyncPaths,
 				Containers:         []liveupdates.Container{c},
 				LastFileTimeSynced: newHighWaterMark,
+				StepSource:         lu.Annotations[liveupdate.AnnotationStepSource],
 			})
 			filesApplied = true
 		}
@@ -885,7 +906,19 @@ func (r *Reconciler) applyInternal(
 	input Input) v1alpha1.LiveUpdateStatus {
 
 	var result v1alpha1.LiveUpdateStatus
-	cu := r.containerUpdater(input)
+	cu, err := r.containerUpdater(ctx, input)
+	if err != nil {
+		result.Failed = &v1alpha1.LiveUpdateStateFailed{
+			Reason:  "NoTransport",
+			Message: err.Error(),
+		}
+		return result
+	}
+	state := r.store.RLockState()
+	execTimeout := state.UpdateSettings.LiveUpdateExecTimeout()
+	r.store.RUnlockState()
+	ctx = containerupdate.WithExecTimeout(ctx, execTimeout)
+
 	l := logger.Get(ctx)
 	containers := input.Containers
 	names := liveupdates.ContainerDisplayNames(containers)
@@ -930,6 +963,10 @@ func (r *Reconciler) applyInternal(
 		}
 	}
 
+	if input.StepSource != "" {
+		l.Infof("Using live_update steps from %s for container%s: %s", input.StepSource, suffix, names)
+	}
+
 	var lastExecErrorStatus *v1alpha1.LiveUpdateContainerStatus
 	for _, cInfo := range containers {
 		// TODO(nick): We should try to distinguish between cases where the tar writer
@@ -964,6 +1001,7 @@ func (r *Reconciler) applyInternal(
 			} else {
 				// Something went wrong with this update and it's NOT the user's fault--
 				// likely a infrastructure error. Bail, and fall back to full build.
+				r.maybeForgetExecAllowed(cu, cInfo.Namespace, err)
 				msg := ""
 				if cStatus.PodName != "" {
 					msg = fmt.Sprintf("Updating pod %s: %v", cStatus.PodName, err)
@@ -995,22 +1033,101 @@ func (r *Reconciler) applyInternal(
 	return result
 }
 
-func (r *Reconciler) containerUpdater(input Input) containerupdate.ContainerUpdater {
+func (r *Reconciler) containerUpdater(ctx context.Context, input Input) (containerupdate.ContainerUpdater, error) {
 	isDC := input.IsDC
 	if isDC || r.updateMode == liveupdates.UpdateModeContainer {
-		return r.DockerUpdater
+		return r.DockerUpdater, nil
 	}
 
 	if r.updateMode == liveupdates.UpdateModeKubectlExec {
-		return r.ExecUpdater
+		return r.ExecUpdater, nil
 	}
 
 	dcu, ok := r.DockerUpdater.(*containerupdate.DockerUpdater)
 	if ok && dcu.WillBuildToKubeContext(r.kubeContext) {
-		return r.DockerUpdater
+		return r.DockerUpdater, nil
+	}
+
+	return r.k8sUpdater(ctx, input)
+}
+
+// Picks how to reach containers in the Kubernetes cluster, as configured by
+// update_settings(k8s_live_update_transport).
+func (r *Reconciler) k8sUpdater(ctx context.Context, input Input) (containerupdate.ContainerUpdater, error) {
+	state := r.store.RLockState()
+	transport := state.UpdateSettings.LiveUpdateTransportFor(string(r.kubeContext))
+	r.store.RUnlockState()
+
+	switch transport {
+	case model.LiveUpdateTransportExec:
+		return r.ExecUpdater, nil
+	case model.LiveUpdateTransportAgent:
+		return r.AgentUpdater, nil
+	}
+
+	var forbidden []string
+	for _, c := range input.Containers {
+		if !r.canExec(ctx, c.Namespace) {
+			forbidden = append(forbidden, c.Namespace.String())
+		}
 	}
+	if len(forbidden) == 0 {
+		return r.ExecUpdater, nil
+	}
+
+	acu, ok := r.AgentUpdater.(*containerupdate.AgentUpdater)
+	if !ok {
+		return r.AgentUpdater, nil
+	}
+	agents, err := acu.Agents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Live update can't run: you don't have pods/exec permission in namespace %q, "+
+			"and checking for the Tilt agent failed: %v", forbidden[0], err)
+	}
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("Live update can't run: you don't have pods/exec permission in namespace %q, "+
+			"and no Tilt agent pods are running in namespace %q.\n"+
+			"Ask your cluster admin for pods/exec permission, or to deploy the Tilt agent daemonset "+
+			"(pods labeled %s=%s) from %s.",
+			forbidden[0], agent.Namespace, agent.LabelName, agent.LabelApp, agent.ManifestURL)
+	}
+	return r.AgentUpdater, nil
+}
+
+// Checks whether the user may exec into pods in the namespace. If the
+// check itself fails, assume they can, and let exec report any error.
+//
+// Must be called with r.mu held.
+func (r *Reconciler) canExec(ctx context.Context, ns k8s.Namespace) bool {
+	check, ok := r.execAllowed[ns]
+	if ok && time.Since(check.checkedAt) < execCheckTTL {
+		return check.allowed
+	}
+
+	ecu, ok := r.ExecUpdater.(*containerupdate.ExecUpdater)
+	if !ok {
+		return true
+	}
+	allowed, err := ecu.CanExec(ctx, ns)
+	if err != nil {
+		logger.Get(ctx).Debugf("Checking pods/exec permission: %v", err)
+		return true
+	}
+	r.execAllowed[ns] = execCheck{allowed: allowed, checkedAt: time.Now()}
+	if !allowed {
+		logger.Get(ctx).Infof("No pods/exec permission in namespace %q. Live updating with the Tilt agent.", ns)
+	}
+	return allowed
+}
 
-	return r.ExecUpdater
+// If exec was rejected by the server, our cached permission check is stale.
+// Forget it, so that the next update checks again.
+//
+// Must be called with r.mu held.
+func (r *Reconciler) maybeForgetExecAllowed(cu containerupdate.ContainerUpdater, ns k8s.Namespace, err error) {
+	if cu == r.ExecUpdater && apierrors.IsForbidden(err) {
+		delete(r.execAllowed, ns)
+	}
 }
 
 func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
@@ -11,7 +11,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/buildcontrols"
 	"github.com/tilt-dev/tilt/pkg/apis"
@@ -149,6 +152,32 @@ func TestConsumeFileEvents(t *testing.T) {
 	assert.NotNil(t, f.st.lastCompletedAction)
 }
 
+func TestNoLiveUpdateTransport(t *testing.T) {
+	f := newFixture(t)
+
+	kCli := k8s.NewFakeK8sClient(t)
+	kCli.ExecForbidden = true
+	f.r.ExecUpdater = containerupdate.NewExecUpdater(kCli)
+	f.r.AgentUpdater = containerupdate.NewAgentUpdater(kCli)
+
+	p, _ := os.Getwd()
+	nowMicro := apis.NowMicro()
+	txtPath := filepath.Join(p, "a.txt")
+	txtChangeTime := metav1.MicroTime{Time: nowMicro.Add(time.Second)}
+
+	f.setupFrontend()
+	f.addFileEvent("frontend-fw", txtPath, txtChangeTime)
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	var lu v1alpha1.LiveUpdate
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	if assert.NotNil(t, lu.Status.Failed) {
+		assert.Equal(t, "NoTransport", lu.Status.Failed.Reason)
+		assert.Contains(t, lu.Status.Failed.Message, `you don't have pods/exec permission in namespace "default"`)
+		assert.Contains(t, lu.Status.Failed.Message, `no Tilt agent pods are running in namespace "tilt-agent"`)
+	}
+}
+
 func TestConsumeFileEventsDockerCompose(t *testing.T) {
 	f := newFixture(t)
 
@@ -1142,3 +1171,48 @@ func (f *fixture) kdUpdateStatus(name string, status v1alpha1.KubernetesDiscover
 	update.Status = status
 	f.UpdateStatus(update)
 }
+
+func TestExecForbiddenRechecksPermission(t *testing.T) {
+	f := newFixture(t)
+
+	kCli := k8s.NewFakeK8sClient(t)
+	kCli.ExecErrors = []error{
+		apierrors.NewForbidden(schema.GroupResource{Resource: "pods/exec"}, "pod-1", fmt.Errorf("no")),
+	}
+	f.r.ExecUpdater = containerupdate.NewExecUpdater(kCli)
+	f.r.AgentUpdater = containerupdate.NewAgentUpdater(kCli)
+
+	p, _ := os.Getwd()
+	nowMicro := apis.NowMicro()
+	txtPath := filepath.Join(p, "a.txt")
+
+	f.setupFrontend()
+	f.addFileEvent("frontend-fw", txtPath, metav1.MicroTime{Time: nowMicro.Add(time.Second)})
+	f.MustReconcile(types.NamespacedName{Name: "frontend-liveupdate"})
+
+	var lu v1alpha1.LiveUpdate
+	f.MustGet(types.NamespacedName{Name: "frontend-liveupdate"}, &lu)
+	if assert.NotNil(t, lu.Status.Failed) {
+		assert.Equal(t, "UpdateFailed", lu.Status.Failed.Reason)
+	}
+	assert.NotContains(t, f.r.execAllowed, k8s.Namespace("default"))
+
+	// The next update checks the permission again, rather than trusting the
+	// cached answer.
+	kCli.ExecForbidden = true
+	assert.False(t, f.r.canExec(f.Context(), "default"))
+}
+
+func TestExecPermissionCheckExpires(t *testing.T) {
+	f := newFixture(t)
+
+	kCli := k8s.NewFakeK8sClient(t)
+	kCli.ExecForbidden = true
+	f.r.ExecUpdater = containerupdate.NewExecUpdater(kCli)
+
+	f.r.execAllowed["default"] = execCheck{allowed: true, checkedAt: time.Now()}
+	assert.True(t, f.r.canExec(f.Context(), "default"))
+
+	f.r.execAllowed["default"] = execCheck{allowed: true, checkedAt: time.Now().Add(-execCheckTTL)}
+	assert.False(t, f.r.canExec(f.Context(), "default"))
+}
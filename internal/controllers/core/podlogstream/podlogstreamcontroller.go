@@ -26,6 +26,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/engine/runtimelog"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/store"
@@ -39,6 +40,7 @@ var podLogReconnectGap = 2 * time.Second
 const maxDebounceDuration = time.Minute
 
 var clusterGVK = v1alpha1.SchemeGroupVersion.WithKind("Cluster")
+var configMapGVK = v1alpha1.SchemeGroupVersion.WithKind("ConfigMap")
 
 // Reconciles the PodLogStream API object.
 //
@@ -79,16 +81,13 @@ func NewController(ctx context.Context, client ctrlclient.Client, scheme *runtim
 }
 
 // Filter containers based on the inclusions/exclusions in the PodLogStream spec.
+//
+// The names in the spec may be globs, like "*-proxy".
 func (c *Controller) filterContainers(stream *PodLogStream, containers []v1alpha1.Container) []v1alpha1.Container {
 	if len(stream.Spec.OnlyContainers) > 0 {
-		only := make(map[container.Name]bool, len(stream.Spec.OnlyContainers))
-		for _, name := range stream.Spec.OnlyContainers {
-			only[container.Name(name)] = true
-		}
-
 		result := []v1alpha1.Container{}
 		for _, c := range containers {
-			if only[container.Name(c.Name)] {
+			if container.Name(c.Name).MatchesAny(stream.Spec.OnlyContainers) {
 				result = append(result, c)
 			}
 		}
@@ -96,14 +95,9 @@ func (c *Controller) filterContainers(stream *PodLogStream, containers []v1alpha
 	}
 
 	if len(stream.Spec.IgnoreContainers) > 0 {
-		ignore := make(map[container.Name]bool, len(stream.Spec.IgnoreContainers))
-		for _, name := range stream.Spec.IgnoreContainers {
-			ignore[container.Name(name)] = true
-		}
-
 		result := []v1alpha1.Container{}
 		for _, c := range containers {
-			if !ignore[container.Name(c.Name)] {
+			if !container.Name(c.Name).MatchesAny(stream.Spec.IgnoreContainers) {
 				result = append(result, c)
 			}
 		}
@@ -182,8 +176,19 @@ func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reco
 }
 
 func (c *Controller) addOrUpdateContainerWatches(ctx context.Context, streamName types.NamespacedName, stream *v1alpha1.PodLogStream, podNN types.NamespacedName, pod *v1.Pod) reconcile.Result {
-	initContainers := c.filterContainers(stream, k8sconv.PodContainers(ctx, pod, pod.Status.InitContainerStatuses))
-	runContainers := c.filterContainers(stream, k8sconv.PodContainers(ctx, pod, pod.Status.ContainerStatuses))
+	// The resource's log container filters can be turned off at runtime,
+	// e.g., to see what a sidecar is doing.
+	showAll, err := configmap.ShowAllLogContainers(ctx, c.client, stream.Annotations[v1alpha1.AnnotationManifest])
+	if err != nil {
+		return c.setErrorStatus(streamName, err)
+	}
+
+	initContainers := k8sconv.PodContainers(ctx, pod, pod.Status.InitContainerStatuses)
+	runContainers := k8sconv.PodContainers(ctx, pod, pod.Status.ContainerStatuses)
+	if !showAll {
+		initContainers = c.filterContainers(stream, initContainers)
+		runContainers = c.filterContainers(stream, runContainers)
+	}
 	containers := []v1alpha1.Container{}
 	containers = append(containers, initContainers...)
 	containers = append(containers, runContainers...)
@@ -527,7 +532,9 @@ func (c *Controller) maybeUpdateObjectStatus(ctx context.Context, nn types.Names
 func (c *Controller) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 	b := ctrl.NewControllerManagedBy(mgr).
 		For(&PodLogStream{}).
-		WatchesRawSource(c.podSource, handler.Funcs{})
+		WatchesRawSource(c.podSource, handler.Funcs{}).
+		Watches(&v1alpha1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(c.indexer.Enqueue))
 
 	return b, nil
 }
@@ -569,5 +576,11 @@ func indexPodLogStreamForTiltAPI(obj ctrlclient.Object) []indexer.Key {
 			GVK:  clusterGVK,
 		})
 	}
+	if pls != nil && pls.Annotations[v1alpha1.AnnotationManifest] != "" {
+		results = append(results, indexer.Key{
+			Name: types.NamespacedName{Name: configmap.AllLogContainersName(pls.Annotations[v1alpha1.AnnotationManifest])},
+			GVK:  configMapGVK,
+		})
+	}
 	return results
 }
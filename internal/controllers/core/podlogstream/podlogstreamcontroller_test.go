@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis"
 
@@ -361,6 +362,70 @@ func TestIgnoredContainerLogs(t *testing.T) {
 	f.AssertOutputContains("hello world!")
 }
 
+func TestContainerLogPatterns(t *testing.T) {
+	f := newPLMFixture(t)
+
+	pb := newPodBuilder(podID).
+		addRunningContainer("api", "cID-api").
+		addRunningContainer("envoy-proxy", "cID-envoy").
+		addRunningContainer("log-shipper", "cID-shipper")
+	f.kClient.UpsertPod(pb.toPod())
+
+	f.kClient.SetLogsForPodContainer(podID, "api", "hello api!")
+	f.kClient.SetLogsForPodContainer(podID, "envoy-proxy", "hello envoy!")
+	f.kClient.SetLogsForPodContainer(podID, "log-shipper", "hello shipper!")
+
+	pls := plsFromPod("server", pb, time.Time{})
+	pls.Spec.IgnoreContainers = []string{"*-proxy", "log-*"}
+	f.Create(pls)
+
+	f.AssertOutputContains("hello api!")
+	f.AssertOutputDoesNotContain("envoy")
+	f.AssertOutputDoesNotContain("shipper")
+}
+
+func TestShowAllLogContainers(t *testing.T) {
+	f := newPLMFixture(t)
+
+	pb := newPodBuilder(podID).
+		addRunningContainer("api", "cID-api").
+		addRunningContainer("envoy-proxy", "cID-envoy")
+	f.kClient.UpsertPod(pb.toPod())
+
+	f.kClient.SetLogsForPodContainer(podID, "api", "hello api!")
+	f.kClient.SetLogsForPodContainer(podID, "envoy-proxy", "hello envoy!")
+
+	pls := plsFromPod("server", pb, time.Time{})
+	pls.Spec.OnlyContainers = []string{"api"}
+	f.Create(pls)
+
+	f.AssertOutputContains("hello api!")
+	f.AssertOutputDoesNotContain("envoy")
+
+	f.Create(&v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: configmap.AllLogContainersName("server")},
+		Data:       map[string]string{configmap.AllLogContainersKey: "true"},
+	})
+	f.MustReconcile(types.NamespacedName{Name: pls.Name})
+
+	f.AssertOutputContains("hello envoy!")
+}
+
+func TestReconcilerIndexingAllLogContainers(t *testing.T) {
+	f := newPLMFixture(t)
+
+	pls := plsFromPod("server", newPodBuilder(podID), f.clock.Now())
+	f.Create(pls)
+
+	ctx := context.Background()
+	reqs := f.plsc.indexer.Enqueue(ctx, &v1alpha1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: configmap.AllLogContainersName("server")},
+	})
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "default-pod-id"}},
+	}, reqs)
+}
+
 // Our old Fake Kubernetes client used to interact badly
 // with the pod log stream reconciler, leading to an infinite
 // loop in tests.
@@ -2,7 +2,9 @@ package portforward
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +16,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/timecmp"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -43,6 +46,10 @@ type Reconciler struct {
 	requeuer   *indexer.Requeuer
 	indexer    *indexer.Indexer
 
+	// Registers the local ports that forwards listen on, so that if Tilt
+	// exits without closing them, the next session can report who has them.
+	supervisor *localexec.Supervisor
+
 	// map of PortForward object name --> running forward(s)
 	activeForwards map[types.NamespacedName]*portForwardEntry
 }
@@ -55,6 +62,7 @@ func NewReconciler(
 	scheme *runtime.Scheme,
 	store store.RStore,
 	clients cluster.ClientProvider,
+	localEnv *localexec.Env,
 ) *Reconciler {
 	return &Reconciler{
 		store:          store,
@@ -62,6 +70,7 @@ func NewReconciler(
 		clients:        cluster.NewClientManager(clients),
 		requeuer:       indexer.NewRequeuer(),
 		indexer:        indexer.NewIndexer(scheme, indexPortForward),
+		supervisor:     localEnv.Supervisor(),
 		activeForwards: make(map[types.NamespacedName]*portForwardEntry),
 	}
 }
@@ -195,6 +204,7 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 		int(forward.ContainerPort),
 		forward.Host)
 	if err != nil {
+		err = describePortConflict(err, forward.LocalPort)
 		logError(err)
 		entry.setStatus(forward, ForwardStatus{
 			LocalPort:     forward.LocalPort,
@@ -209,7 +219,9 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 	// the doneCh ensures we don't leak the goroutine if ForwardPorts() errors out early without
 	// ever becoming ready
 	doneCh := make(chan struct{}, 1)
+	readyDoneCh := make(chan struct{})
 	go func() {
+		defer close(readyDoneCh)
 		readyCh := pf.ReadyCh()
 		if readyCh == nil {
 			return
@@ -222,6 +234,10 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 			// forward initialization errored at start before ready
 			return
 		case <-readyCh:
+			r.supervisor.AddPort(localexec.RegisteredPort{
+				Port:    pf.LocalPort(),
+				Purpose: fmt.Sprintf("port-forward %s", entry.meta.Annotations[v1alpha1.AnnotationManifest]),
+			})
 			entry.setStatus(forward, ForwardStatus{
 				LocalPort:     int32(pf.LocalPort()),
 				ContainerPort: forward.ContainerPort,
@@ -234,7 +250,10 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 
 	err = pf.ForwardPorts()
 	close(doneCh)
+	<-readyDoneCh
+	r.supervisor.RemovePort(pf.LocalPort())
 	if err != nil {
+		err = describePortConflict(err, forward.LocalPort)
 		logError(err)
 		entry.setStatus(forward, ForwardStatus{
 			LocalPort:     int32(pf.LocalPort()),
@@ -247,6 +266,19 @@ func (r *Reconciler) onePortForward(ctx context.Context, entry *portForwardEntry
 	}
 }
 
+// describePortConflict adds the process that holds the local port to an
+// error from a port that's already in use, if it can be found.
+func describePortConflict(err error, localPort int32) error {
+	if localPort == 0 || !strings.Contains(err.Error(), "address already in use") {
+		return err
+	}
+	holder, ok := localexec.FindPortHolder(int(localPort))
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%v (port %d is held by %s)", err, localPort, holder)
+}
+
 func (r *Reconciler) TearDown(_ context.Context) {
 	for name := range r.activeForwards {
 		r.stop(name)
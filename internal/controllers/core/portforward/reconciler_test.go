@@ -19,6 +19,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apis/cluster"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/apis"
 
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -74,6 +75,24 @@ func TestDeletePortForward(t *testing.T) {
 	f.assertContextCancelled(t, origForwardCtx)
 }
 
+func TestPortForwardRegistersPort(t *testing.T) {
+	f := newPFRFixture(t)
+
+	pf := f.makeSimplePF(pfFooName, 8000, 8080)
+	f.Create(pf)
+	f.requirePortForwardStarted(pfFooName, 8000, 8080)
+
+	assert.Equal(t, []localexec.RegisteredPort{
+		{Port: 8000, Purpose: "port-forward manifest-pf_foo"},
+	}, f.r.supervisor.RegisteredPorts())
+
+	f.Delete(pf)
+	f.requirePortForwardDeleted(pfFooName)
+	require.Eventually(t, func() bool {
+		return len(f.r.supervisor.RegisteredPorts()) == 0
+	}, time.Second, 10*time.Millisecond, "port still registered")
+}
+
 func TestModifyPortForward(t *testing.T) {
 	f := newPFRFixture(t)
 
@@ -352,7 +371,7 @@ type pfrFixture struct {
 func newPFRFixture(t *testing.T) *pfrFixture {
 	cfb := fake.NewControllerFixtureBuilder(t)
 	clients := cluster.NewFakeClientProvider(t, cfb.Client)
-	r := NewReconciler(cfb.Client, cfb.Scheme(), cfb.Store, clients)
+	r := NewReconciler(cfb.Client, cfb.Scheme(), cfb.Store, clients, localexec.EmptyEnv())
 	indexer.StartSourceForTesting(cfb.Context(), r.requeuer, r, nil)
 
 	return &pfrFixture{
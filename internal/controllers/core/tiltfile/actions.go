@@ -15,6 +15,7 @@ type ConfigsReloadStartedAction struct {
 	StartTime    time.Time
 	SpanID       logstore.SpanID
 	Reason       model.BuildReason
+	ArgsChange   *model.TiltfileArgsChange
 }
 
 func (ConfigsReloadStartedAction) Action() {}
@@ -23,9 +24,10 @@ type ConfigsReloadedAction struct {
 	Name model.ManifestName
 
 	// TODO(nick): Embed TiltfileLoadResult instead of copying fields.
-	Manifests   []model.Manifest
-	Tiltignore  model.Dockerignore
-	ConfigFiles []string
+	Manifests      []model.Manifest
+	ResourceGroups []model.ResourceGroup
+	Tiltignore     model.Dockerignore
+	ConfigFiles    []string
 
 	FinishTime           time.Time
 	Err                  error
@@ -43,6 +45,10 @@ type ConfigsReloadedAction struct {
 	// A checkpoint into the logstore when Tiltfile execution started.
 	// Useful for knowing how far back in time we have to scrub secrets.
 	CheckpointAtExecStart logstore.Checkpoint
+
+	// Whether the Tiltfile failed to load with new args, and we went back
+	// to the old ones.
+	ArgsReverted bool
 }
 
 func (ConfigsReloadedAction) Action() {}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -16,9 +17,12 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
 	"github.com/tilt-dev/tilt/internal/controllers/apiset"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/feature"
@@ -110,10 +114,24 @@ func updateOwnedObjects(
 		break
 	}
 
+	// The log container toggles are runtime state; keep their values
+	// across Tiltfile reloads.
+	newConfigMaps := apiObjects.GetSetForType(&v1alpha1.ConfigMap{})
+	oldConfigMaps := existingObjects.GetSetForType(&v1alpha1.ConfigMap{})
+	if tlr != nil {
+		for _, m := range tlr.Manifests {
+			name := configmap.AllLogContainersName(m.Name.String())
+			if _, ok := newConfigMaps[name]; !ok {
+				continue
+			}
+			if old, ok := oldConfigMaps[name]; ok {
+				newConfigMaps[name] = old
+			}
+		}
+	}
+
 	if !changeEnabledResources {
 		// if we're not changing enabled resources, use existing values for disable configmaps
-		newConfigMaps := apiObjects.GetSetForType(&v1alpha1.ConfigMap{})
-		oldConfigMaps := existingObjects.GetSetForType(&v1alpha1.ConfigMap{})
 		for _, ds := range disableSources {
 			if old, ok := oldConfigMaps[ds.ConfigMap.Name]; ok {
 				newConfigMaps[ds.ConfigMap.Name] = old
@@ -246,10 +264,16 @@ func toAPIObjects(
 		result.AddSetForType(&v1alpha1.ConfigMap{}, toDisableConfigMaps(disableSources, tlr.EnabledManifests))
 		result.AddSetForType(&v1alpha1.Cmd{}, toCmdObjects(tlr, disableSources))
 		result.AddSetForType(&v1alpha1.ToggleButton{}, toToggleButtons(disableSources))
+		result.AddSetForType(&v1alpha1.ConfigMap{}, toAllLogContainersConfigMaps(tlr))
+		result.AddSetForType(&v1alpha1.ToggleButton{}, toAllLogContainersToggleButtons(tlr))
 		result.AddSetForType(&v1alpha1.Cluster{}, toClusterObjects(nn, tlr, defaultK8sConnection))
 		result.AddSetForType(&v1alpha1.UIButton{}, toCancelButtons(tlr))
 	}
 
+	if tf != nil {
+		result.AddSetForType(&v1alpha1.UIButton{}, toArgsButtons(tf))
+	}
+
 	result.AddSetForType(&v1alpha1.Session{}, toSessionObjects(nn, tf, tlr, ciTimeoutFlag, mode))
 	result.AddSetForType(&v1alpha1.UIResource{}, toUIResourceObjects(tf, tlr, disableSources))
 
@@ -383,15 +407,107 @@ func toToggleButtons(disableSources disableSourceMap) apiset.TypedObjectSet {
 	return result
 }
 
+// Whether a manifest's logs leave out some of its containers, beyond the
+// service mesh containers that are always left out.
+func hasLogContainerFilters(m model.Manifest) bool {
+	if !m.IsK8s() {
+		return false
+	}
+	spec := m.K8sTarget().KubernetesApplySpec.PodLogStreamTemplateSpec
+	if spec == nil {
+		return false
+	}
+	if len(spec.OnlyContainers) > 0 {
+		return true
+	}
+
+	defaults := make(map[string]bool, len(container.DefaultIgnoredLogContainers))
+	for _, name := range container.DefaultIgnoredLogContainers {
+		defaults[string(name)] = true
+	}
+	for _, name := range spec.IgnoreContainers {
+		if !defaults[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func toAllLogContainersConfigMaps(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
+	result := apiset.TypedObjectSet{}
+	for _, m := range tlr.Manifests {
+		if !hasLogContainerFilters(m) {
+			continue
+		}
+		cm := &v1alpha1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: configmap.AllLogContainersName(m.Name.String()),
+			},
+			Data: map[string]string{configmap.AllLogContainersKey: "false"},
+		}
+		result[cm.Name] = cm
+	}
+	return result
+}
+
+func toAllLogContainersToggleButtons(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
+	result := apiset.TypedObjectSet{}
+	for _, m := range tlr.Manifests {
+		if !hasLogContainerFilters(m) {
+			continue
+		}
+		name := configmap.AllLogContainersName(m.Name.String())
+		tb := &v1alpha1.ToggleButton{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Spec: v1alpha1.ToggleButtonSpec{
+				Location: v1alpha1.UIComponentLocation{
+					ComponentID:   m.Name.String(),
+					ComponentType: v1alpha1.ComponentTypeResource,
+				},
+				On: v1alpha1.ToggleButtonStateSpec{
+					Text:     "Show Filtered Logs",
+					IconName: "filter_alt",
+				},
+				Off: v1alpha1.ToggleButtonStateSpec{
+					Text:     "Show All Containers' Logs",
+					IconName: "visibility",
+				},
+				StateSource: v1alpha1.StateSource{
+					ConfigMap: &v1alpha1.ConfigMapStateSource{
+						Name:     name,
+						Key:      configmap.AllLogContainersKey,
+						OnValue:  "true",
+						OffValue: "false",
+					},
+				},
+			},
+		}
+		result[tb.Name] = tb
+	}
+	return result
+}
+
 func toCancelButtons(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet {
 	result := apiset.TypedObjectSet{}
 	for _, m := range tlr.Manifests {
 		button := uibutton.StopBuildButton(m.Name.String())
 		result[button.Name] = button
+
+		reset := uibutton.ResetButton(m.Name.String())
+		result[reset.Name] = reset
 	}
 	return result
 }
 
+// The button that sets the Tiltfile's args from the web UI.
+// Its input always shows the args currently in effect.
+func toArgsButtons(tf *v1alpha1.Tiltfile) apiset.TypedObjectSet {
+	button := uibutton.ArgsButton(tf.Name, tf.Spec.Args)
+	return apiset.TypedObjectSet{button.Name: button}
+}
+
 // Pulls out all the KubernetesApply objects generated by the Tiltfile.
 func toKubernetesApplyObjects(tlr *tiltfile.TiltfileLoadResult, disableSources disableSourceMap) apiset.TypedObjectSet {
 	result := apiset.TypedObjectSet{}
@@ -479,6 +595,9 @@ func toLiveUpdateObjects(tlr *tiltfile.TiltfileLoadResult) apiset.TypedObjectSet
 				},
 				Spec: luSpec,
 			}
+			if iTarget.LiveUpdateSource != "" {
+				obj.Annotations[liveupdate.AnnotationStepSource] = iTarget.LiveUpdateSource
+			}
 			result[luName] = obj
 		}
 	}
@@ -615,11 +734,19 @@ func toClusterObjects(nn types.NamespacedName, tlr *tiltfile.TiltfileLoadResult,
 	}
 
 	if tlr.HasOrchestrator(model.OrchestratorK8s) {
+		k8sAnnotations := annotations
+		if tlr.DefaultRegistryPullSecret != nil {
+			k8sAnnotations = tlr.DefaultRegistryPullSecret.Annotations()
+			for k, v := range annotations {
+				k8sAnnotations[k] = v
+			}
+		}
+
 		name := v1alpha1.ClusterNameDefault
 		result[name] = &v1alpha1.Cluster{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:        name,
-				Annotations: annotations,
+				Annotations: k8sAnnotations,
 			},
 			Spec: v1alpha1.ClusterSpec{
 				Connection: &v1alpha1.ClusterConnection{
@@ -745,6 +872,35 @@ func toUIResourceObjects(tf *v1alpha1.Tiltfile, tlr *tiltfile.TiltfileLoadResult
 
 			result[name] = r
 		}
+
+		for _, g := range tlr.ResourceGroups {
+			var members []string
+			var sources []v1alpha1.DisableSource
+			for _, mn := range g.Members {
+				members = append(members, mn.String())
+				if ds := disableSources[mn]; ds != nil {
+					sources = append(sources, *ds)
+				}
+			}
+
+			r := &v1alpha1.UIResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   g.Name.String(),
+					Labels: g.Labels,
+					Annotations: map[string]string{
+						uiresource.AnnotationGroupMembers: strings.Join(members, ","),
+					},
+				},
+			}
+
+			// Disabling the group disables every member.
+			if len(sources) > 0 {
+				r.Status.DisableStatus.State = v1alpha1.DisableStatePending
+				r.Status.DisableStatus.Sources = sources
+			}
+
+			result[g.Name.String()] = r
+		}
 	}
 
 	if tf != nil {
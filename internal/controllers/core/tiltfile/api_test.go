@@ -14,6 +14,7 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils/configmap"
@@ -279,6 +280,32 @@ func TestCreateClusterDefaultRegistry(t *testing.T) {
 	require.Equal(t, "fake-repo", cluster.Spec.DefaultRegistry.SingleName, "Default registry single name")
 }
 
+func TestCreateClusterPullSecret(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").
+		WithImageTarget(NewSanchoDockerBuildImageTarget(f)).
+		WithK8sYAML(testyaml.SanchoYAML).
+		Build()
+	tf := &v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{Name: model.MainTiltfileManifestName.String()},
+	}
+	nn := apis.Key(tf)
+	tlr := &tiltfile.TiltfileLoadResult{
+		Manifests:       []model.Manifest{fe},
+		DefaultRegistry: &v1alpha1.RegistryHosting{Host: "registry.example.com"},
+		DefaultRegistryPullSecret: &k8s.PullSecretSettings{
+			From: k8s.PullSecretFromDockerConfig,
+		},
+	}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	assert.NoError(t, err)
+
+	var cluster v1alpha1.Cluster
+	require.NoError(t, f.Get(types.NamespacedName{Name: "default"}, &cluster))
+	assert.Equal(t, k8s.PullSecretFromDockerConfig, cluster.Annotations[k8s.AnnotationPullSecretFrom])
+	assert.Equal(t, "", cluster.Annotations[k8s.AnnotationPullSecretTarget])
+}
+
 // Ensure that we emit disable-related objects/field appropriately
 func TestDisableObjects(t *testing.T) {
 	f := newAPIFixture(t)
@@ -365,6 +392,45 @@ func TestUpdateDisableSource(t *testing.T) {
 	require.Equal(t, "true", cm.Data["isDisabled"])
 }
 
+// A resource with log container filters gets a toggle to show all its containers' logs,
+// and the toggle's state survives a reload.
+func TestAllLogContainersToggle(t *testing.T) {
+	f := newAPIFixture(t)
+	fe := manifestbuilder.New(f, "fe").WithK8sYAML(testyaml.SanchoSidecarYAML).Build()
+	kTarget := fe.K8sTarget()
+	kTarget.KubernetesApplySpec.PodLogStreamTemplateSpec = &v1alpha1.PodLogStreamTemplateSpec{
+		OnlyContainers: []string{"sancho"},
+	}
+	fe = fe.WithDeployTarget(kTarget)
+	be := manifestbuilder.New(f, "be").WithK8sYAML(testyaml.SanchoYAML).Build()
+
+	nn := types.NamespacedName{Name: "tiltfile"}
+	tf := &v1alpha1.Tiltfile{ObjectMeta: metav1.ObjectMeta{Name: "tiltfile"}}
+	tlr := &tiltfile.TiltfileLoadResult{Manifests: []model.Manifest{fe, be}}
+	err := f.updateOwnedObjects(nn, tf, tlr)
+	require.NoError(t, err)
+
+	var tb v1alpha1.ToggleButton
+	require.NoError(t, f.Get(types.NamespacedName{Name: "fe-all-log-containers"}, &tb))
+	assert.Equal(t, "fe", tb.Spec.Location.ComponentID)
+	assert.Equal(t, "fe-all-log-containers", tb.Spec.StateSource.ConfigMap.Name)
+
+	err = f.Get(types.NamespacedName{Name: "be-all-log-containers"}, &tb)
+	assert.True(t, apierrors.IsNotFound(err))
+
+	var cm v1alpha1.ConfigMap
+	require.NoError(t, f.Get(types.NamespacedName{Name: "fe-all-log-containers"}, &cm))
+	assert.Equal(t, "false", cm.Data["showAll"])
+
+	cm.Data["showAll"] = "true"
+	require.NoError(t, f.c.Update(f.ctx, &cm))
+
+	err = f.updateOwnedObjects(nn, tf, tlr)
+	require.NoError(t, err)
+	require.NoError(t, f.Get(types.NamespacedName{Name: "fe-all-log-containers"}, &cm))
+	assert.Equal(t, "true", cm.Data["showAll"])
+}
+
 // make sure that objects created by the Tiltfile are included in typesToReconcile, so that
 // they get cleaned up when they go away
 // note: this test is not exhaustive, since not all branches generate all types that are possibly
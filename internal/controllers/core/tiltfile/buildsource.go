@@ -18,6 +18,13 @@ type BuildEntry struct {
 	CheckpointAtExecStart logstore.Checkpoint
 	LoadCount             int
 	ArgsChanged           bool
+
+	// The args of the previous run.
+	PrevArgs []string
+
+	// The args changed since a run that loaded successfully, so if this run
+	// fails, the Tiltfile goes back to PrevArgs.
+	RevertArgsOnError bool
 }
 
 func (be *BuildEntry) WithLogger(ctx context.Context, st store.RStore) context.Context {
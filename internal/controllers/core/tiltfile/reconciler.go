@@ -21,6 +21,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apicmp"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/configmap"
 	"github.com/tilt-dev/tilt/internal/controllers/apis/trigger"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uibutton"
 	"github.com/tilt-dev/tilt/internal/controllers/indexer"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -52,6 +53,9 @@ type Reconciler struct {
 
 	runs map[types.NamespacedName]*runStatus
 
+	// The last click of each Tiltfile's Set Args button that we've handled.
+	lastArgsClick map[types.NamespacedName]time.Time
+
 	// dockerConnectMetricReporter ensures we only report a single Docker connect status
 	// event per `tilt up`. Currently, a client is initialized on start (via wire/DI)
 	// and if there's an error, an exploding client is created; we'll never attempt
@@ -66,6 +70,8 @@ func (r *Reconciler) CreateBuilder(mgr ctrl.Manager) (*builder.Builder, error) {
 		For(&v1alpha1.Tiltfile{}).
 		Watches(&v1alpha1.ConfigMap{},
 			handler.EnqueueRequestsFromMapFunc(r.enqueueTriggerQueue)).
+		Watches(&v1alpha1.UIButton{},
+			handler.EnqueueRequestsFromMapFunc(r.enqueueArgsButton)).
 		WatchesRawSource(r.requeuer, handler.Funcs{})
 
 	trigger.SetupControllerRestartOn(b, r.indexer, func(obj ctrlclient.Object) *v1alpha1.RestartOnSpec {
@@ -96,6 +102,7 @@ func NewReconciler(
 		ctrlClient:           ctrlClient,
 		indexer:              indexer.NewIndexer(scheme, indexTiltfile),
 		runs:                 make(map[types.NamespacedName]*runStatus),
+		lastArgsClick:        make(map[types.NamespacedName]time.Time),
 		requeuer:             indexer.NewRequeuer(),
 		engineMode:           engineMode,
 		k8sContextOverride:   k8sContextOverride,
@@ -119,6 +126,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 
 	if apierrors.IsNotFound(err) || !tf.ObjectMeta.DeletionTimestamp.IsZero() {
 		r.deleteExistingRun(nn)
+		delete(r.lastArgsClick, nn)
 
 		// Delete owned objects
 		err := updateOwnedObjects(ctx, r.ctrlClient, nn, nil, nil, false, r.ciTimeoutFlag, r.engineMode, r.defaultK8sConnection())
@@ -129,10 +137,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, request reconcile.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	ctx = store.MustObjectLogHandler(ctx, r.st, &tf)
+	err = r.maybeSetArgsFromButton(ctx, &tf)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// The apiserver is the source of truth, and will ensure the engine state is up to date.
 	r.st.Dispatch(tiltfiles.NewTiltfileUpsertAction(&tf))
 
-	ctx = store.MustObjectLogHandler(ctx, r.st, &tf)
 	run := r.runs[nn]
 	if run == nil {
 		// Initialize the UISession and filewatch if this has never been initialized before.
@@ -222,10 +235,12 @@ func (r *Reconciler) needsBuild(
 	step := runStepNone
 	lastStartTime := time.Time{}
 	lastStartArgs := []string{}
+	lastLoadSucceeded := false
 	if run != nil {
 		step = run.step
 		lastStartTime = run.startTime
 		lastStartArgs = run.startArgs
+		lastLoadSucceeded = step == runStepDone && run.tlr != nil && run.tlr.Error == nil
 	}
 
 	if step == runStepNone {
@@ -265,6 +280,8 @@ func (r *Reconciler) needsBuild(
 		CheckpointAtExecStart: state.LogStore.Checkpoint(),
 		LoadCount:             r.loadCount,
 		ArgsChanged:           !sliceutils.StringSliceEquals(lastStartArgs, tf.Spec.Args),
+		PrevArgs:              lastStartArgs,
+		RevertArgsOnError:     reason.Has(model.BuildReasonFlagTiltfileArgs) && lastLoadSucceeded,
 	}
 }
 
@@ -294,6 +311,11 @@ func (r *Reconciler) startRunAsync(ctx context.Context, nn types.NamespacedName,
 
 // Executes the tiltfile on a non-blocking goroutine, and requests reconciliation on completion.
 func (r *Reconciler) run(ctx context.Context, nn types.NamespacedName, tf *v1alpha1.Tiltfile, run *runStatus, entry *BuildEntry) {
+	var argsChange *model.TiltfileArgsChange
+	if entry.BuildReason.Has(model.BuildReasonFlagTiltfileArgs) {
+		argsChange = &model.TiltfileArgsChange{From: entry.PrevArgs, To: entry.Args}
+	}
+
 	startTime := time.Now()
 	r.st.Dispatch(ConfigsReloadStartedAction{
 		Name:         entry.Name,
@@ -301,18 +323,16 @@ func (r *Reconciler) run(ctx context.Context, nn types.NamespacedName, tf *v1alp
 		StartTime:    startTime,
 		SpanID:       SpanIDForLoadCount(entry.Name, entry.LoadCount),
 		Reason:       entry.BuildReason,
+		ArgsChange:   argsChange,
 	})
 
 	buildcontrols.LogBuildEntry(ctx, buildcontrols.BuildEntry{
 		Name:         entry.Name,
 		BuildReason:  entry.BuildReason,
 		FilesChanged: entry.FilesChanged,
+		ArgsChange:   argsChange,
 	})
 
-	if entry.BuildReason.Has(model.BuildReasonFlagTiltfileArgs) {
-		logger.Get(ctx).Infof("Tiltfile args changed to: %v", entry.Args)
-	}
-
 	tlr := r.tfl.Load(ctx, tf, run.tlr)
 
 	// If the user is executing an empty main tiltfile, that probably means
@@ -367,13 +387,20 @@ func (r *Reconciler) handleLoaded(
 		return errors.Wrap(err, "Failed to update API server")
 	}
 
+	argsReverted := false
 	if tlr.Error != nil {
 		logger.Get(ctx).Errorf("%s", tlr.Error.Error())
+
+		argsReverted, err = r.revertArgs(ctx, tf, entry)
+		if err != nil {
+			return errors.Wrap(err, "Failed to restore Tiltfile args")
+		}
 	}
 
 	r.st.Dispatch(ConfigsReloadedAction{
 		Name:                  entry.Name,
 		Manifests:             tlr.Manifests,
+		ResourceGroups:        tlr.ResourceGroups,
 		Tiltignore:            tlr.Tiltignore,
 		ConfigFiles:           tlr.ConfigFiles,
 		FinishTime:            time.Now(),
@@ -388,6 +415,7 @@ func (r *Reconciler) handleLoaded(
 		VersionSettings:       tlr.VersionSettings,
 		UpdateSettings:        tlr.UpdateSettings,
 		WatchSettings:         tlr.WatchSettings,
+		ArgsReverted:          argsReverted,
 	})
 
 	run, ok := r.runs[nn]
@@ -403,6 +431,66 @@ func (r *Reconciler) handleLoaded(
 	return nil
 }
 
+// If new args broke a Tiltfile that loaded with the old ones (e.g., they
+// failed config.parse() validation), go back to the old args, so that they
+// stay in effect for the next run. The old args trigger one more run, which
+// restores the resources they enable.
+//
+// Returns true if the args were restored.
+func (r *Reconciler) revertArgs(ctx context.Context, tf *v1alpha1.Tiltfile, entry *BuildEntry) (bool, error) {
+	if !entry.RevertArgsOnError || !sliceutils.StringSliceEquals(tf.Spec.Args, entry.Args) {
+		return false, nil
+	}
+
+	update := tf.DeepCopy()
+	update.Spec.Args = entry.PrevArgs
+	err := r.ctrlClient.Update(ctx, update)
+	if err != nil {
+		return false, err
+	}
+	update.DeepCopyInto(tf)
+
+	logger.Get(ctx).Warnf("Tiltfile failed to load with args %v. Restored the previous args: %v",
+		entry.Args, entry.PrevArgs)
+	return true, nil
+}
+
+// If the user submitted new args with the Set Args button, update the
+// Tiltfile spec, the same way `tilt args` does. The args change triggers
+// a run, which restores the old args if the new ones fail to load.
+func (r *Reconciler) maybeSetArgsFromButton(ctx context.Context, tf *v1alpha1.Tiltfile) error {
+	nn := types.NamespacedName{Name: tf.Name}
+	var button v1alpha1.UIButton
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Name: uibutton.ArgsButtonName(tf.Name)}, &button)
+	if err != nil {
+		return ctrlclient.IgnoreNotFound(err)
+	}
+
+	clickTime := button.Status.LastClickedAt.Time
+	if !clickTime.After(r.lastArgsClick[nn]) {
+		return nil
+	}
+	r.lastArgsClick[nn] = clickTime
+
+	args, ok, err := uibutton.ArgsInputValue(&button)
+	if err != nil {
+		logger.Get(ctx).Errorf("%v", err)
+		return nil
+	}
+	if !ok || sliceutils.StringSliceEquals(args, tf.Spec.Args) {
+		return nil
+	}
+
+	update := tf.DeepCopy()
+	update.Spec.Args = args
+	err = r.ctrlClient.Update(ctx, update)
+	if err != nil {
+		return err
+	}
+	update.DeepCopyInto(tf)
+	return nil
+}
+
 // Cancel execution of a running tiltfile and delete all record of it.
 func (r *Reconciler) deleteExistingRun(nn types.NamespacedName) {
 	run, ok := r.runs[nn]
@@ -418,6 +506,17 @@ func indexTiltfile(obj client.Object) []indexer.Key {
 	return nil
 }
 
+// Find the Tiltfile whose args a Set Args button controls.
+func (r *Reconciler) enqueueArgsButton(ctx context.Context, obj client.Object) []reconcile.Request {
+	button, ok := obj.(*v1alpha1.UIButton)
+	if !ok || button.Annotations[v1alpha1.AnnotationButtonType] != v1alpha1.ButtonTypeSetArgs {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: button.Spec.Location.ComponentID}},
+	}
+}
+
 // Find any objects we need to reconcile based on the trigger queue.
 func (r *Reconciler) enqueueTriggerQueue(ctx context.Context, obj client.Object) []reconcile.Request {
 	cm, ok := obj.(*v1alpha1.ConfigMap)
@@ -289,6 +289,117 @@ func TestArgsChangeResetsEnabledResources(t *testing.T) {
 	f.requireEnabled(m2, true)
 }
 
+func TestArgsFailureRestoresPreviousArgs(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+
+	m1 := manifestbuilder.New(f.tempdir, "m1").WithLocalServeCmd("hi").Build()
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests:        []model.Manifest{m1},
+		EnabledManifests: []model.ManifestName{"m1"},
+	}
+
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-tf",
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path: p,
+			Args: []string{"--services=m1"},
+		},
+	}
+	f.createAndWaitForLoaded(&tf)
+
+	ts := time.Now()
+	f.setArgs("my-tf", []string{"--services=bogus"})
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Error: fmt.Errorf("You specified some resources that could not be found: \"bogus\""),
+	}
+
+	f.MustReconcile(types.NamespacedName{Name: "my-tf"})
+	f.waitForRunning("my-tf")
+	f.popQueue()
+	f.waitForTerminatedAfter("my-tf", ts)
+
+	f.MustGet(types.NamespacedName{Name: "my-tf"}, &tf)
+	assert.Equal(t, []string{"--services=m1"}, tf.Spec.Args)
+	f.requireEnabled(m1, true)
+
+	reloaded := f.lastReloadedAction()
+	assert.True(t, reloaded.ArgsReverted)
+
+	// The restored args start another run. If that fails too, the args stay
+	// where they are.
+	ts = time.Now()
+	f.MustReconcile(types.NamespacedName{Name: "my-tf"})
+	f.waitForRunning("my-tf")
+	f.popQueue()
+	f.waitForTerminatedAfter("my-tf", ts)
+
+	f.MustGet(types.NamespacedName{Name: "my-tf"}, &tf)
+	assert.Equal(t, []string{"--services=m1"}, tf.Spec.Args)
+}
+
+func TestArgsButton(t *testing.T) {
+	f := newFixture(t)
+	p := f.tempdir.JoinPath("Tiltfile")
+
+	m1 := manifestbuilder.New(f.tempdir, "m1").WithLocalServeCmd("hi").Build()
+	f.tfl.Result = tiltfile.TiltfileLoadResult{
+		Manifests:        []model.Manifest{m1},
+		EnabledManifests: []model.ManifestName{"m1"},
+	}
+
+	tf := v1alpha1.Tiltfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "my-tf",
+		},
+		Spec: v1alpha1.TiltfileSpec{
+			Path: p,
+			Args: []string{"--services=m1"},
+		},
+	}
+	f.createAndWaitForLoaded(&tf)
+
+	var button v1alpha1.UIButton
+	f.MustGet(types.NamespacedName{Name: uibutton.ArgsButtonName("my-tf")}, &button)
+	require.Len(t, button.Spec.Inputs, 1)
+	assert.Equal(t, "--services=m1", button.Spec.Inputs[0].Text.DefaultValue)
+
+	ts := time.Now()
+	button.Status.LastClickedAt = metav1.NowMicro()
+	button.Status.Inputs = []v1alpha1.UIInputStatus{
+		{
+			Name: uibutton.ArgsInputName,
+			Text: &v1alpha1.UITextInputStatus{Value: "--services=m1 'two words'"},
+		},
+	}
+	f.UpdateStatus(&button)
+
+	f.MustReconcile(types.NamespacedName{Name: "my-tf"})
+	f.waitForRunning("my-tf")
+	f.popQueue()
+	f.waitForTerminatedAfter("my-tf", ts)
+
+	f.MustGet(types.NamespacedName{Name: "my-tf"}, &tf)
+	assert.Equal(t, []string{"--services=m1", "two words"}, tf.Spec.Args)
+
+	var started ConfigsReloadStartedAction
+	for _, a := range f.st.Actions() {
+		if action, ok := a.(ConfigsReloadStartedAction); ok {
+			started = action
+		}
+	}
+	assert.Equal(t, &model.TiltfileArgsChange{
+		From: []string{"--services=m1"},
+		To:   []string{"--services=m1", "two words"},
+	}, started.ArgsChange)
+	assert.False(t, f.lastReloadedAction().ArgsReverted)
+
+	f.MustGet(types.NamespacedName{Name: uibutton.ArgsButtonName("my-tf")}, &button)
+	assert.Equal(t, "--services=m1 'two words'", button.Spec.Inputs[0].Text.DefaultValue)
+}
+
 func TestRunWithoutArgsChangePreservesEnabledResources(t *testing.T) {
 	f := newFixture(t)
 	p := f.tempdir.JoinPath("Tiltfile")
@@ -577,6 +688,19 @@ func (f *fixture) setArgs(name string, args []string) {
 	require.NoError(f.T(), err)
 }
 
+func (f *fixture) lastReloadedAction() ConfigsReloadedAction {
+	var result ConfigsReloadedAction
+	found := false
+	for _, a := range f.st.Actions() {
+		if action, ok := a.(ConfigsReloadedAction); ok {
+			result = action
+			found = true
+		}
+	}
+	require.True(f.T(), found, "no ConfigsReloadedAction")
+	return result
+}
+
 func (f *fixture) requireEnabled(m model.Manifest, isEnabled bool) {
 	var cm v1alpha1.ConfigMap
 	f.MustGet(types.NamespacedName{Name: disableConfigMapName(m)}, &cm)
@@ -2,7 +2,12 @@ package tiltfile
 
 import (
 	"context"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -22,10 +27,12 @@ func HandleConfigsReloadStarted(
 	}
 
 	status := model.BuildRecord{
-		StartTime: event.StartTime,
-		Reason:    event.Reason,
-		Edits:     event.FilesChanged,
-		SpanID:    event.SpanID,
+		StartTime:  event.StartTime,
+		Reason:     event.Reason,
+		Edits:      event.FilesChanged,
+		SpanID:     event.SpanID,
+		Triggers:   ms.PendingTriggers,
+		ArgsChange: event.ArgsChange,
 	}
 	ms.CurrentBuilds[TiltfileBuildSource] = status
 	state.RemoveFromTriggerQueue(event.Name)
@@ -103,6 +110,12 @@ func HandleConfigsReloaded(
 		b.FinishTime = event.FinishTime
 		b.Error = event.Err
 
+		if event.ArgsReverted && b.ArgsChange != nil {
+			change := *b.ArgsChange
+			change.Reverted = true
+			b.ArgsChange = &change
+		}
+
 		if b.SpanID != "" {
 			b.WarningCount = len(state.LogStore.Warnings(b.SpanID))
 		}
@@ -140,6 +153,8 @@ func HandleConfigsReloaded(
 	}
 
 	// Make sure all the new manifests are in the EngineState.
+	invalidated := map[model.ManifestName]bool{}
+	var changedCRDKinds []schema.GroupKind
 	for _, m := range manifests {
 		mt, ok := state.ManifestTargets[m.ManifestName()]
 		if ok && mt.Manifest.SourceTiltfile != event.Name {
@@ -147,6 +162,15 @@ func HandleConfigsReloaded(
 			continue
 		}
 
+		oldYAML := ""
+		if ok && mt.Manifest.IsK8s() {
+			oldYAML = mt.Manifest.K8sTarget().YAML
+		}
+		if m.IsK8s() {
+			changedCRDKinds = append(changedCRDKinds,
+				k8s.ChangedCRDKinds(oldYAML, m.K8sTarget().YAML)...)
+		}
+
 		// Create a new manifest if it changed types.
 		createNew := !ok ||
 			mt.Manifest.IsK8s() != m.IsK8s() ||
@@ -154,6 +178,7 @@ func HandleConfigsReloaded(
 			mt.Manifest.IsDC() != m.IsDC()
 		if createNew {
 			mt = store.NewManifestTarget(m)
+			invalidated[m.Name] = true
 		}
 
 		configFilesThatChanged := ms.LastBuild().Edits
@@ -167,10 +192,16 @@ func HandleConfigsReloaded(
 			ms.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
 			ms.PendingManifestChange = event.FinishTime
 			ms.ConfigFilesThatCausedChange = configFilesThatChanged
+			invalidated[m.Name] = true
 		}
 		state.UpsertManifestTarget(mt)
 	}
 
+	if len(changedCRDKinds) > 0 {
+		reapplyCustomResources(ctx, state, changedCRDKinds, invalidated,
+			event.FinishTime, ms.LastBuild().Edits)
+	}
+
 	// Go through all the existing manifest targets. If they were from this
 	// Tiltfile, but were removed from the latest Tiltfile execution, delete them.
 	for _, mt := range state.Targets() {
@@ -184,6 +215,21 @@ func HandleConfigsReloaded(
 		}
 	}
 
+	// Replace the resource groups from this Tiltfile.
+	for name, g := range state.ResourceGroups {
+		if g.SourceTiltfile == event.Name {
+			delete(state.ResourceGroups, name)
+		}
+	}
+	for _, g := range event.ResourceGroups {
+		if existing, ok := state.ResourceGroups[g.Name]; ok {
+			logger.Get(ctx).Errorf("Resource group defined in two tiltfiles: %s, %s", event.Name, existing.SourceTiltfile)
+			continue
+		}
+		g.SourceTiltfile = event.Name
+		state.ResourceGroups[g.Name] = g
+	}
+
 	state.TiltfileConfigPaths[event.Name] = event.ConfigFiles
 
 	// Global state that's only configurable from the main manifest.
@@ -196,3 +242,52 @@ func HandleConfigsReloaded(
 		state.DockerPruneSettings = event.DockerPruneSettings
 	}
 }
+
+// When a Tiltfile adds or changes a CRD, the custom resources of its kind
+// need to be re-applied, so that the apiserver validates them against the new
+// schema (or accepts them at all, if they failed with "no matches for kind").
+//
+// Resources that this reload already invalidated will be re-applied anyway.
+func reapplyCustomResources(
+	ctx context.Context,
+	state *store.EngineState,
+	kinds []schema.GroupKind,
+	invalidated map[model.ManifestName]bool,
+	changeTime time.Time,
+	configFilesThatChanged []string,
+) {
+	isChangedKind := make(map[schema.GroupKind]bool, len(kinds))
+	for _, gk := range kinds {
+		isChangedKind[gk] = true
+	}
+
+	for _, mt := range state.Targets() {
+		m := mt.Manifest
+		if invalidated[m.Name] || !m.IsK8s() {
+			continue
+		}
+
+		entities, err := k8s.ParseYAMLFromString(m.K8sTarget().YAML)
+		if err != nil {
+			continue
+		}
+
+		var crKinds []string
+		for _, e := range entities {
+			gk := e.GVK().GroupKind()
+			if isChangedKind[gk] {
+				crKinds = sliceutils.AppendWithoutDupes(crKinds, gk.String())
+			}
+		}
+		if len(crKinds) == 0 {
+			continue
+		}
+
+		logger.Get(ctx).Infof("Re-applying %s: the CustomResourceDefinition for %s changed",
+			m.Name, strings.Join(crKinds, ", "))
+		ms := mt.State
+		ms.BuildStatuses = make(map[model.TargetID]*store.BuildStatus)
+		ms.PendingManifestChange = changeTime
+		ms.ConfigFilesThatCausedChange = configFilesThatChanged
+	}
+}
@@ -2,11 +2,17 @@ package tiltfile
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -84,3 +90,83 @@ func TestManifestOrder(t *testing.T) {
 		[]model.ManifestName{"b", "extra-x", "d", "extra-omega", "a", "c"},
 		state.ManifestDefinitionOrder)
 }
+
+func TestCRDChangeReappliesCustomResources(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout))
+	state := store.NewState()
+	tf := model.MainTiltfileManifestName
+
+	crdYAML, crYAML, ok := strings.Cut(testyaml.CRDYAML, "---")
+	require.True(t, ok)
+	manifests := func(crdYAML string) []model.Manifest {
+		return []model.Manifest{
+			model.Manifest{Name: "crd"}.WithDeployTarget(k8s.MustTarget("crd", crdYAML)),
+			model.Manifest{Name: "cr"}.WithDeployTarget(k8s.MustTarget("cr", crYAML)),
+			model.Manifest{Name: "other"}.WithDeployTarget(k8s.MustTarget("other", testyaml.SanchoYAML)),
+		}
+	}
+
+	HandleConfigsReloaded(ctx, state, ConfigsReloadedAction{
+		Name:      tf,
+		Manifests: manifests(crdYAML),
+	})
+	for _, mt := range state.Targets() {
+		mt.State.PendingManifestChange = time.Time{}
+	}
+
+	// Reloading without changes to the CRD doesn't re-apply anything.
+	HandleConfigsReloaded(ctx, state, ConfigsReloadedAction{
+		Name:       tf,
+		Manifests:  manifests(crdYAML),
+		FinishTime: time.Now(),
+	})
+	for _, mt := range state.Targets() {
+		assert.True(t, mt.State.PendingManifestChange.IsZero(), mt.Manifest.Name)
+	}
+
+	// Changing the CRD's schema re-applies the custom resources of its kind.
+	finishTime := time.Now()
+	HandleConfigsReloaded(ctx, state, ConfigsReloadedAction{
+		Name:       tf,
+		Manifests:  manifests(strings.Replace(crdYAML, "minimum: 1", "minimum: 2", 1)),
+		FinishTime: finishTime,
+	})
+	pending := func(name model.ManifestName) time.Time {
+		mt, ok := state.ManifestTargets[name]
+		require.True(t, ok)
+		return mt.State.PendingManifestChange
+	}
+	assert.Equal(t, finishTime, pending("crd"))
+	assert.Equal(t, finishTime, pending("cr"))
+	assert.True(t, pending("other").IsZero())
+}
+
+func TestArgsChangeRecordedOnBuild(t *testing.T) {
+	ctx := logger.WithLogger(context.Background(), logger.NewTestLogger(os.Stdout))
+	state := store.NewState()
+	tf := model.MainTiltfileManifestName
+
+	change := &model.TiltfileArgsChange{
+		From: []string{"--services=api"},
+		To:   []string{"--services=bogus"},
+	}
+	HandleConfigsReloadStarted(ctx, state, ConfigsReloadStartedAction{
+		Name:       tf,
+		StartTime:  time.Now(),
+		Reason:     model.BuildReasonFlagTiltfileArgs,
+		ArgsChange: change,
+	})
+	HandleConfigsReloaded(ctx, state, ConfigsReloadedAction{
+		Name:         tf,
+		FinishTime:   time.Now(),
+		Err:          fmt.Errorf("bogus not found"),
+		ArgsReverted: true,
+	})
+
+	b := state.TiltfileStates[tf].LastBuild()
+	require.NotNil(t, b.ArgsChange)
+	assert.Equal(t, change.From, b.ArgsChange.From)
+	assert.Equal(t, change.To, b.ArgsChange.To)
+	assert.True(t, b.ArgsChange.Reverted)
+	assert.False(t, change.Reverted, "the started action should not be mutated")
+}
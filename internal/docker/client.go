@@ -544,6 +544,7 @@ func (c *Cli) ImageBuild(ctx context.Context, g *errgroup.Group, buildContext io
 	opts.PullParent = options.PullParent
 	opts.Platform = options.Platform
 	opts.ExtraHosts = append([]string{}, options.ExtraHosts...)
+	opts.NoCache = options.NoCache
 
 	if options.DirSource != nil {
 		opts.RemoteContext = clientSessionRemote
@@ -603,6 +604,18 @@ func (c *Cli) ExecInContainer(ctx context.Context, cID container.ID, cmd model.C
 	}
 	defer connection.Close()
 
+	// The attached connection doesn't watch the context, so close it when
+	// the context is done (e.g., the command timed out).
+	execDone := make(chan struct{})
+	defer close(execDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			connection.Close()
+		case <-execDone:
+		}
+	}()
+
 	err = c.ContainerExecStart(ctx, execId.ID, types.ExecStartCheck{})
 	if err != nil {
 		return errors.Wrap(err, "ExecInContainer#start")
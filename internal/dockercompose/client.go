@@ -20,6 +20,7 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 
@@ -51,9 +52,10 @@ type DockerComposeClient interface {
 }
 
 type cmdDCClient struct {
-	env     docker.Env
-	mu      *sync.Mutex
-	initCmd *sync.Once
+	env        docker.Env
+	supervisor *localexec.Supervisor
+	mu         *sync.Mutex
+	initCmd    *sync.Once
 
 	composeCmd []string
 	version    string
@@ -63,11 +65,12 @@ type cmdDCClient struct {
 
 // TODO(dmiller): we might want to make this take a path to the docker-compose config so we don't
 // have to keep passing it in.
-func NewDockerComposeClient(lenv docker.LocalEnv) DockerComposeClient {
+func NewDockerComposeClient(lenv docker.LocalEnv, localEnv *localexec.Env) DockerComposeClient {
 	return &cmdDCClient{
-		env:     docker.Env(lenv),
-		mu:      &sync.Mutex{},
-		initCmd: &sync.Once{},
+		env:        docker.Env(lenv),
+		supervisor: localEnv.Supervisor(),
+		mu:         &sync.Mutex{},
+		initCmd:    &sync.Once{},
 	}
 }
 
@@ -112,7 +115,7 @@ func (c *cmdDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeService
 		cmd.Stdin = strings.NewReader(spec.Project.YAML)
 		cmd.Stdout = stdout
 		cmd.Stderr = stderr
-		err := cmd.Run()
+		err := c.supervisor.Run(cmd)
 		if err != nil {
 			return FormatError(cmd, nil, err)
 		}
@@ -134,7 +137,7 @@ func (c *cmdDCClient) Up(ctx context.Context, spec v1alpha1.DockerComposeService
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	return FormatError(cmd, nil, cmd.Run())
+	return FormatError(cmd, nil, c.supervisor.Run(cmd))
 }
 
 func (c *cmdDCClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject, stdout, stderr io.Writer) error {
@@ -154,7 +157,7 @@ func (c *cmdDCClient) Down(ctx context.Context, p v1alpha1.DockerComposeProject,
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err := c.supervisor.Run(cmd)
 	if err != nil {
 		return FormatError(cmd, nil, err)
 	}
@@ -195,7 +198,7 @@ func (c *cmdDCClient) Rm(ctx context.Context, specs []v1alpha1.DockerComposeServ
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	err := cmd.Run()
+	err := c.supervisor.Run(cmd)
 	if err != nil {
 		return FormatError(cmd, nil, err)
 	}
@@ -215,7 +218,7 @@ func (c *cmdDCClient) StreamEvents(ctx context.Context, p v1alpha1.DockerCompose
 		return ch, errors.Wrap(err, "making stdout pipe for `docker-compose events`")
 	}
 
-	err = cmd.Start()
+	err = c.supervisor.Start(cmd)
 	if err != nil {
 		return ch, errors.Wrapf(err, "`docker-compose %s`",
 			strings.Join(args, " "))
@@ -231,6 +234,7 @@ func (c *cmdDCClient) StreamEvents(ctx context.Context, p v1alpha1.DockerCompose
 		}
 
 		err = cmd.Wait()
+		c.supervisor.Done(cmd)
 		if err != nil {
 			logger.Get(ctx).Infof("[DOCKER-COMPOSE WATCHER] exited with error: %v", err)
 		}
@@ -341,12 +345,12 @@ func dcLoaderOption(name string) func(opts *loader.Options) {
 	}
 }
 
-func dcExecutableVersion(environ []string) ([]string, string, string, error) {
+func dcExecutableVersion(supervisor *localexec.Supervisor, environ []string) ([]string, string, string, error) {
 	execVersion := func(names []string) (string, string, error) {
 		args := append(names, "version")
 		cmd := exec.Command(args[0], args[1:]...)
 		cmd.Env = append(os.Environ(), environ...)
-		stdout, err := cmd.Output()
+		stdout, err := supervisor.Output(cmd)
 		if err != nil {
 			return "", "", FormatError(cmd, stdout, err)
 		}
@@ -373,7 +377,7 @@ func dcExecutableVersion(environ []string) ([]string, string, string, error) {
 
 func (c *cmdDCClient) initDcCommand() {
 	c.initCmd.Do(func() {
-		cmd, version, build, err := dcExecutableVersion(c.env.AsEnviron())
+		cmd, version, build, err := dcExecutableVersion(c.supervisor, c.env.AsEnviron())
 		c.composeCmd = cmd
 		c.version = version
 		c.build = build
@@ -400,7 +404,7 @@ func (c *cmdDCClient) dcOutput(ctx context.Context, p v1alpha1.DockerComposeProj
 	cmd := c.dcCommand(ctx, args)
 	cmd.Stdin = strings.NewReader(p.YAML)
 
-	output, err := cmd.Output()
+	output, err := c.supervisor.Output(cmd)
 	if err != nil {
 		errorMessage := fmt.Sprintf("command %q failed.\nerror: %v\nstdout: %q", cmd.Args, err, string(output))
 		if err, ok := err.(*exec.ExitError); ok {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/docker"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/testutils"
 	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -151,7 +152,7 @@ func newDCFixture(t testing.TB) *dcFixture {
 	return &dcFixture{
 		t:      t,
 		ctx:    ctx,
-		cli:    NewDockerComposeClient(docker.LocalEnv{}),
+		cli:    NewDockerComposeClient(docker.LocalEnv{}, localexec.EmptyEnv()),
 		tmpdir: tmpdir,
 	}
 }
@@ -20,9 +20,25 @@ import (
 type AST struct {
 	directives []*parser.Directive
 	result     *parser.Result
+
+	// The directive lines at the top of the Dockerfile, for Print.
+	header []headerLine
+
+	// The original Dockerfile, for checks that need the raw source lines,
+	// without a byte order mark.
+	source Dockerfile
+
+	// Whether the Dockerfile started with a byte order mark, so that Print
+	// keeps it.
+	bom bool
+
+	// Incremented whenever an instruction is added or removed, to
+	// invalidate InstructionHandles.
+	generation int
 }
 
 func ParseAST(df Dockerfile) (AST, error) {
+	df, bom := stripBOM(df)
 	result, err := parser.Parse(newReader(df))
 	if err != nil {
 		return AST{}, errors.Wrap(err, "dockerfile.ParseAST")
@@ -37,6 +53,9 @@ func ParseAST(df Dockerfile) (AST, error) {
 	return AST{
 		directives: directives,
 		result:     result,
+		header:     parseHeader(df, directives),
+		source:     df,
+		bom:        bom,
 	}, nil
 }
 
@@ -69,13 +88,31 @@ func (a AST) extractBaseNameInFromCommand(node *parser.Node, shlex *shell.Lex, m
 
 // Find all images referenced in this dockerfile and call the visitor function.
 // If the visitor function returns a new image, substitute that image into the dockerfile.
+//
+// Images are referenced by FROM, COPY --from, and the from= of a bind or
+// cache mount on a RUN.
+//
+// Only ARGs before the first FROM are used to expand image names, and
+// references to earlier stages (by index, or by name, case-insensitively
+// like BuildKit) aren't images, so the visitor doesn't see them, even if
+// there's an image with the same name.
 func (a AST) traverseImageRefs(visitor func(node *parser.Node, ref reference.Named) reference.Named, dockerfileArgs []instructions.ArgCommand) error {
 	metaArgs := append([]instructions.ArgCommand(nil), dockerfileArgs...)
 	shlex := shell.NewLex(a.result.EscapeToken)
 
-	return a.Traverse(func(node *parser.Node) error {
+	byName := map[string]int{}
+	isStageRef := func(name string, ctx NodeContext) bool {
+		_, ok := resolveStageRef(name, byName, ctx.Stage)
+		return ok
+	}
+
+	return a.TraverseWithContext(func(node *parser.Node, ctx NodeContext) error {
 		switch strings.ToLower(node.Value) {
 		case command.Arg:
+			if ctx.Stage != -1 {
+				return nil // an ARG inside a stage is scoped to that stage
+			}
+
 			inst, err := instructions.ParseInstruction(node)
 			if err != nil {
 				return nil // ignore parsing error
@@ -90,8 +127,12 @@ func (a AST) traverseImageRefs(visitor func(node *parser.Node, ref reference.Nam
 			metaArgs = append([]instructions.ArgCommand{*argCmd}, metaArgs...)
 
 		case command.From:
+			if ctx.StageName != "" {
+				byName[strings.ToLower(ctx.StageName)] = ctx.Stage
+			}
+
 			baseName := a.extractBaseNameInFromCommand(node, shlex, metaArgs)
-			if baseName == "" {
+			if baseName == "" || isStageRef(baseName, ctx) {
 				return nil // ignore parsing error
 			}
 
@@ -115,7 +156,7 @@ func (a AST) traverseImageRefs(visitor func(node *parser.Node, ref reference.Nam
 			}
 
 			copyCmd, ok := inst.(*instructions.CopyCommand)
-			if !ok {
+			if !ok || isStageRef(copyCmd.From, ctx) {
 				return nil
 			}
 
@@ -132,22 +173,151 @@ func (a AST) traverseImageRefs(visitor func(node *parser.Node, ref reference.Nam
 					}
 				}
 			}
+
+		case command.Run:
+			for i, flag := range node.Flags {
+				mount, ok := parseMountFlag(flag)
+				if !ok {
+					continue
+				}
+
+				from, ok := mount.imageFrom()
+				if !ok {
+					continue
+				}
+
+				argsMap := fakeArgsMap(shlex, metaArgs)
+				expanded, err := shlex.ProcessWordWithMap(from, argsMap)
+				if err == nil {
+					from = expanded
+				}
+				if from == "" || isStageRef(from, ctx) {
+					continue
+				}
+
+				ref, err := container.ParseNamed(from)
+				if err != nil {
+					continue // drop the error, we don't care about malformed images
+				}
+
+				newRef := visitor(node, ref)
+				if newRef != nil {
+					node.Flags[i] = mount.withFrom(container.FamiliarString(newRef))
+				}
+			}
 		}
 
 		return nil
 	})
 }
 
+// A `RUN --mount=...` flag, split into its comma-separated key=value
+// fields as written, so that one field can be replaced without touching
+// the others.
+type mountFlag struct {
+	prefix string
+	fields []string
+}
+
+func parseMountFlag(flag string) (mountFlag, bool) {
+	const prefix = "--mount="
+	if len(flag) < len(prefix) || !strings.EqualFold(flag[:len(prefix)], prefix) {
+		return mountFlag{}, false
+	}
+
+	// Like buildkit, the fields are CSV, so a comma in quotes doesn't
+	// separate fields.
+	var fields []string
+	value := flag[len(prefix):]
+	start := 0
+	quoted := false
+	for i, c := range value {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				fields = append(fields, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	fields = append(fields, value[start:])
+	return mountFlag{prefix: flag[:len(prefix)], fields: fields}, true
+}
+
+// The value of the field with the given key, and its index.
+func (m mountFlag) field(key string) (string, int) {
+	for i, f := range m.fields {
+		k, v, ok := strings.Cut(f, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.Trim(v, `"`), i
+		}
+	}
+	return "", -1
+}
+
+// The image (or stage) that a bind or cache mount is from, if any. Other
+// types of mounts can't be from an image.
+func (m mountFlag) imageFrom() (string, bool) {
+	mountType, _ := m.field("type")
+	switch strings.ToLower(mountType) {
+	case "", instructions.MountTypeBind, instructions.MountTypeCache:
+	default:
+		return "", false
+	}
+
+	from, i := m.field("from")
+	return from, i != -1 && from != ""
+}
+
+// The flag as written, with the from= field replaced.
+func (m mountFlag) withFrom(from string) string {
+	_, i := m.field("from")
+	fields := append([]string(nil), m.fields...)
+	k, _, _ := strings.Cut(fields[i], "=")
+	fields[i] = fmt.Sprintf("%s=%s", k, from)
+	return m.prefix + strings.Join(fields, ",")
+}
+
 func (a AST) InjectImageDigest(selector container.RefSelector, ref reference.NamedTagged, buildArgs []string) (bool, error) {
-	modified := false
+	subs, err := a.InjectImageDigestReport(selector, ref, buildArgs)
+	return len(subs) > 0, err
+}
+
+// An instruction that image injection rewrote.
+type Substitution struct {
+	Line int `json:"line"`
+
+	// The instruction, as printed before and after the injection.
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// InjectImageDigestReport is like InjectImageDigest, but reports each
+// instruction that it rewrote, in order.
+func (a AST) InjectImageDigestReport(selector container.RefSelector, ref reference.NamedTagged, buildArgs []string) ([]Substitution, error) {
+	var nodes []*parser.Node
+	var result []Substitution
 	err := a.traverseImageRefs(func(node *parser.Node, toReplace reference.Named) reference.Named {
 		if selector.Matches(toReplace) {
-			modified = true
+			// A RUN with several mounts of the image is one substitution.
+			if len(nodes) == 0 || nodes[len(nodes)-1] != node {
+				nodes = append(nodes, node)
+				result = append(result, Substitution{Line: node.StartLine, Before: fmtNode(node)})
+			}
 			return ref
 		}
 		return nil
 	}, argInstructions(buildArgs))
-	return modified, err
+	if err != nil {
+		return nil, err
+	}
+
+	for i, node := range nodes {
+		result[i].After = fmtNode(node)
+	}
+	return result, nil
 }
 
 // Post-order traversal of the Dockerfile AST.
@@ -166,13 +336,83 @@ func (a AST) traverseNode(node *parser.Node, visit func(*parser.Node) error) err
 	return visit(node)
 }
 
+// Where a node is in the Dockerfile, for TraverseWithContext.
+type NodeContext struct {
+	// The index of the enclosing stage, or -1 before the first FROM.
+	Stage int
+
+	// The name of the enclosing stage (from `FROM image AS name`),
+	// lowercased, if any.
+	StageName string
+
+	// The index of the instruction within its stage. A FROM is 0.
+	// Instructions before the first FROM are numbered from 0 too. A
+	// nested node has its index among its parent's children.
+	Ordinal int
+
+	// The node's parent, or nil for the root.
+	Parent *parser.Node
+}
+
+// TraverseWithContext is like Traverse, but also tells the visitor which
+// stage each node is in, where it is in the stage, and its parent.
+//
+// Like Traverse, the root is visited last, outside of any stage.
+func (a AST) TraverseWithContext(visit func(*parser.Node, NodeContext) error) error {
+	root := a.result.AST
+	stage := NodeContext{Stage: -1, Ordinal: -1}
+	for _, node := range root.Children {
+		if strings.EqualFold(node.Value, command.From) {
+			stage = NodeContext{Stage: stage.Stage + 1, Ordinal: -1}
+			inst, err := instructions.ParseInstruction(node)
+			if err == nil {
+				if s, ok := inst.(*instructions.Stage); ok {
+					stage.StageName = strings.ToLower(s.Name)
+				}
+			}
+		}
+		stage.Ordinal++
+
+		err := a.traverseNodeWithContext(node, NodeContext{
+			Stage:     stage.Stage,
+			StageName: stage.StageName,
+			Ordinal:   stage.Ordinal,
+			Parent:    root,
+		}, visit)
+		if err != nil {
+			return err
+		}
+	}
+	return visit(root, NodeContext{Stage: -1})
+}
+
+// traverseNodeWithContext visits the node's children, in the same stage
+// as the node, and then the node.
+func (a AST) traverseNodeWithContext(node *parser.Node, ctx NodeContext, visit func(*parser.Node, NodeContext) error) error {
+	for i, c := range node.Children {
+		err := a.traverseNodeWithContext(c, NodeContext{
+			Stage:     ctx.Stage,
+			StageName: ctx.StageName,
+			Ordinal:   i,
+			Parent:    node,
+		}, visit)
+		if err != nil {
+			return err
+		}
+	}
+	return visit(node, ctx)
+}
+
 func (a AST) Print() (Dockerfile, error) {
 	buf := bytes.NewBuffer(nil)
 	currentLine := 1
 
-	directiveFmt := "# %s = %s\n"
-	for _, v := range a.directives {
-		_, err := fmt.Fprintf(buf, directiveFmt, v.Name, v.Value)
+	if a.bom {
+		buf.WriteString(utf8BOM)
+	}
+
+	for _, h := range a.header {
+		_, err := fmt.Fprintln(buf, h.String())
 		if err != nil {
 			return "", err
 		}
@@ -195,32 +435,98 @@ func (a AST) Print() (Dockerfile, error) {
 
 		currentLine = node.StartLine + lineCount
 	}
-	return Dockerfile(buf.String()), nil
+	return Dockerfile(decodeRawBytes(buf.String())), nil
+}
+
+// removeNode removes the top-level instruction at index i, and moves the
+// instructions after it up so that Print doesn't leave a hole. Of the blank
+// lines around the instruction, the larger run is kept.
+func (a *AST) removeNode(i int) {
+	children := a.result.AST.Children
+	node := children[i]
+	if i+1 < len(children) {
+		gapBefore := node.StartLine - 1
+		if i > 0 {
+			gapBefore = node.StartLine - children[i-1].EndLine - 1
+		}
+		gapAfter := children[i+1].StartLine - node.EndLine - 1
+
+		shift := node.EndLine - node.StartLine + 1
+		if gapBefore < gapAfter {
+			shift += gapBefore
+		} else {
+			shift += gapAfter
+		}
+		for _, n := range children[i+1:] {
+			n.StartLine -= shift
+			n.EndLine -= shift
+		}
+	}
+	a.result.AST.Children = append(children[:i], children[i+1:]...)
+	a.generation++
+}
+
+// insertNode adds a parsed instruction at index i, on the line after the
+// instruction before it, and moves the instructions after it down.
+func (a *AST) insertNode(i int, node *parser.Node) {
+	children := a.result.AST.Children
+	start := 1
+	if i > 0 {
+		start = children[i-1].EndLine + 1
+	} else if len(a.header) > 0 {
+		start = len(a.header) + 1
+	}
+
+	lines := node.EndLine - node.StartLine + 1
+	node.StartLine = start
+	node.EndLine = start + lines - 1
+	for _, n := range children[i:] {
+		n.StartLine += lines
+		n.EndLine += lines
+	}
+
+	children = append(children, nil)
+	copy(children[i+1:], children[i:])
+	children[i] = node
+	a.result.AST.Children = children
+	a.generation++
 }
 
 // Loosely adapted from
 // https://github.com/jessfraz/dockfmt/blob/master/format.go
 // Returns the number of lines printed.
 func (a AST) printNode(node *parser.Node, writer io.Writer) (int, error) {
-	var v string
+	v := fmtNode(node)
+	_, err := fmt.Fprintln(writer, v)
+	if err != nil {
+		return 0, err
+	}
+	return strings.Count(v, "\n") + 1, nil
+}
 
+func fmtNode(node *parser.Node) string {
 	// format per directive
 	switch strings.ToLower(node.Value) {
 	// all the commands that use parseMaybeJSON
 	// https://github.com/moby/buildkit/blob/2ec7d53b00f24624cda0adfbdceed982623a93b3/frontend/dockerfile/parser/parser.go#L152
 	case command.Cmd, command.Entrypoint, command.Run, command.Shell:
-		v = fmtCmd(node)
-	case command.Label:
-		v = fmtLabel(node)
+		return fmtCmd(node)
+	case command.Env, command.Label:
+		return fmtKeyValues(node)
+	case command.Onbuild:
+		return fmtOnbuild(node)
 	default:
-		v = fmtDefault(node)
+		return fmtDefault(node)
 	}
+}
 
-	_, err := fmt.Fprintln(writer, v)
-	if err != nil {
-		return 0, err
+// The instruction after ONBUILD is parsed into a child node, rather than
+// args.
+func fmtOnbuild(node *parser.Node) string {
+	if node.Next == nil || len(node.Next.Children) == 0 {
+		return fmtDefault(node)
 	}
-	return strings.Count(v, "\n") + 1, nil
+	return fmt.Sprintf("%s %s", strings.ToUpper(node.Value), fmtNode(node.Next.Children[0]))
 }
 
 func getCmd(n *parser.Node) []string {
@@ -286,8 +592,15 @@ func fmtDefault(node *parser.Node) string {
 	return appendHeredocs(node, strings.Join(cmd, " "))
 }
 
-func fmtLabel(node *parser.Node) string {
+// ENV and LABEL are parsed into alternating key and value nodes.
+func fmtKeyValues(node *parser.Node) string {
 	cmd := getCmd(node)
+	if isLegacyKeyValue(node) && len(cmd) == 3 {
+		// In the legacy form, `ENV key some value`, the value is the rest of
+		// the line, and can't be printed as key=value without quoting.
+		return strings.Join(cmd, " ")
+	}
+
 	assignments := []string{cmd[0]}
 	for i := 1; i < len(cmd); i += 2 {
 		if i+1 < len(cmd) {
@@ -299,8 +612,15 @@ func fmtLabel(node *parser.Node) string {
 	return strings.Join(assignments, " ")
 }
 
+// Whether an ENV or LABEL uses the legacy `ENV key value` form rather than
+// `ENV key=value ...`. Like the parser, this checks the first word.
+func isLegacyKeyValue(node *parser.Node) bool {
+	fields := strings.Fields(node.Original)
+	return len(fields) > 1 && !strings.Contains(fields[1], "=")
+}
+
 func newReader(df Dockerfile) io.Reader {
-	return bytes.NewBufferString(string(df))
+	return bytes.NewBufferString(encodeRawBytes(string(df)))
 }
 
 // Loosely adapted from the buildkit code for turning args into a map.
@@ -1,9 +1,12 @@
 package dockerfile
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrintBasicAST(t *testing.T) {
@@ -81,6 +84,15 @@ LABEL description="This text illustrates that label-values can span multiple lin
 `)
 }
 
+func TestPrintEnv(t *testing.T) {
+	assertPrintSame(t, `
+ENV A=1 B=2
+ENV GREETING="hello world"
+ENV LEGACY some value
+LABEL legacy some value
+`)
+}
+
 func TestPrintCopyFlags(t *testing.T) {
 	assertPrintSame(t, `
 FROM golang:10
@@ -107,22 +119,76 @@ RUN echo bye
 }
 
 func TestMultipleDirectivesOrderDeterministic(t *testing.T) {
-	orig := `# syntax = dockerfile:1
+	assertPrintSame(t, `# syntax = dockerfile:1
 # escape = \
 # unknown = foo
 
 FROM golang:10
-`
-	// known directives should be preserved
-	// unknown directives should be dropped
-	expected := `# syntax = dockerfile:1
-# escape = \
+`)
+}
 
+func TestPrintDirectivesVerbatim(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		df   string
+	}{
+		{"syntax", "# syntax=docker/dockerfile:1.4\nFROM golang:10\n"},
+		{"syntax with spaces", "#   syntax =  docker/dockerfile:1\n\nFROM golang:10\n"},
+		{"escape", "# escape=`\n\nFROM golang:10\nRUN echo hi\n"},
+		{"check", "# syntax=docker/dockerfile:1\n# check=skip=JSONArgsRecommended;error=true\nFROM golang:10\n"},
+		{"unknown", "# futuredirective=a = b\nFROM golang:10\n"},
+		{"shebang", "#!/usr/bin/env -S docker build . -f\n# syntax=docker/dockerfile:1\nFROM golang:10\n"},
+		{"crlf", "# syntax=docker/dockerfile:1\r\nFROM golang:10\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			expected := strings.ReplaceAll(tc.df, "\r\n", "\n")
+			assertPrint(t, tc.df, expected)
+		})
+	}
+}
 
-FROM golang:10
-`
+func TestPrintSynthesizedDirective(t *testing.T) {
+	ast, err := ParseAST("FROM golang:10\n")
+	require.NoError(t, err)
 
-	assertPrint(t, orig, expected)
+	ast.header = append(ast.header, headerLine{directive: &parser.Directive{Name: "syntax", Value: "docker/dockerfile:1"}})
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, "# syntax = docker/dockerfile:1\nFROM golang:10\n", string(df))
+}
+
+func TestTraverseWithContext(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=alpine
+FROM golang:1.21 AS Builder
+RUN go build ./...
+FROM $BASE
+COPY --from=builder /app /app
+CMD ["/app"]
+`))
+	require.NoError(t, err)
+
+	type visited struct {
+		value string
+		ctx   NodeContext
+	}
+	var actual []visited
+	err = ast.TraverseWithContext(func(node *parser.Node, ctx NodeContext) error {
+		actual = append(actual, visited{node.Value, ctx})
+		return nil
+	})
+	require.NoError(t, err)
+
+	root := ast.result.AST
+	assert.Equal(t, []visited{
+		{"ARG", NodeContext{Stage: -1, Ordinal: 0, Parent: root}},
+		{"FROM", NodeContext{Stage: 0, StageName: "builder", Ordinal: 0, Parent: root}},
+		{"RUN", NodeContext{Stage: 0, StageName: "builder", Ordinal: 1, Parent: root}},
+		{"FROM", NodeContext{Stage: 1, Ordinal: 0, Parent: root}},
+		{"COPY", NodeContext{Stage: 1, Ordinal: 1, Parent: root}},
+		{"CMD", NodeContext{Stage: 1, Ordinal: 2, Parent: root}},
+		{"", NodeContext{Stage: -1}},
+	}, actual)
 }
 
 // Convert the dockerfile into an AST, print it, and then
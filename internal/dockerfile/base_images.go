@@ -0,0 +1,36 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// ListBaseImages returns the image of each FROM, in order, with ARGs
+// expanded the same way that image injection expands them. Nothing in the
+// Dockerfile is changed.
+//
+// FROMs that refer to an earlier stage, FROM scratch, and images that
+// aren't valid refs are skipped. An image used by several FROMs is listed
+// once for each.
+func (a AST) ListBaseImages(buildArgs []string) ([]reference.Named, error) {
+	result := []reference.Named{}
+	err := a.traverseImageRefs(func(node *parser.Node, ref reference.Named) reference.Named {
+		if strings.EqualFold(node.Value, command.From) && !isScratch(ref) {
+			result = append(result, ref)
+		}
+		return nil
+	}, argInstructions(buildArgs))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// isScratch checks if the image of a FROM, after ARG expansion, is the
+// empty scratch image rather than an image that can be pulled.
+func isScratch(ref reference.Named) bool {
+	return reference.FamiliarString(ref) == "scratch"
+}
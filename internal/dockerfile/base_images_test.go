@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBaseImages(t *testing.T) {
+	df := Dockerfile(`
+ARG BASE=debian
+FROM golang:1.21 AS builder
+COPY --from=gcr.io/windmill/foo /src /src
+FROM builder AS test
+FROM ${BASE}
+FROM scratch
+FROM $MISSING:v1
+FROM gcr.io/distroless/static
+COPY --from=builder /app /app
+FROM golang:1.21
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	images, err := ast.ListBaseImages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"docker.io/library/golang:1.21",
+		"docker.io/library/debian",
+		"gcr.io/distroless/static",
+		"docker.io/library/golang:1.21",
+	}, refStrings(images))
+
+	images, err = ast.ListBaseImages([]string{"BASE=debian:12"})
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io/library/debian:12", images[1].String())
+
+	// Listing doesn't change the Dockerfile.
+	printed, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, df, printed)
+}
+
+func TestListBaseImagesScratchFromArg(t *testing.T) {
+	df := Dockerfile(`
+ARG BASE=scratch
+FROM $BASE
+FROM ${RUNTIME}
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	images, err := ast.ListBaseImages(nil)
+	require.NoError(t, err)
+	assert.Empty(t, images)
+
+	images, err = ast.ListBaseImages([]string{"RUNTIME=scratch"})
+	require.NoError(t, err)
+	assert.Empty(t, images)
+
+	images, err = ast.ListBaseImages([]string{"BASE=alpine"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"docker.io/library/alpine"}, refStrings(images))
+}
+
+func TestListBaseImagesEmpty(t *testing.T) {
+	ast, err := ParseAST(Dockerfile("ARG BASE\n"))
+	require.NoError(t, err)
+
+	images, err := ast.ListBaseImages(nil)
+	require.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func refStrings(refs []reference.Named) []string {
+	var result []string
+	for _, ref := range refs {
+		result = append(result, ref.String())
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A COPY --from a builder stage with a source so broad that it likely
+// copies the builder's toolchain and packages along with the artifacts.
+type LeakFinding struct {
+	// The line of the COPY.
+	Line int
+
+	// The builder stage copied from, by index and name (if any).
+	FromStage     int
+	FromStageName string
+
+	// The broad source path, cleaned and absolute (e.g., "/usr").
+	Source string
+}
+
+// Directories where package managers and toolchains install things. Copying
+// one of them from a builder stage drags those into the stage that copies it.
+var broadCopySources = map[string]bool{
+	"/": true, "/bin": true, "/sbin": true, "/lib": true, "/lib64": true,
+	"/usr": true, "/usr/bin": true, "/usr/sbin": true, "/usr/lib": true,
+	"/usr/lib64": true, "/usr/local": true, "/usr/local/bin": true,
+	"/usr/local/lib": true, "/usr/share": true, "/opt": true, "/var": true,
+	"/var/lib": true, "/var/cache": true, "/etc": true, "/root": true,
+	"/home": true, "/go": true, "/go/pkg": true,
+}
+
+// BuilderLeakage finds COPY --from instructions that copy a broad path
+// (like `/` or `/usr`) out of a builder stage, as classified by
+// ClassifyStages. A runtime stage should copy the builder's artifacts, not
+// the directories its build tools were installed into.
+//
+// Sources are resolved from the root of the stage copied from, so `.` is
+// `/`. A wildcard that copies all of a directory (e.g., `/usr/*`) counts as
+// the directory. COPY --from an image, rather than a stage, isn't checked.
+func (a AST) BuilderLeakage(buildArgs []string) ([]LeakFinding, error) {
+	kinds, err := a.ClassifyStages(buildArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The name of each stage, by index.
+	byName := map[string]int{}
+	names := map[int]string{}
+
+	var result []LeakFinding
+	err = a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			if inst.Name != "" {
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+				names[st.stageIndex] = inst.Name
+			}
+
+		case *instructions.CopyCommand:
+			from := st.vars.expand(inst.From)
+			if from == "" {
+				return nil
+			}
+
+			index, ok := resolveStageRef(from, byName, st.stageIndex)
+			if !ok || kinds[index].Role != StageBuilder {
+				return nil
+			}
+
+			for _, src := range inst.SourcePaths {
+				src, ok := broadCopySource(st.vars.expand(src))
+				if !ok {
+					continue
+				}
+				result = append(result, LeakFinding{
+					Line:          node.StartLine,
+					FromStage:     index,
+					FromStageName: names[index],
+					Source:        src,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// broadCopySource returns the absolute source path, and whether it's broad.
+func broadCopySource(src string) (string, bool) {
+	if src == "" {
+		return "", false
+	}
+	if path.Base(src) == "*" {
+		src = path.Dir(src)
+	}
+	src = path.Clean(path.Join("/", src))
+	return src, broadCopySources[src]
+}
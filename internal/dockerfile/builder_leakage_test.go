@@ -0,0 +1,62 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilderLeakage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS Builder
+RUN apt-get install -y gcc make
+RUN go build -o /out/app ./cmd/app
+
+FROM gcr.io/distroless/static AS base
+CMD ["/app"]
+
+FROM base
+ARG TOOLS=/usr/local
+COPY --from=builder /out/app /app
+COPY --from=builder / /
+COPY --from=builder . /builder
+COPY --from=0 $TOOLS/ /usr/local/
+COPY --from=builder /usr/* /usr/
+COPY --from=builder /usr/lib/libfoo.so /usr/lib/
+COPY --from=base / /base
+COPY --from=nginx:latest /etc /etc
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.BuilderLeakage(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []LeakFinding{
+		{Line: 12, FromStage: 0, FromStageName: "builder", Source: "/"},
+		{Line: 13, FromStage: 0, FromStageName: "builder", Source: "/"},
+		{Line: 14, FromStage: 0, FromStageName: "builder", Source: "/usr/local"},
+		{Line: 15, FromStage: 0, FromStageName: "builder", Source: "/usr"},
+	}, findings)
+}
+
+func TestBuilderLeakageBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:20 AS deps
+RUN npm ci
+
+FROM alpine
+ARG SRC=/app
+COPY --from=deps $SRC /app
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.BuilderLeakage(nil)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	findings, err = ast.BuilderLeakage([]string{"SRC=/usr"})
+	require.NoError(t, err)
+	assert.Equal(t, []LeakFinding{
+		{Line: 7, FromStage: 0, FromStageName: "deps", Source: "/usr"},
+	}, findings)
+}
@@ -0,0 +1,136 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A build step that re-runs when a file in the build context changes.
+type ImpactedStep struct {
+	Stage     int
+	StageName string
+	Line      int
+
+	// The instruction as written in the Dockerfile.
+	Original string
+
+	// True if this is the COPY or ADD that pulls in the changed file.
+	// Every other step re-runs because it comes after it.
+	Trigger bool
+}
+
+// StepCount returns the number of instructions in all build stages,
+// counting each FROM as a step.
+func (a AST) StepCount() int {
+	count := 0
+	inStage := false
+	for _, node := range a.result.AST.Children {
+		if strings.ToLower(node.Value) == command.From {
+			inStage = true
+		}
+		if inStage {
+			count++
+		}
+	}
+	return count
+}
+
+// CacheImpact returns the steps that re-run when the file at p
+// (a slash-separated path relative to the build context) changes.
+//
+// In each stage, the first COPY or ADD from the build context that
+// includes the path invalidates the cache, along with every step after
+// it in that stage.
+func (a AST) CacheImpact(p string) ([]ImpactedStep, error) {
+	p = path.Clean(strings.TrimPrefix(p, "./"))
+
+	var result []ImpactedStep
+	impactedStage := -1
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if st.stageIndex < 0 {
+			return nil
+		}
+
+		if impactedStage == st.stageIndex {
+			result = append(result, ImpactedStep{
+				Stage:     st.stageIndex,
+				StageName: st.stageName,
+				Line:      node.StartLine,
+				Original:  node.Original,
+			})
+			return nil
+		}
+
+		for _, src := range contextSources(inst) {
+			if contextSourceIncludes(st.vars.expand(src), p) {
+				impactedStage = st.stageIndex
+				result = append(result, ImpactedStep{
+					Stage:     st.stageIndex,
+					StageName: st.stageName,
+					Line:      node.StartLine,
+					Original:  node.Original,
+					Trigger:   true,
+				})
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// contextSources returns the build context paths that a COPY or ADD
+// reads from. Copies from other stages or images and remote ADDs
+// don't read from the context.
+func contextSources(inst interface{}) []string {
+	switch inst := inst.(type) {
+	case *instructions.CopyCommand:
+		if inst.From != "" {
+			return nil
+		}
+		return inst.SourcePaths
+	case *instructions.AddCommand:
+		var result []string
+		for _, src := range inst.SourcePaths {
+			if isRemoteSource(src) {
+				continue
+			}
+			result = append(result, src)
+		}
+		return result
+	}
+	return nil
+}
+
+func isRemoteSource(src string) bool {
+	return strings.HasPrefix(src, "http://") ||
+		strings.HasPrefix(src, "https://") ||
+		strings.HasPrefix(src, "git@")
+}
+
+// contextSourceIncludes checks whether a COPY/ADD source (which may be a
+// directory or a glob) includes the given context-relative file.
+func contextSourceIncludes(src, file string) bool {
+	src = path.Clean(strings.TrimPrefix(src, "/"))
+	if src == "." {
+		return true
+	}
+
+	// The source matches the file or one of its parent directories.
+	for f := file; f != "." && f != "/"; f = path.Dir(f) {
+		if f == src {
+			return true
+		}
+		if ok, err := path.Match(src, f); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
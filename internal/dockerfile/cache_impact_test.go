@@ -0,0 +1,71 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheImpact(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN go build -o /app ./cmd/api
+
+FROM alpine
+COPY --from=builder /app /app
+COPY config/*.yaml /etc/api/
+ENTRYPOINT ["/app"]
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 10, ast.StepCount())
+
+	steps, err := ast.CacheImpact("go.mod")
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+	assert.Equal(t, ImpactedStep{
+		Stage:     0,
+		StageName: "builder",
+		Line:      4,
+		Original:  "COPY go.mod go.sum ./",
+		Trigger:   true,
+	}, steps[0])
+	assert.Equal(t, 7, steps[3].Line)
+	assert.False(t, steps[3].Trigger)
+
+	steps, err = ast.CacheImpact("./cmd/api/main.go")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "COPY . .", steps[0].Original)
+
+	steps, err = ast.CacheImpact("config/prod.yaml")
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+	assert.Equal(t, 11, steps[2].Line)
+	assert.True(t, steps[2].Trigger)
+	assert.Equal(t, 1, steps[2].Stage)
+	assert.Equal(t, 12, steps[3].Line)
+}
+
+func TestCacheImpactExpandsArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG SRC=web
+COPY ${SRC}/ /srv/
+RUN build
+`))
+	require.NoError(t, err)
+
+	steps, err := ast.CacheImpact("web/index.html")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.True(t, steps[0].Trigger)
+
+	steps, err = ast.CacheImpact("api/main.go")
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+}
@@ -0,0 +1,261 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A `RUN --mount=type=cache` that probably doesn't speed up the build.
+type CacheWarning struct {
+	// The line of the RUN.
+	Line   int
+	Target string
+
+	Message string
+}
+
+// A package manager's cache, and the ways a RUN can bypass it.
+type cacheTool struct {
+	name string
+
+	// Part of the path of the cache, used to match mount targets.
+	dirHint string
+
+	// Commands (as word prefixes) that empty the cache.
+	cleanCommands []string
+
+	// Flags that turn off the cache, on the tool's commands.
+	noCacheFlags []string
+
+	// Env vars that move the cache, or turn it off.
+	dirEnv     []string
+	noCacheEnv []string
+}
+
+var cacheTools = []cacheTool{
+	{
+		name:          "go",
+		dirHint:       "go-build",
+		cleanCommands: []string{"go clean -cache"},
+		dirEnv:        []string{"GOCACHE"},
+	},
+	{
+		name:          "go",
+		dirHint:       "/go/pkg/mod",
+		cleanCommands: []string{"go clean -modcache"},
+		dirEnv:        []string{"GOMODCACHE"},
+	},
+	{
+		name:          "pip",
+		dirHint:       "pip",
+		cleanCommands: []string{"pip cache purge", "pip3 cache purge"},
+		noCacheFlags:  []string{"--no-cache-dir"},
+		dirEnv:        []string{"PIP_CACHE_DIR"},
+		noCacheEnv:    []string{"PIP_NO_CACHE_DIR"},
+	},
+	{
+		name:          "npm",
+		dirHint:       ".npm",
+		cleanCommands: []string{"npm cache clean"},
+		dirEnv:        []string{"npm_config_cache", "NPM_CONFIG_CACHE"},
+	},
+	{
+		name:          "yarn",
+		dirHint:       "yarn",
+		cleanCommands: []string{"yarn cache clean"},
+		dirEnv:        []string{"YARN_CACHE_FOLDER"},
+	},
+	{
+		name:          "apt-get",
+		dirHint:       "/var/cache/apt",
+		cleanCommands: []string{"apt-get clean", "apt clean"},
+	},
+	{
+		name:          "apk",
+		dirHint:       "/var/cache/apk",
+		cleanCommands: []string{"apk cache clean"},
+		noCacheFlags:  []string{"--no-cache"},
+	},
+}
+
+// IneffectiveCacheMounts finds cache mounts that the RUN can't use.
+//
+// These heuristics are checked for each `--mount=type=cache`:
+//
+//   - The stage runs as a non-root USER, but the mount doesn't set uid, so
+//     it's owned by root and the user can't write to it.
+//   - The target is under /root, but the stage runs as a non-root USER,
+//     whose home (and cache) is somewhere else.
+//   - The RUN deletes the target with rm, or empties it with the package
+//     manager (e.g., `npm cache clean`, `apt-get clean`).
+//   - The RUN turns off the package manager's cache (e.g., `pip install
+//     --no-cache-dir`, `apk add --no-cache`, or ENV PIP_NO_CACHE_DIR).
+//   - An ENV or ARG moves the package manager's cache outside of every
+//     cache mount (e.g., GOCACHE=/tmp/go while /root/.cache/go-build is
+//     mounted).
+//
+// Package manager caches are recognized by their default paths, so a
+// mount at a custom path is only checked for USER and rm.
+//
+// Earlier RUNs aren't checked. They change the image, not the cache.
+func (a AST) IneffectiveCacheMounts(buildArgs []string) ([]CacheWarning, error) {
+	var result []CacheWarning
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		run, ok := inst.(*instructions.RunCommand)
+		if !ok {
+			return nil
+		}
+
+		runMounts, ok := st.vars.expandMounts(run)
+		if !ok {
+			return nil
+		}
+
+		var targets []string
+		var mounts []*instructions.Mount
+		for _, m := range runMounts {
+			if m.Type != instructions.MountTypeCache {
+				continue
+			}
+			m.Target = path.Clean(m.Target)
+			targets = append(targets, m.Target)
+			mounts = append(mounts, m)
+		}
+		if len(mounts) == 0 {
+			return nil
+		}
+
+		script := runScript(node, run, st.vars)
+		segments := shellSegments(script)
+
+		warn := func(target, format string, args ...interface{}) {
+			result = append(result, CacheWarning{
+				Line:    node.StartLine,
+				Target:  target,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		user, _, _ := strings.Cut(st.user, ":")
+		nonRoot := user != "" && !isRootUser(user)
+		for _, m := range mounts {
+			if nonRoot && m.UID == nil {
+				warn(m.Target, "cache mount %s is owned by root, but the stage runs as USER %s. Set uid= on the mount",
+					m.Target, user)
+			}
+			if nonRoot && pathWithin(m.Target, "/root") {
+				warn(m.Target, "cache mount %s is in root's home, but USER %s keeps its cache in its own home",
+					m.Target, user)
+			}
+
+			for _, words := range segments {
+				if deleted, ok := removedPath(words, m.Target); ok {
+					warn(m.Target, "`rm %s` deletes the cache mount %s", deleted, m.Target)
+				}
+			}
+
+			for _, tool := range cacheTools {
+				if !strings.Contains(m.Target, tool.dirHint) {
+					continue
+				}
+				for _, words := range segments {
+					line := strings.Join(words, " ")
+					for _, cmd := range tool.cleanCommands {
+						if line == cmd || strings.HasPrefix(line, cmd+" ") {
+							warn(m.Target, "`%s` empties the cache mount %s", cmd, m.Target)
+						}
+					}
+					if invokes(words, tool.name) {
+						for _, w := range words {
+							for _, flag := range tool.noCacheFlags {
+								if w == flag {
+									warn(m.Target, "`%s %s` turns off the cache in %s", tool.name, flag, m.Target)
+								}
+							}
+						}
+					}
+				}
+				for _, env := range tool.noCacheEnv {
+					if val, ok := st.vars.lookup(env); ok && val != "" && val != "0" && strings.ToLower(val) != "false" {
+						warn(m.Target, "%s=%s turns off the cache in %s", env, val, m.Target)
+					}
+				}
+				for _, env := range tool.dirEnv {
+					val, ok := st.vars.lookup(env)
+					if !ok || val == "" || withinAny(path.Clean(val), targets) {
+						continue
+					}
+					warn(m.Target, "%s=%s moves the %s cache outside of the cache mount %s", env, val, tool.name, m.Target)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// shellSegments splits a script into simple commands, as lists of words.
+func shellSegments(script string) [][]string {
+	var result [][]string
+	for _, segment := range strings.FieldsFunc(script, func(r rune) bool {
+		return r == ';' || r == '&' || r == '|' || r == '\n'
+	}) {
+		words := strings.Fields(segment)
+		if len(words) > 0 {
+			result = append(result, words)
+		}
+	}
+	return result
+}
+
+// invokes checks whether a command runs the tool, directly (e.g., pip3)
+// or through another command (e.g., python -m pip).
+func invokes(words []string, tool string) bool {
+	for _, w := range words {
+		base := path.Base(w)
+		if base == tool || base == tool+"3" {
+			return true
+		}
+	}
+	return false
+}
+
+// removedPath checks whether an rm command deletes the target, its
+// contents, or a directory that contains it, and returns the argument
+// that does.
+func removedPath(words []string, target string) (string, bool) {
+	if len(words) == 0 || path.Base(words[0]) != "rm" {
+		return "", false
+	}
+	for _, w := range words[1:] {
+		if strings.HasPrefix(w, "-") {
+			continue
+		}
+		p := path.Clean(strings.TrimSuffix(w, "*"))
+		if pathWithin(target, p) || (path.Dir(p) == target && strings.HasSuffix(w, "*")) {
+			return w, true
+		}
+	}
+	return "", false
+}
+
+// pathWithin checks whether p is dir or inside it.
+func pathWithin(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
+func withinAny(p string, dirs []string) bool {
+	for _, dir := range dirs {
+		if pathWithin(p, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheWarningMessages(t *testing.T, df string, buildArgs []string) []string {
+	ast, err := ParseAST(Dockerfile(df))
+	require.NoError(t, err)
+
+	warnings, err := ast.IneffectiveCacheMounts(buildArgs)
+	require.NoError(t, err)
+	var result []string
+	for _, w := range warnings {
+		result = append(result, w.Message)
+	}
+	return result
+}
+
+func TestIneffectiveCacheMountsNonRootUser(t *testing.T) {
+	msgs := cacheWarningMessages(t, `
+FROM python:3.11
+USER app
+RUN --mount=type=cache,target=/root/.cache/pip pip install -r requirements.txt
+RUN --mount=type=cache,target=/home/app/.cache/pip,uid=1000 pip install -r requirements.txt
+`, nil)
+	assert.Equal(t, []string{
+		"cache mount /root/.cache/pip is owned by root, but the stage runs as USER app. Set uid= on the mount",
+		"cache mount /root/.cache/pip is in root's home, but USER app keeps its cache in its own home",
+	}, msgs)
+}
+
+func TestIneffectiveCacheMountsRootUser(t *testing.T) {
+	msgs := cacheWarningMessages(t, `
+FROM golang:1.20
+USER root:root
+RUN --mount=type=cache,target=/root/.cache/go-build go build ./...
+`, nil)
+	assert.Empty(t, msgs)
+}
+
+func TestIneffectiveCacheMountsCleared(t *testing.T) {
+	msgs := cacheWarningMessages(t, `
+FROM node:18
+RUN --mount=type=cache,target=/root/.npm npm ci && npm cache clean --force
+RUN --mount=type=cache,target=/var/cache/apt apt-get update && apt-get install -y curl && apt-get clean
+RUN --mount=type=cache,target=/root/.cache rm -rf /root/.cache/*
+RUN --mount=type=cache,target=/root/.cache rm -rf /root/.cache/pip/selfcheck.json
+`, nil)
+	assert.Equal(t, []string{
+		"`npm cache clean` empties the cache mount /root/.npm",
+		"`apt-get clean` empties the cache mount /var/cache/apt",
+		"`rm /root/.cache/*` deletes the cache mount /root/.cache",
+	}, msgs)
+}
+
+func TestIneffectiveCacheMountsDisabled(t *testing.T) {
+	msgs := cacheWarningMessages(t, `
+FROM python:3.11
+RUN --mount=type=cache,target=/root/.cache/pip python -m pip install --no-cache-dir flask
+FROM alpine
+RUN --mount=type=cache,target=/var/cache/apk apk add --no-cache curl
+FROM python:3.11
+ENV PIP_NO_CACHE_DIR=1
+RUN --mount=type=cache,target=/root/.cache/pip pip install flask
+`, nil)
+	assert.Equal(t, []string{
+		"`pip --no-cache-dir` turns off the cache in /root/.cache/pip",
+		"`apk --no-cache` turns off the cache in /var/cache/apk",
+		"PIP_NO_CACHE_DIR=1 turns off the cache in /root/.cache/pip",
+	}, msgs)
+}
+
+func TestIneffectiveCacheMountsMovedByEnv(t *testing.T) {
+	msgs := cacheWarningMessages(t, `
+FROM golang:1.20
+ARG CACHE=/tmp/go
+ENV GOCACHE=${CACHE}
+RUN --mount=type=cache,target=/root/.cache/go-build go build ./...
+RUN --mount=type=cache,target=/root/.cache/go-build --mount=type=cache,target=/tmp/go go build ./...
+`, nil)
+	assert.Equal(t, []string{
+		"GOCACHE=/tmp/go moves the go cache outside of the cache mount /root/.cache/go-build",
+	}, msgs)
+}
+
+func TestIneffectiveCacheMountsLines(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+USER nobody
+RUN --mount=type=bind,target=/src ls /src
+RUN --mount=type=cache,target=/cache ls /cache
+`))
+	require.NoError(t, err)
+
+	warnings, err := ast.IneffectiveCacheMounts(nil)
+	require.NoError(t, err)
+	if assert.Len(t, warnings, 1) {
+		assert.Equal(t, 5, warnings[0].Line)
+		assert.Equal(t, "/cache", warnings[0].Target)
+	}
+}
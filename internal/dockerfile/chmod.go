@@ -0,0 +1,110 @@
+package dockerfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A COPY or ADD whose --chmod makes files writable by everyone.
+type ChmodFinding struct {
+	Line int
+	Mode string
+}
+
+// WorldWritableChmods finds COPY and ADD instructions with a --chmod
+// that grants write permission to all users.
+//
+// Handles both octal (`--chmod=777`) and symbolic (`--chmod=a+w`) modes.
+func (a AST) WorldWritableChmods() ([]ChmodFinding, error) {
+	var result []ChmodFinding
+	err := a.Traverse(func(node *parser.Node) error {
+		mode := copyChmod(node)
+		if mode != "" && isWorldWritableMode(mode) {
+			result = append(result, ChmodFinding{Line: node.StartLine, Mode: mode})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// copyChmod returns the --chmod flag of a COPY or ADD node, if any.
+func copyChmod(node *parser.Node) string {
+	switch strings.ToLower(node.Value) {
+	case command.Copy, command.Add:
+	default:
+		return ""
+	}
+
+	if len(node.Flags) == 0 {
+		return ""
+	}
+
+	inst, err := instructions.ParseInstruction(node)
+	if err != nil {
+		return "" // ignore parsing error
+	}
+
+	switch inst := inst.(type) {
+	case *instructions.CopyCommand:
+		return inst.Chmod
+	case *instructions.AddCommand:
+		return inst.Chmod
+	}
+	return ""
+}
+
+func isWorldWritableMode(mode string) bool {
+	octal, err := strconv.ParseUint(mode, 8, 32)
+	if err == nil {
+		return octal&0o002 != 0
+	}
+
+	// Evaluate symbolic clauses in order (e.g., "u=rwx,go=rx,o+w"),
+	// tracking only the world-write bit.
+	writable := false
+	for _, clause := range strings.Split(mode, ",") {
+		i := strings.IndexAny(clause, "+-=")
+		if i == -1 {
+			continue
+		}
+
+		who := clause[:i]
+		if who != "" && !strings.ContainsAny(who, "oa") {
+			continue
+		}
+
+		// A clause can chain several operations, like "o+w-x".
+		rest := clause[i:]
+		for len(rest) > 0 {
+			op := rest[0]
+			end := strings.IndexAny(rest[1:], "+-=")
+			perms := rest[1:]
+			if end != -1 {
+				perms = rest[1 : end+1]
+			}
+
+			hasWrite := strings.Contains(perms, "w")
+			switch op {
+			case '+':
+				writable = writable || hasWrite
+			case '-':
+				writable = writable && !hasWrite
+			case '=':
+				writable = hasWrite
+			}
+
+			if end == -1 {
+				break
+			}
+			rest = rest[end+1:]
+		}
+	}
+	return writable
+}
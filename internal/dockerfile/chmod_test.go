@@ -0,0 +1,49 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorldWritableChmods(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --chmod=777 a /a
+COPY --chmod=755 b /b
+ADD --chmod=666 c /c
+COPY --chmod=a+w d /d
+COPY --chmod=u=rwx,go=rx e /e
+COPY --chmod=o+w,o-w f /f
+COPY --chmod=+rw g /g
+COPY --chown=1000 h /h
+COPY i /i
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.WorldWritableChmods()
+	require.NoError(t, err)
+	assert.Equal(t, []ChmodFinding{
+		{Line: 3, Mode: "777"},
+		{Line: 5, Mode: "666"},
+		{Line: 6, Mode: "a+w"},
+		{Line: 9, Mode: "+rw"},
+	}, findings)
+}
+
+func TestIsWorldWritableMode(t *testing.T) {
+	for mode, expected := range map[string]bool{
+		"0777":    true,
+		"0775":    false,
+		"2":       true,
+		"o=rw":    true,
+		"ug+w":    false,
+		"o+x-w":   false,
+		"o+r+w":   true,
+		"a=rwx":   true,
+		"garbage": false,
+	} {
+		assert.Equal(t, expected, isWorldWritableMode(mode), mode)
+	}
+}
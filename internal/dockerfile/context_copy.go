@@ -0,0 +1,37 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// DockerfileInContext finds COPY and ADD instructions that copy the
+// Dockerfile into the image as part of a broader copy, like `COPY . .`.
+//
+// Assumes the Dockerfile is named Dockerfile, at the root of the build
+// context. The fix is usually to add Dockerfile to the .dockerignore.
+//
+// Returns the line numbers of the instructions.
+func (a AST) DockerfileInContext() ([]int, error) {
+	var result []int
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		for _, src := range contextSources(inst) {
+			src = st.vars.expand(src)
+			if path.Clean(strings.TrimPrefix(src, "/")) == "Dockerfile" {
+				// Copying the Dockerfile by name is deliberate.
+				continue
+			}
+			if contextSourceIncludes(src, "Dockerfile") {
+				result = append(result, node.StartLine)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,29 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileInContext(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+COPY go.mod go.sum ./
+COPY . .
+COPY ./ /src
+ADD * /app/
+COPY Dockerfile /Dockerfile
+COPY src /src
+
+FROM alpine
+COPY --from=builder . /
+ADD https://example.com/archive.tar.gz /tmp/
+`))
+	require.NoError(t, err)
+
+	lines, err := ast.DockerfileInContext()
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5, 6}, lines)
+}
@@ -0,0 +1,37 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A file or directory that a COPY or ADD reads from the build context.
+type ContextSource struct {
+	// The line of the COPY or ADD.
+	Line int
+
+	// Relative to the context, with ARGs expanded. May be a glob.
+	Path string
+}
+
+// ContextSources lists what COPY and ADD instructions read from the build
+// context. Sources from other stages (COPY --from) and remote ADDs aren't
+// read from the context, so they aren't listed.
+func (a AST) ContextSources(buildArgs []string) ([]ContextSource, error) {
+	var result []ContextSource
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		for _, src := range contextSources(inst) {
+			result = append(result, ContextSource{
+				Line: node.StartLine,
+				Path: path.Clean(strings.TrimPrefix(st.vars.expand(src), "/")),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,34 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextSources(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+ARG OUT=dist
+COPY go.mod go.sum ./
+COPY ./${OUT}/ /app/
+ADD *.txt /app/
+
+FROM alpine
+COPY --from=builder /app /app
+ADD https://example.com/archive.tar.gz /tmp/
+COPY . .
+`))
+	require.NoError(t, err)
+
+	sources, err := ast.ContextSources(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ContextSource{
+		{Line: 4, Path: "go.mod"},
+		{Line: 4, Path: "go.sum"},
+		{Line: 5, Path: "dist"},
+		{Line: 6, Path: "*.txt"},
+		{Line: 11, Path: "."},
+	}, sources)
+}
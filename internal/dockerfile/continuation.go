@@ -0,0 +1,45 @@
+package dockerfile
+
+import (
+	"strings"
+)
+
+// ContinuationWarnings returns the lines that end with a line continuation
+// (usually `\`) but are followed by a blank line or a comment.
+//
+// The parser skips blank lines and comments inside an instruction, so the
+// instruction silently continues onto the next non-empty line. Some builders
+// handle this differently, and it's usually a mistake (like a stray `\` at
+// the end of a RUN that swallows the next instruction).
+func (a AST) ContinuationWarnings() ([]int, error) {
+	lines := strings.Split(strings.ReplaceAll(string(a.source), "\r\n", "\n"), "\n")
+	escape := string(a.result.EscapeToken)
+
+	var result []int
+	for _, node := range a.result.AST.Children {
+		// Line numbers are 1-indexed.
+		// Continuations in heredoc bodies belong to the script, not the
+		// Dockerfile.
+		end := instructionEndLine(node)
+		for line := node.StartLine; line < end && line < len(lines); line++ {
+			text := strings.TrimRight(lines[line-1], " \t")
+			if line != node.StartLine && isCommentOrBlank(text) {
+				// The parser skips these, so a trailing escape doesn't matter.
+				continue
+			}
+			if !strings.HasSuffix(text, escape) {
+				continue
+			}
+
+			if isCommentOrBlank(lines[line]) {
+				result = append(result, line)
+			}
+		}
+	}
+	return result, nil
+}
+
+func isCommentOrBlank(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinuationWarnings(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		df       string
+		expected []int
+	}{
+		{
+			name: "clean",
+			df: `FROM alpine
+RUN apk add \
+  curl \
+  git
+`,
+		},
+		{
+			name: "followed by comment",
+			df: `FROM alpine
+RUN apk add \
+# curl is needed for healthchecks
+  curl
+`,
+			expected: []int{2},
+		},
+		{
+			name: "followed by blank line",
+			df: `FROM alpine
+RUN apk add curl \
+
+COPY . .
+`,
+			expected: []int{2},
+		},
+		{
+			name:     "trailing whitespace",
+			df:       "FROM alpine\nRUN apk add \\  \n\n  curl\n",
+			expected: []int{2},
+		},
+		{
+			name: "multiple",
+			df: `FROM alpine
+RUN apk add \
+  curl \
+# git too
+  git \
+
+ENV FOO=bar
+`,
+			expected: []int{3, 5},
+		},
+		{
+			name: "comment ending in backslash",
+			df: `FROM alpine
+RUN apk add \
+# a comment \
+  curl
+`,
+			expected: []int{2},
+		},
+		{
+			name:     "escape directive",
+			df:       "# escape=`\nFROM alpine\nRUN echo \\\nRUN apk add `\n\n  curl\n",
+			expected: []int{4},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ast, err := ParseAST(Dockerfile(tc.df))
+			require.NoError(t, err)
+
+			lines, err := ast.ContinuationWarnings()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, lines)
+		})
+	}
+}
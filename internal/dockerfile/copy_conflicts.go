@@ -0,0 +1,107 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Two COPY or ADD instructions in a stage that write the same file.
+type ConflictFinding struct {
+	Stage     int
+	StageName string
+
+	// The line of the COPY whose file is overwritten.
+	FirstLine int
+
+	// The line of the COPY that overwrites it.
+	SecondLine int
+
+	// The absolute path of the file both COPYs write.
+	Dest string
+}
+
+// ConflictingCopyDests finds COPY and ADD pairs in the same stage that
+// write to the same file, with relative destinations resolved against the
+// WORKDIR. The second COPY wins, so the first is wasted. This is usually a
+// COPY that was duplicated with a new source but the old destination.
+//
+// Only file destinations that can be worked out from the Dockerfile are
+// compared: a source that's a directory (e.g., "." or "src/") or a wildcard
+// writes files we can't name. Other sources are assumed to be files, since
+// the Dockerfile alone can't tell. A RUN between the pair may read the
+// first file, so pairs split by a RUN aren't reported.
+func (a AST) ConflictingCopyDests(buildArgs []string) ([]ConflictFinding, error) {
+	// The line of the last COPY that wrote each file since the last RUN.
+	var written map[string]int
+	var result []ConflictFinding
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		var sd instructions.SourcesAndDest
+		switch inst := inst.(type) {
+		case *instructions.Stage, *instructions.RunCommand:
+			written = map[string]int{}
+			return nil
+		case *instructions.CopyCommand:
+			sd = inst.SourcesAndDest
+		case *instructions.AddCommand:
+			sd = inst.SourcesAndDest
+		default:
+			return nil
+		}
+
+		for _, dest := range copyDestFiles(sd, st) {
+			if first, ok := written[dest]; ok {
+				result = append(result, ConflictFinding{
+					Stage:      st.stageIndex,
+					StageName:  st.stageName,
+					FirstLine:  first,
+					SecondLine: node.StartLine,
+					Dest:       dest,
+				})
+			}
+			written[dest] = node.StartLine
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// The absolute paths of the files a COPY writes, where they're known.
+func copyDestFiles(sd instructions.SourcesAndDest, st *walkState) []string {
+	sources := make([]string, 0, len(sd.SourcePaths)+len(sd.SourceContents))
+	for _, src := range sd.SourcePaths {
+		sources = append(sources, st.vars.expand(src))
+	}
+	for _, c := range sd.SourceContents {
+		sources = append(sources, c.Path)
+	}
+
+	dest := st.vars.expand(sd.DestPath)
+	if !path.IsAbs(dest) {
+		dest = path.Join(st.workDir, dest)
+	}
+	destIsDir := strings.HasSuffix(sd.DestPath, "/") || sd.DestPath == "." || len(sources) > 1
+
+	var result []string
+	for _, src := range sources {
+		if src == "" || strings.HasSuffix(src, "/") || strings.ContainsAny(src, "*?[") {
+			continue
+		}
+		base := path.Base(src)
+		if base == "." || base == ".." || base == "/" {
+			continue
+		}
+
+		if destIsDir {
+			result = append(result, path.Join(dest, base))
+		} else {
+			result = append(result, path.Clean(dest))
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflictingCopyDests(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		df        string
+		buildArgs []string
+		expected  []ConflictFinding
+	}{
+		{
+			name: "same file dest",
+			df: `
+FROM alpine
+COPY config.dev.json /app/config.json
+COPY config.prod.json /app/config.json
+`,
+			expected: []ConflictFinding{
+				{Stage: 0, FirstLine: 3, SecondLine: 4, Dest: "/app/config.json"},
+			},
+		},
+		{
+			name: "relative dest resolved against workdir",
+			df: `
+FROM alpine AS app
+WORKDIR /app
+COPY settings.yaml /app/
+ADD other/settings.yaml .
+`,
+			expected: []ConflictFinding{
+				{Stage: 0, StageName: "app", FirstLine: 4, SecondLine: 5, Dest: "/app/settings.yaml"},
+			},
+		},
+		{
+			name: "multiple sources into a dir",
+			df: `
+FROM alpine
+WORKDIR /src
+COPY go.mod go.sum ./
+COPY vendor/go.sum ./
+`,
+			expected: []ConflictFinding{
+				{Stage: 0, FirstLine: 4, SecondLine: 5, Dest: "/src/go.sum"},
+			},
+		},
+		{
+			name: "dest from arg",
+			df: `
+FROM alpine
+ARG DIR=/etc/app
+COPY a.conf ${DIR}/app.conf
+COPY b.conf /etc/app/app.conf
+`,
+			expected: []ConflictFinding{
+				{Stage: 0, FirstLine: 4, SecondLine: 5, Dest: "/etc/app/app.conf"},
+			},
+		},
+		{
+			name: "run in between",
+			df: `
+FROM alpine
+COPY package.json /app/
+RUN npm install
+COPY package.json /app/
+`,
+		},
+		{
+			name: "different stages",
+			df: `
+FROM alpine
+COPY a.txt /out.txt
+FROM alpine
+COPY b.txt /out.txt
+`,
+		},
+		{
+			name: "directories and wildcards",
+			df: `
+FROM alpine
+WORKDIR /app
+COPY . .
+COPY src/ ./
+COPY *.json ./
+COPY . .
+`,
+		},
+		{
+			name: "different files in same dir",
+			df: `
+FROM alpine
+COPY a.txt b.txt /data/
+COPY c.txt /data/
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ast, err := ParseAST(Dockerfile(tc.df))
+			require.NoError(t, err)
+
+			findings, err := ast.ConflictingCopyDests(tc.buildArgs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, findings)
+		})
+	}
+}
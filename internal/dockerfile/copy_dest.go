@@ -0,0 +1,55 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// AbsolutizeCopyDests rewrites each relative COPY or ADD destination
+// to an absolute path, resolved against the effective WORKDIR at that
+// point in the stage (or "/" if the stage never sets one).
+//
+// Flags and sources are left as they are. A destination that names a
+// directory (with a trailing slash, or ".") keeps its trailing slash.
+//
+// Returns the number of destinations rewritten.
+func (a *AST) AbsolutizeCopyDests(buildArgs []string) (int, error) {
+	count := 0
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst.(type) {
+		case *instructions.CopyCommand, *instructions.AddCommand:
+		default:
+			return nil
+		}
+
+		dest := copyDestNode(node)
+		if dest == nil || path.IsAbs(st.vars.expand(dest.Value)) {
+			return nil
+		}
+
+		abs := path.Join(st.workDir, dest.Value)
+		if (dest.Value == "." || strings.HasSuffix(dest.Value, "/")) && !strings.HasSuffix(abs, "/") {
+			abs += "/"
+		}
+		dest.Value = abs
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// copyDestNode returns the node holding the destination of a COPY or
+// ADD, which is always the last argument.
+func copyDestNode(node *parser.Node) *parser.Node {
+	var dest *parser.Node
+	for n := node.Next; n != nil; n = n.Next {
+		dest = n
+	}
+	return dest
+}
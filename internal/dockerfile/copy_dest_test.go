@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbsolutizeCopyDestsAfterWorkDir(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21
+WORKDIR /app
+COPY --chown=1000 go.mod go.sum ./
+COPY src bin
+WORKDIR cmd
+ADD ["main.go", "."]
+COPY config /etc/config
+`))
+	require.NoError(t, err)
+
+	count, err := ast.AbsolutizeCopyDests(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	actual, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM golang:1.21
+WORKDIR /app
+COPY --chown=1000 go.mod go.sum /app/
+COPY src /app/bin
+WORKDIR cmd
+ADD main.go /app/cmd/
+COPY config /etc/config
+`, string(actual))
+}
+
+func TestAbsolutizeCopyDestsNoWorkDir(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --from=builder bin/app app
+COPY . .
+`))
+	require.NoError(t, err)
+
+	count, err := ast.AbsolutizeCopyDests(nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	actual, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+COPY --from=builder bin/app /app
+COPY . /
+`, string(actual))
+}
+
+func TestAbsolutizeCopyDestsExpandsVars(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG DEST=/opt
+WORKDIR /srv
+COPY a $DEST
+COPY b ${SUB}
+`))
+	require.NoError(t, err)
+
+	count, err := ast.AbsolutizeCopyDests([]string{"SUB=data"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	actual, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+ARG DEST=/opt
+WORKDIR /srv
+COPY a $DEST
+COPY b /srv/${SUB}
+`, string(actual))
+}
@@ -0,0 +1,114 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A COPY --from that names a stage that doesn't exist.
+type DanglingRef struct {
+	Line int
+	Name string
+
+	// The declared stage with the closest name, if any is close enough
+	// to be a likely typo or missed rename.
+	Suggestion string
+}
+
+// DanglingStageRefs finds each `COPY --from=<name>` where the name
+// looks like a stage name but doesn't match any stage declared
+// before it.
+//
+// Names with a registry, tag, or digest (like `golang:1.21`) are assumed
+// to be images. Numeric names must be the index of an earlier stage.
+func (a AST) DanglingStageRefs(buildArgs []string) ([]DanglingRef, error) {
+	var allStages []string
+	for _, node := range a.result.AST.Children {
+		inst, err := instructions.ParseInstruction(node)
+		if err != nil {
+			continue
+		}
+		if stage, ok := inst.(*instructions.Stage); ok && stage.Name != "" {
+			allStages = append(allStages, stage.Name)
+		}
+	}
+
+	var result []DanglingRef
+	byName := map[string]int{}
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			if inst.Name != "" {
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+			}
+		case *instructions.CopyCommand:
+			from := st.vars.expand(inst.From)
+			if from == "" || !looksLikeStageName(from) {
+				return nil
+			}
+
+			if _, ok := resolveStageRef(from, byName, st.stageIndex); ok {
+				return nil
+			}
+
+			result = append(result, DanglingRef{
+				Line:       node.StartLine,
+				Name:       from,
+				Suggestion: closestName(from, allStages),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func looksLikeStageName(name string) bool {
+	return !strings.ContainsAny(name, "/:@")
+}
+
+// closestName returns the candidate with the smallest edit distance
+// to name, or "" if none are close.
+func closestName(name string, candidates []string) string {
+	best := ""
+	bestDist := len(name)/2 + 1
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if d < bestDist {
+			best = c
+			bestDist = d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
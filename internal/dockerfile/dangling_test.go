@@ -0,0 +1,66 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDanglingStageRefs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS build-api
+RUN go build -o /api
+
+FROM node:20 AS build-web
+RUN npm run build
+
+FROM alpine
+COPY --from=build-api /api /api
+COPY --from=builder-web /dist /www
+COPY --from=golang:1.21 /usr/local/go /go
+COPY --from=0 /api /api2
+COPY --from=5 /api /api3
+COPY --from=cache /x /x
+`))
+	require.NoError(t, err)
+
+	refs, err := ast.DanglingStageRefs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []DanglingRef{
+		{Line: 10, Name: "builder-web", Suggestion: "build-web"},
+		{Line: 13, Name: "5"},
+		{Line: 14, Name: "cache"},
+	}, refs)
+}
+
+func TestDanglingStageRefsExpandsArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+FROM alpine
+ARG SRC=buidler
+COPY --from=${SRC} /app /app
+`))
+	require.NoError(t, err)
+
+	refs, err := ast.DanglingStageRefs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []DanglingRef{{Line: 5, Name: "buidler", Suggestion: "builder"}}, refs)
+
+	refs, err = ast.DanglingStageRefs([]string{"SRC=builder"})
+	require.NoError(t, err)
+	assert.Empty(t, refs)
+}
+
+func TestDanglingStageRefsLaterStage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --from=builder /app /app
+FROM golang:1.21 AS builder
+`))
+	require.NoError(t, err)
+
+	refs, err := ast.DanglingStageRefs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []DanglingRef{{Line: 3, Name: "builder", Suggestion: "builder"}}, refs)
+}
@@ -0,0 +1,254 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A command that installs a project's dependencies from its manifests.
+type dependencyInstall struct {
+	// The command, as words. The first word is matched by base name, so
+	// /usr/local/bin/npm matches "npm".
+	command []string
+
+	// The files in the build context that the install reads.
+	manifests []string
+
+	// The manifests are named by -r flags, like pip's requirements files.
+	requirementFlags bool
+}
+
+// Ordered so that a subcommand is checked before its bare tool.
+var dependencyInstalls = []dependencyInstall{
+	{command: []string{"npm", "ci"}, manifests: []string{"package.json", "package-lock.json"}},
+	{command: []string{"npm", "install"}, manifests: []string{"package.json", "package-lock.json"}},
+	{command: []string{"npm", "i"}, manifests: []string{"package.json", "package-lock.json"}},
+	{command: []string{"yarn", "install"}, manifests: []string{"package.json", "yarn.lock"}},
+	{command: []string{"yarn"}, manifests: []string{"package.json", "yarn.lock"}},
+	{command: []string{"pnpm", "install"}, manifests: []string{"package.json", "pnpm-lock.yaml"}},
+	{command: []string{"pnpm", "i"}, manifests: []string{"package.json", "pnpm-lock.yaml"}},
+	{command: []string{"pip", "install"}, requirementFlags: true},
+	{command: []string{"poetry", "install"}, manifests: []string{"pyproject.toml", "poetry.lock"}},
+	{command: []string{"pipenv", "install"}, manifests: []string{"Pipfile", "Pipfile.lock"}},
+	{command: []string{"go", "mod", "download"}, manifests: []string{"go.mod", "go.sum"}},
+	{command: []string{"bundle", "install"}, manifests: []string{"Gemfile", "Gemfile.lock"}},
+	{command: []string{"cargo", "fetch"}, manifests: []string{"Cargo.toml", "Cargo.lock"}},
+	{command: []string{"composer", "install"}, manifests: []string{"composer.json", "composer.lock"}},
+}
+
+// A COPY or ADD of the whole build context.
+type contextCopy struct {
+	node *parser.Node
+
+	// The destination as written, and as an absolute path.
+	dest    string
+	destAbs string
+}
+
+// SuggestDependencyCaching finds stages that copy the whole build context
+// (e.g., `COPY . .`) before a RUN that installs dependencies (e.g., `npm
+// ci`), so that changing any file re-runs the install. The suggestion is
+// to copy only the dependency manifests, install, and then copy the rest:
+//
+//	COPY package.json package-lock.json ./
+//	RUN npm ci
+//	COPY . .
+//
+// Installs are recognized for npm, yarn, pnpm, pip (with -r), poetry,
+// pipenv, go mod download, bundler, cargo fetch, and composer. An install
+// that names the packages to install (e.g., `npm install -g typescript`)
+// doesn't read the manifests, so it isn't reported.
+//
+// Each Suggestion has one stage, with Commands and Lines for the COPY and
+// then the RUN.
+func (a AST) SuggestDependencyCaching(buildArgs []string) ([]Suggestion, error) {
+	var wholeCopy *contextCopy
+	var result []Suggestion
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			wholeCopy = nil
+
+		case *instructions.CopyCommand, *instructions.AddCommand:
+			if wholeCopy != nil || !copiesWholeContext(inst, st.vars) {
+				return nil
+			}
+			dest := st.vars.expand(copySourcesAndDest(inst).DestPath)
+			destAbs := dest
+			if !path.IsAbs(destAbs) {
+				destAbs = path.Join(st.workDir, destAbs)
+			}
+			wholeCopy = &contextCopy{node: node, dest: dest, destAbs: path.Clean(destAbs)}
+
+		case *instructions.RunCommand:
+			if wholeCopy == nil {
+				return nil
+			}
+
+			var installs []string
+			var manifests []string
+			for _, words := range shellSegments(runScript(node, inst, st.vars)) {
+				install, files, ok := matchDependencyInstall(words, st.workDir, wholeCopy.destAbs)
+				if !ok {
+					continue
+				}
+				installs = append(installs, install)
+				manifests = appendUnique(manifests, files...)
+			}
+			if len(manifests) == 0 {
+				return nil
+			}
+
+			copyCmd := normalizedInstruction(wholeCopy.node)
+			runCmd := normalizedInstruction(node)
+			result = append(result, Suggestion{
+				BaseImage:  st.baseName,
+				Stages:     []int{st.stageIndex},
+				StageNames: []string{st.stageName},
+				Commands:   []string{copyCmd, runCmd},
+				Lines:      [][]int{{wholeCopy.node.StartLine, node.StartLine}},
+				Message: fmt.Sprintf(
+					"`%s` on line %d copies the whole build context before `%s` on line %d, "+
+						"so changing any file re-runs the install. "+
+						"Copy the dependency manifests first, so the install stays cached until they change:\n\n"+
+						"COPY %s %s\n%s\n%s",
+					copyCmd, wholeCopy.node.StartLine, strings.Join(installs, " && "), node.StartLine,
+					strings.Join(manifests, " "), dirDest(wholeCopy.dest), runCmd, copyCmd),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// copiesWholeContext checks whether a COPY or ADD copies the root of the
+// build context.
+func copiesWholeContext(inst interface{}, vars *stageVars) bool {
+	for _, src := range contextSources(inst) {
+		if path.Clean(strings.TrimPrefix(vars.expand(src), "/")) == "." {
+			return true
+		}
+	}
+	return false
+}
+
+func copySourcesAndDest(inst interface{}) instructions.SourcesAndDest {
+	switch inst := inst.(type) {
+	case *instructions.CopyCommand:
+		return inst.SourcesAndDest
+	case *instructions.AddCommand:
+		return inst.SourcesAndDest
+	}
+	return instructions.SourcesAndDest{}
+}
+
+// matchDependencyInstall checks whether a shell command installs
+// dependencies from manifests, and returns the command and the manifests,
+// as paths in the build context.
+//
+// workDir is where the command runs, and copyDest is where the build
+// context was copied to, for resolving requirements files.
+func matchDependencyInstall(words []string, workDir, copyDest string) (string, []string, bool) {
+	for _, install := range dependencyInstalls {
+		i := commandIndex(words, install.command)
+		if i == -1 {
+			continue
+		}
+		args := words[i+len(install.command):]
+		cmd := strings.Join(words[i:], " ")
+
+		if install.requirementFlags {
+			var manifests []string
+			for _, req := range requirementFiles(args) {
+				if !path.IsAbs(req) {
+					req = path.Join(workDir, req)
+				}
+				if rel, ok := strings.CutPrefix(path.Clean(req), strings.TrimSuffix(copyDest, "/")+"/"); ok {
+					manifests = append(manifests, rel)
+				}
+			}
+			return cmd, manifests, len(manifests) > 0
+		}
+
+		for _, arg := range args {
+			if !strings.HasPrefix(arg, "-") {
+				// Installs named packages, or runs another subcommand
+				// (e.g., `yarn build`).
+				return "", nil, false
+			}
+		}
+		return cmd, install.manifests, true
+	}
+	return "", nil, false
+}
+
+// commandIndex returns where the command starts in the words, or -1. The
+// tool may be run through another command, like `python -m pip`.
+func commandIndex(words []string, command []string) int {
+	for i := 0; i+len(command) <= len(words); i++ {
+		base := path.Base(words[i])
+		if base != command[0] && base != command[0]+"3" {
+			continue
+		}
+		match := true
+		for j, w := range command[1:] {
+			if words[i+1+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// requirementFiles returns the files named by -r flags, like
+// `pip install -r requirements.txt`.
+func requirementFiles(args []string) []string {
+	var result []string
+	for i, arg := range args {
+		switch {
+		case (arg == "-r" || arg == "--requirement") && i+1 < len(args):
+			result = append(result, args[i+1])
+		case strings.HasPrefix(arg, "--requirement="):
+			result = append(result, strings.TrimPrefix(arg, "--requirement="))
+		case strings.HasPrefix(arg, "-r") && len(arg) > 2:
+			result = append(result, arg[2:])
+		}
+	}
+	return result
+}
+
+// dirDest makes a COPY destination a directory, as COPY requires for
+// several sources.
+func dirDest(dest string) string {
+	if strings.HasSuffix(dest, "/") {
+		return dest
+	}
+	return dest + "/"
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,106 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggestDependencyCaching(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:18 AS web
+WORKDIR /app
+COPY . .
+RUN npm ci
+RUN npm run build
+
+FROM golang:1.20
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN go build ./...
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.SuggestDependencyCaching(nil)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+
+	s := suggestions[0]
+	assert.Equal(t, "node:18", s.BaseImage)
+	assert.Equal(t, []int{0}, s.Stages)
+	assert.Equal(t, []string{"web"}, s.StageNames)
+	assert.Equal(t, []string{"COPY . .", "RUN npm ci"}, s.Commands)
+	assert.Equal(t, [][]int{{4, 5}}, s.Lines)
+	assert.Contains(t, s.Message, "COPY package.json package-lock.json ./\nRUN npm ci\nCOPY . .")
+}
+
+func TestSuggestDependencyCachingInstalls(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		run       string
+		manifests string
+	}{
+		{"yarn", "RUN yarn install --frozen-lockfile", "COPY package.json yarn.lock /app/"},
+		{"bare yarn", "RUN yarn", "COPY package.json yarn.lock /app/"},
+		{"pip", "RUN pip install --no-cache-dir -r requirements.txt -r requirements-dev.txt",
+			"COPY requirements.txt requirements-dev.txt /app/"},
+		{"python -m pip", "RUN python3 -m pip install -r /app/requirements.txt", "COPY requirements.txt /app/"},
+		{"several installs", "RUN go mod download && bundle install",
+			"COPY go.mod go.sum Gemfile Gemfile.lock /app/"},
+		{"global install", "RUN npm install -g typescript", ""},
+		{"build", "RUN yarn build", ""},
+		{"pip without requirements", "RUN pip install .", ""},
+		{"requirements outside the copy", "RUN pip install -r /tmp/requirements.txt", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ast, err := ParseAST(Dockerfile("FROM base\nWORKDIR /app\nCOPY . /app\n" + tc.run + "\n"))
+			require.NoError(t, err)
+
+			suggestions, err := ast.SuggestDependencyCaching(nil)
+			require.NoError(t, err)
+			if tc.manifests == "" {
+				assert.Empty(t, suggestions)
+				return
+			}
+			require.Len(t, suggestions, 1)
+			assert.Contains(t, suggestions[0].Message, tc.manifests+"\n")
+		})
+	}
+}
+
+func TestSuggestDependencyCachingNoContextCopy(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:18 AS deps
+COPY package.json ./
+RUN npm ci
+
+FROM node:18
+COPY --from=deps . .
+RUN npm ci
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.SuggestDependencyCaching(nil)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestSuggestDependencyCachingBuildArg(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM python:3.11
+ARG SRC
+COPY ${SRC} /code
+WORKDIR /code
+RUN pip install -r requirements.txt
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.SuggestDependencyCaching([]string{"SRC=."})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, [][]int{{4, 6}}, suggestions[0].Lines)
+	assert.Contains(t, suggestions[0].Message, "COPY requirements.txt /code/\n")
+}
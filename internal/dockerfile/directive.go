@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A parser directive at the top of the Dockerfile, like `# syntax=docker/dockerfile:1`.
+type Directive struct {
+	// The directive name, lowercased (e.g., "syntax" or "escape").
+	Name  string
+	Value string
+	Line  int
+}
+
+// Directives returns the parser directives in the Dockerfile, in the order
+// they appear. Directives that buildkit doesn't recognize aren't included.
+func (a AST) Directives() []Directive {
+	result := make([]Directive, 0, len(a.directives))
+	for _, d := range a.directives {
+		line := 0
+		if len(d.Location) > 0 {
+			line = d.Location[0].Start.Line
+		}
+		result = append(result, Directive{
+			Name:  d.Name,
+			Value: d.Value,
+			Line:  line,
+		})
+	}
+	return result
+}
+
+// Matches a line that looks like a parser directive, as buildkit does.
+var directiveLineRe = regexp.MustCompile(`^#\s*([a-zA-Z][a-zA-Z0-9]*)\s*=\s*(.+?)\s*$`)
+
+// A line in the header at the top of the Dockerfile: a parser directive,
+// something that looks like one but that buildkit doesn't recognize (like
+// `# check=skip=...`), or a `#!` first line.
+type headerLine struct {
+	// The directive that buildkit parsed from this line, if any.
+	directive *parser.Directive
+
+	// The line as written. Print writes it back exactly, since tools that
+	// read directives may be sensitive to whitespace. Empty for a directive
+	// that wasn't parsed from the Dockerfile, which is printed as
+	// `# name = value`.
+	source string
+}
+
+func (h headerLine) String() string {
+	if h.source != "" || h.directive == nil {
+		return h.source
+	}
+	return fmt.Sprintf("# %s = %s", h.directive.Name, h.directive.Value)
+}
+
+// parseHeader returns the lines at the top of the Dockerfile that look like
+// parser directives, with the directives that buildkit parsed from them.
+func parseHeader(df Dockerfile, directives []*parser.Directive) []headerLine {
+	byLine := map[int]*parser.Directive{}
+	for _, d := range directives {
+		if len(d.Location) > 0 {
+			byLine[d.Location[0].Start.Line] = d
+		}
+	}
+
+	var result []headerLine
+	scanner := bufio.NewScanner(strings.NewReader(string(df)))
+	for i := 1; scanner.Scan(); i++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		isShebang := i == 1 && strings.HasPrefix(line, "#!")
+		if !isShebang && !directiveLineRe.MatchString(line) {
+			break
+		}
+		result = append(result, headerLine{directive: byLine[i], source: line})
+	}
+	return result
+}
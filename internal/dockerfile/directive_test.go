@@ -0,0 +1,35 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectives(t *testing.T) {
+	df := Dockerfile(`# syntax = docker/dockerfile:1.4
+# ESCAPE=` + "`" + `
+# unknown = foo
+
+FROM golang:1.20
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Directive{
+		{Name: "syntax", Value: "docker/dockerfile:1.4", Line: 1},
+		{Name: "escape", Value: "`", Line: 2},
+	}, ast.Directives())
+}
+
+func TestDirectivesNone(t *testing.T) {
+	df := Dockerfile(`FROM golang:1.20
+# syntax = docker/dockerfile:1.4
+RUN echo hi
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	assert.Empty(t, ast.Directives())
+}
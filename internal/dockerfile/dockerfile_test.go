@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFindImages(t *testing.T) {
@@ -93,6 +94,37 @@ FROM gcr.io/image-a:${TAG}
 	}
 }
 
+func TestFindImagesSkipsStages(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21 AS Builder
+FROM builder AS test
+COPY --from=builder /app /app
+COPY --from=0 /app /app
+COPY --from=gcr.io/image-b /src /src
+`)
+	images, err := df.FindImages(nil)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(images)) {
+		assert.Equal(t, "docker.io/library/golang:1.21", images[0].String())
+		assert.Equal(t, "gcr.io/image-b", images[1].String())
+	}
+}
+
+func TestFindImagesStageArgNotGlobal(t *testing.T) {
+	df := Dockerfile(`
+ARG TAG=1.0
+FROM gcr.io/image-a:${TAG}
+ARG TAG=2.0
+FROM gcr.io/image-b:${TAG}
+`)
+	images, err := df.FindImages(nil)
+	assert.NoError(t, err)
+	if assert.Equal(t, 2, len(images)) {
+		assert.Equal(t, "gcr.io/image-a:1.0", images[0].String())
+		assert.Equal(t, "gcr.io/image-b:1.0", images[1].String())
+	}
+}
+
 func TestFindImagesWithMount(t *testing.T) {
 	// Example from:
 	// https://github.com/tilt-dev/tilt/issues/3331
@@ -116,3 +148,53 @@ RUN --mount=type=cache,id=pip,target=/root/.cache/pip pip install python-dateuti
 		assert.Equal(t, "docker.io/library/python2-base", images[0].String())
 	}
 }
+
+func TestWorkDir(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.10 AS builder
+WORKDIR /src
+
+FROM alpine
+ARG APP=app
+ENV ROOT=/srv
+WORKDIR $ROOT
+WORKDIR ${APP}
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	dir, err := ast.WorkDir(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/app", dir)
+
+	dir, err = ast.WorkDir([]string{"APP=other"})
+	require.NoError(t, err)
+	assert.Equal(t, "/srv/other", dir)
+}
+
+func TestWorkDirDefault(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.10
+WORKDIR /src
+FROM alpine
+`))
+	require.NoError(t, err)
+
+	dir, err := ast.WorkDir(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/", dir)
+}
+
+func TestWorkDirInheritedFromStage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.10 AS base
+WORKDIR /src
+FROM base
+WORKDIR app
+`))
+	require.NoError(t, err)
+
+	dir, err := ast.WorkDir(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "/src/app", dir)
+}
@@ -0,0 +1,224 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// The version of the DocumentModel format. It changes when a field is
+// removed or changes meaning, but not when a field is added, so that an
+// editor integration can check that it understands the model it's given.
+const DocumentModelVersion = 1
+
+// A structural model of a whole Dockerfile, for editor integrations like
+// a language server: everything hover, completion, and diagnostics need,
+// built in one pass.
+type DocumentModel struct {
+	// Always DocumentModelVersion.
+	Version int
+
+	// The parser directives at the top of the file.
+	Directives []Directive
+
+	// The stages, in order.
+	Stages []StageModel
+
+	// Every top-level instruction, in file order.
+	Instructions []InstructionModel
+}
+
+// A stage, from its FROM to its last instruction.
+type StageModel struct {
+	Index int
+
+	// The name from `FROM image AS name`, lowercased, if any.
+	Name string
+
+	// The image or stage the stage is built FROM, with ARGs expanded.
+	BaseName string
+
+	// The index of the earlier stage that the stage is built FROM, or -1
+	// if it's built from an image.
+	BaseStage int
+
+	// The line of the FROM, and the last line of the stage's last
+	// instruction.
+	StartLine int
+	EndLine   int
+}
+
+// A flag of an instruction, like `--from=builder`.
+type InstructionFlag struct {
+	// The name, without dashes (e.g., "from").
+	Name string
+
+	// The value, or "" for a flag without one, like `--link`.
+	Value string
+}
+
+// One instruction.
+type InstructionModel struct {
+	// The keyword, in upper case (e.g., "RUN").
+	Type string
+
+	// The index of the stage the instruction belongs to, or -1 for ARGs
+	// before the first FROM.
+	Stage int
+
+	// The lines the instruction spans, including continuation lines and
+	// heredoc bodies.
+	StartLine int
+	EndLine   int
+
+	// The flags, in order.
+	Flags []InstructionFlag
+
+	// The arguments, split as in InstructionHandle.Args: a shell-form
+	// command is one argument, each element of an exec-form (JSON) command
+	// is one, and ENV and LABEL alternate keys and values. For ONBUILD,
+	// the trigger instruction is one argument.
+	Args []string
+
+	// Whether the arguments were written in the exec (JSON) form.
+	ExecForm bool
+
+	// The heredoc bodies, in order.
+	Heredocs []parser.Heredoc
+
+	// The images the instruction refers to (a FROM image, or a COPY --from
+	// image), with ARGs expanded and normalized (e.g.,
+	// "docker.io/library/golang:1.21"). References to stages aren't
+	// images, so they aren't included. These are the refs that
+	// InjectImageDigest can replace.
+	ImageRefs []string
+
+	// The comment lines directly above the instruction, without the `#`.
+	// A blank line ends the comment.
+	Comments []string
+
+	// Why the instruction doesn't parse (e.g., an unknown flag), if it
+	// doesn't.
+	ParseError string
+}
+
+// DocumentModel returns a model of the whole Dockerfile: the directives,
+// the stages, and every instruction with its type, lines, flags, args,
+// image refs, and comments.
+//
+// The model only depends on the Dockerfile and the build args, so it's
+// stable across calls.
+func (a AST) DocumentModel(buildArgs []string) (DocumentModel, error) {
+	imageRefs := map[*parser.Node][]string{}
+	err := a.traverseImageRefs(func(node *parser.Node, ref reference.Named) reference.Named {
+		imageRefs[node] = append(imageRefs[node], ref.String())
+		return nil
+	}, argInstructions(buildArgs))
+	if err != nil {
+		return DocumentModel{}, err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(a.source), "\r\n", "\n"), "\n")
+
+	byName := map[string]int{}
+
+	model := DocumentModel{
+		Version:      DocumentModelVersion,
+		Directives:   a.Directives(),
+		Stages:       []StageModel{},
+		Instructions: []InstructionModel{},
+	}
+	err = a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if strings.ToLower(node.Value) == command.From {
+			// A FROM that doesn't parse still starts a stage, with no
+			// name or base.
+			model.Stages = append(model.Stages, StageModel{
+				Index:     st.stageIndex,
+				Name:      st.stageName,
+				BaseName:  st.baseName,
+				BaseStage: baseStageIndex(st.baseName, st.stageIndex, byName),
+				StartLine: node.StartLine,
+			})
+			if st.stageName != "" {
+				byName[strings.ToLower(st.stageName)] = st.stageIndex
+			}
+		}
+		if st.stageIndex >= 0 {
+			model.Stages[st.stageIndex].EndLine = node.EndLine
+		}
+
+		im := InstructionModel{
+			Type:      strings.ToUpper(node.Value),
+			Stage:     st.stageIndex,
+			StartLine: node.StartLine,
+			EndLine:   node.EndLine,
+			Flags:     instructionFlags(node),
+			Args:      instructionArgs(node),
+			ExecForm:  node.Attributes["json"],
+			Heredocs:  node.Heredocs,
+			ImageRefs: imageRefs[node],
+			Comments:  attachedComments(lines, len(a.header), node.StartLine),
+		}
+		if inst == nil {
+			_, err := instructions.ParseInstruction(node)
+			if err != nil {
+				im.ParseError = err.Error()
+			}
+		}
+		model.Instructions = append(model.Instructions, im)
+		return nil
+	})
+	if err != nil {
+		return DocumentModel{}, err
+	}
+	return model, nil
+}
+
+// baseStageIndex returns the index of the earlier stage that a stage
+// builds FROM, or -1 if it builds from an image.
+func baseStageIndex(baseName string, current int, byName map[string]int) int {
+	index, _ := resolveStageRef(baseName, byName, current)
+	return index
+}
+
+func instructionFlags(node *parser.Node) []InstructionFlag {
+	var result []InstructionFlag
+	for _, f := range node.Flags {
+		k, v, _ := strings.Cut(strings.TrimPrefix(f, "--"), "=")
+		result = append(result, InstructionFlag{Name: k, Value: v})
+	}
+	return result
+}
+
+func instructionArgs(node *parser.Node) []string {
+	if strings.ToLower(node.Value) == command.Onbuild {
+		if node.Next == nil || len(node.Next.Children) == 0 {
+			return nil
+		}
+		return []string{fmtNode(node.Next.Children[0])}
+	}
+
+	var result []string
+	for n := node.Next; n != nil; n = n.Next {
+		result = append(result, n.Value)
+	}
+	return result
+}
+
+// attachedComments returns the comment lines directly above a line (which
+// starts at 1), without the `#`. The header of parser directives isn't a
+// comment.
+func attachedComments(lines []string, headerLen, line int) []string {
+	var result []string
+	for i := line - 2; i >= headerLen && i < len(lines); i-- {
+		text := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(text, "#") {
+			break
+		}
+		result = append([]string{strings.TrimSpace(strings.TrimPrefix(text, "#"))}, result...)
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentModel(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.21
+
+# The builder.
+# Compiles the app.
+FROM golang:${GO_VERSION} AS Builder
+RUN --mount=type=cache,target=/root/.cache \
+    go build -o /out/app .
+
+FROM alpine
+COPY --from=builder --link /out/app /app
+COPY <<EOF /etc/motd
+hello
+EOF
+CMD ["/app", "--serve"]
+`))
+	require.NoError(t, err)
+
+	model, err := ast.DocumentModel(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, DocumentModelVersion, model.Version)
+	assert.Equal(t, []Directive{{Name: "syntax", Value: "docker/dockerfile:1", Line: 1}}, model.Directives)
+	assert.Equal(t, []StageModel{
+		{Index: 0, Name: "builder", BaseName: "golang:1.21", BaseStage: -1, StartLine: 6, EndLine: 8},
+		{Index: 1, BaseName: "alpine", BaseStage: -1, StartLine: 10, EndLine: 15},
+	}, model.Stages)
+
+	require.Len(t, model.Instructions, 7)
+	assert.Equal(t, InstructionModel{
+		Type:      "ARG",
+		Stage:     -1,
+		StartLine: 2,
+		EndLine:   2,
+		Args:      []string{"GO_VERSION=1.21"},
+	}, model.Instructions[0])
+	assert.Equal(t, InstructionModel{
+		Type:      "FROM",
+		Stage:     0,
+		StartLine: 6,
+		EndLine:   6,
+		Args:      []string{"golang:${GO_VERSION}", "AS", "Builder"},
+		ImageRefs: []string{"docker.io/library/golang:1.21"},
+		Comments:  []string{"The builder.", "Compiles the app."},
+	}, model.Instructions[1])
+	assert.Equal(t, InstructionModel{
+		Type:      "RUN",
+		Stage:     0,
+		StartLine: 7,
+		EndLine:   8,
+		Flags:     []InstructionFlag{{Name: "mount", Value: "type=cache,target=/root/.cache"}},
+		Args:      []string{"go build -o /out/app ."},
+	}, model.Instructions[2])
+
+	copyFrom := model.Instructions[4]
+	assert.Equal(t, []InstructionFlag{{Name: "from", Value: "builder"}, {Name: "link"}}, copyFrom.Flags)
+	assert.Empty(t, copyFrom.ImageRefs)
+
+	heredoc := model.Instructions[5]
+	assert.Equal(t, 12, heredoc.StartLine)
+	assert.Equal(t, 14, heredoc.EndLine)
+	assert.Equal(t, []parser.Heredoc{{Name: "EOF", Content: "hello\n", Expand: true}}, heredoc.Heredocs)
+
+	cmd := model.Instructions[6]
+	assert.True(t, cmd.ExecForm)
+	assert.Equal(t, []string{"/app", "--serve"}, cmd.Args)
+}
+
+func TestDocumentModelStageRefs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS base
+FROM base AS test
+FROM 0
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+`))
+	require.NoError(t, err)
+
+	model, err := ast.DocumentModel(nil)
+	require.NoError(t, err)
+
+	require.Len(t, model.Stages, 3)
+	assert.Equal(t, -1, model.Stages[0].BaseStage)
+	assert.Equal(t, 0, model.Stages[1].BaseStage)
+	assert.Equal(t, 0, model.Stages[2].BaseStage)
+	assert.Empty(t, model.Instructions[1].ImageRefs)
+	assert.Equal(t, []string{"docker.io/library/nginx:latest"}, model.Instructions[3].ImageRefs)
+}
+
+func TestDocumentModelStageRefsOnlyMatchEarlierStages(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM app AS app
+FROM release AS build
+FROM alpine AS release
+`))
+	require.NoError(t, err)
+
+	model, err := ast.DocumentModel(nil)
+	require.NoError(t, err)
+
+	require.Len(t, model.Stages, 3)
+	for _, s := range model.Stages {
+		assert.Equal(t, -1, s.BaseStage, "stage %d", s.Index)
+	}
+}
+
+func TestDocumentModelBuildArgsAndErrors(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=alpine
+FROM $BASE
+
+# Not a valid flag.
+COPY --frm=builder /a /b
+`))
+	require.NoError(t, err)
+
+	model, err := ast.DocumentModel([]string{"BASE=debian:12"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "debian:12", model.Stages[0].BaseName)
+	assert.Equal(t, []string{"docker.io/library/debian:12"}, model.Instructions[1].ImageRefs)
+
+	bad := model.Instructions[2]
+	assert.Equal(t, []string{"Not a valid flag."}, bad.Comments)
+	assert.Contains(t, bad.ParseError, "frm")
+}
@@ -0,0 +1,45 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT renders the DependencyGraph in Graphviz DOT, with arrows from each
+// image or stage to the stages that use it. Pipe it to `dot -Tpng`.
+//
+// Stages are boxes, external images are ellipses, and COPY --from edges
+// are dashed.
+func (a AST) ToDOT(buildArgs []string) (string, error) {
+	g, err := a.DependencyGraph(buildArgs)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph dockerfile {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s", dotQuote(n.Label))
+		if n.Stage < 0 {
+			attrs += ", shape=ellipse"
+		}
+		fmt.Fprintf(&sb, "  %s [%s];\n", dotQuote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		attrs := `label="FROM"`
+		if e.Kind == EdgeCopiesFrom {
+			attrs = `label="COPY --from", style=dashed`
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [%s];\n", dotQuote(e.To), dotQuote(e.From), attrs)
+	}
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// dotQuote makes s a DOT string literal.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
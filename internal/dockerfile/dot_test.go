@@ -0,0 +1,38 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToDOT(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.20 AS build
+RUN go build ./...
+
+FROM alpine
+COPY --from=build /app /app
+`))
+	require.NoError(t, err)
+
+	dot, err := ast.ToDOT(nil)
+	require.NoError(t, err)
+	assert.Equal(t, `digraph dockerfile {
+  rankdir=LR;
+  node [shape=box];
+  "stage:0" [label="build"];
+  "stage:1" [label="stage 1"];
+  "image:golang:1.20" [label="golang:1.20", shape=ellipse];
+  "image:alpine" [label="alpine", shape=ellipse];
+  "image:golang:1.20" -> "stage:0" [label="FROM"];
+  "image:alpine" -> "stage:1" [label="FROM"];
+  "stage:0" -> "stage:1" [label="COPY --from", style=dashed];
+}
+`, dot)
+}
+
+func TestDOTQuote(t *testing.T) {
+	assert.Equal(t, `"a\"b\\c\nd"`, dotQuote("a\"b\\c\nd"))
+}
@@ -0,0 +1,31 @@
+package dockerfile
+
+import (
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// EffectiveLabels returns the labels that the final stage sets on the
+// image, with ARG and ENV references expanded. When a key is set more than
+// once, the last LABEL wins.
+//
+// LABELs are scoped to their stage: a stage that builds FROM an earlier
+// stage inherits its labels, but copying files from a stage (COPY --from)
+// doesn't. Labels set by a base image are not known.
+func (a AST) EffectiveLabels(buildArgs []string) (map[string]string, error) {
+	labels := map[string]string{}
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		labels = st.labels
+		if inst, ok := inst.(*instructions.LabelCommand); ok {
+			labels = copyLabels(st.labels)
+			for _, kv := range inst.Labels {
+				labels[st.vars.expand(kv.Key)] = st.vars.expand(kv.Value)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyLabels(labels), nil
+}
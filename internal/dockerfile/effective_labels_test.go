@@ -0,0 +1,82 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveLabels(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG VERSION=dev
+FROM alpine
+ARG VERSION
+ENV TEAM=platform
+LABEL version=$VERSION maintainer="${TEAM}@example.com"
+LABEL "description"="the api server" version="${VERSION}-final"
+`))
+	require.NoError(t, err)
+
+	labels, err := ast.EffectiveLabels(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"version":     "dev-final",
+		"maintainer":  "platform@example.com",
+		"description": "the api server",
+	}, labels)
+
+	labels, err = ast.EffectiveLabels([]string{"VERSION=1.2.3"})
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3-final", labels["version"])
+}
+
+func TestEffectiveLabelsStageScope(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.20 AS builder
+LABEL stage=builder tool=go
+
+FROM builder AS test
+LABEL stage=test
+
+FROM alpine
+COPY --from=test /out /out
+LABEL stage=runtime
+`))
+	require.NoError(t, err)
+
+	// COPY --from doesn't bring the test stage's labels along.
+	labels, err := ast.EffectiveLabels(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"stage": "runtime"}, labels)
+}
+
+func TestEffectiveLabelsInheritedFromStage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS base
+LABEL org=acme stage=base
+
+FROM base
+LABEL stage=final
+`))
+	require.NoError(t, err)
+
+	labels, err := ast.EffectiveLabels(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"org": "acme", "stage": "final"}, labels)
+}
+
+func TestEffectiveLabelsNone(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS base
+LABEL org=acme
+
+FROM alpine
+RUN echo hi
+`))
+	require.NoError(t, err)
+
+	labels, err := ast.EffectiveLabels(nil)
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+}
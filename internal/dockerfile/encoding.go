@@ -0,0 +1,68 @@
+package dockerfile
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Dockerfiles should be UTF-8, but editors on Windows sometimes save them
+// with a byte order mark, or in Latin-1.
+//
+// Buildkit doesn't recognize the directives on a line that starts with a
+// BOM, and replaces invalid UTF-8 with U+FFFD, so Print wouldn't give back
+// the bytes it was given, and the Dockerfile's hash would change on every
+// round-trip.
+const utf8BOM = "\xef\xbb\xbf"
+
+// Before parsing, each byte that isn't valid UTF-8 is swapped for a rune in
+// this private use range, and Print swaps it back.
+const rawByteRuneBase = 0x10FF00
+
+func stripBOM(df Dockerfile) (Dockerfile, bool) {
+	s, ok := strings.CutPrefix(string(df), utf8BOM)
+	return Dockerfile(s), ok
+}
+
+// encodeRawBytes swaps the bytes of s that aren't valid UTF-8 for runes that
+// decodeRawBytes swaps back.
+func encodeRawBytes(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			sb.WriteRune(rawByteRuneBase + rune(s[i]))
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func decodeRawBytes(s string) string {
+	if strings.IndexFunc(s, isRawByteRune) == -1 {
+		return s
+	}
+
+	// The header lines are printed as written, so s may also have invalid
+	// UTF-8 of its own, which is copied as is.
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isRawByteRune(r) {
+			sb.WriteByte(byte(r - rawByteRuneBase))
+		} else {
+			sb.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func isRawByteRune(r rune) bool {
+	return r >= rawByteRuneBase && r <= rawByteRuneBase+0xff
+}
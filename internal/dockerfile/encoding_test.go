@@ -0,0 +1,58 @@
+package dockerfile
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBOM(t *testing.T) {
+	contents, err := os.ReadFile("testdata/bom.Dockerfile")
+	require.NoError(t, err)
+
+	ast, err := ParseAST(Dockerfile(contents))
+	require.NoError(t, err)
+	assert.Equal(t, []Directive{{Name: "syntax", Value: "docker/dockerfile:1", Line: 1}}, ast.Directives())
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, string(contents), string(df))
+}
+
+func TestParseLatin1(t *testing.T) {
+	contents, err := os.ReadFile("testdata/latin1.Dockerfile")
+	require.NoError(t, err)
+
+	ast, err := ParseAST(Dockerfile(contents))
+	require.NoError(t, err)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, "# syntax=docker/dockerfile:1\n"+
+		"\n"+
+		"FROM alpine\n"+
+		"LABEL description=\"caf\xe9 au lait\"\n"+
+		"ENV GREETING=ol\xe1\n"+
+		"RUN echo \"na\xefve\" > /greeting\n", string(df))
+
+	// Printing again doesn't change anything, so the hash is stable.
+	ast, err = ParseAST(df)
+	require.NoError(t, err)
+	again, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, string(df), string(again))
+}
+
+func TestRawBytesRoundTrip(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"plain ascii",
+		"café in UTF-8",
+		"caf\xe9 in Latin-1",
+		"\xff\xfe\x80",
+	} {
+		assert.Equal(t, s, decodeRawBytes(encodeRawBytes(s)))
+	}
+}
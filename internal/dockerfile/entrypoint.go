@@ -0,0 +1,85 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// ShellFormEntrypoints returns the lines of ENTRYPOINTs in shell form, like
+// `ENTRYPOINT node app.js`, in any stage.
+//
+// A shell-form ENTRYPOINT runs under `/bin/sh -c`, which doesn't forward
+// signals, so the app never sees the SIGTERM it needs to shut down
+// gracefully. An ENTRYPOINT that starts with `exec` replaces the shell, so
+// it isn't flagged.
+func (a AST) ShellFormEntrypoints() ([]int, error) {
+	var result []int
+	for _, node := range a.result.AST.Children {
+		if isShellFormEntrypoint(node) {
+			result = append(result, node.StartLine)
+		}
+	}
+	return result, nil
+}
+
+// ExecFormEntrypoints rewrites shell-form ENTRYPOINTs in exec form, e.g.,
+//
+//	ENTRYPOINT node app.js
+//
+// becomes
+//
+//	ENTRYPOINT ["node", "app.js"]
+//
+// Only ENTRYPOINTs made of plain words are rewritten. Anything the shell
+// would interpret (variables, quotes, globs, pipes, redirects, and so on)
+// means something different in exec form, so those are left alone.
+//
+// Returns the number of instructions rewritten.
+func (a *AST) ExecFormEntrypoints() (int, error) {
+	count := 0
+	for _, node := range a.result.AST.Children {
+		if !isShellFormEntrypoint(node) {
+			continue
+		}
+
+		cmdLine := node.Next.Value
+		if strings.ContainsAny(cmdLine, shellSpecialChars) {
+			continue
+		}
+
+		var head, tail *parser.Node
+		for _, word := range strings.Fields(cmdLine) {
+			n := &parser.Node{Value: word}
+			if head == nil {
+				head = n
+			} else {
+				tail.Next = n
+			}
+			tail = n
+		}
+
+		node.Next = head
+		if node.Attributes == nil {
+			node.Attributes = map[string]bool{}
+		}
+		node.Attributes["json"] = true
+		count++
+	}
+	return count, nil
+}
+
+// Characters that make the shell do something other than split words.
+const shellSpecialChars = "$`|&;<>(){}[]*?~#!\\'\"\n"
+
+func isShellFormEntrypoint(node *parser.Node) bool {
+	if strings.ToLower(node.Value) != command.Entrypoint || node.Attributes["json"] {
+		return false
+	}
+	if node.Next == nil {
+		return false
+	}
+	words := strings.Fields(node.Next.Value)
+	return len(words) > 0 && words[0] != "exec"
+}
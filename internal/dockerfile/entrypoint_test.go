@@ -0,0 +1,55 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellFormEntrypoints(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:18 AS build
+ENTRYPOINT node build.js
+
+FROM node:18
+ENTRYPOINT ["node", "app.js"]
+ENTRYPOINT exec node app.js
+ENTRYPOINT node app.js --port $PORT
+CMD node app.js
+`))
+	require.NoError(t, err)
+
+	lines, err := ast.ShellFormEntrypoints()
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 8}, lines)
+}
+
+func TestExecFormEntrypoints(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:18
+ENTRYPOINT node app.js --port 8080
+ENTRYPOINT node app.js --port $PORT
+ENTRYPOINT ./start.sh > /var/log/app.log
+ENTRYPOINT ["node", "app.js"]
+`))
+	require.NoError(t, err)
+
+	count, err := ast.ExecFormEntrypoints()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM node:18
+ENTRYPOINT ["node", "app.js", "--port", "8080"]
+ENTRYPOINT node app.js --port $PORT
+ENTRYPOINT ./start.sh > /var/log/app.log
+ENTRYPOINT ["node", "app.js"]
+`, string(df))
+
+	lines, err := ast.ShellFormEntrypoints()
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, lines)
+}
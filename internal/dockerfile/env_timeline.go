@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"sort"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A point in the Dockerfile where an ENV variable gets a value.
+type EnvEvent struct {
+	Line      int
+	Stage     int
+	StageName string
+
+	Key string
+
+	// The value, with ARG and ENV references expanded.
+	Value string
+
+	// True if the variable already had a value in this stage, which
+	// Previous holds.
+	Overwritten bool
+	Previous    string
+
+	// True if the stage inherited the variable from the earlier stage it
+	// builds FROM. Inherited events are reported on the FROM line.
+	Inherited bool
+}
+
+// EnvTimeline returns, in order, each point where an ENV variable is set
+// or overwritten.
+//
+// ENV doesn't carry over between stages, except when a stage builds FROM
+// an earlier stage; those variables are reported as inherited at the
+// start of the stage. ENV set by a base image is not known.
+func (a AST) EnvTimeline(buildArgs []string) ([]EnvEvent, error) {
+	var result []EnvEvent
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			env := st.vars.envSnapshot()
+			keys := make([]string, 0, len(env))
+			for k := range env {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				result = append(result, EnvEvent{
+					Line:      node.StartLine,
+					Stage:     st.stageIndex,
+					StageName: st.stageName,
+					Key:       k,
+					Value:     env[k],
+					Inherited: true,
+				})
+			}
+
+		case *instructions.EnvCommand:
+			env := st.vars.envSnapshot()
+			values := st.vars.expandEnv(inst)
+			for i, kv := range inst.Env {
+				prev, ok := env[kv.Key]
+				value := values[i]
+				result = append(result, EnvEvent{
+					Line:        node.StartLine,
+					Stage:       st.stageIndex,
+					StageName:   st.stageName,
+					Key:         kv.Key,
+					Value:       value,
+					Overwritten: ok,
+					Previous:    prev,
+				})
+				env[kv.Key] = value
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
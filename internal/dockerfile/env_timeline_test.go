@@ -0,0 +1,40 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvTimeline(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG VERSION=1.0
+FROM golang:1.21 AS builder
+ARG VERSION
+ENV APP=server PATH=/app/bin:$PATH
+ENV TAG=$APP-$VERSION
+ENV APP=worker
+
+FROM builder AS test
+ENV TAG=test
+
+FROM alpine
+ENV APP=final
+`))
+	require.NoError(t, err)
+
+	events, err := ast.EnvTimeline([]string{"VERSION=2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []EnvEvent{
+		{Line: 5, Stage: 0, StageName: "builder", Key: "APP", Value: "server"},
+		{Line: 5, Stage: 0, StageName: "builder", Key: "PATH", Value: "/app/bin:"},
+		{Line: 6, Stage: 0, StageName: "builder", Key: "TAG", Value: "server-2.0"},
+		{Line: 7, Stage: 0, StageName: "builder", Key: "APP", Value: "worker", Overwritten: true, Previous: "server"},
+		{Line: 9, Stage: 1, StageName: "test", Key: "APP", Value: "worker", Inherited: true},
+		{Line: 9, Stage: 1, StageName: "test", Key: "PATH", Value: "/app/bin:", Inherited: true},
+		{Line: 9, Stage: 1, StageName: "test", Key: "TAG", Value: "server-2.0", Inherited: true},
+		{Line: 10, Stage: 1, StageName: "test", Key: "TAG", Value: "test", Overwritten: true, Previous: "server-2.0"},
+		{Line: 13, Stage: 2, Key: "APP", Value: "final"},
+	}, events)
+}
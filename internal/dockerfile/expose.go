@@ -0,0 +1,114 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A port declared by an EXPOSE instruction.
+type ExposedPort struct {
+	Line  int
+	Stage int
+
+	// The port number or range (e.g., "80" or "8000-8010").
+	Port string
+
+	// "tcp" or "udp". Defaults to "tcp".
+	Protocol string
+}
+
+func (p ExposedPort) String() string {
+	return fmt.Sprintf("%s/%s", p.Port, p.Protocol)
+}
+
+// A port that's exposed more than once in the same stage.
+type PortDup struct {
+	Stage    int
+	Port     string
+	Protocol string
+
+	// The lines of every EXPOSE that declares the port, in order.
+	Lines []int
+}
+
+// ExposedPorts returns every port declared by an EXPOSE, with
+// ARG and ENV references expanded.
+func (a AST) ExposedPorts(buildArgs []string) ([]ExposedPort, error) {
+	var result []ExposedPort
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if _, ok := inst.(*instructions.ExposeCommand); !ok {
+			return nil
+		}
+		result = append(result, exposedPorts(node, st)...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// exposedPorts returns the ports that an EXPOSE declares.
+func exposedPorts(node *parser.Node, st *walkState) []ExposedPort {
+	var result []ExposedPort
+
+	// Buildkit sorts the parsed ports, so read them from the node
+	// to keep them in source order.
+	for n := node.Next; n != nil; n = n.Next {
+		for _, word := range strings.Fields(st.vars.expand(n.Value)) {
+			port, proto, found := strings.Cut(word, "/")
+			proto = strings.ToLower(proto)
+			if !found || proto == "" {
+				proto = "tcp"
+			}
+			result = append(result, ExposedPort{
+				Line:     node.StartLine,
+				Stage:    st.stageIndex,
+				Port:     port,
+				Protocol: proto,
+			})
+		}
+	}
+	return result
+}
+
+// DuplicateExposedPorts finds ports that are EXPOSEd more than once
+// in the same stage. `80/tcp` and `80/udp` are different ports.
+func (a AST) DuplicateExposedPorts() ([]PortDup, error) {
+	ports, err := a.ExposedPorts(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		stage int
+		port  string
+	}
+	var order []key
+	lines := map[key][]int{}
+	for _, p := range ports {
+		k := key{stage: p.Stage, port: p.String()}
+		if _, ok := lines[k]; !ok {
+			order = append(order, k)
+		}
+		lines[k] = append(lines[k], p.Line)
+	}
+
+	var result []PortDup
+	for _, k := range order {
+		if len(lines[k]) < 2 {
+			continue
+		}
+		port, proto, _ := strings.Cut(k.port, "/")
+		result = append(result, PortDup{
+			Stage:    k.stage,
+			Port:     port,
+			Protocol: proto,
+			Lines:    lines[k],
+		})
+	}
+	return result, nil
+}
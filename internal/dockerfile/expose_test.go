@@ -0,0 +1,49 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposedPorts(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG PORT=8080
+EXPOSE 80 443/TCP
+EXPOSE $PORT/udp 9000-9010
+`))
+	require.NoError(t, err)
+
+	ports, err := ast.ExposedPorts(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []ExposedPort{
+		{Line: 4, Stage: 0, Port: "80", Protocol: "tcp"},
+		{Line: 4, Stage: 0, Port: "443", Protocol: "tcp"},
+		{Line: 5, Stage: 0, Port: "8080", Protocol: "udp"},
+		{Line: 5, Stage: 0, Port: "9000-9010", Protocol: "tcp"},
+	}, ports)
+}
+
+func TestDuplicateExposedPorts(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+EXPOSE 80
+
+FROM alpine
+EXPOSE 80 53/udp
+EXPOSE 8080
+EXPOSE 80/tcp 53/tcp
+EXPOSE 8080 53/udp
+`))
+	require.NoError(t, err)
+
+	dups, err := ast.DuplicateExposedPorts()
+	require.NoError(t, err)
+	assert.Equal(t, []PortDup{
+		{Stage: 1, Port: "80", Protocol: "tcp", Lines: []int{6, 8}},
+		{Stage: 1, Port: "53", Protocol: "udp", Lines: []int{6, 9}},
+		{Stage: 1, Port: "8080", Protocol: "tcp", Lines: []int{7, 9}},
+	}, dups)
+}
@@ -0,0 +1,51 @@
+package dockerfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// StageFanIn counts how many times each stage is the source of a
+// `COPY --from`. A stage that's copied from in many places may be
+// better off exporting its outputs in one place.
+//
+// Named stages are keyed by their (lowercased) name, and unnamed stages by
+// their index (e.g., "0"). A reference by index to a named stage counts
+// towards its name. Stages that are never copied from, and --from images
+// that aren't stages, are left out.
+func (a AST) StageFanIn(buildArgs []string) (map[string]int, error) {
+	result := map[string]int{}
+
+	// The key of each stage, by index.
+	var keys []string
+	byName := map[string]int{}
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			key := strconv.Itoa(st.stageIndex)
+			if inst.Name != "" {
+				key = inst.Name
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+			}
+			keys = append(keys, key)
+
+		case *instructions.CopyCommand:
+			from := st.vars.expand(inst.From)
+			if from == "" {
+				return nil
+			}
+
+			if index, ok := resolveStageRef(from, byName, st.stageIndex); ok {
+				result[keys[index]]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageFanIn(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS Builder
+RUN go build -o /out/app ./cmd/app
+RUN go build -o /out/cli ./cmd/cli
+
+FROM alpine
+RUN echo assets > /assets
+
+FROM alpine
+ARG SRC=builder
+COPY --from=builder /out/app /app
+COPY --from=$SRC /out/cli /cli
+COPY --from=0 /go/pkg /pkg
+COPY --from=1 /assets /assets
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+COPY --from=2 /etc/passwd /etc/passwd
+`))
+	require.NoError(t, err)
+
+	fanIn, err := ast.StageFanIn(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"builder": 3, "1": 1}, fanIn)
+}
+
+func TestStageFanInBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS a
+FROM alpine AS b
+FROM alpine
+ARG SRC
+COPY --from=$SRC /x /x
+`))
+	require.NoError(t, err)
+
+	fanIn, err := ast.StageFanIn([]string{"SRC=b"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"b": 1}, fanIn)
+}
+
+func TestStageFanInOnlyCountsEarlierStages(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS app
+COPY --from=app /x /x
+COPY --from=assets /assets /assets
+
+FROM alpine AS assets
+RUN echo assets > /assets
+`))
+	require.NoError(t, err)
+
+	fanIn, err := ast.StageFanIn(nil)
+	require.NoError(t, err)
+	assert.Empty(t, fanIn)
+}
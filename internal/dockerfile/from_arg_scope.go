@@ -0,0 +1,111 @@
+package dockerfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A FROM that uses an ARG it can't see.
+type ScopeFinding struct {
+	// The line of the FROM.
+	Line int
+	Arg  string
+
+	// The line of the first ARG declaring it, or 0 if it's never declared.
+	DeclaredLine int
+
+	Message string
+}
+
+// ARGs that BuildKit defines for every FROM.
+var automaticPlatformArgs = map[string]bool{
+	"TARGETPLATFORM": true, "TARGETOS": true, "TARGETARCH": true, "TARGETVARIANT": true,
+	"BUILDPLATFORM": true, "BUILDOS": true, "BUILDARCH": true, "BUILDVARIANT": true,
+}
+
+var varRefRE = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// InvalidFromArgScope finds FROM instructions whose image or --platform
+// refer to an ARG that isn't declared before the first FROM.
+//
+// A FROM only sees global ARGs. An ARG declared inside a stage (after
+// any FROM) is scoped to that stage, so a later `FROM $BASE` expands it
+// to nothing. Passing the value as a build arg doesn't help.
+func (a AST) InvalidFromArgScope(buildArgs []string) ([]ScopeFinding, error) {
+	passed := map[string]bool{}
+	for _, arg := range buildArgs {
+		name, _, _ := strings.Cut(arg, "=")
+		passed[name] = true
+	}
+
+	globals := map[string]bool{}
+	// The line of the first in-stage declaration of each ARG.
+	stageDecls := map[string]int{}
+
+	type fromRef struct {
+		line int
+		vars []string
+	}
+	var froms []fromRef
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			// A FROM can mention the same ARG in its image and platform.
+			froms = append(froms, fromRef{node.StartLine, referencedVars(inst.BaseName + " " + inst.Platform)})
+		case *instructions.ArgCommand:
+			for _, kv := range inst.Args {
+				if st.stageIndex < 0 {
+					globals[kv.Key] = true
+				} else if _, ok := stageDecls[kv.Key]; !ok {
+					stageDecls[kv.Key] = node.StartLine
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ScopeFinding
+	for _, from := range froms {
+		for _, name := range from.vars {
+			if globals[name] || automaticPlatformArgs[name] {
+				continue
+			}
+
+			f := ScopeFinding{Line: from.line, Arg: name, DeclaredLine: stageDecls[name]}
+			if f.DeclaredLine != 0 {
+				f.Message = fmt.Sprintf("ARG %s is declared inside a stage (line %d), but FROM only sees ARGs declared before the first FROM",
+					name, f.DeclaredLine)
+			} else {
+				f.Message = fmt.Sprintf("ARG %s is never declared; declare it before the first FROM", name)
+			}
+			if passed[name] {
+				f.Message += fmt.Sprintf(". Passing --build-arg %s has no effect until it is", name)
+			}
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+// referencedVars returns the names of the variables that a word uses, in
+// order, without duplicates.
+func referencedVars(word string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, m := range varRefRE.FindAllStringSubmatch(word, -1) {
+		name := m[1] + m[2]
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,69 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidFromArgScope(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG GO_VERSION=1.20
+FROM golang:${GO_VERSION} AS build
+ARG BASE=alpine
+RUN go build ./...
+
+FROM --platform=$BUILDPLATFORM $BASE
+COPY --from=build /app /app
+
+FROM $RUNTIME:$TAG
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.InvalidFromArgScope(nil)
+	require.NoError(t, err)
+	if assert.Len(t, findings, 3) {
+		assert.Equal(t, 7, findings[0].Line)
+		assert.Equal(t, "BASE", findings[0].Arg)
+		assert.Equal(t, 4, findings[0].DeclaredLine)
+		assert.Contains(t, findings[0].Message, "declared inside a stage (line 4)")
+
+		assert.Equal(t, 10, findings[1].Line)
+		assert.Equal(t, "RUNTIME", findings[1].Arg)
+		assert.Equal(t, 0, findings[1].DeclaredLine)
+		assert.Contains(t, findings[1].Message, "never declared")
+
+		assert.Equal(t, "TAG", findings[2].Arg)
+	}
+}
+
+func TestInvalidFromArgScopeBuildArg(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG BASE
+FROM ${BASE}
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.InvalidFromArgScope([]string{"BASE=debian"})
+	require.NoError(t, err)
+	if assert.Len(t, findings, 1) {
+		assert.Contains(t, findings[0].Message, "Passing --build-arg BASE has no effect")
+	}
+}
+
+func TestInvalidFromArgScopeGlobal(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=alpine
+ARG TAG
+FROM --platform=${TARGETPLATFORM} ${BASE}:${TAG}
+ARG BASE
+FROM $BASE
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.InvalidFromArgScope(nil)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
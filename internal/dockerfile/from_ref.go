@@ -0,0 +1,102 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+// A FROM whose image, once ARGs are expanded, is empty or isn't a valid
+// image ref.
+type FromRefProblem struct {
+	Line int
+
+	// The image as written, e.g., "${BASE}".
+	BaseName string
+
+	// The image with ARGs expanded.
+	Expanded string
+
+	// The ARGs that the image references, in order.
+	Args []string
+
+	// The ARGs that the image references that have no value.
+	UnsetArgs []string
+
+	// Why the expanded image isn't a valid ref, or nil if it's empty.
+	Err error
+}
+
+func (p FromRefProblem) Message() string {
+	var msg string
+	if p.Expanded == "" {
+		msg = fmt.Sprintf("FROM at line %d expands to an empty image", p.Line)
+	} else {
+		msg = fmt.Sprintf("FROM at line %d expands to invalid image ref %q: %v", p.Line, p.Expanded, p.Err)
+	}
+	if len(p.UnsetArgs) == 0 {
+		return msg
+	}
+
+	verb := "has"
+	if len(p.UnsetArgs) > 1 {
+		verb = "have"
+	}
+	suggestions := make([]string, len(p.UnsetArgs))
+	for i, name := range p.UnsetArgs {
+		suggestions[i] = fmt.Sprintf("'%s': ...", name)
+	}
+	return fmt.Sprintf("%s because ARG %s %s no value; pass build_args={%s}",
+		msg, strings.Join(p.UnsetArgs, ", "), verb, strings.Join(suggestions, ", "))
+}
+
+// InvalidFromRefs finds the FROM instructions that, with ARGs expanded,
+// name an empty or malformed image. Docker only reports these when the
+// build runs, with an error that doesn't mention the ARG.
+//
+// FROMs that refer to an earlier stage are fine.
+func (a AST) InvalidFromRefs(buildArgs []string) ([]FromRefProblem, error) {
+	byName := map[string]int{}
+	var result []FromRefProblem
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		stage, ok := inst.(*instructions.Stage)
+		if !ok {
+			return nil
+		}
+		if stage.Name != "" {
+			byName[strings.ToLower(stage.Name)] = st.stageIndex
+		}
+		if _, ok := resolveStageRef(st.baseName, byName, st.stageIndex); ok {
+			return nil
+		}
+
+		p := FromRefProblem{
+			Line:     node.StartLine,
+			BaseName: stage.BaseName,
+			Expanded: st.baseName,
+			Args:     referencedVars(stage.BaseName),
+		}
+		if p.Expanded != "" {
+			_, p.Err = container.ParseNamed(p.Expanded)
+			if p.Err == nil {
+				return nil
+			}
+		}
+
+		for _, name := range p.Args {
+			if st.vars.globals[name] == "" && !automaticPlatformArgs[name] {
+				p.UnsetArgs = append(p.UnsetArgs, name)
+			}
+		}
+		result = append(result, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
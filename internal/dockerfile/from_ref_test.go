@@ -0,0 +1,78 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidFromRefsEmpty(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE
+FROM ${BASE}
+RUN echo hi
+`))
+	require.NoError(t, err)
+
+	problems, err := ast.InvalidFromRefs(nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 3, problems[0].Line)
+	assert.Equal(t, []string{"BASE"}, problems[0].UnsetArgs)
+	assert.Equal(t,
+		"FROM at line 3 expands to an empty image because ARG BASE has no value; pass build_args={'BASE': ...}",
+		problems[0].Message())
+
+	problems, err = ast.InvalidFromRefs([]string{"BASE=alpine:3.18"})
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestInvalidFromRefsMalformed(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG REGISTRY=gcr.io/my-project
+ARG TAG
+FROM $REGISTRY/app:$TAG
+`))
+	require.NoError(t, err)
+
+	problems, err := ast.InvalidFromRefs(nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "gcr.io/my-project/app:", problems[0].Expanded)
+	assert.Equal(t, []string{"REGISTRY", "TAG"}, problems[0].Args)
+	assert.Equal(t, []string{"TAG"}, problems[0].UnsetArgs)
+	assert.Contains(t, problems[0].Message(),
+		`FROM at line 4 expands to invalid image ref "gcr.io/my-project/app:": `)
+	assert.Contains(t, problems[0].Message(), "because ARG TAG has no value; pass build_args={'TAG': ...}")
+}
+
+func TestInvalidFromRefsMalformedNoArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM Alpine
+`))
+	require.NoError(t, err)
+
+	problems, err := ast.InvalidFromRefs(nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Empty(t, problems[0].UnsetArgs)
+	assert.Contains(t, problems[0].Message(), `FROM at line 2 expands to invalid image ref "Alpine": `)
+	assert.NotContains(t, problems[0].Message(), "build_args")
+}
+
+func TestInvalidFromRefsValid(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG GO_VERSION=1.20
+FROM golang:${GO_VERSION} AS Builder
+FROM builder AS test
+FROM scratch
+COPY --from=test /out /out
+`))
+	require.NoError(t, err)
+
+	problems, err := ast.InvalidFromRefs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
@@ -0,0 +1,212 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A HEALTHCHECK that runs a tool the image probably doesn't have.
+type HealthcheckWarning struct {
+	Line int
+	Tool string
+}
+
+// Where a healthcheck tool usually comes from.
+type toolSource struct {
+	// Packages that install the tool.
+	packages []string
+
+	// Base images that already include the tool.
+	images []string
+}
+
+// Tools that healthchecks commonly use, but that minimal base images
+// often don't include.
+var healthcheckTools = map[string]toolSource{
+	"curl":              {packages: []string{"curl"}, images: []string{"curl"}},
+	"wget":              {packages: []string{"wget", "busybox"}, images: []string{"alpine", "busybox"}},
+	"nc":                {packages: []string{"netcat", "netcat-openbsd", "netcat-traditional", "nmap-ncat", "busybox"}, images: []string{"alpine", "busybox"}},
+	"jq":                {packages: []string{"jq"}},
+	"pg_isready":        {packages: []string{"postgresql-client"}, images: []string{"postgres"}},
+	"redis-cli":         {packages: []string{"redis-tools", "redis"}, images: []string{"redis"}},
+	"mysqladmin":        {packages: []string{"mysql-client", "default-mysql-client", "mariadb-client"}, images: []string{"mysql", "mariadb"}},
+	"grpc_health_probe": {},
+}
+
+// HealthcheckToolAvailability finds HEALTHCHECKs that run a tool (like
+// curl or wget) that isn't installed by an earlier RUN in the stage.
+//
+// This is best-effort. It only knows about a handful of common tools, and
+// it can't see inside the base image, so it assumes the tool is missing
+// unless the base image's name suggests otherwise. A tool counts as
+// installed if a RUN installs it with a package manager (apt-get, apk,
+// yum, dnf, microdnf, or zypper), or if a RUN or COPY mentions a path to it.
+func (a AST) HealthcheckToolAvailability(buildArgs []string) ([]HealthcheckWarning, error) {
+	var result []HealthcheckWarning
+	installedByStage := map[string]map[string]bool{}
+	var installed map[string]bool
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			installed = map[string]bool{}
+			if prev, ok := installedByStage[strings.ToLower(st.baseName)]; ok {
+				for tool := range prev {
+					installed[tool] = true
+				}
+			} else {
+				for tool, src := range healthcheckTools {
+					if imageProvides(st.baseName, src.images) {
+						installed[tool] = true
+					}
+				}
+			}
+			if inst.Name != "" {
+				installedByStage[strings.ToLower(inst.Name)] = installed
+			}
+
+		case *instructions.RunCommand:
+			for tool := range toolsInstalledBy(runScript(node, inst, st.vars)) {
+				installed[tool] = true
+			}
+
+		case *instructions.CopyCommand:
+			for _, p := range append(append([]string{}, inst.SourcePaths...), inst.DestPath) {
+				if _, ok := healthcheckTools[path.Base(p)]; ok {
+					installed[path.Base(p)] = true
+				}
+			}
+
+		case *instructions.HealthCheckCommand:
+			if installed == nil || inst.Health == nil {
+				return nil
+			}
+			tool := healthcheckTool(inst.Health.Test)
+			if _, ok := healthcheckTools[tool]; ok && !installed[tool] {
+				result = append(result, HealthcheckWarning{Line: node.StartLine, Tool: tool})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// healthcheckTool returns the name of the program a HEALTHCHECK runs.
+func healthcheckTool(test []string) string {
+	if len(test) < 2 {
+		return "" // NONE
+	}
+
+	args := test[1:]
+	if test[0] == "CMD-SHELL" {
+		args = strings.Fields(test[1])
+	}
+	for _, arg := range args {
+		if arg == "exec" || strings.Contains(arg, "=") {
+			continue
+		}
+		return path.Base(arg)
+	}
+	return ""
+}
+
+// imageProvides checks whether the base image's name (without registry or
+// tag) matches one of the images.
+func imageProvides(baseName string, images []string) bool {
+	name := strings.ToLower(baseName)
+	if i := strings.LastIndexAny(name, ":@"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	name = path.Base(name)
+	for _, image := range images {
+		if name == image {
+			return true
+		}
+	}
+	return false
+}
+
+// toolsInstalledBy returns the healthcheck tools that a RUN script installs.
+func toolsInstalledBy(script string) map[string]bool {
+	result := map[string]bool{}
+	for _, segment := range strings.FieldsFunc(script, func(r rune) bool {
+		return r == ';' || r == '&' || r == '|' || r == '\n'
+	}) {
+		words := strings.Fields(segment)
+		for _, word := range words {
+			if _, ok := healthcheckTools[path.Base(word)]; ok && strings.Contains(word, "/") {
+				result[path.Base(word)] = true
+			}
+		}
+
+		for _, pkg := range installedPackages(words) {
+			for tool, src := range healthcheckTools {
+				if pkg == tool {
+					result[tool] = true
+				}
+				for _, p := range src.packages {
+					if pkg == p {
+						result[tool] = true
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// installedPackages returns the packages in a package manager install
+// command, like `apt-get install -y curl` or `apk add --no-cache curl`.
+func installedPackages(words []string) []string {
+	var result []string
+	for _, w := range installArgs(words) {
+		// Strip versions, like curl=7.88.1-10 or curl@edge.
+		if j := strings.IndexAny(w, "=@"); j != -1 {
+			w = w[:j]
+		}
+		result = append(result, w)
+	}
+	return result
+}
+
+// installArgs returns the packages in a package manager install command
+// as written, with any version pins (like curl=7.88.1-10).
+func installArgs(words []string) []string {
+	for i, word := range words {
+		manager := path.Base(word)
+		var installVerb string
+		switch manager {
+		case "apt-get", "apt", "yum", "dnf", "microdnf", "zypper":
+			installVerb = "install"
+		case "apk":
+			installVerb = "add"
+		default:
+			continue
+		}
+
+		var result []string
+		sawVerb := false
+		for _, w := range words[i+1:] {
+			if strings.HasPrefix(w, "-") {
+				continue
+			}
+			if !sawVerb {
+				if w != installVerb {
+					break
+				}
+				sawVerb = true
+				continue
+			}
+			result = append(result, w)
+		}
+		if sawVerb {
+			return result
+		}
+	}
+	return nil
+}
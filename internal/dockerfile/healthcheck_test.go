@@ -0,0 +1,98 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthcheckToolAvailability(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		dockerfile string
+		expected   []HealthcheckWarning
+	}{
+		{
+			name: "missing curl",
+			dockerfile: `
+FROM debian:bookworm-slim
+RUN apt-get update && apt-get install -y ca-certificates
+HEALTHCHECK CMD curl -f http://localhost:8080/healthz || exit 1
+`,
+			expected: []HealthcheckWarning{{Line: 4, Tool: "curl"}},
+		},
+		{
+			name: "installed with apt-get",
+			dockerfile: `
+FROM debian:bookworm-slim
+RUN apt-get update && \
+    apt-get -y install --no-install-recommends curl=7.88.1-10 && \
+    rm -rf /var/lib/apt/lists/*
+HEALTHCHECK CMD curl -f http://localhost:8080/healthz || exit 1
+`,
+		},
+		{
+			name: "installed with apk, exec form",
+			dockerfile: `
+FROM alpine:3.19
+RUN apk add --no-cache curl
+HEALTHCHECK CMD ["curl", "-f", "http://localhost:8080/healthz"]
+`,
+		},
+		{
+			name: "wget from busybox",
+			dockerfile: `
+FROM alpine:3.19
+HEALTHCHECK CMD wget -q -O - http://localhost:8080/healthz
+`,
+		},
+		{
+			name: "postgres image",
+			dockerfile: `
+FROM postgres:16
+HEALTHCHECK CMD pg_isready -U postgres
+`,
+		},
+		{
+			name: "downloaded binary",
+			dockerfile: `
+FROM gcr.io/distroless/base
+COPY --from=ghcr.io/grpc-ecosystem/grpc-health-probe:v0.4.24 /ko-app/grpc-health-probe /bin/grpc_health_probe
+HEALTHCHECK CMD ["/bin/grpc_health_probe", "-addr=:50051"]
+`,
+		},
+		{
+			name: "inherited from earlier stage",
+			dockerfile: `
+FROM debian:bookworm-slim AS base
+RUN apt-get update && apt-get install -y curl
+
+FROM base
+HEALTHCHECK CMD curl -f http://localhost:8080/healthz
+
+FROM debian:bookworm-slim
+HEALTHCHECK CMD curl -f http://localhost:8080/healthz
+`,
+			expected: []HealthcheckWarning{{Line: 9, Tool: "curl"}},
+		},
+		{
+			name: "unknown tool",
+			dockerfile: `
+FROM scratch
+COPY server /server
+HEALTHCHECK CMD ["/server", "healthcheck"]
+HEALTHCHECK NONE
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ast, err := ParseAST(Dockerfile(tc.dockerfile))
+			require.NoError(t, err)
+
+			warnings, err := ast.HealthcheckToolAvailability(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, warnings)
+		})
+	}
+}
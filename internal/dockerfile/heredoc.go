@@ -0,0 +1,46 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Heredocs (`RUN <<EOF` and `COPY <<EOF /dest`) put a body after the
+// instruction line. The parser keeps the instruction line in the node's
+// arguments and the bodies in node.Heredocs, so:
+//
+//   - A heredoc COPY or ADD source is inline content, not a path in the
+//     build context. instructions.CopyCommand.SourcePaths leaves it out,
+//     so contextSources does too.
+//   - A heredoc RUN's body is part of its script (see runScript).
+//   - The node's lines run through the end of the last body (see
+//     instructionEndLine).
+//   - Print writes the instruction line once, then each body (see
+//     appendHeredocs).
+
+// runScript returns the script that a RUN executes: its command line,
+// then the body of each heredoc, with ARGs and ENVs expanded, since
+// they're in the environment when the script runs.
+func runScript(node *parser.Node, run *instructions.RunCommand, vars *stageVars) string {
+	script := strings.Join(run.CmdLine, " ")
+	for _, h := range node.Heredocs {
+		script += "\n" + h.Content
+	}
+	return vars.expand(script)
+}
+
+// instructionEndLine returns the last line of the instruction itself,
+// before any heredoc bodies.
+func instructionEndLine(node *parser.Node) int {
+	end := node.EndLine
+	for _, h := range node.Heredocs {
+		// The body, then the delimiter.
+		end -= strings.Count(h.Content, "\n") + 1
+	}
+	if end < node.StartLine {
+		return node.StartLine
+	}
+	return end
+}
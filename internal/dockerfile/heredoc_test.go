@@ -0,0 +1,145 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+// A Dockerfile that uses heredocs everywhere they're allowed.
+const heredocDockerfile = `# syntax=docker/dockerfile:1
+FROM golang:1.21 AS builder
+ARG CACHE=/root/.cache/go-build
+COPY <<EOF /src/go.mod
+module example.com/app
+EOF
+COPY <<a.go <<b.go cmd/ /src/
+package a
+a.go
+package b
+b.go
+RUN --mount=type=cache,target=/root/.cache/go-build <<EOF
+go build ./... \
+
+rm -rf $CACHE
+EOF
+
+FROM alpine
+COPY --from=builder /out/app /app
+RUN python3 <<"EOF" > /greeting
+print("FROM python")
+EOF
+`
+
+func TestHeredocPrint(t *testing.T) {
+	ast, err := ParseAST(heredocDockerfile)
+	require.NoError(t, err)
+
+	// Each body is printed once, after its instruction.
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `# syntax=docker/dockerfile:1
+FROM golang:1.21 AS builder
+ARG CACHE=/root/.cache/go-build
+COPY <<EOF /src/go.mod
+module example.com/app
+EOF
+COPY <<a.go <<b.go cmd/ /src/
+package a
+a.go
+package b
+b.go
+RUN --mount=type=cache,target=/root/.cache/go-build <<EOF
+go build ./... \
+
+rm -rf $CACHE
+EOF
+
+FROM alpine
+COPY --from=builder /out/app /app
+RUN python3 <<"EOF" > /greeting
+print("FROM python")
+EOF
+`, string(df))
+
+	// And printing is stable.
+	reparsed, err := ParseAST(df)
+	require.NoError(t, err)
+	df2, err := reparsed.Print()
+	require.NoError(t, err)
+	assert.Equal(t, df, df2)
+}
+
+func TestHeredocInjectImageDigest(t *testing.T) {
+	ref := container.MustParseNamedTagged("alpine:deadbeef")
+	df, modified, err := InjectImageDigest(heredocDockerfile, container.NameSelector(ref), ref, nil)
+	require.NoError(t, err)
+	assert.True(t, modified)
+	assert.Contains(t, string(df), "\nFROM alpine:deadbeef\n")
+
+	// The FROM in the heredoc body isn't an image.
+	assert.Contains(t, string(df), "\nprint(\"FROM python\")\nEOF\n")
+}
+
+func TestHeredocContextPaths(t *testing.T) {
+	ast, err := ParseAST(heredocDockerfile)
+	require.NoError(t, err)
+
+	// Heredoc sources are inline, so only cmd/ comes from the context.
+	deps, err := ast.StageContextDependencies(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{0: {"cmd"}, 1: {}}, deps)
+
+	steps, err := ast.CacheImpact("EOF")
+	require.NoError(t, err)
+	assert.Empty(t, steps)
+
+	steps, err = ast.CacheImpact("cmd/main.go")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+	assert.Equal(t, 7, steps[0].Line)
+	assert.True(t, steps[0].Trigger)
+	assert.Equal(t, 12, steps[1].Line)
+}
+
+func TestHeredocRunScript(t *testing.T) {
+	ast, err := ParseAST(heredocDockerfile)
+	require.NoError(t, err)
+
+	// The cache is cleared in the body, with the ARG expanded.
+	warnings, err := ast.IneffectiveCacheMounts(nil)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, 12, warnings[0].Line)
+
+	// The continuation in the body is part of the script.
+	lines, err := ast.ContinuationWarnings()
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+}
+
+func TestHeredocSemanticComparison(t *testing.T) {
+	normalized := func(body string) string {
+		ast, err := ParseAST(Dockerfile("FROM alpine\nRUN <<EOF\n" + body + "EOF\n"))
+		require.NoError(t, err)
+		return normalizedInstruction(ast.result.AST.Children[1])
+	}
+
+	// RUNs with the same instruction line but different bodies differ.
+	assert.Equal(t, normalized("apk add curl\n"), normalized("apk add curl\n"))
+	assert.NotEqual(t, normalized("apk add curl\n"), normalized("apk add git\n"))
+}
+
+func TestInstructionEndLine(t *testing.T) {
+	ast, err := ParseAST(heredocDockerfile)
+	require.NoError(t, err)
+
+	var ends []int
+	for _, node := range ast.result.AST.Children {
+		ends = append(ends, instructionEndLine(node))
+	}
+	assert.Equal(t, []int{2, 3, 4, 7, 12, 18, 19, 20}, ends)
+}
@@ -0,0 +1,80 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Nested includes deeper than this are an error.
+const maxIncludeDepth = 10
+
+// A line like `# tilt:include dockerfiles/base-deps.inc`.
+var includeRE = regexp.MustCompile(`^\s*#\s*tilt:include\s+(\S+)\s*$`)
+
+// ResolveIncludes replaces each `# tilt:include PATH` line in the
+// Dockerfile with the contents of the file at PATH, so that Dockerfiles
+// can share snippets.
+//
+// Relative paths are relative to the directory of the file with the
+// include. Included files can include other files, up to a bounded
+// depth, but not themselves.
+//
+// path is the path of the Dockerfile, for resolving relative paths and
+// reporting errors. read is called for each included file, so that the
+// caller can watch it.
+func ResolveIncludes(df Dockerfile, path string, read func(path string) ([]byte, error)) (Dockerfile, error) {
+	result, err := resolveIncludes(string(df), path, read, []string{path})
+	if err != nil {
+		return "", err
+	}
+	return Dockerfile(result), nil
+}
+
+func resolveIncludes(contents, path string, read func(path string) ([]byte, error), stack []string) (string, error) {
+	if !strings.Contains(contents, "tilt:include") {
+		return contents, nil
+	}
+
+	lines := strings.SplitAfter(contents, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		match := includeRE.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if match == nil {
+			sb.WriteString(line)
+			continue
+		}
+
+		included := match[1]
+		if !filepath.IsAbs(included) {
+			included = filepath.Join(filepath.Dir(path), included)
+		}
+		wrap := func(err error) error {
+			return fmt.Errorf("%s:%d: including %s: %v", path, i+1, match[1], err)
+		}
+
+		for _, p := range stack {
+			if p == included {
+				return "", wrap(fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), included))
+			}
+		}
+		if len(stack) > maxIncludeDepth {
+			return "", wrap(fmt.Errorf("includes are nested more than %d deep", maxIncludeDepth))
+		}
+
+		bs, err := read(included)
+		if err != nil {
+			return "", wrap(err)
+		}
+		resolved, err := resolveIncludes(string(bs), included, read, append(stack, included))
+		if err != nil {
+			return "", wrap(err)
+		}
+		sb.WriteString(resolved)
+		if resolved != "" && !strings.HasSuffix(resolved, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
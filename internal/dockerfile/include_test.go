@@ -0,0 +1,72 @@
+package dockerfile
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIncludes map[string]string
+
+func (f fakeIncludes) read(path string) ([]byte, error) {
+	contents, ok := f[path]
+	if !ok {
+		return nil, fmt.Errorf("open %s: %v", path, os.ErrNotExist)
+	}
+	return []byte(contents), nil
+}
+
+func TestResolveIncludes(t *testing.T) {
+	files := fakeIncludes{
+		"/src/dockerfiles/base-deps.inc": "RUN apk add curl\n# tilt:include tools.inc\n",
+		"/src/dockerfiles/tools.inc":     "RUN apk add git",
+	}
+	df, err := ResolveIncludes(Dockerfile(`FROM alpine
+# tilt:include dockerfiles/base-deps.inc
+COPY . /app
+`), "/src/Dockerfile", files.read)
+	require.NoError(t, err)
+	assert.Equal(t, `FROM alpine
+RUN apk add curl
+RUN apk add git
+COPY . /app
+`, string(df))
+}
+
+func TestResolveIncludesNone(t *testing.T) {
+	df, err := ResolveIncludes(Dockerfile("FROM alpine\n# just a comment\n"), "/src/Dockerfile", fakeIncludes{}.read)
+	require.NoError(t, err)
+	assert.Equal(t, "FROM alpine\n# just a comment\n", string(df))
+}
+
+func TestResolveIncludesMissing(t *testing.T) {
+	files := fakeIncludes{
+		"/src/a.inc": "# tilt:include b.inc\n",
+	}
+	_, err := ResolveIncludes(Dockerfile("FROM alpine\n# tilt:include a.inc\n"), "/src/Dockerfile", files.read)
+	assert.EqualError(t, err, "/src/Dockerfile:2: including a.inc: "+
+		"/src/a.inc:1: including b.inc: open /src/b.inc: file does not exist")
+}
+
+func TestResolveIncludesCycle(t *testing.T) {
+	files := fakeIncludes{
+		"/src/a.inc": "RUN a\n# tilt:include b.inc\n",
+		"/src/b.inc": "# tilt:include a.inc\n",
+	}
+	_, err := ResolveIncludes(Dockerfile("FROM alpine\n# tilt:include a.inc\n"), "/src/Dockerfile", files.read)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle: /src/Dockerfile -> /src/a.inc -> /src/b.inc -> /src/a.inc")
+}
+
+func TestResolveIncludesTooDeep(t *testing.T) {
+	files := fakeIncludes{}
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("/src/%d.inc", i)] = fmt.Sprintf("# tilt:include %d.inc\n", i+1)
+	}
+	_, err := ResolveIncludes(Dockerfile("# tilt:include 0.inc\n"), "/src/Dockerfile", files.read)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "includes are nested more than 10 deep")
+}
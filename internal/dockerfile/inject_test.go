@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/tilt-dev/tilt/internal/container"
 )
@@ -205,3 +206,193 @@ ADD . .
 `, string(newDf))
 	}
 }
+
+func TestInjectSkipsStageNames(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21 AS builder
+FROM builder
+COPY --from=builder /app /app
+`)
+	ref := container.MustParseNamedTagged("builder:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.False(t, modified)
+		assert.Equal(t, df, newDf)
+	}
+}
+
+func TestInjectSkipsStageNamedLikeImage(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21 AS golang
+RUN go build -o /out/app .
+
+FROM alpine
+COPY --from=golang /out/app /app
+`)
+	ref := container.MustParseNamedTagged("golang:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, modified)
+		assert.Equal(t, `
+FROM golang:deadbeef AS golang
+RUN go build -o /out/app .
+
+FROM alpine
+COPY --from=golang /out/app /app
+`, string(newDf))
+	}
+}
+
+func TestInjectSkipsStageNamesCaseInsensitive(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21 AS Builder
+FROM alpine
+COPY --from=builder /app /app
+COPY --from=BUILDER /lib /lib
+`)
+	ref := container.MustParseNamedTagged("builder:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.False(t, modified)
+		assert.Equal(t, df, newDf)
+	}
+}
+
+func TestInjectCopyFromLaterStageName(t *testing.T) {
+	// A stage name only counts once it's declared, so an earlier
+	// COPY --from with the same name is an image.
+	df := Dockerfile(`
+FROM alpine
+COPY --from=builder /app /app
+
+FROM golang:1.21 AS builder
+`)
+	ref := container.MustParseNamedTagged("builder:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, modified)
+		assert.Equal(t, `
+FROM alpine
+COPY --from=builder:deadbeef /app /app
+
+FROM golang:1.21 AS builder
+`, string(newDf))
+	}
+}
+
+func TestInjectReport(t *testing.T) {
+	df := Dockerfile(`
+FROM gcr.io/windmill/foo AS base
+ADD . .
+
+FROM golang:1.10
+COPY --from=gcr.io/windmill/foo /src /src
+`)
+	ref := container.MustParseNamedTagged("gcr.io/windmill/foo:deadbeef")
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	subs, err := ast.InjectImageDigestReport(container.NameSelector(ref), ref, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []Substitution{
+		{Line: 2, Before: "FROM gcr.io/windmill/foo AS base", After: "FROM gcr.io/windmill/foo:deadbeef AS base"},
+		{Line: 6, Before: "COPY --from=gcr.io/windmill/foo /src /src", After: "COPY --from=gcr.io/windmill/foo:deadbeef /src /src"},
+	}, subs)
+}
+
+func TestInjectReportNoMatch(t *testing.T) {
+	ast, err := ParseAST(Dockerfile("FROM golang:1.10\n"))
+	require.NoError(t, err)
+
+	ref := container.MustParseNamedTagged("gcr.io/windmill/foo:deadbeef")
+	subs, err := ast.InjectImageDigestReport(container.NameSelector(ref), ref, nil)
+	require.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
+func TestInjectRunMountFrom(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21
+RUN --mount=type=bind,from=myorg/toolchain,target=/tools make
+`)
+	ref := container.MustParseNamedTagged("myorg/toolchain:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, modified)
+		assert.Equal(t, `
+FROM golang:1.21
+RUN --mount=type=bind,from=myorg/toolchain:deadbeef,target=/tools make
+`, string(newDf))
+	}
+}
+
+func TestInjectRunMultipleMounts(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21
+RUN --mount=from=myorg/toolchain,target=/tools,readonly --mount=type=cache,target=/root/.cache,from=myorg/toolchain,source=/cache,sharing=locked --mount=type=bind,from=myorg/other,target=/other make
+`)
+	ref := container.MustParseNamedTagged("myorg/toolchain:deadbeef")
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	subs, err := ast.InjectImageDigestReport(container.NameSelector(ref), ref, nil)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, 3, subs[0].Line)
+
+	newDf, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM golang:1.21
+RUN --mount=from=myorg/toolchain:deadbeef,target=/tools,readonly --mount=type=cache,target=/root/.cache,from=myorg/toolchain:deadbeef,source=/cache,sharing=locked --mount=type=bind,from=myorg/other,target=/other make
+`, string(newDf))
+}
+
+func TestInjectRunMountWithHeredoc(t *testing.T) {
+	df := Dockerfile(`
+FROM golang:1.21
+RUN --mount=type=bind,from=myorg/toolchain,target=/tools <<EOF
+/tools/configure
+make
+EOF
+`)
+	ref := container.MustParseNamedTagged("myorg/toolchain:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, modified)
+		assert.Equal(t, `
+FROM golang:1.21
+RUN --mount=type=bind,from=myorg/toolchain:deadbeef,target=/tools <<EOF
+/tools/configure
+make
+EOF
+`, string(newDf))
+	}
+}
+
+func TestInjectRunMountSkipsStagesAndOtherTypes(t *testing.T) {
+	df := Dockerfile(`
+FROM myorg/toolchain AS toolchain
+FROM golang:1.21
+RUN --mount=type=bind,from=toolchain,target=/tools make
+RUN --mount=type=secret,id=toolchain,from=myorg/toolchain make
+`)
+	ref := container.MustParseNamedTagged("toolchain:deadbeef")
+	newDf, modified, err := InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.False(t, modified)
+		assert.Equal(t, df, newDf)
+	}
+
+	ref = container.MustParseNamedTagged("myorg/toolchain:deadbeef")
+	newDf, modified, err = InjectImageDigest(df, container.NameSelector(ref), ref, nil)
+	if assert.NoError(t, err) {
+		assert.True(t, modified)
+		assert.Equal(t, `
+FROM myorg/toolchain:deadbeef AS toolchain
+FROM golang:1.21
+RUN --mount=type=bind,from=toolchain,target=/tools make
+RUN --mount=type=secret,id=toolchain,from=myorg/toolchain make
+`, string(newDf))
+	}
+}
@@ -0,0 +1,250 @@
+package dockerfile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/pkg/errors"
+)
+
+// ErrStaleHandle is returned when an InstructionHandle is used after an
+// instruction was added to or removed from its Dockerfile.
+var ErrStaleHandle = errors.New("instruction handle is stale: the Dockerfile's instructions changed since it was found")
+
+// A handle to one instruction in an AST, for tools that rewrite Dockerfiles
+// without munging strings.
+//
+// Every change is checked by re-parsing the instruction, and is rolled back
+// if the instruction no longer parses. Adding or removing an instruction
+// (with InsertAfter or Remove, or any other method that restructures the
+// AST) invalidates every handle; find the instructions again to keep going.
+type InstructionHandle struct {
+	ast        *AST
+	node       *parser.Node
+	generation int
+}
+
+// FindInstructions returns handles to the instructions with the given
+// keyword (e.g., "run"; case doesn't matter), in file order.
+func (a *AST) FindInstructions(cmd string) []InstructionHandle {
+	var result []InstructionHandle
+	for _, node := range a.result.AST.Children {
+		if strings.EqualFold(node.Value, cmd) {
+			result = append(result, InstructionHandle{ast: a, node: node, generation: a.generation})
+		}
+	}
+	return result
+}
+
+// Valid reports whether the handle can still be used.
+func (h InstructionHandle) Valid() bool {
+	return h.ast != nil && h.generation == h.ast.generation
+}
+
+// Args returns the arguments of the instruction, without its flags.
+//
+// In the shell form, the whole command is one argument, e.g.,
+// `RUN pip install flask` has the args ["pip install flask"]. In the exec
+// form, each element is an argument. LABEL alternates keys and values.
+//
+// Returns nil if the handle is stale.
+func (h InstructionHandle) Args() []string {
+	if !h.Valid() {
+		return nil
+	}
+	var result []string
+	for n := h.node.Next; n != nil; n = n.Next {
+		result = append(result, n.Value)
+	}
+	return result
+}
+
+// SetArg replaces argument i.
+func (h InstructionHandle) SetArg(i int, v string) error {
+	if !h.Valid() {
+		return ErrStaleHandle
+	}
+
+	n := h.node.Next
+	for j := 0; j < i && n != nil; j++ {
+		n = n.Next
+	}
+	if i < 0 || n == nil {
+		return fmt.Errorf("%s: no argument %d", h.describe(), i)
+	}
+
+	old := n.Value
+	n.Value = v
+	err := h.validate()
+	if err != nil {
+		n.Value = old
+		return err
+	}
+	return nil
+}
+
+// Flags returns the flags of the instruction by name, without dashes (e.g.,
+// {"mount": "type=cache,target=/root/.cache"}). A flag without a value,
+// like `--link`, maps to "". If a flag is repeated, the last value wins.
+//
+// Returns nil if the handle is stale.
+func (h InstructionHandle) Flags() map[string]string {
+	if !h.Valid() {
+		return nil
+	}
+	result := make(map[string]string, len(h.node.Flags))
+	for _, f := range h.node.Flags {
+		k, v, _ := strings.Cut(strings.TrimPrefix(f, "--"), "=")
+		result[k] = v
+	}
+	return result
+}
+
+// SetFlag sets a flag, replacing every existing use of it. An empty value
+// writes the flag without one, like `--link`.
+func (h InstructionHandle) SetFlag(k, v string) error {
+	if !h.Valid() {
+		return ErrStaleHandle
+	}
+
+	old := h.node.Flags
+	var flags []string
+	replaced := false
+	for _, f := range old {
+		name, _, _ := strings.Cut(strings.TrimPrefix(f, "--"), "=")
+		if name != k {
+			flags = append(flags, f)
+		} else if !replaced {
+			flags = append(flags, formatFlag(k, v))
+			replaced = true
+		}
+	}
+	if !replaced {
+		flags = append(flags, formatFlag(k, v))
+	}
+	return h.setFlags(flags)
+}
+
+// AddFlag adds a flag, keeping any existing uses of it. For flags that can
+// be repeated, like `--mount`.
+func (h InstructionHandle) AddFlag(k, v string) error {
+	if !h.Valid() {
+		return ErrStaleHandle
+	}
+	flags := append(append([]string(nil), h.node.Flags...), formatFlag(k, v))
+	return h.setFlags(flags)
+}
+
+func (h InstructionHandle) setFlags(flags []string) error {
+	old := h.node.Flags
+	h.node.Flags = flags
+	err := h.validate()
+	if err != nil {
+		h.node.Flags = old
+		return err
+	}
+	return nil
+}
+
+// InsertAfter parses one instruction and adds it after this one.
+//
+// Invalidates every handle.
+func (h InstructionHandle) InsertAfter(raw string) error {
+	if !h.Valid() {
+		return ErrStaleHandle
+	}
+
+	i := h.index()
+	if i == -1 {
+		return ErrStaleHandle
+	}
+
+	node, err := h.ast.parseInstruction(raw)
+	if err != nil {
+		return fmt.Errorf("inserting after %s: %v", h.describe(), err)
+	}
+	h.ast.insertNode(i+1, node)
+	return nil
+}
+
+// Remove removes the instruction.
+//
+// Invalidates every handle.
+func (h InstructionHandle) Remove() error {
+	if !h.Valid() {
+		return ErrStaleHandle
+	}
+
+	i := h.index()
+	if i == -1 {
+		return ErrStaleHandle
+	}
+	h.ast.removeNode(i)
+	return nil
+}
+
+func (h InstructionHandle) index() int {
+	for i, node := range h.ast.result.AST.Children {
+		if node == h.node {
+			return i
+		}
+	}
+	return -1
+}
+
+func (h InstructionHandle) describe() string {
+	return fmt.Sprintf("%s on line %d", strings.ToUpper(h.node.Value), h.node.StartLine)
+}
+
+// validate checks that the instruction still parses as it would be
+// printed.
+func (h InstructionHandle) validate() error {
+	buf := bytes.NewBuffer(nil)
+	_, err := h.ast.printNode(h.node, buf)
+	if err != nil {
+		return err
+	}
+
+	node, err := h.ast.parseInstruction(buf.String())
+	if err != nil {
+		return fmt.Errorf("%s: %v", h.describe(), err)
+	}
+	if !strings.EqualFold(node.Value, h.node.Value) {
+		return fmt.Errorf("%s: would be printed as %s", h.describe(), strings.ToUpper(node.Value))
+	}
+	return nil
+}
+
+// parseInstruction parses a single instruction, with the escape character
+// of the Dockerfile.
+func (a *AST) parseInstruction(raw string) (*parser.Node, error) {
+	src := raw
+	if a.result.EscapeToken != '\\' {
+		src = fmt.Sprintf("# escape=%c\n%s", a.result.EscapeToken, raw)
+	}
+
+	result, err := parser.Parse(strings.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.AST.Children) != 1 {
+		return nil, fmt.Errorf("expected one instruction, got %d", len(result.AST.Children))
+	}
+
+	node := result.AST.Children[0]
+	_, err = instructions.ParseInstruction(node)
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func formatFlag(k, v string) string {
+	if v == "" {
+		return "--" + k
+	}
+	return fmt.Sprintf("--%s=%s", k, v)
+}
@@ -0,0 +1,183 @@
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstructionHandlesAddPipCacheMounts(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM python:3.11
+WORKDIR /app
+COPY requirements.txt .
+RUN pip install -r requirements.txt
+RUN --mount=type=secret,id=netrc pip install private-pkg
+RUN apt-get update
+COPY . .
+`))
+	require.NoError(t, err)
+
+	for _, h := range ast.FindInstructions("run") {
+		args := h.Args()
+		if len(args) == 0 || !strings.Contains(args[0], "pip install") {
+			continue
+		}
+		require.NoError(t, h.AddFlag("mount", "type=cache,target=/root/.cache/pip"))
+	}
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM python:3.11
+WORKDIR /app
+COPY requirements.txt .
+RUN --mount=type=cache,target=/root/.cache/pip pip install -r requirements.txt
+RUN --mount=type=secret,id=netrc --mount=type=cache,target=/root/.cache/pip pip install private-pkg
+RUN apt-get update
+COPY . .
+`, string(df))
+}
+
+func TestInstructionHandleArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+LABEL a=1 b=2
+CMD ["server", "--port", "8080"]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "1", "b", "2"}, ast.FindInstructions("LABEL")[0].Args())
+
+	cmd := ast.FindInstructions("cmd")[0]
+	assert.Equal(t, []string{"server", "--port", "8080"}, cmd.Args())
+	require.NoError(t, cmd.SetArg(2, "9090"))
+	assert.EqualError(t, cmd.SetArg(3, "x"), "CMD on line 4: no argument 3")
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+LABEL a=1 b=2
+CMD ["server", "--port", "9090"]
+`, string(df))
+}
+
+func TestInstructionHandleSetFlag(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --chown=1000 --link app /app
+`))
+	require.NoError(t, err)
+
+	h := ast.FindInstructions("copy")[0]
+	assert.Equal(t, map[string]string{"chown": "1000", "link": ""}, h.Flags())
+	require.NoError(t, h.SetFlag("chown", "app:app"))
+	require.NoError(t, h.SetFlag("chmod", "755"))
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+COPY --chown=app:app --link --chmod=755 app /app
+`, string(df))
+}
+
+func TestInstructionHandleRejectsInvalidChange(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY app /app
+`))
+	require.NoError(t, err)
+
+	h := ast.FindInstructions("copy")[0]
+	err = h.SetFlag("bogus", "1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "COPY on line 3")
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, "\nFROM alpine\nCOPY app /app\n", string(df))
+}
+
+func TestInstructionHandleInsertAfter(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`# syntax=docker/dockerfile:1
+FROM alpine
+RUN apk add curl
+
+COPY app /app
+`))
+	require.NoError(t, err)
+
+	h := ast.FindInstructions("run")[0]
+	require.NoError(t, h.InsertAfter("RUN curl --version"))
+	assert.False(t, h.Valid())
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `# syntax=docker/dockerfile:1
+FROM alpine
+RUN apk add curl
+RUN curl --version
+
+COPY app /app
+`, string(df))
+
+	runs := ast.FindInstructions("run")
+	require.Len(t, runs, 2)
+	assert.Equal(t, []string{"curl --version"}, runs[1].Args())
+}
+
+func TestInstructionHandleInsertAfterInvalid(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN true
+`))
+	require.NoError(t, err)
+
+	h := ast.FindInstructions("run")[0]
+	assert.Error(t, h.InsertAfter("RUN a\nRUN b"))
+	assert.Error(t, h.InsertAfter("EXPOSE"))
+	assert.True(t, h.Valid())
+}
+
+func TestInstructionHandleRemove(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN apk add curl
+RUN echo debug
+COPY app /app
+`))
+	require.NoError(t, err)
+
+	copyHandle := ast.FindInstructions("copy")[0]
+	require.NoError(t, ast.FindInstructions("run")[1].Remove())
+
+	assert.Equal(t, ErrStaleHandle, copyHandle.SetArg(0, "other"))
+	assert.Equal(t, ErrStaleHandle, copyHandle.Remove())
+	assert.Nil(t, copyHandle.Args())
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+RUN apk add curl
+COPY app /app
+`, string(df))
+}
+
+func TestInstructionHandleStaleAfterOtherMutations(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN true
+RUN echo hi
+`))
+	require.NoError(t, err)
+
+	h := ast.FindInstructions("run")[1]
+	_, err = ast.RemoveNoOps()
+	require.NoError(t, err)
+	assert.Equal(t, ErrStaleHandle, h.SetFlag("network", "none"))
+}
@@ -0,0 +1,42 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A reference to one instruction in a Dockerfile.
+type InstructionRef struct {
+	// The index of the stage the instruction belongs to, starting at 0.
+	// ARGs before the first FROM have a StageIndex of -1.
+	StageIndex int
+
+	// The instruction keyword, in upper case (e.g., "RUN").
+	InstructionType string
+
+	// The line the instruction starts on.
+	Line int
+}
+
+// InstructionIndex lists every instruction in the Dockerfile, in file
+// order, with the stage it belongs to.
+//
+// Intended as a join key for build tooling, e.g., to match BuildKit
+// step timings back to the instruction that produced them. The result
+// only depends on the Dockerfile's text, so it's stable across runs.
+func (a AST) InstructionIndex() ([]InstructionRef, error) {
+	result := []InstructionRef{}
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		result = append(result, InstructionRef{
+			StageIndex:      st.stageIndex,
+			InstructionType: strings.ToUpper(node.Value),
+			Line:            node.StartLine,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstructionIndex(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG GO_VERSION=1.21
+FROM golang:${GO_VERSION} AS builder
+WORKDIR /src
+RUN go mod download && \
+  go build ./...
+
+FROM alpine
+copy --from=builder /src/app /app
+ENTRYPOINT ["/app"]
+`))
+	require.NoError(t, err)
+
+	index, err := ast.InstructionIndex()
+	require.NoError(t, err)
+	assert.Equal(t, []InstructionRef{
+		{StageIndex: -1, InstructionType: "ARG", Line: 2},
+		{StageIndex: 0, InstructionType: "FROM", Line: 3},
+		{StageIndex: 0, InstructionType: "WORKDIR", Line: 4},
+		{StageIndex: 0, InstructionType: "RUN", Line: 5},
+		{StageIndex: 1, InstructionType: "FROM", Line: 8},
+		{StageIndex: 1, InstructionType: "COPY", Line: 9},
+		{StageIndex: 1, InstructionType: "ENTRYPOINT", Line: 10},
+	}, index)
+}
@@ -0,0 +1,74 @@
+package dockerfile
+
+import (
+	"sort"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A LABEL whose value is set from build ARGs.
+type LabelBinding struct {
+	Line      int
+	Stage     int
+	StageName string
+
+	Key string
+
+	// The value as written, e.g., "$VERSION".
+	RawValue string
+
+	// The value with ARG and ENV references expanded.
+	Value string
+
+	// The ARGs that the value references, sorted.
+	Args []string
+}
+
+// LabelArgBindings finds the LABELs whose values reference build ARGs,
+// so that provenance tooling can tell which labels are set at build time.
+//
+// Only references to an ARG in scope count. A reference to an ENV (even
+// one set from an ARG) or to an undeclared variable doesn't.
+func (a AST) LabelArgBindings(buildArgs []string) ([]LabelBinding, error) {
+	var result []LabelBinding
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		label, ok := inst.(*instructions.LabelCommand)
+		if !ok {
+			return nil
+		}
+
+		for _, kv := range label.Labels {
+			_, matches, err := st.vars.shlex.ProcessWordWithMatches(kv.Value, st.vars.scope())
+			if err != nil {
+				continue
+			}
+
+			var args []string
+			for name := range matches {
+				if st.vars.isArg(name) {
+					args = append(args, name)
+				}
+			}
+			if len(args) == 0 {
+				continue
+			}
+			sort.Strings(args)
+
+			result = append(result, LabelBinding{
+				Line:      node.StartLine,
+				Stage:     st.stageIndex,
+				StageName: st.stageName,
+				Key:       kv.Key,
+				RawValue:  kv.Value,
+				Value:     st.vars.expand(kv.Value),
+				Args:      args,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
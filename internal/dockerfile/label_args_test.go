@@ -0,0 +1,48 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelArgBindings(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG VERSION=dev
+FROM alpine AS base
+ARG VERSION
+ARG GIT_SHA
+ENV BUILD_ENV=prod
+LABEL org.opencontainers.image.version=$VERSION \
+      org.opencontainers.image.revision="${GIT_SHA}" \
+      maintainer="team@example.com" \
+      env=$BUILD_ENV \
+      build="${VERSION}-${GIT_SHA}"
+
+FROM base
+ENV VERSION=shadowed
+LABEL version=$VERSION undeclared=$NOPE
+`))
+	require.NoError(t, err)
+
+	bindings, err := ast.LabelArgBindings([]string{"GIT_SHA=abc123"})
+	require.NoError(t, err)
+	assert.Equal(t, []LabelBinding{
+		{
+			Line: 7, Stage: 0, StageName: "base",
+			Key: "org.opencontainers.image.version", RawValue: "$VERSION", Value: "dev",
+			Args: []string{"VERSION"},
+		},
+		{
+			Line: 7, Stage: 0, StageName: "base",
+			Key: "org.opencontainers.image.revision", RawValue: `"${GIT_SHA}"`, Value: "abc123",
+			Args: []string{"GIT_SHA"},
+		},
+		{
+			Line: 7, Stage: 0, StageName: "base",
+			Key: "build", RawValue: `"${VERSION}-${GIT_SHA}"`, Value: "dev-abc123",
+			Args: []string{"GIT_SHA", "VERSION"},
+		},
+	}, bindings)
+}
@@ -0,0 +1,84 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// MergeCompatibleCopies combines consecutive COPY instructions that copy
+// into the same directory with the same flags (including --from), e.g.,
+//
+//	COPY --from=builder a /dest/
+//	COPY --from=builder b /dest/
+//
+// becomes
+//
+//	COPY --from=builder a b /dest/
+//
+// Merging is conservative. The destination must be written identically in
+// both instructions and end with a slash, so that it's a directory either
+// way. Instructions in JSON form, with heredocs, or with a comment in
+// between are left alone.
+//
+// Returns the number of instructions merged away.
+func (a *AST) MergeCompatibleCopies() (int, error) {
+	count := 0
+	children := a.result.AST.Children
+	for i := 0; i+1 < len(children); {
+		if !canMergeCopies(children[i], children[i+1]) {
+			i++
+			continue
+		}
+
+		mergeCopySources(children[i], children[i+1])
+		a.removeNode(i + 1)
+		children = a.result.AST.Children
+		count++
+	}
+	return count, nil
+}
+
+func canMergeCopies(first, second *parser.Node) bool {
+	if !isMergeableCopy(first) || !isMergeableCopy(second) {
+		return false
+	}
+	if len(second.PrevComment) > 0 {
+		return false
+	}
+	if strings.Join(first.Flags, " ") != strings.Join(second.Flags, " ") {
+		return false
+	}
+
+	dest := copyDestNode(first).Value
+	return strings.HasSuffix(dest, "/") && dest == copyDestNode(second).Value
+}
+
+// A COPY with at least one source, written in the plain form.
+func isMergeableCopy(node *parser.Node) bool {
+	if strings.ToLower(node.Value) != command.Copy {
+		return false
+	}
+	if node.Attributes["json"] || len(node.Heredocs) > 0 {
+		return false
+	}
+	return node.Next != nil && node.Next.Next != nil
+}
+
+// mergeCopySources adds the sources of second to first, before its
+// destination.
+func mergeCopySources(first, second *parser.Node) {
+	lastSrc := first.Next
+	for lastSrc.Next.Next != nil {
+		lastSrc = lastSrc.Next
+	}
+	dest := lastSrc.Next
+
+	src := second.Next
+	lastSrc.Next = src
+	for src.Next.Next != nil {
+		src = src.Next
+	}
+	src.Next = dest
+}
@@ -0,0 +1,66 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeCompatibleCopies(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --from=builder /out/app /usr/bin/
+COPY --from=builder /out/tool /out/helper /usr/bin/
+
+COPY --from=builder /out/config /usr/bin/
+RUN app --version
+`))
+	require.NoError(t, err)
+
+	count, err := ast.MergeCompatibleCopies()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	actual, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+COPY --from=builder /out/app /out/tool /out/helper /out/config /usr/bin/
+
+RUN app --version
+`, string(actual))
+
+	// The result parses back to the same Dockerfile.
+	reparsed, err := ParseAST(actual)
+	require.NoError(t, err)
+	reprinted, err := reparsed.Print()
+	require.NoError(t, err)
+	assert.Equal(t, actual, reprinted)
+}
+
+func TestMergeCompatibleCopiesLeavesIncompatible(t *testing.T) {
+	df := Dockerfile(`
+FROM alpine
+COPY --from=builder a /dest/
+COPY --from=other b /dest/
+COPY --from=other --chown=1000 c /dest/
+COPY --chown=1000 d /dest/
+COPY e /dest/
+COPY f /dest
+COPY g /dest
+COPY ["h", "/opt/"]
+COPY ["i", "/opt/"]
+ADD j /srv/
+ADD k /srv/
+COPY l /srv/
+# keep this one separate
+COPY m /srv/
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	count, err := ast.MergeCompatibleCopies()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
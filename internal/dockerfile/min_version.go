@@ -0,0 +1,197 @@
+package dockerfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// The version of the Dockerfile frontend that every BuildKit build supports.
+const BaseFrontendVersion = "1.0"
+
+// A Dockerfile feature that needs a newer Dockerfile frontend than
+// BaseFrontendVersion.
+//
+// Versions are of the Dockerfile frontend (as in
+// `# syntax=docker/dockerfile:1.4`), which is what a Dockerfile pins, and
+// are the first stable (non-labs) release with the feature.
+type Feature struct {
+	Name    string
+	Version string
+
+	// The instructions that may use the feature, lowercased, and whether
+	// an instruction uses it.
+	instructions []string
+	uses         func(node *parser.Node) bool
+
+	// For a parser directive, its name.
+	directive string
+
+	// For a narrower use of another feature that needs a newer version
+	// (e.g., a symbolic --chmod), the name of the other feature.
+	refines string
+}
+
+// Features lists the Dockerfile features that need a newer frontend, oldest
+// first. To support a new feature, add it here.
+var Features = []Feature{
+	{Name: "RUN --mount", Version: "1.2", instructions: []string{command.Run},
+		uses: hasFlag("--mount")},
+	{Name: "--chmod", Version: "1.2", instructions: []string{command.Copy, command.Add},
+		uses: hasFlag("--chmod")},
+	{Name: "RUN --network", Version: "1.3", instructions: []string{command.Run},
+		uses: hasFlag("--network")},
+	{Name: "heredoc", Version: "1.4", instructions: []string{command.Run, command.Copy, command.Add},
+		uses: func(node *parser.Node) bool { return len(node.Heredocs) > 0 }},
+	{Name: "--link", Version: "1.4", instructions: []string{command.Copy, command.Add},
+		uses: hasFlag("--link")},
+	{Name: "ADD --checksum", Version: "1.6", instructions: []string{command.Add},
+		uses: hasFlag("--checksum")},
+	{Name: "# check", Version: "1.8", directive: "check"},
+	{Name: "RUN --mount=type=secret,env=", Version: "1.10", instructions: []string{command.Run},
+		uses: usesSecretEnv, refines: "RUN --mount"},
+	{Name: "non-octal --chmod", Version: "1.14", instructions: []string{command.Copy, command.Add},
+		uses: usesSymbolicChmod, refines: "--chmod"},
+}
+
+// A use of a Feature in the Dockerfile.
+type FeatureUse struct {
+	Feature string
+	Version string
+	Line    int
+}
+
+// MinBuildKitVersion returns the oldest Dockerfile frontend version that
+// can build the Dockerfile, and the uses of the features that need it, so
+// that CI can check the Dockerfile against a pinned builder.
+//
+// Every use of a feature newer than BaseFrontendVersion is returned, in
+// order, not just the ones that need the returned version.
+func (a AST) MinBuildKitVersion() (string, []FeatureUse, error) {
+	minVersion := BaseFrontendVersion
+	var uses []FeatureUse
+	use := func(f Feature, line int) {
+		uses = append(uses, FeatureUse{Feature: f.Name, Version: f.Version, Line: line})
+		if compareVersions(f.Version, minVersion) > 0 {
+			minVersion = f.Version
+		}
+	}
+
+	for i, h := range a.header {
+		// Read the line, since buildkit may not recognize the directive.
+		match := directiveLineRe.FindStringSubmatch(h.source)
+		if match == nil {
+			continue
+		}
+		for _, f := range Features {
+			if f.directive != "" && f.directive == strings.ToLower(match[1]) {
+				use(f, i+1)
+			}
+		}
+	}
+
+	err := a.Traverse(func(node *parser.Node) error {
+		for _, f := range Features {
+			if f.usedBy(node) {
+				use(f, node.StartLine)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return minVersion, uses, nil
+}
+
+func (f Feature) usedBy(node *parser.Node) bool {
+	return f.uses != nil && containsString(f.instructions, strings.ToLower(node.Value)) && f.uses(node)
+}
+
+// hasFlag reports whether an instruction has the flag, as `--flag` or
+// `--flag=value`.
+func hasFlag(name string) func(node *parser.Node) bool {
+	return func(node *parser.Node) bool {
+		return len(flagValues(node, name)) > 0
+	}
+}
+
+// flagValues returns the values of each use of a flag on an instruction.
+func flagValues(node *parser.Node, name string) []string {
+	var result []string
+	for _, flag := range node.Flags {
+		if flag == name {
+			result = append(result, "")
+		} else if value, ok := strings.CutPrefix(flag, name+"="); ok {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func usesSecretEnv(node *parser.Node) bool {
+	for _, mount := range flagValues(node, "--mount") {
+		isSecret := false
+		hasEnv := false
+		for _, field := range strings.Split(mount, ",") {
+			key, value, _ := strings.Cut(field, "=")
+			switch strings.ToLower(key) {
+			case "type":
+				isSecret = value == "secret"
+			case "env":
+				hasEnv = true
+			}
+		}
+		if isSecret && hasEnv {
+			return true
+		}
+	}
+	return false
+}
+
+// usesSymbolicChmod reports whether a --chmod is written like `u+x` rather
+// than in octal. ARG references aren't expanded, so they count as octal.
+func usesSymbolicChmod(node *parser.Node) bool {
+	for _, mode := range flagValues(node, "--chmod") {
+		if mode == "" || strings.Contains(mode, "$") {
+			continue
+		}
+		if _, err := strconv.ParseUint(mode, 8, 32); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compareVersions compares dotted version numbers, like "1.4" and "1.10".
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var an, bn int
+		if i < len(as) {
+			an, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			bn, _ = strconv.Atoi(bs[i])
+		}
+		if an != bn {
+			if an < bn {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
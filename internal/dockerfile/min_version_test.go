@@ -0,0 +1,105 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinBuildKitVersion(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`# syntax=docker/dockerfile:1
+FROM golang:1.21 AS builder
+RUN --mount=type=cache,target=/root/.cache/go-build go build ./...
+COPY --link --chmod=755 entrypoint.sh /
+RUN <<EOF
+echo hi
+EOF
+`))
+	require.NoError(t, err)
+
+	version, uses, err := ast.MinBuildKitVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.4", version)
+	assert.Equal(t, []FeatureUse{
+		{Feature: "RUN --mount", Version: "1.2", Line: 3},
+		{Feature: "--chmod", Version: "1.2", Line: 4},
+		{Feature: "--link", Version: "1.4", Line: 4},
+		{Feature: "heredoc", Version: "1.4", Line: 5},
+	}, uses)
+}
+
+func TestMinBuildKitVersionNoFeatures(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN echo hi
+COPY . /app
+`))
+	require.NoError(t, err)
+
+	version, uses, err := ast.MinBuildKitVersion()
+	require.NoError(t, err)
+	assert.Equal(t, BaseFrontendVersion, version)
+	assert.Empty(t, uses)
+}
+
+func TestMinBuildKitVersionComparesNumerically(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN --mount=type=secret,id=token,env=TOKEN ./deploy.sh
+ADD --link https://example.com/app.tar.gz /app/
+`))
+	require.NoError(t, err)
+
+	version, uses, err := ast.MinBuildKitVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.10", version)
+	assert.Equal(t, []FeatureUse{
+		{Feature: "RUN --mount", Version: "1.2", Line: 3},
+		{Feature: "RUN --mount=type=secret,env=", Version: "1.10", Line: 3},
+		{Feature: "--link", Version: "1.4", Line: 4},
+	}, uses)
+}
+
+func TestMinBuildKitVersionCheckDirective(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`# syntax=docker/dockerfile:1
+# check=skip=JSONArgsRecommended
+FROM alpine
+RUN --network=none make
+`))
+	require.NoError(t, err)
+
+	version, uses, err := ast.MinBuildKitVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.8", version)
+	assert.Equal(t, []FeatureUse{
+		{Feature: "# check", Version: "1.8", Line: 2},
+		{Feature: "RUN --network", Version: "1.3", Line: 4},
+	}, uses)
+}
+
+func TestMinBuildKitVersionSymbolicChmod(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG MODE=644
+COPY --chmod=$MODE config /etc/
+COPY --chmod=u+x run.sh /
+`))
+	require.NoError(t, err)
+
+	version, uses, err := ast.MinBuildKitVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "1.14", version)
+	assert.Equal(t, []FeatureUse{
+		{Feature: "--chmod", Version: "1.2", Line: 4},
+		{Feature: "--chmod", Version: "1.2", Line: 5},
+		{Feature: "non-octal --chmod", Version: "1.14", Line: 5},
+	}, uses)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, -1, compareVersions("1.4", "1.10"))
+	assert.Equal(t, 1, compareVersions("1.10", "1.9"))
+	assert.Equal(t, 0, compareVersions("1.4", "1.4.0"))
+	assert.Equal(t, 1, compareVersions("2", "1.14"))
+}
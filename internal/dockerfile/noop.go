@@ -0,0 +1,69 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// NoOpInstructions finds instructions that do nothing, which generated
+// Dockerfiles sometimes emit:
+//
+//   - a RUN of `true`, `:`, or nothing at all
+//   - an ENV or LABEL with no assignments
+//
+// Returns the line numbers of the instructions.
+func (a AST) NoOpInstructions() ([]int, error) {
+	var result []int
+	for _, node := range a.result.AST.Children {
+		if isNoOp(node) {
+			result = append(result, node.StartLine)
+		}
+	}
+	return result, nil
+}
+
+// RemoveNoOps removes the instructions that NoOpInstructions finds.
+//
+// Returns the number of instructions removed.
+func (a *AST) RemoveNoOps() (int, error) {
+	count := 0
+	for i := len(a.result.AST.Children) - 1; i >= 0; i-- {
+		if isNoOp(a.result.AST.Children[i]) {
+			a.removeNode(i)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func isNoOp(node *parser.Node) bool {
+	switch strings.ToLower(node.Value) {
+	case command.Run:
+		return isNoOpRun(node)
+	case command.Env, command.Label:
+		return node.Next == nil
+	}
+	return false
+}
+
+func isNoOpRun(node *parser.Node) bool {
+	if len(node.Heredocs) > 0 {
+		return false
+	}
+
+	args := getCmdArgs(node)
+	if node.Attributes["json"] {
+		// The exec form runs the binary directly, so `:`, a shell
+		// builtin, would fail.
+		return len(args) == 0 ||
+			(len(args) == 1 && (args[0] == "true" || args[0] == "/bin/true"))
+	}
+
+	switch strings.TrimSpace(strings.Join(args, " ")) {
+	case "", "true", ":", "/bin/true":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoOpInstructions(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN true
+RUN :
+RUN ["true"]
+RUN [":"]
+RUN --mount=type=cache,target=/root/.cache true
+RUN true && make
+ENV
+ENV A=1
+LABEL
+LABEL a=""
+`))
+	require.NoError(t, err)
+
+	lines, err := ast.NoOpInstructions()
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 5, 7, 9, 11}, lines)
+}
+
+func TestRemoveNoOps(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN true
+RUN apk add curl
+
+LABEL
+ENV
+
+COPY . /app
+RUN :
+`))
+	require.NoError(t, err)
+
+	count, err := ast.RemoveNoOps()
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	actual, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+RUN apk add curl
+
+COPY . /app
+`, string(actual))
+}
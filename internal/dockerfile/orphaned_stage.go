@@ -0,0 +1,91 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// OrphanedFinalStage reports whether the final stage ignores every earlier
+// stage, even though one of them is a builder (see ClassifyStages). That
+// usually means the COPY --from of the build artifacts was lost, and the
+// builder work is wasted.
+//
+// The final stage uses an earlier stage if it's built FROM it, COPYs
+// --from it, or mounts it with `RUN --mount=from=...`. If none of the
+// earlier stages are builders (e.g., they're alternate targets for
+// --target), the final stage isn't orphaned.
+func (a AST) OrphanedFinalStage(buildArgs []string) (bool, error) {
+	graph, err := a.DependencyGraph(buildArgs)
+	if err != nil {
+		return false, err
+	}
+
+	final := -1
+	for _, n := range graph.Nodes {
+		if n.Stage > final {
+			final = n.Stage
+		}
+	}
+	if final < 1 {
+		return false, nil
+	}
+
+	finalID := stageNodeID(final)
+	for _, e := range graph.Edges {
+		if e.From == finalID && strings.HasPrefix(e.To, "stage:") {
+			return false, nil
+		}
+	}
+
+	mounts, err := a.mountsEarlierStage(buildArgs, final)
+	if err != nil || mounts {
+		return false, err
+	}
+
+	kinds, err := a.ClassifyStages(buildArgs)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i < final; i++ {
+		if kinds[i].Role == StageBuilder {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Whether a RUN in the given stage mounts an earlier stage.
+func (a AST) mountsEarlierStage(buildArgs []string, stage int) (bool, error) {
+	byName := map[string]int{}
+	result := false
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			if inst.Name != "" {
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+			}
+
+		case *instructions.RunCommand:
+			if st.stageIndex != stage {
+				return nil
+			}
+
+			mounts, ok := st.vars.expandMounts(inst)
+			if !ok {
+				return nil
+			}
+			for _, m := range mounts {
+				if m.From == "" {
+					continue
+				}
+				if _, ok := resolveStageRef(m.From, byName, stage); ok {
+					result = true
+				}
+			}
+		}
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,118 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrphanedFinalStage(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		df       string
+		expected bool
+	}{
+		{
+			name: "copy lost",
+			df: `
+FROM golang:1.20 AS builder
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+COPY app /app
+ENTRYPOINT ["/app"]
+`,
+			expected: true,
+		},
+		{
+			name: "copies from builder",
+			df: `
+FROM golang:1.20 AS builder
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+COPY --from=builder /out/app /app
+`,
+		},
+		{
+			name: "copies from builder by index",
+			df: `
+FROM golang:1.20
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+COPY --from=0 /out/app /app
+`,
+		},
+		{
+			name: "copies from builder by ARG",
+			df: `
+FROM golang:1.20 AS builder
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+ARG FROM_STAGE=builder
+COPY --from=$FROM_STAGE /out/app /app
+`,
+		},
+		{
+			name: "built from builder",
+			df: `
+FROM node:18 AS deps
+RUN npm ci
+
+FROM deps
+CMD ["npm", "start"]
+`,
+		},
+		{
+			name: "mounts builder",
+			df: `
+FROM golang:1.20 AS builder
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+RUN --mount=type=bind,from=builder,source=/out,target=/mnt cp /mnt/app /app
+`,
+		},
+		{
+			name: "copies from an external image only",
+			df: `
+FROM golang:1.20 AS builder
+RUN go build -o /out/app ./cmd/app
+
+FROM alpine
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+`,
+			expected: true,
+		},
+		{
+			name: "single stage",
+			df: `
+FROM golang:1.20
+RUN go build -o /out/app ./cmd/app
+`,
+		},
+		{
+			name: "earlier stages are alternate targets",
+			df: `
+FROM alpine AS debug
+RUN apk add curl
+CMD ["sh"]
+
+FROM alpine
+CMD ["echo", "hi"]
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ast, err := ParseAST(Dockerfile(tc.df))
+			require.NoError(t, err)
+
+			orphaned, err := ast.OrphanedFinalStage(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, orphaned)
+		})
+	}
+}
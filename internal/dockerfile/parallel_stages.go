@@ -0,0 +1,60 @@
+package dockerfile
+
+// ParallelizableStages groups the stages by dependency level, from the
+// DependencyGraph. The stages in a group don't depend on each other, so
+// BuildKit can build them concurrently once the groups before have been
+// built.
+//
+// Group 0 is the stages that only depend on external images. Each later
+// stage is in the group after the latest of the stages it's built FROM or
+// copies from. Stages are listed by index, in ascending order.
+//
+// A Dockerfile with one stage per group builds serially. Moving work into
+// stages that don't depend on each other (e.g., building the frontend and
+// the backend in separate stages) makes the groups wider.
+func (a AST) ParallelizableStages(buildArgs []string) ([][]int, error) {
+	graph, err := a.DependencyGraph(buildArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	stageByID := map[string]int{}
+	for _, n := range graph.Nodes {
+		if n.Stage >= 0 {
+			stageByID[n.ID] = n.Stage
+		}
+	}
+
+	// Edges always point to earlier stages, so the levels can be computed
+	// in stage order.
+	deps := map[int][]int{}
+	for _, e := range graph.Edges {
+		to, ok := stageByID[e.To]
+		if !ok {
+			continue
+		}
+		from := stageByID[e.From]
+		deps[from] = append(deps[from], to)
+	}
+
+	var result [][]int
+	levels := map[int]int{}
+	for _, n := range graph.Nodes {
+		if n.Stage < 0 {
+			continue
+		}
+		level := 0
+		for _, dep := range deps[n.Stage] {
+			if levels[dep]+1 > level {
+				level = levels[dep] + 1
+			}
+		}
+		levels[n.Stage] = level
+
+		for len(result) <= level {
+			result = append(result, nil)
+		}
+		result[level] = append(result[level], n.Stage)
+	}
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelizableStages(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.20 AS base
+RUN apk add git
+
+FROM base AS backend
+RUN go build ./...
+
+FROM node:18 AS frontend
+RUN npm run build
+
+FROM base AS tools
+RUN go install golang.org/x/tools/...
+
+FROM alpine
+COPY --from=backend /out/app /app
+COPY --from=frontend /out/static /static
+`))
+	require.NoError(t, err)
+
+	groups, err := ast.ParallelizableStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0, 2}, {1, 3}, {4}}, groups)
+}
+
+func TestParallelizableStagesSerial(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.20 AS build
+RUN go build ./...
+
+FROM build AS test
+RUN go test ./...
+
+FROM alpine
+COPY --from=test /out/app /app
+`))
+	require.NoError(t, err)
+
+	groups, err := ast.ParallelizableStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0}, {1}, {2}}, groups)
+}
+
+func TestParallelizableStagesBuildArg(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=alpine
+FROM golang:1.20 AS build
+FROM node:18 AS web
+FROM ${BASE}
+`))
+	require.NoError(t, err)
+
+	groups, err := ast.ParallelizableStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0, 1, 2}}, groups)
+
+	groups, err = ast.ParallelizableStages([]string{"BASE=web"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]int{{0, 1}, {2}}, groups)
+}
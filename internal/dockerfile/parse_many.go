@@ -0,0 +1,76 @@
+package dockerfile
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+type parseJob struct {
+	name string
+	df   Dockerfile
+}
+
+type parseResult struct {
+	name string
+	ast  AST
+	err  error
+}
+
+// ParseMany parses Dockerfiles in parallel, keyed by name (e.g., by path in
+// a repo). Every name ends up in exactly one of the returned maps: the ASTs
+// that parsed, or the errors of the ones that didn't.
+//
+// At most `concurrency` files are parsed at once. If concurrency is less
+// than 1, it defaults to GOMAXPROCS.
+//
+// If ctx is canceled, no new files are parsed, and the files that were
+// never parsed get ctx.Err().
+func ParseMany(ctx context.Context, files map[string]Dockerfile, concurrency int) (map[string]AST, map[string]error) {
+	if concurrency < 1 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	if concurrency > len(files) {
+		concurrency = len(files)
+	}
+
+	jobs := make(chan parseJob)
+	results := make(chan parseResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				result := parseResult{name: job.name}
+				if err := ctx.Err(); err != nil {
+					result.err = err
+				} else {
+					result.ast, result.err = ParseAST(job.df)
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		for name, df := range files {
+			jobs <- parseJob{name: name, df: df}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	asts := make(map[string]AST)
+	errs := make(map[string]error)
+	for result := range results {
+		if result.err != nil {
+			errs[result.name] = result.err
+		} else {
+			asts[result.name] = result.ast
+		}
+	}
+	return asts, errs
+}
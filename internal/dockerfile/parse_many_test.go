@@ -0,0 +1,53 @@
+package dockerfile
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMany(t *testing.T) {
+	files := map[string]Dockerfile{
+		"empty/Dockerfile":    "",
+		"comments/Dockerfile": "# just a comment\n",
+	}
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("svc%d/Dockerfile", i)] = Dockerfile(fmt.Sprintf("FROM alpine\nRUN echo %d\n", i))
+	}
+
+	asts, errs := ParseMany(context.Background(), files, 4)
+	assert.Len(t, asts, 20)
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs["empty/Dockerfile"].Error(), "dockerfile.ParseAST")
+	assert.Contains(t, errs["comments/Dockerfile"].Error(), "dockerfile.ParseAST")
+
+	df, err := asts["svc7/Dockerfile"].Print()
+	require.NoError(t, err)
+	assert.Equal(t, "FROM alpine\nRUN echo 7\n", string(df))
+}
+
+func TestParseManyEmpty(t *testing.T) {
+	asts, errs := ParseMany(context.Background(), nil, 0)
+	assert.Empty(t, asts)
+	assert.Empty(t, errs)
+}
+
+func TestParseManyCanceled(t *testing.T) {
+	files := map[string]Dockerfile{
+		"a/Dockerfile": "FROM alpine\n",
+		"b/Dockerfile": "FROM busybox\n",
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	asts, errs := ParseMany(ctx, files, 0)
+	assert.Empty(t, asts)
+	assert.Equal(t, map[string]error{
+		"a/Dockerfile": context.Canceled,
+		"b/Dockerfile": context.Canceled,
+	}, errs)
+}
@@ -0,0 +1,93 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// The PATH that buildkit uses for Linux images that don't set one.
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// An ENV instruction that sets PATH.
+type PathMod struct {
+	Line  int
+	Stage int
+
+	// The PATH after the instruction, with variables expanded.
+	Value string
+
+	// Directories on the new PATH that weren't on the previous PATH, in order.
+	Added []string
+}
+
+// PathModifications finds every ENV that sets PATH, and resolves
+// the cumulative PATH at that point in the stage.
+//
+// We don't know the PATH of the base image, so we assume each stage
+// starts with the buildkit default PATH (unless it builds on an earlier
+// stage, in which case it inherits that stage's PATH). References to
+// $PATH or ${PATH} are expanded relative to the previous value.
+func (a AST) PathModifications(buildArgs []string) ([]PathMod, error) {
+	var result []PathMod
+	current := defaultPath
+	stagePaths := map[string]string{}
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			current = defaultPath
+			if p, ok := stagePaths[strings.ToLower(st.baseName)]; ok {
+				current = p
+			}
+		case *instructions.EnvCommand:
+			scope := st.vars.scope()
+			scope["PATH"] = current
+			for _, kv := range inst.Env {
+				if kv.Key != "PATH" {
+					continue
+				}
+
+				value, err := st.vars.shlex.ProcessWordWithMap(kv.Value, scope)
+				if err != nil {
+					value = kv.Value
+				}
+
+				result = append(result, PathMod{
+					Line:  node.StartLine,
+					Stage: st.stageIndex,
+					Value: value,
+					Added: addedPathDirs(current, value),
+				})
+				current = value
+			}
+		}
+
+		if st.stageName != "" {
+			stagePaths[strings.ToLower(st.stageName)] = current
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addedPathDirs returns the entries of newPath that aren't in oldPath.
+func addedPathDirs(oldPath, newPath string) []string {
+	existing := map[string]bool{}
+	for _, dir := range strings.Split(oldPath, ":") {
+		existing[dir] = true
+	}
+
+	var added []string
+	for _, dir := range strings.Split(newPath, ":") {
+		if dir == "" || existing[dir] {
+			continue
+		}
+		existing[dir] = true
+		added = append(added, dir)
+	}
+	return added
+}
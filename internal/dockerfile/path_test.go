@@ -0,0 +1,67 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathModifications(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+ARG GOBIN=/go/bin
+ENV PATH="${GOBIN}:$PATH"
+ENV PATH=$PATH:/opt/tools/bin FOO=bar
+
+FROM builder AS tools
+ENV PATH=/usr/local/node/bin:${PATH}
+
+FROM alpine
+ENV PATH=/app/bin
+`))
+	require.NoError(t, err)
+
+	mods, err := ast.PathModifications(nil)
+	require.NoError(t, err)
+	require.Len(t, mods, 4)
+
+	assert.Equal(t, PathMod{
+		Line:  4,
+		Stage: 0,
+		Value: "/go/bin:" + defaultPath,
+		Added: []string{"/go/bin"},
+	}, mods[0])
+	assert.Equal(t, PathMod{
+		Line:  5,
+		Stage: 0,
+		Value: "/go/bin:" + defaultPath + ":/opt/tools/bin",
+		Added: []string{"/opt/tools/bin"},
+	}, mods[1])
+	assert.Equal(t, PathMod{
+		Line:  8,
+		Stage: 1,
+		Value: "/usr/local/node/bin:/go/bin:" + defaultPath + ":/opt/tools/bin",
+		Added: []string{"/usr/local/node/bin"},
+	}, mods[2])
+	assert.Equal(t, PathMod{
+		Line:  11,
+		Stage: 2,
+		Value: "/app/bin",
+		Added: []string{"/app/bin"},
+	}, mods[3])
+}
+
+func TestPathModificationsBuildArg(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG TOOLS
+ENV PATH=${TOOLS}/bin:$PATH
+`))
+	require.NoError(t, err)
+
+	mods, err := ast.PathModifications([]string{"TOOLS=/opt/tools"})
+	require.NoError(t, err)
+	require.Len(t, mods, 1)
+	assert.Equal(t, []string{"/opt/tools/bin"}, mods[0].Added)
+}
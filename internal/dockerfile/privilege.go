@@ -0,0 +1,60 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A stage that switches to root and never switches back.
+type PrivIssue struct {
+	Stage     int
+	StageName string
+
+	// The line of the last USER that switched to root.
+	Line int
+}
+
+// PrivilegeResetIssues finds stages that switch to root with USER (e.g.,
+// to install packages) and are still root when the stage ends, so the
+// image runs as root.
+//
+// Stages that never set USER root aren't flagged, even if their base
+// image runs as root. Every stage is checked; callers that only care
+// about the final image can filter by Stage.
+func (a AST) PrivilegeResetIssues(buildArgs []string) ([]PrivIssue, error) {
+	var result []PrivIssue
+	var current *PrivIssue
+	endStage := func() {
+		if current != nil {
+			result = append(result, *current)
+			current = nil
+		}
+	}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			endStage()
+		case *instructions.UserCommand:
+			if isRootUser(st.vars.expand(inst.User)) {
+				current = &PrivIssue{Stage: st.stageIndex, StageName: st.stageName, Line: node.StartLine}
+			} else {
+				current = nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	endStage()
+	return result, nil
+}
+
+// isRootUser checks whether a USER value (user[:group]) is root.
+func isRootUser(user string) bool {
+	name, _, _ := strings.Cut(user, ":")
+	return name == "root" || name == "0"
+}
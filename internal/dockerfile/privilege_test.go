@@ -0,0 +1,56 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivilegeResetIssues(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:20 AS builder
+USER root
+RUN apt-get update && apt-get install -y python3
+USER node
+RUN npm ci
+
+FROM node:20 AS debug
+USER 0:0
+RUN apt-get install -y gdb
+USER app
+USER root:root
+
+FROM builder
+ARG RUNTIME_USER=root
+RUN echo hi
+USER $RUNTIME_USER
+`))
+	require.NoError(t, err)
+
+	issues, err := ast.PrivilegeResetIssues(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []PrivIssue{
+		{Stage: 1, StageName: "debug", Line: 12},
+		{Stage: 2, Line: 17},
+	}, issues)
+
+	issues, err = ast.PrivilegeResetIssues([]string{"RUNTIME_USER=node"})
+	require.NoError(t, err)
+	assert.Equal(t, []PrivIssue{
+		{Stage: 1, StageName: "debug", Line: 12},
+	}, issues)
+}
+
+func TestPrivilegeResetIssuesNone(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN adduser -D app
+USER app
+`))
+	require.NoError(t, err)
+
+	issues, err := ast.PrivilegeResetIssues(nil)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
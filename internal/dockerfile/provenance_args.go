@@ -0,0 +1,142 @@
+package dockerfile
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A ProvenanceArgRule recognizes an ARG that provenance tooling supplies,
+// from the words in its name. "GIT_COMMIT", "git-commit", and "gitCommit"
+// are all the words ["git", "commit"], lowercased.
+type ProvenanceArgRule func(words []string) bool
+
+// The rules that ProvenanceArgs applies. Append to them (in a copy) and
+// call ProvenanceArgsMatching for other naming conventions.
+var DefaultProvenanceArgRules = []ProvenanceArgRule{
+	ProvenanceArgWord("commit"),
+	ProvenanceArgWord("sha"),
+	ProvenanceArgWord("revision"),
+	ProvenanceArgPhrase("vcs", "ref"),
+	ProvenanceArgPhrase("git", "ref"),
+	ProvenanceArgPhrase("build", "date"),
+	ProvenanceArgPhrase("build", "time"),
+	ProvenanceArgPhrase("build", "timestamp"),
+	ProvenanceArgPhrase("source", "date", "epoch"),
+	isProvenanceVersion,
+}
+
+// ProvenanceArgWord matches names that contain the word, e.g., "commit"
+// matches GIT_COMMIT and COMMIT_ID.
+func ProvenanceArgWord(word string) ProvenanceArgRule {
+	return ProvenanceArgPhrase(word)
+}
+
+// ProvenanceArgPhrase matches names that contain the words in order, e.g.,
+// ("build", "date") matches BUILD_DATE and IMAGE_BUILD_DATE.
+func ProvenanceArgPhrase(phrase ...string) ProvenanceArgRule {
+	return func(words []string) bool {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			match := true
+			for j, w := range phrase {
+				if words[i+j] != w {
+					match = false
+					break
+				}
+			}
+			if match {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Prefixes of a *_VERSION arg that describe the image being built, rather
+// than a tool it installs (like NODE_VERSION).
+var provenanceVersionPrefixes = map[string]bool{
+	"app":     true,
+	"build":   true,
+	"image":   true,
+	"project": true,
+	"release": true,
+	"service": true,
+}
+
+func isProvenanceVersion(words []string) bool {
+	if len(words) == 0 || words[len(words)-1] != "version" {
+		return false
+	}
+	return len(words) == 1 || (len(words) == 2 && provenanceVersionPrefixes[words[0]])
+}
+
+// ProvenanceArgs returns the declared ARGs that look like provenance inputs
+// (a commit, a version, a build date, etc.), so that tooling knows which
+// build args to supply. See DefaultProvenanceArgRules.
+//
+// Each ARG is listed once, in the order it's first declared.
+func (a AST) ProvenanceArgs() ([]string, error) {
+	return a.ProvenanceArgsMatching(DefaultProvenanceArgRules)
+}
+
+// ProvenanceArgsMatching is like ProvenanceArgs, with a custom rule set. An
+// ARG is a provenance input if any rule matches it.
+func (a AST) ProvenanceArgsMatching(rules []ProvenanceArgRule) ([]string, error) {
+	seen := map[string]bool{}
+	var result []string
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		arg, ok := inst.(*instructions.ArgCommand)
+		if !ok {
+			return nil
+		}
+
+		for _, kv := range arg.Args {
+			if seen[kv.Key] {
+				continue
+			}
+			seen[kv.Key] = true
+
+			words := argNameWords(kv.Key)
+			for _, rule := range rules {
+				if rule(words) {
+					result = append(result, kv.Key)
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Splits an ARG name into lowercase words, at punctuation and at camelCase
+// boundaries.
+func argNameWords(name string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush()
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return words
+}
@@ -0,0 +1,62 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenanceArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG NODE_VERSION=18
+ARG GIT_COMMIT
+FROM node:${NODE_VERSION} AS build
+ARG BUILD_DATE
+ARG GIT_COMMIT
+ARG REGISTRY
+RUN make
+
+FROM alpine
+ARG VERSION=dev vcsRef
+ARG SOURCE_DATE_EPOCH
+ARG APP_VERSION
+ARG GO_VERSION=1.20
+ARG SHAPE
+LABEL org.opencontainers.image.revision=$vcsRef
+`))
+	require.NoError(t, err)
+
+	args, err := ast.ProvenanceArgs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"GIT_COMMIT", "BUILD_DATE", "VERSION", "vcsRef", "SOURCE_DATE_EPOCH", "APP_VERSION",
+	}, args)
+}
+
+func TestProvenanceArgsMatching(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG GIT_COMMIT
+ARG CI_PIPELINE_ID
+ARG BUILD_DATE
+`))
+	require.NoError(t, err)
+
+	rules := append([]ProvenanceArgRule{}, DefaultProvenanceArgRules...)
+	rules = append(rules, ProvenanceArgPhrase("pipeline", "id"))
+	args, err := ast.ProvenanceArgsMatching(rules)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GIT_COMMIT", "CI_PIPELINE_ID", "BUILD_DATE"}, args)
+
+	args, err = ast.ProvenanceArgsMatching([]ProvenanceArgRule{ProvenanceArgWord("commit")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GIT_COMMIT"}, args)
+}
+
+func TestArgNameWords(t *testing.T) {
+	assert.Equal(t, []string{"git", "commit"}, argNameWords("GIT_COMMIT"))
+	assert.Equal(t, []string{"git", "commit"}, argNameWords("gitCommit"))
+	assert.Equal(t, []string{"build", "date"}, argNameWords("build-date"))
+	assert.Equal(t, []string{"sha256"}, argNameWords("SHA256"))
+}
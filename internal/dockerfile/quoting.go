@@ -0,0 +1,138 @@
+package dockerfile
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Besides whitespace, the characters that a value is quoted for: quotes and
+// escapes, and shell metacharacters that are easy to misread unquoted.
+const quotedChars = "\"'\\;&|<>()*?#`"
+
+// NormalizeQuoting rewrites ENV and LABEL values so that they're quoted if
+// and only if they contain whitespace or special characters (or are
+// empty), e.g., `ENV A="1"` becomes `ENV A=1`, and `ENV A=hello\ world`
+// becomes `ENV A="hello world"`. Values that need quotes keep the quotes
+// they have; added quotes are double quotes.
+//
+// Values that can't be rewritten without changing what they mean are left
+// alone, like `A="$HOME"'/bin'`, or a single-quoted `$`. So is the legacy
+// `ENV key value` form. Keys aren't changed.
+//
+// Returns the number of values changed.
+func (a *AST) NormalizeQuoting() (int, error) {
+	escape := a.result.EscapeToken
+	count := 0
+	for _, node := range a.result.AST.Children {
+		count += normalizeKeyValueQuoting(node, escape)
+
+		if strings.EqualFold(node.Value, command.Onbuild) && node.Next != nil && len(node.Next.Children) > 0 {
+			count += normalizeKeyValueQuoting(node.Next.Children[0], escape)
+		}
+	}
+	return count, nil
+}
+
+func normalizeKeyValueQuoting(node *parser.Node, escape rune) int {
+	switch strings.ToLower(node.Value) {
+	case command.Env, command.Label:
+	default:
+		return 0
+	}
+	if isLegacyKeyValue(node) {
+		return 0
+	}
+
+	count := 0
+	for key := node.Next; key != nil && key.Next != nil; key = key.Next.Next {
+		value := key.Next
+		normalized, ok := normalizeValueQuoting(value.Value, escape)
+		if ok && normalized != value.Value {
+			value.Value = normalized
+			count++
+		}
+	}
+	return count
+}
+
+// normalizeValueQuoting returns the value, quoted if and only if it needs
+// to be. Returns false if it can't tell what the value means.
+func normalizeValueQuoting(raw string, escape rune) (string, bool) {
+	content, quoted, ok := unquoteValue(raw, escape)
+	if !ok {
+		return raw, false
+	}
+	if !needsQuotes(content) {
+		return content, true
+	}
+	if quoted {
+		return raw, true
+	}
+	return quoteValue(content, escape), true
+}
+
+func needsQuotes(s string) bool {
+	return s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(quotedChars, r)
+	}) != -1
+}
+
+// unquoteValue parses a value that's either entirely in one pair of quotes
+// or not quoted at all. A `$` stays in the content as is, since it expands
+// the same way unquoted and in double quotes; values where it wouldn't,
+// like an escaped `$`, aren't supported.
+func unquoteValue(raw string, escape rune) (content string, quoted bool, ok bool) {
+	runes := []rune(raw)
+	n := len(runes)
+
+	if n >= 2 && runes[0] == '\'' && runes[n-1] == '\'' {
+		inner := string(runes[1 : n-1])
+		if strings.ContainsAny(inner, "'$") {
+			return "", false, false
+		}
+		return inner, true, true
+	}
+
+	isDoubleQuoted := n >= 2 && runes[0] == '"' && runes[n-1] == '"'
+	if isDoubleQuoted {
+		runes = runes[1 : n-1]
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == escape:
+			if i+1 == len(runes) {
+				return "", false, false
+			}
+			next := runes[i+1]
+			if next == '$' || (isDoubleQuoted && next != '"' && next != escape) {
+				return "", false, false
+			}
+			sb.WriteRune(next)
+			i++
+		case r == '"' || (r == '\'' && !isDoubleQuoted):
+			return "", false, false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), isDoubleQuoted, true
+}
+
+func quoteValue(content string, escape rune) string {
+	var sb strings.Builder
+	sb.WriteRune('"')
+	for _, r := range content {
+		if r == '"' || r == escape {
+			sb.WriteRune(escape)
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteRune('"')
+	return sb.String()
+}
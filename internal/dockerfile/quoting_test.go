@@ -0,0 +1,74 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeQuoting(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ENV SIMPLE="value" PATH="$PATH:/app/bin"
+ENV SPACED=hello\ world
+ENV EMBEDDED="say \"hi\"" SINGLE='x y' PLAIN=ok
+LABEL description="A web server" version="1.0" maintainer='ops'
+LABEL empty=
+ONBUILD ENV CHILD="child"
+ENV LEGACY some value
+`))
+	require.NoError(t, err)
+
+	count, err := ast.NormalizeQuoting()
+	require.NoError(t, err)
+	assert.Equal(t, 7, count)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine
+ENV SIMPLE=value PATH=$PATH:/app/bin
+ENV SPACED="hello world"
+ENV EMBEDDED="say \"hi\"" SINGLE='x y' PLAIN=ok
+LABEL description="A web server" version=1.0 maintainer=ops
+LABEL empty=""
+ONBUILD ENV CHILD=child
+ENV LEGACY some value
+`, string(df))
+
+	// Normalizing again changes nothing.
+	count, err = ast.NormalizeQuoting()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestNormalizeQuotingLeavesAmbiguousValues(t *testing.T) {
+	src := `
+FROM alpine
+ENV A="$HOME"'/bin' B='$HOME' C=\$HOME
+`
+	ast, err := ParseAST(Dockerfile(src))
+	require.NoError(t, err)
+
+	count, err := ast.NormalizeQuoting()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, src, string(df))
+}
+
+func TestNormalizeQuotingEscapeDirective(t *testing.T) {
+	ast, err := ParseAST(Dockerfile("# escape=`\nFROM alpine\nENV A=hello` world B=\"C:\\dir\"\n"))
+	require.NoError(t, err)
+
+	count, err := ast.NormalizeQuoting()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, "# escape=`\nFROM alpine\nENV A=\"hello world\" B=\"C:\\dir\"\n", string(df))
+}
@@ -0,0 +1,164 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A change to one or more stages that would make the build faster, from
+// RedundantBaseImageSetup or SuggestDependencyCaching.
+type Suggestion struct {
+	BaseImage string
+
+	// The stages, by index, and their names (or "" if unnamed).
+	Stages     []int
+	StageNames []string
+
+	// The instructions the suggestion is about, as written, e.g.,
+	// "RUN apk add git".
+	Commands []string
+
+	// The lines of the commands, for each stage.
+	Lines [][]int
+
+	Message string
+}
+
+// The setup of one stage: the instructions after FROM, up to the first
+// COPY or ADD.
+type stageSetup struct {
+	index    int
+	name     string
+	base     string
+	commands []string
+	lines    []int
+	done     bool
+}
+
+// RedundantBaseImageSetup finds stages that are built FROM the same image
+// and start with the same setup instructions (e.g., installing the same
+// packages), so the setup runs more than once.
+//
+// A stage's setup is the instructions before its first COPY or ADD,
+// compared as written (ignoring whitespace). Stages match when they share
+// a base image and their setup starts the same way, and the shared part
+// includes a RUN. Stages built FROM another stage aren't compared.
+func (a AST) RedundantBaseImageSetup(buildArgs []string) ([]Suggestion, error) {
+	var stages []*stageSetup
+	byName := map[string]int{}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if st.stageIndex < 0 {
+			return nil
+		}
+
+		if stage, ok := inst.(*instructions.Stage); ok {
+			s := &stageSetup{index: st.stageIndex, name: stage.Name, base: st.baseName}
+			if _, ok := resolveStageRef(st.baseName, byName, st.stageIndex); ok {
+				s.done = true
+			}
+			if stage.Name != "" {
+				byName[strings.ToLower(stage.Name)] = st.stageIndex
+			}
+			stages = append(stages, s)
+			return nil
+		}
+		if st.stageIndex >= len(stages) {
+			return nil
+		}
+
+		s := stages[st.stageIndex]
+		cmd := strings.ToLower(node.Value)
+		if s.done || cmd == command.Copy || cmd == command.Add {
+			s.done = true
+			return nil
+		}
+		s.commands = append(s.commands, normalizedInstruction(node))
+		s.lines = append(s.lines, node.StartLine)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Group the stages by base image and first setup instruction.
+	type groupKey struct{ base, first string }
+	groups := map[groupKey][]*stageSetup{}
+	var keys []groupKey
+	for _, s := range stages {
+		if len(s.commands) == 0 {
+			continue
+		}
+		key := groupKey{s.base, s.commands[0]}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], s)
+	}
+
+	var result []Suggestion
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+
+		shared := commonPrefix(group)
+		hasRun := false
+		for _, c := range shared {
+			if strings.HasPrefix(strings.ToLower(c), command.Run+" ") {
+				hasRun = true
+			}
+		}
+		if !hasRun {
+			continue
+		}
+
+		sug := Suggestion{BaseImage: key.base, Commands: shared}
+		var labels []string
+		for _, s := range group {
+			sug.Stages = append(sug.Stages, s.index)
+			sug.StageNames = append(sug.StageNames, s.name)
+			sug.Lines = append(sug.Lines, s.lines[:len(shared)])
+			label := s.name
+			if label == "" {
+				label = strconv.Itoa(s.index)
+			}
+			labels = append(labels, label)
+		}
+		sug.Message = fmt.Sprintf(
+			"stages %s are all built FROM %s and start with the same %d instruction(s). "+
+				"Move them into a shared stage (FROM %s AS base), and build the stages FROM base",
+			strings.Join(labels, ", "), key.base, len(shared), key.base)
+		result = append(result, sug)
+	}
+	return result, nil
+}
+
+// The setup instructions that all the stages start with.
+func commonPrefix(group []*stageSetup) []string {
+	prefix := group[0].commands
+	for _, s := range group[1:] {
+		n := 0
+		for n < len(prefix) && n < len(s.commands) && prefix[n] == s.commands[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return prefix
+}
+
+// The instruction as written, with whitespace collapsed, and the contents
+// of any heredocs.
+func normalizedInstruction(node *parser.Node) string {
+	s := strings.Join(strings.Fields(node.Original), " ")
+	for _, h := range node.Heredocs {
+		s += "\n" + h.Content
+	}
+	return s
+}
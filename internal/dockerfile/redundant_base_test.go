@@ -0,0 +1,88 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedundantBaseImageSetup(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG GO=1.21
+FROM golang:${GO} AS build
+RUN apk add --no-cache git
+RUN  go install github.com/a/tool@latest
+COPY . /src
+RUN go build ./...
+
+FROM golang:1.21 AS test
+RUN apk add --no-cache git
+RUN go install github.com/a/tool@latest
+RUN go install github.com/b/other@latest
+COPY . /src
+RUN go test ./...
+
+FROM alpine
+COPY --from=build /app /app
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.RedundantBaseImageSetup(nil)
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+
+	s := suggestions[0]
+	assert.Equal(t, "golang:1.21", s.BaseImage)
+	assert.Equal(t, []int{0, 1}, s.Stages)
+	assert.Equal(t, []string{"build", "test"}, s.StageNames)
+	assert.Equal(t, []string{
+		"RUN apk add --no-cache git",
+		"RUN go install github.com/a/tool@latest",
+	}, s.Commands)
+	assert.Equal(t, [][]int{{4, 5}, {10, 11}}, s.Lines)
+	assert.Contains(t, s.Message, "stages build, test are all built FROM golang:1.21 and start with the same 2 instruction(s)")
+}
+
+func TestRedundantBaseImageSetupDifferentSetup(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS build
+RUN apk add git
+
+FROM golang:1.21 AS lint
+RUN apk add make
+
+FROM golang:1.20 AS old
+RUN apk add git
+
+FROM golang:1.21 AS env
+WORKDIR /src
+COPY . .
+RUN apk add git
+
+FROM build AS test
+RUN apk add git
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.RedundantBaseImageSetup(nil)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestRedundantBaseImageSetupNoRun(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS a
+WORKDIR /app
+COPY a /app
+
+FROM alpine AS b
+WORKDIR /app
+COPY b /app
+`))
+	require.NoError(t, err)
+
+	suggestions, err := ast.RedundantBaseImageSetup(nil)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
@@ -0,0 +1,77 @@
+package dockerfile
+
+import (
+	"sort"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// RequiredBuildArgs returns the ARGs that must be passed as build args
+// for the image references in the Dockerfile to be extracted correctly.
+//
+// These are ARGs with no default that are used in a FROM base image or
+// platform, or in a COPY --from. Without them, the reference expands
+// to the wrong image (or to nothing).
+func (a AST) RequiredBuildArgs() ([]string, error) {
+	// Maps each declared ARG to whether it has a default.
+	globals := map[string]bool{}
+	var stageArgs map[string]bool
+
+	required := map[string]bool{}
+	check := func(st *walkState, word string, declared map[string]bool) {
+		if word == "" {
+			return
+		}
+		env := make(map[string]string, len(declared))
+		for name := range declared {
+			env[name] = ""
+		}
+		_, matches, err := st.vars.shlex.ProcessWordWithMatches(word, env)
+		if err != nil {
+			return
+		}
+		for name := range matches {
+			if !declared[name] {
+				required[name] = true
+			}
+		}
+	}
+
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			stageArgs = map[string]bool{}
+			check(st, inst.BaseName, globals)
+			check(st, inst.Platform, globals)
+
+		case *instructions.ArgCommand:
+			for _, kv := range inst.Args {
+				if st.stageIndex < 0 {
+					globals[kv.Key] = kv.Value != nil
+				} else {
+					stageArgs[kv.Key] = kv.Value != nil || globals[kv.Key]
+				}
+			}
+
+		case *instructions.EnvCommand:
+			for _, kv := range inst.Env {
+				stageArgs[kv.Key] = true
+			}
+
+		case *instructions.CopyCommand:
+			check(st, inst.From, stageArgs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(required))
+	for name := range required {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG REGISTRY
+ARG GO_VERSION=1.21
+ARG PLATFORM
+ARG UNUSED
+FROM --platform=$PLATFORM ${REGISTRY}/golang:${GO_VERSION} AS builder
+
+FROM alpine
+ARG TOOLS_IMAGE
+ARG GO_VERSION
+ENV CACHE_IMAGE=cache:latest
+COPY --from=${TOOLS_IMAGE} /bin/tool /bin/tool
+COPY --from=golang:${GO_VERSION} /usr/local/go /go
+COPY --from=${CACHE_IMAGE} /cache /cache
+COPY --from=builder /app /app
+`))
+	require.NoError(t, err)
+
+	args, err := ast.RequiredBuildArgs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"PLATFORM", "REGISTRY", "TOOLS_IMAGE"}, args)
+}
+
+func TestRequiredBuildArgsNone(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=alpine
+FROM ${BASE}
+ARG VERSION
+RUN echo $VERSION
+`))
+	require.NoError(t, err)
+
+	args, err := ast.RequiredBuildArgs()
+	require.NoError(t, err)
+	assert.Empty(t, args)
+}
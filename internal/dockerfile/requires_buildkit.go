@@ -0,0 +1,79 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// An instruction that the legacy (non-BuildKit) builder can't build.
+type BuildKitRequirement struct {
+	Line int
+
+	// The instruction keyword, in upper case (e.g., "COPY").
+	Instruction string
+
+	// The Name of the Feature that needs BuildKit.
+	Feature string
+}
+
+func (r BuildKitRequirement) Message() string {
+	switch {
+	case r.Feature == "--chmod":
+		return fmt.Sprintf("%s --chmod requires BuildKit (the legacy builder supports --chown, but not --chmod)",
+			r.Instruction)
+	case strings.HasPrefix(r.Feature, "--"):
+		return fmt.Sprintf("%s %s requires BuildKit", r.Instruction, r.Feature)
+	case r.Feature == "heredoc":
+		return fmt.Sprintf("%s with a heredoc requires BuildKit", r.Instruction)
+	}
+	return fmt.Sprintf("%s requires BuildKit", r.Feature)
+}
+
+// RequiresBuildKit finds the instructions that use features only BuildKit
+// supports, so that teams on the legacy builder get a clear error instead
+// of Docker's parse errors.
+//
+// Parser directives aren't included, since the legacy builder reads them as
+// comments.
+func (a AST) RequiresBuildKit() ([]BuildKitRequirement, error) {
+	var result []BuildKitRequirement
+	err := a.Traverse(func(node *parser.Node) error {
+		for _, f := range Features {
+			if f.refines != "" || !f.usedBy(node) {
+				continue
+			}
+			result = append(result, BuildKitRequirement{
+				Line:        node.StartLine,
+				Instruction: strings.ToUpper(node.Value),
+				Feature:     f.Name,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ChmodUsage returns the lines of the COPY and ADD instructions with a
+// --chmod, which the legacy builder rejects.
+func (a AST) ChmodUsage() ([]int, error) {
+	var result []int
+	err := a.Traverse(func(node *parser.Node) error {
+		switch strings.ToLower(node.Value) {
+		case command.Copy, command.Add:
+			if len(flagValues(node, "--chmod")) > 0 {
+				result = append(result, node.StartLine)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChmodUsage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --chown=app:app config /etc/app/
+COPY --chmod=755 entrypoint.sh /
+ADD --chown=1000 --chmod=u+x https://example.com/tool /usr/local/bin/tool
+RUN chmod 755 /entrypoint.sh
+`))
+	require.NoError(t, err)
+
+	lines, err := ast.ChmodUsage()
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5}, lines)
+}
+
+func TestRequiresBuildKit(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`# syntax=docker/dockerfile:1
+# check=skip=JSONArgsRecommended
+FROM alpine
+COPY --chown=app:app config /etc/app/
+COPY --chmod=u+x entrypoint.sh /
+RUN --mount=type=secret,id=token,env=TOKEN ./fetch.sh
+RUN <<EOF
+echo hi
+EOF
+`))
+	require.NoError(t, err)
+
+	reqs, err := ast.RequiresBuildKit()
+	require.NoError(t, err)
+
+	var messages []string
+	for _, r := range reqs {
+		messages = append(messages, r.Message())
+	}
+	assert.Equal(t, []string{
+		"COPY --chmod requires BuildKit (the legacy builder supports --chown, but not --chmod)",
+		"RUN --mount requires BuildKit",
+		"RUN with a heredoc requires BuildKit",
+	}, messages)
+	assert.Equal(t, []int{5, 6, 7}, []int{reqs[0].Line, reqs[1].Line, reqs[2].Line})
+}
+
+func TestRequiresBuildKitNone(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+COPY --chown=app:app --from=builder /app /app
+RUN make
+`))
+	require.NoError(t, err)
+
+	reqs, err := ast.RequiresBuildKit()
+	require.NoError(t, err)
+	assert.Empty(t, reqs)
+}
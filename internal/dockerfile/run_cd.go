@@ -0,0 +1,190 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+)
+
+// RunCdUsage finds RUN instructions that start with `cd DIR &&`, like
+// `RUN cd /app && make`, and returns their lines. The directory only lasts
+// for the one RUN, which is easy to miss; a WORKDIR says the same thing
+// more clearly, and persists.
+//
+// This is a heuristic. Only a cd at the very start of the script, to a
+// single directory, followed by `&&`, is flagged. A cd in a subshell, a
+// loop, or any other compound command, or to a directory computed with a
+// command substitution (e.g., `cd "$(mktemp -d)"`), isn't. Shell form RUNs
+// are checked, and exec form RUNs that run `sh -c` (or bash -c); RUNs
+// with heredocs aren't.
+func (a AST) RunCdUsage() ([]int, error) {
+	var result []int
+	for _, node := range a.result.AST.Children {
+		if strings.ToLower(node.Value) != command.Run || len(node.Heredocs) > 0 {
+			continue
+		}
+
+		inst, err := instructions.ParseInstruction(node)
+		if err != nil {
+			continue // ignore parsing error
+		}
+		run, ok := inst.(*instructions.RunCommand)
+		if !ok {
+			continue
+		}
+
+		script, ok := runShellScript(run)
+		if !ok {
+			continue
+		}
+
+		words, op, ok := leadingCommand(script)
+		if ok && op == "&&" && len(words) == 2 && words[0] == "cd" && !strings.HasPrefix(words[1], "-") {
+			result = append(result, node.StartLine)
+		}
+	}
+	return result, nil
+}
+
+// runShellScript returns the shell script that a RUN runs: the command
+// line of the shell form, or the script of an exec form `sh -c SCRIPT`.
+func runShellScript(run *instructions.RunCommand) (string, bool) {
+	if run.PrependShell {
+		return strings.Join(run.CmdLine, " "), true
+	}
+	if len(run.CmdLine) == 3 && run.CmdLine[1] == "-c" {
+		switch path.Base(run.CmdLine[0]) {
+		case "sh", "bash", "ash", "dash":
+			return run.CmdLine[2], true
+		}
+	}
+	return "", false
+}
+
+// leadingCommand returns the words of the simple command that a shell
+// script starts with, with quotes removed, and the control operator that
+// follows it (e.g., "&&", or ";" for a newline), or "" at the end of the
+// script.
+//
+// Returns false if the script doesn't start with a simple command that can
+// be read without running anything: e.g., it starts with a subshell or a
+// compound command, or the command has a redirection, a command
+// substitution, or an unterminated quote.
+func leadingCommand(script string) (words []string, op string, ok bool) {
+	var word strings.Builder
+	inWord := false
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch c {
+		case ' ', '\t':
+			endWord()
+
+		case '\n', ';':
+			endWord()
+			if len(words) == 0 {
+				continue // an empty line
+			}
+			return words, ";", true
+
+		case '&', '|':
+			endWord()
+			if len(words) == 0 {
+				return nil, "", false
+			}
+			op := string(c)
+			if i+1 < len(script) && script[i+1] == c {
+				op += string(c)
+			}
+			return words, op, true
+
+		case '#':
+			if inWord {
+				word.WriteByte(c)
+				continue
+			}
+			// A comment, to the end of the line.
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			i--
+
+		case '(', ')', '<', '>', '`':
+			return nil, "", false
+
+		case '$':
+			if i+1 < len(script) && script[i+1] == '(' {
+				return nil, "", false
+			}
+			word.WriteByte(c)
+			inWord = true
+
+		case '\\':
+			if i+1 < len(script) {
+				i++
+				if script[i] != '\n' {
+					word.WriteByte(script[i])
+				}
+			}
+			inWord = true
+
+		case '\'':
+			end := strings.IndexByte(script[i+1:], '\'')
+			if end == -1 {
+				return nil, "", false
+			}
+			word.WriteString(script[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+
+		case '"':
+			i++
+			for ; i < len(script) && script[i] != '"'; i++ {
+				switch {
+				case script[i] == '`' || (script[i] == '$' && i+1 < len(script) && script[i+1] == '('):
+					return nil, "", false
+				case script[i] == '\\' && i+1 < len(script) && strings.IndexByte("\"\\$`", script[i+1]) != -1:
+					i++
+				}
+				word.WriteByte(script[i])
+			}
+			if i == len(script) {
+				return nil, "", false
+			}
+			inWord = true
+
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+
+		// A compound command, like `{ cd /app && make; }` or `if ...`,
+		// starts with a reserved word.
+		if !inWord && len(words) == 1 && isReservedWord(words[0]) {
+			return nil, "", false
+		}
+	}
+
+	endWord()
+	if len(words) == 0 || isReservedWord(words[0]) {
+		return nil, "", false
+	}
+	return words, "", true
+}
+
+func isReservedWord(w string) bool {
+	switch w {
+	case "{", "!", "if", "for", "while", "until", "case", "select", "function":
+		return true
+	}
+	return false
+}
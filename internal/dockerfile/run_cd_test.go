@@ -0,0 +1,65 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunCdUsage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+RUN cd /app && make
+RUN cd "/opt/my app" && ./configure && \
+    make install
+RUN ["sh", "-c", "cd /src && go build ./..."]
+RUN cd $HOME && ls
+RUN (cd /app && make)
+RUN for d in a b; do cd $d && make; done
+RUN { cd /app && make; }
+RUN if [ -d /app ]; then cd /app && make; fi
+RUN cd "$(mktemp -d)" && curl -O https://example.com/x.tgz
+RUN cd /app || exit 1
+RUN cd /app; make
+RUN cd - && make
+RUN cd
+RUN make && cd /app && make install
+RUN echo "cd /app && make"
+RUN ["cd", "/app"]
+`))
+	require.NoError(t, err)
+
+	lines, err := ast.RunCdUsage()
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4, 6, 7}, lines)
+}
+
+func TestLeadingCommand(t *testing.T) {
+	for _, tc := range []struct {
+		script string
+		words  []string
+		op     string
+		ok     bool
+	}{
+		{"cd /app && make", []string{"cd", "/app"}, "&&", true},
+		{"cd 'a b' | cat", []string{"cd", "a b"}, "|", true},
+		{`cd "a \"b\"" &`, []string{"cd", `a "b"`}, "&", true},
+		{"cd a\\ b\nmake", []string{"cd", "a b"}, ";", true},
+		{"# comment\ncd /app && make", []string{"cd", "/app"}, "&&", true},
+		{"\n\n  make", []string{"make"}, "", true},
+		{"cd /app > /dev/null && make", nil, "", false},
+		{"cd `pwd` && make", nil, "", false},
+		{"cd \"unterminated && make", nil, "", false},
+		{"&& make", nil, "", false},
+		{"while true; do cd /app; done", nil, "", false},
+		{"", nil, "", false},
+	} {
+		t.Run(tc.script, func(t *testing.T) {
+			words, op, ok := leadingCommand(tc.script)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.words, words)
+			assert.Equal(t, tc.op, op)
+		})
+	}
+}
@@ -0,0 +1,217 @@
+package dockerfile
+
+import (
+	"strings"
+	"time"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// The shell that runs shell-form commands, until a SHELL changes it.
+var defaultShell = []string{"/bin/sh", "-c"}
+
+// What a container runtime needs to know to run the image built from the
+// final stage: the settings that a Dockerfile writes into the image
+// config.
+//
+// Settings that the final stage inherits from an earlier stage it builds
+// FROM are included. Settings from an external base image aren't known, so
+// an empty field means "whatever the base image says".
+type RuntimeContract struct {
+	// The final stage.
+	Stage     int
+	StageName string
+	BaseName  string
+
+	// The USER, with ARG and ENV references expanded.
+	User string
+
+	// The WORKDIR. Defaults to "/".
+	WorkDir string
+
+	// The ENV variables, with references expanded.
+	Env map[string]string
+
+	// The EXPOSEd ports, in order, without duplicates.
+	ExposedPorts []ExposedPort
+
+	// The VOLUME mount points, in order, without duplicates.
+	Volumes []string
+
+	// The ENTRYPOINT and CMD, as the image config stores them: shell-form
+	// commands are wrapped in the SHELL (e.g., ["/bin/sh", "-c", "app"]).
+	// Arguments aren't expanded, since the container's shell does that.
+	//
+	// An ENTRYPOINT resets a CMD inherited from an earlier stage, as in
+	// `docker build`.
+	Entrypoint []string
+	Cmd        []string
+
+	// The HEALTHCHECK, or nil if there's none (or HEALTHCHECK NONE).
+	Healthcheck *Healthcheck
+
+	// The STOPSIGNAL, e.g., "SIGQUIT".
+	StopSignal string
+}
+
+// A HEALTHCHECK, as the image config stores it.
+type Healthcheck struct {
+	// The test, e.g., ["CMD", "curl", "-f", "localhost"] or
+	// ["CMD-SHELL", "curl -f localhost"].
+	Test []string
+
+	// Zero means the runtime's default.
+	Interval    time.Duration
+	Timeout     time.Duration
+	StartPeriod time.Duration
+	Retries     int
+}
+
+// The contract as of some point in a stage, plus the state that later
+// instructions need.
+type contractState struct {
+	contract RuntimeContract
+	shell    []string
+
+	// Whether the stage itself set CMD, so that a later ENTRYPOINT keeps it.
+	cmdSet bool
+}
+
+func (s contractState) clone() contractState {
+	// Env is replaced by the new stage's, so doesn't need a copy.
+	c := s.contract
+	c.ExposedPorts = append([]ExposedPort(nil), c.ExposedPorts...)
+	c.Volumes = append([]string(nil), c.Volumes...)
+	c.Entrypoint = append([]string(nil), c.Entrypoint...)
+	c.Cmd = append([]string(nil), c.Cmd...)
+	if c.Healthcheck != nil {
+		h := *c.Healthcheck
+		h.Test = append([]string(nil), h.Test...)
+		c.Healthcheck = &h
+	}
+	return contractState{
+		contract: c,
+		shell:    append([]string(nil), s.shell...),
+	}
+}
+
+// RuntimeContract returns the runtime settings of the final stage (USER,
+// WORKDIR, ENV, EXPOSE, VOLUME, ENTRYPOINT, CMD, HEALTHCHECK, and
+// STOPSIGNAL) in one pass over the Dockerfile, so that a scheduler can
+// know how to run the image without building and inspecting it.
+//
+// Returns the zero contract if there are no stages.
+func (a AST) RuntimeContract(buildArgs []string) (RuntimeContract, error) {
+	var cur contractState
+	stages := map[string]contractState{}
+	saveStage := func() {
+		if cur.contract.StageName != "" {
+			stages[strings.ToLower(cur.contract.StageName)] = cur
+		}
+	}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if st.stageIndex < 0 {
+			return nil
+		}
+
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			saveStage()
+			if prev, ok := stages[strings.ToLower(st.baseName)]; ok {
+				cur = prev.clone()
+			} else {
+				cur = contractState{shell: defaultShell}
+			}
+			cur.cmdSet = false
+			cur.contract.Stage = st.stageIndex
+			cur.contract.StageName = st.stageName
+			cur.contract.BaseName = st.baseName
+			cur.contract.User = st.user
+			cur.contract.WorkDir = st.workDir
+			cur.contract.Env = st.vars.envSnapshot()
+
+		case *instructions.EnvCommand:
+			env := st.vars.envSnapshot()
+			values := st.vars.expandEnv(inst)
+			for i, kv := range inst.Env {
+				env[kv.Key] = values[i]
+			}
+			cur.contract.Env = env
+
+		case *instructions.UserCommand:
+			cur.contract.User = st.vars.expand(inst.User)
+
+		case *instructions.WorkdirCommand:
+			cur.contract.WorkDir = resolveWorkDir(st.workDir, st.vars.expand(inst.Path))
+
+		case *instructions.ExposeCommand:
+			for _, port := range exposedPorts(node, st) {
+				if !containsPort(cur.contract.ExposedPorts, port) {
+					cur.contract.ExposedPorts = append(cur.contract.ExposedPorts, port)
+				}
+			}
+
+		case *instructions.VolumeCommand:
+			for _, v := range inst.Volumes {
+				cur.contract.Volumes = appendUnique(cur.contract.Volumes, st.vars.expand(v))
+			}
+
+		case *instructions.ShellCommand:
+			cur.shell = append([]string(nil), inst.Shell...)
+
+		case *instructions.EntrypointCommand:
+			cur.contract.Entrypoint = cur.command(inst.ShellDependantCmdLine)
+			if !cur.cmdSet {
+				cur.contract.Cmd = nil
+			}
+
+		case *instructions.CmdCommand:
+			cur.contract.Cmd = cur.command(inst.ShellDependantCmdLine)
+			cur.cmdSet = true
+
+		case *instructions.HealthCheckCommand:
+			cur.contract.Healthcheck = newHealthcheck(inst)
+
+		case *instructions.StopSignalCommand:
+			cur.contract.StopSignal = st.vars.expand(inst.Signal)
+		}
+		return nil
+	})
+	if err != nil {
+		return RuntimeContract{}, err
+	}
+	return cur.contract, nil
+}
+
+// command returns an ENTRYPOINT or CMD as the image config stores it.
+func (s contractState) command(cmd instructions.ShellDependantCmdLine) []string {
+	if !cmd.PrependShell {
+		return append([]string(nil), cmd.CmdLine...)
+	}
+	return append(append([]string(nil), s.shell...), strings.Join(cmd.CmdLine, " "))
+}
+
+func newHealthcheck(inst *instructions.HealthCheckCommand) *Healthcheck {
+	h := inst.Health
+	if h == nil || len(h.Test) == 0 || h.Test[0] == "NONE" {
+		return nil
+	}
+	return &Healthcheck{
+		Test:        append([]string(nil), h.Test...),
+		Interval:    h.Interval,
+		Timeout:     h.Timeout,
+		StartPeriod: h.StartPeriod,
+		Retries:     h.Retries,
+	}
+}
+
+func containsPort(ports []ExposedPort, port ExposedPort) bool {
+	for _, p := range ports {
+		if p.Port == port.Port && p.Protocol == port.Protocol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeContract(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG PORT=8080
+FROM golang:1.21 AS builder
+USER builder
+EXPOSE 9999
+CMD ["go", "test"]
+
+FROM alpine AS runtime
+ARG PORT
+ENV APP_HOME=/srv/app LOG_LEVEL=info
+ENV CONFIG=$APP_HOME/config.yaml
+WORKDIR $APP_HOME
+USER 1000:1000
+EXPOSE $PORT 53/udp
+EXPOSE 8080/tcp
+VOLUME ["/data", "$APP_HOME/cache"]
+VOLUME /data
+HEALTHCHECK --interval=30s --timeout=5s --retries=3 CMD wget -q -O- localhost:$PORT/healthz
+STOPSIGNAL SIGQUIT
+ENTRYPOINT ["/srv/app/server"]
+CMD ["--config", "/srv/app/config.yaml"]
+`))
+	require.NoError(t, err)
+
+	c, err := ast.RuntimeContract(nil)
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeContract{
+		Stage:     1,
+		StageName: "runtime",
+		BaseName:  "alpine",
+		User:      "1000:1000",
+		WorkDir:   "/srv/app",
+		Env: map[string]string{
+			"APP_HOME":  "/srv/app",
+			"LOG_LEVEL": "info",
+			"CONFIG":    "/srv/app/config.yaml",
+		},
+		ExposedPorts: []ExposedPort{
+			{Line: 14, Stage: 1, Port: "8080", Protocol: "tcp"},
+			{Line: 14, Stage: 1, Port: "53", Protocol: "udp"},
+		},
+		Volumes:    []string{"/data", "/srv/app/cache"},
+		Entrypoint: []string{"/srv/app/server"},
+		Cmd:        []string{"--config", "/srv/app/config.yaml"},
+		Healthcheck: &Healthcheck{
+			Test:     []string{"CMD-SHELL", "wget -q -O- localhost:$PORT/healthz"},
+			Interval: 30 * time.Second,
+			Timeout:  5 * time.Second,
+			Retries:  3,
+		},
+		StopSignal: "SIGQUIT",
+	}, c)
+}
+
+func TestRuntimeContractInheritsFromEarlierStage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS base
+ENV APP_ENV=production
+WORKDIR /app
+EXPOSE 80
+VOLUME /data
+CMD ["serve"]
+HEALTHCHECK CMD ["healthcheck"]
+STOPSIGNAL SIGTERM
+
+FROM base
+EXPOSE 443
+SHELL ["/bin/bash", "-c"]
+ENTRYPOINT exec app
+HEALTHCHECK NONE
+`))
+	require.NoError(t, err)
+
+	c, err := ast.RuntimeContract(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "base", c.BaseName)
+	assert.Equal(t, "/app", c.WorkDir)
+	assert.Equal(t, map[string]string{"APP_ENV": "production"}, c.Env)
+	assert.Equal(t, []string{"80/tcp", "443/tcp"}, portStrings(c.ExposedPorts))
+	assert.Equal(t, []string{"/data"}, c.Volumes)
+	assert.Equal(t, []string{"/bin/bash", "-c", "exec app"}, c.Entrypoint)
+
+	// The ENTRYPOINT resets the inherited CMD.
+	assert.Nil(t, c.Cmd)
+	assert.Nil(t, c.Healthcheck)
+	assert.Equal(t, "SIGTERM", c.StopSignal)
+}
+
+func TestRuntimeContractCmdBeforeEntrypoint(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+CMD echo hi
+ENTRYPOINT ["/entrypoint.sh"]
+`))
+	require.NoError(t, err)
+
+	c, err := ast.RuntimeContract(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/entrypoint.sh"}, c.Entrypoint)
+	assert.Equal(t, []string{"/bin/sh", "-c", "echo hi"}, c.Cmd)
+}
+
+func TestRuntimeContractExternalBase(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine AS builder
+USER builder
+CMD ["make"]
+
+FROM nginx
+`))
+	require.NoError(t, err)
+
+	c, err := ast.RuntimeContract(nil)
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeContract{
+		Stage:    1,
+		BaseName: "nginx",
+		WorkDir:  "/",
+		Env:      map[string]string{},
+	}, c)
+}
+
+func TestRuntimeContractNoStages(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`ARG FOO=bar`))
+	require.NoError(t, err)
+
+	c, err := ast.RuntimeContract(nil)
+	require.NoError(t, err)
+	assert.Equal(t, RuntimeContract{}, c)
+}
+
+func portStrings(ports []ExposedPort) []string {
+	var result []string
+	for _, p := range ports {
+		result = append(result, p.String())
+	}
+	return result
+}
+
+// A Dockerfile with many stages, each setting every runtime setting.
+func benchmarkDockerfile() Dockerfile {
+	sb := strings.Builder{}
+	sb.WriteString("ARG VERSION=1.0\n")
+	for i := 0; i < 14; i++ {
+		if i == 0 {
+			sb.WriteString("FROM alpine AS stage0\n")
+		} else {
+			sb.WriteString(fmt.Sprintf("FROM stage%d AS stage%d\n", i-1, i))
+		}
+		sb.WriteString("ARG VERSION\n")
+		for j := 0; j < 5; j++ {
+			sb.WriteString(fmt.Sprintf("ENV VAR_%d_%d=$VERSION\n", i, j))
+			sb.WriteString(fmt.Sprintf("RUN echo %d %d\n", i, j))
+		}
+		sb.WriteString(fmt.Sprintf("WORKDIR /app/%d\n", i))
+		sb.WriteString(fmt.Sprintf("USER %d\n", 1000+i))
+		sb.WriteString(fmt.Sprintf("EXPOSE %d\n", 8000+i))
+		sb.WriteString(fmt.Sprintf("VOLUME /data/%d\n", i))
+		sb.WriteString("HEALTHCHECK CMD [\"healthcheck\"]\n")
+		sb.WriteString("STOPSIGNAL SIGTERM\n")
+		sb.WriteString("ENTRYPOINT [\"/app/server\"]\n")
+		sb.WriteString("CMD [\"--verbose\"]\n")
+	}
+	return Dockerfile(sb.String())
+}
+
+func BenchmarkRuntimeContract(b *testing.B) {
+	ast, err := ParseAST(benchmarkDockerfile())
+	require.NoError(b, err)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := ast.RuntimeContract(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// The existing extractors only cover WORKDIR, EXPOSE, and ENV, and each
+// walks the whole Dockerfile.
+func BenchmarkRuntimeContractSeparateExtractors(b *testing.B) {
+	ast, err := ParseAST(benchmarkDockerfile())
+	require.NoError(b, err)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, err := ast.WorkDir(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = ast.ExposedPorts(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, err = ast.EnvTimeline(nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
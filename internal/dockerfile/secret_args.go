@@ -0,0 +1,128 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// An ARG that looks like a secret, and the RUNs that read it.
+type SecretArgFinding struct {
+	Stage     int
+	StageName string
+	Name      string
+
+	// The line of the ARG in the stage.
+	Line int
+
+	// The lines of the RUNs that reference the ARG.
+	RunLines []int
+
+	Message string
+}
+
+// Words that end the name of a secret, e.g., NPM_TOKEN or DB_PASSWORD.
+var secretArgSuffixes = map[string]bool{
+	"token":       true,
+	"password":    true,
+	"passwd":      true,
+	"key":         true,
+	"secret":      true,
+	"credentials": true,
+}
+
+// Words that make a name a secret wherever they appear, e.g.,
+// SECRET_KEY_BASE.
+var secretArgWords = map[string]bool{
+	"secret":   true,
+	"password": true,
+	"passwd":   true,
+}
+
+func isSecretArgName(name string) bool {
+	words := argNameWords(name)
+	if len(words) == 0 {
+		return false
+	}
+	if secretArgSuffixes[words[len(words)-1]] {
+		return true
+	}
+	for _, w := range words {
+		if secretArgWords[w] {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretArgs finds ARGs whose names look like secrets (e.g., NPM_TOKEN,
+// DB_PASSWORD, API_KEY) and that a RUN references. The value of a build
+// arg that a RUN uses is recorded in the image history, so anyone who can
+// pull the image can read it. A secret mount (`RUN --mount=type=secret`)
+// makes the secret available to the RUN without storing it.
+//
+// A RUN can only read an ARG that's declared in its stage, so findings are
+// per stage, with the line of the ARG in that stage. ARGs that no RUN
+// references aren't reported. An ENV of the same name hides the ARG from
+// later RUNs (and is its own, separate leak).
+func (a AST) SecretArgs() ([]SecretArgFinding, error) {
+	var result []*SecretArgFinding
+
+	// The findings for the current stage, by ARG name.
+	var current map[string]*SecretArgFinding
+	err := a.walkInstructions(nil, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			current = map[string]*SecretArgFinding{}
+
+		case *instructions.ArgCommand:
+			if st.stageIndex < 0 {
+				return nil
+			}
+			for _, kv := range inst.Args {
+				if !isSecretArgName(kv.Key) || current[kv.Key] != nil {
+					continue
+				}
+				f := &SecretArgFinding{
+					Stage:     st.stageIndex,
+					StageName: st.stageName,
+					Name:      kv.Key,
+					Line:      node.StartLine,
+					Message: fmt.Sprintf(
+						"ARG %s looks like a secret, and build args that a RUN uses are stored in the image history. "+
+							"Pass it with `RUN --mount=type=secret,id=%s` instead",
+						kv.Key, strings.ToLower(kv.Key)),
+				}
+				current[kv.Key] = f
+				result = append(result, f)
+			}
+
+		case *instructions.RunCommand:
+			script := strings.Join(inst.CmdLine, " ")
+			for _, h := range node.Heredocs {
+				script += "\n" + h.Content
+			}
+			for _, name := range referencedVars(script) {
+				f := current[name]
+				if f == nil || !st.vars.isArg(name) {
+					continue
+				}
+				f.RunLines = append(f.RunLines, node.StartLine)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []SecretArgFinding
+	for _, f := range result {
+		if len(f.RunLines) > 0 {
+			findings = append(findings, *f)
+		}
+	}
+	return findings, nil
+}
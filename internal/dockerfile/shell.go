@@ -0,0 +1,49 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+)
+
+// A `SHELL` that isn't in JSON exec form, like `SHELL /bin/bash -c`.
+type ShellFormError struct {
+	Line int
+
+	// The arguments as written.
+	Value string
+}
+
+func (e ShellFormError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("line %d: SHELL requires at least one argument", e.Line)
+	}
+	return fmt.Sprintf("line %d: SHELL must use JSON array form, like SHELL [\"/bin/bash\", \"-c\"]: got %q",
+		e.Line, e.Value)
+}
+
+// ValidateShellInstructions checks that each SHELL uses the JSON exec form,
+// returning a ShellFormError for each that doesn't.
+//
+// Unlike RUN or CMD, SHELL has no shell form, so the builder rejects it.
+func (a AST) ValidateShellInstructions() []error {
+	var result []error
+	for _, node := range a.result.AST.Children {
+		if strings.ToLower(node.Value) != command.Shell {
+			continue
+		}
+
+		var args []string
+		for n := node.Next; n != nil; n = n.Next {
+			args = append(args, n.Value)
+		}
+
+		if !node.Attributes["json"] {
+			result = append(result, ShellFormError{Line: node.StartLine, Value: strings.Join(args, " ")})
+		} else if len(args) == 0 {
+			result = append(result, ShellFormError{Line: node.StartLine})
+		}
+	}
+	return result
+}
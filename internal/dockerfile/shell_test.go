@@ -0,0 +1,37 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateShellInstructions(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+SHELL ["/bin/sh", "-c"]
+SHELL /bin/bash -c
+shell ["powershell", "-command"]
+SHELL []
+`))
+	require.NoError(t, err)
+
+	errs := ast.ValidateShellInstructions()
+	assert.Equal(t, []error{
+		ShellFormError{Line: 4, Value: "/bin/bash -c"},
+		ShellFormError{Line: 6},
+	}, errs)
+	assert.EqualError(t, errs[0], `line 4: SHELL must use JSON array form, like SHELL ["/bin/bash", "-c"]: got "/bin/bash -c"`)
+	assert.EqualError(t, errs[1], `line 6: SHELL requires at least one argument`)
+}
+
+func TestValidateShellInstructionsValid(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+SHELL ["/bin/bash", "-o", "pipefail", "-c"]
+RUN echo hi
+`))
+	require.NoError(t, err)
+	assert.Empty(t, ast.ValidateShellInstructions())
+}
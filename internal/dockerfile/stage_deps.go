@@ -0,0 +1,83 @@
+package dockerfile
+
+import (
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// StageContextDependencies finds the build context paths that each stage
+// reads, keyed by stage index. A file change only invalidates the stages
+// whose paths include it (and the stages built from them).
+//
+// Paths are slash-separated and relative to the context, in the order
+// they're first used. They come from COPY and ADD sources, and from
+// `RUN --mount=type=bind` mounts of the context. Copies from other stages
+// or images, and remote ADDs, don't read the context and are left out.
+//
+// Globs are kept as written (with ARGs expanded), so match them with
+// path.Match. A stage that reads the whole context (e.g., `COPY . .`)
+// maps to just ".". Every stage has an entry, even if it's empty.
+func (a AST) StageContextDependencies(buildArgs []string) (map[int][]string, error) {
+	result := map[int][]string{}
+	wholeContext := map[int]bool{}
+	seen := map[int]map[string]bool{}
+
+	add := func(stage int, src string) {
+		if wholeContext[stage] {
+			return
+		}
+		if seen[stage] == nil {
+			seen[stage] = map[string]bool{}
+		}
+		p := path.Clean(strings.TrimPrefix(src, "/"))
+		if p == "." || p == "/" || p == "*" {
+			wholeContext[stage] = true
+			result[stage] = []string{"."}
+			return
+		}
+		if seen[stage][p] {
+			return
+		}
+		seen[stage][p] = true
+		result[stage] = append(result[stage], p)
+	}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if st.stageIndex < 0 {
+			return nil
+		}
+
+		if strings.ToLower(node.Value) == command.From {
+			result[st.stageIndex] = []string{}
+			return nil
+		}
+
+		for _, src := range contextSources(inst) {
+			add(st.stageIndex, st.vars.expand(src))
+		}
+
+		run, ok := inst.(*instructions.RunCommand)
+		if !ok {
+			return nil
+		}
+
+		mounts, ok := st.vars.expandMounts(run)
+		if !ok {
+			return nil
+		}
+		for _, m := range mounts {
+			if m.Type == instructions.MountTypeBind && m.From == "" {
+				add(st.stageIndex, m.Source)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
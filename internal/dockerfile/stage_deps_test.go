@@ -0,0 +1,76 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageContextDependencies(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+ARG APP=api
+COPY go.mod go.sum ./
+COPY ./cmd/$APP /src/cmd/$APP
+COPY pkg/ /src/pkg/
+COPY go.mod /tmp/go.mod
+RUN --mount=type=bind,source=vendor,target=/src/vendor go build ./...
+
+FROM node:18 AS web
+COPY web/*.json ./
+ADD https://example.com/font.woff /fonts/
+ADD web/src /src
+
+FROM alpine
+COPY --from=builder /out/api /api
+COPY --from=web /dist /www
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+`))
+	require.NoError(t, err)
+
+	deps, err := ast.StageContextDependencies(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{
+		0: {"go.mod", "go.sum", "cmd/api", "pkg", "vendor"},
+		1: {"web/*.json", "web/src"},
+		2: {},
+	}, deps)
+}
+
+func TestStageContextDependenciesWholeContext(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21
+COPY go.mod ./
+COPY . .
+COPY extra /extra
+
+FROM alpine
+RUN --mount=type=bind,target=/src make -C /src
+
+FROM alpine
+COPY ./ /src
+`))
+	require.NoError(t, err)
+
+	deps, err := ast.StageContextDependencies(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{
+		0: {"."},
+		1: {"."},
+		2: {"."},
+	}, deps)
+}
+
+func TestStageContextDependenciesBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21
+ARG APP
+COPY cmd/${APP} /src
+`))
+	require.NoError(t, err)
+
+	deps, err := ast.StageContextDependencies([]string{"APP=worker"})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{0: {"cmd/worker"}}, deps)
+}
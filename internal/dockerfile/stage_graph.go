@@ -0,0 +1,106 @@
+package dockerfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+type GraphEdgeKind string
+
+const (
+	// The stage is built FROM the image or stage.
+	EdgeDerivesFrom GraphEdgeKind = "derives-from"
+
+	// The stage has a COPY --from the image or stage.
+	EdgeCopiesFrom GraphEdgeKind = "copies-from"
+)
+
+// A stage, or an image from outside the Dockerfile.
+type GraphNode struct {
+	ID string
+
+	// The index of the stage, or -1 for an external image.
+	Stage int
+
+	// The stage name (or "stage N" if unnamed), or the image.
+	Label string
+}
+
+// An edge from a stage to the image or stage it depends on.
+type GraphEdge struct {
+	From string
+	To   string
+	Kind GraphEdgeKind
+}
+
+type DependencyGraph struct {
+	// Stages in order, then external images in the order they're first used.
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// DependencyGraph finds what each stage depends on: the image or stage it's
+// built FROM, and the images and stages it copies from.
+func (a AST) DependencyGraph(buildArgs []string) (DependencyGraph, error) {
+	var stages, images []GraphNode
+	var edges []GraphEdge
+	seenImages := map[string]bool{}
+	seenEdges := map[GraphEdge]bool{}
+
+	byName := map[string]int{}
+
+	// The ID of the image or earlier stage that ref points to.
+	resolve := func(ref string, current int) string {
+		if index, ok := resolveStageRef(ref, byName, current); ok {
+			return stageNodeID(index)
+		}
+		id := "image:" + ref
+		if !seenImages[id] {
+			seenImages[id] = true
+			images = append(images, GraphNode{ID: id, Stage: -1, Label: ref})
+		}
+		return id
+	}
+	addEdge := func(e GraphEdge) {
+		if !seenEdges[e] {
+			seenEdges[e] = true
+			edges = append(edges, e)
+		}
+	}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			label := inst.Name
+			if label == "" {
+				label = fmt.Sprintf("stage %d", st.stageIndex)
+			}
+			stages = append(stages, GraphNode{ID: stageNodeID(st.stageIndex), Stage: st.stageIndex, Label: label})
+			if st.baseName != "" {
+				addEdge(GraphEdge{From: stageNodeID(st.stageIndex), To: resolve(st.baseName, st.stageIndex), Kind: EdgeDerivesFrom})
+			}
+			if inst.Name != "" {
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+			}
+
+		case *instructions.CopyCommand:
+			from := st.vars.expand(inst.From)
+			if from == "" || st.stageIndex < 0 {
+				return nil
+			}
+			addEdge(GraphEdge{From: stageNodeID(st.stageIndex), To: resolve(from, st.stageIndex), Kind: EdgeCopiesFrom})
+		}
+		return nil
+	})
+	if err != nil {
+		return DependencyGraph{}, err
+	}
+	return DependencyGraph{Nodes: append(stages, images...), Edges: edges}, nil
+}
+
+func stageNodeID(index int) string {
+	return fmt.Sprintf("stage:%d", index)
+}
@@ -0,0 +1,45 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG GO_VERSION=1.20
+FROM golang:${GO_VERSION} AS build
+RUN go build ./...
+
+FROM build AS test
+RUN go test ./...
+
+FROM alpine
+COPY --from=build /app /app
+COPY --from=build /lib /lib
+COPY --from=nginx:latest /etc/nginx /etc/nginx
+COPY --from=1 /report /report
+`))
+	require.NoError(t, err)
+
+	g, err := ast.DependencyGraph(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []GraphNode{
+		{ID: "stage:0", Stage: 0, Label: "build"},
+		{ID: "stage:1", Stage: 1, Label: "test"},
+		{ID: "stage:2", Stage: 2, Label: "stage 2"},
+		{ID: "image:golang:1.20", Stage: -1, Label: "golang:1.20"},
+		{ID: "image:alpine", Stage: -1, Label: "alpine"},
+		{ID: "image:nginx:latest", Stage: -1, Label: "nginx:latest"},
+	}, g.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "stage:0", To: "image:golang:1.20", Kind: EdgeDerivesFrom},
+		{From: "stage:1", To: "stage:0", Kind: EdgeDerivesFrom},
+		{From: "stage:2", To: "image:alpine", Kind: EdgeDerivesFrom},
+		{From: "stage:2", To: "stage:0", Kind: EdgeCopiesFrom},
+		{From: "stage:2", To: "image:nginx:latest", Kind: EdgeCopiesFrom},
+		{From: "stage:2", To: "stage:1", Kind: EdgeCopiesFrom},
+	}, g.Edges)
+}
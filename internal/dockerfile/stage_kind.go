@@ -0,0 +1,224 @@
+package dockerfile
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+type StageRole string
+
+const (
+	// A stage that installs toolchains and compiles.
+	StageBuilder StageRole = "builder"
+
+	// A minimal stage that copies in artifacts and runs them.
+	StageRuntime StageRole = "runtime"
+)
+
+// The role of a stage, with the evidence for it.
+type StageKind struct {
+	Role StageRole
+
+	// How much of the evidence points to Role, from 0.5 (a coin flip) to 1.
+	Confidence float64
+
+	// The evidence, in the order it appears. Each reason starts with the
+	// role it points to, e.g., "builder: runs go build".
+	Reasons []string
+}
+
+// Base images (without registry or tag) that ship a toolchain.
+var builderImages = map[string]bool{
+	"golang": true, "rust": true, "node": true, "maven": true, "gradle": true,
+	"openjdk": true, "eclipse-temurin": true, "gcc": true, "buildpack-deps": true,
+	"sdk": true, "swift": true, "elixir": true, "haskell": true, "sbt": true,
+}
+
+// Base images that are minimal.
+var runtimeImages = map[string]bool{
+	"scratch": true, "alpine": true, "busybox": true, "distroless": true,
+	"static": true, "static-debian11": true, "static-debian12": true,
+	"base": true, "base-debian11": true, "base-debian12": true,
+	"cc-debian11": true, "cc-debian12": true, "ubi-minimal": true, "ubi-micro": true,
+	"runtime": true, "aspnet": true, "runtime-deps": true, "nginx": true,
+}
+
+// Commands that compile or bundle, as the first one or two words of a
+// shell command.
+var buildCommands = []string{
+	"go build", "go install", "cargo build", "make", "cmake", "gcc", "g++",
+	"clang", "mvn", "gradle", "./gradlew", "./mvnw", "javac", "tsc", "webpack",
+	"npm run build", "npm ci", "yarn build", "yarn install", "pnpm build",
+	"dotnet build", "dotnet publish", "pip wheel", "python setup.py",
+}
+
+// Packages that provide a toolchain.
+var buildPackages = map[string]bool{
+	"gcc": true, "g++": true, "clang": true, "make": true, "cmake": true,
+	"build-essential": true, "build-base": true, "musl-dev": true,
+	"libc-dev": true, "libc6-dev": true, "git": true, "autoconf": true,
+	"automake": true, "pkg-config": true, "pkgconf": true,
+}
+
+// The evidence about one stage.
+type stageEvidence struct {
+	builder, runtime int
+	reasons          []string
+}
+
+func (e *stageEvidence) add(role StageRole, weight int, reason string) {
+	if role == StageBuilder {
+		e.builder += weight
+	} else {
+		e.runtime += weight
+	}
+	e.reasons = append(e.reasons, fmt.Sprintf("%s: %s", role, reason))
+}
+
+// ClassifyStages labels each stage, by index, as a builder or runtime
+// stage.
+//
+// This is a heuristic that weighs the evidence for each role:
+//
+//   - builder: the base image ships a toolchain (e.g., golang, node, maven)
+//   - builder: a RUN compiles or bundles (e.g., go build, make, npm run build)
+//   - builder: a RUN installs toolchain packages (e.g., gcc, build-essential)
+//   - builder: a later stage copies files from the stage
+//   - runtime: the base image is minimal (e.g., scratch, alpine, distroless)
+//   - runtime: it's the final stage
+//   - runtime: it sets CMD or ENTRYPOINT
+//   - runtime: it copies files from another stage and runs nothing
+//
+// Base images and being copied from or being final weigh twice as much
+// as the rest. Ties go to runtime for the final stage, and to builder
+// otherwise. A stage built FROM an earlier stage doesn't get evidence
+// from its base image.
+func (a AST) ClassifyStages(buildArgs []string) (map[int]StageKind, error) {
+	var evidence []*stageEvidence
+	copiedFrom := map[int]bool{}
+	hasRun := map[int]bool{}
+	hasCopyFrom := map[int]bool{}
+
+	byName := map[string]int{}
+
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		if st.stageIndex < 0 {
+			return nil
+		}
+		var e *stageEvidence
+		if st.stageIndex < len(evidence) {
+			e = evidence[st.stageIndex]
+		}
+
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			e = &stageEvidence{}
+			evidence = append(evidence, e)
+			if _, isStage := resolveStageRef(st.baseName, byName, st.stageIndex); !isStage {
+				classifyBaseImage(e, st.baseName)
+			}
+			if inst.Name != "" {
+				byName[strings.ToLower(inst.Name)] = st.stageIndex
+			}
+
+		case *instructions.RunCommand:
+			hasRun[st.stageIndex] = true
+			classifyRun(e, runScript(node, inst, st.vars))
+
+		case *instructions.CopyCommand:
+			from := st.vars.expand(inst.From)
+			if from == "" {
+				return nil
+			}
+			if index, ok := resolveStageRef(from, byName, st.stageIndex); ok {
+				copiedFrom[index] = true
+				hasCopyFrom[st.stageIndex] = true
+			}
+
+		case *instructions.CmdCommand, *instructions.EntrypointCommand:
+			if e != nil {
+				e.add(StageRuntime, 1, "sets "+strings.ToUpper(node.Value))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int]StageKind, len(evidence))
+	last := len(evidence) - 1
+	for i, e := range evidence {
+		if copiedFrom[i] {
+			e.add(StageBuilder, 2, "a later stage copies from it")
+		}
+		if i == last {
+			e.add(StageRuntime, 2, "it's the final stage")
+		}
+		if hasCopyFrom[i] && !hasRun[i] {
+			e.add(StageRuntime, 1, "it copies from another stage and runs nothing")
+		}
+
+		role := StageBuilder
+		if e.runtime > e.builder || (e.runtime == e.builder && i == last) {
+			role = StageRuntime
+		}
+
+		confidence := 0.5
+		if total := e.builder + e.runtime; total > 0 {
+			winner := e.builder
+			if role == StageRuntime {
+				winner = e.runtime
+			}
+			confidence = float64(winner) / float64(total)
+		}
+		result[i] = StageKind{Role: role, Confidence: confidence, Reasons: e.reasons}
+	}
+	return result, nil
+}
+
+func classifyBaseImage(e *stageEvidence, baseName string) {
+	name := strings.ToLower(baseName)
+	tag := ""
+	if i := strings.LastIndexAny(name, ":@"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	repo := path.Base(name)
+
+	switch {
+	case builderImages[repo] || strings.Contains(tag, "sdk") || strings.HasSuffix(tag, "-dev"):
+		e.add(StageBuilder, 2, fmt.Sprintf("base image %s ships a toolchain", baseName))
+	case runtimeImages[repo] || strings.Contains(tag, "slim") || strings.Contains(tag, "jre") ||
+		strings.Contains(name, "distroless"):
+		e.add(StageRuntime, 2, fmt.Sprintf("base image %s is minimal", baseName))
+	}
+}
+
+func classifyRun(e *stageEvidence, script string) {
+	for _, segment := range strings.FieldsFunc(script, func(r rune) bool {
+		return r == ';' || r == '&' || r == '|' || r == '\n'
+	}) {
+		words := strings.Fields(segment)
+		if len(words) == 0 {
+			continue
+		}
+
+		line := strings.Join(words, " ")
+		for _, cmd := range buildCommands {
+			if line == cmd || strings.HasPrefix(line, cmd+" ") {
+				e.add(StageBuilder, 1, "runs "+cmd)
+				break
+			}
+		}
+
+		for _, pkg := range installedPackages(words) {
+			if buildPackages[pkg] {
+				e.add(StageBuilder, 1, "installs "+pkg)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyStagesBuilderAndRuntime(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS build
+WORKDIR /src
+COPY . .
+RUN go build -o /out/app ./cmd/app
+
+FROM gcr.io/distroless/static-debian12
+COPY --from=build /out/app /app
+ENTRYPOINT ["/app"]
+`))
+	require.NoError(t, err)
+
+	kinds, err := ast.ClassifyStages(nil)
+	require.NoError(t, err)
+	require.Len(t, kinds, 2)
+
+	assert.Equal(t, StageKind{
+		Role:       StageBuilder,
+		Confidence: 1,
+		Reasons: []string{
+			"builder: base image golang:1.21 ships a toolchain",
+			"builder: runs go build",
+			"builder: a later stage copies from it",
+		},
+	}, kinds[0])
+
+	assert.Equal(t, StageKind{
+		Role:       StageRuntime,
+		Confidence: 1,
+		Reasons: []string{
+			"runtime: base image gcr.io/distroless/static-debian12 is minimal",
+			"runtime: sets ENTRYPOINT",
+			"runtime: it's the final stage",
+			"runtime: it copies from another stage and runs nothing",
+		},
+	}, kinds[1])
+}
+
+func TestClassifyStagesRuntimeInstallsCompiler(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=python:3.12-slim
+FROM ${BASE}
+RUN apt-get install -y gcc && pip install -r requirements.txt
+CMD ["python", "app.py"]
+`))
+	require.NoError(t, err)
+
+	kinds, err := ast.ClassifyStages(nil)
+	require.NoError(t, err)
+
+	// The evidence for builder is there, so callers can warn about it.
+	assert.Equal(t, StageRuntime, kinds[0].Role)
+	assert.InDelta(t, 5.0/6.0, kinds[0].Confidence, 0.001)
+	assert.Contains(t, kinds[0].Reasons, "builder: installs gcc")
+}
+
+func TestClassifyStagesSingleBuildStage(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM node:20
+RUN npm ci && npm run build
+CMD ["node", "dist/index.js"]
+`))
+	require.NoError(t, err)
+
+	kinds, err := ast.ClassifyStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, StageBuilder, kinds[0].Role)
+	assert.InDelta(t, 4.0/7.0, kinds[0].Confidence, 0.001)
+}
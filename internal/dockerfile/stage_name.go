@@ -0,0 +1,48 @@
+package dockerfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+)
+
+// The stage names that buildkit accepts (after lowercasing).
+var validStageNameRe = regexp.MustCompile(`^[a-z][a-z0-9-_.]*$`)
+
+// A `FROM ... AS <name>` with a name the builder would reject.
+type StageNameError struct {
+	Line int
+	Name string
+}
+
+func (e StageNameError) Error() string {
+	return fmt.Sprintf("line %d: invalid stage name %q: must start with a letter and contain only "+
+		"letters, digits, '-', '_', and '.'", e.Line, e.Name)
+}
+
+// ValidateStageNames checks each `FROM ... AS <name>` against the stage
+// name grammar, returning a StageNameError for each invalid name.
+func (a AST) ValidateStageNames() []error {
+	var result []error
+	for _, node := range a.result.AST.Children {
+		if strings.ToLower(node.Value) != command.From {
+			continue
+		}
+
+		var args []string
+		for n := node.Next; n != nil; n = n.Next {
+			args = append(args, n.Value)
+		}
+		if len(args) != 3 || !strings.EqualFold(args[1], "as") {
+			continue
+		}
+
+		name := args[2]
+		if !validStageNameRe.MatchString(strings.ToLower(name)) {
+			result = append(result, StageNameError{Line: node.StartLine, Name: name})
+		}
+	}
+	return result
+}
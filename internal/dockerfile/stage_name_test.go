@@ -0,0 +1,38 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStageNames(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS Builder
+FROM builder AS test_1.x-y
+FROM alpine AS 2nd
+FROM alpine as my@stage
+FROM alpine AS -dash
+FROM alpine
+`))
+	require.NoError(t, err)
+
+	errs := ast.ValidateStageNames()
+	assert.Equal(t, []error{
+		StageNameError{Line: 4, Name: "2nd"},
+		StageNameError{Line: 5, Name: "my@stage"},
+		StageNameError{Line: 6, Name: "-dash"},
+	}, errs)
+	assert.EqualError(t, errs[0], `line 4: invalid stage name "2nd": must start with a letter and contain only letters, digits, '-', '_', and '.'`)
+}
+
+func TestValidateStageNamesValid(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.21 AS builder
+FROM scratch
+COPY --from=builder /app /app
+`))
+	require.NoError(t, err)
+	assert.Empty(t, ast.ValidateStageNames())
+}
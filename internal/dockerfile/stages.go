@@ -0,0 +1,72 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A stage of a (possibly multi-stage) Dockerfile.
+type Stage struct {
+	// The name of the stage, from `FROM image AS name`, or empty if the
+	// stage isn't named. BuildKit lowercases it, since stage names aren't
+	// case-sensitive.
+	Name string
+
+	// The image that the stage is built from, with ARGs expanded.
+	//
+	// Nil if the stage is built from an earlier stage, from scratch, or
+	// from something that isn't a valid image ref.
+	BaseImage reference.Named
+
+	// The index of the stage, from 0.
+	Index int
+
+	// The line of the stage's FROM, from 1.
+	StartLine int
+}
+
+// ListStages returns every stage of the Dockerfile, in order.
+//
+// Base images are found the same way as ListBaseImages, so BaseImage is
+// only set for the stages whose image ListBaseImages would list.
+func (a AST) ListStages(buildArgs []string) ([]Stage, error) {
+	baseImages := map[*parser.Node]reference.Named{}
+	err := a.traverseImageRefs(func(node *parser.Node, ref reference.Named) reference.Named {
+		if strings.EqualFold(node.Value, command.From) && !isScratch(ref) {
+			baseImages[node] = ref
+		}
+		return nil
+	}, argInstructions(buildArgs))
+	if err != nil {
+		return nil, err
+	}
+
+	result := []Stage{}
+	err = a.TraverseWithContext(func(node *parser.Node, ctx NodeContext) error {
+		if ctx.Parent != a.result.AST || !strings.EqualFold(node.Value, command.From) {
+			return nil
+		}
+
+		stage := Stage{
+			BaseImage: baseImages[node],
+			Index:     ctx.Stage,
+			StartLine: node.StartLine,
+		}
+		inst, err := instructions.ParseInstruction(node)
+		if err == nil {
+			if s, ok := inst.(*instructions.Stage); ok {
+				stage.Name = s.Name
+			}
+		}
+		result = append(result, stage)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
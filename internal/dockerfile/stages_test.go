@@ -0,0 +1,116 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListStages(t *testing.T) {
+	df := Dockerfile(`
+ARG BASE=debian
+FROM golang:1.21 AS Builder
+RUN go build -o /app .
+
+FROM builder AS test
+RUN go test ./...
+
+FROM ${BASE}
+FROM scratch AS empty
+FROM gcr.io/distroless/static
+COPY --from=builder /app /app
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	stages, err := ast.ListStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []stageSummary{
+		{Name: "builder", BaseImage: "docker.io/library/golang:1.21", Index: 0, StartLine: 3},
+		{Name: "test", Index: 1, StartLine: 6},
+		{BaseImage: "docker.io/library/debian", Index: 2, StartLine: 9},
+		{Name: "empty", Index: 3, StartLine: 10},
+		{BaseImage: "gcr.io/distroless/static", Index: 4, StartLine: 11},
+	}, summarizeStages(stages))
+
+	stages, err = ast.ListStages([]string{"BASE=debian:12"})
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io/library/debian:12", stages[2].BaseImage.String())
+
+	// Listing doesn't change the Dockerfile.
+	printed, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, df, printed)
+}
+
+func TestListStagesScratchFromArg(t *testing.T) {
+	df := Dockerfile(`
+ARG BASE=scratch
+FROM $BASE AS base
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	stages, err := ast.ListStages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []stageSummary{
+		{Name: "base", Index: 0, StartLine: 3},
+	}, summarizeStages(stages))
+
+	stages, err = ast.ListStages([]string{"BASE=alpine"})
+	require.NoError(t, err)
+	assert.Equal(t, []stageSummary{
+		{Name: "base", BaseImage: "docker.io/library/alpine", Index: 0, StartLine: 3},
+	}, summarizeStages(stages))
+}
+
+func TestListStagesByIndex(t *testing.T) {
+	df := Dockerfile(`
+FROM alpine
+FROM 0
+FROM 2
+`)
+	ast, err := ParseAST(df)
+	require.NoError(t, err)
+
+	stages, err := ast.ListStages(nil)
+	require.NoError(t, err)
+
+	// Stage 0 is an earlier stage, but there's no stage 2 before the third FROM,
+	// so it's an image.
+	assert.Equal(t, []stageSummary{
+		{BaseImage: "docker.io/library/alpine", Index: 0, StartLine: 2},
+		{Index: 1, StartLine: 3},
+		{BaseImage: "docker.io/library/2", Index: 2, StartLine: 4},
+	}, summarizeStages(stages))
+}
+
+func TestListStagesEmpty(t *testing.T) {
+	ast, err := ParseAST(Dockerfile("ARG BASE\n"))
+	require.NoError(t, err)
+
+	stages, err := ast.ListStages(nil)
+	require.NoError(t, err)
+	assert.Empty(t, stages)
+}
+
+// A Stage with its base image as a string, for easier comparison.
+type stageSummary struct {
+	Name      string
+	BaseImage string
+	Index     int
+	StartLine int
+}
+
+func summarizeStages(stages []Stage) []stageSummary {
+	var result []stageSummary
+	for _, s := range stages {
+		summary := stageSummary{Name: s.Name, Index: s.Index, StartLine: s.StartLine}
+		if s.BaseImage != nil {
+			summary.BaseImage = s.BaseImage.String()
+		}
+		result = append(result, summary)
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package dockerfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// Whether a UIDFinding is a user or a group ID.
+const (
+	UIDKindUser  = "uid"
+	UIDKindGroup = "gid"
+)
+
+// A numeric user or group ID, from USER or a COPY/ADD --chown.
+type UIDFinding struct {
+	Stage     int
+	StageName string
+	Line      int
+
+	// "USER", "COPY", or "ADD".
+	Instruction string
+
+	// UIDKindUser or UIDKindGroup.
+	Kind string
+	ID   int
+
+	// The user[:group] as written, with ARGs expanded, e.g., "1000:1000".
+	Value string
+}
+
+// HardcodedUIDs finds the numeric user and group IDs that USER and
+// COPY/ADD --chown set, so that a policy tool can check them against the
+// ranges reserved on the host (e.g., IDs that collide with host users on
+// a shared node).
+//
+// A user:group value is reported as two findings, one for each ID. Names
+// (like USER app) aren't reported, since the image maps them to IDs.
+// Root (0) is reported like any other ID; callers that only care about
+// collisions can filter it out.
+func (a AST) HardcodedUIDs(buildArgs []string) ([]UIDFinding, error) {
+	var result []UIDFinding
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		var value string
+		switch inst := inst.(type) {
+		case *instructions.UserCommand:
+			value = inst.User
+		case *instructions.CopyCommand:
+			value = inst.Chown
+		case *instructions.AddCommand:
+			value = inst.Chown
+		default:
+			return nil
+		}
+
+		value = st.vars.expand(value)
+		user, group, _ := strings.Cut(value, ":")
+		for _, id := range []struct{ kind, s string }{
+			{UIDKindUser, user},
+			{UIDKindGroup, group},
+		} {
+			n, err := strconv.Atoi(id.s)
+			if err != nil || n < 0 {
+				continue
+			}
+			result = append(result, UIDFinding{
+				Stage:       st.stageIndex,
+				StageName:   st.stageName,
+				Line:        node.StartLine,
+				Instruction: strings.ToUpper(node.Value),
+				Kind:        id.kind,
+				ID:          n,
+				Value:       value,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardcodedUIDs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM golang:1.20 AS build
+USER 1000
+COPY --chown=1000:1000 . /src
+COPY --chown=app:app go.mod /src/
+
+FROM alpine
+ARG UID=1001
+ADD --chown=${UID}:root app.tar.gz /app
+USER nobody
+USER 0
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.HardcodedUIDs(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []UIDFinding{
+		{Stage: 0, StageName: "build", Line: 3, Instruction: "USER", Kind: UIDKindUser, ID: 1000, Value: "1000"},
+		{Stage: 0, StageName: "build", Line: 4, Instruction: "COPY", Kind: UIDKindUser, ID: 1000, Value: "1000:1000"},
+		{Stage: 0, StageName: "build", Line: 4, Instruction: "COPY", Kind: UIDKindGroup, ID: 1000, Value: "1000:1000"},
+		{Stage: 1, Line: 9, Instruction: "ADD", Kind: UIDKindUser, ID: 1001, Value: "1001:root"},
+		{Stage: 1, Line: 11, Instruction: "USER", Kind: UIDKindUser, ID: 0, Value: "0"},
+	}, findings)
+}
+
+func TestHardcodedUIDsBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ARG UID
+USER $UID
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.HardcodedUIDs(nil)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	findings, err = ast.HardcodedUIDs([]string{"UID=2000"})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 2000, findings[0].ID)
+	assert.Equal(t, 4, findings[0].Line)
+}
@@ -0,0 +1,65 @@
+package dockerfile
+
+import (
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+// A FROM image with no tag and no digest, so the registry picks the
+// `latest` tag for it.
+type UntaggedFinding struct {
+	Line int
+
+	// The image, with ARGs expanded, e.g., "ubuntu".
+	Image string
+}
+
+// UntaggedBaseImages finds FROM instructions whose image has neither a tag
+// nor a digest, like `FROM ubuntu`. These mean `ubuntu:latest`, but unlike
+// an explicit `:latest`, they're usually a tag someone forgot to add, so
+// they're reported separately: an image explicitly tagged `:latest` isn't
+// a finding.
+//
+// FROMs that refer to an earlier stage, FROM scratch, and images that
+// aren't valid refs (see InvalidFromRefs) are skipped.
+func (a AST) UntaggedBaseImages(buildArgs []string) ([]UntaggedFinding, error) {
+	byName := map[string]int{}
+	var result []UntaggedFinding
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		stage, ok := inst.(*instructions.Stage)
+		if !ok {
+			return nil
+		}
+		if stage.Name != "" {
+			byName[strings.ToLower(stage.Name)] = st.stageIndex
+		}
+		if st.baseName == "" || strings.EqualFold(st.baseName, "scratch") {
+			return nil
+		}
+		if _, ok := resolveStageRef(st.baseName, byName, st.stageIndex); ok {
+			return nil
+		}
+
+		ref, err := container.ParseNamed(st.baseName)
+		if err != nil {
+			return nil
+		}
+		if _, ok := ref.(reference.Tagged); ok {
+			return nil
+		}
+		if _, ok := ref.(reference.Digested); ok {
+			return nil
+		}
+		result = append(result, UntaggedFinding{Line: node.StartLine, Image: st.baseName})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
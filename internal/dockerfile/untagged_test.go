@@ -0,0 +1,39 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUntaggedBaseImages(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG BASE=debian
+FROM ubuntu AS Builder
+FROM ubuntu:latest
+FROM gcr.io/distroless/static
+FROM alpine@sha256:c5b1261d6d3e43071626931fc004f70149baeba2c8ec672bd4f27761f8e1ad6b
+FROM golang:1.21
+FROM builder
+FROM scratch
+FROM ${BASE}
+FROM $MISSING
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.UntaggedBaseImages(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []UntaggedFinding{
+		{Line: 3, Image: "ubuntu"},
+		{Line: 5, Image: "gcr.io/distroless/static"},
+		{Line: 10, Image: "debian"},
+	}, findings)
+
+	findings, err = ast.UntaggedBaseImages([]string{"BASE=debian:12"})
+	require.NoError(t, err)
+	assert.Equal(t, []UntaggedFinding{
+		{Line: 3, Image: "ubuntu"},
+		{Line: 5, Image: "gcr.io/distroless/static"},
+	}, findings)
+}
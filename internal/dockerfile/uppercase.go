@@ -0,0 +1,80 @@
+package dockerfile
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+)
+
+// UppercaseInstructions rewrites instruction keywords that aren't written in
+// upper case (e.g., `from` or `Run`), including the instruction after an
+// ONBUILD and the CMD of a HEALTHCHECK. Arguments and comments are left
+// alone, even if they look like keywords.
+//
+// Print already prints top-level keywords in upper case; this normalizes
+// the nested ones too, and the source text that checks like CacheImpact
+// report.
+//
+// Returns the number of keywords changed.
+func (a *AST) UppercaseInstructions() (int, error) {
+	count := 0
+	for _, node := range a.result.AST.Children {
+		if uppercaseKeyword(&node.Original, 0) {
+			count++
+		}
+
+		switch strings.ToLower(node.Value) {
+		case command.Onbuild:
+			if node.Next != nil && len(node.Next.Children) > 0 {
+				child := node.Next.Children[0]
+				if uppercaseKeyword(&child.Original, 0) {
+					uppercaseKeyword(&node.Original, 1)
+					count++
+				}
+			}
+		case command.Healthcheck:
+			if node.Next != nil && strings.EqualFold(node.Next.Value, command.Cmd) && node.Next.Value != "CMD" {
+				node.Next.Value = "CMD"
+				uppercaseKeyword(&node.Original, 1)
+				count++
+			}
+		}
+	}
+	return count, nil
+}
+
+// uppercaseKeyword uppercases word n of an instruction's source, not
+// counting flags, and reports whether it changed.
+func uppercaseKeyword(s *string, n int) bool {
+	src := *s
+	i := 0
+	for {
+		for i < len(src) && unicode.IsSpace(rune(src[i])) {
+			i++
+		}
+		start := i
+		for i < len(src) && !unicode.IsSpace(rune(src[i])) {
+			i++
+		}
+		if start == i {
+			return false
+		}
+
+		word := src[start:i]
+		if strings.HasPrefix(word, "--") {
+			continue
+		}
+		if n > 0 {
+			n--
+			continue
+		}
+
+		upper := strings.ToUpper(word)
+		if upper == word {
+			return false
+		}
+		*s = src[:start] + upper + src[i:]
+		return true
+	}
+}
@@ -0,0 +1,70 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUppercaseInstructions(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+from alpine AS build
+Run echo from run
+# run this later
+COPY . /app
+onbuild run echo hi
+healthcheck --interval=5s cmd curl localhost
+`))
+	require.NoError(t, err)
+
+	count, err := ast.UppercaseInstructions()
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	df, err := ast.Print()
+	require.NoError(t, err)
+	assert.Equal(t, `
+FROM alpine AS build
+RUN echo from run
+
+COPY . /app
+ONBUILD RUN echo hi
+HEALTHCHECK --interval=5s CMD curl localhost
+`, string(df))
+
+	children := ast.result.AST.Children
+	assert.Equal(t, "RUN echo from run", children[1].Original)
+	assert.Equal(t, "ONBUILD RUN echo hi", children[3].Original)
+	assert.Equal(t, "HEALTHCHECK --interval=5s CMD curl localhost", children[4].Original)
+	assert.Equal(t, []string{"run this later"}, children[2].PrevComment)
+
+	count, err = ast.UppercaseInstructions()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestUppercaseInstructionsAlreadyUppercase(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM alpine
+ONBUILD COPY --from=build /app /app
+HEALTHCHECK CMD curl localhost
+`))
+	require.NoError(t, err)
+
+	count, err := ast.UppercaseInstructions()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestPrintOnbuild(t *testing.T) {
+	assertPrint(t, `
+FROM alpine
+ONBUILD COPY --from=build /app /app
+ONBUILD RUN ["make", "install"]
+`, `
+FROM alpine
+ONBUILD COPY --from=build /app /app
+ONBUILD RUN ["make", "install"]
+`)
+}
@@ -0,0 +1,163 @@
+package dockerfile
+
+import (
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+// stageVars tracks the ARG and ENV values visible to instructions
+// as we walk through the Dockerfile.
+//
+// Loosely follows the buildkit scoping rules: ARGs declared before the
+// first FROM are global, and a stage only sees a global ARG if it
+// re-declares it. Build args passed by the user override defaults.
+// ENV values take precedence over ARGs of the same name.
+type stageVars struct {
+	shlex     *shell.Lex
+	overrides map[string]string
+	globals   map[string]string
+	args      map[string]string
+	env       map[string]string
+	inStage   bool
+}
+
+func newStageVars(shlex *shell.Lex, buildArgs []string) *stageVars {
+	return &stageVars{
+		shlex:     shlex,
+		overrides: fakeArgsMap(shlex, argInstructions(buildArgs)),
+		globals:   map[string]string{},
+		args:      map[string]string{},
+		env:       map[string]string{},
+	}
+}
+
+// Start a new stage. If the stage builds on a previous stage,
+// pass that stage's ENV so that it's inherited.
+func (v *stageVars) startStage(inheritedEnv map[string]string) {
+	v.inStage = true
+	v.args = map[string]string{}
+	v.env = copyVars(inheritedEnv)
+}
+
+// Update the variables in scope for an ARG or ENV instruction.
+// Other instructions are ignored.
+func (v *stageVars) apply(inst interface{}) {
+	switch inst := inst.(type) {
+	case *instructions.ArgCommand:
+		for _, kv := range inst.Args {
+			v.applyArg(kv)
+		}
+	case *instructions.EnvCommand:
+		values := v.expandEnv(inst)
+		for i, kv := range inst.Env {
+			v.env[kv.Key] = values[i]
+		}
+	}
+}
+
+// Expand the values of an ENV, in order.
+//
+// All values in a single ENV are expanded against the environment from
+// before the instruction.
+func (v *stageVars) expandEnv(inst *instructions.EnvCommand) []string {
+	values := make([]string, len(inst.Env))
+	for i, kv := range inst.Env {
+		values[i] = v.expand(kv.Value)
+	}
+	return values
+}
+
+// Expand a RUN's flags and return its mounts, or false if they don't
+// expand.
+//
+// Mount options are only parsed once they're expanded.
+func (v *stageVars) expandMounts(run *instructions.RunCommand) ([]*instructions.Mount, bool) {
+	err := run.Expand(func(word string) (string, error) {
+		return v.expand(word), nil
+	})
+	if err != nil {
+		return nil, false
+	}
+	return instructions.GetMounts(run), true
+}
+
+func (v *stageVars) applyArg(kv instructions.KeyValuePairOptional) {
+	scope := v.args
+	if !v.inStage {
+		scope = v.globals
+	}
+
+	if val, ok := v.overrides[kv.Key]; ok {
+		scope[kv.Key] = val
+	} else if kv.Value != nil {
+		scope[kv.Key] = v.expand(*kv.Value)
+	} else if val, ok := v.globals[kv.Key]; ok && v.inStage {
+		scope[kv.Key] = val
+	} else if _, ok := scope[kv.Key]; !ok {
+		scope[kv.Key] = ""
+	}
+}
+
+// All the variables that can be referenced at this point.
+func (v *stageVars) scope() map[string]string {
+	if !v.inStage {
+		return v.globals
+	}
+	result := copyVars(v.args)
+	for k, val := range v.env {
+		result[k] = val
+	}
+	return result
+}
+
+// Lookup a variable by name.
+func (v *stageVars) lookup(name string) (string, bool) {
+	val, ok := v.scope()[name]
+	return val, ok
+}
+
+// Whether a reference to name resolves to an ARG (rather than an ENV).
+func (v *stageVars) isArg(name string) bool {
+	if !v.inStage {
+		_, ok := v.globals[name]
+		return ok
+	}
+	if _, ok := v.env[name]; ok {
+		return false
+	}
+	_, ok := v.args[name]
+	return ok
+}
+
+// Expand variable references in a word, falling back to the
+// unexpanded word if it's malformed.
+func (v *stageVars) expand(word string) string {
+	result, err := v.shlex.ProcessWordWithMap(word, v.scope())
+	if err != nil {
+		return word
+	}
+	return result
+}
+
+// Expand variable references in a word using only global ARGs,
+// the way FROM does.
+func (v *stageVars) expandGlobal(word string) string {
+	result, err := v.shlex.ProcessWordWithMap(word, v.globals)
+	if err != nil {
+		return word
+	}
+	return result
+}
+
+// Returns a copy of the current ENV.
+func (v *stageVars) envSnapshot() map[string]string {
+	return copyVars(v.env)
+}
+
+func copyVars(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,184 @@
+package dockerfile
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// A RUN that installs a different version of the software that the base
+// image is named for, like `RUN apt-get install python3.10` in a stage
+// built FROM python:3.11.
+type SkewFinding struct {
+	Line int
+
+	// The software, e.g., "python".
+	Software string
+
+	// The version in the base image tag, e.g., "3.11".
+	ImageVersion string
+
+	// The package as written in the install command, and the version
+	// it names, e.g., "python3.10" and "3.10".
+	Package        string
+	PackageVersion string
+}
+
+// Software that base images are commonly named and tagged for, and the
+// packages that install other versions of it.
+type versionedSoftware struct {
+	name string
+
+	// Base images for the software, tagged by version (e.g., python:3.11).
+	images []string
+
+	// Packages with the version in the name, after one of these prefixes
+	// (e.g., python3.10, or openjdk-17-jdk).
+	packagePrefixes []string
+
+	// Packages with the version in a pin (e.g., nodejs=18.16.0-1).
+	pinnedPackages []string
+}
+
+var versionedSoftwares = []versionedSoftware{
+	{name: "python", images: []string{"python"}, packagePrefixes: []string{"python"}, pinnedPackages: []string{"python3"}},
+	{name: "node", images: []string{"node"}, pinnedPackages: []string{"nodejs"}},
+	{name: "go", images: []string{"golang"}, packagePrefixes: []string{"golang-"}},
+	{name: "java", images: []string{"openjdk", "eclipse-temurin", "amazoncorretto"}, packagePrefixes: []string{"openjdk-", "openjdk", "temurin-"}},
+	{name: "ruby", images: []string{"ruby"}, packagePrefixes: []string{"ruby"}},
+	{name: "postgresql", images: []string{"postgres"}, packagePrefixes: []string{"postgresql-client-", "postgresql-", "postgresql"}},
+}
+
+// A version at the start of a tag (3.11-slim) or package suffix (3.10-dev).
+var leadingVersionRE = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)(?:$|[-_.+~:a-z])`)
+
+// The base image software and version that a stage was built from.
+type versionHint struct {
+	software *versionedSoftware
+	version  string
+}
+
+// VersionSkewWarnings finds RUNs that install a version of the software
+// that the stage's base image is for, but that disagrees with the version
+// in the image tag. For example, a stage built FROM python:3.11 that runs
+// `apt-get install python3.10` gets the wrong python, or two of them.
+//
+// This is a heuristic. It only knows a handful of images (python, node,
+// golang, a few JDKs, ruby, and postgres), and reads versions from the
+// tag and from package names (or pins, like nodejs=18.16.0-1). Versions
+// are only compared as far as both are specific, up to major.minor, so
+// python:3 and python3.10 agree. Untagged images, and tags without a
+// version (like latest), are skipped.
+func (a AST) VersionSkewWarnings(buildArgs []string) ([]SkewFinding, error) {
+	var result []SkewFinding
+	var current *versionHint
+	hintsByStage := map[string]*versionHint{}
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		switch inst := inst.(type) {
+		case *instructions.Stage:
+			if prev, ok := hintsByStage[strings.ToLower(st.baseName)]; ok {
+				current = prev
+			} else {
+				current = imageVersionHint(st.baseName)
+			}
+			if inst.Name != "" {
+				hintsByStage[strings.ToLower(inst.Name)] = current
+			}
+
+		case *instructions.RunCommand:
+			if current == nil {
+				return nil
+			}
+			for _, words := range shellSegments(runScript(node, inst, st.vars)) {
+				for _, pkg := range installArgs(words) {
+					version, ok := packageVersion(current.software, pkg)
+					if !ok || versionsAgree(current.version, version) {
+						continue
+					}
+					result = append(result, SkewFinding{
+						Line:           node.StartLine,
+						Software:       current.software.name,
+						ImageVersion:   current.version,
+						Package:        pkg,
+						PackageVersion: version,
+					})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// imageVersionHint returns the software and version that an image ref
+// (like python:3.11-slim) is for, or nil if it's not a known image or
+// has no version in its tag.
+func imageVersionHint(ref string) *versionHint {
+	ref = strings.ToLower(ref)
+	if i := strings.Index(ref, "@"); i != -1 {
+		ref = ref[:i]
+	}
+	i := strings.LastIndex(ref, ":")
+	if i <= strings.LastIndex(ref, "/") {
+		return nil
+	}
+	name, tag := path.Base(ref[:i]), ref[i+1:]
+
+	match := leadingVersionRE.FindStringSubmatch(tag)
+	if match == nil {
+		return nil
+	}
+	for i, sw := range versionedSoftwares {
+		for _, image := range sw.images {
+			if name == image {
+				return &versionHint{software: &versionedSoftwares[i], version: match[1]}
+			}
+		}
+	}
+	return nil
+}
+
+// packageVersion returns the version of the software that a package
+// argument (like python3.10-dev or nodejs=18.16.0-1) installs.
+func packageVersion(sw *versionedSoftware, pkg string) (string, bool) {
+	name, pin, hasPin := strings.Cut(pkg, "=")
+	if hasPin {
+		for _, p := range sw.pinnedPackages {
+			if name == p {
+				if match := leadingVersionRE.FindStringSubmatch(strings.TrimPrefix(pin, "=")); match != nil {
+					return match[1], true
+				}
+			}
+		}
+	}
+
+	for _, prefix := range sw.packagePrefixes {
+		rest, ok := strings.CutPrefix(name, prefix)
+		if !ok {
+			continue
+		}
+		if match := leadingVersionRE.FindStringSubmatch(rest); match != nil {
+			return match[1], true
+		}
+	}
+	return "", false
+}
+
+// versionsAgree compares versions as far as both are specific, up to
+// major.minor.
+func versionsAgree(a, b string) bool {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs) && i < 2; i++ {
+		if strings.TrimLeft(as[i], "0") != strings.TrimLeft(bs[i], "0") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionSkewWarnings(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+FROM python:3.11-slim AS base
+RUN apt-get update && apt-get install -y python3.10 python3.10-dev python3-pip curl
+RUN apt-get install -y python3=3.9.2-3
+
+FROM base
+RUN apk add --no-cache python2.7
+
+FROM node:18-alpine
+RUN apt-get install -y nodejs=16.20.0-1nodesource1
+
+FROM golang:1.21
+RUN apt-get install -y golang-1.19-go
+
+FROM eclipse-temurin:17-jdk
+RUN apt-get install -y openjdk-11-jre-headless
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.VersionSkewWarnings(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []SkewFinding{
+		{Line: 3, Software: "python", ImageVersion: "3.11", Package: "python3.10", PackageVersion: "3.10"},
+		{Line: 3, Software: "python", ImageVersion: "3.11", Package: "python3.10-dev", PackageVersion: "3.10"},
+		{Line: 4, Software: "python", ImageVersion: "3.11", Package: "python3=3.9.2-3", PackageVersion: "3.9.2"},
+		{Line: 7, Software: "python", ImageVersion: "3.11", Package: "python2.7", PackageVersion: "2.7"},
+		{Line: 10, Software: "node", ImageVersion: "18", Package: "nodejs=16.20.0-1nodesource1", PackageVersion: "16.20.0"},
+		{Line: 13, Software: "go", ImageVersion: "1.21", Package: "golang-1.19-go", PackageVersion: "1.19"},
+		{Line: 16, Software: "java", ImageVersion: "17", Package: "openjdk-11-jre-headless", PackageVersion: "11"},
+	}, findings)
+}
+
+func TestVersionSkewWarningsAgree(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG PY=3.10
+FROM python:${PY}
+RUN apt-get install -y python3.10-venv python3-pip
+
+FROM python:3
+RUN apt-get install -y python3.12
+
+FROM python:latest
+RUN apt-get install -y python3.9
+
+FROM ubuntu:22.04
+RUN apt-get install -y python3.10
+
+FROM node:18
+RUN apt-get install -y nodejs=18.17.0-1
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.VersionSkewWarnings(nil)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestVersionSkewWarningsBuildArgs(t *testing.T) {
+	ast, err := ParseAST(Dockerfile(`
+ARG PY=3.10
+FROM python:${PY}
+RUN apt-get install -y python3.10
+`))
+	require.NoError(t, err)
+
+	findings, err := ast.VersionSkewWarnings([]string{"PY=3.12"})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "3.12", findings[0].ImageVersion)
+}
@@ -0,0 +1,146 @@
+package dockerfile
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/moby/buildkit/frontend/dockerfile/shell"
+)
+
+// The state of the build at a particular instruction.
+type walkState struct {
+	// The index of the current stage, or -1 before the first FROM.
+	stageIndex int
+
+	// The name of the current stage (from `FROM image AS name`), if any.
+	stageName string
+
+	// The base image or stage of the current stage, with ARGs expanded.
+	baseName string
+
+	workDir string
+	user    string
+	labels  map[string]string
+	vars    *stageVars
+}
+
+// The state carried over when a stage builds FROM a previous stage.
+type stageResult struct {
+	workDir string
+	user    string
+	labels  map[string]string
+	env     map[string]string
+}
+
+// walkInstructions visits each top-level instruction in order, tracking
+// the stage, variables, WORKDIR, USER, and LABELs in effect.
+//
+// inst is the parsed instruction, or nil if the instruction doesn't parse.
+//
+// For FROM, the visitor sees the state of the new stage. For all other
+// instructions, the visitor sees the state from before the instruction
+// took effect.
+func (a AST) walkInstructions(buildArgs []string, visit func(node *parser.Node, inst interface{}, st *walkState) error) error {
+	shlex := shell.NewLex(a.result.EscapeToken)
+	st := &walkState{
+		stageIndex: -1,
+		workDir:    "/",
+		labels:     map[string]string{},
+		vars:       newStageVars(shlex, buildArgs),
+	}
+	stageResults := map[string]stageResult{}
+
+	for _, node := range a.result.AST.Children {
+		inst, err := instructions.ParseInstruction(node)
+		if err != nil {
+			inst = nil // ignore parsing error
+		}
+
+		if strings.ToLower(node.Value) == command.From {
+			st.stageIndex++
+			st.stageName = ""
+			st.baseName = ""
+			st.workDir = "/"
+			st.user = ""
+			st.labels = map[string]string{}
+
+			var inheritedEnv map[string]string
+			if stage, ok := inst.(*instructions.Stage); ok {
+				st.stageName = stage.Name
+				st.baseName = st.vars.expandGlobal(stage.BaseName)
+				if prev, ok := stageResults[strings.ToLower(st.baseName)]; ok {
+					st.workDir = prev.workDir
+					st.user = prev.user
+					st.labels = copyLabels(prev.labels)
+					inheritedEnv = prev.env
+				}
+			}
+			st.vars.startStage(inheritedEnv)
+		}
+
+		err = visit(node, inst, st)
+		if err != nil {
+			return err
+		}
+
+		switch inst := inst.(type) {
+		case *instructions.ArgCommand, *instructions.EnvCommand:
+			st.vars.apply(inst)
+		case *instructions.WorkdirCommand:
+			st.workDir = resolveWorkDir(st.workDir, st.vars.expand(inst.Path))
+		case *instructions.UserCommand:
+			st.user = st.vars.expand(inst.User)
+		case *instructions.LabelCommand:
+			// Copy, so that stage results don't change under us.
+			labels := copyLabels(st.labels)
+			for _, kv := range inst.Labels {
+				labels[st.vars.expand(kv.Key)] = st.vars.expand(kv.Value)
+			}
+			st.labels = labels
+		}
+
+		if st.stageName != "" {
+			stageResults[strings.ToLower(st.stageName)] = stageResult{
+				workDir: st.workDir,
+				user:    st.user,
+				labels:  st.labels,
+				env:     st.vars.envSnapshot(),
+			}
+		}
+	}
+	return nil
+}
+
+// resolveStageRef returns the index of the stage that ref (a FROM base,
+// COPY --from, or RUN --mount from=) refers to, or false if ref is an
+// image.
+//
+// byName is the index of each named stage seen so far, by lowercased name.
+// Like BuildKit, stages are matched by index or case-insensitively by name,
+// and only stages before current count: a stage can't refer to itself or
+// to a stage declared after it.
+func resolveStageRef(ref string, byName map[string]int, current int) (int, bool) {
+	index, err := strconv.Atoi(ref)
+	if err != nil {
+		var ok bool
+		index, ok = byName[strings.ToLower(ref)]
+		if !ok {
+			return -1, false
+		}
+	}
+	if index < 0 || index >= current {
+		return -1, false
+	}
+	return index, true
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,32 @@
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveStageRef(t *testing.T) {
+	byName := map[string]int{"builder": 0, "test": 1, "release": 2}
+
+	for _, tc := range []struct {
+		ref     string
+		current int
+		index   int
+		ok      bool
+	}{
+		{"builder", 2, 0, true},
+		{"Builder", 2, 0, true},
+		{"1", 2, 1, true},
+		{"0", 0, -1, false},
+		{"-1", 2, -1, false},
+		{"test", 1, -1, false},
+		{"release", 1, -1, false},
+		{"2", 1, -1, false},
+		{"alpine", 2, -1, false},
+	} {
+		index, ok := resolveStageRef(tc.ref, byName, tc.current)
+		assert.Equal(t, tc.index, index, "%s in stage %d", tc.ref, tc.current)
+		assert.Equal(t, tc.ok, ok, "%s in stage %d", tc.ref, tc.current)
+	}
+}
@@ -0,0 +1,37 @@
+package dockerfile
+
+import (
+	"path"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// WorkDir returns the effective WORKDIR of the final stage.
+//
+// Relative WORKDIRs are resolved against the previous WORKDIR, and
+// ARG/ENV references are expanded. A stage that builds FROM an earlier
+// stage inherits its WORKDIR. If the final stage never sets a
+// WORKDIR, returns "/".
+func (a AST) WorkDir(buildArgs []string) (string, error) {
+	workDir := "/"
+	err := a.walkInstructions(buildArgs, func(node *parser.Node, inst interface{}, st *walkState) error {
+		workDir = st.workDir
+		if inst, ok := inst.(*instructions.WorkdirCommand); ok {
+			workDir = resolveWorkDir(st.workDir, st.vars.expand(inst.Path))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return workDir, nil
+}
+
+// resolveWorkDir joins a (possibly relative) WORKDIR onto the current one.
+func resolveWorkDir(current, dir string) string {
+	if path.IsAbs(dir) {
+		return path.Clean(dir)
+	}
+	return path.Join(current, dir)
+}
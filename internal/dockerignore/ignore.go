@@ -15,13 +15,60 @@ import (
 type dockerPathMatcher struct {
 	repoRoot string
 	matcher  *tiltDockerignore.PatternMatcher
+
+	// The same patterns, in runs that share a directory, so that Matches
+	// can skip the runs that can't match a file. Ignores merged from many
+	// .gitignore files are mostly runs like this.
+	groups []patternGroup
+}
+
+// A run of patterns that only match files under dir.
+type patternGroup struct {
+	dir      string
+	patterns []string
+	matcher  *tiltDockerignore.PatternMatcher
+
+	// The run's exclusions as plain patterns, or nil if it has none.
+	exclusions *tiltDockerignore.PatternMatcher
+}
+
+func (g patternGroup) covers(f string) bool {
+	return strings.HasPrefix(f, g.dir) &&
+		(len(f) == len(g.dir) || f[len(g.dir)] == filepath.Separator || strings.HasSuffix(g.dir, string(filepath.Separator)))
 }
 
 func (i dockerPathMatcher) Matches(f string) (bool, error) {
 	if !filepath.IsAbs(f) {
 		f = filepath.Join(i.repoRoot, f)
 	}
-	return i.matcher.Matches(f)
+
+	// Later patterns win, so each run that matches overrides the ones
+	// before it. A run that doesn't match can still end with one of its
+	// exclusions matching, which un-ignores the file.
+	matched := false
+	for _, g := range i.groups {
+		if !g.covers(f) {
+			continue
+		}
+		ok, err := g.matcher.Matches(f)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			matched = true
+			continue
+		}
+		if g.exclusions != nil {
+			excluded, err := g.exclusions.Matches(f)
+			if err != nil {
+				return false, err
+			}
+			if excluded {
+				matched = false
+			}
+		}
+	}
+	return matched, nil
 }
 
 func (i dockerPathMatcher) MatchesEntireDir(f string) (bool, error) {
@@ -138,7 +185,13 @@ func NewDockerPatternMatcher(repoRoot string, patterns []string) (*dockerPathMat
 		return nil, err
 	}
 
-	pm, err := tiltDockerignore.NewPatternMatcher(absPatterns(absRoot, patterns))
+	abs := absPatterns(absRoot, patterns)
+	pm, err := tiltDockerignore.NewPatternMatcher(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := groupPatterns(abs)
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +199,61 @@ func NewDockerPatternMatcher(repoRoot string, patterns []string) (*dockerPathMat
 	return &dockerPathMatcher{
 		repoRoot: absRoot,
 		matcher:  pm,
+		groups:   groups,
 	}, nil
 }
 
+// Splits absolute patterns into runs that only match files under the same
+// directory.
+func groupPatterns(patterns []string) ([]patternGroup, error) {
+	var groups []patternGroup
+	for _, p := range patterns {
+		dir := literalDir(strings.TrimPrefix(p, "!"))
+		if n := len(groups); n > 0 && groups[n-1].dir == dir {
+			groups[n-1].patterns = append(groups[n-1].patterns, p)
+			continue
+		}
+		groups = append(groups, patternGroup{dir: dir, patterns: []string{p}})
+	}
+
+	for i, g := range groups {
+		m, err := tiltDockerignore.NewPatternMatcher(g.patterns)
+		if err != nil {
+			return nil, err
+		}
+		groups[i].matcher = m
+
+		var exclusions []string
+		for _, p := range g.patterns {
+			if strings.HasPrefix(p, "!") {
+				exclusions = append(exclusions, p[1:])
+			}
+		}
+		if len(exclusions) > 0 {
+			em, err := tiltDockerignore.NewPatternMatcher(exclusions)
+			if err != nil {
+				return nil, err
+			}
+			groups[i].exclusions = em
+		}
+	}
+	return groups, nil
+}
+
+// The deepest directory that every file the pattern matches is under.
+func literalDir(pattern string) string {
+	globChars := "*?["
+	if filepath.Separator != '\\' {
+		// Outside Windows, a backslash escapes the next character.
+		globChars += `\`
+	}
+	dir := filepath.Dir(pattern)
+	for strings.ContainsAny(dir, globChars) && filepath.Dir(dir) != dir {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
+
 func readDockerignorePatterns(repoRoot string) ([]string, error) {
 	var excludes []string
 
@@ -172,3 +277,34 @@ func DockerIgnoreTesterFromContents(repoRoot string, contents string) (*dockerPa
 
 	return NewDockerPatternMatcher(repoRoot, patterns)
 }
+
+// MatchingPattern returns the index of the pattern that ignores f, or -1 if
+// f isn't ignored. Patterns must be absolute. Later patterns win, like in
+// the matcher, so this is the last pattern that matches, unless an
+// exclusion after it matches too.
+//
+// Compiles each pattern on its own, so it's meant for explaining a match,
+// not for matching on every file change.
+func MatchingPattern(patterns []string, f string) (int, error) {
+	result := -1
+	for i, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || p == "!" {
+			continue
+		}
+		isExclusion := p[0] == '!'
+		ok, err := tiltDockerignore.Matches(f, []string{strings.TrimPrefix(p, "!")})
+		if err != nil {
+			return -1, err
+		}
+		if !ok {
+			continue
+		}
+		if isExclusion {
+			result = -1
+		} else {
+			result = i
+		}
+	}
+	return result, nil
+}
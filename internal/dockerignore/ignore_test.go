@@ -112,6 +112,41 @@ func TestOnlyGoFiles(t *testing.T) {
 	tf.AssertResultEntireDir(tf.JoinPath("pkg", "internal", "module"), false)
 }
 
+// Patterns under different dirs are matched in separate runs, and a later
+// run still overrides an earlier one.
+func TestExceptionsAcrossDirs(t *testing.T) {
+	tf := newTestFixture(t, "**/dist", "web/**/*.log", "!web/**/dist", "web/api/dist", "!web/**/keep.log")
+	tf.AssertResult(tf.JoinPath("dist", "app.js"), true)
+	tf.AssertResult(tf.JoinPath("web", "dist", "app.js"), false)
+	tf.AssertResult(tf.JoinPath("web", "api", "dist", "app.js"), true)
+	tf.AssertResult(tf.JoinPath("web", "app.log"), true)
+	tf.AssertResult(tf.JoinPath("web", "keep.log"), false)
+	tf.AssertResult(tf.JoinPath("app.log"), false)
+	tf.AssertResult(tf.JoinPath("webapp", "app.log"), false)
+}
+
+func TestMatchingPattern(t *testing.T) {
+	tf := newTestFixture(t)
+	patterns := []string{
+		tf.JoinPath("**", "*.log"),
+		tf.JoinPath("dist"),
+		"!" + tf.JoinPath("**", "keep.log"),
+	}
+
+	for path, expected := range map[string]int{
+		"app.log":         0,
+		"dist/app.js":     1,
+		"dist/keep.log":   -1,
+		"keep.log":        -1,
+		"src/main.go":     -1,
+		"src/app/out.log": 0,
+	} {
+		actual, err := dockerignore.MatchingPattern(patterns, tf.JoinPath(path))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual, path)
+	}
+}
+
 func TestNoDockerignoreFile(t *testing.T) {
 	tf := newTestFixture(t)
 	tf.AssertResult(tf.JoinPath("hi"), false)
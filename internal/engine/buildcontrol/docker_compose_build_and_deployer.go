@@ -192,6 +192,7 @@ func (bd *DockerComposeBuildAndDeployer) BuildAndDeploy(ctx context.Context, st
 			}
 		}
 
+		iTarget.NoCache = currentState[target.ID()].ResetTriggered
 		cluster := currentState[target.ID()].ClusterOrEmpty()
 		return bd.build(ctx, iTarget, cmd, cluster, imageMapSet, ps)
 	})
@@ -95,6 +95,13 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 		if err != nil {
 			return store.BuildResultSet{}, WrapDontFallBackError(err)
 		}
+		if stateSet.ResetTriggered() {
+			ps.Printf(ctx, "Restarting pod and log tracking")
+			err = ibd.r.ForceResetDiscovery(ctx, types.NamespacedName{Name: kTarget.ID().Name.String()})
+			if err != nil {
+				return store.BuildResultSet{}, WrapDontFallBackError(err)
+			}
+		}
 		ps.EndPipelineStep(ctx)
 	}
 
@@ -137,6 +144,7 @@ func (ibd *ImageBuildAndDeployer) BuildAndDeploy(ctx context.Context, st store.R
 			}
 		}
 
+		iTarget.NoCache = stateSet[target.ID()].ResetTriggered
 		cluster := stateSet[target.ID()].ClusterOrEmpty()
 		return ibd.build(ctx, iTarget, cmd, cluster, imageMapSet, ps)
 	})
@@ -76,6 +76,23 @@ func TestForceUpdateK8s(t *testing.T) {
 
 	// A force rebuild should delete the old resources.
 	assert.Equal(t, 1, strings.Count(f.k8s.DeletedYaml, "Deployment"))
+	assert.False(t, f.docker.BuildOptions.NoCache)
+}
+
+func TestResetBuildsWithoutCache(t *testing.T) {
+	f := newIBDFixture(t, clusterid.ProductGKE)
+
+	m := NewSanchoDockerBuildManifest(f)
+
+	iTargetID1 := m.ImageTargets[0].ID()
+	stateSet := store.BuildStateSet{
+		iTargetID1: store.BuildState{FullBuildTriggered: true, ResetTriggered: true},
+	}
+	_, err := f.BuildAndDeploy(BuildTargets(m), stateSet)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, strings.Count(f.k8s.DeletedYaml, "Deployment"))
+	assert.True(t, f.docker.BuildOptions.NoCache)
 }
 
 func TestForceUpdateDoesNotDeleteNamespace(t *testing.T) {
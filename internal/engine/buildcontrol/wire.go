@@ -28,6 +28,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/dockerfile"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
 	"github.com/tilt-dev/tilt/internal/tracer"
@@ -50,6 +51,7 @@ var BaseWireSet = wire.NewSet(
 	NewLocalTargetBuildAndDeployer,
 	containerupdate.NewDockerUpdater,
 	containerupdate.NewExecUpdater,
+	containerupdate.NewAgentUpdater,
 	build.NewImageBuilder,
 
 	tracer.InitOpenTelemetry,
@@ -83,6 +85,7 @@ func ProvideImageBuildAndDeployer(
 		wire.Bind(new(localexec.Execer), new(*localexec.ProcessExecer)),
 		cmd.NewFakeProberManager,
 		wire.Bind(new(cmd.ProberManager), new(*cmd.FakeProberManager)),
+		wire.Value(offline.Mode{}),
 	)
 
 	return nil, nil
@@ -105,9 +108,12 @@ func ProvideDockerComposeBuildAndDeployer(
 		cmdimage.NewReconciler,
 		cmd.NewController,
 		localexec.EmptyEnv,
+		localexec.NewProcessExecer,
 		cmd.ProvideExecer,
+		wire.Bind(new(localexec.Execer), new(*localexec.ProcessExecer)),
 		cmd.NewFakeProberManager,
 		wire.Bind(new(cmd.ProberManager), new(*cmd.FakeProberManager)),
+		wire.Value(offline.Mode{}),
 	)
 
 	return nil, nil
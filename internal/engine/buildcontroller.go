@@ -39,6 +39,7 @@ type buildEntry struct {
 	buildStateSet store.BuildStateSet
 	filesChanged  []string
 	buildReason   model.BuildReason
+	triggers      []model.TriggerRequest
 	spanID        logstore.SpanID
 }
 
@@ -92,6 +93,7 @@ func (c *BuildController) needsBuild(ctx context.Context, st store.RStore) (buil
 		name:          manifest.Name,
 		targets:       targets,
 		buildReason:   buildReason,
+		triggers:      ms.PendingTriggers,
 		buildStateSet: buildStateSet,
 		filesChanged:  append(ms.ConfigFilesThatCausedChange, buildStateSet.FilesChanged()...),
 		spanID:        SpanIDForBuildLog(c.buildsStartedCount),
@@ -135,6 +137,7 @@ func (c *BuildController) OnChange(ctx context.Context, st store.RStore, summary
 			Name:         entry.Name(),
 			BuildReason:  entry.BuildReason(),
 			FilesChanged: entry.FilesChanged(),
+			Triggers:     entry.triggers,
 		})
 
 		result, err := c.buildAndDeploy(ctx, st, entry)
@@ -240,6 +243,12 @@ func buildStateSet(ctx context.Context, manifest model.Manifest,
 		}
 	}
 
+	if reason.Has(model.BuildReasonFlagReset) {
+		for k, v := range result {
+			result[k] = v.WithResetTriggered(true)
+		}
+	}
+
 	return result
 }
 
@@ -186,6 +186,34 @@ func TestBuildControllerImageBuildTrigger(t *testing.T) {
 	}
 }
 
+func TestBuildControllerAPITrigger(t *testing.T) {
+	f := newTestFixture(t)
+	mName := model.ManifestName("foobar")
+
+	manifest := f.simpleManifestWithTriggerMode(mName, model.TriggerModeManual)
+	f.Start([]model.Manifest{manifest})
+
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("main.go"))
+	f.WaitUntil("pending change appears", func(st store.EngineState) bool {
+		return len(st.BuildStatus(manifest.ImageTargetAt(0).ID()).PendingFileChanges) >= 1
+	})
+
+	// A full build skips live update, even though the change is eligible.
+	request := model.TriggerRequest{ID: "trigger-1", Message: "file saved"}
+	f.store.Dispatch(store.AppendToTriggerQueueAction{
+		Name:    mName,
+		Reason:  model.BuildReasonFlagTriggerAPI.With(model.BuildReasonFlagFullBuild),
+		Request: request,
+	})
+	call := f.nextCallComplete()
+	assert.True(t, call.oneImageState().FullBuildTriggered)
+
+	f.WaitUntilManifestState("build recorded", mName, func(ms store.ManifestState) bool {
+		_, ok := ms.LastBuild().Trigger(request.ID)
+		return len(ms.PendingTriggers) == 0 && ok
+	})
+}
+
 func TestBuildQueueOrdering(t *testing.T) {
 	f := newTestFixture(t)
 
@@ -555,6 +583,78 @@ func TestBuildControllerResourceDepTrumpsInitialBuild(t *testing.T) {
 	require.Equal(t, "bar", call.local().Name.String())
 }
 
+// bar runs on each update of foo, even though none of its own files changed
+func TestBuildControllerRunOnUpdateOf(t *testing.T) {
+	f := newTestFixture(t)
+
+	foo := manifestbuilder.New(f, "foo").
+		WithLocalResource("foo cmd", []string{f.JoinPath("foo")}).
+		Build()
+	bar := manifestbuilder.New(f, "bar").
+		WithLocalResource("bar cmd", []string{f.JoinPath("bar")}).
+		WithResourceDeps("foo").
+		Build()
+	bar = bar.WithDeployTarget(bar.LocalTarget().WithRunOnUpdateOf([]model.ManifestName{"foo"}, false))
+	f.Start([]model.Manifest{foo, bar})
+
+	call := f.nextCall()
+	require.Equal(t, "foo", call.local().Name.String())
+	call = f.nextCall()
+	require.Equal(t, "bar", call.local().Name.String())
+	f.assertNoCall("bar shouldn't re-run for the initial build of foo")
+
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("foo", "main.go"))
+	call = f.nextCall()
+	require.Equal(t, "foo", call.local().Name.String())
+	call = f.nextCall()
+	require.Equal(t, "bar", call.local().Name.String())
+	assert.Equal(t, map[model.TargetID]bool{
+		{Type: model.TargetTypeManifest, Name: "foo"}: true,
+	}, call.state[bar.LocalTarget().ID()].DepsChangedSet)
+
+	// A failed update of foo doesn't re-run bar.
+	f.SetNextBuildError(errors.New("failure"))
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("foo", "main.go"))
+	call = f.nextCall()
+	require.Equal(t, "foo", call.local().Name.String())
+	f.assertNoCall("bar shouldn't re-run when foo fails")
+}
+
+// bar runs on each update of foo, but only when triggered manually
+func TestBuildControllerRunOnUpdateOfManual(t *testing.T) {
+	f := newTestFixture(t)
+
+	foo := manifestbuilder.New(f, "foo").
+		WithLocalResource("foo cmd", []string{f.JoinPath("foo")}).
+		Build()
+	bar := manifestbuilder.New(f, "bar").
+		WithLocalResource("bar cmd", []string{f.JoinPath("bar")}).
+		WithResourceDeps("foo").
+		WithTriggerMode(model.TriggerModeManualWithAutoInit).
+		Build()
+	bar = bar.WithDeployTarget(bar.LocalTarget().WithRunOnUpdateOf([]model.ManifestName{"foo"}, false))
+	f.Start([]model.Manifest{foo, bar})
+
+	call := f.nextCall()
+	require.Equal(t, "foo", call.local().Name.String())
+	call = f.nextCall()
+	require.Equal(t, "bar", call.local().Name.String())
+
+	f.fsWatcher.Events <- watch.NewFileEvent(f.JoinPath("foo", "main.go"))
+	call = f.nextCall()
+	require.Equal(t, "foo", call.local().Name.String())
+	f.assertNoCall("bar should wait for a manual trigger")
+
+	f.WaitUntilManifestState("bar has pending changes", "bar", func(ms store.ManifestState) bool {
+		hasPendingChanges, _ := ms.HasPendingChanges()
+		return hasPendingChanges
+	})
+
+	f.store.Dispatch(store.AppendToTriggerQueueAction{Name: "bar"})
+	call = f.nextCall()
+	require.Equal(t, "bar", call.local().Name.String())
+}
+
 // bar depends on foo. make sure bar waits on foo even as foo fails
 func TestBuildControllerResourceDepTrumpsPendingBuild(t *testing.T) {
 	f := newTestFixture(t)
@@ -11,6 +11,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/local"
 	"github.com/tilt-dev/tilt/internal/engine/session"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
 	"github.com/tilt-dev/tilt/internal/hud"
@@ -62,6 +63,7 @@ func ProvideSubscribers(
 	sc *session.Controller,
 	uss *uisession.Subscriber,
 	urs *uiresource.Subscriber,
+	tss *triggerstats.Subscriber,
 ) []store.Subscriber {
 	apiSubscribers := ProvideSubscribersAPIOnly(hudsc, tscm, cb, ts)
 
@@ -83,6 +85,7 @@ func ProvideSubscribers(
 		sc,
 		uss,
 		urs,
+		tss,
 	}
 	return append(apiSubscribers, legacySubscribers...)
 }
@@ -0,0 +1,224 @@
+// Package triggerstats records which changed files trigger builds, and how
+// long those builds take, so users can find the files that cost them the
+// most rebuild time.
+//
+// The stats persist in the Tilt data dir, and accumulate across sessions.
+package triggerstats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+const dataFile = "trigger-stats.json"
+
+// How to rank files in a report.
+type SortBy string
+
+const (
+	// By the number of builds the file triggered.
+	SortByBuilds SortBy = "builds"
+
+	// By the total duration of the builds the file triggered.
+	SortBySeconds SortBy = "seconds"
+)
+
+// The builds triggered by changes to one file.
+type FileStat struct {
+	Path string `json:"path"`
+
+	// The number of builds that the file triggered.
+	Builds int `json:"builds"`
+
+	// The total duration of those builds. A build triggered by several
+	// files counts towards each of them.
+	BuildSeconds float64 `json:"buildSeconds"`
+
+	// When the file last triggered a build.
+	LastBuild time.Time `json:"lastBuild"`
+}
+
+// All the stats recorded so far.
+type Stats struct {
+	// When the stats were first recorded.
+	Since time.Time `json:"since"`
+
+	Files map[string]*FileStat `json:"files"`
+}
+
+// One completed build, and the files that triggered it.
+type Build struct {
+	Files      []string
+	StartTime  time.Time
+	FinishTime time.Time
+}
+
+// StatsPath is where the stats live in the data dir.
+func StatsPath(base xdg.Base) (string, error) {
+	p, err := base.DataFile(dataFile)
+	if err != nil {
+		return "", fmt.Errorf("trigger stats: %v", err)
+	}
+	return p, nil
+}
+
+// Load reads the stats from the data dir. Returns empty stats if none have
+// been recorded.
+//
+// If the file is corrupt, Load quarantines it and returns empty stats
+// along with a *statefile.CorruptError.
+func Load(base xdg.Base) (Stats, error) {
+	p, err := StatsPath(base)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{Files: map[string]*FileStat{}}
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return stats, nil
+	} else if err != nil {
+		return Stats{}, fmt.Errorf("trigger stats: %v", err)
+	}
+
+	err = json.Unmarshal(contents, &stats)
+	if err != nil {
+		return Stats{Files: map[string]*FileStat{}}, statefile.Recover(p, err)
+	}
+	if stats.Files == nil {
+		stats.Files = map[string]*FileStat{}
+	}
+	return stats, nil
+}
+
+// Record adds builds to the stats in the data dir.
+//
+// If the existing stats were corrupt, Record starts over, and returns
+// the *statefile.CorruptError after writing.
+//
+// The file is re-read first, so that Tilt sessions running in parallel
+// don't clobber each other's stats.
+func Record(base xdg.Base, builds []Build) error {
+	if len(builds) == 0 {
+		return nil
+	}
+
+	stats, loadErr := Load(base)
+	if loadErr != nil && !statefile.IsCorrupt(loadErr) {
+		return loadErr
+	}
+
+	for _, b := range builds {
+		stats.add(b)
+	}
+
+	p, err := StatsPath(base)
+	if err != nil {
+		return err
+	}
+	contents, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temp file and rename, so that readers never see a partial file.
+	tmp := p + ".tmp"
+	err = os.WriteFile(tmp, contents, 0644)
+	if err != nil {
+		return fmt.Errorf("trigger stats: %v", err)
+	}
+	err = os.Rename(tmp, p)
+	if err != nil {
+		return fmt.Errorf("trigger stats: %v", err)
+	}
+
+	// The new stats are written, but still let the caller know that
+	// the old ones were lost.
+	return loadErr
+}
+
+func (s *Stats) add(b Build) {
+	if s.Since.IsZero() || b.StartTime.Before(s.Since) {
+		s.Since = b.StartTime
+	}
+
+	seconds := b.FinishTime.Sub(b.StartTime).Seconds()
+	seen := map[string]bool{}
+	for _, f := range b.Files {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+
+		stat, ok := s.Files[f]
+		if !ok {
+			stat = &FileStat{Path: f}
+			s.Files[f] = stat
+		}
+		stat.Builds++
+		stat.BuildSeconds += seconds
+		if b.FinishTime.After(stat.LastBuild) {
+			stat.LastBuild = b.FinishTime
+		}
+	}
+}
+
+// Top returns the files that triggered the most builds (or build seconds),
+// most first. If limit is positive, returns at most that many.
+func (s Stats) Top(by SortBy, limit int) []FileStat {
+	result := make([]FileStat, 0, len(s.Files))
+	for _, stat := range s.Files {
+		result = append(result, *stat)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if by == SortBySeconds && a.BuildSeconds != b.BuildSeconds {
+			return a.BuildSeconds > b.BuildSeconds
+		}
+		if a.Builds != b.Builds {
+			return a.Builds > b.Builds
+		}
+		if a.BuildSeconds != b.BuildSeconds {
+			return a.BuildSeconds > b.BuildSeconds
+		}
+		return a.Path < b.Path
+	})
+
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+// The top files, as served by the API and printed by `tilt analyze triggers`.
+type Report struct {
+	Since  time.Time  `json:"since"`
+	SortBy SortBy     `json:"sortBy"`
+	Files  []FileStat `json:"files"`
+}
+
+func NewReport(stats Stats, by SortBy, limit int) Report {
+	return Report{
+		Since:  stats.Since,
+		SortBy: by,
+		Files:  stats.Top(by, limit),
+	}
+}
+
+// ParseSortBy validates a sort order from a flag or query param.
+func ParseSortBy(s string) (SortBy, error) {
+	switch SortBy(s) {
+	case SortByBuilds, SortBySeconds:
+		return SortBy(s), nil
+	case "":
+		return SortByBuilds, nil
+	}
+	return "", fmt.Errorf("invalid sort %q: must be %q or %q", s, SortByBuilds, SortBySeconds)
+}
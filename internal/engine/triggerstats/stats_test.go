@@ -0,0 +1,90 @@
+package triggerstats
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+func TestRecordAccumulatesAcrossSessions(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	err := Record(base, []Build{
+		{Files: []string{"/src/api.proto", "/src/main.go"}, StartTime: t0, FinishTime: t0.Add(30 * time.Second)},
+		{Files: []string{"/src/main.go"}, StartTime: t0.Add(time.Minute), FinishTime: t0.Add(time.Minute + 5*time.Second)},
+	})
+	require.NoError(t, err)
+
+	// A later session adds to the stats on disk.
+	err = Record(base, []Build{
+		{Files: []string{"/src/api.proto", "/src/api.proto"}, StartTime: t0.Add(time.Hour), FinishTime: t0.Add(time.Hour + 60*time.Second)},
+	})
+	require.NoError(t, err)
+
+	stats, err := Load(base)
+	require.NoError(t, err)
+	assert.Equal(t, t0, stats.Since)
+
+	assert.Equal(t, []FileStat{
+		{Path: "/src/api.proto", Builds: 2, BuildSeconds: 90, LastBuild: t0.Add(time.Hour + 60*time.Second)},
+		{Path: "/src/main.go", Builds: 2, BuildSeconds: 35, LastBuild: t0.Add(time.Minute + 5*time.Second)},
+	}, stats.Top(SortByBuilds, 0))
+}
+
+func TestTop(t *testing.T) {
+	stats := Stats{Files: map[string]*FileStat{
+		"a": {Path: "a", Builds: 10, BuildSeconds: 10},
+		"b": {Path: "b", Builds: 2, BuildSeconds: 120},
+		"c": {Path: "c", Builds: 10, BuildSeconds: 50},
+	}}
+
+	paths := func(stats []FileStat) []string {
+		var result []string
+		for _, s := range stats {
+			result = append(result, s.Path)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, paths(stats.Top(SortByBuilds, 0)))
+	assert.Equal(t, []string{"b", "c", "a"}, paths(stats.Top(SortBySeconds, 0)))
+	assert.Equal(t, []string{"b"}, paths(stats.Top(SortBySeconds, 1)))
+}
+
+func TestLoadEmpty(t *testing.T) {
+	stats, err := Load(xdg.FakeBase{Dir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Empty(t, stats.Top(SortByBuilds, 0))
+}
+
+func TestParseSortBy(t *testing.T) {
+	by, err := ParseSortBy("")
+	require.NoError(t, err)
+	assert.Equal(t, SortByBuilds, by)
+
+	_, err = ParseSortBy("size")
+	assert.EqualError(t, err, `invalid sort "size": must be "builds" or "seconds"`)
+}
+
+func TestRecordRecoversFromCorruptStats(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	p, err := StatsPath(base)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(p, []byte(`{"files": {"a": `), 0644))
+
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	err = Record(base, []Build{
+		{Files: []string{"/src/main.go"}, StartTime: t0, FinishTime: t0.Add(time.Second)},
+	})
+	require.True(t, statefile.IsCorrupt(err))
+
+	stats, err := Load(base)
+	require.NoError(t, err)
+	assert.Len(t, stats.Files, 1)
+}
@@ -0,0 +1,78 @@
+package triggerstats
+
+import (
+	"context"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Subscriber records the files that triggered each completed build.
+type Subscriber struct {
+	base xdg.Base
+
+	// The finish time of the last build recorded for each manifest.
+	lastRecorded map[model.ManifestName]time.Time
+}
+
+var _ store.Subscriber = &Subscriber{}
+
+func NewSubscriber(base xdg.Base) *Subscriber {
+	return &Subscriber{
+		base:         base,
+		lastRecorded: make(map[model.ManifestName]time.Time),
+	}
+}
+
+func (s *Subscriber) OnChange(ctx context.Context, st store.RStore, summary store.ChangeSummary) error {
+	if summary.IsLogOnly() {
+		return nil
+	}
+
+	builds := s.newBuilds(st)
+	err := Record(s.base, builds)
+	if statefile.IsCorrupt(err) {
+		logger.Get(ctx).Warnf("Build trigger stats: %v", err)
+	} else if err != nil {
+		// The stats are only informational, so don't fail the session.
+		logger.Get(ctx).Debugf("Recording build triggers: %v", err)
+	}
+	return nil
+}
+
+// newBuilds returns the builds triggered by file changes that completed
+// since the last call.
+func (s *Subscriber) newBuilds(st store.RStore) []Build {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	var result []Build
+	for _, mt := range state.Targets() {
+		name := mt.Manifest.Name
+		last := s.lastRecorded[name]
+
+		// BuildHistory is newest first.
+		for _, b := range mt.State.BuildHistory {
+			if b.FinishTime.IsZero() || !b.FinishTime.After(last) {
+				break
+			}
+			if len(b.Edits) == 0 {
+				continue
+			}
+			result = append(result, Build{
+				Files:      b.Edits,
+				StartTime:  b.StartTime,
+				FinishTime: b.FinishTime,
+			})
+		}
+
+		if finish := mt.State.LastBuild().FinishTime; finish.After(last) {
+			s.lastRecorded[name] = finish
+		}
+	}
+	return result
+}
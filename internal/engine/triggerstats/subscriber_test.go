@@ -0,0 +1,56 @@
+package triggerstats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestSubscriberRecordsEachBuildOnce(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	st := store.NewTestingStore()
+	s := NewSubscriber(base)
+	ctx := context.Background()
+
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	mt := store.NewManifestTarget(model.Manifest{Name: "api"})
+	state := st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(mt)
+	mt.State.AddCompletedBuild(model.BuildRecord{StartTime: t0, FinishTime: t0.Add(time.Second)})
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		Edits:      []string{"/src/main.go"},
+		StartTime:  t0.Add(time.Minute),
+		FinishTime: t0.Add(time.Minute + 10*time.Second),
+	})
+	st.UnlockMutableState()
+
+	require.NoError(t, s.OnChange(ctx, st, store.LegacyChangeSummary()))
+	require.NoError(t, s.OnChange(ctx, st, store.LegacyChangeSummary()))
+
+	state = st.LockMutableStateForTesting()
+	mt.State.AddCompletedBuild(model.BuildRecord{
+		Edits:      []string{"/src/main.go", "/src/go.mod"},
+		StartTime:  t0.Add(2 * time.Minute),
+		FinishTime: t0.Add(2*time.Minute + 20*time.Second),
+	})
+	st.UnlockMutableState()
+
+	require.NoError(t, s.OnChange(ctx, st, store.LegacyChangeSummary()))
+
+	stats, err := Load(base)
+	require.NoError(t, err)
+	top := stats.Top(SortByBuilds, 0)
+	require.Len(t, top, 2)
+	assert.Equal(t, "/src/main.go", top[0].Path)
+	assert.Equal(t, 2, top[0].Builds)
+	assert.Equal(t, 30.0, top[0].BuildSeconds)
+	assert.Equal(t, "/src/go.mod", top[1].Path)
+	assert.Equal(t, 1, top[1].Builds)
+}
@@ -50,6 +50,24 @@ func (s *Subscriber) OnChange(ctx context.Context, st store.RStore, summary stor
 		return err
 	}
 
+	bookmarks := session.Annotations[webview.AnnotationLogBookmarks]
+	if bookmarks != stored.Annotations[webview.AnnotationLogBookmarks] {
+		update := stored.DeepCopy()
+		if update.Annotations == nil {
+			update.Annotations = make(map[string]string)
+		}
+		if bookmarks == "" {
+			delete(update.Annotations, webview.AnnotationLogBookmarks)
+		} else {
+			update.Annotations[webview.AnnotationLogBookmarks] = bookmarks
+		}
+		err = s.client.Update(ctx, update)
+		if err != nil {
+			return err
+		}
+		stored = update
+	}
+
 	if !apicmp.DeepEqual(session.Status, stored.Status) {
 		// If the current version is different than what's stored, update it.
 		update := &v1alpha1.UISession{
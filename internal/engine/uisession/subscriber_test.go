@@ -12,9 +12,11 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/hud/webview"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/apis"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 func TestCreate(t *testing.T) {
@@ -54,6 +56,22 @@ func TestUpdate(t *testing.T) {
 	assert.Equal(t, apis.NewTime(now).String(), r.Status.TiltStartTime.String())
 }
 
+func TestUpdateLogBookmarks(t *testing.T) {
+	f := newFixture(t)
+	_ = f.sub.OnChange(f.ctx, f.store, store.LegacyChangeSummary())
+
+	now := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	f.store.WithState(func(es *store.EngineState) {
+		es.LogBookmarks = append(es.LogBookmarks, logstore.Bookmark{Name: "repro", Time: now, Checkpoint: 12})
+	})
+	_ = f.sub.OnChange(f.ctx, f.store, store.LegacyChangeSummary())
+
+	r := f.session("Tiltfile")
+	require.NotNil(t, r)
+	assert.JSONEq(t, `[{"name": "repro", "time": "2022-01-02T03:04:05Z", "checkpoint": 12}]`,
+		r.Annotations[webview.AnnotationLogBookmarks])
+}
+
 type fixture struct {
 	ctx   context.Context
 	t     *testing.T
@@ -158,8 +158,16 @@ func upperReducerFn(ctx context.Context, state *store.EngineState, action store.
 		handlePanicAction(state, action)
 	case store.LogAction:
 		handleLogAction(state, action)
+	case store.LogBookmarkAction:
+		state.LogBookmarks = append(state.LogBookmarks, action.Bookmark)
+	case store.SecretsAction:
+		state.Secrets.AddAll(action.Secrets)
 	case store.AppendToTriggerQueueAction:
+		if action.Reason.Has(model.BuildReasonFlagReset) {
+			state.ResetManifest(action.Name)
+		}
 		state.AppendToTriggerQueue(action.Name, action.Reason)
+		state.AppendTriggerRequest(action.Name, action.Request)
 	case sessions.SessionStatusUpdateAction:
 		sessions.HandleSessionStatusUpdateAction(state, action)
 	case prompt.SwitchTerminalModeAction:
@@ -70,6 +70,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/local"
 	"github.com/tilt-dev/tilt/internal/engine/session"
 	"github.com/tilt-dev/tilt/internal/engine/telemetry"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
 	"github.com/tilt-dev/tilt/internal/engine/uiresource"
 	"github.com/tilt-dev/tilt/internal/engine/uisession"
 	"github.com/tilt-dev/tilt/internal/feature"
@@ -80,6 +81,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/openurl"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/buildcontrols"
@@ -3165,7 +3167,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	ciSettingsPlugin := cisettings.NewPlugin(0)
 	realTFL := tiltfile.ProvideTiltfileLoader(ta,
 		k8sContextPlugin, versionPlugin, configPlugin, extPlugin, ciSettingsPlugin,
-		fakeDcc, "localhost", execer, feature.MainDefaults, env)
+		fakeDcc, "localhost", execer, feature.MainDefaults, env, nil, nil)
 	tfl := tiltfile.NewFakeTiltfileLoader()
 	cc := configs.NewConfigsController(cdc)
 	tqs := configs.NewTriggerQueueSubscriber(cdc)
@@ -3181,7 +3183,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	kdc := kubernetesdiscovery.NewReconciler(cdc, sch, clusterClients, rd, st)
 	sw := k8swatch.NewServiceWatcher(clusterClients, ns)
 	ewm := k8swatch.NewEventWatchManager(clusterClients, ns)
-	tcum := cloud.NewStatusManager(httptest.NewFakeClientEmptyJSON(), clock)
+	tcum := cloud.NewStatusManager(httptest.NewFakeClientEmptyJSON(), clock, offline.Mode{})
 	fe := cmd.NewFakeExecer()
 	fpm := cmd.NewFakeProberManager()
 	fwc := filewatch.NewController(cdc, st, watcher.NewSub, timerMaker.Maker(), v1alpha1.NewScheme(), clock)
@@ -3205,7 +3207,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 		cdc,
 		uncached)
 	require.NoError(t, err, "Failed to create Tilt API server controller manager")
-	pfr := apiportforward.NewReconciler(cdc, sch, st, clusterClients)
+	pfr := apiportforward.NewReconciler(cdc, sch, st, clusterClients, localexec.EmptyEnv())
 
 	wsl := server.NewWebsocketList()
 
@@ -3216,7 +3218,7 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	tfr := ctrltiltfile.NewReconciler(st, tfl, dockerClient, cdc, sch, engineMode, "", "", 0)
 	tbr := togglebutton.NewReconciler(cdc, sch)
 	extr := extension.NewReconciler(cdc, sch, ta)
-	extrr, err := extensionrepo.NewReconciler(cdc, st, base)
+	extrr, err := extensionrepo.NewReconciler(cdc, st, base, offline.Mode{})
 	require.NoError(t, err)
 	cmr := configmap.NewReconciler(cdc, st)
 
@@ -3224,8 +3226,8 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 	lur := liveupdate.NewFakeReconciler(st, cu, cdc)
 	dockerBuilder := build.NewDockerBuilder(dockerClient, nil)
 	customBuilder := build.NewCustomBuilder(dockerClient, clock, cmds)
-	kp := build.NewKINDLoader()
-	ib := build.NewImageBuilder(dockerBuilder, customBuilder, kp)
+	kp := build.NewKINDLoader(localexec.EmptyEnv())
+	ib := build.NewImageBuilder(dockerBuilder, customBuilder, kp, execer, offline.Mode{})
 	dir := dockerimage.NewReconciler(cdc, st, sch, dockerClient, ib)
 	cir := cmdimage.NewReconciler(cdc, st, sch, dockerClient, ib)
 	clr := cluster.NewReconciler(ctx, cdc, st, clock, clusterClients, docker.LocalEnv{},
@@ -3303,8 +3305,9 @@ func newTestFixture(t *testing.T, options ...fixtureOptions) *testFixture {
 
 	uss := uisession.NewSubscriber(cdc)
 	urs := uiresource.NewSubscriber(cdc)
+	tss := triggerstats.NewSubscriber(base)
 
-	subs := ProvideSubscribers(hudsc, tscm, cb, h, ts, tp, sw, bc, cc, tqs, ar, au, ewm, tcum, dp, tc, lsc, podm, sessionController, uss, urs)
+	subs := ProvideSubscribers(hudsc, tscm, cb, h, ts, tp, sw, bc, cc, tqs, ar, au, ewm, tcum, dp, tc, lsc, podm, sessionController, uss, urs, tss)
 	ret.upper, err = NewUpper(ctx, st, subs)
 	require.NoError(t, err)
 
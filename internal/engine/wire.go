@@ -29,6 +29,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/engine/buildcontrol"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/offline"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/liveupdates"
 )
@@ -79,6 +80,7 @@ func provideFakeBuildAndDeployer(
 		cmd.WireSet,
 		clockwork.NewRealClock,
 		provideFakeEnv,
+		wire.Value(offline.Mode{}),
 	)
 
 	return nil, nil
@@ -0,0 +1,429 @@
+// Package fsck checks the state that Tilt keeps on disk for corrupt and
+// orphaned files, and repairs them.
+//
+// Tilt keeps state in a few places:
+//
+//   - The data dir (e.g., ~/.local/share/tilt-dev): build trigger stats,
+//     local command approvals and audit log, and downloaded extension repos.
+//   - The cache dir (e.g., ~/.cache/tilt-dev): apiserver certs.
+//   - The settings dir (e.g., ~/.tilt-dev): the token and the API configs.
+//
+// The readers of these files recover from corrupt files on their own. Use
+// fsck to find out what went wrong after a crash, or to clean up eagerly.
+package fsck
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/controllers/core/extensionrepo"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+const (
+	storeBuildHistory = "build history"
+	storeApprovals    = "local command approvals"
+	storeAuditLog     = "local command audit log"
+	storeExtensions   = "extension cache"
+	storeCerts        = "apiserver certs"
+	storeToken        = "token"
+	storeAPIConfig    = "api configs"
+)
+
+type Kind string
+
+const (
+	// The file can't be read.
+	KindCorrupt Kind = "corrupt"
+
+	// The file was left behind by an interrupted write or download.
+	KindOrphaned Kind = "orphaned"
+
+	// The file was moved aside because it was corrupt. It's kept so
+	// that you can look at it, and fsck never touches it.
+	KindQuarantined Kind = "quarantined"
+)
+
+type Fix string
+
+const (
+	FixNone Fix = ""
+
+	// Move the file aside, so that Tilt starts over with defaults.
+	FixQuarantine Fix = "quarantine"
+
+	// Delete the file. Only for caches that Tilt rebuilds on its own.
+	FixRemove Fix = "remove"
+)
+
+type Problem struct {
+	// The part of Tilt's state that the file belongs to.
+	Store string `json:"store"`
+
+	Path    string `json:"path"`
+	Kind    Kind   `json:"kind"`
+	Message string `json:"message"`
+
+	// How --repair fixes it.
+	Fix Fix `json:"fix,omitempty"`
+}
+
+type Checker struct {
+	base xdg.Base
+	dir  *dirs.TiltDevDir
+}
+
+func NewChecker(base xdg.Base, dir *dirs.TiltDevDir) Checker {
+	return Checker{base: base, dir: dir}
+}
+
+// Check looks for problems in all the state that Tilt keeps.
+//
+// Returns an error only if the state can't be checked at all.
+func (c Checker) Check() ([]Problem, error) {
+	checks := []func() ([]Problem, error){
+		c.checkTriggerStats,
+		c.checkApprovals,
+		c.checkAuditLog,
+		c.checkExtensionCache,
+		c.checkCerts,
+		c.checkToken,
+		c.checkAPIConfig,
+	}
+
+	var result []Problem
+	for _, check := range checks {
+		problems, err := check()
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(problems, func(i, j int) bool {
+			return problems[i].Path < problems[j].Path
+		})
+		result = append(result, problems...)
+	}
+	return result, nil
+}
+
+// Repair applies the fix for a problem. Returns a description of what it did.
+func Repair(p Problem) (string, error) {
+	switch p.Fix {
+	case FixQuarantine:
+		dest, err := statefile.Quarantine(p.Path)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("moved to %s", dest), nil
+	case FixRemove:
+		err := os.RemoveAll(p.Path)
+		if err != nil {
+			return "", fmt.Errorf("removing %s: %v", p.Path, err)
+		}
+		return "removed (Tilt rebuilds it when needed)", nil
+	}
+	return "", nil
+}
+
+func (c Checker) checkTriggerStats() ([]Problem, error) {
+	p, err := triggerstats.StatsPath(c.base)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats triggerstats.Stats
+	result := checkJSONFile(storeBuildHistory, p, &stats)
+
+	// Stats are written to a temp file, then renamed into place.
+	tmp := p + ".tmp"
+	if _, err := os.Stat(tmp); err == nil {
+		result = append(result, Problem{
+			Store:   storeBuildHistory,
+			Path:    tmp,
+			Kind:    KindOrphaned,
+			Message: "left over from an interrupted write",
+			Fix:     FixRemove,
+		})
+	}
+	return result, nil
+}
+
+func (c Checker) checkApprovals() ([]Problem, error) {
+	p, err := c.base.DataFile(sandbox.ApprovalsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals map[string]json.RawMessage
+	return checkJSONFile(storeApprovals, p, &approvals), nil
+}
+
+func (c Checker) checkAuditLog() ([]Problem, error) {
+	p, err := c.base.DataFile(sandbox.AuditLogFile)
+	if err != nil {
+		return nil, err
+	}
+
+	result := quarantinedCopies(storeAuditLog, p)
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return append(result, unreadable(storeAuditLog, p, err)), nil
+	}
+
+	// One JSON object per line. A crash mid-write usually truncates the last one.
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 || json.Valid(text) {
+			continue
+		}
+		return append(result, Problem{
+			Store:   storeAuditLog,
+			Path:    p,
+			Kind:    KindCorrupt,
+			Message: fmt.Sprintf("line %d is not valid JSON", line),
+			Fix:     FixQuarantine,
+		}), nil
+	}
+	return result, nil
+}
+
+// Extension repos are downloaded into a tree like Go's GOPATH
+// (e.g., tilt_modules/github.com/tilt-dev/tilt-extensions). Every leaf
+// directory should be a git checkout.
+func (c Checker) checkExtensionCache() ([]Problem, error) {
+	root, err := c.base.DataFile(extensionrepo.TiltModulesRelDir)
+	if err != nil {
+		return nil, err
+	}
+	problems, _ := checkRepoTree(root)
+	return problems, nil
+}
+
+// checkRepoTree reports directories with no git checkouts under them, and
+// checkouts that are broken. Also returns whether there are any checkouts
+// under dir, so that the caller can report the highest directory with none.
+func checkRepoTree(dir string) ([]Problem, bool) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, false
+	} else if err != nil {
+		return []Problem{unreadable(storeExtensions, dir, err)}, true
+	}
+
+	var subdirs []string
+	for _, e := range entries {
+		if e.Name() == ".git" {
+			_, err := os.Stat(filepath.Join(dir, ".git", "HEAD"))
+			if err != nil {
+				return []Problem{{
+					Store:   storeExtensions,
+					Path:    dir,
+					Kind:    KindCorrupt,
+					Message: "git checkout has no HEAD",
+					Fix:     FixRemove,
+				}}, true
+			}
+			return nil, true
+		}
+		if e.IsDir() {
+			subdirs = append(subdirs, e.Name())
+		}
+	}
+
+	var result []Problem
+	hasRepo := false
+	for _, name := range subdirs {
+		p := filepath.Join(dir, name)
+		if statefile.IsQuarantined(p) {
+			result = append(result, quarantined(storeExtensions, p))
+			continue
+		}
+
+		problems, ok := checkRepoTree(p)
+		if !ok {
+			problems = []Problem{{
+				Store:   storeExtensions,
+				Path:    p,
+				Kind:    KindOrphaned,
+				Message: "no git checkouts; probably an interrupted download",
+				Fix:     FixRemove,
+			}}
+		}
+		hasRepo = hasRepo || ok
+		result = append(result, problems...)
+	}
+	return result, hasRepo
+}
+
+// Certs live in certs/<apiserver name>/<pair name>.{crt,key}.
+func (c Checker) checkCerts() ([]Problem, error) {
+	root, err := c.base.CacheFile("certs")
+	if err != nil {
+		return nil, err
+	}
+
+	serverDirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return []Problem{unreadable(storeCerts, root, err)}, nil
+	}
+
+	var result []Problem
+	for _, serverDir := range serverDirs {
+		if !serverDir.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, serverDir.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			result = append(result, unreadable(storeCerts, dir, err))
+			continue
+		}
+
+		pairs := map[string]bool{}
+		for _, f := range files {
+			ext := filepath.Ext(f.Name())
+			if ext == ".crt" || ext == ".key" {
+				pairs[strings.TrimSuffix(f.Name(), ext)] = true
+			}
+		}
+
+		for pair := range pairs {
+			certFile := filepath.Join(dir, pair+".crt")
+			keyFile := filepath.Join(dir, pair+".key")
+			_, err := tls.LoadX509KeyPair(certFile, keyFile)
+			if err == nil {
+				continue
+			}
+
+			if os.IsNotExist(err) {
+				existing := certFile
+				if _, statErr := os.Stat(certFile); statErr != nil {
+					existing = keyFile
+				}
+				result = append(result, Problem{
+					Store:   storeCerts,
+					Path:    existing,
+					Kind:    KindOrphaned,
+					Message: "the other half of the key pair is missing",
+					Fix:     FixRemove,
+				})
+				continue
+			}
+
+			result = append(result,
+				Problem{Store: storeCerts, Path: certFile, Kind: KindCorrupt, Message: err.Error(), Fix: FixRemove},
+				Problem{Store: storeCerts, Path: keyFile, Kind: KindCorrupt, Message: err.Error(), Fix: FixRemove})
+		}
+	}
+	return result, nil
+}
+
+func (c Checker) checkToken() ([]Problem, error) {
+	p := filepath.Join(c.dir.Root(), "token")
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return []Problem{unreadable(storeToken, p, err)}, nil
+	}
+	if len(bytes.TrimSpace(contents)) == 0 {
+		return []Problem{{
+			Store:   storeToken,
+			Path:    p,
+			Kind:    KindCorrupt,
+			Message: "empty",
+			Fix:     FixRemove,
+		}}, nil
+	}
+	return nil, nil
+}
+
+func (c Checker) checkAPIConfig() ([]Problem, error) {
+	p := filepath.Join(c.dir.Root(), "config")
+	result := quarantinedCopies(storeAPIConfig, p)
+	_, err := clientcmd.LoadFromFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		result = append(result, Problem{
+			Store:   storeAPIConfig,
+			Path:    p,
+			Kind:    KindCorrupt,
+			Message: err.Error(),
+			Fix:     FixQuarantine,
+		})
+	}
+	return result, nil
+}
+
+// checkJSONFile reports a JSON file that doesn't parse, and any copies that
+// were quarantined earlier.
+func checkJSONFile(store string, p string, v interface{}) []Problem {
+	result := quarantinedCopies(store, p)
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return result
+	} else if err != nil {
+		return append(result, unreadable(store, p, err))
+	}
+
+	err = json.Unmarshal(contents, v)
+	if err != nil {
+		result = append(result, Problem{
+			Store:   store,
+			Path:    p,
+			Kind:    KindCorrupt,
+			Message: fmt.Sprintf("invalid JSON: %v", err),
+			Fix:     FixQuarantine,
+		})
+	}
+	return result
+}
+
+func quarantinedCopies(store string, p string) []Problem {
+	matches, _ := filepath.Glob(p + ".corrupt-*")
+	var result []Problem
+	for _, m := range matches {
+		if statefile.IsQuarantined(m) {
+			result = append(result, quarantined(store, m))
+		}
+	}
+	return result
+}
+
+func quarantined(store string, p string) Problem {
+	return Problem{
+		Store:   store,
+		Path:    p,
+		Kind:    KindQuarantined,
+		Message: "moved aside earlier because it was corrupt; delete it once you've looked at it",
+	}
+}
+
+func unreadable(store string, p string, err error) Problem {
+	return Problem{
+		Store:   store,
+		Path:    p,
+		Kind:    KindCorrupt,
+		Message: fmt.Sprintf("can't read: %v", err),
+	}
+}
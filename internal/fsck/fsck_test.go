@@ -0,0 +1,118 @@
+package fsck
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/wmclient/pkg/dirs"
+
+	"github.com/tilt-dev/tilt/internal/xdg"
+)
+
+type fixture struct {
+	t       *testing.T
+	base    xdg.FakeBase
+	dir     *dirs.TiltDevDir
+	checker Checker
+}
+
+func newFixture(t *testing.T) *fixture {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	dir := dirs.NewTiltDevDirAt(t.TempDir())
+	return &fixture{t: t, base: base, dir: dir, checker: NewChecker(base, dir)}
+}
+
+func (f *fixture) dataFile(rel string, contents string) string {
+	p, err := f.base.DataFile(rel)
+	require.NoError(f.t, err)
+	require.NoError(f.t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(f.t, os.WriteFile(p, []byte(contents), 0644))
+	return p
+}
+
+func (f *fixture) settingsFile(rel string, contents string) string {
+	p := filepath.Join(f.dir.Root(), rel)
+	require.NoError(f.t, os.WriteFile(p, []byte(contents), 0644))
+	return p
+}
+
+func (f *fixture) check() []Problem {
+	problems, err := f.checker.Check()
+	require.NoError(f.t, err)
+	return problems
+}
+
+func TestCheckClean(t *testing.T) {
+	f := newFixture(t)
+	f.dataFile("trigger-stats.json", `{"files": {}}`)
+	f.dataFile("local-command-approvals.json", `{}`)
+	f.dataFile("local-commands.log", "{\"decision\":\"allowed\"}\n{\"decision\":\"denied\"}\n")
+	f.dataFile("tilt_modules/github.com/tilt-dev/tilt-extensions/.git/HEAD", "ref: refs/heads/master\n")
+	f.settingsFile("token", "abc")
+	f.settingsFile("config", "apiVersion: v1\nkind: Config\n")
+
+	assert.Empty(t, f.check())
+}
+
+func TestCheckAndRepair(t *testing.T) {
+	f := newFixture(t)
+	stats := f.dataFile("trigger-stats.json", `{"files": {`)
+	tmp := f.dataFile("trigger-stats.json.tmp", `{}`)
+	auditLog := f.dataFile("local-commands.log", "{\"decision\":\"allowed\"}\n{\"decision\":\"den")
+	f.dataFile("tilt_modules/github.com/tilt-dev/tilt-extensions/README.md", "")
+	partial := filepath.Join(f.base.Dir, "cache", "tilt_modules", "github.com")
+	token := f.settingsFile("token", "\n")
+	config := f.settingsFile("config", "clusters: [not yaml")
+
+	problems := f.check()
+	var summary []string
+	for _, p := range problems {
+		summary = append(summary, string(p.Kind)+" "+p.Path)
+	}
+	assert.Equal(t, []string{
+		"corrupt " + stats,
+		"orphaned " + tmp,
+		"corrupt " + auditLog,
+		"orphaned " + partial,
+		"corrupt " + token,
+		"corrupt " + config,
+	}, summary)
+	assert.Equal(t, "line 2 is not valid JSON", problems[2].Message)
+
+	for _, p := range problems {
+		_, err := Repair(p)
+		require.NoError(t, err)
+	}
+
+	// The quarantined files are kept, and reported, but need no repair.
+	problems = f.check()
+	require.Len(t, problems, 3)
+	for _, p := range problems {
+		assert.Equal(t, KindQuarantined, p.Kind)
+		assert.Equal(t, FixNone, p.Fix)
+	}
+	_, err := os.Stat(partial)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCheckCerts(t *testing.T) {
+	f := newFixture(t)
+	p, err := f.base.CacheFile(filepath.Join("certs", "tilt-default", "localhost_10350"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(p+".crt", []byte("not a cert"), 0644))
+	require.NoError(t, os.WriteFile(p+".key", []byte("not a key"), 0644))
+	require.NoError(t, os.WriteFile(p+"1.crt", []byte("not a cert"), 0644))
+
+	problems := f.check()
+	require.Len(t, problems, 3)
+	assert.Equal(t, KindCorrupt, problems[0].Kind)
+	assert.Equal(t, p+".crt", problems[0].Path)
+	assert.Equal(t, KindCorrupt, problems[1].Kind)
+	assert.Equal(t, p+".key", problems[1].Path)
+	assert.Equal(t, KindOrphaned, problems[2].Kind)
+	assert.Equal(t, p+"1.crt", problems[2].Path)
+}
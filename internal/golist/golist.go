@@ -0,0 +1,231 @@
+// Package golist finds the Go packages that a main package depends on.
+//
+// A Go repo is often the build context for several images, one per binary.
+// With the dependencies of each binary, Tilt can ignore edits to Go files
+// that the binary doesn't import, instead of rebuilding every image.
+package golist
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+var moduleFileNames = []string{"go.mod", "go.sum", "go.work"}
+
+// The Go packages that a main package depends on.
+type Deps struct {
+	// The directory that `go list` ran in.
+	Dir string
+
+	// The main package, relative to Dir (e.g., ./cmd/api).
+	Main string
+
+	// The directories of the packages that Main depends on (including
+	// itself), inside Dir. Packages outside Dir, like the standard library
+	// and the module cache, are left out.
+	PackageDirs []string
+
+	// When `go list` last ran.
+	RefreshedAt time.Time
+
+	// A hash of go.mod, go.sum, and the imports of each package. When it
+	// changes, the deps are stale.
+	fingerprint string
+}
+
+// IgnorePatterns returns dockerignore-style patterns, relative to Dir,
+// that ignore the Go files that Main doesn't depend on.
+//
+// Only Go source files are ignored. Other files (like go.mod, or files
+// that the binary embeds) still count.
+func (d Deps) IgnorePatterns() []string {
+	result := []string{"**/*.go"}
+	for _, dir := range d.PackageDirs {
+		rel, err := filepath.Rel(d.Dir, dir)
+		if err != nil {
+			continue
+		}
+		result = append(result, "!"+filepath.ToSlash(filepath.Join(rel, "*.go")))
+	}
+
+	// Tests never affect the binary.
+	return append(result, "**/*_test.go")
+}
+
+// ModuleFiles returns the go.mod, go.sum, and go.work files that the deps
+// were computed from. When they change, the deps should be recomputed.
+func (d Deps) ModuleFiles() []string {
+	var result []string
+	for _, modDir := range moduleDirs(d.Dir) {
+		for _, name := range moduleFileNames {
+			p := filepath.Join(modDir, name)
+			if _, err := os.Stat(p); err == nil {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
+
+type runFunc func(ctx context.Context, dir string, args ...string) ([]byte, error)
+
+// Analyzer runs `go list`, and caches the result until go.mod, go.sum, or
+// the imports of a dependency change.
+type Analyzer struct {
+	mu    sync.Mutex
+	cache map[string]Deps
+	run   runFunc
+	now   func() time.Time
+}
+
+func NewAnalyzer(execer localexec.Execer) *Analyzer {
+	return &Analyzer{
+		cache: make(map[string]Deps),
+		run:   goRunner(execer),
+		now:   time.Now,
+	}
+}
+
+func goRunner(execer localexec.Execer) runFunc {
+	return func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := model.Cmd{Argv: append([]string{"go"}, args...), Dir: dir}
+		exitCode, err := execer.Run(ctx, cmd, localexec.RunIO{Stdout: &stdout, Stderr: &stderr})
+		if err == nil && exitCode != 0 {
+			err = fmt.Errorf("exit status %d", exitCode)
+		}
+		if err != nil {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+		return stdout.Bytes(), nil
+	}
+}
+
+// Deps returns the packages that main depends on. main is a package path
+// relative to dir, like ./cmd/api.
+func (a *Analyzer) Deps(ctx context.Context, dir string, main string) (Deps, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := dir + "\x00" + main
+	cached, ok := a.cache[key]
+	if ok && cached.fingerprint == fingerprint(cached.Dir, cached.PackageDirs) {
+		return cached, nil
+	}
+
+	out, err := a.run(ctx, dir, "list", "-deps", "-f", "{{if not .Standard}}{{.Dir}}{{end}}", main)
+	if err != nil {
+		return Deps{}, fmt.Errorf("go list -deps %s: %v", main, err)
+	}
+
+	// go list reports real paths, so resolve symlinks before comparing.
+	realDir, err := ospath.RealAbs(dir)
+	if err != nil {
+		return Deps{}, err
+	}
+
+	seen := map[string]bool{}
+	var pkgDirs []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		rel, ok := ospath.Child(realDir, line)
+		if !ok {
+			continue
+		}
+		pkgDir := filepath.Join(dir, rel)
+		if seen[pkgDir] {
+			continue
+		}
+		seen[pkgDir] = true
+		pkgDirs = append(pkgDirs, pkgDir)
+	}
+	if len(pkgDirs) == 0 {
+		return Deps{}, fmt.Errorf("go list -deps %s: no packages found in %s", main, dir)
+	}
+	sort.Strings(pkgDirs)
+
+	deps := Deps{
+		Dir:         dir,
+		Main:        main,
+		PackageDirs: pkgDirs,
+		RefreshedAt: a.now(),
+		fingerprint: fingerprint(dir, pkgDirs),
+	}
+	a.cache[key] = deps
+	return deps, nil
+}
+
+// fingerprint hashes everything that can change which packages a main
+// package depends on: the module files, the Go files in each package, and
+// their imports.
+func fingerprint(dir string, pkgDirs []string) string {
+	h := sha256.New()
+
+	for _, modDir := range moduleDirs(dir) {
+		for _, name := range moduleFileNames {
+			contents, _ := os.ReadFile(filepath.Join(modDir, name))
+			_, _ = fmt.Fprintf(h, "%s\x00%d\x00", filepath.Join(modDir, name), len(contents))
+			_, _ = h.Write(contents)
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, pkgDir := range pkgDirs {
+		files, _ := filepath.Glob(filepath.Join(pkgDir, "*.go"))
+		sort.Strings(files)
+		for _, f := range files {
+			if strings.HasSuffix(f, "_test.go") {
+				continue
+			}
+			_, _ = fmt.Fprintf(h, "%s\x00", f)
+			parsed, err := parser.ParseFile(fset, f, nil, parser.ImportsOnly)
+			if err != nil {
+				// Hash the error, so that the deps refresh once the file is fixed.
+				_, _ = fmt.Fprintf(h, "error\x00")
+				continue
+			}
+			for _, imp := range parsed.Imports {
+				_, _ = fmt.Fprintf(h, "%s\x00", imp.Path.Value)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// moduleDirs returns dir and its parents, up to the first one with a go.mod.
+func moduleDirs(dir string) []string {
+	var result []string
+	for {
+		result = append(result, dir)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return result
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return result
+		}
+		dir = parent
+	}
+}
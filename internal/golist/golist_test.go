@@ -0,0 +1,109 @@
+package golist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/testutils"
+)
+
+type fixture struct {
+	t        *testing.T
+	ctx      context.Context
+	dir      string
+	analyzer *Analyzer
+	runs     int
+}
+
+func newFixture(t *testing.T) *fixture {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	ctx, _, _ := testutils.CtxAndAnalyticsForTest()
+	f := &fixture{t: t, ctx: ctx, dir: dir}
+	f.analyzer = NewAnalyzer(localexec.NewProcessExecer(localexec.EmptyEnv()))
+	f.analyzer.now = func() time.Time { return time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC) }
+	run := f.analyzer.run
+	f.analyzer.run = func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+		f.runs++
+		return run(ctx, dir, args...)
+	}
+
+	f.file("go.mod", "module example.com/app\n\ngo 1.20\n")
+	f.file("cmd/api/main.go", "package main\n\nimport _ \"example.com/app/pkg/db\"\n\nfunc main() {}\n")
+	f.file("cmd/worker/main.go", "package main\n\nfunc main() {}\n")
+	f.file("pkg/db/db.go", "package db\n\nimport _ \"fmt\"\n")
+	f.file("pkg/queue/queue.go", "package queue\n")
+	return f
+}
+
+func (f *fixture) file(rel string, contents string) {
+	p := filepath.Join(f.dir, rel)
+	require.NoError(f.t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(f.t, os.WriteFile(p, []byte(contents), 0644))
+}
+
+func (f *fixture) deps(main string) Deps {
+	deps, err := f.analyzer.Deps(f.ctx, f.dir, main)
+	require.NoError(f.t, err)
+	return deps
+}
+
+func TestDeps(t *testing.T) {
+	f := newFixture(t)
+
+	deps := f.deps("./cmd/api")
+	assert.Equal(t, []string{
+		filepath.Join(f.dir, "cmd", "api"),
+		filepath.Join(f.dir, "pkg", "db"),
+	}, deps.PackageDirs)
+	assert.Equal(t, []string{"**/*.go", "!cmd/api/*.go", "!pkg/db/*.go", "**/*_test.go"}, deps.IgnorePatterns())
+}
+
+func TestDepsCachedUntilImportsChange(t *testing.T) {
+	f := newFixture(t)
+
+	f.deps("./cmd/api")
+	assert.Equal(t, 1, f.runs)
+
+	// Editing a function body doesn't change the deps.
+	f.file("pkg/db/db.go", "package db\n\nimport _ \"fmt\"\n\nfunc Open() {}\n")
+	f.deps("./cmd/api")
+	assert.Equal(t, 1, f.runs)
+
+	// Adding an import does.
+	f.file("pkg/db/db.go", "package db\n\nimport _ \"example.com/app/pkg/queue\"\n")
+	deps := f.deps("./cmd/api")
+	assert.Equal(t, 2, f.runs)
+	assert.Contains(t, deps.PackageDirs, filepath.Join(f.dir, "pkg", "queue"))
+
+	// So does go.mod.
+	f.file("go.mod", "module example.com/app\n\ngo 1.21\n")
+	f.deps("./cmd/api")
+	assert.Equal(t, 3, f.runs)
+}
+
+func TestDepsError(t *testing.T) {
+	f := newFixture(t)
+	_, err := f.analyzer.Deps(f.ctx, f.dir, "./cmd/missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "go list -deps ./cmd/missing")
+}
+
+func TestModuleFiles(t *testing.T) {
+	f := newFixture(t)
+	f.file("go.sum", "")
+
+	deps := f.deps("./cmd/api")
+	assert.Equal(t, []string{
+		filepath.Join(f.dir, "go.mod"),
+		filepath.Join(f.dir, "go.sum"),
+	}, deps.ModuleFiles())
+}
@@ -2,8 +2,12 @@ package hud
 
 import (
 	"io"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/mattn/go-isatty"
+
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
@@ -12,15 +16,31 @@ var backoffMultiplier = time.Duration(2)
 
 type Stdout io.Writer
 
+// ANSI codes to move the cursor to the start of the previous line and clear it.
+const overwritePreviousLine = "\x1b[1A\r\x1b[2K"
+
 type IncrementalPrinter struct {
 	progress map[progressKey]progressStatus
 	stdout   Stdout
+
+	// On a terminal, a progress line that updates the last line printed
+	// overwrites it in place.
+	overwrite bool
+
+	// The progress line that was printed last, if any.
+	lastProgress progressKey
+	hasLast      bool
 }
 
 func NewIncrementalPrinter(stdout Stdout) *IncrementalPrinter {
+	overwrite := false
+	if f, ok := stdout.(*os.File); ok {
+		overwrite = isatty.IsTerminal(f.Fd())
+	}
 	return &IncrementalPrinter{
-		progress: make(map[progressKey]progressStatus),
-		stdout:   stdout,
+		progress:  make(map[progressKey]progressStatus),
+		stdout:    stdout,
+		overwrite: overwrite,
 	}
 }
 
@@ -30,15 +50,16 @@ func (p *IncrementalPrinter) PrintNewline() {
 
 func (p *IncrementalPrinter) Print(lines []logstore.LogLine) {
 	for _, line := range lines {
-		// Naive progress implementation: skip lines that have already been printed
-		// recently. This works with any output stream.
-		//
-		// TODO(nick): Use ANSI codes to overwrite previous lines. It requires
-		// a little extra bookkeeping about where to find the progress line,
-		// and only works on terminals.
 		progressID := line.ProgressID
 		key := progressKey{spanID: line.SpanID, progressID: progressID}
-		if progressID != "" {
+
+		if p.overwrite && progressID != "" && p.hasLast && p.lastProgress == key {
+			// On a terminal, overwrite the progress line if nothing has been
+			// printed since.
+			_, _ = io.WriteString(p.stdout, overwritePreviousLine)
+		} else if progressID != "" {
+			// Naive progress implementation: skip lines that have already been printed
+			// recently. This works with any output stream.
 			status, hasBeenPrinted := p.progress[key]
 			shouldPrint := line.ProgressMustPrint ||
 				!hasBeenPrinted ||
@@ -48,6 +69,8 @@ func (p *IncrementalPrinter) Print(lines []logstore.LogLine) {
 			}
 		}
 		_, _ = io.WriteString(p.stdout, line.Text)
+		p.lastProgress = key
+		p.hasLast = progressID != "" && strings.HasSuffix(line.Text, "\n")
 
 		if progressID != "" {
 			status := p.progress[key]
@@ -52,3 +52,24 @@ func TestPrinterMustPrint(t *testing.T) {
 	assert.Equal(t, "layer 1: Pending\nlayer 2: Pending\nlayer 1: Done\n", out.String())
 
 }
+
+func TestPrinterOverwritesProgressOnTerminal(t *testing.T) {
+	out := &bytes.Buffer{}
+	now := time.Now()
+	printer := NewIncrementalPrinter(Stdout(out))
+	printer.overwrite = true
+
+	printer.Print([]logstore.LogLine{
+		logstore.LogLine{Text: "Step 1/3\n", ProgressID: "tiltfile", Time: now},
+		logstore.LogLine{Text: "Step 2/3\n", ProgressID: "tiltfile", Time: now},
+	})
+	assert.Equal(t, "Step 1/3\n"+overwritePreviousLine+"Step 2/3\n", out.String())
+
+	// Once something else is printed, the progress line starts over.
+	out.Reset()
+	printer.Print([]logstore.LogLine{
+		logstore.LogLine{Text: "hello\n", Time: now},
+		logstore.LogLine{Text: "Step 3/3\n", ProgressID: "tiltfile", Time: now.Add(time.Hour)},
+	})
+	assert.Equal(t, "hello\nStep 3/3\n", out.String())
+}
@@ -4,17 +4,21 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	tty "github.com/mattn/go-tty"
 
 	"github.com/tilt-dev/tilt/internal/analytics"
 	"github.com/tilt-dev/tilt/internal/hud"
+	"github.com/tilt-dev/tilt/internal/hud/webview"
 	"github.com/tilt-dev/tilt/internal/openurl"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 )
 
 //nolint:govet
@@ -132,6 +136,7 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore, _ store.
 	hasBrowserUI := !p.url.Empty()
 	if hasBrowserUI {
 		_, _ = fmt.Fprintf(p.stdout, "(space) to open the browser\n")
+		_, _ = fmt.Fprintf(p.stdout, "(b) to bookmark this point in the logs\n")
 	}
 
 	_, _ = fmt.Fprintf(p.stdout, "(s) to stream logs (--stream=true)\n")
@@ -212,6 +217,18 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore, _ store.
 						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
 					}
 					msg.stopCh <- false
+				case 'b':
+					if !hasBrowserUI {
+						msg.stopCh <- false
+						break
+					}
+					p.a.Incr("ui.prompt.bookmark", map[string]string{})
+					state := st.RLockState()
+					bookmark := state.LogStore.Bookmark("", time.Now())
+					st.RUnlockState()
+					st.Dispatch(store.LogBookmarkAction{Bookmark: bookmark})
+					_, _ = fmt.Fprintf(p.stdout, "%s: %s\n", bookmark.Name, p.bookmarkURL(bookmark))
+					msg.stopCh <- false
 				default:
 					msg.stopCh <- false
 
@@ -223,6 +240,15 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore, _ store.
 	return nil
 }
 
+func (p *TerminalPrompt) bookmarkURL(b logstore.Bookmark) string {
+	u := url.URL(p.url)
+	ref, err := url.Parse(webview.LogBookmarkPath(b))
+	if err != nil {
+		return u.String()
+	}
+	return u.ResolveReference(ref).String()
+}
+
 type runeMessage struct {
 	rune rune
 
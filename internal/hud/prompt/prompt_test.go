@@ -56,6 +56,21 @@ func TestOpenHUD(t *testing.T) {
 	assert.Equal(t, SwitchTerminalModeAction{Mode: store.TerminalModeHUD}, action)
 }
 
+func TestBookmark(t *testing.T) {
+	f := newFixture(t)
+
+	_ = f.prompt.OnChange(f.ctx, f.st, store.LegacyChangeSummary())
+
+	assert.Contains(t, f.out.String(), "(b) to bookmark this point in the logs")
+
+	f.input.nextRune <- 'b'
+
+	action := f.st.WaitForAction(t, reflect.TypeOf(store.LogBookmarkAction{}))
+	bookmark := action.(store.LogBookmarkAction).Bookmark
+	assert.Contains(t, bookmark.Name, "Bookmark at ")
+	f.out.AssertEventuallyContains(t, "http://localhost:10350/r/(all)/overview?at=0", time.Second)
+}
+
 func TestInitOutput(t *testing.T) {
 	f := newFixture(t)
 
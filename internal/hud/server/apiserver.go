@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/tilt-dev/tilt-apiserver/pkg/server/builder"
 	"github.com/tilt-dev/tilt-apiserver/pkg/server/options"
 	"github.com/tilt-dev/tilt-apiserver/pkg/server/testdata"
+	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -66,7 +69,19 @@ func ProvideKeyCert(apiServerName model.APIServerName, host model.WebHost, port
 		return options.GeneratableKeyCert{}, err
 	}
 
-	return options.GeneratableKeyCert{CertDirectory: filepath.Dir(exampleCert), PairName: pairName}, nil
+	// The apiserver generates a new pair if one is missing, but fails to
+	// start if the pair is unreadable. The pair is only a cache, so remove
+	// it and let it be regenerated.
+	certDir := filepath.Dir(exampleCert)
+	certFile := filepath.Join(certDir, pairName+".crt")
+	keyFile := filepath.Join(certDir, pairName+".key")
+	_, err = tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil && !os.IsNotExist(err) {
+		_ = os.Remove(certFile)
+		_ = os.Remove(keyFile)
+	}
+
+	return options.GeneratableKeyCert{CertDirectory: certDir, PairName: pairName}, nil
 }
 
 // Uses the kubernetes config-loading library to load
@@ -93,10 +108,14 @@ func ProvideWebListener(host model.WebHost, port model.WebPort) (WebListener, er
 	webListener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", string(host), int(port)))
 	if err != nil {
 		if strings.HasSuffix(err.Error(), "address already in use") {
-			return nil, fmt.Errorf("Tilt cannot start because you already have another process on port %d\n"+
+			process := "another process"
+			if holder, ok := localexec.FindPortHolder(int(port)); ok {
+				process = fmt.Sprintf("another process (%s)", holder)
+			}
+			return nil, fmt.Errorf("Tilt cannot start because you already have %s on port %d\n"+
 				"If you want to run multiple Tilt instances simultaneously,\n"+
 				"use the --port flag or TILT_PORT env variable to set a custom port\nOriginal error: %v",
-				port, err)
+				process, port, err)
 		}
 		return nil, err
 	}
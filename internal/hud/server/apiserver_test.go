@@ -135,6 +135,20 @@ func TestAPIServerDynamicClient(t *testing.T) {
 	}
 }
 
+func TestAPIServerCorruptConfig(t *testing.T) {
+	f := newAPIServerFixture(t)
+	f.WriteFile("config", "clusters: [this is not yaml")
+	f.start()
+
+	quarantined, err := filepath.Glob(f.JoinPath("config.corrupt-*"))
+	require.NoError(t, err)
+	assert.Len(t, quarantined, 1)
+
+	config, err := clientcmd.LoadFromFile(f.JoinPath("config"))
+	require.NoError(t, err)
+	assert.Contains(t, config.Contexts, "tilt-default")
+}
+
 func TestAPIServerProxy(t *testing.T) {
 	f := newAPIServerFixture(t)
 	f.start()
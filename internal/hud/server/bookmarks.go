@@ -0,0 +1,81 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
+)
+
+type bookmarkPayload struct {
+	Name string `json:"name"`
+
+	// Defaults to now.
+	Time *time.Time `json:"time"`
+}
+
+type bookmarkResponse struct {
+	logstore.Bookmark
+
+	// A link to the web UI, scrolled to the bookmark.
+	URL string `json:"url"`
+}
+
+// Lists the log bookmarks (GET), or bookmarks the log (POST). The payload is:
+//
+//	{"name": "repro happened here", "time": "2022-01-02T15:04:05Z"}
+//
+// Both fields are optional. Responds 201 with the bookmark.
+func (s *HeadsUpServer) HandleLogBookmarks(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		state := s.store.RLockState()
+		resp := make([]bookmarkResponse, 0, len(state.LogBookmarks))
+		for _, b := range state.LogBookmarks {
+			resp = append(resp, toBookmarkResponse(b))
+		}
+		s.store.RUnlockState()
+		writeBookmarkResponse(w, http.StatusOK, resp)
+
+	case http.MethodPost:
+		var payload bookmarkPayload
+		err := json.NewDecoder(req.Body).Decode(&payload)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		t := time.Now()
+		if payload.Time != nil {
+			t = *payload.Time
+		}
+
+		state := s.store.RLockState()
+		bookmark := state.LogStore.Bookmark(strings.TrimSpace(payload.Name), t)
+		s.store.RUnlockState()
+
+		s.store.Dispatch(store.LogBookmarkAction{Bookmark: bookmark})
+		writeBookmarkResponse(w, http.StatusCreated, toBookmarkResponse(bookmark))
+
+	default:
+		http.Error(w, "must be GET or POST request", http.StatusMethodNotAllowed)
+	}
+}
+
+func toBookmarkResponse(b logstore.Bookmark) bookmarkResponse {
+	return bookmarkResponse{
+		Bookmark: b,
+		URL:      webview.LogBookmarkPath(b),
+	}
+}
+
+func writeBookmarkResponse(w http.ResponseWriter, status int, resp interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
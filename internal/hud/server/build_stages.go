@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The last build of one Docker image, split up by Dockerfile stage.
+type ImageBuildStages struct {
+	// The image ref, as written in the Tiltfile.
+	Image string `json:"image"`
+
+	// The DockerImage API object that the stages came from.
+	DockerImage string `json:"dockerImage"`
+
+	Stages []build.BuildStage `json:"stages"`
+}
+
+// Reports the timings of each Dockerfile stage (and each step within a
+// stage) in the last build of a resource's images, for tools that want to
+// know where a build spends its time.
+//
+// Query params:
+//   - resource: the resource name (required)
+//
+// Images built with custom_build() have no stages.
+func (s *HeadsUpServer) HandleBuildStages(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mn := model.ManifestName(req.URL.Query().Get("resource"))
+	if mn == "" {
+		http.Error(w, "missing resource", http.StatusBadRequest)
+		return
+	}
+
+	state := s.store.RLockState()
+	manifest, ok := state.Manifest(mn)
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q not found", mn), http.StatusNotFound)
+		return
+	}
+
+	result := []ImageBuildStages{}
+	for _, iTarget := range manifest.ImageTargets {
+		if iTarget.DockerImageName == "" {
+			continue
+		}
+
+		var di v1alpha1.DockerImage
+		err := s.ctrlClient.Get(req.Context(), types.NamespacedName{Name: iTarget.DockerImageName}, &di)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			http.Error(w, fmt.Sprintf("Error reading DockerImage %s: %v", iTarget.DockerImageName, err),
+				http.StatusInternalServerError)
+			return
+		}
+
+		stages := build.BuildStages(di.Status.StageStatuses)
+		if stages == nil {
+			stages = []build.BuildStage{}
+		}
+		result = append(result, ImageBuildStages{
+			Image:       di.Spec.Ref,
+			DockerImage: di.Name,
+			Stages:      stages,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering build stages: %v", err), http.StatusInternalServerError)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"regexp"
 
 	"github.com/gorilla/mux"
@@ -18,8 +19,10 @@ import (
 
 	"github.com/tilt-dev/tilt-apiserver/pkg/server/start"
 	"github.com/tilt-dev/tilt/internal/filelock"
+	"github.com/tilt-dev/tilt/internal/statefile"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/pkg/assets"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 )
 
@@ -86,7 +89,7 @@ func (s *HeadsUpServerController) TearDown(ctx context.Context) {
 	_ = s.webServer.Close()
 	_ = s.apiServer.Close()
 
-	_ = s.removeFromAPIServerConfig()
+	_ = s.removeFromAPIServerConfig(ctx)
 }
 
 func (s *HeadsUpServerController) OnChange(ctx context.Context, st store.RStore, _ store.ChangeSummary) error {
@@ -103,7 +106,7 @@ func (s *HeadsUpServerController) SetUp(ctx context.Context, st store.RStore) er
 	if err != nil {
 		return fmt.Errorf("Cannot start the tilt-apiserver: %v", err)
 	}
-	err = s.addToAPIServerConfig()
+	err = s.addToAPIServerConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("writing tilt api configs: %v", err)
 	}
@@ -198,17 +201,12 @@ func (s *HeadsUpServerController) setUpHelper(ctx context.Context, st store.RSto
 // Write the API server configs into the user settings directory.
 //
 // Usually shows up as ~/.windmill/config or ~/.tilt-dev/config.
-func (s *HeadsUpServerController) addToAPIServerConfig() error {
+func (s *HeadsUpServerController) addToAPIServerConfig(ctx context.Context) error {
 	if s.configAccess == nil {
 		return nil
 	}
 
-	var newConfig *clientcmdapi.Config
-	err := filelock.WithRLock(s.configAccess, func() error {
-		var e error
-		newConfig, e = s.configAccess.GetStartingConfig()
-		return e
-	})
+	newConfig, err := s.startingConfig(ctx)
 	if err != nil {
 		return err
 	}
@@ -239,17 +237,12 @@ func (s *HeadsUpServerController) addToAPIServerConfig() error {
 // Remove this API server's configs into the user settings directory.
 //
 // Usually shows up as ~/.windmill/config or ~/.tilt-dev/config.
-func (s *HeadsUpServerController) removeFromAPIServerConfig() error {
+func (s *HeadsUpServerController) removeFromAPIServerConfig(ctx context.Context) error {
 	if s.configAccess == nil {
 		return nil
 	}
 
-	var newConfig *clientcmdapi.Config
-	err := filelock.WithRLock(s.configAccess, func() error {
-		var e error
-		newConfig, e = s.configAccess.GetStartingConfig()
-		return e
-	})
+	newConfig, err := s.startingConfig(ctx)
 	if err != nil {
 		return err
 	}
@@ -266,6 +259,40 @@ func (s *HeadsUpServerController) removeFromAPIServerConfig() error {
 	return s.modifyConfig(*newConfig)
 }
 
+// Read the API server configs from the user settings directory.
+//
+// If the file is corrupt, quarantine it and start over with an empty config,
+// so that a bad file doesn't stop Tilt from starting.
+func (s *HeadsUpServerController) startingConfig(ctx context.Context) (*clientcmdapi.Config, error) {
+	var config *clientcmdapi.Config
+	err := filelock.WithRLock(s.configAccess, func() error {
+		var e error
+		config, e = s.configAccess.GetStartingConfig()
+		return e
+	})
+	if err == nil {
+		return config, nil
+	}
+
+	path := s.configAccess.GetDefaultFilename()
+	_, loadErr := clientcmd.LoadFromFile(path)
+	if loadErr == nil || os.IsNotExist(loadErr) {
+		// The default file is fine, so this is some other problem.
+		return nil, err
+	}
+
+	var corruptErr error
+	err = filelock.WithLock(s.configAccess, func() error {
+		corruptErr = statefile.Recover(path, loadErr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Get(ctx).Warnf("Tilt API configs: %v", corruptErr)
+	return clientcmdapi.NewConfig(), nil
+}
+
 func (s *HeadsUpServerController) modifyConfig(config clientcmdapi.Config) error {
 	return filelock.WithLock(s.configAccess, func() error {
 		return clientcmd.ModifyConfig(s.configAccess, config, true)
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/tilt-dev/tilt/internal/build"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/pkg/apis"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The version of the layout of an export bundle. Bump it when a file in the
+// bundle moves or changes shape.
+const ExportSchemaVersion = 1
+
+// The name of the index file at the root of an export bundle.
+const ExportManifestFile = "manifest.json"
+
+// The index of an export bundle.
+type ExportManifest struct {
+	SchemaVersion int       `json:"schemaVersion"`
+	Resource      string    `json:"resource"`
+	TiltVersion   string    `json:"tiltVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+
+	// Every other file in the bundle.
+	Files []ExportFile `json:"files"`
+
+	// Anything that couldn't be exported as-is, e.g., a build context that
+	// doesn't exist anymore.
+	Notes []string `json:"notes,omitempty"`
+}
+
+type ExportFile struct {
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// One file in the build context that Tilt sends for an image.
+type ExportContextFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// One of the resource's recent builds.
+type ExportBuild struct {
+	StartTime    time.Time `json:"startTime"`
+	FinishTime   time.Time `json:"finishTime,omitempty"`
+	Reason       string    `json:"reason"`
+	BuildTypes   []string  `json:"buildTypes,omitempty"`
+	Edits        []string  `json:"edits,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	WarningCount int       `json:"warningCount,omitempty"`
+
+	// The path of the build's log in the bundle.
+	Log string `json:"log,omitempty"`
+}
+
+// Exports the effective configuration of a resource as a gzipped tar, so
+// that a build or deploy can be reproduced (or a bug reported) outside of
+// the running Tilt.
+//
+// The bundle has the Dockerfile of each image after Tilt injected the images
+// it depends on, the files in its build context with their hashes, its build
+// args and other settings, the live_update config, the YAML that Tilt
+// applied, and the recent builds with their logs. Secrets are scrubbed from
+// every file. manifest.json describes the rest.
+//
+// Query params:
+//   - resource: the resource name (required)
+func (s *HeadsUpServer) HandleExport(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	mn := model.ManifestName(req.URL.Query().Get("resource"))
+	if mn == "" {
+		http.Error(w, "missing resource", http.StatusBadRequest)
+		return
+	}
+
+	b := &exportBundle{secrets: model.SecretSet{}}
+	state := s.store.RLockState()
+	manifest, ok := state.Manifest(mn)
+	if ok {
+		b.secrets.AddAll(state.Secrets)
+		b.manifest = ExportManifest{
+			SchemaVersion: ExportSchemaVersion,
+			Resource:      mn.String(),
+			TiltVersion:   state.TiltBuildInfo.Version,
+			CreatedAt:     time.Now().UTC(),
+		}
+		var history []model.BuildRecord
+		if ms, ok := state.ManifestState(mn); ok {
+			history = ms.BuildHistory
+		}
+		b.addBuilds(history, state.LogStore.SpanLog)
+	}
+	s.store.RUnlockState()
+	if !ok {
+		http.Error(w, fmt.Sprintf("resource %q not found", mn), http.StatusNotFound)
+		return
+	}
+
+	err := s.exportManifest(req.Context(), b, manifest)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error exporting %s: %v", mn, err), http.StatusInternalServerError)
+		return
+	}
+
+	var out bytes.Buffer
+	err = b.write(&out)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error exporting %s: %v", mn, err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-bundle.tgz", apis.SanitizeName(mn.String()))))
+	_, _ = w.Write(out.Bytes())
+}
+
+func (s *HeadsUpServer) exportManifest(ctx context.Context, b *exportBundle, manifest model.Manifest) error {
+	for _, iTarget := range manifest.ImageTargets {
+		dir := path.Join("images", apis.SanitizeName(iTarget.ID().Name.String()))
+		if iTarget.DockerImageName != "" {
+			err := s.exportDockerImage(ctx, b, dir, iTarget.DockerImageName)
+			if err != nil {
+				return err
+			}
+		} else if iTarget.CmdImageName != "" {
+			var ci v1alpha1.CmdImage
+			err := s.ctrlClient.Get(ctx, types.NamespacedName{Name: iTarget.CmdImageName}, &ci)
+			if apierrors.IsNotFound(err) {
+				b.note("custom_build() image %s has not been created yet", iTarget.CmdImageName)
+			} else if err != nil {
+				return fmt.Errorf("reading CmdImage %s: %v", iTarget.CmdImageName, err)
+			} else {
+				b.addJSON(path.Join(dir, "custom_build.json"),
+					"The command that builds the image with custom_build(), and its settings", ci.Spec)
+			}
+		}
+
+		if !liveupdate.IsEmptySpec(iTarget.LiveUpdateSpec) {
+			b.addJSON(path.Join(dir, "live_update.json"),
+				"The live_update steps for the image", iTarget.LiveUpdateSpec)
+		}
+	}
+
+	if manifest.IsK8s() {
+		kTarget := manifest.K8sTarget()
+		var ka v1alpha1.KubernetesApply
+		err := s.ctrlClient.Get(ctx, types.NamespacedName{Name: kTarget.ID().Name.String()}, &ka)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("reading KubernetesApply %s: %v", kTarget.ID().Name, err)
+		}
+
+		if ka.Status.ResultYAML != "" {
+			b.add("kubernetes.yaml", "The YAML that Tilt applied, with the images it built injected",
+				[]byte(ka.Status.ResultYAML))
+		} else if kTarget.YAML != "" {
+			b.add("kubernetes.yaml", "The YAML from the Tiltfile. Tilt hasn't applied it, so no images are injected",
+				[]byte(kTarget.YAML))
+			b.note("the resource hasn't been deployed, so kubernetes.yaml is the YAML before injection")
+		}
+	} else if manifest.IsDC() {
+		b.add("docker-compose.yaml", "The Docker Compose service, as Tilt read it from the project",
+			[]byte(manifest.DockerComposeTarget().ServiceYAML))
+	}
+
+	return nil
+}
+
+func (s *HeadsUpServer) exportDockerImage(ctx context.Context, b *exportBundle, dir string, name string) error {
+	var di v1alpha1.DockerImage
+	err := s.ctrlClient.Get(ctx, types.NamespacedName{Name: name}, &di)
+	if apierrors.IsNotFound(err) {
+		b.note("docker_build() image %s has not been created yet", name)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading DockerImage %s: %v", name, err)
+	}
+
+	imageMaps := make(map[types.NamespacedName]*v1alpha1.ImageMap)
+	for _, imName := range di.Spec.ImageMaps {
+		var im v1alpha1.ImageMap
+		nn := types.NamespacedName{Name: imName}
+		err := s.ctrlClient.Get(ctx, nn, &im)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("reading ImageMap %s: %v", imName, err)
+		}
+		imageMaps[nn] = &im
+	}
+
+	spec, err := build.InjectImageDependencies(di.Spec, imageMaps)
+	if err != nil {
+		b.note("injecting images into the Dockerfile for %s: %v", di.Spec.Ref, err)
+		spec = di.Spec
+	}
+
+	b.add(path.Join(dir, "Dockerfile"),
+		"The Dockerfile that Tilt builds with, after injecting the images it depends on",
+		[]byte(spec.DockerfileContents))
+
+	settings := spec
+	settings.DockerfileContents = ""
+	b.addJSON(path.Join(dir, "docker_build.json"),
+		"The build args, target, and other settings that Tilt builds the image with", settings)
+
+	files, err := exportContextFiles(ctx, spec)
+	if err != nil {
+		b.note("listing the build context of %s: %v", di.Spec.Ref, err)
+		return nil
+	}
+	b.addJSON(path.Join(dir, "context.json"),
+		"The files in the build context after filtering, with their sizes and hashes", files)
+	return nil
+}
+
+// Lists the files in the context that Tilt would send for spec.
+func exportContextFiles(ctx context.Context, spec v1alpha1.DockerImageSpec) ([]ExportContextFile, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(build.WriteContext(ctx, pw, spec, ignore.CreateBuildContextFilter(spec.ContextIgnores)))
+	}()
+	defer func() {
+		_ = pr.Close()
+	}()
+
+	files := []ExportContextFile{}
+	tr := tar.NewReader(pr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, tr)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, ExportContextFile{
+			Path:   h.Name,
+			Size:   size,
+			SHA256: hex.EncodeToString(hash.Sum(nil)),
+		})
+	}
+}
+
+type exportEntry struct {
+	path     string
+	contents []byte
+}
+
+// Collects the files of an export bundle, with secrets scrubbed.
+type exportBundle struct {
+	manifest ExportManifest
+	secrets  model.SecretSet
+	entries  []exportEntry
+}
+
+func (b *exportBundle) add(p, description string, contents []byte) {
+	b.entries = append(b.entries, exportEntry{path: p, contents: b.secrets.Scrub(contents)})
+	b.manifest.Files = append(b.manifest.Files, ExportFile{Path: p, Description: description})
+}
+
+func (b *exportBundle) addJSON(p, description string, v interface{}) {
+	contents, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.note("encoding %s: %v", p, err)
+		return
+	}
+	b.add(p, description, append(contents, '\n'))
+}
+
+func (b *exportBundle) note(format string, args ...interface{}) {
+	b.manifest.Notes = append(b.manifest.Notes, string(b.secrets.Scrub([]byte(fmt.Sprintf(format, args...)))))
+}
+
+// Adds the builds in history (newest first) and their logs.
+func (b *exportBundle) addBuilds(history []model.BuildRecord, spanLog func(model.LogSpanID) string) {
+	builds := []ExportBuild{}
+	for i, record := range history {
+		eb := ExportBuild{
+			StartTime:    record.StartTime,
+			FinishTime:   record.FinishTime,
+			Reason:       record.Reason.String(),
+			Edits:        record.Edits,
+			WarningCount: record.WarningCount,
+		}
+		for _, bt := range record.BuildTypes {
+			eb.BuildTypes = append(eb.BuildTypes, string(bt))
+		}
+		if record.Error != nil {
+			eb.Error = record.Error.Error()
+		}
+		if record.SpanID != "" {
+			eb.Log = fmt.Sprintf("builds/%d.log", i)
+			b.add(eb.Log, fmt.Sprintf("The log of build %d in builds.json", i), []byte(spanLog(record.SpanID)))
+		}
+		builds = append(builds, eb)
+	}
+	b.addJSON("builds.json", "The most recent builds, newest first", builds)
+}
+
+// Writes the bundle as a gzipped tar, with manifest.json first.
+func (b *exportBundle) write(w io.Writer) error {
+	index, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	entries := append([]exportEntry{{path: ExportManifestFile, contents: append(index, '\n')}}, b.entries...)
+	for _, e := range entries {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    e.path,
+			Mode:    0644,
+			Size:    int64(len(e.contents)),
+			ModTime: b.manifest.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = tw.Write(e.contents)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
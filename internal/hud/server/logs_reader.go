@@ -8,8 +8,10 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
 	"github.com/tilt-dev/tilt/internal/hud"
 	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
 	"github.com/tilt-dev/tilt/pkg/model/logstore"
@@ -61,6 +63,9 @@ type LogStreamer struct {
 	serverWatermark int32
 	resources       model.ManifestNameSet // if present, resource(s) to stream logs for
 	printer         *hud.IncrementalPrinter
+
+	// The members of any requested resources that are resource groups.
+	groupMembers map[model.ManifestName][]model.ManifestName
 }
 
 func NewLogStreamer(resources []string, p *hud.IncrementalPrinter) *LogStreamer {
@@ -70,20 +75,27 @@ func NewLogStreamer(resources []string, p *hud.IncrementalPrinter) *LogStreamer
 	}
 
 	return &LogStreamer{
-		resources: mnSet,
-		logstore:  logstore.NewLogStore(),
-		printer:   p,
+		resources:    mnSet,
+		logstore:     logstore.NewLogStore(),
+		printer:      p,
+		groupMembers: make(map[model.ManifestName][]model.ManifestName),
 	}
 }
 
 func (ls *LogStreamer) Handle(v *proto_webview.View) error {
+	if v != nil {
+		ls.updateGroupMembers(v.UiResources)
+	}
+
 	if v == nil || v.LogList == nil || v.LogList.FromCheckpoint == -1 {
 		// Server has no new logs to send
 		return nil
 	}
 
+	manifestNames := ls.manifestNames()
+
 	// if printing logs for only one resource, don't need resource name prefix
-	suppressPrefix := len(ls.resources) == 1
+	suppressPrefix := len(manifestNames) == 1
 
 	segments := v.LogList.Segments
 	if v.LogList.FromCheckpoint < ls.serverWatermark {
@@ -98,7 +110,7 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 	}
 
 	ls.printer.Print(ls.logstore.ContinuingLinesWithOptions(ls.checkpoint, logstore.LineOptions{
-		ManifestNames:  ls.resources,
+		ManifestNames:  manifestNames,
 		SuppressPrefix: suppressPrefix,
 	}))
 
@@ -107,6 +119,50 @@ func (ls *LogStreamer) Handle(v *proto_webview.View) error {
 
 	return nil
 }
+
+// Resource groups don't have logs of their own, so the logs for a group
+// are the logs of its members. The server sends UIResources as they change,
+// so keep track of the members of any group we're streaming.
+func (ls *LogStreamer) updateGroupMembers(resources []*v1alpha1.UIResource) {
+	for _, r := range resources {
+		mn := model.ManifestName(r.Name)
+		if !ls.resources[mn] {
+			continue
+		}
+
+		members := uiresource.GroupMembers(r)
+		if r.DeletionTimestamp != nil || len(members) == 0 {
+			delete(ls.groupMembers, mn)
+			continue
+		}
+
+		mns := make([]model.ManifestName, 0, len(members))
+		for _, m := range members {
+			mns = append(mns, model.ManifestName(m))
+		}
+		ls.groupMembers[mn] = mns
+	}
+}
+
+// The resources to print logs for, with groups replaced by their members.
+func (ls *LogStreamer) manifestNames() model.ManifestNameSet {
+	if len(ls.groupMembers) == 0 {
+		return ls.resources
+	}
+
+	result := make(model.ManifestNameSet, len(ls.resources))
+	for mn := range ls.resources {
+		members, ok := ls.groupMembers[mn]
+		if !ok {
+			result[mn] = true
+			continue
+		}
+		for _, m := range members {
+			result[m] = true
+		}
+	}
+	return result
+}
 func StreamLogs(ctx context.Context, follow bool, url model.WebURL, resources []string, printer *hud.IncrementalPrinter) error {
 	url.Scheme = "ws"
 	url.Path = "/ws/view"
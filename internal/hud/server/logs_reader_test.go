@@ -8,7 +8,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/model"
 
 	"github.com/tilt-dev/tilt/internal/hud"
@@ -131,6 +134,29 @@ func TestLogStreamerCheckpointHandlingWithFiltering(t *testing.T) {
 	f.assertExpectedLogLines(expected)
 }
 
+func TestLogStreamerResourceGroup(t *testing.T) {
+	f := newLogStreamerFixture(t).withResourceNames("auth")
+	f.handle(&proto_webview.View{
+		UiResources: []*v1alpha1.UIResource{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "auth",
+					Annotations: map[string]string{uiresource.AnnotationGroupMembers: "auth-api,auth-redis"},
+				},
+			},
+		},
+	})
+
+	manifestNames := []string{"auth-api", "", "auth-redis", "web", "auth-api"}
+	view := f.newViewWithLogsForManifests(alphabet[:5], manifestNames, 0)
+	f.handle(view)
+
+	// Members are interleaved, so they're prefixed even though we asked for one resource.
+	expected := f.expectedLinesWithPrefixes(
+		[]string{"alpha", "charlie", "echo"}, []string{"auth-api", "auth-redis", "auth-api"})
+	f.assertExpectedLogLines(expected)
+}
+
 type logStreamerFixture struct {
 	t          *testing.T
 	fakeStdout *bytes.Buffer
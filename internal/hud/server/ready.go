@@ -0,0 +1,160 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+type readyResource struct {
+	Name     string   `json:"name"`
+	Ready    bool     `json:"ready"`
+	UpToDate bool     `json:"upToDate"`
+	Disabled bool     `json:"disabled,omitempty"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+type readyPayload struct {
+	Ready     bool            `json:"ready"`
+	Resources []readyResource `json:"resources"`
+}
+
+// Reports whether the whole environment is ready, so that external tooling
+// can wait on a single URL (e.g., with `curl --fail --retry`).
+//
+// Responds 200 if every enabled resource is Ready and UpToDate, and 503
+// otherwise. The body lists each resource and the reasons it's blocking.
+//
+// Filter the resources with query params, either repeated or comma-separated:
+//
+//	/api/ready?resource=api,web
+//	/api/ready?label=backend
+//
+// A resource named with `resource` that doesn't exist (yet) blocks readiness.
+func (s *HeadsUpServer) HandleReady(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var list v1alpha1.UIResourceList
+	err := s.ctrlClient.List(req.Context(), &list)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error listing resources: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	query := req.URL.Query()
+	names := queryValues(query["resource"])
+	labels := queryValues(query["label"])
+
+	payload := readyPayload{Ready: true, Resources: []readyResource{}}
+	found := map[string]bool{}
+	for _, r := range list.Items {
+		if len(names) > 0 && !names[r.Name] {
+			continue
+		}
+		if len(labels) > 0 && !hasAnyLabel(r.Labels, labels) {
+			continue
+		}
+		found[r.Name] = true
+
+		rr := toReadyResource(r)
+		if !rr.Disabled && !(rr.Ready && rr.UpToDate) {
+			payload.Ready = false
+		}
+		payload.Resources = append(payload.Resources, rr)
+	}
+
+	for name := range names {
+		if !found[name] {
+			payload.Ready = false
+			payload.Resources = append(payload.Resources, readyResource{
+				Name:    name,
+				Reasons: []string{"NotFound"},
+			})
+		}
+	}
+
+	sort.Slice(payload.Resources, func(i, j int) bool {
+		return payload.Resources[i].Name < payload.Resources[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if payload.Ready {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func toReadyResource(r v1alpha1.UIResource) readyResource {
+	rr := readyResource{
+		Name:     r.Name,
+		Disabled: r.Status.DisableStatus.State == v1alpha1.DisableStateDisabled,
+	}
+	if rr.Disabled {
+		return rr
+	}
+
+	for _, cType := range []v1alpha1.UIResourceConditionType{v1alpha1.UIResourceUpToDate, v1alpha1.UIResourceReady} {
+		c, ok := uiResourceCondition(r, cType)
+		if ok && c.Status == metav1.ConditionTrue {
+			if cType == v1alpha1.UIResourceReady {
+				rr.Ready = true
+			} else {
+				rr.UpToDate = true
+			}
+			continue
+		}
+
+		reason := fmt.Sprintf("Not%s", cType)
+		if ok && c.Reason != "" {
+			reason = c.Reason
+		}
+		if ok && c.Message != "" {
+			reason = fmt.Sprintf("%s: %s", reason, c.Message)
+		}
+		rr.Reasons = append(rr.Reasons, reason)
+	}
+	return rr
+}
+
+func uiResourceCondition(r v1alpha1.UIResource, cType v1alpha1.UIResourceConditionType) (v1alpha1.UIResourceCondition, bool) {
+	for _, c := range r.Status.Conditions {
+		if c.Type == cType {
+			return c, true
+		}
+	}
+	return v1alpha1.UIResourceCondition{}, false
+}
+
+// queryValues collects query params that may be repeated or comma-separated.
+func queryValues(values []string) map[string]bool {
+	result := map[string]bool{}
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" {
+				result[item] = true
+			}
+		}
+	}
+	return result
+}
+
+func hasAnyLabel(resourceLabels map[string]string, labels map[string]bool) bool {
+	for l := range resourceLabels {
+		if labels[l] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type resetPayload struct {
+	ManifestNames []string `json:"manifest_names"`
+
+	// Reset every enabled resource, instead of ManifestNames.
+	All bool `json:"all"`
+}
+
+type resetResponse struct {
+	ManifestNames []string `json:"manifest_names"`
+}
+
+// Resets resources for `tilt reset`: Tilt forgets what it built for them,
+// deletes their objects, and rebuilds them without the builder's cache.
+//
+// Responds 200 with the names of the resources that were reset. Responds
+// 404 if a resource doesn't exist, and 409 if it's disabled.
+func (s *HeadsUpServer) HandleReset(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "must be POST request", http.StatusBadRequest)
+		return
+	}
+
+	var payload resetPayload
+	decoder := json.NewDecoder(req.Body)
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(&payload)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error parsing JSON payload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if payload.All == (len(payload.ManifestNames) > 0) {
+		http.Error(w, "must specify either manifest_names or all", http.StatusBadRequest)
+		return
+	}
+
+	state := s.store.RLockState()
+	targets, status, err := resetTargets(state, payload)
+	s.store.RUnlockState()
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	names := []string{}
+	for _, mn := range targets {
+		s.store.Dispatch(store.AppendToTriggerQueueAction{
+			Name:   mn,
+			Reason: model.BuildReasonFlagTriggerCLI.With(model.BuildReasonFlagReset),
+		})
+		names = append(names, mn.String())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resetResponse{ManifestNames: names})
+}
+
+// Returns the resources to reset, or an error with its HTTP status.
+func resetTargets(state store.EngineState, payload resetPayload) ([]model.ManifestName, int, error) {
+	var result []model.ManifestName
+	if payload.All {
+		for _, mt := range state.Targets() {
+			if mt.State.DisableState != v1alpha1.DisableStateDisabled {
+				result = append(result, mt.Manifest.Name)
+			}
+		}
+		return result, http.StatusOK, nil
+	}
+
+	for _, name := range payload.ManifestNames {
+		mn := model.ManifestName(name)
+		if members := state.ResourceGroupMembers(mn); len(members) > 0 {
+			for _, member := range members {
+				mt, ok := state.ManifestTargets[member]
+				if ok && mt.State.DisableState != v1alpha1.DisableStateDisabled {
+					result = append(result, member)
+				}
+			}
+			continue
+		}
+
+		mt, ok := state.ManifestTargets[mn]
+		if !ok {
+			if _, isTiltfile := state.TiltfileStates[mn]; isTiltfile {
+				return nil, http.StatusBadRequest, fmt.Errorf("resource %q is a Tiltfile, which can't be reset", mn)
+			}
+			return nil, http.StatusNotFound, fmt.Errorf("resource %q does not exist", mn)
+		}
+		if mt.State.DisableState == v1alpha1.DisableStateDisabled {
+			return nil, http.StatusConflict, fmt.Errorf("resource %q is currently disabled", mn)
+		}
+		result = append(result, mn)
+	}
+	return result, http.StatusOK, nil
+}
@@ -7,11 +7,10 @@ import (
 	"log"
 	"net/http"
 	_ "net/http/pprof"
+	"strconv"
 
 	"google.golang.org/protobuf/types/known/timestamppb"
 
-	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
-
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -21,9 +20,12 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
 	"github.com/tilt-dev/tilt/internal/hud/webview"
+	"github.com/tilt-dev/tilt/internal/statefile"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/store/tiltfiles"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/assets"
 	"github.com/tilt-dev/tilt/pkg/model"
 	proto_webview "github.com/tilt-dev/tilt/pkg/webview"
@@ -50,6 +52,11 @@ type analyticsOptPayload struct {
 type triggerPayload struct {
 	ManifestNames []string          `json:"manifest_names"`
 	BuildReason   model.BuildReason `json:"build_reason"`
+
+	// Set by external tools instead of ManifestNames. See handleResourceTrigger.
+	Resource  string `json:"resource"`
+	Reason    string `json:"reason"`
+	BuildType string `json:"build_type"`
 }
 
 type overrideTriggerModePayload struct {
@@ -64,6 +71,9 @@ type HeadsUpServer struct {
 	a          *tiltanalytics.TiltAnalytics
 	wsList     *WebsocketList
 	ctrlClient ctrlclient.Client
+	base       xdg.Base
+
+	triggerLimiters triggerLimiters
 }
 
 func ProvideHeadsUpServer(
@@ -72,7 +82,8 @@ func ProvideHeadsUpServer(
 	assetServer assets.Server,
 	analytics *tiltanalytics.TiltAnalytics,
 	wsList *WebsocketList,
-	ctrlClient ctrlclient.Client) (*HeadsUpServer, error) {
+	ctrlClient ctrlclient.Client,
+	base xdg.Base) (*HeadsUpServer, error) {
 	r := mux.NewRouter().UseEncodedPath()
 	s := &HeadsUpServer{
 		ctx:        ctx,
@@ -81,6 +92,7 @@ func ProvideHeadsUpServer(
 		a:          analytics,
 		wsList:     wsList,
 		ctrlClient: ctrlClient,
+		base:       base,
 	}
 
 	r.HandleFunc("/api/view", s.ViewJSON)
@@ -88,7 +100,15 @@ func ProvideHeadsUpServer(
 	r.HandleFunc("/api/analytics", s.HandleAnalytics)
 	r.HandleFunc("/api/analytics_opt", s.HandleAnalyticsOpt)
 	r.HandleFunc("/api/trigger", s.HandleTrigger)
+	r.HandleFunc("/api/reset", s.HandleReset)
+	r.HandleFunc("/api/trigger/{id}", s.HandleTriggerStatus)
 	r.HandleFunc("/api/override/trigger_mode", s.HandleOverrideTriggerMode)
+	r.HandleFunc("/api/ready", s.HandleReady)
+	r.HandleFunc("/api/summary", s.HandleSummary)
+	r.HandleFunc("/api/build_stages", s.HandleBuildStages)
+	r.HandleFunc("/api/export", s.HandleExport)
+	r.HandleFunc("/api/analyze/triggers", s.HandleAnalyzeTriggers)
+	r.HandleFunc("/api/bookmarks", s.HandleLogBookmarks)
 	// this endpoint is only used for testing snapshots in development
 	r.HandleFunc("/api/snapshot/{snapshot_id}", s.SnapshotJSON)
 	r.HandleFunc("/api/websocket_token", s.WebsocketToken)
@@ -238,6 +258,9 @@ func (s *HeadsUpServer) HandleSetTiltfileArgs(w http.ResponseWriter, req *http.R
 	}
 }
 
+// Triggers a resource from the web UI or `tilt trigger`. External tools
+// set "resource" in the payload instead; see handleResourceTrigger.
+//
 // Responds with:
 // * 200/empty body on success
 // * 200/error message in body on well-formed, unservicable requests (e.g. resource is disabled or doesn't exist)
@@ -257,6 +280,11 @@ func (s *HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if payload.Resource != "" {
+		s.handleResourceTrigger(w, req, payload)
+		return
+	}
+
 	if len(payload.ManifestNames) != 1 {
 		http.Error(w, fmt.Sprintf("/api/trigger currently supports exactly one manifest name, got %d", len(payload.ManifestNames)), http.StatusBadRequest)
 		return
@@ -266,13 +294,15 @@ func (s *HeadsUpServer) HandleTrigger(w http.ResponseWriter, req *http.Request)
 
 	state := s.store.RLockState()
 	defer s.store.RUnlockState()
-	ms, ok := state.ManifestState(mn)
+	targets, ok := triggerTargets(state, mn)
 	if !ok {
 		http.Error(w, fmt.Sprintf("resource %q does not exist", mn), http.StatusNotFound)
-	} else if ms != nil && ms.DisableState == v1alpha1.DisableStateDisabled {
+	} else if len(targets) == 0 {
 		_, _ = fmt.Fprintf(w, "resource %q is currently disabled", mn)
 	} else {
-		s.store.Dispatch(store.AppendToTriggerQueueAction{Name: mn, Reason: payload.BuildReason})
+		for _, target := range targets {
+			s.store.Dispatch(store.AppendToTriggerQueueAction{Name: target, Reason: payload.BuildReason})
+		}
 	}
 }
 
@@ -309,6 +339,46 @@ func (s *HeadsUpServer) HandleOverrideTriggerMode(w http.ResponseWriter, req *ht
 	})
 }
 
+// Reports the files that triggered the most builds, across sessions.
+//
+// Query params:
+//   - sort: "builds" (the default) or "seconds"
+//   - limit: the max number of files to return (default 20, 0 for all)
+func (s *HeadsUpServer) HandleAnalyzeTriggers(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := req.URL.Query()
+	sortBy, err := triggerstats.ParseSortBy(query.Get("sort"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if l := query.Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			http.Error(w, fmt.Sprintf("invalid limit %q", l), http.StatusBadRequest)
+			return
+		}
+	}
+
+	stats, err := triggerstats.Load(s.base)
+	if err != nil && !statefile.IsCorrupt(err) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(triggerstats.NewReport(stats, sortBy, limit))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error rendering trigger stats: %v", err), http.StatusInternalServerError)
+	}
+}
+
 func (s *HeadsUpServer) WebsocketToken(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	_, _ = w.Write([]byte(websocketCSRFToken.String()))
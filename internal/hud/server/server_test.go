@@ -1,12 +1,18 @@
 package server_test
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -19,15 +25,21 @@ import (
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	tiltanalytics "github.com/tilt-dev/tilt/internal/analytics"
+	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/controllers/fake"
+	"github.com/tilt-dev/tilt/internal/engine/triggerstats"
 	"github.com/tilt-dev/tilt/internal/hud/server"
 	"github.com/tilt-dev/tilt/internal/hud/view"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/store"
 	"github.com/tilt-dev/tilt/internal/testutils"
+	"github.com/tilt-dev/tilt/internal/token"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/assets"
+	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/model/logstore"
 	"github.com/tilt-dev/wmclient/pkg/analytics"
 )
 
@@ -220,6 +232,257 @@ func TestHandleTriggerNonTiltfileManifest(t *testing.T) {
 	assert.Equal(t, "foobar", action.Name.String())
 }
 
+func TestHandleResourceTrigger(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api").withToken("secret")
+
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret",
+		`{"resource": "api", "reason": "file saved", "build_type": "auto"}`)
+	require.Equal(t, http.StatusAccepted, status, body)
+
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "api", resp["resource"])
+	assert.Equal(t, "queued", resp["status"])
+	require.NotEmpty(t, resp["id"])
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	assert.Equal(t, store.AppendToTriggerQueueAction{
+		Name:    "api",
+		Reason:  model.BuildReasonFlagTriggerAPI,
+		Request: model.TriggerRequest{ID: resp["id"], Resource: "api", Message: "file saved"},
+	}, a)
+}
+
+func TestHandleResourceTriggerFullBuild(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api").withToken("secret")
+
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret",
+		`{"resource": "api", "build_type": "full"}`)
+	require.Equal(t, http.StatusAccepted, status, body)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	reason := a.(store.AppendToTriggerQueueAction).Reason
+	assert.True(t, reason.Has(model.BuildReasonFlagFullBuild))
+	assert.True(t, reason.HasTrigger())
+}
+
+func TestHandleResourceTriggerInvalidBuildType(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api").withToken("secret")
+
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret",
+		`{"resource": "api", "build_type": "fast"}`)
+	require.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `invalid build_type \"fast\"`)
+}
+
+func TestHandleResourceTriggerUnauthorized(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api").withToken("secret")
+
+	for _, token := range []string{"", "wrong"} {
+		status, _ := f.makeTriggerReq(http.MethodPost, "/api/trigger", token, `{"resource": "api"}`)
+		assert.Equal(t, http.StatusUnauthorized, status, "token %q", token)
+	}
+}
+
+func TestHandleResourceTriggerUnknownResource(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("frontend", "backend", "db").withToken("secret")
+
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret", `{"resource": "fronted"}`)
+	require.Equal(t, http.StatusNotFound, status)
+
+	var resp struct {
+		Error       string   `json:"error"`
+		Suggestions []string `json:"suggestions"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, `resource "fronted" does not exist`, resp.Error)
+	require.NotEmpty(t, resp.Suggestions)
+	assert.Equal(t, "frontend", resp.Suggestions[0])
+}
+
+func TestHandleResourceTriggerRateLimit(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api", "web").withToken("secret")
+
+	for i := 0; i < 3; i++ {
+		status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret", `{"resource": "api"}`)
+		require.Equal(t, http.StatusAccepted, status, body)
+	}
+
+	rr := f.serveTriggerReq(http.MethodPost, "/api/trigger", "secret", `{"resource": "api"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+
+	// Each resource has its own limit.
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret", `{"resource": "web"}`)
+	assert.Equal(t, http.StatusAccepted, status, body)
+}
+
+func TestHandleTriggerStatus(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("api").withToken("secret")
+
+	start := time.Now()
+	state := f.st.LockMutableStateForTesting()
+	ms := state.ManifestTargets["api"].State
+	ms.PendingTriggers = []model.TriggerRequest{{ID: "queued-id"}}
+	ms.CurrentBuilds["buildcontrol"] = model.BuildRecord{
+		StartTime: start,
+		Triggers:  []model.TriggerRequest{{ID: "building-id"}},
+	}
+	ms.BuildHistory = []model.BuildRecord{
+		{StartTime: start, FinishTime: start, Error: fmt.Errorf("oh no"), Triggers: []model.TriggerRequest{{ID: "failed-id"}}},
+		{StartTime: start, FinishTime: start, Triggers: []model.TriggerRequest{{ID: "succeeded-id"}}},
+	}
+	f.st.UnlockMutableState()
+
+	for _, tc := range []struct {
+		id     string
+		status string
+	}{
+		{"queued-id", "queued"},
+		{"building-id", "building"},
+		{"failed-id", "failed"},
+		{"succeeded-id", "succeeded"},
+	} {
+		code, body := f.makeTriggerReq(http.MethodGet, "/api/trigger/"+tc.id, "secret", "")
+		require.Equal(t, http.StatusOK, code, body)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(body), &resp))
+		assert.Equal(t, tc.status, resp["status"], tc.id)
+		assert.Equal(t, "api", resp["resource"], tc.id)
+	}
+
+	code, _ := f.makeTriggerReq(http.MethodGet, "/api/trigger/unknown-id", "secret", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = f.makeTriggerReq(http.MethodGet, "/api/trigger/queued-id", "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+func TestHandleResourceTriggerGroup(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("auth-api", "auth-redis", "auth-worker").withToken("secret")
+	f.withResourceGroup("auth", "auth-api", "auth-redis", "auth-worker")
+
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["auth-redis"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	status, body := f.makeTriggerReq(http.MethodPost, "/api/trigger", "secret", `{"resource": "auth"}`)
+	require.Equal(t, http.StatusAccepted, status, body)
+
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "auth", resp["resource"])
+
+	// Disabled members aren't triggered.
+	var names []model.ManifestName
+	require.Eventually(t, func() bool {
+		names = nil
+		for _, a := range f.getActions() {
+			if a, ok := a.(store.AppendToTriggerQueueAction); ok {
+				assert.Equal(t, resp["id"], a.Request.ID)
+				assert.Equal(t, model.ManifestName("auth"), a.Request.Resource)
+				names = append(names, a.Name)
+			}
+		}
+		return len(names) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []model.ManifestName{"auth-api", "auth-worker"}, names)
+}
+
+func TestHandleTriggerStatusGroup(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("auth-api", "auth-redis").withToken("secret")
+	f.withResourceGroup("auth", "auth-api", "auth-redis")
+
+	start := time.Now()
+	request := model.TriggerRequest{ID: "group-id", Resource: "auth"}
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["auth-api"].State.BuildHistory = []model.BuildRecord{
+		{StartTime: start, FinishTime: start.Add(time.Second), Triggers: []model.TriggerRequest{request}},
+	}
+	state.ManifestTargets["auth-redis"].State.CurrentBuilds["buildcontrol"] = model.BuildRecord{
+		StartTime: start.Add(time.Second),
+		Triggers:  []model.TriggerRequest{request},
+	}
+	f.st.UnlockMutableState()
+
+	// The trigger isn't done until every member is done.
+	code, body := f.makeTriggerReq(http.MethodGet, "/api/trigger/group-id", "secret", "")
+	require.Equal(t, http.StatusOK, code, body)
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "building", resp["status"])
+	assert.Equal(t, "auth", resp["resource"])
+	assert.Nil(t, resp["finish_time"])
+
+	state = f.st.LockMutableStateForTesting()
+	ms := state.ManifestTargets["auth-redis"].State
+	delete(ms.CurrentBuilds, "buildcontrol")
+	ms.BuildHistory = []model.BuildRecord{
+		{StartTime: start.Add(time.Second), FinishTime: start.Add(2 * time.Second), Error: fmt.Errorf("oh no"), Triggers: []model.TriggerRequest{request}},
+	}
+	f.st.UnlockMutableState()
+
+	code, body = f.makeTriggerReq(http.MethodGet, "/api/trigger/group-id", "secret", "")
+	require.Equal(t, http.StatusOK, code, body)
+	resp = nil
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "failed", resp["status"])
+	assert.Equal(t, "auth-redis: oh no", resp["error"])
+}
+
+func TestHandleReset(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("foo", "bar")
+
+	status, body := f.makeReq("/api/reset", f.serv.HandleReset, http.MethodPost, `{"manifest_names": ["foo"]}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"manifest_names": ["foo"]}`, body)
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.AppendToTriggerQueueAction{}), f.getActions)
+	assert.Equal(t, store.AppendToTriggerQueueAction{
+		Name:   "foo",
+		Reason: model.BuildReasonFlagTriggerCLI.With(model.BuildReasonFlagReset),
+	}, a)
+}
+
+func TestHandleResetAll(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("foo", "bar", "baz")
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["bar"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	// Disabled resources aren't reset.
+	status, body := f.makeReq("/api/reset", f.serv.HandleReset, http.MethodPost, `{"all": true}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.JSONEq(t, `{"manifest_names": ["foo", "baz"]}`, body)
+}
+
+func TestHandleResetErrors(t *testing.T) {
+	f := newTestFixture(t).withDummyManifests("foo", "bar")
+	state := f.st.LockMutableStateForTesting()
+	state.ManifestTargets["bar"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	for _, tc := range []struct {
+		payload string
+		status  int
+		body    string
+	}{
+		{`{}`, http.StatusBadRequest, "must specify either manifest_names or all"},
+		{`{"all": true, "manifest_names": ["foo"]}`, http.StatusBadRequest, "must specify either manifest_names or all"},
+		{`{"manifest_names": ["nope"]}`, http.StatusNotFound, `resource "nope" does not exist`},
+		{`{"manifest_names": ["bar"]}`, http.StatusConflict, `resource "bar" is currently disabled`},
+		{`{"manifest_names": ["(Tiltfile)"]}`, http.StatusBadRequest, `resource "(Tiltfile)" is a Tiltfile, which can't be reset`},
+	} {
+		t.Run(tc.payload, func(t *testing.T) {
+			status, body := f.makeReq("/api/reset", f.serv.HandleReset, http.MethodPost, tc.payload)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.body, strings.TrimSpace(body))
+		})
+	}
+	assert.Empty(t, f.getActions())
+}
+
 func TestHandleOverrideTriggerModeReturnsErrorForBadManifest(t *testing.T) {
 	f := newTestFixture(t).withDummyManifests("foo", "baz")
 
@@ -312,6 +575,325 @@ func TestSetTiltfileArgs(t *testing.T) {
 	)
 }
 
+func TestHandleReady(t *testing.T) {
+	f := newTestFixture(t)
+	f.withUIResource("api", true, true, "backend")
+	f.withUIResource("web", false, true, "frontend")
+	f.withUIResource("off", false, false, "backend")
+	f.disableUIResource("off")
+
+	code, body := f.makeReq("/api/ready", f.serv.HandleReady, http.MethodGet, "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"ready": false, "resources": [
+  {"name": "api", "ready": true, "upToDate": true},
+  {"name": "off", "ready": false, "upToDate": false, "disabled": true},
+  {"name": "web", "ready": false, "upToDate": true, "reasons": ["RuntimePending"]}
+]}`, body)
+
+	code, body = f.makeReq("/api/ready?label=backend", f.serv.HandleReady, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"ready":true`)
+
+	code, _ = f.makeReq("/api/ready?resource=api", f.serv.HandleReady, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = f.makeReq("/api/ready?resource=api,web", f.serv.HandleReady, http.MethodGet, "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
+
+func TestHandleReadyMissingResource(t *testing.T) {
+	f := newTestFixture(t)
+	f.withUIResource("api", true, true)
+
+	code, body := f.makeReq("/api/ready?resource=api&resource=db", f.serv.HandleReady, http.MethodGet, "")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"ready": false, "resources": [
+  {"name": "api", "ready": true, "upToDate": true},
+  {"name": "db", "ready": false, "upToDate": false, "reasons": ["NotFound"]}
+]}`, body)
+}
+
+func TestHandleReadyNonGet(t *testing.T) {
+	f := newTestFixture(t)
+	code, _ := f.makeReq("/api/ready", f.serv.HandleReady, http.MethodPost, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestHandleSummary(t *testing.T) {
+	f := newTestFixture(t)
+
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := f.st.LockMutableStateForTesting()
+	state.TiltfileStates[model.MainTiltfileManifestName].AddCompletedBuild(model.BuildRecord{StartTime: t0, FinishTime: t0})
+	for _, name := range []string{"ok", "building", "error", "pending", "off"} {
+		m := model.Manifest{Name: model.ManifestName(name), TriggerMode: model.TriggerModeAuto}
+		mt := store.NewManifestTarget(m)
+		mt.State.DisableState = v1alpha1.DisableStateEnabled
+		state.UpsertManifestTarget(mt)
+	}
+	state.ManifestTargets["ok"].State.AddCompletedBuild(model.BuildRecord{StartTime: t0, FinishTime: t0.Add(time.Second)})
+	state.ManifestTargets["building"].State.CurrentBuilds["buildcontrol"] = model.BuildRecord{StartTime: t0}
+	state.ManifestTargets["error"].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  t0,
+		FinishTime: t0.Add(2 * time.Second),
+		Error:      fmt.Errorf("compile error"),
+	})
+	state.ManifestTargets["off"].State.DisableState = v1alpha1.DisableStateDisabled
+	f.st.UnlockMutableState()
+
+	code, body := f.makeReq("/api/summary", f.serv.HandleSummary, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+  "ok": 2, "building": 1, "pending": 1, "error": 1, "disabled": 1,
+  "state": "error",
+  "last_update_time": "2023-01-01T12:00:02Z"
+}`, body)
+}
+
+func TestHandleSummaryBeforeTiltfileLoads(t *testing.T) {
+	f := newTestFixture(t)
+
+	code, body := f.makeReq("/api/summary", f.serv.HandleSummary, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"ok": 0, "building": 0, "pending": 1, "error": 0, "disabled": 0, "state": "pending"}`, body)
+}
+
+func TestHandleSummaryNonGet(t *testing.T) {
+	f := newTestFixture(t)
+	code, _ := f.makeReq("/api/summary", f.serv.HandleSummary, http.MethodPost, "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
+
+func TestHandleBuildStages(t *testing.T) {
+	f := newTestFixture(t)
+
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/fe"))
+	iTarget.DockerImageName = "fe:gcr.io_fe"
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(model.Manifest{Name: "fe"}.WithImageTarget(iTarget)))
+	f.st.UnlockMutableState()
+
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	micro := func(d time.Duration) *metav1.MicroTime {
+		t := metav1.NewMicroTime(t0.Add(d))
+		return &t
+	}
+	di := &v1alpha1.DockerImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe:gcr.io_fe"},
+		Spec:       v1alpha1.DockerImageSpec{Ref: "gcr.io/fe"},
+	}
+	require.NoError(t, f.ctrlClient.Create(f.ctx, di))
+	di.Status.StageStatuses = []v1alpha1.DockerImageStageStatus{
+		{Name: "[builder 1/2] FROM golang", Cached: true, StartedAt: micro(0), FinishedAt: micro(0)},
+		{Name: "[builder 2/2] RUN go build", StartedAt: micro(0), FinishedAt: micro(3 * time.Second)},
+		{Name: "[stage-1 2/2] COPY --from=builder /app /app", StartedAt: micro(3 * time.Second), FinishedAt: micro(4 * time.Second),
+			Error: "copy failed"},
+	}
+	require.NoError(t, f.ctrlClient.Status().Update(f.ctx, di))
+
+	code, body := f.makeReq("/api/build_stages?resource=fe", f.serv.HandleBuildStages, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+
+	var result []server.ImageBuildStages
+	require.NoError(t, json.Unmarshal([]byte(body), &result))
+	require.Len(t, result, 1)
+	assert.Equal(t, "gcr.io/fe", result[0].Image)
+	assert.Equal(t, "fe:gcr.io_fe", result[0].DockerImage)
+
+	stages := result[0].Stages
+	require.Len(t, stages, 2)
+	assert.Equal(t, "builder", stages[0].Name)
+	assert.Equal(t, int64(3000), stages[0].DurationMs)
+	assert.False(t, stages[0].Cached)
+	assert.Len(t, stages[0].Steps, 2)
+	assert.Equal(t, "stage-1", stages[1].Name)
+	assert.Equal(t, "copy failed", stages[1].Error)
+}
+
+func TestHandleBuildStagesNotFound(t *testing.T) {
+	f := newTestFixture(t)
+
+	code, _ := f.makeReq("/api/build_stages?resource=fe", f.serv.HandleBuildStages, http.MethodGet, "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = f.makeReq("/api/build_stages", f.serv.HandleBuildStages, http.MethodGet, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandleExport(t *testing.T) {
+	f := newTestFixture(t)
+
+	contextDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(contextDir, "main.go"), []byte("package main\n"), 0644))
+
+	iTarget := model.MustNewImageTarget(container.MustParseSelector("gcr.io/fe")).
+		WithLiveUpdateSpec("fe:gcr.io_fe", v1alpha1.LiveUpdateSpec{
+			BasePath: contextDir,
+			Syncs:    []v1alpha1.LiveUpdateSync{{LocalPath: ".", ContainerPath: "/app"}},
+		})
+	iTarget.DockerImageName = "fe:gcr.io_fe"
+	kTarget := model.NewK8sTargetForTesting("kind: Deployment\nimage: gcr.io/fe\n")
+	kTarget.Name = "fe"
+	m := model.Manifest{Name: "fe"}.WithImageTarget(iTarget).WithDeployTarget(kTarget)
+
+	state := f.st.LockMutableStateForTesting()
+	state.UpsertManifestTarget(store.NewManifestTarget(m))
+	state.Secrets.AddSecret("creds", "token", []byte("hunter2"))
+	state.LogStore.Append(store.NewLogAction("fe", "build:1", logger.InfoLvl, nil, []byte("logging in with hunter2\n")), nil)
+	ms, _ := state.ManifestState("fe")
+	ms.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC),
+		FinishTime: time.Date(2023, 1, 1, 12, 0, 5, 0, time.UTC),
+		Reason:     model.BuildReasonFlagChangedFiles,
+		BuildTypes: []model.BuildType{model.BuildTypeImage, model.BuildTypeK8s},
+		SpanID:     "build:1",
+	})
+	f.st.UnlockMutableState()
+
+	require.NoError(t, f.ctrlClient.Create(f.ctx, &v1alpha1.DockerImage{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe:gcr.io_fe"},
+		Spec: v1alpha1.DockerImageSpec{
+			Ref:                "gcr.io/fe",
+			DockerfileContents: "FROM gcr.io/base\nCOPY . /app\n",
+			Context:            contextDir,
+			Args:               []string{"TOKEN=hunter2"},
+			ImageMaps:          []string{"gcr.io_base"},
+		},
+	}))
+	im := &v1alpha1.ImageMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "gcr.io_base"},
+		Spec:       v1alpha1.ImageMapSpec{Selector: "gcr.io/base"},
+	}
+	require.NoError(t, f.ctrlClient.Create(f.ctx, im))
+	im.Status.ImageFromLocal = "gcr.io/base:tilt-123"
+	require.NoError(t, f.ctrlClient.Status().Update(f.ctx, im))
+
+	ka := &v1alpha1.KubernetesApply{
+		ObjectMeta: metav1.ObjectMeta{Name: "fe"},
+		Spec:       kTarget.KubernetesApplySpec,
+	}
+	require.NoError(t, f.ctrlClient.Create(f.ctx, ka))
+	ka.Status.ResultYAML = "kind: Deployment\nimage: gcr.io/fe:tilt-456\n"
+	require.NoError(t, f.ctrlClient.Status().Update(f.ctx, ka))
+
+	code, body := f.makeReq("/api/export?resource=fe", f.serv.HandleExport, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code, body)
+
+	files := readBundle(t, body)
+	var manifest server.ExportManifest
+	require.NoError(t, json.Unmarshal([]byte(files["manifest.json"]), &manifest))
+	assert.Equal(t, server.ExportSchemaVersion, manifest.SchemaVersion)
+	assert.Equal(t, "fe", manifest.Resource)
+	assert.Empty(t, manifest.Notes)
+	for _, file := range manifest.Files {
+		assert.Contains(t, files, file.Path)
+	}
+
+	assert.Equal(t, "FROM gcr.io/base:tilt-123\nCOPY . /app\n", files["images/gcr.io_fe/Dockerfile"])
+	assert.Contains(t, files["images/gcr.io_fe/docker_build.json"], `"TOKEN=[redacted secret creds:token]"`)
+	assert.Contains(t, files["images/gcr.io_fe/live_update.json"], `"containerPath": "/app"`)
+	assert.Equal(t, "kind: Deployment\nimage: gcr.io/fe:tilt-456\n", files["kubernetes.yaml"])
+
+	var contextFiles []server.ExportContextFile
+	require.NoError(t, json.Unmarshal([]byte(files["images/gcr.io_fe/context.json"]), &contextFiles))
+	assert.Contains(t, contextFiles, server.ExportContextFile{
+		Path:   "main.go",
+		Size:   13,
+		SHA256: fmt.Sprintf("%x", sha256.Sum256([]byte("package main\n"))),
+	})
+
+	var builds []server.ExportBuild
+	require.NoError(t, json.Unmarshal([]byte(files["builds.json"]), &builds))
+	require.Len(t, builds, 1)
+	assert.Equal(t, []string{"image", "k8s"}, builds[0].BuildTypes)
+	assert.Equal(t, "builds/0.log", builds[0].Log)
+	assert.Contains(t, files["builds/0.log"], "logging in with [redacted secret creds:token]")
+
+	for name, contents := range files {
+		assert.NotContains(t, contents, "hunter2", name)
+	}
+}
+
+func TestHandleExportNotFound(t *testing.T) {
+	f := newTestFixture(t)
+
+	code, _ := f.makeReq("/api/export?resource=fe", f.serv.HandleExport, http.MethodGet, "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = f.makeReq("/api/export", f.serv.HandleExport, http.MethodGet, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+// Reads the files in a gzipped tar into a map from path to contents.
+func readBundle(t *testing.T, body string) map[string]string {
+	gr, err := gzip.NewReader(strings.NewReader(body))
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	files := make(map[string]string)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		require.NoError(t, err)
+		contents, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[h.Name] = string(contents)
+	}
+}
+
+func TestHandleAnalyzeTriggers(t *testing.T) {
+	f := newTestFixture(t)
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	err := triggerstats.Record(f.base, []triggerstats.Build{
+		{Files: []string{"/src/api.proto"}, StartTime: t0, FinishTime: t0.Add(90 * time.Second)},
+		{Files: []string{"/src/main.go"}, StartTime: t0, FinishTime: t0.Add(5 * time.Second)},
+		{Files: []string{"/src/main.go"}, StartTime: t0, FinishTime: t0.Add(5 * time.Second)},
+	})
+	require.NoError(t, err)
+
+	code, body := f.makeReq("/api/analyze/triggers?sort=seconds&limit=1", f.serv.HandleAnalyzeTriggers, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+  "since": "2023-01-01T12:00:00Z",
+  "sortBy": "seconds",
+  "files": [{"path": "/src/api.proto", "builds": 1, "buildSeconds": 90, "lastBuild": "2023-01-01T12:01:30Z"}]
+}`, body)
+
+	code, _ = f.makeReq("/api/analyze/triggers?sort=size", f.serv.HandleAnalyzeTriggers, http.MethodGet, "")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestHandleLogBookmarks(t *testing.T) {
+	f := newTestFixture(t)
+
+	t0 := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := f.st.LockMutableStateForTesting()
+	state.LogStore.Append(store.NewLogAction("api", "", logger.InfoLvl, nil, []byte("starting\n")), nil)
+	state.LogBookmarks = []logstore.Bookmark{{Name: "deploy", Time: t0, Checkpoint: 0}}
+	f.st.UnlockMutableState()
+
+	code, body := f.makeReq("/api/bookmarks", f.serv.HandleLogBookmarks, http.MethodPost, `{"name": "repro happened here"}`)
+	require.Equal(t, http.StatusCreated, code, body)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &resp))
+	assert.Equal(t, "repro happened here", resp["name"])
+	assert.Equal(t, float64(1), resp["checkpoint"])
+	assert.Equal(t, "/r/(all)/overview?at=1", resp["url"])
+
+	a := store.WaitForAction(t, reflect.TypeOf(store.LogBookmarkAction{}), f.getActions)
+	assert.Equal(t, "repro happened here", a.(store.LogBookmarkAction).Bookmark.Name)
+
+	code, body = f.makeReq("/api/bookmarks", f.serv.HandleLogBookmarks, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `[{"name": "deploy", "time": "2023-01-01T12:00:00Z", "checkpoint": 0, "url": "/r/(all)/overview?at=0"}]`, body)
+
+	code, _ = f.makeReq("/api/bookmarks", f.serv.HandleLogBookmarks, http.MethodPost, `{"name":`)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
 type serverFixture struct {
 	t            *testing.T
 	ctx          context.Context
@@ -322,6 +904,7 @@ type serverFixture struct {
 	ctrlClient   ctrlclient.Client
 	getActions   func() []store.Action
 	snapshotHTTP *fakeHTTPClient
+	base         xdg.Base
 }
 
 func newTestFixture(t *testing.T) *serverFixture {
@@ -340,8 +923,9 @@ func newTestFixture(t *testing.T) *serverFixture {
 	})
 
 	ctx := context.Background()
+	base := xdg.FakeBase{Dir: t.TempDir()}
 
-	serv, err := server.ProvideHeadsUpServer(ctx, st, assets.NewFakeServer(), ta, wsl, ctrlClient)
+	serv, err := server.ProvideHeadsUpServer(ctx, st, assets.NewFakeServer(), ta, wsl, ctrlClient, base)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -356,6 +940,7 @@ func newTestFixture(t *testing.T) *serverFixture {
 		ctrlClient:   ctrlClient,
 		getActions:   getActions,
 		snapshotHTTP: snapshotHTTP,
+		base:         base,
 	}
 }
 
@@ -377,6 +962,42 @@ func (f *serverFixture) makeReq(endpoint string, handler http.HandlerFunc,
 	return rr.Code, rr.Body.String()
 }
 
+// Sends a request through the router, authenticated with the given token.
+func (f *serverFixture) serveTriggerReq(method, path, token, body string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, path, strings.NewReader(body))
+	require.NoError(f.t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rr := httptest.NewRecorder()
+	f.serv.Router().ServeHTTP(rr, req)
+	return rr
+}
+
+func (f *serverFixture) makeTriggerReq(method, path, token, body string) (statusCode int, respBody string) {
+	rr := f.serveTriggerReq(method, path, token, body)
+	return rr.Code, rr.Body.String()
+}
+
+func (f *serverFixture) withToken(t token.Token) *serverFixture {
+	state := f.st.LockMutableStateForTesting()
+	state.Token = t
+	f.st.UnlockMutableState()
+	return f
+}
+
+func (f *serverFixture) withResourceGroup(name string, members ...string) {
+	g := model.ResourceGroup{Name: model.ManifestName(name)}
+	for _, m := range members {
+		g.Members = append(g.Members, model.ManifestName(m))
+	}
+	state := f.st.LockMutableStateForTesting()
+	state.ResourceGroups[g.Name] = g
+	f.st.UnlockMutableState()
+}
+
 func (f *serverFixture) withDummyManifests(mNames ...string) *serverFixture {
 	state := f.st.LockMutableStateForTesting()
 	for _, mName := range mNames {
@@ -388,6 +1009,41 @@ func (f *serverFixture) withDummyManifests(mNames ...string) *serverFixture {
 	return f
 }
 
+func (f *serverFixture) withUIResource(name string, ready, upToDate bool, labels ...string) {
+	status := func(ok bool) metav1.ConditionStatus {
+		if ok {
+			return metav1.ConditionTrue
+		}
+		return metav1.ConditionFalse
+	}
+	r := &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+		Status: v1alpha1.UIResourceStatus{
+			Conditions: []v1alpha1.UIResourceCondition{
+				{Type: v1alpha1.UIResourceUpToDate, Status: status(upToDate)},
+				{Type: v1alpha1.UIResourceReady, Status: status(ready)},
+			},
+		},
+	}
+	if !upToDate {
+		r.Status.Conditions[0].Reason = "UpdatePending"
+	}
+	if !ready {
+		r.Status.Conditions[1].Reason = "RuntimePending"
+	}
+	for _, l := range labels {
+		r.Labels[l] = l
+	}
+	require.NoError(f.t, f.ctrlClient.Create(f.ctx, r))
+}
+
+func (f *serverFixture) disableUIResource(name string) {
+	var r v1alpha1.UIResource
+	require.NoError(f.t, f.ctrlClient.Get(f.ctx, types.NamespacedName{Name: name}, &r))
+	r.Status.DisableStatus.State = v1alpha1.DisableStateDisabled
+	require.NoError(f.t, f.ctrlClient.Status().Update(f.ctx, &r))
+}
+
 type fakeHTTPClient struct {
 	lastReq *http.Request
 }
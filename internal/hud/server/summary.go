@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The overall state in a Summary, from most to least urgent.
+const (
+	SummaryStateError    = "error"
+	SummaryStateBuilding = "building"
+	SummaryStatePending  = "pending"
+	SummaryStateOK       = "ok"
+)
+
+// Summary counts the resources in each state, for shell prompts and status
+// bars that poll often and only need the headline.
+type Summary struct {
+	OK       int `json:"ok"`
+	Building int `json:"building"`
+	Pending  int `json:"pending"`
+	Error    int `json:"error"`
+	Disabled int `json:"disabled"`
+
+	// The most urgent state of any enabled resource.
+	State string `json:"state"`
+
+	// When the most recent update finished. Omitted if nothing has finished
+	// updating yet.
+	LastUpdateTime *time.Time `json:"last_update_time,omitempty"`
+}
+
+// Reports how many resources are in each state.
+//
+// Served from the engine's in-memory state, without talking to the API server
+// or the cluster, so that it's cheap enough to call on every shell prompt.
+func (s *HeadsUpServer) HandleSummary(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := s.store.RLockState()
+	summary := summarize(state)
+	s.store.RUnlockState()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(summary)
+}
+
+func summarize(state store.EngineState) Summary {
+	var summary Summary
+	var lastUpdate time.Time
+	add := func(ms *store.ManifestState, triggerMode model.TriggerMode) {
+		if finish := ms.LastBuild().FinishTime; finish.After(lastUpdate) {
+			lastUpdate = finish
+		}
+
+		if ms.DisableState == v1alpha1.DisableStateDisabled {
+			summary.Disabled++
+			return
+		}
+
+		updateStatus := ms.UpdateStatus(triggerMode)
+		runtimeStatus := ms.RuntimeStatus(triggerMode)
+		switch {
+		case updateStatus == v1alpha1.UpdateStatusInProgress:
+			summary.Building++
+		case updateStatus == v1alpha1.UpdateStatusError || runtimeStatus == v1alpha1.RuntimeStatusError:
+			summary.Error++
+		case updateStatus == v1alpha1.UpdateStatusPending || runtimeStatus == v1alpha1.RuntimeStatusPending:
+			summary.Pending++
+		default:
+			summary.OK++
+		}
+	}
+
+	for _, ms := range state.GetTiltfileStates() {
+		add(ms, model.TriggerModeAuto)
+	}
+	for _, mt := range state.Targets() {
+		add(mt.State, mt.Manifest.TriggerMode)
+	}
+
+	switch {
+	case summary.Error > 0:
+		summary.State = SummaryStateError
+	case summary.Building > 0:
+		summary.State = SummaryStateBuilding
+	case summary.Pending > 0:
+		summary.State = SummaryStatePending
+	default:
+		summary.State = SummaryStateOK
+	}
+
+	if !lastUpdate.IsZero() {
+		summary.LastUpdateTime = &lastUpdate
+	}
+	return summary
+}
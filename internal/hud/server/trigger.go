@@ -0,0 +1,362 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/schollz/closestmatch"
+	"golang.org/x/time/rate"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	triggerBuildTypeAuto = "auto"
+	triggerBuildTypeFull = "full"
+)
+
+const (
+	triggerStatusQueued    = "queued"
+	triggerStatusBuilding  = "building"
+	triggerStatusSucceeded = "succeeded"
+	triggerStatusFailed    = "failed"
+)
+
+// Editors may trigger on every save. Each resource allows a short burst of
+// triggers, then one per second. Extra triggers are rejected rather than
+// queued, because the next build picks up every change anyway.
+const (
+	triggerRateLimit = rate.Limit(1)
+	triggerRateBurst = 3
+)
+
+type triggerResponse struct {
+	ID         string     `json:"id"`
+	Resource   string     `json:"resource"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartTime  *time.Time `json:"start_time,omitempty"`
+	FinishTime *time.Time `json:"finish_time,omitempty"`
+}
+
+type triggerErrorResponse struct {
+	Error       string   `json:"error"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Rate limits for /api/trigger, by resource.
+type triggerLimiters struct {
+	mu       sync.Mutex
+	limiters map[model.ManifestName]*rate.Limiter
+}
+
+// reserve returns how long the caller has to wait before they can trigger
+// the resource again, or 0 if they can trigger it now.
+func (l *triggerLimiters) reserve(mn model.ManifestName) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limiters == nil {
+		l.limiters = make(map[model.ManifestName]*rate.Limiter)
+	}
+	limiter, ok := l.limiters[mn]
+	if !ok {
+		limiter = rate.NewLimiter(triggerRateLimit, triggerRateBurst)
+		l.limiters[mn] = limiter
+	}
+
+	r := limiter.Reserve()
+	delay := r.Delay()
+	if delay > 0 {
+		r.Cancel()
+	}
+	return delay
+}
+
+// Triggers a resource for an external tool, like an editor plugin or a git
+// hook. The payload is:
+//
+//	{"resource": "api", "reason": "file saved", "build_type": "auto"}
+//
+// build_type is "auto" (the default), which builds the same way as the
+// trigger button, or "full", which skips live update and rebuilds images.
+// The reason is logged with the build, and stored in its build record.
+//
+// Callers authenticate with the Tilt token (in ~/.tilt-dev/token):
+//
+//	Authorization: Bearer <token>
+//
+// Responds 202 with the trigger ID, which can be polled with
+// GET /api/trigger/{id}. Responds 404 with the closest resource names if
+// the resource doesn't exist, 409 if it's disabled, and 429 (with
+// Retry-After) if it's been triggered too often.
+func (s *HeadsUpServer) handleResourceTrigger(w http.ResponseWriter, req *http.Request, payload triggerPayload) {
+	if !s.checkBearerToken(req) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeTriggerError(w, http.StatusUnauthorized, triggerErrorResponse{Error: "missing or invalid bearer token"})
+		return
+	}
+
+	reason := model.BuildReasonFlagTriggerAPI
+	switch payload.BuildType {
+	case "", triggerBuildTypeAuto:
+	case triggerBuildTypeFull:
+		reason = reason.With(model.BuildReasonFlagFullBuild)
+	default:
+		writeTriggerError(w, http.StatusBadRequest, triggerErrorResponse{
+			Error: fmt.Sprintf("invalid build_type %q: must be %q or %q", payload.BuildType, triggerBuildTypeAuto, triggerBuildTypeFull),
+		})
+		return
+	}
+
+	mn := model.ManifestName(payload.Resource)
+
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+	targets, ok := triggerTargets(state, mn)
+	if !ok {
+		writeTriggerError(w, http.StatusNotFound, triggerErrorResponse{
+			Error:       fmt.Sprintf("resource %q does not exist", mn),
+			Suggestions: closestResourceNames(state, mn),
+		})
+		return
+	}
+	if len(targets) == 0 {
+		writeTriggerError(w, http.StatusConflict, triggerErrorResponse{
+			Error: fmt.Sprintf("resource %q is currently disabled", mn),
+		})
+		return
+	}
+
+	if delay := s.triggerLimiters.reserve(mn); delay > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+		writeTriggerError(w, http.StatusTooManyRequests, triggerErrorResponse{
+			Error: fmt.Sprintf("resource %q was triggered too often; try again in %s", mn, delay.Round(time.Millisecond)),
+		})
+		return
+	}
+
+	request := model.TriggerRequest{
+		ID:       uuid.New().String(),
+		Resource: mn,
+		Message:  strings.TrimSpace(payload.Reason),
+	}
+	for _, target := range targets {
+		s.store.Dispatch(store.AppendToTriggerQueueAction{Name: target, Reason: reason, Request: request})
+	}
+
+	w.Header().Set("Location", "/api/trigger/"+request.ID)
+	writeTriggerResponse(w, http.StatusAccepted, triggerResponse{
+		ID:       request.ID,
+		Resource: mn.String(),
+		Status:   triggerStatusQueued,
+	})
+}
+
+// Reports the status of a build requested with POST /api/trigger.
+//
+// Tilt only keeps the last few builds of each resource, so poll until
+// the status is "succeeded" or "failed". A trigger that's been dropped
+// (e.g., because its resource was disabled or removed) is a 404.
+func (s *HeadsUpServer) HandleTriggerStatus(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "must be GET request", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.checkBearerToken(req) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeTriggerError(w, http.StatusUnauthorized, triggerErrorResponse{Error: "missing or invalid bearer token"})
+		return
+	}
+
+	id := mux.Vars(req)["id"]
+
+	state := s.store.RLockState()
+	defer s.store.RUnlockState()
+
+	// A trigger of a resource group builds each member, so it's done when
+	// they're all done.
+	var resp triggerResponse
+	found := false
+	states := append(state.GetTiltfileStates(), state.ManifestStates()...)
+	for _, ms := range states {
+		memberResp, ok := triggerStatus(ms, id)
+		if !ok {
+			continue
+		}
+		if !found {
+			resp = memberResp
+			found = true
+			continue
+		}
+		resp = mergeTriggerStatus(resp, memberResp)
+	}
+	if !found {
+		writeTriggerError(w, http.StatusNotFound, triggerErrorResponse{Error: fmt.Sprintf("trigger %q not found", id)})
+		return
+	}
+	if resp.Status == triggerStatusQueued || resp.Status == triggerStatusBuilding {
+		resp.FinishTime = nil
+	}
+	writeTriggerResponse(w, http.StatusOK, resp)
+}
+
+func triggerStatus(ms *store.ManifestState, id string) (triggerResponse, bool) {
+	resp := triggerResponse{ID: id}
+	resource := func(t model.TriggerRequest) string {
+		if t.Resource == "" {
+			return ms.Name.String()
+		}
+		return t.Resource.String()
+	}
+
+	for _, t := range ms.PendingTriggers {
+		if t.ID == id {
+			resp.Resource = resource(t)
+			resp.Status = triggerStatusQueued
+			return resp, true
+		}
+	}
+
+	for _, b := range ms.CurrentBuilds {
+		if t, ok := b.Trigger(id); ok {
+			resp.Resource = resource(t)
+			resp.Status = triggerStatusBuilding
+			resp.StartTime = &b.StartTime
+			return resp, true
+		}
+	}
+
+	for _, b := range ms.BuildHistory {
+		t, ok := b.Trigger(id)
+		if !ok {
+			continue
+		}
+		resp.Resource = resource(t)
+		resp.Status = triggerStatusSucceeded
+		if b.Error != nil {
+			resp.Status = triggerStatusFailed
+			resp.Error = b.Error.Error()
+			if resp.Resource != ms.Name.String() {
+				resp.Error = fmt.Sprintf("%s: %v", ms.Name, b.Error)
+			}
+		}
+		resp.StartTime = &b.StartTime
+		resp.FinishTime = &b.FinishTime
+		return resp, true
+	}
+	return triggerResponse{}, false
+}
+
+// How far along each status is. A group trigger has the status of the
+// member that's furthest behind.
+var triggerStatusProgress = map[string]int{
+	triggerStatusQueued:    0,
+	triggerStatusBuilding:  1,
+	triggerStatusFailed:    2,
+	triggerStatusSucceeded: 3,
+}
+
+func mergeTriggerStatus(a, b triggerResponse) triggerResponse {
+	result := a
+	if triggerStatusProgress[b.Status] < triggerStatusProgress[a.Status] {
+		result.Status = b.Status
+	}
+	if result.Error == "" {
+		result.Error = b.Error
+	}
+	if b.StartTime != nil && (a.StartTime == nil || b.StartTime.Before(*a.StartTime)) {
+		result.StartTime = b.StartTime
+	}
+	if b.FinishTime != nil && (a.FinishTime == nil || b.FinishTime.After(*a.FinishTime)) {
+		result.FinishTime = b.FinishTime
+	}
+	return result
+}
+
+// The manifests to build when a resource is triggered. Triggering a resource
+// group triggers each of its enabled members.
+//
+// Returns false if there's no resource with that name.
+func triggerTargets(state store.EngineState, mn model.ManifestName) ([]model.ManifestName, bool) {
+	if members := state.ResourceGroupMembers(mn); len(members) > 0 {
+		var result []model.ManifestName
+		for _, member := range members {
+			ms, ok := state.ManifestState(member)
+			if ok && (ms == nil || ms.DisableState != v1alpha1.DisableStateDisabled) {
+				result = append(result, member)
+			}
+		}
+		return result, true
+	}
+
+	ms, ok := state.ManifestState(mn)
+	if !ok {
+		return nil, false
+	}
+	if ms != nil && ms.DisableState == v1alpha1.DisableStateDisabled {
+		return nil, true
+	}
+	return []model.ManifestName{mn}, true
+}
+
+func (s *HeadsUpServer) checkBearerToken(req *http.Request) bool {
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+
+	state := s.store.RLockState()
+	expected := string(state.Token)
+	s.store.RUnlockState()
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+func closestResourceNames(state store.EngineState, mn model.ManifestName) []string {
+	var names []string
+	for _, ms := range state.GetTiltfileStates() {
+		names = append(names, ms.Name.String())
+	}
+	for _, ms := range state.ManifestStates() {
+		names = append(names, ms.Name.String())
+	}
+	for _, g := range state.SortedResourceGroups() {
+		names = append(names, g.Name.String())
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	var result []string
+	cm := closestmatch.New(names, []int{2, 3, 4})
+	for _, match := range cm.ClosestN(mn.String(), 3) {
+		// closestmatch sometimes returns an empty string when nothing matches.
+		if match == "" {
+			break
+		}
+		result = append(result, match)
+	}
+	return result
+}
+
+func writeTriggerResponse(w http.ResponseWriter, status int, resp triggerResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func writeTriggerError(w http.ResponseWriter, status int, resp triggerErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
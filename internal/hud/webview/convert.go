@@ -2,6 +2,7 @@ package webview
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strings"
@@ -31,6 +32,16 @@ import (
 // with the other Session API.
 const UISessionName = "Tiltfile"
 
+// AnnotationLogBookmarks holds the log bookmarks on the UISession, as a JSON
+// list of logstore.Bookmark.
+const AnnotationLogBookmarks = "tilt.dev/log-bookmarks"
+
+// LogBookmarkPath is the path in the web UI that shows the logs of all
+// resources, scrolled to the bookmark.
+func LogBookmarkPath(b logstore.Bookmark) string {
+	return fmt.Sprintf("/r/(all)/overview?at=%d", b.Checkpoint)
+}
+
 // Create the complete snapshot of the webview.
 func CompleteView(ctx context.Context, client ctrlclient.Client, st store.RStore) (*proto_webview.View, error) {
 	ret := &proto_webview.View{}
@@ -182,6 +193,13 @@ func ToUISession(s store.EngineState) *v1alpha1.UISession {
 
 	status.TiltfileKey = s.MainTiltfilePath()
 
+	if len(s.LogBookmarks) > 0 {
+		bookmarks, err := json.Marshal(s.LogBookmarks)
+		if err == nil {
+			ret.Annotations = map[string]string{AnnotationLogBookmarks: string(bookmarks)}
+		}
+	}
+
 	return ret
 }
 
@@ -236,6 +254,22 @@ func ToUIResourceList(state store.EngineState, disableSources map[string][]v1alp
 		ret = append(ret, r)
 	}
 
+	if len(state.ResourceGroups) > 0 {
+		byName := make(map[string]*v1alpha1.UIResource, len(ret))
+		for _, r := range ret {
+			byName[r.Name] = r
+		}
+		for _, g := range state.SortedResourceGroups() {
+			r, err := toGroupUIResource(g, byName, state, disableSources)
+			if err != nil {
+				return nil, err
+			}
+
+			r.Status.Order = int32(len(ret) + 1)
+			ret = append(ret, r)
+		}
+	}
+
 	return ret, nil
 }
 
@@ -305,9 +339,51 @@ func toUIResource(mt *store.ManifestTarget, s store.EngineState, disableSources
 		UIResourceUpToDateCondition(r.Status),
 		UIResourceReadyCondition(r.Status),
 	}
+	if c, ok := uiResourceDegradedCondition(mn, s); ok {
+		r.Status.Conditions = append(r.Status.Conditions, c)
+	}
 	return r, nil
 }
 
+// The "Degraded" condition reports failures of the local resources that
+// run on each update of this resource with degrade_on_failure (e.g., its tests).
+//
+// Returns false if no such local resources exist.
+func uiResourceDegradedCondition(mn model.ManifestName, s store.EngineState) (v1alpha1.UIResourceCondition, bool) {
+	c := v1alpha1.UIResourceCondition{
+		Type:               v1alpha1.UIResourceDegraded,
+		Status:             metav1.ConditionFalse,
+		LastTransitionTime: apis.NowMicro(),
+	}
+
+	found := false
+	var failed []string
+	for _, mt := range s.TargetsBesides(mn) {
+		if !mt.Manifest.IsLocal() {
+			continue
+		}
+		lt := mt.Manifest.LocalTarget()
+		if !lt.DegradeOnFailure || !lt.RunsOnUpdateOf(mn) {
+			continue
+		}
+		found = true
+		if mt.State.LastBuild().Error != nil {
+			failed = append(failed, mt.Manifest.Name.String())
+		}
+	}
+	if !found {
+		return v1alpha1.UIResourceCondition{}, false
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		c.Status = metav1.ConditionTrue
+		c.Reason = "DependentFailed"
+		c.Message = fmt.Sprintf("Failed: %s", strings.Join(failed, ", "))
+	}
+	return c, true
+}
+
 // The "Ready" condition is a cross-resource status report that's synthesized
 // from the more type-specific fields of UIResource.
 func UIResourceReadyCondition(r v1alpha1.UIResourceStatus) v1alpha1.UIResourceCondition {
@@ -441,6 +517,9 @@ func populateResourceInfoView(mt *store.ManifestTarget, r *v1alpha1.UIResource)
 			PodRestarts:        kState.VisiblePodContainerRestarts(podID),
 			DisplayNames:       kState.EntityDisplayNames(),
 		}
+		for _, c := range k8sconv.LongRunningContainers(pod) {
+			rK8s.Containers = append(rK8s.Containers, *c.DeepCopy())
+		}
 		if podID != "" {
 			rK8s.SpanID = string(k8sconv.SpanIDForPod(mt.Manifest.Name, podID))
 		}
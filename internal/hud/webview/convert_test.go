@@ -1,6 +1,7 @@
 package webview
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -179,6 +180,40 @@ func TestStateToViewK8sTargetsIncludeDisplayNames(t *testing.T) {
 	assert.Equal(t, []string{"foo:namespace", "foo:secret"}, r.K8sResourceInfo.DisplayNames)
 }
 
+func TestStateToViewK8sContainers(t *testing.T) {
+	m := model.Manifest{Name: "foo"}.WithDeployTarget(model.K8sTarget{})
+	state := newState([]model.Manifest{m})
+	running := v1alpha1.ContainerState{Running: &v1alpha1.ContainerStateRunning{}}
+	state.ManifestTargets[m.Name].State.RuntimeState = store.NewK8sRuntimeStateWithPods(m, v1alpha1.Pod{
+		Name:   "pod-id",
+		Status: "Running",
+		Phase:  "Running",
+		InitContainers: []v1alpha1.Container{
+			{Name: "migrate", State: v1alpha1.ContainerState{Terminated: &v1alpha1.ContainerStateTerminated{}}},
+			{Name: "log-shipper", Image: "fluent-bit", Ready: false, Restarts: 2, State: running},
+		},
+		Containers: []v1alpha1.Container{
+			{Name: "api", Image: "api", Ready: true, State: running},
+		},
+	})
+
+	v := completeProtoView(t, *state)
+	r, ok := findResource(m.Name, v)
+	require.True(t, ok)
+
+	var names []string
+	for _, c := range r.K8sResourceInfo.Containers {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"log-shipper", "api"}, names)
+	assert.Equal(t, int32(2), r.K8sResourceInfo.Containers[0].Restarts)
+	assert.Equal(t, "fluent-bit", r.K8sResourceInfo.Containers[0].Image)
+
+	// The sidecar counts toward the pod's readiness and restarts.
+	assert.False(t, r.K8sResourceInfo.AllContainersReady)
+	assert.Equal(t, int32(2), r.K8sResourceInfo.PodRestarts)
+}
+
 func TestStateToViewTiltfileLog(t *testing.T) {
 	es := newState([]model.Manifest{})
 	spanID := ctrltiltfile.SpanIDForLoadCount("(Tiltfile)", 1)
@@ -325,6 +360,103 @@ func TestLocalResource(t *testing.T) {
 	require.False(t, spec.HasLiveUpdate)
 }
 
+func TestResourceGroup(t *testing.T) {
+	localManifest := func(name string) model.Manifest {
+		lt := model.NewLocalTarget(model.TargetName(name), model.Cmd{}, model.ToHostCmd("serve"), nil)
+		return model.Manifest{Name: model.ManifestName(name)}.WithDeployTarget(lt)
+	}
+	api := localManifest("api")
+	worker := localManifest("worker")
+
+	state := newState([]model.Manifest{api, worker})
+	state.ManifestTargets["api"].State.RuntimeState = store.LocalRuntimeState{Status: v1alpha1.RuntimeStatusOK}
+	state.ManifestTargets["worker"].State.RuntimeState = store.LocalRuntimeState{Status: v1alpha1.RuntimeStatusError}
+	state.ResourceGroups["backend"] = model.ResourceGroup{
+		Name:    "backend",
+		Members: []model.ManifestName{"api", "worker"},
+	}
+
+	disableSources := map[string][]v1alpha1.DisableSource{
+		"api":    {{ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "api-disable", Key: "isDisabled"}}},
+		"worker": {{ConfigMap: &v1alpha1.ConfigMapDisableSource{Name: "worker-disable", Key: "isDisabled"}}},
+	}
+	for name := range disableSources {
+		cm := name + "-disable"
+		state.ConfigMaps[cm] = &v1alpha1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: cm}, Data: map[string]string{"isDisabled": "false"}}
+	}
+
+	uiResources, err := ToUIResourceList(*state, disableSources)
+	require.NoError(t, err)
+	require.Len(t, uiResources, 4)
+
+	g := uiResources[3]
+	require.Equal(t, "backend", g.Name)
+	assert.Equal(t, "api,worker", g.Annotations["tilt.dev/resource-group-members"])
+
+	// The group has the worst status of its members.
+	assert.Equal(t, v1alpha1.RuntimeStatusError, g.Status.RuntimeStatus)
+	rc := readyCondition(g.Status)
+	assert.Equal(t, "False", string(rc.Status))
+	assert.Equal(t, "RuntimeError", rc.Reason)
+	assert.Equal(t, "worker: RuntimeError", rc.Message)
+
+	// Disabling the group disables every member.
+	assert.Equal(t, append(disableSources["api"], disableSources["worker"]...), g.Status.DisableStatus.Sources)
+	assert.Equal(t, v1alpha1.DisableStateEnabled, g.Status.DisableStatus.State)
+	assert.Equal(t, int32(2), g.Status.DisableStatus.EnabledCount)
+}
+
+func TestDegradedByFailedTests(t *testing.T) {
+	api := model.Manifest{Name: "api"}.WithDeployTarget(model.K8sTarget{})
+	cmd := model.Cmd{Argv: []string{"go", "test", "./..."}, Dir: "path/to/tiltfile"}
+	lt := model.NewLocalTarget("api-tests", cmd, model.Cmd{}, nil).
+		WithRunOnUpdateOf([]model.ManifestName{"api"}, true)
+	tests := model.Manifest{Name: "api-tests"}.WithDeployTarget(lt)
+
+	state := newState([]model.Manifest{api, tests})
+	v := completeProtoView(t, *state)
+	rv, ok := findResource(api.Name, v)
+	require.True(t, ok)
+	require.Equal(t, "False", string(degradedCondition(rv).Status))
+
+	state.ManifestTargets[tests.Name].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now().Add(-time.Second),
+		FinishTime: time.Now(),
+		Error:      fmt.Errorf("exit status 1"),
+	})
+	v = completeProtoView(t, *state)
+	rv, ok = findResource(api.Name, v)
+	require.True(t, ok)
+	c := degradedCondition(rv)
+	require.Equal(t, "True", string(c.Status))
+	require.Equal(t, "DependentFailed", c.Reason)
+	require.Equal(t, "Failed: api-tests", c.Message)
+
+	// The tests themselves don't get a degraded condition.
+	rv, ok = findResource(tests.Name, v)
+	require.True(t, ok)
+	require.Nil(t, degradedCondition(rv))
+}
+
+func TestNotDegradedByInformationalTests(t *testing.T) {
+	api := model.Manifest{Name: "api"}.WithDeployTarget(model.K8sTarget{})
+	cmd := model.Cmd{Argv: []string{"go", "test", "./..."}, Dir: "path/to/tiltfile"}
+	lt := model.NewLocalTarget("api-tests", cmd, model.Cmd{}, nil).
+		WithRunOnUpdateOf([]model.ManifestName{"api"}, false)
+	tests := model.Manifest{Name: "api-tests"}.WithDeployTarget(lt)
+
+	state := newState([]model.Manifest{api, tests})
+	state.ManifestTargets[tests.Name].State.AddCompletedBuild(model.BuildRecord{
+		StartTime:  time.Now().Add(-time.Second),
+		FinishTime: time.Now(),
+		Error:      fmt.Errorf("exit status 1"),
+	})
+	v := completeProtoView(t, *state)
+	rv, ok := findResource(api.Name, v)
+	require.True(t, ok)
+	require.Nil(t, degradedCondition(rv))
+}
+
 func TestBuildHistory(t *testing.T) {
 	br1 := model.BuildRecord{
 		StartTime:  time.Now().Add(-1 * time.Hour),
@@ -598,6 +730,15 @@ func readyCondition(rs v1alpha1.UIResourceStatus) *v1alpha1.UIResourceCondition
 	return nil
 }
 
+func degradedCondition(rs v1alpha1.UIResourceStatus) *v1alpha1.UIResourceCondition {
+	for _, c := range rs.Conditions {
+		if c.Type == v1alpha1.UIResourceDegraded {
+			return &c
+		}
+	}
+	return nil
+}
+
 func upToDateCondition(rs v1alpha1.UIResourceStatus) *v1alpha1.UIResourceCondition {
 	for _, c := range rs.Conditions {
 		if c.Type == v1alpha1.UIResourceUpToDate {
@@ -0,0 +1,131 @@
+package webview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/controllers/apis/uiresource"
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// How bad each status is, for picking the worst status in a group.
+var updateStatusSeverity = map[v1alpha1.UpdateStatus]int{
+	v1alpha1.UpdateStatusNotApplicable: 0,
+	v1alpha1.UpdateStatusOK:            1,
+	v1alpha1.UpdateStatusNone:          2,
+	v1alpha1.UpdateStatusPending:       3,
+	v1alpha1.UpdateStatusInProgress:    4,
+	v1alpha1.UpdateStatusError:         5,
+}
+
+var runtimeStatusSeverity = map[v1alpha1.RuntimeStatus]int{
+	v1alpha1.RuntimeStatusNotApplicable: 0,
+	v1alpha1.RuntimeStatusOK:            1,
+	v1alpha1.RuntimeStatusNone:          2,
+	v1alpha1.RuntimeStatusUnknown:       3,
+	v1alpha1.RuntimeStatusPending:       4,
+	v1alpha1.RuntimeStatusError:         5,
+}
+
+// Converts a resource group into a UIResource, with the worst status of
+// its members.
+//
+// members has the UIResources of the group's members, by name. Members that
+// don't exist (e.g., because they were removed) are skipped.
+func toGroupUIResource(g model.ResourceGroup, members map[string]*v1alpha1.UIResource, s store.EngineState, disableSources map[string][]v1alpha1.DisableSource) (*v1alpha1.UIResource, error) {
+	var memberNames []string
+	var sources []v1alpha1.DisableSource
+	var rs []*v1alpha1.UIResource
+	for _, mn := range g.Members {
+		memberNames = append(memberNames, mn.String())
+		sources = append(sources, disableSources[mn.String()]...)
+		if r, ok := members[mn.String()]; ok {
+			rs = append(rs, r)
+		}
+	}
+
+	drs, err := disableResourceStatus(sources, s)
+	if err != nil {
+		return nil, errors.Wrap(err, "error determining disable resource status")
+	}
+
+	status := v1alpha1.UIResourceStatus{
+		UpdateStatus:  v1alpha1.UpdateStatusNotApplicable,
+		RuntimeStatus: v1alpha1.RuntimeStatusNotApplicable,
+		DisableStatus: drs,
+	}
+	for _, r := range rs {
+		ms := r.Status
+		if updateStatusSeverity[ms.UpdateStatus] > updateStatusSeverity[status.UpdateStatus] {
+			status.UpdateStatus = ms.UpdateStatus
+		}
+		if runtimeStatusSeverity[ms.RuntimeStatus] > runtimeStatusSeverity[status.RuntimeStatus] {
+			status.RuntimeStatus = ms.RuntimeStatus
+		}
+
+		status.Queued = status.Queued || ms.Queued
+		status.HasPendingChanges = status.HasPendingChanges || ms.HasPendingChanges
+		status.PendingBuildSince = earliestTime(status.PendingBuildSince, ms.PendingBuildSince)
+		if ms.CurrentBuild != nil && (status.CurrentBuild == nil ||
+			ms.CurrentBuild.StartTime.Before(&status.CurrentBuild.StartTime)) {
+			status.CurrentBuild = ms.CurrentBuild.DeepCopy()
+		}
+		if status.LastDeployTime.Before(&ms.LastDeployTime) {
+			status.LastDeployTime = ms.LastDeployTime
+		}
+		status.EndpointLinks = append(status.EndpointLinks, ms.EndpointLinks...)
+	}
+
+	status.Conditions = []v1alpha1.UIResourceCondition{
+		groupCondition(v1alpha1.UIResourceUpToDate, rs, UIResourceUpToDateCondition(status)),
+		groupCondition(v1alpha1.UIResourceReady, rs, UIResourceReadyCondition(status)),
+	}
+
+	return &v1alpha1.UIResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   g.Name.String(),
+			Labels: g.Labels,
+			Annotations: map[string]string{
+				uiresource.AnnotationGroupMembers: strings.Join(memberNames, ","),
+			},
+		},
+		Status: status,
+	}, nil
+}
+
+// A condition is only true for the group if it's true for every member.
+// Otherwise, it reports why the first failing member is failing.
+//
+// If the members don't have the condition yet, falls back to the condition
+// computed from the group's status.
+func groupCondition(t v1alpha1.UIResourceConditionType, members []*v1alpha1.UIResource, fallback v1alpha1.UIResourceCondition) v1alpha1.UIResourceCondition {
+	result := fallback
+	for _, r := range members {
+		for _, c := range r.Status.Conditions {
+			if c.Type != t || c.Status == metav1.ConditionTrue {
+				continue
+			}
+			if result.Message == "" {
+				result.Status = c.Status
+				result.Reason = c.Reason
+				result.Message = fmt.Sprintf("%s: %s", r.Name, c.Reason)
+			}
+		}
+	}
+	return result
+}
+
+func earliestTime(a, b metav1.MicroTime) metav1.MicroTime {
+	if a.IsZero() {
+		return b
+	}
+	if b.IsZero() || a.Before(&b) {
+		return a
+	}
+	return b
+}
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
 	"helm.sh/helm/v3/pkg/kube"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -105,6 +107,10 @@ type Client interface {
 	// than they were passed in) and with UUIDs from the Kube API
 	Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error)
 
+	// Waits until the CRDs are established, so that we can apply custom
+	// resources of their kinds. CRDs that don't exist yet are waited for too.
+	WaitForCRDsEstablished(ctx context.Context, crds []K8sEntity, timeout time.Duration) error
+
 	// Delete all given entities, optionally waiting for them to be fully deleted.
 	//
 	// Currently ignores any "not found" errors, because that seems like the correct
@@ -132,6 +138,12 @@ type Client interface {
 
 	Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
 
+	// Whether the current user may exec into pods in the namespace.
+	CanExec(ctx context.Context, n Namespace) (bool, error)
+
+	// Returns one key of a Secret.
+	SecretValue(ctx context.Context, n Namespace, name, key string) ([]byte, error)
+
 	// Returns version information about the apiserver, or an error if we're not connected.
 	CheckConnected(ctx context.Context) (*version.Info, error)
 
@@ -169,6 +181,9 @@ type K8sClient struct {
 	clientLoader      clientcmd.ClientConfig
 	resourceClient    ResourceClient
 	ownerFetcher      OwnerFetcher
+
+	// Coalesces concurrent applies of identical objects.
+	upserts *singleflight.Group
 }
 
 var _ Client = &K8sClient{}
@@ -249,6 +264,7 @@ func ProvideK8sClient(
 		metadata:          meta,
 		apiConfig:         apiConfig,
 		clientLoader:      clientLoader,
+		upserts:           &singleflight.Group{},
 	}
 	c.resourceClient = newResourceClient(c)
 	c.ownerFetcher = NewOwnerFetcher(globalCtx, c)
@@ -358,11 +374,11 @@ func (k *K8sClient) ToRawKubeConfigLoader() clientcmd.ClientConfig {
 
 func (k *K8sClient) Upsert(ctx context.Context, entities []K8sEntity, timeout time.Duration) ([]K8sEntity, error) {
 	result := make([]K8sEntity, 0, len(entities))
-	for _, e := range entities {
+	for i, e := range entities {
 		innerCtx, cancel := context.WithTimeout(ctx, timeout)
 		defer cancel()
 
-		newEntity, err := k.escalatingUpdate(innerCtx, e)
+		newEntity, err := k.coalescedUpdate(innerCtx, e)
 		if err != nil {
 			if ctx.Err() == context.DeadlineExceeded {
 				return nil, timeoutError(timeout)
@@ -370,11 +386,61 @@ func (k *K8sClient) Upsert(ctx context.Context, entities []K8sEntity, timeout ti
 			return nil, err
 		}
 		result = append(result, newEntity...)
+
+		// CRDs sort first. If we're also applying custom resources of this
+		// kind, they'll fail until the CRD is established.
+		if definesKindOf(e, entities[i+1:]) {
+			err := k.waitForCRDEstablished(innerCtx, e)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return result, nil
 }
 
+// coalescedUpdate applies the entity, unless an apply of the identical
+// entity is already in flight, in which case it shares that result.
+func (k *K8sClient) coalescedUpdate(ctx context.Context, e K8sEntity) ([]K8sEntity, error) {
+	if k.upserts == nil {
+		return k.escalatingUpdate(ctx, e)
+	}
+	key, err := upsertKey(e)
+	if err != nil {
+		return k.escalatingUpdate(ctx, e)
+	}
+
+	v, err, shared := k.upserts.Do(key, func() (interface{}, error) {
+		return k.escalatingUpdate(ctx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := v.([]K8sEntity)
+	if !shared {
+		return result, nil
+	}
+
+	// Callers may modify the result, so each gets its own copy.
+	coalescedApplies.Inc()
+	copies := make([]K8sEntity, len(result))
+	for i, r := range result {
+		copies[i] = r.DeepCopy()
+	}
+	return copies, nil
+}
+
+// The identity and content of the entity.
+func upsertKey(e K8sEntity) (string, error) {
+	yaml, err := SerializeSpecYAML([]K8sEntity{e})
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256([]byte(yaml))
+	return fmt.Sprintf("%s/%s/%s/%x", e.GVK(), e.Namespace(), e.Name(), h), nil
+}
+
 func (k *K8sClient) OwnerFetcher() OwnerFetcher {
 	return k.ownerFetcher
 }
@@ -770,6 +836,18 @@ func (k *K8sClient) ListMeta(ctx context.Context, gvk schema.GroupVersionKind, n
 	return result, nil
 }
 
+func (k *K8sClient) SecretValue(ctx context.Context, n Namespace, name, key string) ([]byte, error) {
+	secret, err := k.core.Secrets(n.String()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	val, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", n, name, key)
+	}
+	return val, nil
+}
+
 func (k *K8sClient) GetMetaByReference(ctx context.Context, ref v1.ObjectReference) (metav1.Object, error) {
 	gvk := ReferenceGVK(ref)
 	mapping, err := k.forceDiscovery(ctx, gvk)
@@ -931,5 +1009,14 @@ type RESTConfigOrError struct {
 
 func ProvideRESTConfig(clientLoader clientcmd.ClientConfig) RESTConfigOrError {
 	config, err := clientLoader.ClientConfig()
-	return RESTConfigOrError{Config: config, Error: err}
+	if err != nil {
+		return RESTConfigOrError{Error: err}
+	}
+
+	limits, err := RateLimitsFromEnv()
+	if err != nil {
+		return RESTConfigOrError{Error: err}
+	}
+	WrapWithRateLimits(config, limits)
+	return RESTConfigOrError{Config: config}
 }
@@ -13,11 +13,13 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
 	"helm.sh/helm/v3/pkg/kube"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -55,6 +57,44 @@ func TestUpsert(t *testing.T) {
 	assert.Equal(t, 5, len(f.resourceClient.updates))
 }
 
+func TestUpsertWaitsForCRDEstablished(t *testing.T) {
+	f := newClientTestFixture(t)
+	f.client.dynamic = dynfake.NewSimpleDynamicClient(scheme.Scheme, &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apiextensions.k8s.io/v1beta1",
+			"kind":       "CustomResourceDefinition",
+			"metadata":   map[string]interface{}{"name": "projects.example.martin-helmich.de"},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Established", "status": "True"},
+				},
+			},
+		},
+	})
+
+	entities, err := ParseYAMLFromString(testyaml.CRDYAML)
+	require.NoError(t, err)
+	_, err = f.k8sUpsert(f.ctx, SortedEntities(entities))
+	require.NoError(t, err)
+	require.Equal(t, 2, len(f.resourceClient.updates))
+	assert.Equal(t, "projects.example.martin-helmich.de", f.resourceClient.updates[0].Name)
+	assert.Equal(t, "example-project", f.resourceClient.updates[1].Name)
+}
+
+func TestUpsertCRDNotEstablished(t *testing.T) {
+	f := newClientTestFixture(t)
+
+	entities, err := ParseYAMLFromString(testyaml.CRDYAML)
+	require.NoError(t, err)
+	_, err = f.client.Upsert(f.ctx, SortedEntities(entities), 100*time.Millisecond)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "CustomResourceDefinition projects.example.martin-helmich.de was not established before the timeout")
+	}
+
+	// The custom resource was never applied.
+	assert.Equal(t, 1, len(f.resourceClient.updates))
+}
+
 func TestDelete(t *testing.T) {
 	f := newClientTestFixture(t)
 	postgres, err := ParseYAMLFromString(testyaml.PostgresYAML)
@@ -409,6 +449,7 @@ func newClientTestFixture(t *testing.T) *clientTestFixture {
 		registryAsync:     registryAsync,
 		resourceClient:    resourceClient,
 		drm:               fakeRESTMapper{},
+		upserts:           &singleflight.Group{},
 	}
 
 	return ret
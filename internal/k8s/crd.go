@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+const crdKind = "CustomResourceDefinition"
+
+// How often to check whether a CRD is established.
+var crdEstablishedPollInterval = 250 * time.Millisecond
+
+// CRDGroupKind returns the kind of custom resource that a CRD defines.
+func CRDGroupKind(e K8sEntity) (schema.GroupKind, bool) {
+	gvk := e.GVK()
+	if gvk.Kind != crdKind || gvk.Group != "apiextensions.k8s.io" {
+		return schema.GroupKind{}, false
+	}
+
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+	if err != nil {
+		return schema.GroupKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(obj, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj, "spec", "names", "kind")
+	if kind == "" {
+		return schema.GroupKind{}, false
+	}
+	return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+// ChangedCRDKinds returns the kinds of custom resources whose CRDs were
+// added or changed between two versions of some YAML.
+func ChangedCRDKinds(oldYAML, newYAML string) []schema.GroupKind {
+	if oldYAML == newYAML || !strings.Contains(newYAML, crdKind) {
+		return nil
+	}
+
+	oldSpecs := crdSpecsByKind(oldYAML)
+	var result []schema.GroupKind
+	for gk, spec := range crdSpecsByKind(newYAML) {
+		oldSpec, ok := oldSpecs[gk]
+		if !ok || !reflect.DeepEqual(oldSpec, spec) {
+			result = append(result, gk)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+	return result
+}
+
+func crdSpecsByKind(yaml string) map[schema.GroupKind]interface{} {
+	result := make(map[schema.GroupKind]interface{})
+	if !strings.Contains(yaml, crdKind) {
+		return result
+	}
+
+	entities, err := ParseYAMLFromString(yaml)
+	if err != nil {
+		return result
+	}
+	for _, e := range entities {
+		gk, ok := CRDGroupKind(e)
+		if !ok {
+			continue
+		}
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e.Obj)
+		if err != nil {
+			continue
+		}
+		result[gk] = obj["spec"]
+	}
+	return result
+}
+
+// definesKindOf checks whether any of the entities are custom resources
+// defined by the CRD.
+func definesKindOf(crd K8sEntity, entities []K8sEntity) bool {
+	gk, ok := CRDGroupKind(crd)
+	if !ok {
+		return false
+	}
+	for _, e := range entities {
+		if e.GVK().GroupKind() == gk {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitForCRDsEstablished waits for CRDs that might be applied by someone
+// else (e.g., another Tilt resource), including CRDs that don't exist yet.
+func (k *K8sClient) WaitForCRDsEstablished(ctx context.Context, crds []K8sEntity, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for _, crd := range crds {
+		err := k.waitForCRDEstablished(ctx, crd)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wait until the apiserver serves the custom resource of a CRD we just applied.
+//
+// Until then, applying a custom resource of that kind fails with
+// "no matches for kind".
+func (k *K8sClient) waitForCRDEstablished(ctx context.Context, crd K8sEntity) error {
+	gvr := schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  crd.GVK().Version,
+		Resource: "customresourcedefinitions",
+	}
+
+	logged := false
+	ticker := time.NewTicker(crdEstablishedPollInterval)
+	defer ticker.Stop()
+	for {
+		obj, err := k.dynamic.Resource(gvr).Get(ctx, crd.Name(), metav1.GetOptions{})
+		if err == nil && isCRDEstablished(obj) {
+			return nil
+		}
+
+		if !logged {
+			logger.Get(ctx).Infof("Waiting for %s %s to be established", crdKind, crd.Name())
+			logged = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s %s was not established before the timeout. "+
+				"Custom resources of its kind can't be applied until it is. Check its status with:\n"+
+				"  kubectl get crd %s -o jsonpath='{.status.conditions}'", crdKind, crd.Name(), crd.Name())
+		case <-ticker.C:
+		}
+	}
+}
+
+func isCRDEstablished(obj *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == "Established" && condition["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestChangedCRDKinds(t *testing.T) {
+	crdYAML, crYAML, ok := strings.Cut(testyaml.CRDYAML, "---")
+	require.True(t, ok)
+	project := schema.GroupKind{Group: "example.martin-helmich.de", Kind: "Project"}
+
+	assert.Empty(t, ChangedCRDKinds(crdYAML, crdYAML))
+	assert.Empty(t, ChangedCRDKinds(crdYAML, crYAML))
+	assert.Equal(t, []schema.GroupKind{project}, ChangedCRDKinds("", crdYAML))
+	assert.Equal(t, []schema.GroupKind{project}, ChangedCRDKinds(crYAML, testyaml.CRDYAML))
+
+	changed := strings.Replace(crdYAML, "minimum: 1", "minimum: 2", 1)
+	assert.Equal(t, []schema.GroupKind{project}, ChangedCRDKinds(crdYAML, changed))
+
+	// Changes outside the spec don't matter.
+	labeled := strings.Replace(crdYAML, "metadata:\n", "metadata:\n  labels:\n    a: b\n", 1)
+	assert.Empty(t, ChangedCRDKinds(crdYAML, labeled))
+}
@@ -33,16 +33,19 @@ type entityList []K8sEntity
 
 func (l entityList) Len() int { return len(l) }
 func (l entityList) Less(i, j int) bool {
-	// Sort entities by the priority of their Kind
+	// Sort entities by the priority of their Kind, so that dependencies
+	// (like Namespaces and CRDs) are applied first.
 	indexI := kustomize.TypeOrders[l[i].GVK().Kind]
 	indexJ := kustomize.TypeOrders[l[j].GVK().Kind]
-	if indexI != indexJ {
-		return indexI < indexJ
-	}
-	return i < j
+	return indexI < indexJ
 }
 func (l entityList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
 
+// SortedEntities sorts entities into the order Tilt applies them: by the
+// priority of their Kind (Namespaces, CRDs, and RBAC first; see
+// kustomize.OrderFirst).
+//
+// Entities with the same priority keep the order the user declared them in.
 func SortedEntities(entities []K8sEntity) []K8sEntity {
 	entList := entityList(CopyEntities(entities))
 	sort.Stable(entList)
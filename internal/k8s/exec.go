@@ -6,14 +6,16 @@ import (
 	"fmt"
 	"io"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/remotecommand"
 	"k8s.io/kubectl/pkg/scheme"
 
 	"github.com/tilt-dev/tilt/internal/container"
 )
 
-func (k *K8sClient) Exec(_ context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+func (k *K8sClient) Exec(ctx context.Context, podID PodID, cName container.Name, n Namespace, cmd []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
 	req := k.core.RESTClient().Post().
 		Resource("pods").
 		Namespace(n.String()).
@@ -33,7 +35,7 @@ func (k *K8sClient) Exec(_ context.Context, podID PodID, cName container.Name, n
 		return fmt.Errorf("establishing connection: %w", err)
 	}
 
-	err = exec.Stream(remotecommand.StreamOptions{
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{
 		Stdin:  stdin,
 		Stdout: stdout,
 		Stderr: stderr,
@@ -52,3 +54,21 @@ func (k *K8sClient) Exec(_ context.Context, podID PodID, cName container.Name, n
 	}
 	return nil
 }
+
+func (k *K8sClient) CanExec(ctx context.Context, n Namespace) (bool, error) {
+	review, err := k.clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx,
+		&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   n.String(),
+					Verb:        "create",
+					Resource:    "pods",
+					Subresource: "exec",
+				},
+			},
+		}, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("checking pods/exec permission: %w", err)
+	}
+	return review.Status.Allowed, nil
+}
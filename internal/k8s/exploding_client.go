@@ -29,6 +29,10 @@ func (ec *explodingClient) Upsert(ctx context.Context, entities []K8sEntity, tim
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) WaitForCRDsEstablished(ctx context.Context, crds []K8sEntity, timeout time.Duration) error {
+	return errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) Delete(ctx context.Context, entities []K8sEntity, wait time.Duration) error {
 	return errors.Wrap(ec.err, "could not set up kubernetes client")
 }
@@ -93,6 +97,14 @@ func (ec *explodingClient) Exec(ctx context.Context, podID PodID, cName containe
 	return errors.Wrap(ec.err, "could not set up kubernetes client")
 }
 
+func (ec *explodingClient) CanExec(ctx context.Context, n Namespace) (bool, error) {
+	return false, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
+func (ec *explodingClient) SecretValue(ctx context.Context, n Namespace, name, key string) ([]byte, error) {
+	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
+}
+
 func (ec *explodingClient) CheckConnected(ctx context.Context) (*version.Info, error) {
 	return nil, errors.Wrap(ec.err, "could not set up kubernetes client")
 }
@@ -6,6 +6,8 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/internal/container"
 )
 
 func ExtractPods(obj interface{}) ([]*v1.PodSpec, error) {
@@ -132,6 +134,45 @@ func extractContainers(obj interface{}) ([]*v1.Container, error) {
 	return result, nil
 }
 
+// Like extractContainers, but only returns the containers whose names
+// match one of the patterns. If there are no patterns, returns all of them.
+func extractCandidateContainers(obj interface{}, patterns []string) ([]*v1.Container, error) {
+	containers, err := extractContainers(obj)
+	if err != nil || len(patterns) == 0 {
+		return containers, err
+	}
+
+	result := make([]*v1.Container, 0, len(containers))
+	for _, c := range containers {
+		if container.Name(c.Name).MatchesAny(patterns) {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// Like extractEnvVars, but only returns the env vars of the containers
+// whose names match one of the patterns. If there are no patterns, returns
+// all of them.
+func extractCandidateEnvVars(obj interface{}, patterns []string) ([]*v1.EnvVar, error) {
+	if len(patterns) == 0 {
+		return extractEnvVars(obj)
+	}
+
+	containers, err := extractCandidateContainers(obj, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*v1.EnvVar
+	for _, c := range containers {
+		for i := range c.Env {
+			result = append(result, &c.Env[i])
+		}
+	}
+	return result, nil
+}
+
 type extractor struct {
 	// The type we want to return pointers to
 	pType reflect.Type
@@ -78,6 +78,10 @@ type FakeK8sClient struct {
 	LastUpsertResult []K8sEntity
 	UpsertTimeout    time.Duration
 
+	// The CRDs passed to WaitForCRDsEstablished.
+	WaitedForCRDs               []K8sEntity
+	WaitForCRDsEstablishedError error
+
 	Runtime    container.Runtime
 	Registry   *v1alpha1.RegistryHosting
 	FakeNodeIP NodeIP
@@ -98,6 +102,8 @@ type FakeK8sClient struct {
 	ExecCalls           []ExecCall
 	ExecOutputs         []io.Reader
 	ExecErrors          []error
+	ExecForbidden       bool
+	SecretValues        map[string][]byte // keyed by namespace/name/key
 	ClusterHealthStatus *ClusterHealth
 	ClusterHealthError  error
 	FakeAPIConfig       *api.Config
@@ -427,6 +433,14 @@ func (c *FakeK8sClient) Upsert(_ context.Context, entities []K8sEntity, timeout
 	return result, nil
 }
 
+func (c *FakeK8sClient) WaitForCRDsEstablished(_ context.Context, crds []K8sEntity, timeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.WaitedForCRDs = append(c.WaitedForCRDs, crds...)
+	return c.WaitForCRDsEstablishedError
+}
+
 func (c *FakeK8sClient) Delete(_ context.Context, entities []K8sEntity, wait time.Duration) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -669,6 +683,22 @@ func (c *FakeK8sClient) Exec(ctx context.Context, podID PodID, cName container.N
 	return nil
 }
 
+func (c *FakeK8sClient) CanExec(ctx context.Context, n Namespace) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.ExecForbidden, nil
+}
+
+func (c *FakeK8sClient) SecretValue(ctx context.Context, n Namespace, name, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	val, ok := c.SecretValues[fmt.Sprintf("%s/%s/%s", n, name, key)]
+	if !ok {
+		return nil, apierrors.NewNotFound(v1.Resource("secrets"), name)
+	}
+	return val, nil
+}
+
 func (c *FakeK8sClient) CheckConnected(ctx context.Context) (*version.Info, error) {
 	return &version.Info{}, nil
 }
@@ -37,6 +37,17 @@ func InjectImagePullPolicy(entity K8sEntity, policy v1.PullPolicy) (K8sEntity, e
 //
 // Returns: the new entity, whether the image was replaced, and an error.
 func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectRef reference.Named, locators []ImageLocator, matchInEnvVars bool, policy v1.PullPolicy) (K8sEntity, bool, error) {
+	return InjectImageDigestForContainers(entity, nil, selector, injectRef, locators, matchInEnvVars, policy)
+}
+
+// Like InjectImageDigest, but only replaces images in the containers
+// whose names match one of the container name patterns (see
+// container.Name.MatchesAny), and in their env vars. Other containers,
+// like sidecars that happen to use the same image, are left alone.
+//
+// If there are no patterns, all containers are candidates. Images found by
+// locators are always candidates, since locators don't point at containers.
+func InjectImageDigestForContainers(entity K8sEntity, containerPatterns []string, selector container.RefSelector, injectRef reference.Named, locators []ImageLocator, matchInEnvVars bool, policy v1.PullPolicy) (K8sEntity, bool, error) {
 	entity = entity.DeepCopy()
 
 	// NOTE(nick): For some reason, if you have a reference with a digest,
@@ -56,7 +67,7 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 
 	replaced := false
 
-	entity, r, err := injectImageDigestInContainers(entity, selector, injectRef, policy)
+	entity, r, err := injectImageDigestInContainers(entity, containerPatterns, selector, injectRef, policy)
 	if err != nil {
 		return K8sEntity{}, false, err
 	}
@@ -65,7 +76,7 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 	}
 
 	if matchInEnvVars {
-		entity, r, err = injectImageDigestInEnvVars(entity, selector, injectRef)
+		entity, r, err = injectImageDigestInEnvVars(entity, containerPatterns, selector, injectRef)
 		if err != nil {
 			return K8sEntity{}, false, err
 		}
@@ -87,8 +98,8 @@ func InjectImageDigest(entity K8sEntity, selector container.RefSelector, injectR
 	return entity, replaced, nil
 }
 
-func injectImageDigestInContainers(entity K8sEntity, selector container.RefSelector, injectRef reference.Named, policy v1.PullPolicy) (K8sEntity, bool, error) {
-	containers, err := extractContainers(&entity)
+func injectImageDigestInContainers(entity K8sEntity, containerPatterns []string, selector container.RefSelector, injectRef reference.Named, policy v1.PullPolicy) (K8sEntity, bool, error) {
+	containers, err := extractCandidateContainers(&entity, containerPatterns)
 	if err != nil {
 		return K8sEntity{}, false, err
 	}
@@ -110,8 +121,8 @@ func injectImageDigestInContainers(entity K8sEntity, selector container.RefSelec
 	return entity, replaced, nil
 }
 
-func injectImageDigestInEnvVars(entity K8sEntity, selector container.RefSelector, injectRef reference.Named) (K8sEntity, bool, error) {
-	envVars, err := extractEnvVars(&entity)
+func injectImageDigestInEnvVars(entity K8sEntity, containerPatterns []string, selector container.RefSelector, injectRef reference.Named) (K8sEntity, bool, error) {
+	envVars, err := extractCandidateEnvVars(&entity, containerPatterns)
 	if err != nil {
 		return K8sEntity{}, false, err
 	}
@@ -205,10 +216,17 @@ func (e K8sEntity) HasImage(image container.RefSelector, locators []ImageLocator
 }
 
 func (e K8sEntity) FindImages(locators []ImageLocator, envVarImages []container.RefSelector) ([]reference.Named, error) {
+	return e.FindImagesInContainers(nil, locators, envVarImages)
+}
+
+// Like FindImages, but only looks in the containers whose names match one
+// of the container name patterns, and in their env vars. If there are no
+// patterns, looks in all containers.
+func (e K8sEntity) FindImagesInContainers(containerPatterns []string, locators []ImageLocator, envVarImages []container.RefSelector) ([]reference.Named, error) {
 	var result []reference.Named
 
 	// Look for images in instances of Container
-	containers, err := extractContainers(&e)
+	containers, err := extractCandidateContainers(&e, containerPatterns)
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +255,7 @@ func (e K8sEntity) FindImages(locators []ImageLocator, envVarImages []container.
 		result = append(result, refs...)
 	}
 
-	envVars, err := extractEnvVars(&obj)
+	envVars, err := extractCandidateEnvVars(&obj, containerPatterns)
 	if err != nil {
 		return nil, err
 	}
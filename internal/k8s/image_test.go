@@ -463,3 +463,84 @@ func TestMatchInEnvVarsTrue(t *testing.T) {
 	assert.Equal(t, namedTagged.String(), c.Image)
 	assert.Contains(t, c.Env, v1.EnvVar{Name: "bar", Value: namedTagged.String()})
 }
+
+const sidecarSameImageYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      initContainers:
+      - name: migrate
+        image: gcr.io/some-project-162817/api
+      containers:
+      - name: api
+        image: gcr.io/some-project-162817/api
+        env:
+        - name: WORKER_IMAGE
+          value: gcr.io/some-project-162817/api
+      - name: log-shipper
+        image: gcr.io/some-project-162817/api
+        env:
+        - name: WORKER_IMAGE
+          value: gcr.io/some-project-162817/api
+`
+
+func TestInjectDigestForContainers(t *testing.T) {
+	entity := parseOneEntity(t, sidecarSameImageYAML)
+	name := "gcr.io/some-project-162817/api"
+	ref, err := reference.ParseNamed(name + ":tilt-123")
+	require.NoError(t, err)
+
+	newEntity, replaced, err := InjectImageDigestForContainers(entity, []string{"api", "mig*"},
+		container.NameSelector(ref), ref, nil, true, v1.PullNever)
+	require.NoError(t, err)
+	assert.True(t, replaced)
+
+	spec := newEntity.Obj.(*appsv1.Deployment).Spec.Template.Spec
+	assert.Equal(t, ref.String(), spec.InitContainers[0].Image)
+	assert.Equal(t, ref.String(), spec.Containers[0].Image)
+	assert.Equal(t, ref.String(), spec.Containers[0].Env[0].Value)
+
+	// The sidecar that uses the same image is left alone.
+	assert.Equal(t, name, spec.Containers[1].Image)
+	assert.Equal(t, v1.PullPolicy(""), spec.Containers[1].ImagePullPolicy)
+	assert.Equal(t, name, spec.Containers[1].Env[0].Value)
+}
+
+func TestInjectDigestForContainersNoMatch(t *testing.T) {
+	entity := parseOneEntity(t, sidecarSameImageYAML)
+	ref, err := reference.ParseNamed("gcr.io/some-project-162817/api:tilt-123")
+	require.NoError(t, err)
+
+	newEntity, replaced, err := InjectImageDigestForContainers(entity, []string{"worker"},
+		container.NameSelector(ref), ref, nil, true, v1.PullNever)
+	require.NoError(t, err)
+	assert.False(t, replaced)
+
+	spec := newEntity.Obj.(*appsv1.Deployment).Spec.Template.Spec
+	assert.Equal(t, "gcr.io/some-project-162817/api", spec.Containers[0].Image)
+}
+
+func TestFindImagesInContainers(t *testing.T) {
+	entity := parseOneEntity(t, strings.ReplaceAll(sidecarSameImageYAML,
+		"name: log-shipper\n        image: gcr.io/some-project-162817/api",
+		"name: log-shipper\n        image: fluent/fluent-bit"))
+
+	images, err := entity.FindImagesInContainers([]string{"log-shipper"}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, "docker.io/fluent/fluent-bit", images[0].String())
+
+	images, err = entity.FindImages(nil, nil)
+	require.NoError(t, err)
+	assert.Len(t, images, 3)
+}
@@ -0,0 +1,135 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/docker/distribution/reference"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+// A field that image injection rewrote.
+type InjectedField struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+
+	// The container the field belongs to, if any. An image found by an
+	// image locator (e.g., in a CRD) may not belong to a container.
+	Container string `json:"container,omitempty"`
+
+	// The path to the field, e.g., "spec.template.spec.containers[0].image".
+	Path string `json:"path"`
+
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// Lists of containers in a pod spec, by field name.
+var containerListFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// InjectImageDigestReport is like InjectImageDigestForContainers, but
+// also reports each field that the injection rewrote, so that a user can
+// see what Tilt would change before it changes anything.
+//
+// Pull policies that the injection sets aren't reported.
+func InjectImageDigestReport(entity K8sEntity, containerPatterns []string, selector container.RefSelector, injectRef reference.Named, locators []ImageLocator, matchInEnvVars bool, policy v1.PullPolicy) (K8sEntity, []InjectedField, error) {
+	result, replaced, err := InjectImageDigestForContainers(entity, containerPatterns, selector, injectRef, locators, matchInEnvVars, policy)
+	if err != nil || !replaced {
+		return result, nil, err
+	}
+
+	before, err := runtime.DefaultUnstructuredConverter.ToUnstructured(entity.Obj)
+	if err != nil {
+		return K8sEntity{}, nil, err
+	}
+	after, err := runtime.DefaultUnstructuredConverter.ToUnstructured(result.Obj)
+	if err != nil {
+		return K8sEntity{}, nil, err
+	}
+
+	var fields []InjectedField
+	diffStringFields("", "", before, after, func(path, containerName, old, new string) {
+		fields = append(fields, InjectedField{
+			Kind:      entity.GVK().Kind,
+			Namespace: entity.Namespace().String(),
+			Name:      entity.Name(),
+			Container: containerName,
+			Path:      path,
+			Old:       old,
+			New:       new,
+		})
+	})
+	return result, fields, nil
+}
+
+// diffStringFields visits the string fields that differ between two
+// unstructured objects of the same shape, with the name of the container
+// each field is in (if any).
+func diffStringFields(path, containerName string, before, after interface{}, visit func(path, containerName, old, new string)) {
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(a))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == "imagePullPolicy" {
+				continue
+			}
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+
+			if containerListFields[k] {
+				bl, _ := b[k].([]interface{})
+				al, _ := a[k].([]interface{})
+				for i := 0; i < len(al) && i < len(bl); i++ {
+					name := containerName
+					if c, ok := al[i].(map[string]interface{}); ok {
+						name, _ = c["name"].(string)
+					}
+					diffStringFields(fmt.Sprintf("%s[%d]", childPath, i), name, bl[i], al[i], visit)
+				}
+				continue
+			}
+			diffStringFields(childPath, containerName, b[k], a[k], visit)
+		}
+
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < len(a) && i < len(b); i++ {
+			diffStringFields(fmt.Sprintf("%s[%d]", path, i), containerName, b[i], a[i], visit)
+		}
+
+	case string:
+		a, ok := after.(string)
+		if ok && a != b {
+			visit(path, containerName, b, a)
+		}
+
+	case nil:
+		// A field that was added, e.g., an image tag set by an image
+		// object locator.
+		a, ok := after.(string)
+		if ok && a != "" {
+			visit(path, containerName, "", a)
+		}
+	}
+}
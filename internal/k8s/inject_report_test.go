@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestInjectImageDigestReport(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoImageInEnvYAML)
+	ref := container.MustParseNamedTagged(testyaml.SanchoImage + ":tilt-deadbeef")
+
+	_, fields, err := InjectImageDigestReport(entity, nil, container.NameSelector(ref), ref, nil, true, "")
+	require.NoError(t, err)
+	assert.Equal(t, []InjectedField{
+		{
+			Kind:      "Deployment",
+			Namespace: "sancho-ns",
+			Name:      "sancho",
+			Container: "sancho",
+			Path:      "spec.template.spec.containers[0].env[1].value",
+			Old:       testyaml.SanchoImage,
+			New:       testyaml.SanchoImage + ":tilt-deadbeef",
+		},
+		{
+			Kind:      "Deployment",
+			Namespace: "sancho-ns",
+			Name:      "sancho",
+			Container: "sancho",
+			Path:      "spec.template.spec.containers[0].image",
+			Old:       testyaml.SanchoImage,
+			New:       testyaml.SanchoImage + ":tilt-deadbeef",
+		},
+	}, fields)
+}
+
+func TestInjectImageDigestReportSidecar(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoSidecarYAML)
+	ref := container.MustParseNamedTagged(testyaml.SanchoSidecarImage + ":tilt-deadbeef")
+
+	_, fields, err := InjectImageDigestReport(entity, nil, container.NameSelector(ref), ref, nil, false, "")
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	assert.Equal(t, "sancho-sidecar", fields[0].Container)
+	assert.Equal(t, "spec.template.spec.containers[1].image", fields[0].Path)
+}
+
+func TestInjectImageDigestReportNoMatch(t *testing.T) {
+	entity := parseOneEntity(t, testyaml.SanchoYAML)
+	ref := container.MustParseNamedTagged("gcr.io/other:tilt-deadbeef")
+
+	_, fields, err := InjectImageDigestReport(entity, nil, container.NameSelector(ref), ref, nil, false, "")
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
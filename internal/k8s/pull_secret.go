@@ -0,0 +1,215 @@
+package k8s
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Tilt sets these annotations on the Cluster when the Tiltfile asks for an
+// image pull secret for the default registry, e.g.,
+//
+//	default_registry('gcr.io/my-project', create_pull_secret_from='docker-config')
+//
+// AnnotationPullSecretFrom is where the credentials come from, and
+// AnnotationPullSecretTarget is what references the secret: the pod specs
+// that Tilt deploys ("pod"), or the default ServiceAccount of their
+// namespace ("serviceaccount").
+const (
+	AnnotationPullSecretFrom   = "tilt.dev/pull-secret-from"
+	AnnotationPullSecretTarget = "tilt.dev/pull-secret-target"
+)
+
+const (
+	PullSecretFromDockerConfig     = "docker-config"
+	PullSecretTargetPod            = "pod"
+	PullSecretTargetServiceAccount = "serviceaccount"
+)
+
+// Where the credentials for the default registry's pull secret come from,
+// and what references it.
+type PullSecretSettings struct {
+	From   string
+	Target string
+}
+
+// Annotations returns the Cluster annotations for the settings.
+func (s PullSecretSettings) Annotations() map[string]string {
+	result := map[string]string{
+		AnnotationPullSecretFrom: s.From,
+	}
+	if s.Target != "" {
+		result[AnnotationPullSecretTarget] = s.Target
+	}
+	return result
+}
+
+// The name of the Secret that Tilt creates in each namespace.
+const PullSecretName = "tilt-registry-pull-secret"
+
+// The ServiceAccount that pods run as when they don't name one.
+const defaultServiceAccountName = "default"
+
+// RegistryDomain returns the domain of a registry, e.g., "gcr.io" for
+// "gcr.io/my-project".
+func RegistryDomain(host string) string {
+	domain, _, _ := strings.Cut(host, "/")
+	if domain == "index.docker.io" {
+		return "docker.io"
+	}
+	return domain
+}
+
+// Whether the image is pulled from the registry domain.
+func imageOnDomain(image string, domain string) bool {
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return false
+	}
+	return reference.Domain(ref) == domain
+}
+
+// PodSpecsPullingFrom returns the pod specs of the entity with a container
+// (or init container) image on the registry domain.
+func PodSpecsPullingFrom(entity K8sEntity, domain string) ([]*v1.PodSpec, error) {
+	pods, err := ExtractPods(&entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*v1.PodSpec
+	for _, pod := range pods {
+		containers := append(append([]v1.Container{}, pod.InitContainers...), pod.Containers...)
+		for _, c := range containers {
+			if imageOnDomain(c.Image, domain) {
+				result = append(result, pod)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// InjectImagePullSecret adds the secret to the imagePullSecrets of each pod
+// spec that pulls from the registry domain.
+//
+// Returns: the new entity, whether any pod spec pulls from the registry, and
+// an error.
+func InjectImagePullSecret(entity K8sEntity, name string, domain string) (K8sEntity, bool, error) {
+	entity = entity.DeepCopy()
+	pods, err := PodSpecsPullingFrom(entity, domain)
+	if err != nil {
+		return K8sEntity{}, false, err
+	}
+
+	for _, pod := range pods {
+		if !hasImagePullSecret(pod.ImagePullSecrets, name) {
+			pod.ImagePullSecrets = append(pod.ImagePullSecrets, v1.LocalObjectReference{Name: name})
+		}
+	}
+	return entity, len(pods) > 0, nil
+}
+
+func hasImagePullSecret(refs []v1.LocalObjectReference, name string) bool {
+	for _, ref := range refs {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPullSecretEntity creates a kubernetes.io/dockerconfigjson Secret with
+// the credentials for a single registry.
+func NewPullSecretEntity(ns Namespace, domain string, username string, password string) (K8sEntity, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, password)))
+	config, err := json.Marshal(map[string]interface{}{
+		"auths": map[string]interface{}{
+			domain: map[string]string{
+				"username": username,
+				"password": password,
+				"auth":     auth,
+			},
+		},
+	})
+	if err != nil {
+		return K8sEntity{}, err
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PullSecretName,
+			Namespace: string(ns),
+			Labels: map[string]string{
+				ManagedByLabel: ManagedByValue,
+			},
+		},
+		Type: v1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			v1.DockerConfigJsonKey: config,
+		},
+	}
+	return NewK8sEntity(secret), nil
+}
+
+// PullSecretValues returns the values in the pull secret that should be
+// scrubbed from logs.
+func PullSecretValues(entity K8sEntity) model.SecretSet {
+	result := model.SecretSet{}
+	secret, ok := entity.Obj.(*v1.Secret)
+	if !ok {
+		return result
+	}
+
+	for key, data := range secret.Data {
+		result.AddSecret(secret.Name, key, data)
+
+		var config struct {
+			Auths map[string]struct {
+				Password string `json:"password"`
+				Auth     string `json:"auth"`
+			} `json:"auths"`
+		}
+		if json.Unmarshal(data, &config) != nil {
+			continue
+		}
+		for _, auth := range config.Auths {
+			if auth.Password != "" {
+				result.AddSecret(secret.Name, "password", []byte(auth.Password))
+			}
+			if auth.Auth != "" {
+				result.AddSecret(secret.Name, "auth", []byte(auth.Auth))
+			}
+		}
+	}
+	return result
+}
+
+// NewPullSecretServiceAccountEntity creates the default ServiceAccount of
+// the namespace, referencing the pull secret, so that every pod that runs
+// as it can pull from the registry.
+func NewPullSecretServiceAccountEntity(ns Namespace) K8sEntity {
+	sa := &v1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultServiceAccountName,
+			Namespace: string(ns),
+		},
+		ImagePullSecrets: []v1.LocalObjectReference{{Name: PullSecretName}},
+	}
+	return NewK8sEntity(sa)
+}
@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestRegistryDomain(t *testing.T) {
+	assert.Equal(t, "gcr.io", RegistryDomain("gcr.io/my-project"))
+	assert.Equal(t, "localhost:5000", RegistryDomain("localhost:5000"))
+	assert.Equal(t, "docker.io", RegistryDomain("index.docker.io/me"))
+}
+
+func TestInjectImagePullSecret(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+
+	newEntity, ok, err := InjectImagePullSecret(entities[0], PullSecretName, "gcr.io")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	pullSecrets := newEntity.Obj.(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets
+	assert.Equal(t, []v1.LocalObjectReference{{Name: PullSecretName}}, pullSecrets)
+
+	// Injecting twice doesn't duplicate the secret.
+	newEntity, _, err = InjectImagePullSecret(newEntity, PullSecretName, "gcr.io")
+	require.NoError(t, err)
+	pullSecrets = newEntity.Obj.(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets
+	assert.Len(t, pullSecrets, 1)
+
+	// The original is unchanged.
+	assert.Empty(t, entities[0].Obj.(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets)
+}
+
+func TestInjectImagePullSecretOtherRegistry(t *testing.T) {
+	entities, err := ParseYAMLFromString(testyaml.SanchoYAML)
+	require.NoError(t, err)
+
+	newEntity, ok, err := InjectImagePullSecret(entities[0], PullSecretName, "registry.example.com")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Empty(t, newEntity.Obj.(*appsv1.Deployment).Spec.Template.Spec.ImagePullSecrets)
+}
+
+func TestNewPullSecretEntity(t *testing.T) {
+	e, err := NewPullSecretEntity("dev", "gcr.io", "_json_key", "hunter2")
+	require.NoError(t, err)
+
+	secret := e.Obj.(*v1.Secret)
+	assert.Equal(t, "dev", secret.Namespace)
+	assert.Equal(t, v1.SecretTypeDockerConfigJson, secret.Type)
+
+	var config struct {
+		Auths map[string]map[string]string `json:"auths"`
+	}
+	require.NoError(t, json.Unmarshal(secret.Data[v1.DockerConfigJsonKey], &config))
+	assert.Equal(t, map[string]string{
+		"username": "_json_key",
+		"password": "hunter2",
+		"auth":     "X2pzb25fa2V5Omh1bnRlcjI=",
+	}, config.Auths["gcr.io"])
+
+	secrets := PullSecretValues(e)
+	assert.Equal(t, "pw=[redacted secret tilt-registry-pull-secret:password]",
+		string(secrets.Scrub([]byte("pw=hunter2"))))
+	assert.Equal(t, "auth=[redacted secret tilt-registry-pull-secret:auth]",
+		string(secrets.Scrub([]byte("auth=X2pzb25fa2V5Omh1bnRlcjI="))))
+}
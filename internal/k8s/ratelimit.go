@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// Client-side rate limits for requests to the cluster.
+//
+// Reads (get, list, watch) and writes have separate budgets, so that a
+// burst of applies doesn't starve the watches, and vice versa.
+type RateLimits struct {
+	ReadQPS    float32
+	ReadBurst  int
+	WriteQPS   float32
+	WriteBurst int
+}
+
+// Generous enough that one person running Tilt never waits on them.
+var DefaultRateLimits = RateLimits{
+	ReadQPS:    50,
+	ReadBurst:  100,
+	WriteQPS:   20,
+	WriteBurst: 40,
+}
+
+var (
+	apiRequests = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace: "tilt",
+		Subsystem: "k8s_client",
+		Name:      "requests_total",
+		Help:      "Requests from Tilt to the Kubernetes API server, by verb and resource.",
+	}, []string{"verb", "resource"})
+
+	throttledSeconds = metrics.NewCounterVec(&metrics.CounterOpts{
+		Namespace: "tilt",
+		Subsystem: "k8s_client",
+		Name:      "throttled_seconds_total",
+		Help:      "Time that requests to the Kubernetes API server waited on the client-side rate limits, by budget.",
+	}, []string{"budget"})
+
+	coalescedApplies = metrics.NewCounter(&metrics.CounterOpts{
+		Namespace: "tilt",
+		Subsystem: "k8s_client",
+		Name:      "coalesced_applies_total",
+		Help:      "Applies that shared the result of an identical apply already in flight.",
+	})
+)
+
+func init() {
+	legacyregistry.MustRegister(apiRequests, throttledSeconds, coalescedApplies)
+}
+
+// RateLimitsFromEnv reads overrides of the default rate limits from
+// TILT_K8S_READ_QPS, TILT_K8S_READ_BURST, TILT_K8S_WRITE_QPS, and
+// TILT_K8S_WRITE_BURST.
+func RateLimitsFromEnv() (RateLimits, error) {
+	limits := DefaultRateLimits
+	for _, v := range []struct {
+		env   string
+		qps   *float32
+		burst *int
+	}{
+		{env: "TILT_K8S_READ_QPS", qps: &limits.ReadQPS},
+		{env: "TILT_K8S_READ_BURST", burst: &limits.ReadBurst},
+		{env: "TILT_K8S_WRITE_QPS", qps: &limits.WriteQPS},
+		{env: "TILT_K8S_WRITE_BURST", burst: &limits.WriteBurst},
+	} {
+		s := os.Getenv(v.env)
+		if s == "" {
+			continue
+		}
+		if v.qps != nil {
+			qps, err := strconv.ParseFloat(s, 32)
+			if err != nil || qps <= 0 {
+				return RateLimits{}, fmt.Errorf("parsing env %s: expected a positive number, got %q", v.env, s)
+			}
+			*v.qps = float32(qps)
+		} else {
+			burst, err := strconv.Atoi(s)
+			if err != nil || burst <= 0 {
+				return RateLimits{}, fmt.Errorf("parsing env %s: expected a positive integer, got %q", v.env, s)
+			}
+			*v.burst = burst
+		}
+	}
+	return limits, nil
+}
+
+// WrapWithRateLimits replaces client-go's single rate limit with separate
+// read and write budgets, and counts the requests.
+//
+// The limits are shared by every client made from the config.
+func WrapWithRateLimits(config *rest.Config, limits RateLimits) {
+	read := flowcontrol.NewTokenBucketRateLimiter(limits.ReadQPS, limits.ReadBurst)
+	write := flowcontrol.NewTokenBucketRateLimiter(limits.WriteQPS, limits.WriteBurst)
+
+	// A negative QPS turns off client-go's rate limiter.
+	config.QPS = -1
+	config.RateLimiter = nil
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &rateLimitedTransport{delegate: rt, read: read, write: write}
+	})
+}
+
+type rateLimitedTransport struct {
+	delegate http.RoundTripper
+	read     flowcontrol.RateLimiter
+	write    flowcontrol.RateLimiter
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	verb, resource := requestVerbResource(req)
+	budget, limiter := "write", t.write
+	if verb == "get" || verb == "list" || verb == "watch" {
+		budget, limiter = "read", t.read
+	}
+
+	start := time.Now()
+	err := limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if wait := time.Since(start); wait > time.Millisecond {
+		throttledSeconds.WithLabelValues(budget).Add(wait.Seconds())
+	}
+
+	apiRequests.WithLabelValues(verb, resource).Inc()
+	return t.delegate.RoundTrip(req)
+}
+
+func (t *rateLimitedTransport) WrappedRoundTripper() http.RoundTripper {
+	return t.delegate
+}
+
+// requestVerbResource guesses the API verb and resource (e.g., "list" and
+// "pods") of a request from its method and path. Requests that aren't for
+// a resource, like discovery, have an empty resource.
+func requestVerbResource(req *http.Request) (verb string, resource string) {
+	parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	// /api/v1/... or /apis/group/version/...
+	var rest []string
+	switch {
+	case len(parts) > 2 && parts[0] == "api":
+		rest = parts[2:]
+	case len(parts) > 3 && parts[0] == "apis":
+		rest = parts[3:]
+	}
+	if len(rest) > 2 && rest[0] == "namespaces" {
+		rest = rest[2:]
+	}
+
+	hasName := len(rest) > 1
+	if len(rest) > 0 {
+		resource = rest[0]
+	}
+	if len(rest) > 2 {
+		resource += "/" + rest[2]
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead:
+		if req.URL.Query().Get("watch") == "true" || req.URL.Query().Get("watch") == "1" {
+			return "watch", resource
+		}
+		if hasName || resource == "" {
+			return "get", resource
+		}
+		return "list", resource
+	case http.MethodPost:
+		return "create", resource
+	case http.MethodPut:
+		return "update", resource
+	case http.MethodPatch:
+		return "patch", resource
+	case http.MethodDelete:
+		if hasName {
+			return "delete", resource
+		}
+		return "deletecollection", resource
+	}
+	return strings.ToLower(req.Method), resource
+}
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+func TestRequestVerbResource(t *testing.T) {
+	for _, tc := range []struct {
+		method, url    string
+		verb, resource string
+	}{
+		{"GET", "/api/v1/namespaces/default/pods", "list", "pods"},
+		{"GET", "/api/v1/namespaces/default/pods?watch=true", "watch", "pods"},
+		{"GET", "/api/v1/namespaces/default/pods/web-0", "get", "pods"},
+		{"GET", "/api/v1/namespaces/default/pods/web-0/log", "get", "pods/log"},
+		{"GET", "/api/v1/namespaces", "list", "namespaces"},
+		{"GET", "/api/v1/namespaces/default", "get", "namespaces"},
+		{"GET", "/apis/apps/v1/deployments", "list", "deployments"},
+		{"PATCH", "/apis/apps/v1/namespaces/default/deployments/web", "patch", "deployments"},
+		{"POST", "/api/v1/namespaces/default/configmaps", "create", "configmaps"},
+		{"PUT", "/api/v1/namespaces/default/configmaps/cfg", "update", "configmaps"},
+		{"DELETE", "/api/v1/namespaces/default/configmaps/cfg", "delete", "configmaps"},
+		{"DELETE", "/api/v1/namespaces/default/configmaps", "deletecollection", "configmaps"},
+		{"GET", "/apis", "get", ""},
+		{"GET", "/version", "get", ""},
+	} {
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			verb, resource := requestVerbResource(req)
+			assert.Equal(t, tc.verb, verb)
+			assert.Equal(t, tc.resource, resource)
+		})
+	}
+}
+
+func TestRateLimitsFromEnv(t *testing.T) {
+	limits, err := RateLimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRateLimits, limits)
+
+	t.Setenv("TILT_K8S_WRITE_QPS", "2.5")
+	t.Setenv("TILT_K8S_READ_BURST", "7")
+	limits, err = RateLimitsFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, float32(2.5), limits.WriteQPS)
+	assert.Equal(t, 7, limits.ReadBurst)
+	assert.Equal(t, DefaultRateLimits.ReadQPS, limits.ReadQPS)
+
+	t.Setenv("TILT_K8S_WRITE_BURST", "0")
+	_, err = RateLimitsFromEnv()
+	assert.EqualError(t, err, `parsing env TILT_K8S_WRITE_BURST: expected a positive integer, got "0"`)
+}
+
+func TestRateLimitedTransportUsesWriteBudget(t *testing.T) {
+	tr := &rateLimitedTransport{
+		delegate: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		}),
+		read:  flowcontrol.NewFakeAlwaysRateLimiter(),
+		write: flowcontrol.NewFakeNeverRateLimiter(),
+	}
+
+	resp, err := tr.RoundTrip(httptest.NewRequest("GET", "/api/v1/namespaces/default/pods", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("PATCH", "/apis/apps/v1/namespaces/default/deployments/web", nil).WithContext(ctx)
+	_, err = tr.RoundTrip(req)
+	assert.Error(t, err)
+}
+
+func TestWrapWithRateLimits(t *testing.T) {
+	config := &rest.Config{QPS: 5, Burst: 10}
+	WrapWithRateLimits(config, DefaultRateLimits)
+	assert.Equal(t, float32(-1), config.QPS)
+	require.NotNil(t, config.WrapTransport)
+
+	rt := config.WrapTransport(http.DefaultTransport)
+	_, ok := rt.(*rateLimitedTransport)
+	assert.True(t, ok)
+}
+
+func TestUpsertKey(t *testing.T) {
+	entities, err := ParseYAMLFromString(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  a: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cfg
+data:
+  a: "2"
+`)
+	require.NoError(t, err)
+
+	key1, err := upsertKey(entities[0])
+	require.NoError(t, err)
+	key2, err := upsertKey(entities[1])
+	require.NoError(t, err)
+	same, err := upsertKey(entities[0].DeepCopy())
+	require.NoError(t, err)
+
+	assert.NotEqual(t, key1, key2)
+	assert.Equal(t, key1, same)
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
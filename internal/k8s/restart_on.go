@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tilt sets this annotation on the pod templates of workloads that should
+// restart when a ConfigMap or Secret changes. The value lists each one with
+// the hash of its data, e.g., "configmap/app-config=0f3a9c21d4". A new hash
+// changes the pod template, so the apply rolls the pods.
+const AnnotationRestartOn = "tilt.dev/restart-on"
+
+// A ConfigMap or Secret that a workload uses.
+type ConfigRef struct {
+	// "configmap" or "secret"
+	Kind string
+	Name string
+}
+
+func (r ConfigRef) String() string {
+	return fmt.Sprintf("%s/%s", r.Kind, r.Name)
+}
+
+// ParseConfigRef parses a reference like "configmap:app-config".
+func ParseConfigRef(s string) (ConfigRef, error) {
+	kind, name, ok := strings.Cut(s, ":")
+	kind = strings.ToLower(kind)
+	if !ok || name == "" || (kind != "configmap" && kind != "secret") {
+		return ConfigRef{}, fmt.Errorf("invalid config reference %q. Expected configmap:NAME or secret:NAME", s)
+	}
+	return ConfigRef{Kind: kind, Name: name}, nil
+}
+
+func FormatRestartOn(hashes map[ConfigRef]string) string {
+	entries := make([]string, 0, len(hashes))
+	for ref, hash := range hashes {
+		entries = append(entries, fmt.Sprintf("%s=%s", ref, hash))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func ParseRestartOn(s string) map[ConfigRef]string {
+	result := make(map[ConfigRef]string)
+	for _, entry := range strings.Split(s, ",") {
+		ref, hash, ok := strings.Cut(entry, "=")
+		if !ok {
+			continue
+		}
+		kind, name, ok := strings.Cut(ref, "/")
+		if !ok {
+			continue
+		}
+		result[ConfigRef{Kind: kind, Name: name}] = hash
+	}
+	return result
+}
+
+// ReadRestartOn returns the config hashes on the entity's pod templates.
+func ReadRestartOn(entity K8sEntity) (map[ConfigRef]string, error) {
+	templates, err := ExtractPodTemplateSpec(&entity)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[ConfigRef]string)
+	for _, t := range templates {
+		for ref, hash := range ParseRestartOn(t.Annotations[AnnotationRestartOn]) {
+			result[ref] = hash
+		}
+	}
+	return result, nil
+}
+
+// ChangedConfigs returns the configs in both sets whose hashes differ.
+func ChangedConfigs(old, new map[ConfigRef]string) []ConfigRef {
+	var result []ConfigRef
+	for ref, hash := range new {
+		oldHash, ok := old[ref]
+		if ok && oldHash != hash {
+			result = append(result, ref)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].String() < result[j].String() })
+	return result
+}
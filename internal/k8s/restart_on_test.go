@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigRef(t *testing.T) {
+	ref, err := ParseConfigRef("ConfigMap:app-config")
+	require.NoError(t, err)
+	assert.Equal(t, ConfigRef{Kind: "configmap", Name: "app-config"}, ref)
+
+	_, err = ParseConfigRef("deployment:api")
+	assert.Error(t, err)
+	_, err = ParseConfigRef("secret")
+	assert.Error(t, err)
+}
+
+func TestRestartOnRoundTrip(t *testing.T) {
+	hashes := map[ConfigRef]string{
+		{Kind: "secret", Name: "db"}:            "222",
+		{Kind: "configmap", Name: "app-config"}: "111",
+	}
+	s := FormatRestartOn(hashes)
+	assert.Equal(t, "configmap/app-config=111,secret/db=222", s)
+	assert.Equal(t, hashes, ParseRestartOn(s))
+	assert.Empty(t, ParseRestartOn(""))
+}
+
+func TestChangedConfigs(t *testing.T) {
+	old := ParseRestartOn("configmap/a=1,configmap/b=2,secret/c=3")
+	new := ParseRestartOn("configmap/a=1,configmap/b=5,secret/c=6,secret/d=7")
+	assert.Equal(t, []ConfigRef{
+		{Kind: "configmap", Name: "b"},
+		{Kind: "secret", Name: "c"},
+	}, ChangedConfigs(old, new))
+}
@@ -0,0 +1,416 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-openapi/pkg/util/proto/validation"
+	"k8s.io/kubectl/pkg/util/openapi"
+)
+
+// Set this annotation to "true" on an object to skip schema validation.
+const AnnotationSkipSchemaValidation = "tilt.dev/skip-schema-validation"
+
+// Fetching the OpenAPI schema shouldn't hold up a Tiltfile load for long
+// when the cluster is slow or unreachable.
+const schemaFetchTimeout = 10 * time.Second
+
+// A field in an object that doesn't match the schema of its kind.
+type SchemaViolation struct {
+	Kind      string
+	Name      string
+	Namespace string
+
+	// The path of the field in the object, e.g., "spec.replicas"
+	Path string
+
+	// e.g., `unknown field "spec.replica"` or
+	// `invalid type for "spec.replicas": got string, expected integer`
+	Message string
+}
+
+func unknownFieldViolation(path string) SchemaViolation {
+	return SchemaViolation{
+		Path:    path,
+		Message: fmt.Sprintf("unknown field %q", path),
+	}
+}
+
+func invalidTypeViolation(path, actual, expected string) SchemaViolation {
+	return SchemaViolation{
+		Path:    path,
+		Message: fmt.Sprintf("invalid type for %q: got %s, expected %s", path, actual, expected),
+	}
+}
+
+// The subset of the discovery client that we need to fetch schemas.
+type schemaDiscovery interface {
+	discovery.ServerVersionInterface
+	discovery.OpenAPISchemaInterface
+}
+
+// Validates objects against the OpenAPI schema that the cluster serves.
+//
+// The parsed schema is cached by server version, so it's only fetched again
+// when the cluster is upgraded. If there's no cluster, or the schema can't be
+// fetched, objects are validated against the schemas compiled into Tilt.
+type SchemaValidator struct {
+	discovery schemaDiscovery
+
+	mu        sync.Mutex
+	resources map[string]openapi.Resources
+}
+
+func NewSchemaValidator(discovery schemaDiscovery) *SchemaValidator {
+	return &SchemaValidator{
+		discovery: discovery,
+		resources: make(map[string]openapi.Resources),
+	}
+}
+
+func ProvideSchemaValidator(maybeRESTConfig RESTConfigOrError) *SchemaValidator {
+	if maybeRESTConfig.Error != nil {
+		return NewSchemaValidator(nil)
+	}
+
+	config := rest.CopyConfig(maybeRESTConfig.Config)
+	config.Timeout = schemaFetchTimeout
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return NewSchemaValidator(nil)
+	}
+	return NewSchemaValidator(discoveryClient)
+}
+
+// ValidateYAML checks each object in the YAML against the schema of its kind,
+// and reports the fields that the apiserver would reject or drop (like a
+// `replica` typo in a Deployment spec, or a `replicas: "two"`).
+//
+// Objects of kinds that the cluster doesn't know about are skipped.
+func (v *SchemaValidator) ValidateYAML(yaml string) ([]SchemaViolation, error) {
+	resources, err := v.clusterResources()
+	if err != nil || resources == nil {
+		return ValidateYAMLSchemas(yaml)
+	}
+
+	return validateYAML(yaml, func(obj *unstructured.Unstructured) []SchemaViolation {
+		schema := resources.LookupResource(obj.GroupVersionKind())
+		if schema == nil {
+			return nil
+		}
+
+		var result []SchemaViolation
+		for _, err := range validation.ValidateModel(obj.Object, schema, "") {
+			vErr, ok := err.(validation.ValidationError)
+			if !ok {
+				continue
+			}
+			path := strings.TrimPrefix(vErr.Path, ".")
+			switch e := vErr.Err.(type) {
+			case validation.UnknownFieldError:
+				result = append(result, unknownFieldViolation(joinFieldPath(path, e.Field)))
+			case validation.InvalidTypeError:
+				result = append(result, invalidTypeViolation(path, openAPITypeName(e.Actual), openAPITypeName(e.Expected)))
+			}
+		}
+		return result
+	})
+}
+
+// Returns the parsed schema of the cluster, fetching it if we haven't seen
+// this server version before. Returns nil if there's no cluster.
+func (v *SchemaValidator) clusterResources() (openapi.Resources, error) {
+	if v.discovery == nil {
+		return nil, nil
+	}
+
+	info, err := v.discovery.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	key := serverVersionKey(info)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if resources, ok := v.resources[key]; ok {
+		return resources, nil
+	}
+
+	doc, err := v.discovery.OpenAPISchema()
+	if err != nil {
+		return nil, err
+	}
+	resources, err := parseOpenAPISchema(doc)
+	if err != nil {
+		return nil, err
+	}
+	v.resources[key] = resources
+	return resources, nil
+}
+
+func serverVersionKey(info *version.Info) string {
+	return fmt.Sprintf("%s/%s", info.GitVersion, info.GitCommit)
+}
+
+func parseOpenAPISchema(doc *openapi_v2.Document) (openapi.Resources, error) {
+	resources, err := openapi.NewOpenAPIData(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI schema: %v", err)
+	}
+	return resources, nil
+}
+
+// ValidateYAMLSchemas checks each object in the YAML against the schema of
+// its kind, like SchemaValidator.ValidateYAML.
+//
+// Only built-in kinds are checked, against the schemas compiled into Tilt,
+// so this doesn't need a cluster. Custom resources are skipped.
+func ValidateYAMLSchemas(yaml string) ([]SchemaViolation, error) {
+	return validateYAML(yaml, func(obj *unstructured.Unstructured) []SchemaViolation {
+		typed, err := scheme.Scheme.New(obj.GroupVersionKind())
+		if err != nil {
+			return nil
+		}
+
+		var result []SchemaViolation
+		validateBundledValue(obj.Object, reflect.TypeOf(typed).Elem(), "", &result)
+		return result
+	})
+}
+
+// Decodes each object in the YAML, and validates the ones that don't skip
+// validation.
+func validateYAML(yaml string, validate func(obj *unstructured.Unstructured) []SchemaViolation) ([]SchemaViolation, error) {
+	decoder := yamlDecoder.NewYAMLOrJSONDecoder(bufio.NewReader(strings.NewReader(yaml)), 4096)
+
+	var result []SchemaViolation
+	for {
+		ext := runtime.RawExtension{}
+		if err := decoder.Decode(&ext); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		raw := bytes.TrimSpace(ext.Raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		// Malformed objects are handled by ParseYAML.
+		var unst unstructured.Unstructured
+		_, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, &unst)
+		if err != nil || unst.IsList() || unst.GetAnnotations()[AnnotationSkipSchemaValidation] == "true" {
+			continue
+		}
+
+		for _, v := range validate(&unst) {
+			v.Kind = unst.GetKind()
+			v.Name = unst.GetName()
+			v.Namespace = unst.GetNamespace()
+			result = append(result, v)
+		}
+	}
+	return result, nil
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(metav1.Time{})
+	microTimeType       = reflect.TypeOf(metav1.MicroTime{})
+	durationType        = reflect.TypeOf(metav1.Duration{})
+	quantityType        = reflect.TypeOf(resource.Quantity{})
+	intOrStringType     = reflect.TypeOf(intstr.IntOrString{})
+)
+
+// Walks a value decoded from JSON alongside the Go type that it would be
+// decoded into, and reports the fields that the type doesn't have and the
+// values that the type can't hold.
+//
+// Unlike a strict decode, this keeps going after a type mismatch, so one bad
+// value doesn't hide the problems with the rest of the object.
+func validateBundledValue(value interface{}, t reflect.Type, path string, result *[]SchemaViolation) {
+	if value == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	actual := jsonTypeName(value)
+	expectType := func(expected ...string) {
+		for _, e := range expected {
+			if actual == e || (e == "number" && actual == "integer") {
+				return
+			}
+		}
+		*result = append(*result, invalidTypeViolation(path, actual, strings.Join(expected, " or ")))
+	}
+
+	switch t {
+	case timeType, microTimeType, durationType:
+		expectType("string")
+		return
+	case quantityType, intOrStringType:
+		expectType("string", "integer")
+		return
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		// Types with custom decoding (like RawExtension) can hold anything.
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			expectType("object")
+			return
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldPath := joinFieldPath(path, k)
+			fieldType, ok := fields[k]
+			if !ok {
+				*result = append(*result, unknownFieldViolation(fieldPath))
+				continue
+			}
+			validateBundledValue(obj[k], fieldType, fieldPath, result)
+		}
+
+	case reflect.Map:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			expectType("object")
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			validateBundledValue(obj[k], t.Elem(), joinFieldPath(path, k), result)
+		}
+
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are base64 strings.
+			expectType("string")
+			return
+		}
+		arr, ok := value.([]interface{})
+		if !ok {
+			expectType("array")
+			return
+		}
+		for i, item := range arr {
+			validateBundledValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), result)
+		}
+
+	case reflect.String:
+		expectType("string")
+	case reflect.Bool:
+		expectType("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		expectType("integer")
+	case reflect.Float32, reflect.Float64:
+		expectType("number")
+	}
+}
+
+// Returns the types of the fields of a struct by their JSON name, including
+// the fields of inlined structs.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	result := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" && (opts == "inline" || tag == "") {
+			embedded := f.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for k, v := range jsonFields(embedded) {
+					result[k] = v
+				}
+				continue
+			}
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		result[name] = f.Type
+	}
+	return result
+}
+
+// Returns the JSON type of a value decoded into an unstructured object.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int32, int64:
+		return "integer"
+	case float32, float64:
+		return "number"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// The OpenAPI validator calls objects "map".
+func openAPITypeName(t string) string {
+	if t == "map" {
+		return "object"
+	}
+	return t
+}
+
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
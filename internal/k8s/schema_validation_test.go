@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"fmt"
+
+	"testing"
+
+	openapi_v2 "github.com/google/gnostic/openapiv2"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/k8s/testyaml"
+)
+
+func TestValidateYAMLSchemas(t *testing.T) {
+	violations, err := ValidateYAMLSchemas(`
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+  namespace: bar
+spec:
+  replica: 2
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo
+        securityContext:
+          runAsUsr: 1000
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: foo
+spec:
+  ports:
+  - port: 80
+`)
+	require.NoError(t, err)
+	assert.Equal(t, []SchemaViolation{
+		{Kind: "Deployment", Name: "foo", Namespace: "bar", Path: "spec.replica", Message: `unknown field "spec.replica"`},
+		{Kind: "Deployment", Name: "foo", Namespace: "bar", Path: "spec.template.spec.containers[0].securityContext.runAsUsr", Message: `unknown field "spec.template.spec.containers[0].securityContext.runAsUsr"`},
+	}, violations)
+}
+
+func TestValidateYAMLSchemasSkipsCustomResources(t *testing.T) {
+	violations, err := ValidateYAMLSchemas(testyaml.CRDYAML)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestValidateYAMLSchemasSkipAnnotation(t *testing.T) {
+	violations, err := ValidateYAMLSchemas(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: foo
+  annotations:
+    tilt.dev/skip-schema-validation: "true"
+extra: true
+`)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestValidateYAMLSchemasTypeMismatch(t *testing.T) {
+	violations, err := ValidateYAMLSchemas(deploymentWithBadFields)
+	require.NoError(t, err)
+	assert.Equal(t, []SchemaViolation{
+		{Kind: "Deployment", Name: "foo", Path: "spec.replica", Message: `unknown field "spec.replica"`},
+		{Kind: "Deployment", Name: "foo", Path: "spec.replicas", Message: `invalid type for "spec.replicas": got string, expected integer`},
+		{Kind: "Deployment", Name: "foo", Path: "spec.template.spec.containers[0].resources.limits.cpu", Message: `invalid type for "spec.template.spec.containers[0].resources.limits.cpu": got boolean, expected string or integer`},
+	}, violations)
+}
+
+func TestSchemaValidatorOffline(t *testing.T) {
+	v := NewSchemaValidator(nil)
+	violations, err := v.ValidateYAML(deploymentWithBadFields)
+	require.NoError(t, err)
+	assert.Len(t, violations, 3)
+}
+
+func TestSchemaValidatorClusterSchema(t *testing.T) {
+	d := newFakeSchemaDiscovery(t)
+	v := NewSchemaValidator(d)
+	violations, err := v.ValidateYAML(deploymentWithBadFields)
+	require.NoError(t, err)
+	assert.Equal(t, []SchemaViolation{
+		{Kind: "Deployment", Name: "foo", Path: "spec.replica", Message: `unknown field "spec.replica"`},
+		{Kind: "Deployment", Name: "foo", Path: "spec.replicas", Message: `invalid type for "spec.replicas": got string, expected integer`},
+	}, violations)
+
+	// Kinds that the cluster doesn't know about are skipped.
+	violations, err = v.ValidateYAML(testyaml.CRDYAML)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	assert.Equal(t, 1, d.schemaFetches, "schema should be cached for the server version")
+
+	d.version.GitVersion = "v1.28.0"
+	_, err = v.ValidateYAML(deploymentWithBadFields)
+	require.NoError(t, err)
+	assert.Equal(t, 2, d.schemaFetches, "schema should be fetched again for a new server version")
+}
+
+func TestSchemaValidatorFallsBackWhenSchemaUnavailable(t *testing.T) {
+	d := newFakeSchemaDiscovery(t)
+	d.schemaErr = fmt.Errorf("forbidden")
+	v := NewSchemaValidator(d)
+	violations, err := v.ValidateYAML(deploymentWithBadFields)
+	require.NoError(t, err)
+	assert.Len(t, violations, 3)
+}
+
+const deploymentWithBadFields = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  replica: 2
+  replicas: "two"
+  template:
+    spec:
+      containers:
+      - name: foo
+        image: foo
+        resources:
+          limits:
+            cpu: true
+`
+
+// A tiny slice of the schema that an apiserver serves, with just enough of
+// a Deployment to validate against.
+const fakeOpenAPISchema = `{
+  "swagger": "2.0",
+  "info": {"title": "Kubernetes", "version": "v1.27.0"},
+  "paths": {},
+  "definitions": {
+    "io.k8s.api.apps.v1.Deployment": {
+      "type": "object",
+      "properties": {
+        "apiVersion": {"type": "string"},
+        "kind": {"type": "string"},
+        "metadata": {"type": "object", "additionalProperties": {}},
+        "spec": {"$ref": "#/definitions/io.k8s.api.apps.v1.DeploymentSpec"}
+      },
+      "x-kubernetes-group-version-kind": [{"group": "apps", "kind": "Deployment", "version": "v1"}]
+    },
+    "io.k8s.api.apps.v1.DeploymentSpec": {
+      "type": "object",
+      "properties": {
+        "replicas": {"type": "integer", "format": "int32"},
+        "template": {"type": "object", "additionalProperties": {}}
+      }
+    }
+  }
+}`
+
+type fakeSchemaDiscovery struct {
+	version       version.Info
+	doc           *openapi_v2.Document
+	schemaErr     error
+	schemaFetches int
+}
+
+func newFakeSchemaDiscovery(t *testing.T) *fakeSchemaDiscovery {
+	doc, err := openapi_v2.ParseDocument([]byte(fakeOpenAPISchema))
+	require.NoError(t, err)
+	return &fakeSchemaDiscovery{
+		version: version.Info{GitVersion: "v1.27.0"},
+		doc:     doc,
+	}
+}
+
+func (d *fakeSchemaDiscovery) ServerVersion() (*version.Info, error) {
+	v := d.version
+	return &v, nil
+}
+
+func (d *fakeSchemaDiscovery) OpenAPISchema() (*openapi_v2.Document, error) {
+	d.schemaFetches++
+	if d.schemaErr != nil {
+		return nil, d.schemaErr
+	}
+	return d.doc, nil
+}
@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+// A volume mounted into a container.
+type ContainerVolumeMount struct {
+	Container  container.Name
+	VolumeName string
+
+	// A short description of the volume source (e.g., "emptyDir",
+	// "persistentVolumeClaim"). Empty if the pod spec doesn't declare
+	// the volume.
+	VolumeSource string
+	MountPath    string
+}
+
+// VolumeMountsForImage returns the volume mounts of every container
+// in the entity whose image matches the selector.
+func VolumeMountsForImage(entity K8sEntity, selector container.RefSelector) ([]ContainerVolumeMount, error) {
+	podSpecs, err := ExtractPods(&entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ContainerVolumeMount
+	for _, podSpec := range podSpecs {
+		sources := make(map[string]string, len(podSpec.Volumes))
+		for _, v := range podSpec.Volumes {
+			sources[v.Name] = volumeSourceType(v.VolumeSource)
+		}
+
+		for _, c := range podSpec.Containers {
+			ref, err := container.ParseNamed(c.Image)
+			if err != nil || !selector.Matches(ref) {
+				continue
+			}
+
+			for _, m := range c.VolumeMounts {
+				result = append(result, ContainerVolumeMount{
+					Container:    container.Name(c.Name),
+					VolumeName:   m.Name,
+					VolumeSource: sources[m.Name],
+					MountPath:    m.MountPath,
+				})
+			}
+		}
+	}
+	return result, nil
+}
+
+func volumeSourceType(s v1.VolumeSource) string {
+	switch {
+	case s.EmptyDir != nil:
+		return "emptyDir"
+	case s.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim"
+	case s.HostPath != nil:
+		return "hostPath"
+	case s.ConfigMap != nil:
+		return "configMap"
+	case s.Secret != nil:
+		return "secret"
+	case s.Projected != nil:
+		return "projected"
+	case s.DownwardAPI != nil:
+		return "downwardAPI"
+	case s.CSI != nil:
+		return "csi"
+	case s.Ephemeral != nil:
+		return "ephemeral"
+	case s.NFS != nil:
+		return "nfs"
+	}
+	return "volume"
+}
@@ -0,0 +1,49 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/container"
+)
+
+func TestVolumeMountsForImage(t *testing.T) {
+	entities, err := ParseYAMLFromString(`
+apiVersion: v1
+kind: Pod
+metadata:
+  name: foo
+spec:
+  containers:
+  - name: app
+    image: gcr.io/foo
+    volumeMounts:
+    - name: data
+      mountPath: /data
+    - name: config
+      mountPath: /etc/app
+  - name: sidecar
+    image: gcr.io/sidecar
+    volumeMounts:
+    - name: data
+      mountPath: /var/data
+  volumes:
+  - name: data
+    persistentVolumeClaim:
+      claimName: data
+  - name: config
+    configMap:
+      name: app-config
+`)
+	require.NoError(t, err)
+	require.Len(t, entities, 1)
+
+	mounts, err := VolumeMountsForImage(entities[0], container.MustParseSelector("gcr.io/foo"))
+	require.NoError(t, err)
+	assert.Equal(t, []ContainerVolumeMount{
+		{Container: "app", VolumeName: "data", VolumeSource: "persistentVolumeClaim", MountPath: "/data"},
+		{Container: "app", VolumeName: "config", VolumeSource: "configMap", MountPath: "/etc/app"},
+	}, mounts)
+}
@@ -8,7 +8,6 @@ import (
 	"os/exec"
 	"strings"
 	"sync"
-	"syscall"
 	"testing"
 
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -90,14 +89,12 @@ func (p ProcessExecer) Run(ctx context.Context, cmd model.Cmd, runIO RunIO) (int
 		return -1, err
 	}
 
-	osCmd.SysProcAttr = &syscall.SysProcAttr{}
-	procutil.SetOptNewProcessGroup(osCmd.SysProcAttr)
-
 	osCmd.Stdin = runIO.Stdin
 	osCmd.Stdout = runIO.Stdout
 	osCmd.Stderr = runIO.Stderr
 
-	if err := osCmd.Start(); err != nil {
+	supervisor := p.env.Supervisor()
+	if err := supervisor.Start(osCmd); err != nil {
 		return -1, err
 	}
 
@@ -122,6 +119,7 @@ func (p ProcessExecer) Run(ctx context.Context, cmd model.Cmd, runIO RunIO) (int
 	// and it's preferable vs using Process::Wait() since that complicates I/O handling (Cmd::Wait() will
 	// ensure all I/O is complete before returning)
 	err = osCmd.Wait()
+	supervisor.Done(osCmd)
 	if exitErr, ok := err.(*exec.ExitError); ok {
 		handleProcessExit.Do(
 			func() {
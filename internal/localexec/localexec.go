@@ -15,19 +15,22 @@ import (
 
 // Common environment for local exec commands.
 type Env struct {
-	pairs   []kvPair
-	environ func() []string
+	pairs      []kvPair
+	environ    func() []string
+	supervisor *Supervisor
 }
 
 func EmptyEnv() *Env {
 	return &Env{
-		environ: os.Environ,
+		environ:    os.Environ,
+		supervisor: NewSupervisor(),
 	}
 }
 
 func DefaultEnv(port model.WebPort, host model.WebHost) *Env {
 	e := &Env{
-		environ: os.Environ,
+		environ:    os.Environ,
+		supervisor: NewSupervisor(),
 	}
 
 	// if Tilt was invoked with `tilt up --port=XXXXX`, local() calls to use the Tilt API will fail due to trying to
@@ -43,6 +46,11 @@ func DefaultEnv(port model.WebPort, host model.WebHost) *Env {
 	return e
 }
 
+// Supervisor starts and tracks the processes run with this environment.
+func (e *Env) Supervisor() *Supervisor {
+	return e.supervisor
+}
+
 func (e *Env) Add(k, v string) {
 	e.pairs = append(e.pairs, kvPair{Key: k, Value: v})
 }
@@ -0,0 +1,222 @@
+package localexec
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+// How long ReclaimPorts waits for a killed process's port to close.
+const reclaimTimeout = 2 * time.Second
+
+// A port that a Tilt session listens on, as persisted in the registry.
+type RegisteredPort struct {
+	Port int `json:"port"`
+
+	// What the port is for, like "web UI" or "port-forward frontend".
+	Purpose string `json:"purpose"`
+
+	// The port was picked at random, so the next session won't need it.
+	Dynamic bool `json:"dynamic,omitempty"`
+}
+
+func (p RegisteredPort) String() string {
+	return fmt.Sprintf("port %d (%s)", p.Port, p.Purpose)
+}
+
+// A process listening on a port.
+type PortHolder struct {
+	PID     int
+	Command string
+}
+
+func (h PortHolder) String() string {
+	if h.Command == "" {
+		return fmt.Sprintf("pid %d", h.PID)
+	}
+	return fmt.Sprintf("pid %d (%s)", h.PID, h.Command)
+}
+
+// FindPortHolder looks up the process listening on a TCP port.
+//
+// Returns false if no process is listening, or if the process can't be
+// found, like when it belongs to another user, or outside of Linux.
+func FindPortHolder(port int) (PortHolder, bool) {
+	pid := findListeningPID(port)
+	if pid == 0 {
+		return PortHolder{}, false
+	}
+	return PortHolder{PID: pid, Command: processCommand(pid)}, true
+}
+
+// AddPort registers a port that the session listens on.
+func (s *Supervisor) AddPort(p RegisteredPort) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ports[p.Port] = p
+	s.persistLocked()
+}
+
+// RemovePort unregisters a port once the session stops listening on it.
+func (s *Supervisor) RemovePort(port int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.ports[port]; !ok {
+		return
+	}
+	delete(s.ports, port)
+	s.persistLocked()
+}
+
+// RegisteredPorts returns the registered ports, in order.
+func (s *Supervisor) RegisteredPorts() []RegisteredPort {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedPortsLocked()
+}
+
+func (s *Supervisor) sortedPortsLocked() []RegisteredPort {
+	result := make([]RegisteredPort, 0, len(s.ports))
+	for _, p := range s.ports {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Port < result[j].Port })
+	return result
+}
+
+// ReclaimPorts frees the ports that the previous session using the registry
+// file listened on and that are still held, so that this session can listen
+// on them again. Call it before the session listens on anything, and before
+// Open replaces the registry.
+//
+// A port held by a process group that the previous session started is
+// reclaimed by killing the group. A port held by any other process is
+// logged with the process that holds it, and returned, so that the caller
+// can fall back to another port.
+//
+// If the previous session is still running, its ports aren't touched.
+// Dynamic ports are never returned, since the next session picks new ones.
+func ReclaimPorts(ctx context.Context, file string) []RegisteredPort {
+	l := logger.Get(ctx)
+	prev, err := readRegistryFile(file)
+	if err != nil {
+		l.Debugf("Reading ports from previous session: %v", err)
+		return nil
+	}
+
+	if prev.BootID == bootID() && ownerRunning(prev) {
+		l.Debugf("Another Tilt session (pid %d) is still using %s. Leaving its ports alone", prev.OwnerPID, file)
+		return nil
+	}
+
+	// The previous session's process groups that are still running. PIDs
+	// from a previous boot mean nothing.
+	leftover := make(map[int]RegisteredProcess)
+	if prev.BootID == bootID() {
+		for _, p := range prev.Processes {
+			if isLeftover(p) {
+				leftover[p.PID] = p
+			}
+		}
+	}
+
+	// Find all the holders first, since killing one group may close
+	// several ports.
+	holders := make([]PortHolder, len(prev.Ports))
+	found := make([]bool, len(prev.Ports))
+	for i, port := range prev.Ports {
+		holders[i], found[i] = FindPortHolder(port.Port)
+	}
+
+	var held []RegisteredPort
+	killed := make(map[int]bool)
+	for i, port := range prev.Ports {
+		holder := holders[i]
+		if !found[i] {
+			if !port.Dynamic && !canListen(port.Port) {
+				l.Infof("Port %d (%s), used by the previous Tilt session, is held by another process",
+					port.Port, port.Purpose)
+				held = append(held, port)
+			}
+			continue
+		}
+
+		group, ok := leftover[processGroup(holder.PID)]
+		if !ok {
+			if !port.Dynamic {
+				l.Infof("Port %d (%s), used by the previous Tilt session, is held by %s, which Tilt didn't start",
+					port.Port, port.Purpose, holder)
+				held = append(held, port)
+			}
+			continue
+		}
+
+		if !killed[group.PID] {
+			err := killProcessGroup(group.PID)
+			if err != nil {
+				l.Infof("Failed to reclaim %s from %s: %v", port, holder, err)
+				if !port.Dynamic {
+					held = append(held, port)
+				}
+				continue
+			}
+			killed[group.PID] = true
+		}
+		if !waitForPortClosed(port.Port, reclaimTimeout) {
+			l.Infof("Killed %s, but %s is still in use", holder, port)
+			if !port.Dynamic {
+				held = append(held, port)
+			}
+			continue
+		}
+		l.Infof("Reclaimed %s from %s, left running by a previous Tilt session", port, holder)
+	}
+	return held
+}
+
+// How many ports after a held port AlternatePort tries.
+const alternatePortRange = 10
+
+// AlternatePort finds a port to use instead of one that's held, preferring
+// the ports right after it so that the new port is easy to guess.
+func AlternatePort(port int) (int, error) {
+	for p := port + 1; p <= port+alternatePortRange && p <= 65535; p++ {
+		if canListen(p) {
+			return p, nil
+		}
+	}
+
+	l, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return 0, fmt.Errorf("finding a port to use instead of %d: %v", port, err)
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func waitForPortClosed(port int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if canListen(port) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// canListen checks whether nothing is listening on a port, on any interface.
+func canListen(port int) bool {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	_ = l.Close()
+	return true
+}
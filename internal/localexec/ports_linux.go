@@ -0,0 +1,95 @@
+//go:build linux
+// +build linux
+
+package localexec
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The state of a listening socket in /proc/net/tcp.
+const tcpListen = "0A"
+
+// findListeningPID finds the process with a socket listening on the port,
+// by matching the socket's inode from /proc/net/tcp against the file
+// descriptors of each process. Returns 0 if there isn't one that's visible.
+func findListeningPID(port int) int {
+	inodes := listeningInodes(port)
+	if len(inodes) == 0 {
+		return 0
+	}
+
+	fdDirs, err := filepath.Glob("/proc/[0-9]*/fd")
+	if err != nil {
+		return 0
+	}
+	for _, dir := range fdDirs {
+		pid, err := strconv.Atoi(filepath.Base(filepath.Dir(dir)))
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		fds, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(dir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := strings.CutPrefix(link, "socket:[")
+			if ok && inodes[strings.TrimSuffix(inode, "]")] {
+				return pid
+			}
+		}
+	}
+	return 0
+}
+
+// listeningInodes returns the inodes of the IPv4 and IPv6 sockets listening
+// on the port.
+func listeningInodes(port int) map[string]bool {
+	result := make(map[string]bool)
+	for _, file := range []string{"/proc/net/tcp", "/proc/net/tcp6"} {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Scan() // skip the header
+		for scanner.Scan() {
+			// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 10 || fields[3] != tcpListen {
+				continue
+			}
+			i := strings.LastIndex(fields[1], ":")
+			if i == -1 {
+				continue
+			}
+			localPort, err := strconv.ParseInt(fields[1][i+1:], 16, 32)
+			if err != nil || int(localPort) != port {
+				continue
+			}
+			result[fields[9]] = true
+		}
+		_ = f.Close()
+	}
+	return result
+}
+
+// processCommand returns the command line of a process, or the empty string
+// if it can't be read.
+func processCommand(pid int) string {
+	contents, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return ""
+	}
+	// Arguments are separated by NUL bytes.
+	return strings.ReplaceAll(strings.TrimRight(string(contents), "\x00"), "\x00", " ")
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package localexec
+
+// Outside of Linux, the processes that hold ports aren't looked up.
+
+func findListeningPID(port int) int {
+	return 0
+}
+
+func processCommand(pid int) string {
+	return ""
+}
@@ -0,0 +1,386 @@
+package localexec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+	"github.com/tilt-dev/tilt/pkg/procutil"
+)
+
+// How often the reaper looks for zombie processes.
+const reapInterval = time.Second
+
+// A process group that the Supervisor started, as persisted in the registry.
+type RegisteredProcess struct {
+	// The PID of the process that leads the group. Also the process group ID.
+	PID int `json:"pid"`
+
+	Argv      []string  `json:"argv"`
+	StartTime time.Time `json:"startTime"`
+
+	// When the OS started the leader, in an OS-specific format, so that a
+	// process that reused the PID isn't mistaken for it. Empty if unknown.
+	LeaderStart string `json:"leaderStart,omitempty"`
+
+	// The leader exited, but left other processes running in its group
+	// (e.g., `server &`).
+	Orphaned bool `json:"orphaned,omitempty"`
+}
+
+func (p RegisteredProcess) String() string {
+	return fmt.Sprintf("pid %d (%s)", p.PID, strings.Join(p.Argv, " "))
+}
+
+// The registry, as persisted to disk.
+type registryFile struct {
+	// Identifies the boot of the machine that wrote the registry, since PIDs
+	// from a previous boot mean nothing. Empty if unknown.
+	BootID string `json:"bootID,omitempty"`
+
+	// The Tilt process that wrote the registry, and when the OS started it,
+	// so that a session never cleans up after one that's still running.
+	OwnerPID   int    `json:"ownerPID,omitempty"`
+	OwnerStart string `json:"ownerStart,omitempty"`
+
+	Processes []RegisteredProcess `json:"processes"`
+
+	Ports []RegisteredPort `json:"ports,omitempty"`
+}
+
+// RegistryFile returns where the registry of running processes is persisted
+// for a Tilt session. Sessions are identified by their API server, like
+// their API config, so that a restarted `tilt up` finds the processes of the
+// previous one.
+func RegistryFile(base xdg.Base, name model.APIServerName) (string, error) {
+	return base.StateFile(filepath.Join("processes", string(name)+".json"))
+}
+
+// Supervisor starts the local processes that Tilt runs, each in its own
+// process group, and keeps a registry of the ones that are still running,
+// and of the ports that the session listens on.
+//
+// Once opened with a registry file, the registry is persisted on every
+// change, so that if Tilt exits without stopping its processes (e.g.,
+// because it was killed), the next session can clean them up.
+type Supervisor struct {
+	mu    sync.Mutex
+	file  string
+	procs map[int]RegisteredProcess
+	ports map[int]RegisteredPort
+}
+
+func NewSupervisor() *Supervisor {
+	return &Supervisor{
+		procs: make(map[int]RegisteredProcess),
+		ports: make(map[int]RegisteredPort),
+	}
+}
+
+// Start starts the command in a new process group, and registers it.
+//
+// Callers must wait on the process, and then call Done.
+func (s *Supervisor) Start(c *exec.Cmd) error {
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	procutil.SetOptNewProcessGroup(c.SysProcAttr)
+
+	// Hold the lock while starting, so that the reaper never sees the
+	// process before it's registered.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := c.Start()
+	if err != nil {
+		return err
+	}
+
+	pid := c.Process.Pid
+	s.procs[pid] = RegisteredProcess{
+		PID:         pid,
+		Argv:        c.Args,
+		StartTime:   time.Now(),
+		LeaderStart: processStartTime(pid),
+	}
+	s.persistLocked()
+	return nil
+}
+
+// Done unregisters a command after its process has been waited on.
+//
+// If the process left others running in its group, the group stays
+// registered until they exit, so that it's cleaned up with the session.
+func (s *Supervisor) Done(c *exec.Cmd) {
+	if c.Process == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pid := c.Process.Pid
+	p, ok := s.procs[pid]
+	if !ok {
+		return
+	}
+	if processGroupAlive(pid) {
+		p.Orphaned = true
+		s.procs[pid] = p
+	} else {
+		delete(s.procs, pid)
+	}
+	s.persistLocked()
+}
+
+// Run is like exec.Cmd.Run, but starts the command with Start, and calls
+// Done once it exits.
+func (s *Supervisor) Run(c *exec.Cmd) error {
+	err := s.Start(c)
+	if err != nil {
+		return err
+	}
+	err = c.Wait()
+	s.Done(c)
+	return err
+}
+
+// Output is like exec.Cmd.Output, but runs the command with Run.
+func (s *Supervisor) Output(c *exec.Cmd) ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+
+	var stderr *bytes.Buffer
+	if c.Stderr == nil {
+		stderr = &bytes.Buffer{}
+		c.Stderr = stderr
+	}
+
+	err := s.Run(c)
+	var exitErr *exec.ExitError
+	if stderr != nil && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// Registered returns the registered process groups, ordered by PID.
+func (s *Supervisor) Registered() []RegisteredProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedLocked()
+}
+
+// Open starts persisting the registry to the given file.
+//
+// Any processes still running from the previous session that used the file
+// are killed first, and logged, unless that session is still running.
+//
+// If Tilt is the init process (e.g., in a container), Open also starts
+// reaping the orphaned processes that get re-parented to it, until the
+// context is done.
+func (s *Supervisor) Open(ctx context.Context, file string) {
+	err := s.cleanupPrevious(ctx, file)
+	if err != nil {
+		logger.Get(ctx).Debugf("Reading processes from previous session: %v", err)
+	}
+
+	s.mu.Lock()
+	s.file = file
+	s.persistLocked()
+	s.mu.Unlock()
+
+	if os.Getpid() == 1 {
+		go s.reapLoop(ctx)
+	}
+}
+
+// cleanupPrevious kills the process groups in the registry file that are
+// still running.
+func (s *Supervisor) cleanupPrevious(ctx context.Context, file string) error {
+	prev, err := readRegistryFile(file)
+	if err != nil {
+		return err
+	}
+	if prev.BootID != bootID() {
+		return nil
+	}
+	if ownerRunning(prev) {
+		logger.Get(ctx).Infof("Another Tilt session (pid %d) is still using %s. Leaving its processes running",
+			prev.OwnerPID, file)
+		return nil
+	}
+
+	var leftover []RegisteredProcess
+	for _, p := range prev.Processes {
+		if isLeftover(p) {
+			leftover = append(leftover, p)
+		}
+	}
+	if len(leftover) == 0 {
+		return nil
+	}
+
+	l := logger.Get(ctx)
+	l.Infof("Cleaning up %d process(es) left running by a previous Tilt session", len(leftover))
+	for _, p := range leftover {
+		err := killProcessGroup(p.PID)
+		if err != nil {
+			l.Infof("  Failed to kill %s: %v", p, err)
+			continue
+		}
+		l.Infof("  Killed %s, started %s", p, p.StartTime.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// readRegistryFile reads a registry persisted by a previous session. A
+// missing file is an empty registry.
+func readRegistryFile(file string) (registryFile, error) {
+	contents, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return registryFile{}, nil
+	} else if err != nil {
+		return registryFile{}, err
+	}
+
+	var result registryFile
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return registryFile{}, fmt.Errorf("%s: %v", file, err)
+	}
+	return result, nil
+}
+
+// ownerRunning checks whether the Tilt process that wrote a registry is still
+// running, like when two sessions share an API server. Its processes aren't
+// leftovers then.
+func ownerRunning(r registryFile) bool {
+	pid := r.OwnerPID
+	if pid <= 1 || pid == os.Getpid() || !processAlive(pid) {
+		return false
+	}
+
+	// Check that the PID wasn't reused by an unrelated process.
+	start := processStartTime(pid)
+	if start != "" && r.OwnerStart != "" && start != r.OwnerStart {
+		return false
+	}
+	return true
+}
+
+// isLeftover checks whether a process group from a previous session is still
+// running, and wasn't replaced by an unrelated process that reused the PID.
+func isLeftover(p RegisteredProcess) bool {
+	if p.PID <= 1 || !processGroupAlive(p.PID) {
+		return false
+	}
+
+	// A PID can't be reused while its process group exists, so if the
+	// leader is still running, it must be the same process.
+	start := processStartTime(p.PID)
+	if start != "" && p.LeaderStart != "" && start != p.LeaderStart {
+		return false
+	}
+	return true
+}
+
+func (s *Supervisor) sortedLocked() []RegisteredProcess {
+	result := make([]RegisteredProcess, 0, len(s.procs))
+	for _, p := range s.procs {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].PID < result[j].PID })
+	return result
+}
+
+// persistLocked writes the registry, dropping the orphaned groups that have
+// since exited.
+//
+// Errors are ignored; losing the registry only means that a crashed
+// session's processes aren't cleaned up.
+func (s *Supervisor) persistLocked() {
+	for pid, p := range s.procs {
+		if p.Orphaned && !processGroupAlive(pid) {
+			delete(s.procs, pid)
+		}
+	}
+
+	if s.file == "" {
+		return
+	}
+
+	contents, err := json.MarshalIndent(registryFile{
+		BootID:     bootID(),
+		OwnerPID:   os.Getpid(),
+		OwnerStart: processStartTime(os.Getpid()),
+		Processes:  s.sortedLocked(),
+		Ports:      s.sortedPortsLocked(),
+	}, "", "  ")
+	if err != nil {
+		return
+	}
+
+	// Write to a temp file and rename, so that a crash never leaves a
+	// partial file.
+	tmp := s.file + ".tmp"
+	err = os.WriteFile(tmp, contents, 0644)
+	if err != nil {
+		return
+	}
+	_ = os.Rename(tmp, s.file)
+}
+
+func (s *Supervisor) reapLoop(ctx context.Context) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	var seen map[int]bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			seen = s.reap(seen)
+		}
+	}
+}
+
+// reap waits on the zombie children that the Supervisor didn't start, and
+// returns the zombies it left for the next pass.
+//
+// A zombie is only reaped once it's been seen on two passes, so that it
+// never races with an exec.Cmd that's about to wait on its own process.
+func (s *Supervisor) reap(seen map[int]bool) map[int]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := make(map[int]bool)
+	for _, pid := range zombieChildren() {
+		if _, ok := s.procs[pid]; ok {
+			continue
+		}
+		if !seen[pid] {
+			next[pid] = true
+			continue
+		}
+		reapProcess(pid)
+	}
+	return next
+}
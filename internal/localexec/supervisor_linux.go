@@ -0,0 +1,79 @@
+//go:build linux
+// +build linux
+
+package localexec
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+func bootID() string {
+	contents, err := os.ReadFile("/proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(contents))
+}
+
+// processStartTime returns when the process started, in clock ticks since
+// boot, from /proc/<pid>/stat.
+func processStartTime(pid int) string {
+	fields := procStatFields(pid)
+	// starttime is field 22, counting from 1; fields start at field 3.
+	if len(fields) < 20 {
+		return ""
+	}
+	return fields[19]
+}
+
+// zombieChildren returns the children of this process that have exited and
+// haven't been waited on.
+func zombieChildren() []int {
+	tasks, err := filepath.Glob("/proc/self/task/*/children")
+	if err != nil {
+		return nil
+	}
+
+	var result []int
+	for _, task := range tasks {
+		contents, err := os.ReadFile(task)
+		if err != nil {
+			continue
+		}
+		for _, s := range strings.Fields(string(contents)) {
+			pid, err := strconv.Atoi(s)
+			if err != nil {
+				continue
+			}
+			fields := procStatFields(pid)
+			if len(fields) > 0 && fields[0] == "Z" {
+				result = append(result, pid)
+			}
+		}
+	}
+	return result
+}
+
+func reapProcess(pid int) {
+	var status syscall.WaitStatus
+	_, _ = syscall.Wait4(pid, &status, syscall.WNOHANG, nil)
+}
+
+// procStatFields returns the fields of /proc/<pid>/stat after the command
+// name, which may contain spaces, so the first field is the state.
+func procStatFields(pid int) []string {
+	contents, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil
+	}
+	s := string(contents)
+	i := strings.LastIndex(s, ")")
+	if i == -1 {
+		return nil
+	}
+	return strings.Fields(s[i+1:])
+}
@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package localexec
+
+// Outside of Linux, PIDs aren't checked against the boot or the process
+// start time, and orphans aren't reaped.
+
+func bootID() string {
+	return ""
+}
+
+func processStartTime(pid int) string {
+	return ""
+}
+
+func zombieChildren() []int {
+	return nil
+}
+
+func reapProcess(pid int) {}
@@ -0,0 +1,515 @@
+//go:build !windows
+// +build !windows
+
+package localexec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/testutils/bufsync"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestSupervisorKillsLeftoverDoubleForkedChild(t *testing.T) {
+	f := newSupervisorFixture(t)
+
+	// The previous session starts a child that double-forks a grandchild,
+	// then dies without stopping either.
+	prev := NewSupervisor()
+	prev.Open(f.ctx, f.file)
+	c := f.startDoubleFork(prev, "sleep 60")
+	grandchild := f.readPid("grandchild.pid")
+
+	require.Len(t, f.readRegistry(), 1)
+
+	next := NewSupervisor()
+	next.Open(f.ctx, f.file)
+
+	f.requireGone(c.Process.Pid)
+	f.requireGone(grandchild)
+	_ = c.Wait()
+
+	assert.Contains(t, f.out.String(), "Cleaning up 1 process(es) left running by a previous Tilt session")
+	assert.Contains(t, f.out.String(), "Killed pid "+strconv.Itoa(c.Process.Pid))
+	assert.Empty(t, f.readRegistry())
+}
+
+func TestSupervisorKeepsOrphanedGroupRegistered(t *testing.T) {
+	f := newSupervisorFixture(t)
+
+	// The child exits right away, leaving the grandchild in its group.
+	prev := NewSupervisor()
+	prev.Open(f.ctx, f.file)
+	c := f.startDoubleFork(prev, "true")
+	grandchild := f.readPid("grandchild.pid")
+	require.NoError(t, c.Wait())
+	prev.Done(c)
+
+	registered := f.readRegistry()
+	require.Len(t, registered, 1)
+	assert.True(t, registered[0].Orphaned)
+
+	next := NewSupervisor()
+	next.Open(f.ctx, f.file)
+
+	f.requireGone(grandchild)
+	assert.Contains(t, f.out.String(), "Killed pid "+strconv.Itoa(c.Process.Pid))
+}
+
+func TestSupervisorDoneUnregisters(t *testing.T) {
+	f := newSupervisorFixture(t)
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+	c := exec.Command("sh", "-c", "exit 0")
+	require.NoError(t, s.Start(c))
+	require.Len(t, s.Registered(), 1)
+
+	require.NoError(t, c.Wait())
+	s.Done(c)
+
+	assert.Empty(t, s.Registered())
+	assert.Empty(t, f.readRegistry())
+}
+
+func TestSupervisorOutput(t *testing.T) {
+	s := NewSupervisor()
+
+	c := exec.Command("sh", "-c", "echo out; echo err >&2; exit 3")
+	out, err := s.Output(c)
+	assert.Equal(t, "out\n", string(out))
+
+	var exitErr *exec.ExitError
+	if assert.ErrorAs(t, err, &exitErr) {
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.Equal(t, "err\n", string(exitErr.Stderr))
+	}
+	assert.Empty(t, s.Registered())
+}
+
+func TestSupervisorIgnoresReusedPid(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("start times are only checked on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	// An unrelated process now has the PID that the previous session's
+	// process had.
+	c := exec.Command("sleep", "60")
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, c.Start())
+	defer func() {
+		_ = c.Process.Kill()
+		_ = c.Wait()
+	}()
+
+	f.writeRegistry(RegisteredProcess{
+		PID:         c.Process.Pid,
+		Argv:        []string{"sleep", "60"},
+		LeaderStart: "1",
+	})
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+
+	assert.NoError(t, syscall.Kill(c.Process.Pid, 0))
+	assert.NotContains(t, f.out.String(), "Cleaning up")
+}
+
+func TestSupervisorIgnoresPreviousBoot(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("boots are only checked on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	c := exec.Command("sleep", "60")
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, c.Start())
+	defer func() {
+		_ = c.Process.Kill()
+		_ = c.Wait()
+	}()
+
+	contents, err := json.Marshal(registryFile{
+		BootID:    "some-other-boot",
+		Processes: []RegisteredProcess{{PID: c.Process.Pid, Argv: []string{"sleep", "60"}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(f.file, contents, 0644))
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+
+	assert.NoError(t, syscall.Kill(c.Process.Pid, 0))
+}
+
+func TestSupervisorLeavesProcessesOfRunningOwner(t *testing.T) {
+	f := newSupervisorFixture(t)
+
+	// Stands in for another Tilt session that's still running.
+	owner := exec.Command("sleep", "60")
+	require.NoError(t, owner.Start())
+	defer func() {
+		_ = owner.Process.Kill()
+		_ = owner.Wait()
+	}()
+
+	c := exec.Command("sleep", "60")
+	c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	require.NoError(t, c.Start())
+	defer func() {
+		_ = c.Process.Kill()
+		_ = c.Wait()
+	}()
+
+	contents, err := json.Marshal(registryFile{
+		BootID:     bootID(),
+		OwnerPID:   owner.Process.Pid,
+		OwnerStart: processStartTime(owner.Process.Pid),
+		Processes: []RegisteredProcess{{
+			PID:         c.Process.Pid,
+			Argv:        []string{"sleep", "60"},
+			LeaderStart: processStartTime(c.Process.Pid),
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(f.file, contents, 0644))
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+
+	assert.NoError(t, syscall.Kill(c.Process.Pid, 0))
+	assert.Contains(t, f.out.String(),
+		fmt.Sprintf("Another Tilt session (pid %d) is still using", owner.Process.Pid))
+	assert.NotContains(t, f.out.String(), "Cleaning up")
+}
+
+func TestSupervisorCorruptRegistry(t *testing.T) {
+	f := newSupervisorFixture(t)
+	require.NoError(t, os.WriteFile(f.file, []byte("{"), 0644))
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+
+	assert.Empty(t, f.readRegistry())
+}
+
+func TestSupervisorReapsUnsupervisedZombies(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("zombies are only reaped on Linux")
+	}
+	s := NewSupervisor()
+
+	// Stands in for an orphan re-parented to Tilt.
+	orphan := exec.Command("true")
+	require.NoError(t, orphan.Start())
+
+	// Exits too, but Tilt will wait on it.
+	supervised := exec.Command("true")
+	require.NoError(t, s.Start(supervised))
+
+	requireZombie(t, orphan.Process.Pid)
+	requireZombie(t, supervised.Process.Pid)
+
+	// The first pass only notes the zombie.
+	seen := s.reap(nil)
+	assert.Equal(t, map[int]bool{orphan.Process.Pid: true}, seen)
+	assert.Equal(t, "Z", processState(orphan.Process.Pid))
+
+	s.reap(seen)
+	assert.Equal(t, "", processState(orphan.Process.Pid))
+
+	// The supervised process is left for its own Wait.
+	assert.NoError(t, supervised.Wait())
+	s.Done(supervised)
+}
+
+func TestSupervisorPersistsPorts(t *testing.T) {
+	f := newSupervisorFixture(t)
+
+	s := NewSupervisor()
+	s.Open(f.ctx, f.file)
+	s.AddPort(RegisteredPort{Port: 10350, Purpose: "web UI"})
+	s.AddPort(RegisteredPort{Port: 8080, Purpose: "port-forward frontend"})
+	s.AddPort(RegisteredPort{Port: 37000, Purpose: "API server", Dynamic: true})
+	s.RemovePort(8080)
+
+	expected := []RegisteredPort{
+		{Port: 10350, Purpose: "web UI"},
+		{Port: 37000, Purpose: "API server", Dynamic: true},
+	}
+	assert.Equal(t, expected, s.RegisteredPorts())
+	assert.Equal(t, expected, f.readRegistryFile().Ports)
+}
+
+func TestReclaimPortsKillsLeftoverHolder(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("port holders are only looked up on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	// The previous session starts a process that listens on a port, and
+	// dies without stopping it.
+	prev := NewSupervisor()
+	prev.Open(f.ctx, f.file)
+	c, port := f.startListener(prev.Start)
+	prev.AddPort(RegisteredPort{Port: port, Purpose: "port-forward frontend"})
+
+	held := ReclaimPorts(f.ctx, f.file)
+
+	assert.Empty(t, held)
+	f.requireGone(c.Process.Pid)
+	_ = c.Wait()
+	assert.Contains(t, f.out.String(), fmt.Sprintf(
+		"Reclaimed port %d (port-forward frontend) from pid %d (sleep 60), left running by a previous Tilt session",
+		port, c.Process.Pid))
+	assert.True(t, canListen(port))
+}
+
+func TestReclaimPortsReportsUnrelatedHolder(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("port holders are only looked up on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	c, port := f.startListener(func(c *exec.Cmd) error {
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return c.Start()
+	})
+	f.writeRegistryFile(registryFile{
+		BootID: bootID(),
+		Ports: []RegisteredPort{
+			{Port: port, Purpose: "web UI"},
+		},
+	})
+
+	held := ReclaimPorts(f.ctx, f.file)
+
+	assert.Equal(t, []RegisteredPort{{Port: port, Purpose: "web UI"}}, held)
+	assert.NoError(t, syscall.Kill(c.Process.Pid, 0))
+	assert.Contains(t, f.out.String(), fmt.Sprintf(
+		"Port %d (web UI), used by the previous Tilt session, is held by pid %d (sleep 60), which Tilt didn't start",
+		port, c.Process.Pid))
+}
+
+func TestReclaimPortsLeavesPortsOfRunningOwner(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("port holders are only looked up on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	// Stands in for another Tilt session that's still running.
+	owner := exec.Command("sleep", "60")
+	require.NoError(t, owner.Start())
+	defer func() {
+		_ = owner.Process.Kill()
+		_ = owner.Wait()
+	}()
+
+	c, port := f.startListener(func(c *exec.Cmd) error {
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return c.Start()
+	})
+	f.writeRegistryFile(registryFile{
+		BootID:     bootID(),
+		OwnerPID:   owner.Process.Pid,
+		OwnerStart: processStartTime(owner.Process.Pid),
+		Processes: []RegisteredProcess{{
+			PID:         c.Process.Pid,
+			Argv:        []string{"sleep", "60"},
+			LeaderStart: processStartTime(c.Process.Pid),
+		}},
+		Ports: []RegisteredPort{
+			{Port: port, Purpose: "port-forward frontend"},
+		},
+	})
+
+	held := ReclaimPorts(f.ctx, f.file)
+
+	assert.Empty(t, held)
+	assert.NoError(t, syscall.Kill(c.Process.Pid, 0))
+	assert.NotContains(t, f.out.String(), "Reclaimed")
+}
+
+func TestReclaimPortsIgnoresDynamicPorts(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("port holders are only looked up on Linux")
+	}
+	f := newSupervisorFixture(t)
+
+	_, port := f.startListener(func(c *exec.Cmd) error {
+		c.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+		return c.Start()
+	})
+	f.writeRegistryFile(registryFile{
+		BootID: bootID(),
+		Ports: []RegisteredPort{
+			{Port: port, Purpose: "API server", Dynamic: true},
+		},
+	})
+
+	held := ReclaimPorts(f.ctx, f.file)
+
+	assert.Empty(t, held)
+	assert.Empty(t, f.out.String())
+}
+
+func TestAlternatePort(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer func() { _ = l.Close() }()
+	port := l.Addr().(*net.TCPAddr).Port
+
+	alt, err := AlternatePort(port)
+	require.NoError(t, err)
+	assert.NotEqual(t, port, alt)
+	assert.True(t, canListen(alt))
+}
+
+type supervisorFixture struct {
+	t    *testing.T
+	ctx  context.Context
+	out  *bufsync.ThreadSafeBuffer
+	dir  string
+	file string
+}
+
+func newSupervisorFixture(t *testing.T) *supervisorFixture {
+	out := bufsync.NewThreadSafeBuffer()
+	ctx, cancel := context.WithCancel(logger.WithLogger(context.Background(), logger.NewLogger(logger.InfoLvl, out)))
+	t.Cleanup(cancel)
+
+	dir := t.TempDir()
+	return &supervisorFixture{
+		t:    t,
+		ctx:  ctx,
+		out:  out,
+		dir:  dir,
+		file: filepath.Join(dir, "processes.json"),
+	}
+}
+
+// startDoubleFork starts a child that forks a long-running grandchild into
+// the background and then runs childCmd.
+func (f *supervisorFixture) startDoubleFork(s *Supervisor, childCmd string) *exec.Cmd {
+	f.t.Helper()
+	c := exec.Command("sh", "-c", "(sleep 60 & echo $! > grandchild.pid); "+childCmd)
+	c.Dir = f.dir
+	require.NoError(f.t, s.Start(c))
+	f.t.Cleanup(func() {
+		_ = syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	})
+	return c
+}
+
+func (f *supervisorFixture) readPid(name string) int {
+	f.t.Helper()
+	var pid int
+	require.Eventually(f.t, func() bool {
+		contents, err := os.ReadFile(filepath.Join(f.dir, name))
+		if err != nil {
+			return false
+		}
+		pid, err = strconv.Atoi(strings.TrimSpace(string(contents)))
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	return pid
+}
+
+func (f *supervisorFixture) readRegistry() []RegisteredProcess {
+	f.t.Helper()
+	contents, err := os.ReadFile(f.file)
+	require.NoError(f.t, err)
+	var r registryFile
+	require.NoError(f.t, json.Unmarshal(contents, &r))
+	return r.Processes
+}
+
+func (f *supervisorFixture) writeRegistry(procs ...RegisteredProcess) {
+	f.t.Helper()
+	contents, err := json.Marshal(registryFile{BootID: bootID(), Processes: procs})
+	require.NoError(f.t, err)
+	require.NoError(f.t, os.WriteFile(f.file, contents, 0644))
+}
+
+func (f *supervisorFixture) readRegistryFile() registryFile {
+	f.t.Helper()
+	r, err := readRegistryFile(f.file)
+	require.NoError(f.t, err)
+	return r
+}
+
+func (f *supervisorFixture) writeRegistryFile(r registryFile) {
+	f.t.Helper()
+	contents, err := json.Marshal(r)
+	require.NoError(f.t, err)
+	require.NoError(f.t, os.WriteFile(f.file, contents, 0644))
+}
+
+// startListener starts a process that holds a listening socket, handed to
+// it by the test, which then closes its own copy.
+func (f *supervisorFixture) startListener(start func(c *exec.Cmd) error) (*exec.Cmd, int) {
+	f.t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(f.t, err)
+	port := l.Addr().(*net.TCPAddr).Port
+	file, err := l.(*net.TCPListener).File()
+	require.NoError(f.t, err)
+
+	c := exec.Command("sleep", "60")
+	c.ExtraFiles = []*os.File{file}
+	require.NoError(f.t, start(c))
+	f.t.Cleanup(func() {
+		_ = syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+	})
+
+	require.NoError(f.t, file.Close())
+	require.NoError(f.t, l.Close())
+	return c, port
+}
+
+// requireGone waits for a process to exit. Orphans are re-parented to init,
+// which may not reap them in a container, so zombies count as gone.
+func (f *supervisorFixture) requireGone(pid int) {
+	f.t.Helper()
+	require.Eventually(f.t, func() bool {
+		state := processState(pid)
+		return state == "" || state == "Z"
+	}, 5*time.Second, 10*time.Millisecond, "process %d still running", pid)
+}
+
+func requireZombie(t *testing.T, pid int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return processState(pid) == "Z"
+	}, 5*time.Second, 10*time.Millisecond, "process %d never exited", pid)
+}
+
+// processState returns the first letter of a process's state from ps, or
+// the empty string if there's no such process.
+func processState(pid int) string {
+	out, err := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return ""
+	}
+	state := strings.TrimSpace(string(out))
+	if state == "" {
+		return ""
+	}
+	return state[:1]
+}
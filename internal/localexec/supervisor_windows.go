@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package localexec
+
+// Windows doesn't have process groups to look up by ID, so the registry
+// never finds leftover processes there.
+func processAlive(pid int) bool {
+	return false
+}
+
+func processGroupAlive(pgid int) bool {
+	return false
+}
+
+func killProcessGroup(pgid int) error {
+	return nil
+}
+
+func processGroup(pid int) int {
+	return 0
+}
@@ -0,0 +1,77 @@
+// Package offline decides whether Tilt should skip network operations that
+// it doesn't strictly need.
+//
+// On airplanes and in air-gapped labs, registry pushes, extension fetches,
+// and calls home each wait for a timeout. In offline mode, Tilt skips them,
+// or fails them right away with a clear error, and keeps doing everything
+// that only needs the local machine and cluster.
+package offline
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+type Mode struct {
+	Enabled bool
+
+	// Why offline mode is on, e.g., "--offline".
+	Reason string
+}
+
+func (m Mode) String() string {
+	if !m.Enabled {
+		return "online"
+	}
+	return fmt.Sprintf("offline (%s)", m.Reason)
+}
+
+// Errorf returns an error for an operation that offline mode doesn't allow.
+func (m Mode) Errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("%s: Tilt is in offline mode (%s)", fmt.Sprintf(format, a...), m.Reason)
+}
+
+// The capabilities that offline mode skips or degrades, to list once at
+// startup.
+var Degraded = []string{
+	"Analytics: not sent",
+	"Update checks and Tilt Cloud status: skipped",
+	"Extensions: loaded from the local cache; extensions that were never downloaded fail to load",
+	"docker_build(pull=True): base images aren't pulled; the local copies are used",
+	"Image pushes: fail, unless the registry is local (loading images into a local cluster still works)",
+}
+
+// Detect turns on offline mode if there's no route to the internet.
+//
+// Dialing UDP doesn't send any packets, it only looks up a route, so this
+// is fast and doesn't hang when the network is down. The addresses are
+// reserved for documentation, so any route to them is a default route.
+func Detect() Mode {
+	for _, addr := range []struct{ network, address string }{
+		{"udp4", "192.0.2.1:9"},
+		{"udp6", "[2001:db8::1]:9"},
+	} {
+		conn, err := net.Dial(addr.network, addr.address)
+		if err == nil {
+			_ = conn.Close()
+			return Mode{}
+		}
+	}
+	return Mode{Enabled: true, Reason: "no network route found"}
+}
+
+// IsLocalRegistry reports whether a registry host (e.g., "localhost:5000")
+// is on this machine, so pushes to it work without a network.
+func IsLocalRegistry(host string) bool {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	hostname = strings.ToLower(hostname)
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,34 @@
+package offline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsLocalRegistry(t *testing.T) {
+	for host, expected := range map[string]bool{
+		"localhost":                   true,
+		"localhost:5000":              true,
+		"k3d-registry.localhost:5000": true,
+		"127.0.0.1:5000":              true,
+		"[::1]:5000":                  true,
+		"gcr.io":                      false,
+		"registry.example.com:5000":   false,
+		"10.0.0.5:5000":               false,
+		"localhost.example.com:5000":  false,
+	} {
+		assert.Equal(t, expected, IsLocalRegistry(host), host)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	m := Mode{Enabled: true, Reason: "--offline"}
+	assert.EqualError(t, m.Errorf("pushing %s", "gcr.io/foo"),
+		"pushing gcr.io/foo: Tilt is in offline mode (--offline)")
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "online", Mode{}.String())
+	assert.Equal(t, "offline (--offline)", Mode{Enabled: true, Reason: "--offline"}.String())
+}
@@ -0,0 +1,84 @@
+// Package statefile helps readers of the state that Tilt keeps on disk
+// recover from corrupt files.
+//
+// A corrupt state file must never stop Tilt from starting. Readers move
+// the file aside, so that it can be inspected later, and carry on with
+// defaults.
+package statefile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Quarantined files keep their original name, plus this suffix and a timestamp.
+const quarantineSuffix = ".corrupt-"
+
+// Quarantine moves a corrupt file or directory aside, next to where it was.
+// Returns the new path.
+func Quarantine(path string) (string, error) {
+	stamp := time.Now().Format("20060102T150405")
+	dest := path + quarantineSuffix + stamp
+	for i := 1; ; i++ {
+		_, err := os.Lstat(dest)
+		if os.IsNotExist(err) {
+			break
+		}
+		dest = fmt.Sprintf("%s%s%s.%d", path, quarantineSuffix, stamp, i)
+	}
+
+	err := os.Rename(path, dest)
+	if err != nil {
+		return "", fmt.Errorf("quarantining %s: %v", path, err)
+	}
+	return dest, nil
+}
+
+// IsQuarantined reports whether the path is a file that Quarantine moved aside.
+func IsQuarantined(path string) bool {
+	return strings.Contains(filepath.Base(path), quarantineSuffix)
+}
+
+// CorruptError reports a state file that couldn't be read.
+//
+// Readers return it along with default values, so callers can log it and
+// carry on.
+type CorruptError struct {
+	Path string
+
+	// Where the file was moved. Empty if it couldn't be moved.
+	QuarantinedTo string
+
+	Err error
+}
+
+func (e *CorruptError) Error() string {
+	if e.QuarantinedTo == "" {
+		return fmt.Sprintf("%s is corrupt: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s was corrupt (%v); moved it to %s and started fresh", e.Path, e.Err, e.QuarantinedTo)
+}
+
+func (e *CorruptError) Unwrap() error {
+	return e.Err
+}
+
+// Recover quarantines a state file that failed to parse, and describes
+// what happened.
+func Recover(path string, parseErr error) *CorruptError {
+	dest, err := Quarantine(path)
+	if err != nil {
+		return &CorruptError{Path: path, Err: fmt.Errorf("%v (%v)", parseErr, err)}
+	}
+	return &CorruptError{Path: path, QuarantinedTo: dest, Err: parseErr}
+}
+
+// IsCorrupt reports whether the error is (or wraps) a CorruptError.
+func IsCorrupt(err error) bool {
+	var ce *CorruptError
+	return errors.As(err, &ce)
+}
@@ -0,0 +1,50 @@
+package statefile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(p, []byte("{"), 0644))
+
+	err := Recover(p, fmt.Errorf("unexpected end of JSON input"))
+	require.True(t, IsCorrupt(err))
+	assert.Contains(t, err.Error(), "state.json was corrupt (unexpected end of JSON input)")
+
+	_, statErr := os.Stat(p)
+	assert.True(t, os.IsNotExist(statErr))
+
+	assert.True(t, IsQuarantined(err.QuarantinedTo))
+	contents, readErr := os.ReadFile(err.QuarantinedTo)
+	require.NoError(t, readErr)
+	assert.Equal(t, "{", string(contents))
+}
+
+func TestQuarantineTwice(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "state.json")
+
+	require.NoError(t, os.WriteFile(p, []byte("a"), 0644))
+	first, err := Quarantine(p)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(p, []byte("b"), 0644))
+	second, err := Quarantine(p)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first, second)
+	assert.False(t, IsQuarantined(p))
+}
+
+func TestRecoverMissingFile(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "state.json")
+	err := Recover(p, fmt.Errorf("bad"))
+	assert.Empty(t, err.QuarantinedTo)
+	assert.Contains(t, err.Error(), "state.json is corrupt: bad")
+}
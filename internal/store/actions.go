@@ -130,6 +130,12 @@ type AnalyticsNudgeSurfacedAction struct{}
 
 func (AnalyticsNudgeSurfacedAction) Action() {}
 
+type LogBookmarkAction struct {
+	Bookmark logstore.Bookmark
+}
+
+func (LogBookmarkAction) Action() {}
+
 type TiltCloudStatusReceivedAction struct {
 	SuggestedTiltVersion string
 }
@@ -145,6 +151,17 @@ func (PanicAction) Action() {}
 type AppendToTriggerQueueAction struct {
 	Name   model.ManifestName
 	Reason model.BuildReason
+
+	// Set when an external tool requested the build through /api/trigger.
+	Request model.TriggerRequest
 }
 
 func (AppendToTriggerQueueAction) Action() {}
+
+// Values that should be scrubbed from logs, like credentials that Tilt
+// puts in a Secret.
+type SecretsAction struct {
+	Secrets model.SecretSet
+}
+
+func (SecretsAction) Action() {}
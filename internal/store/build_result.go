@@ -197,6 +197,11 @@ type BuildState struct {
 	// live_update, and force an image build (even if there are no changed files)
 	FullBuildTriggered bool
 
+	// The user reset the resource. Implies FullBuildTriggered, and also
+	// builds without the builder's cache and restarts tracking of the
+	// deployed objects.
+	ResetTriggered bool
+
 	// The default cluster.
 	Cluster *v1alpha1.Cluster
 }
@@ -229,6 +234,11 @@ func (b BuildState) WithFullBuildTriggered(isImageBuildTrigger bool) BuildState
 	return b
 }
 
+func (b BuildState) WithResetTriggered(isReset bool) BuildState {
+	b.ResetTriggered = isReset
+	return b
+}
+
 func (b BuildState) LastLocalImageAsString() string {
 	return LocalImageRefFromBuildResult(b.LastResult)
 }
@@ -277,6 +287,15 @@ func (set BuildStateSet) FullBuildTriggered() bool {
 	return false
 }
 
+func (set BuildStateSet) ResetTriggered() bool {
+	for _, state := range set {
+		if state.ResetTriggered {
+			return true
+		}
+	}
+	return false
+}
+
 func (set BuildStateSet) Empty() bool {
 	return len(set) == 0
 }
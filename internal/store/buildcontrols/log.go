@@ -3,6 +3,8 @@ package buildcontrols
 import (
 	"context"
 
+	"github.com/kballard/go-shellquote"
+
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/pkg/logger"
 	"github.com/tilt-dev/tilt/pkg/model"
@@ -12,6 +14,8 @@ type BuildEntry struct {
 	Name         model.ManifestName
 	BuildReason  model.BuildReason
 	FilesChanged []string
+	Triggers     []model.TriggerRequest
+	ArgsChange   *model.TiltfileArgsChange
 }
 
 func LogBuildEntry(ctx context.Context, entry BuildEntry) {
@@ -33,4 +37,21 @@ func LogBuildEntry(ctx context.Context, entry BuildEntry) {
 			l.Infof("%s", buildReason)
 		}
 	}
+
+	for _, t := range entry.Triggers {
+		if t.Message != "" {
+			l.Infof("Trigger %s: %s", t.ID, t.Message)
+		}
+	}
+
+	if c := entry.ArgsChange; c != nil {
+		l.Infof("Args: %s → %s", formatArgs(c.From), formatArgs(c.To))
+	}
+}
+
+func formatArgs(args []string) string {
+	if len(args) == 0 {
+		return "(none)"
+	}
+	return shellquote.Join(args...)
 }
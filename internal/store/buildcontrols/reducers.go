@@ -39,6 +39,7 @@ func HandleBuildStarted(ctx context.Context, state *store.EngineState, action Bu
 		StartTime: action.StartTime,
 		Reason:    action.Reason,
 		SpanID:    action.SpanID,
+		Triggers:  ms.PendingTriggers,
 	}
 	ms.ConfigFilesThatCausedChange = []string{}
 	ms.CurrentBuilds[action.Source] = bs
@@ -200,6 +201,10 @@ func HandleBuildCompleted(ctx context.Context, engineState *store.EngineState, c
 
 	handleBuildResults(engineState, mt, bs, cb.Result)
 
+	if err == nil {
+		engineState.MarkUpdated(mn, bs.FinishTime)
+	}
+
 	if !ms.PendingManifestChange.IsZero() &&
 		timecmp.BeforeOrEqual(ms.PendingManifestChange, bs.StartTime) {
 		ms.PendingManifestChange = time.Time{}
@@ -70,8 +70,14 @@ type EngineState struct {
 	// All logs in Tilt, stored in a structured format.
 	LogStore *logstore.LogStore `testdiff:"ignore"`
 
+	// Points in the log that the user marked, in the order they were added.
+	LogBookmarks []logstore.Bookmark
+
 	TriggerQueue []model.ManifestName
 
+	// Groups of resources from resource_group(), by group name.
+	ResourceGroups map[model.ManifestName]model.ResourceGroup
+
 	TiltfileDefinitionOrder []model.ManifestName
 	TiltfileStates          map[model.ManifestName]*ManifestState
 
@@ -250,6 +256,23 @@ func (e EngineState) ManifestState(mn model.ManifestName) (*ManifestState, bool)
 	return m.State, ok
 }
 
+// Returns the resources in a group, or nil if there's no group with that name.
+func (e EngineState) ResourceGroupMembers(name model.ManifestName) []model.ManifestName {
+	return e.ResourceGroups[name].Members
+}
+
+// Returns ResourceGroups in a stable order.
+func (e EngineState) SortedResourceGroups() []model.ResourceGroup {
+	result := make([]model.ResourceGroup, 0, len(e.ResourceGroups))
+	for _, g := range e.ResourceGroups {
+		result = append(result, g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
 // Returns Manifests in a stable order
 func (e EngineState) Manifests() []model.Manifest {
 	result := make([]model.Manifest, 0, len(e.ManifestTargets))
@@ -315,6 +338,23 @@ func (e EngineState) TargetsBesides(mn model.ManifestName) []*ManifestTarget {
 	return result
 }
 
+// MarkUpdated records that the resource was successfully built or live-updated
+// at the given time, so that local resources that run on each of its updates
+// (e.g., its tests) are queued to re-run.
+func (e *EngineState) MarkUpdated(mn model.ManifestName, updateTime time.Time) {
+	for _, mt := range e.TargetsBesides(mn) {
+		if !mt.Manifest.IsLocal() {
+			continue
+		}
+		lt := mt.Manifest.LocalTarget()
+		if !lt.RunsOnUpdateOf(mn) {
+			continue
+		}
+		depID := model.TargetID{Type: model.TargetTypeManifest, Name: mn.TargetName()}
+		mt.State.MutableBuildStatus(lt.ID()).PendingDependencyChanges[depID] = updateTime
+	}
+}
+
 func (e *EngineState) ManifestInTriggerQueue(mn model.ManifestName) bool {
 	for _, queued := range e.TriggerQueue {
 		if queued == mn {
@@ -344,10 +384,36 @@ func (e *EngineState) AppendToTriggerQueue(mn model.ManifestName, reason model.B
 	e.TriggerQueue = append(e.TriggerQueue, mn)
 }
 
+// Records a request from an external tool, so that the next build of the
+// manifest can be found by the request ID.
+func (e *EngineState) AppendTriggerRequest(mn model.ManifestName, req model.TriggerRequest) {
+	ms, ok := e.ManifestState(mn)
+	if !ok || req.ID == "" {
+		return
+	}
+	ms.PendingTriggers = append(ms.PendingTriggers, req)
+}
+
+// Forgets what Tilt built and deployed for a manifest, so that its next
+// build starts from scratch, and its pods are tracked from scratch.
+func (e *EngineState) ResetManifest(mn model.ManifestName) {
+	mt, ok := e.ManifestTargets[mn]
+	if !ok {
+		return
+	}
+
+	ms := mt.State
+	ms.BuildStatuses = make(map[model.TargetID]*BuildStatus)
+	if mt.Manifest.IsK8s() {
+		ms.RuntimeState = NewK8sRuntimeState(mt.Manifest)
+	}
+}
+
 func (e *EngineState) RemoveFromTriggerQueue(mn model.ManifestName) {
 	mState, ok := e.ManifestState(mn)
 	if ok {
 		mState.TriggerReason = model.BuildReasonNone
+		mState.PendingTriggers = nil
 	}
 
 	for i, triggerName := range e.TriggerQueue {
@@ -489,6 +555,9 @@ type ManifestState struct {
 	// If the build was manually triggered, record why.
 	TriggerReason model.BuildReason
 
+	// Requests from external tools that the next build will run.
+	PendingTriggers []model.TriggerRequest
+
 	DisableState v1alpha1.DisableState
 }
 
@@ -496,6 +565,7 @@ func NewState() *EngineState {
 	ret := &EngineState{}
 	ret.LogStore = logstore.NewLogStore()
 	ret.ManifestTargets = make(map[model.ManifestName]*ManifestTarget)
+	ret.ResourceGroups = make(map[model.ManifestName]model.ResourceGroup)
 	ret.Secrets = model.SecretSet{}
 	ret.DockerPruneSettings = model.DefaultDockerPruneSettings()
 	ret.VersionSettings = model.VersionSettings{
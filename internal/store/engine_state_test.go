@@ -77,6 +77,53 @@ func TestNextBuildReason(t *testing.T) {
 		mt.NextBuildReason().String())
 }
 
+func TestResetManifest(t *testing.T) {
+	m := k8sManifest(t, "sancho", testyaml.SanchoYAML)
+	state := NewState()
+	state.UpsertManifestTarget(NewManifestTarget(m))
+
+	ms := state.ManifestTargets["sancho"].State
+	ms.MutableBuildStatus(m.K8sTarget().ID()).LastResult = NewK8sDeployResult(m.K8sTarget().ID(), nil)
+	ms.RuntimeState = NewK8sRuntimeStateWithPods(m, v1alpha1.Pod{Name: "pod-a", CreatedAt: apis.Now()})
+	ms.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+
+	state.ResetManifest("sancho")
+
+	assert.Empty(t, ms.BuildStatuses)
+	assert.Equal(t, 0, ms.K8sRuntimeState().PodLen())
+	assert.Len(t, ms.BuildHistory, 1, "build history should be kept")
+}
+
+func TestMarkUpdated(t *testing.T) {
+	api := model.Manifest{Name: "api"}.WithDeployTarget(model.K8sTarget{})
+	cmd := model.Cmd{Argv: []string{"go", "test", "./..."}, Dir: "."}
+	testsTarget := model.NewLocalTarget("api-tests", cmd, model.Cmd{}, nil).
+		WithRunOnUpdateOf([]model.ManifestName{"api"}, false)
+	tests := model.Manifest{Name: "api-tests"}.WithDeployTarget(testsTarget)
+	lintTarget := model.NewLocalTarget("lint", cmd, model.Cmd{}, nil)
+	lint := model.Manifest{Name: "lint"}.WithDeployTarget(lintTarget)
+
+	state := NewState()
+	for _, m := range []model.Manifest{api, tests, lint} {
+		state.UpsertManifestTarget(NewManifestTarget(m))
+	}
+	for _, mt := range state.Targets() {
+		mt.State.AddCompletedBuild(model.BuildRecord{StartTime: time.Now(), FinishTime: time.Now()})
+	}
+
+	state.MarkUpdated("api", time.Now())
+
+	testsMT := state.ManifestTargets["api-tests"]
+	hasPendingChanges, _ := testsMT.State.HasPendingChanges()
+	assert.True(t, hasPendingChanges)
+	assert.Equal(t, "Dependency Updated", testsMT.NextBuildReason().String())
+
+	hasPendingChanges, _ = state.ManifestTargets["lint"].State.HasPendingChanges()
+	assert.False(t, hasPendingChanges)
+	hasPendingChanges, _ = state.ManifestTargets["api"].State.HasPendingChanges()
+	assert.False(t, hasPendingChanges)
+}
+
 func TestManifestTargetEndpoints(t *testing.T) {
 	cases := []endpointsCase{
 		{
@@ -181,6 +181,8 @@ func PodStatusToString(pod v1.Pod) string {
 		switch {
 		case state.Terminated != nil && state.Terminated.ExitCode == 0:
 			continue
+		case state.Running != nil && isSidecarInitContainerStatus(pod, i):
+			continue
 		case state.Terminated != nil:
 			// initialization is failed
 			if len(state.Terminated.Reason) == 0 {
@@ -227,8 +229,9 @@ func PodStatusToString(pod v1.Pod) string {
 // Pull out interesting error messages from the pod status
 func PodStatusErrorMessages(pod v1.Pod) []string {
 	result := []string{}
-	if isPodStillInitializing(pod) {
-		for _, container := range pod.Status.InitContainerStatuses {
+	initializing := isPodStillInitializing(pod)
+	for i, container := range pod.Status.InitContainerStatuses {
+		if initializing || isSidecarInitContainerStatus(pod, i) {
 			result = append(result, containerStatusErrorMessages(container)...)
 		}
 	}
@@ -266,10 +269,11 @@ func containerStatusErrorMessages(container v1.ContainerStatus) []string {
 }
 
 func isPodStillInitializing(pod v1.Pod) bool {
-	for _, container := range pod.Status.InitContainerStatuses {
+	for i, container := range pod.Status.InitContainerStatuses {
 		state := container.State
 		isFinished := state.Terminated != nil && state.Terminated.ExitCode == 0
-		if !isFinished {
+		isRunningSidecar := state.Running != nil && isSidecarInitContainerStatus(pod, i)
+		if !isFinished && !isRunningSidecar {
 			return true
 		}
 	}
@@ -86,11 +86,59 @@ func TestPodStatus(t *testing.T) {
 				"Back-off 40s restarting failed container=my-app pod=my-app-7bb79c789d-8h6n9_default(31369f71-df65-4352-b6bd-6d704a862699)",
 			},
 		},
+		{
+			// A native sidecar keeps running after the app container starts.
+			pod: v1.PodStatus{
+				Phase: v1.PodRunning,
+				InitContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "migrate",
+						State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 0}},
+					},
+					{
+						Name:  "log-shipper",
+						State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+					},
+				},
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "my-app",
+						State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+					},
+				},
+			},
+			status:   "Running",
+			messages: []string{},
+		},
+		{
+			// An init container that's still running, with nothing started
+			// after it, is still initializing the pod.
+			pod: v1.PodStatus{
+				Phase: v1.PodPending,
+				InitContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "migrate",
+						State: v1.ContainerState{Running: &v1.ContainerStateRunning{}},
+					},
+				},
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Name:  "my-app",
+						State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "PodInitializing"}},
+					},
+				},
+			},
+			status:   "Init:0/1",
+			messages: []string{},
+		},
 	}
 
 	for i, c := range cases {
 		t.Run(fmt.Sprintf("case%d", i), func(t *testing.T) {
 			pod := v1.Pod{Status: c.pod}
+			for _, s := range c.pod.InitContainerStatuses {
+				pod.Spec.InitContainers = append(pod.Spec.InitContainers, v1.Container{Name: s.Name})
+			}
 			status := PodStatusToString(pod)
 			assert.Equal(t, c.status, status)
 
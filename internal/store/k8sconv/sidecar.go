@@ -0,0 +1,67 @@
+package k8sconv
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+// SidecarInitContainers returns the init containers of a pod that keep
+// running alongside its app containers, like native sidecars (init
+// containers with `restartPolicy: Always`).
+//
+// The Kubernetes API version that Tilt builds against doesn't have an init
+// container's restartPolicy, so this goes by status instead. Init
+// containers run one at a time, and each must exit successfully before the
+// next one starts. So an init container that hasn't exited successfully,
+// even though a container after it has started, must be a sidecar.
+func SidecarInitContainers(pod v1alpha1.Pod) []v1alpha1.Container {
+	var result []v1alpha1.Container
+	for i, c := range pod.InitContainers {
+		if c.State.Terminated != nil && c.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		if anyStarted(pod.InitContainers[i+1:]) || anyStarted(pod.Containers) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// LongRunningContainers returns the containers that run for the life of a
+// pod: its sidecar init containers, then its app containers.
+func LongRunningContainers(pod v1alpha1.Pod) []v1alpha1.Container {
+	var result []v1alpha1.Container
+	result = append(result, SidecarInitContainers(pod)...)
+	result = append(result, pod.Containers...)
+	return result
+}
+
+func anyStarted(containers []v1alpha1.Container) bool {
+	for _, c := range containers {
+		if c.State.Running != nil || c.State.Terminated != nil || c.Restarts > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Like SidecarInitContainers, but for the init container statuses of a
+// Kubernetes pod.
+func isSidecarInitContainerStatus(pod v1.Pod, i int) bool {
+	state := pod.Status.InitContainerStatuses[i].State
+	if state.Terminated != nil && state.Terminated.ExitCode == 0 {
+		return false
+	}
+	return anyStatusStarted(pod.Status.InitContainerStatuses[i+1:]) ||
+		anyStatusStarted(pod.Status.ContainerStatuses)
+}
+
+func anyStatusStarted(statuses []v1.ContainerStatus) bool {
+	for _, c := range statuses {
+		if c.State.Running != nil || c.State.Terminated != nil || c.RestartCount > 0 {
+			return true
+		}
+	}
+	return false
+}
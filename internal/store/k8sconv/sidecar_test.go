@@ -0,0 +1,43 @@
+package k8sconv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestSidecarInitContainers(t *testing.T) {
+	running := v1alpha1.ContainerState{Running: &v1alpha1.ContainerStateRunning{}}
+	succeeded := v1alpha1.ContainerState{Terminated: &v1alpha1.ContainerStateTerminated{ExitCode: 0}}
+	crashing := v1alpha1.ContainerState{Waiting: &v1alpha1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	waiting := v1alpha1.ContainerState{Waiting: &v1alpha1.ContainerStateWaiting{Reason: "PodInitializing"}}
+
+	pod := v1alpha1.Pod{
+		InitContainers: []v1alpha1.Container{
+			{Name: "migrate", State: succeeded},
+			{Name: "log-shipper", State: running},
+			{Name: "proxy", State: crashing, Restarts: 3},
+		},
+		Containers: []v1alpha1.Container{
+			{Name: "api", State: running},
+		},
+	}
+	assert.Equal(t, []string{"log-shipper", "proxy"}, containerNames(SidecarInitContainers(pod)))
+	assert.Equal(t, []string{"log-shipper", "proxy", "api"}, containerNames(LongRunningContainers(pod)))
+
+	// Until the app container starts, the last init container might just
+	// be slow.
+	pod.InitContainers[2] = v1alpha1.Container{Name: "proxy", State: running}
+	pod.Containers[0].State = waiting
+	assert.Equal(t, []string{"log-shipper"}, containerNames(SidecarInitContainers(pod)))
+}
+
+func containerNames(containers []v1alpha1.Container) []string {
+	var result []string
+	for _, c := range containers {
+		result = append(result, c.Name)
+	}
+	return result
+}
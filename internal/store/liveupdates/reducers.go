@@ -1,12 +1,44 @@
 package liveupdates
 
 import (
+	"time"
+
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func HandleLiveUpdateUpsertAction(state *store.EngineState, action LiveUpdateUpsertAction) {
 	n := action.LiveUpdate.Name
+	old := state.LiveUpdates[n]
 	state.LiveUpdates[n] = action.LiveUpdate
+
+	// A successful live update counts as an update of the resource,
+	// for local resources that run on each of its updates.
+	mn := action.LiveUpdate.Annotations[v1alpha1.AnnotationManifest]
+	if mn == "" {
+		return
+	}
+	lastSynced := lastSuccessfulSync(action.LiveUpdate)
+	if lastSynced.IsZero() || (old != nil && !lastSynced.After(lastSuccessfulSync(old))) {
+		return
+	}
+	state.MarkUpdated(model.ManifestName(mn), time.Now())
+}
+
+// Returns the most recent time that files were synced to a container
+// without an exec error.
+func lastSuccessfulSync(lu *v1alpha1.LiveUpdate) time.Time {
+	var result time.Time
+	for _, c := range lu.Status.Containers {
+		if c.LastExecError != "" {
+			continue
+		}
+		if c.LastFileTimeSynced.Time.After(result) {
+			result = c.LastFileTimeSynced.Time
+		}
+	}
+	return result
 }
 
 func HandleLiveUpdateDeleteAction(state *store.EngineState, action LiveUpdateDeleteAction) {
@@ -235,12 +235,14 @@ func AllPodContainers(p v1alpha1.Pod) []v1alpha1.Container {
 	return result
 }
 
+// Whether all the app containers in the pod, and any sidecar init
+// containers, are ready.
 func AllPodContainersReady(p v1alpha1.Pod) bool {
 	if len(p.Containers) == 0 {
 		return false
 	}
 
-	for _, c := range p.Containers {
+	for _, c := range k8sconv.LongRunningContainers(p) {
 		if !c.Ready {
 			return false
 		}
@@ -248,9 +250,11 @@ func AllPodContainersReady(p v1alpha1.Pod) bool {
 	return true
 }
 
+// The restarts of the app containers in the pod, and any sidecar init
+// containers.
 func AllPodContainerRestarts(p v1alpha1.Pod) int32 {
 	result := int32(0)
-	for _, c := range p.Containers {
+	for _, c := range k8sconv.LongRunningContainers(p) {
 		result += c.Restarts
 	}
 	return result
@@ -2,13 +2,34 @@ package uibuttons
 
 import (
 	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
 )
 
 func HandleUIButtonUpsertAction(state *store.EngineState, action UIButtonUpsertAction) {
 	n := action.UIButton.Name
+	old := state.UIButtons[n]
 	state.UIButtons[n] = action.UIButton
+
+	if isResetClick(old, action.UIButton) {
+		mn := model.ManifestName(action.UIButton.Spec.Location.ComponentID)
+		ms, ok := state.ManifestState(mn)
+		if ok && ms.DisableState != v1alpha1.DisableStateDisabled {
+			state.ResetManifest(mn)
+			state.AppendToTriggerQueue(mn, model.BuildReasonFlagTriggerWeb.With(model.BuildReasonFlagReset))
+		}
+	}
 }
 
 func HandleUIButtonDeleteAction(state *store.EngineState, action UIButtonDeleteAction) {
 	delete(state.UIButtons, action.Name)
 }
+
+// Whether the button is a reset button that was clicked since the last upsert.
+func isResetClick(old, b *v1alpha1.UIButton) bool {
+	if b.Annotations[v1alpha1.AnnotationButtonType] != v1alpha1.ButtonTypeReset ||
+		b.Status.LastClickedAt.IsZero() {
+		return false
+	}
+	return old == nil || b.Status.LastClickedAt.After(old.Status.LastClickedAt.Time)
+}
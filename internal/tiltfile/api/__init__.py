@@ -20,6 +20,13 @@ class LiveUpdateStep:
   """
   pass
 
+class DynamicBuildArg:
+  """A build arg whose value is resolved each time the image is built.
+
+  For details, see the :meth:`arg_from_cmd` and :meth:`arg_from_file` functions.
+  """
+  pass
+
 class PortForward:
   """
   Specifications for setting up and displaying a Kubernetes port-forward.
@@ -134,6 +141,27 @@ def set_team(team_id: str) -> None:
   """
   pass
 
+def progress(message: str) -> None:
+  """Describes what the Tiltfile is doing, for loads that take a while.
+
+  When a Tiltfile takes more than a second to load, Tilt shows its progress in
+  the Tiltfile logs, as a single line that updates in place: the top-level
+  statement that's executing, the function or command it's running, and how long
+  the load has taken. Stop a slow load with the stop button on the Tiltfile resource;
+  Tilt keeps the resources from the last successful load.
+
+  The message shows until the next call to ``progress``. For example:
+
+  .. code-block:: python
+
+    progress('rendering charts')
+    k8s_yaml(helm('./charts/app'))
+
+  Args:
+    message: A short description of the current step.
+  """
+  pass
+
 def sync(local_path: str, remote_path: str) -> LiveUpdateStep:
   """Specify that any changes to `localPath` should be synced to `remotePath`
 
@@ -179,7 +207,7 @@ def restart_container() -> LiveUpdateStep:
 
 def docker_build(ref: str,
                  context: str,
-                 build_args: Dict[str, str] = {},
+                 build_args: Dict[str, Union[str, DynamicBuildArg]] = {},
                  dockerfile: str = "Dockerfile",
                  dockerfile_contents: Union[str, Blob] = "",
                  live_update: List[LiveUpdateStep]=[],
@@ -195,7 +223,10 @@ def docker_build(ref: str,
                  container_args: List[str] = None,
                  cache_from: Union[str, List[str]] = [],
                  pull: bool = False,
-                 platform: str = "") -> None:
+                 platform: str = "",
+                 go_main: str = "",
+                 published: Union[bool, str] = False,
+                 build_timeout_secs: int = None) -> None:
   """Builds a docker image.
 
   The invocation
@@ -214,6 +245,11 @@ def docker_build(ref: str,
 
   Note that you can't set both the `dockerfile` and `dockerfile_contents` arguments (will throw an error).
 
+  To share snippets between Dockerfiles, put a ``# tilt:include path/to/snippet`` line in the Dockerfile. Tilt replaces
+  it with the contents of the file before building. Paths are relative to the file with the include (for
+  ``dockerfile_contents``, relative to the context). Included files can include other files, and Tilt reloads
+  when any of them change.
+
   Note also that the `entrypoint` parameter is not supported for Docker Compose resources.
 
   When using Docker Compose, Tilt expects the image build to be either managed by your Docker Compose file (via the `build <https://docs.docker.com/compose/compose-file/compose-file-v3/#build>`_ key) OR by Tilt's :meth:`docker_build`, but not both. (Follow this `GitHub issue <https://github.com/tilt-dev/tilt/issues/5196>`_ to be notified of changes to this expectation.)
@@ -223,7 +259,7 @@ def docker_build(ref: str,
   Args:
     ref: name for this image (e.g. 'myproj/backend' or 'myregistry/myproj/backend'). If this image will be used in a k8s resource(s), this ref must match the ``spec.container.image`` param for that resource(s).
     context: path to use as the Docker build context.
-    build_args: build-time variables that are accessed like regular environment variables in the ``RUN`` instruction of the Dockerfile. See `the Docker Build Arg documentation <https://docs.docker.com/engine/reference/commandline/build/#set-build-time-variables---build-arg>`_.
+    build_args: build-time variables that are accessed like regular environment variables in the ``RUN`` instruction of the Dockerfile. See `the Docker Build Arg documentation <https://docs.docker.com/engine/reference/commandline/build/#set-build-time-variables---build-arg>`_. Values may be strings, or :meth:`arg_from_cmd` / :meth:`arg_from_file` to resolve the value at build time.
     dockerfile: path to the Dockerfile to build.
     dockerfile_contents: raw contents of the Dockerfile to use for this build.
     live_update: set of steps for updating a running container (see `Live Update documentation <live_update_reference.html>`_).
@@ -240,6 +276,68 @@ def docker_build(ref: str,
     cache_from: Cache image builds from a remote registry. Uses the same syntax as `docker build --cache-from flag <https://docs.docker.com/engine/reference/commandline/build/#specifying-external-cache-sources>`_.
     pull: Force pull the latest version of parent images. Equivalent to the ``docker build --pull`` flag.
     platform: Target platform for build (e.g. ``linux/amd64``). Defaults to the value of the ``DOCKER_DEFAULT_PLATFORM`` environment variable. Equivalent to the ``docker build --platform`` flag.
+    go_main: path to the Go main package that the image builds (e.g. ``./cmd/api``), inside the ``context``. Tilt runs ``go list -deps`` on it, and ignores edits to Go files in packages that it doesn't import, so a repo with many binaries only rebuilds the images that changed. Other files still trigger builds, and the build context is unchanged. Tilt recomputes the dependencies when the Tiltfile reloads, and reloads when ``go.mod`` or ``go.sum`` change; a new import is picked up on the next reload. If ``go list`` fails, Tilt prints a warning and watches the whole context.
+    published: publish the image to other Tilt sessions, so that they can build on it with :meth:`base_image_dependency`. After each build (and push), Tilt writes the image ref and digest to a JSON file. ``True`` writes to ``.tilt/published/<image>.json`` next to the Tiltfile, with the slashes and colons in the image name replaced by underscores (e.g., ``.tilt/published/gcr.io_acme_base.json``). A string is the path to write to, relative to the Tiltfile. Writing the file doesn't trigger builds in this session.
+    build_timeout_secs: how long the build may run before Tilt cancels it and marks it failed. The error names the step that was running and includes its last 50 lines of output. ``0`` means no timeout. Defaults to ``build_timeout_secs`` in :meth:`update_settings`.
+  """
+  pass
+
+def arg_from_cmd(cmd: Union[str, List[str]], dir: str = "") -> DynamicBuildArg:
+  """A build arg whose value is the output of a local command.
+
+  The command runs before each build, rather than when the Tiltfile is loaded,
+  so the value never goes stale. Leading and trailing whitespace is trimmed from
+  the output, and the resolved value is printed in the build log.
+
+  .. code-block:: python
+
+    docker_build('myimage', '.', build_args={'VERSION': arg_from_cmd('git describe --tags')})
+
+  Args:
+    cmd: Command to run. If a string, executed with ``sh -c`` on macOS/Linux, or ``cmd /S /C`` on Windows; if a list, will be passed to the operating system as program name and args.
+    dir: Working directory of the command. Defaults to the Tiltfile's location.
+  """
+  pass
+
+def arg_from_file(path: str) -> DynamicBuildArg:
+  """A build arg whose value is the contents of a local file.
+
+  The file is read before each build, and changes to the file trigger a rebuild.
+  Trailing newlines are trimmed.
+
+  .. code-block:: python
+
+    docker_build('myimage', '.', build_args={'NPMRC': arg_from_file('.npmrc.ci')})
+
+  Args:
+    path: Path to the file, relative to the Tiltfile.
+  """
+  pass
+
+def base_image_dependency(ref: str, watch: str) -> None:
+  """Build on a base image that another Tilt session publishes with ``docker_build(published=...)``.
+
+  When the other session builds a new version of the image, every
+  ``docker_build`` image whose Dockerfile builds ``FROM`` it is rebuilt, with
+  the ``FROM`` rewritten to the new image. Until the image is published, the
+  Dockerfile uses it as written.
+
+  .. code-block:: python
+
+    # In the platform team's Tiltfile
+    docker_build('gcr.io/acme/base', '.', published=True)
+
+    # In an app team's Tiltfile
+    base_image_dependency('gcr.io/acme/base', watch='../platform/.tilt/published/gcr.io_acme_base.json')
+    docker_build('gcr.io/acme/app', '.')
+
+  The new image is looked up by its ref in the Docker that the other session
+  built it with, so both sessions should use the same Docker (or the same
+  registry).
+
+  Args:
+    ref: The base image, as the Dockerfiles refer to it. Matches ``FROM`` lines with any tag.
+    watch: Path to the file that the other session publishes the image to, relative to the Tiltfile.
   """
   pass
 
@@ -295,6 +393,19 @@ def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False)
 
   Any YAML files are watched (See ``watch_file``).
 
+  Objects of built-in kinds are checked against their schema, and Tilt warns about
+  unknown fields (like ``replica`` instead of ``replicas``) before anything is built.
+  Custom resources aren't checked. To skip the check for an object, add the annotation
+  ``tilt.dev/skip-schema-validation: "true"``.
+
+  Tilt applies the objects of each resource by kind priority (so that Namespaces,
+  CRDs, RBAC, volumes, ConfigMaps, Secrets, and Services come before the workloads
+  that use them). Objects with the same priority keep the order they were declared
+  in. Map keys in the YAML that Tilt applies are sorted. So the same Tiltfile
+  always renders the same YAML. To apply some objects after others
+  are ready, put them in a separate resource with ``resource_deps``
+  (see ``k8s_resource``).
+
   Examples:
 
   .. code-block:: python
@@ -319,6 +430,27 @@ def k8s_yaml(yaml: Union[str, List[str], Blob], allow_duplicates: bool = False)
   pass
 
 
+def image_ref(ref: str) -> str:
+  """A placeholder for the ref of an image that Tilt builds.
+
+  Tilt automatically injects built images into container ``image`` fields. Use ``image_ref``
+  when the image appears somewhere else in your YAML, like a Helm value:
+
+  .. code-block:: python
+
+    docker_build('gcr.io/acme/api', '.')
+    k8s_yaml(helm('chart', set=['api.image=' + image_ref('gcr.io/acme/api')]))
+
+  After the image is built, Tilt replaces the placeholder with the ref it deploys
+  (including the tag), and re-applies the YAML whenever the image is rebuilt.
+
+  The YAML must belong to a resource, and ``ref`` must match an image that Tilt builds.
+
+  Args:
+    ref: name of the image, matching the ``ref`` passed to :meth:`docker_build` or :meth:`custom_build`.
+  """
+  pass
+
 def k8s_custom_deploy(name: str,
                       apply_cmd: Union[str, List[str]],
                       delete_cmd: Union[str, List[str]],
@@ -388,6 +520,8 @@ def k8s_custom_deploy(name: str,
       `TILT_IMAGE_i` - The reference to the image #i (0-based) from the point of view of the cluster container runtime.
 
       `TILT_IMAGE_MAP_i` - The name of the image map #i (0-based) with the current status of the image.
+    build_timeout_secs: how long the build command may run before Tilt cancels it and marks the build failed. ``0`` means no timeout.
+      Defaults to ``build_timeout_secs`` in :meth:`update_settings`.
   """
   pass
 
@@ -464,7 +598,14 @@ def k8s_resource(workload: str = "", new_name: str = "",
                  pod_readiness: str = "",
                  links: Union[str, Link, List[Union[str, Link]]]=[],
                  labels: Union[str, List[str]] = [],
-                 discovery_strategy: str = "") -> None:
+                 discovery_strategy: str = "",
+                 instances: List[str] = [],
+                 instance_port_offset: int = 1,
+                 restart_on: Union[str, List[str], None] = None,
+                 live_update_override: Optional[List[LiveUpdateStep]] = None,
+                 log_containers: List[str] = [],
+                 ignore_log_containers: List[str] = [],
+                 image_containers: List[str] = []) -> None:
   """
 
   Configures or creates the specified Kubernetes resource.
@@ -562,7 +703,42 @@ def k8s_resource(workload: str = "", new_name: str = "",
     links: one or more links to be associated with this resource in the UI. For more info, see
       `Accessing Resource Endpoints <accessing_resource_endpoints.html#arbitrary-links>`_.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed separately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
-    discovery_strategy: Possible values: '', 'default', 'selectors-only'. When '' or 'default', Tilt both uses `extra_pod_selectors` and traces k8s owner references to identify this resource's pods. When 'selectors-only', Tilt uses only `extra_pod_selectors`.
+    discovery_strategy: Possible values: '', 'default', 'selectors-only', 'selectors-and-owners'. When '' or 'default', Tilt both uses `extra_pod_selectors` and traces k8s owner references to identify this resource's pods. When 'selectors-only', Tilt uses only `extra_pod_selectors`.
+      When 'selectors-and-owners', Tilt traces owner references, and also adopts pods in the resource's namespaces
+      that match `extra_pod_selectors`, like pods that an operator creates for a custom resource. Adopted pods
+      are reported as "discovered" rather than "owned", and get the resource's logs, status, and port forwards.
+      A pod owned by another resource stays with that resource. If the selectors of more than one resource
+      match a pod, Tilt assigns it to the resource whose name sorts first, and warns about it.
+    instances: Namespaces to deploy this resource to. Tilt creates one resource per namespace,
+      named ``<resource>-<namespace>`` and grouped under a label with the resource name, with all
+      objects moved into that namespace. The instances share the resource's image builds.
+      Any ``resource_deps`` on this resource depend on every instance. Cluster-scoped objects
+      (like namespaces or CRDs) must be in a separate resource.
+    instance_port_offset: With ``instances``, how much to shift the local port of each port forward
+      for each instance after the first, so that the instances don't collide. e.g., with
+      ``port_forwards=8000`` and the default offset of 1, the instances forward 8000, 8001, 8002, etc.
+    restart_on: ConfigMaps and Secrets that restart this resource's pods when they change, like
+      ``['configmap:app-config', 'secret:db-creds']``. They must be deployed by this Tiltfile.
+      Use ``'auto'`` to restart on any ConfigMap or Secret from this Tiltfile that the pods use.
+      Tilt puts a hash of their data on the pod template, so a change rolls the pods. By default,
+      only changes to :meth:`configmap_create` and :meth:`secret_create` objects restart pods.
+      If your app reloads its config on its own, pass ``[]`` to never restart.
+    live_update_override: Live update steps to use for this resource's pods instead of the
+      ``live_update`` steps of the image it deploys. Other resources that deploy the same image
+      keep the image's steps. To extend the image's steps rather than replace them, keep them in a
+      variable and concatenate, e.g. ``live_update_override=steps + [run('make worker')]``.
+      Sync sources must still be inside the image's build context. Pass ``[]`` to turn off live
+      update for this resource. Only valid for resources that deploy one image built by Tilt.
+    log_containers: Names of the containers whose logs appear in this resource's logs. Names may
+      use glob patterns, like ``'worker-*'``. By default, logs from all containers appear.
+    ignore_log_containers: Names of containers whose logs don't appear in this resource's logs,
+      like ``['envoy', 'log-*']``, in addition to the Istio and Linkerd containers, which never
+      appear. ``log_containers`` takes precedence. If either is set, the resource gets a button
+      to show the logs of all its containers for the rest of the session.
+    image_containers: Names (or glob patterns) of the containers that Tilt injects its built
+      images into. By default, Tilt injects into every container that runs one of its images;
+      set this when a sidecar runs the same image as the app but should keep the image
+      from the YAML. The resource only depends on the images of these containers.
   """
   pass
 
@@ -606,6 +782,42 @@ def filter_yaml(yaml: Union[str, List[str], Blob], labels: dict=None, name: str=
   """
   pass
 
+def configmap_create(name: str, from_files: Dict[str, str], namespace: str = "", append_hash: bool = False) -> None:
+  """Creates a ConfigMap from files, and deploys it like ``k8s_yaml``.
+
+  Tilt watches the files. When one changes, Tilt regenerates the ConfigMap
+  and restarts the pods that use it.
+
+  The ConfigMap goes in the resource of the first workload that uses it,
+  in a volume, ``envFrom``, or ``valueFrom``. ::
+
+    configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+    k8s_yaml('app.yaml')
+
+  Args:
+    name: The name of the ConfigMap.
+    from_files: A map from keys in the ConfigMap to paths of the files to read, relative to the Tiltfile.
+    namespace: The namespace of the ConfigMap.
+    append_hash: If True, append a hash of the contents to the name of the ConfigMap, and
+      rewrite references to it in pod specs. Otherwise, Tilt restarts pods that use the
+      ConfigMap with a ``tilt.dev/config-hash`` annotation on the pod template.
+  """
+  pass
+
+def secret_create(name: str, from_files: Dict[str, str], namespace: str = "", append_hash: bool = False) -> None:
+  """Creates a Secret from files, and deploys it like ``k8s_yaml``.
+
+  Works like :meth:`configmap_create`.
+
+  Args:
+    name: The name of the Secret.
+    from_files: A map from keys in the Secret to paths of the files to read, relative to the Tiltfile.
+    namespace: The namespace of the Secret.
+    append_hash: If True, append a hash of the contents to the name of the Secret, and
+      rewrite references to it in pod specs.
+  """
+  pass
+
 def include(path: str):
   """Execute another Tiltfile.
 
@@ -674,6 +886,13 @@ def local(command: Union[str, List[str]],
           stdin: Union[str, Blob, None] = None) -> Blob:
   """Runs a command on the *host* machine, waits for it to finish, and returns its stdout as a ``Blob``
 
+  To check commands before they run (e.g., when loading a Tiltfile from another team),
+  start Tilt with ``--sandbox-local-commands=deny|prompt|allow``. ``deny`` fails the load and lists
+  the commands that would have run. ``prompt`` asks you to approve each new command, and can
+  remember the approval. ``allow`` runs them, recording each one in ``local-commands.log``
+  in the Tilt data dir. Add ``--sandbox-local-resources`` to also check the commands
+  of each :meth:`local_resource`.
+
   Args:
     command: Command to run. If a string, executed with ``sh -c`` on macOS/Linux, or ``cmd /S /C`` on Windows;
       if a list, will be passed to the operating system as program name and args.
@@ -896,7 +1115,7 @@ def encode_yaml_stream(objs: List[StructuredDataType]) -> Blob:
   """
   pass
 
-def default_registry(host: str, host_from_cluster: str = None, single_name: str = "") -> None:
+def default_registry(host: str, host_from_cluster: str = None, single_name: str = "", create_pull_secret_from: str = "", pull_secret_target: str = "") -> None:
   """Specifies that any images that Tilt builds should be renamed so that they have the specified Docker registry.
 
   This is useful if, e.g., a repo is configured to push to Google Container Registry, but you want to use Elastic Container Registry instead, without having to edit a bunch of configs. For example, ``default_registry("gcr.io/myrepo")`` would cause ``docker.io/alpine`` to be rewritten to ``gcr.io/myrepo/docker.io_alpine``
@@ -909,6 +1128,13 @@ def default_registry(host: str, host_from_cluster: str = None, single_name: str
     single_name: In ECR, each repository in a registry needs to be created up-front. single_name lets you
       set a single repository to push to (e.g., a personal dev repository), and embeds the image name in the
       tag instead.
+    create_pull_secret_from: If ``'docker-config'``, Tilt creates a ``tilt-registry-pull-secret`` Secret in each
+      namespace that runs images from the registry, with your local Docker credentials for that registry host
+      (from ``docker login`` or a credential helper). The credentials are re-read on every deploy, so the secret
+      stays fresh, and they're scrubbed from logs.
+    pull_secret_target: What references the pull secret. ``'pod'`` (the default) adds it to the ``imagePullSecrets``
+      of each pod spec that Tilt deploys with an image from the registry, and ``'serviceaccount'``
+      adds it to the ``default`` ServiceAccount of the namespace instead.
 
   Images are renamed following these rules:
 
@@ -934,7 +1160,8 @@ def custom_build(
     command_bat_val: str = "",
     outputs_image_ref_to: str = "",
     command_bat: Union[str, List[str]] = "",
-    image_deps: List[str] = []):
+    image_deps: List[str] = [],
+    build_timeout_secs: int = None):
   """Provide a custom command that will build an image.
 
   Example ::
@@ -959,6 +1186,8 @@ def custom_build(
     command: a command that, when run in the shell, builds an image puts it in the registry as ``ref``. In the
       default mode, must produce an image named ``$EXPECTED_REF``.  If a string, executed with ``sh -c`` on macOS/Linux,
       or ``cmd /S /C`` on Windows; if a list, will be passed to the operating system as program name and args.
+      When the user resets the resource (``tilt reset``), Tilt sets ``$TILT_NO_CACHE=1``, and the command should
+      build without its cache (e.g., ``docker build --no-cache``).
     deps: a list of files or directories to be added as dependencies to this image. Tilt will watch those files and will rebuild the image when they change. Only accepts real paths, not file globs.
     tag: Some tools can't change the image tag at runtime. They need a pre-specified tag. Tilt will set ``$EXPECTED_REF = image_name:tag``,
        then re-tag it with its own tag before pushing to your cluster.
@@ -972,6 +1201,11 @@ def custom_build(
     outputs_image_ref_to: Specifies a file path. When set, the custom build command must write a content-based
       tagged image ref to this file. Tilt will read that file after the cmd runs to get the image ref,
       and inject that image ref into the YAML. For more on content-based tags, see `why tilt uses immutable tags <custom_build.html#why-tilt-uses-immutable-tags>`_
+      The ref may include a digest (e.g., ``name:tag@sha256:...`` or ``name@sha256:...``), like the digest of an
+      image index pushed by ``docker buildx``. The file may also be the metadata file written by
+      ``docker buildx build --metadata-file``. With ``skips_local_docker=True``, the digest is kept in the
+      injected ref, so the cluster pulls the index and picks its own platform. Otherwise, Tilt looks up
+      the image for the local platform by tag.
     command_bat: If non-empty and on Windows, takes precedence over ``command``. Ignored on other platforms.
       If a string, executed as a Windows batch command executed with ``cmd /S /C``; if a list, will be passed to
       the operating system as program name and args.
@@ -1116,12 +1350,14 @@ def local_resource(name: str,
                    allow_parallel: bool=False,
                    links: Union[str, Link, List[Union[str, Link]]]=[],
                    tags: List[str] = [],
-                   env: Dict[str, str] = {},
-                   serve_env: Dict[str, str] = {},
+                   env: Dict[str, Union[str, ResourceValue]] = {},
+                   serve_env: Dict[str, Union[str, ResourceValue]] = {},
                    readiness_probe: Probe = None,
                    dir: str = "",
                    serve_dir: str = "",
-                   labels: List[str] = []) -> None:
+                   labels: List[str] = [],
+                   resource_deps_on_update: List[str] = [],
+                   degrade_on_failure: bool = False) -> None:
   """Configures one or more commands to run on the *host* machine (not in a remote cluster).
 
   By default, Tilt performs an update on local resources on ``tilt up`` and whenever any of their ``deps`` change.
@@ -1159,11 +1395,74 @@ def local_resource(name: str,
       conditions around modifying a shared file system. Set to True to allow them to run in parallel.
     links: one or more links to be associated with this resource in the Web UI (e.g. perhaps you have a "reset database" workflow and want to attach a link to the database web console). Provide one or more strings (the URLs to link to) or :class:`~api.Link` objects.
     env: Environment variables to pass to the executed ``cmd``. Values specified here will override any variables passed to the Tilt parent process.
+      Values can also be read from other resources with :meth:`from_resource`.
     serve_env: Environment variables to pass to the executed ``serve_cmd``. Values specified here will override any variables passed to the Tilt parent process.
+      Values can also be read from other resources with :meth:`from_resource`.
     readiness_probe: Optional readiness probe to use for determining ``serve_cmd`` health state. Fore more info, see the :meth:`probe` function.
     dir: Working directory for ``cmd``. Defaults to the Tiltfile directory.
     serve_dir: Working directory for ``serve_cmd``. Defaults to the Tiltfile directory.
     labels: used to group resources in the Web UI, (e.g. you want all frontend services displayed together, while test and backend services are displayed separately). A label must start and end with an alphanumeric character, can include ``_``, ``-``, and ``.``, and must be 63 characters or less. For an example, see `Resource Grouping <tiltfile_concepts.html#resource-groups>`_.
+    resource_deps_on_update: a list of resources that this resource covers (e.g., tests for a server).
+      ``cmd`` runs again after each successful build or live update of any of them. In ``TRIGGER_MODE_MANUAL``,
+      the resource is marked as having pending changes instead. These resources are also added to ``resource_deps``.
+    degrade_on_failure: If True, a failed ``cmd`` marks the resources in ``resource_deps_on_update`` as degraded.
+      If False (the default), the result is only informational.
+  """
+  pass
+
+class ResourceValue:
+  """A value read from another resource, returned by :meth:`from_resource`."""
+  pass
+
+def from_resource(resource: str, key: str) -> ResourceValue:
+  """Reads a value from another resource, for use in the ``env`` or ``serve_env`` of a :meth:`local_resource`.
+
+  For example, to point a local frontend at a port-forwarded API server:
+
+  .. code-block:: python
+
+    k8s_resource('api', port_forwards=8000)
+    local_resource('web', serve_cmd='npm start',
+                   serve_env={'API_URL': from_resource('api', 'port_forward_url')})
+
+  The value is resolved when the Tiltfile loads, so if you change the port forward,
+  ``web`` restarts with the new URL. The resource is also added to ``resource_deps``.
+
+  Args:
+    resource: the name of a :meth:`k8s_resource` or :meth:`local_resource`.
+    key: the value to read. One of:
+
+      - ``port_forward_url``: the URL of the resource's first port forward, e.g., ``http://localhost:8000/``
+      - ``port_forward_port``: the local port of the resource's first port forward, e.g., ``8000``
+      - ``link_url``: the URL of the resource's first link
+  """
+  pass
+
+def resource_group(name: str, members: List[str], labels: Union[str, List[str]] = []) -> None:
+  """Groups several resources into one logical resource.
+
+  For example, a service might be a Deployment, a Redis StatefulSet, and a local process:
+
+  .. code-block:: python
+
+    resource_group('auth', members=['auth-api', 'auth-redis', 'auth-refresher'])
+
+  The group shows up as a resource of its own:
+
+  - Its status is the worst status of its members (e.g., if any member has an error, so does the group).
+  - ``tilt trigger auth`` triggers every member, and ``tilt disable auth`` disables every member.
+  - ``tilt logs auth`` shows the logs of every member, prefixed with the member's name.
+
+  The members are still resources in their own right, and can be triggered,
+  disabled, and viewed individually.
+
+  A group can be a member of another group. Each resource (or group) can only
+  be a member of one group, and groups can't contain themselves.
+
+  Args:
+    name: the name of the group. It can't be the name of a resource.
+    members: the names of the resources (or other groups) in the group.
+    labels: used to group resources in the Web UI.
   """
   pass
 
@@ -1259,7 +1558,10 @@ def secret_settings(disable_scrub: bool = False) -> None:
 def update_settings(
     max_parallel_updates: int=3,
     k8s_upsert_timeout_secs: int=30,
-    suppress_unused_image_warnings: Union[str, List[str]]=None) -> None:
+    suppress_unused_image_warnings: Union[str, List[str]]=None,
+    k8s_live_update_transport: Union[str, Dict[str, str]]='auto',
+    build_timeout_secs: int=1800,
+    live_update_timeout_secs: int=300) -> None:
   """Configures Tilt's updates to your resources. (An update is any execution of or
   change to a resource. Examples of updates include: doing a docker build + deploy to
   Kubernetes; running a live update on an existing container; and executing
@@ -1270,6 +1572,17 @@ def update_settings(
     k8s_upsert_timeout_secs: timeout (in seconds) for Kubernetes upserts (i.e. ``create``/``apply`` calls). Minimum value is 1.
     suppress_unused_image_warnings: suppresses warnings about images that aren't deployed.
       Accepts a list of image names, or '*' to suppress warnings for all images.
+    k8s_live_update_transport: how live update copies files and runs commands in Kubernetes containers.
+      ``'exec'`` uses the Kubernetes exec API. ``'agent'`` uses the Tilt agent, a daemonset in the
+      ``tilt-agent`` namespace that updates containers through the container runtime on each node,
+      for clusters that don't allow ``pods/exec``. Your cluster admin installs it from
+      ``cmd/tilt-agent/tilt-agent.yaml`` in the Tilt repo; it only updates containers that Tilt deployed. ``'auto'`` (the default) uses exec if you're allowed
+      to, and the agent otherwise. To choose per cluster, pass a dict from kube context name to
+      transport, e.g. ``{'kind-kind': 'exec', 'gke_prod': 'agent'}``; contexts not in the dict use ``'auto'``.
+    build_timeout_secs: how long an image build may run before Tilt cancels it and marks it failed. Default is 1800 (30 minutes).
+      ``0`` means no timeout. ``docker_build`` and ``custom_build`` may override it.
+    live_update_timeout_secs: how long each ``run`` step of a live update may run in the container
+      before Tilt cancels it and fails the update. Default is 300 (5 minutes). ``0`` means no timeout.
 """
 
 def ci_settings(
@@ -1282,7 +1595,7 @@ def ci_settings(
     timeout: Timeout for the whole CI pipeline. A duration string. Defaults to '30m'.
   """
 
-def watch_settings(ignore: Union[str, List[str]]) -> None:
+def watch_settings(ignore: Union[str, List[str]] = [], ignore_extension_cache: bool = False, use_gitignore: bool = False) -> None:
   """Configures global watches.
 
   May be called multiple times to add more ignore patterns.
@@ -1291,6 +1604,15 @@ def watch_settings(ignore: Union[str, List[str]]) -> None:
     ignore: A string or list of strings that should not trigger updates. Equivalent to adding
       patterns to .tiltignore. Relative patterns are evaluated relative to the current working dir.
       See `Debugging File Changes <file_changes.html>`_ for more details.
+    ignore_extension_cache: By default, Tilt reloads the Tiltfile when any file it loads changes,
+      including extensions. If True, changes to extensions that Tilt downloaded (rather than extensions
+      in a local ``file://`` repo) don't trigger a reload. Run ``tilt dump tiltfile-deps`` to see the watched files.
+    use_gitignore: If True, Tilt reads the ``.gitignore`` files (at the root and nested) of every git repo
+      that contains the Tiltfile, an image's build context, or a local resource's deps. Files they ignore
+      don't trigger updates, and are left out of build contexts. Each pattern is recorded as
+      ``gitignore:<path>:<line>``. If a ``.gitignore`` ignores a file that a Dockerfile COPYs by name,
+      Tilt warns and keeps that file in the build context. Files the Tiltfile reads, like
+      ``tilt_config.json``, still trigger a reload.
   """
 
 
@@ -461,6 +461,7 @@ def kubernetes_discovery(
   extra_selectors: List[LabelSelector] = None,
   port_forward_template_spec: Optional[PortForwardTemplateSpec] = None,
   pod_log_stream_template_spec: Optional[PodLogStreamTemplateSpec] = None,
+  discovery_strategy: str = "",
 ):
   """
   KubernetesDiscovery
@@ -494,6 +495,16 @@ def kubernetes_discovery(
       If no template is specified, the controller will stream all
       pod logs available from the apiserver.
       
+    discovery_strategy: DiscoveryStrategy describes how the ExtraSelectors are matched.
+      
+      With the selectors-and-owners strategy, ExtraSelectors only match Pods
+      in the namespaces of the Watches, and a Pod that's traced to a WatchRef
+      of another KubernetesDiscovery object is left to that object.
+      
+      If a Pod only matches the ExtraSelectors of more than one object, it's
+      assigned to the object that sorts first by name.
+      
+      Every other strategy matches ExtraSelectors against all known Pods.
 """
   pass
 def ui_button(
@@ -0,0 +1,107 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+const baseImageDependencyN = "base_image_dependency"
+
+// A base image that another Tilt session builds with
+// docker_build(published=...).
+type baseImageDependency struct {
+	ref  reference.Named
+	path string
+
+	// Whether any docker_build() image builds on it.
+	used bool
+}
+
+func (s *tiltfileState) baseImageDependency(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refStr string
+	watch := value.NewLocalPathUnpacker(thread)
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"ref", &refStr,
+		"watch", &watch,
+	); err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(refStr)
+	if err != nil {
+		return nil, fmt.Errorf("%s: can't parse %q: %v", fn.Name(), refStr, err)
+	}
+
+	for _, dep := range s.baseImageDeps {
+		if dep.ref.Name() == ref.Name() {
+			return nil, fmt.Errorf("%s: %s declared twice", fn.Name(), container.FamiliarString(ref))
+		}
+	}
+
+	s.baseImageDeps = append(s.baseImageDeps, &baseImageDependency{ref: ref, path: watch.Value})
+	return starlark.None, nil
+}
+
+// Finds the base_image_dependency() for an image in a Dockerfile's FROM, if
+// any.
+func (s *tiltfileState) findBaseImageDependency(ref reference.Named) *baseImageDependency {
+	for _, dep := range s.baseImageDeps {
+		if container.NewRefSelector(dep.ref).Matches(ref) {
+			return dep
+		}
+	}
+	return nil
+}
+
+func (s *tiltfileState) warnUnusedBaseImageDependencies() {
+	for _, dep := range s.baseImageDeps {
+		if !dep.used {
+			s.logger.Warnf("%s(%s): no docker_build() image builds on it",
+				baseImageDependencyN, container.FamiliarString(dep.ref))
+		}
+	}
+}
+
+func (d *baseImageDependency) spec() v1alpha1.DockerImageBaseImage {
+	return v1alpha1.DockerImageBaseImage{Ref: d.ref.String(), Path: d.path}
+}
+
+// Reads docker_build(published=...). True publishes to a file named after
+// the image in .tilt/published, next to the Tiltfile; a string publishes to
+// that path.
+func publishPathFromValue(thread *starlark.Thread, ref reference.Named, v starlark.Value) (string, error) {
+	switch v := v.(type) {
+	case nil, starlark.NoneType:
+		return "", nil
+	case starlark.Bool:
+		if !v {
+			return "", nil
+		}
+		name := strings.NewReplacer("/", "_", ":", "_").Replace(reference.FamiliarName(ref))
+		return filepath.Join(starkit.AbsWorkingDir(thread), ".tilt", "published", name+".json"), nil
+	case starlark.String:
+		if v.GoString() == "" {
+			return "", fmt.Errorf("Argument 'published': path must not be empty")
+		}
+		return starkit.AbsPath(thread, v.GoString()), nil
+	}
+	return "", fmt.Errorf("Argument 'published': expected bool or string, got %s", v.Type())
+}
+
+func containsBaseImageDep(deps []*baseImageDependency, dep *baseImageDependency) bool {
+	for _, d := range deps {
+		if d == dep {
+			return true
+		}
+	}
+	return false
+}
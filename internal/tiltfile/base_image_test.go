@@ -0,0 +1,126 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+func TestBaseImageDependency(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "FROM gcr.io/acme/base:latest\nRUN make")
+	f.file("Tiltfile", `
+base_image_dependency('gcr.io/acme/base', watch='../platform/published/base.json')
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.')
+`)
+
+	f.load()
+	m := f.assertNextManifest("fe")
+	iTarget := m.ImageTargetAt(0)
+	path := f.JoinPath("..", "platform", "published", "base.json")
+	assert.Equal(t, []v1alpha1.DockerImageBaseImage{
+		{Ref: "gcr.io/acme/base", Path: path},
+	}, iTarget.DockerBuildInfo().BaseImages)
+	assert.Contains(t, iTarget.Dependencies(), path)
+}
+
+func TestBaseImageDependencyOnlyImagesThatUseIt(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.yaml("be.yaml", deployment("be", image("gcr.io/be")))
+	f.file("fe/Dockerfile", "FROM gcr.io/acme/base")
+	f.file("be/Dockerfile", "FROM alpine")
+	f.file("Tiltfile", `
+base_image_dependency('gcr.io/acme/base', watch='base.json')
+k8s_yaml(['fe.yaml', 'be.yaml'])
+docker_build('gcr.io/fe', 'fe')
+docker_build('gcr.io/be', 'be')
+`)
+
+	f.load()
+	fe := f.assertNextManifest("fe")
+	assert.Len(t, fe.ImageTargetAt(0).DockerBuildInfo().BaseImages, 1)
+	be := f.assertNextManifest("be")
+	assert.Empty(t, be.ImageTargetAt(0).DockerBuildInfo().BaseImages)
+	assert.NotContains(t, be.ImageTargetAt(0).Dependencies(), f.JoinPath("base.json"))
+}
+
+func TestBaseImageDependencyUnused(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "FROM alpine")
+	f.file("Tiltfile", `
+base_image_dependency('gcr.io/acme/base', watch='base.json')
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.')
+`)
+
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	assert.Contains(t, f.warnings[0], "base_image_dependency(gcr.io/acme/base): no docker_build() image builds on it")
+}
+
+func TestBaseImageDependencyDuplicate(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+base_image_dependency('gcr.io/acme/base', watch='a.json')
+base_image_dependency('gcr.io/acme/base:v2', watch='b.json')
+`)
+
+	f.loadErrString("base_image_dependency: gcr.io/acme/base:v2 declared twice")
+}
+
+func TestDockerBuildPublished(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/acme/base")))
+	f.file("Dockerfile", "FROM alpine")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/acme/base', '.', published=True)
+`)
+
+	f.load()
+	publishPath := f.JoinPath(".tilt", "published", "gcr.io_acme_base.json")
+	m := f.assertNextManifest("fe",
+		fileChangeMatches("main.go"),
+		fileChangeFilters(".tilt/published/gcr.io_acme_base.json"),
+		fileChangeFilters(".tilt/published/gcr.io_acme_base.json.tmp"),
+	)
+	assert.Equal(t, publishPath, m.ImageTargetAt(0).DockerBuildInfo().PublishPath)
+}
+
+func TestDockerBuildPublishedPath(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/acme/base")))
+	f.file("Dockerfile", "FROM alpine")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/acme/base', '.', published='published/base.json')
+`)
+
+	f.load()
+	m := f.assertNextManifest("fe")
+	assert.Equal(t, f.JoinPath("published", "base.json"), m.ImageTargetAt(0).DockerBuildInfo().PublishPath)
+}
+
+func TestDockerBuildPublishedBadValue(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Dockerfile", "FROM alpine")
+	f.file("Tiltfile", `
+docker_build('gcr.io/acme/base', '.', published=1)
+`)
+
+	f.loadErrString("Argument 'published': expected bool or string, got int")
+}
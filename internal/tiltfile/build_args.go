@@ -0,0 +1,107 @@
+package tiltfile
+
+import (
+	"fmt"
+	"sort"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// A build arg value that's resolved at build time, rather than
+// when the Tiltfile is loaded.
+type dynamicBuildArg struct {
+	cmd  model.Cmd
+	path string
+}
+
+var _ starlark.Value = dynamicBuildArg{}
+
+func (a dynamicBuildArg) String() string {
+	if a.path != "" {
+		return fmt.Sprintf("arg_from_file(%q)", a.path)
+	}
+	return fmt.Sprintf("arg_from_cmd(%q)", a.cmd.String())
+}
+func (a dynamicBuildArg) Type() string         { return "dynamic_build_arg" }
+func (a dynamicBuildArg) Freeze()              {}
+func (a dynamicBuildArg) Truth() starlark.Bool { return true }
+func (a dynamicBuildArg) Hash() (uint32, error) {
+	return starlark.Tuple{starlark.String(a.cmd.String()), starlark.String(a.path)}.Hash()
+}
+
+func (s *tiltfileState) argFromCmd(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var cmdVal, dirVal starlark.Value
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"cmd", &cmdVal,
+		"dir?", &dirVal); err != nil {
+		return nil, err
+	}
+
+	cmd, err := value.ValueToHostCmd(thread, cmdVal, dirVal, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 'cmd': %v", err)
+	}
+	if cmd.Empty() {
+		return nil, fmt.Errorf("Argument 'cmd' must not be empty")
+	}
+	return dynamicBuildArg{cmd: cmd}, nil
+}
+
+func (s *tiltfileState) argFromFile(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	path := value.NewLocalPathUnpacker(thread)
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"path", &path); err != nil {
+		return nil, err
+	}
+	return dynamicBuildArg{path: path.Value}, nil
+}
+
+// Splits the build_args dict into args with values known at load time
+// (formatted for the docker CLI) and args resolved at build time.
+func parseBuildArgs(v starlark.Value) ([]string, []v1alpha1.DockerImageDynamicArg, error) {
+	static := []string{}
+	if v == nil || v == starlark.None {
+		return static, nil, nil
+	}
+
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return nil, nil, fmt.Errorf("Argument 'build_args': expected dict, got %s", v.Type())
+	}
+
+	var dynamic []v1alpha1.DockerImageDynamicArg
+	for _, item := range d.Items() {
+		k, ok := value.AsString(item[0])
+		if !ok {
+			return nil, nil, fmt.Errorf("Argument 'build_args': key must be a string, got %s", item[0].Type())
+		}
+
+		switch val := item[1].(type) {
+		case dynamicBuildArg:
+			dynamic = append(dynamic, v1alpha1.DockerImageDynamicArg{
+				Name:    k,
+				Command: val.cmd.Argv,
+				Dir:     val.cmd.Dir,
+				Path:    val.path,
+			})
+		default:
+			v, ok := value.AsString(val)
+			if !ok {
+				return nil, nil, fmt.Errorf("Argument 'build_args': value for %q must be a string, "+
+					"arg_from_cmd(), or arg_from_file(), got %s", k, val.Type())
+			}
+			if v == "" {
+				static = append(static, k)
+			} else {
+				static = append(static, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+	}
+	sort.Strings(static)
+	sort.Slice(dynamic, func(i, j int) bool { return dynamic[i].Name < dynamic[j].Name })
+	return static, dynamic, nil
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/moby/buildkit/frontend/dockerfile/dockerignore"
@@ -15,6 +15,8 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/golist"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
@@ -57,6 +59,7 @@ type dockerImage struct {
 	// dbBuildPath may be empty if the user is building from a URL
 	dbBuildPath   string
 	dbBuildArgs   []string
+	dbDynamicArgs []v1alpha1.DockerImageDynamicArg
 	customCommand model.Cmd
 	customDeps    []string
 	customTag     string
@@ -77,10 +80,23 @@ type dockerImage struct {
 	// TODO(milas): we should have a better way of passing the Tiltfile path around during resource assembly
 	tiltfilePath string
 
+	// Set if the Tiltfile declared the Go main package (go_main) and
+	// we were able to find its dependencies.
+	goDeps *golist.Deps
+
+	// Set by docker_build(published=...).
+	publishPath string
+
+	// Images from base_image_dependency() that the Dockerfile builds on.
+	baseImageDeps []*baseImageDependency
+
 	dockerComposeService          string
 	dockerComposeLocalVolumePaths []string
 
 	extraHosts []string
+
+	// Set by build_timeout_secs=, to override the default from update_settings.
+	buildTimeout *time.Duration
 }
 
 func (d *dockerImage) ID() model.TargetID {
@@ -108,21 +124,24 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	var dockerRef, targetStage string
 	contextVal := value.NewLocalPathUnpacker(thread)
 	dockerfilePathVal := value.NewLocalPathUnpacker(thread)
+	goMainVal := value.NewLocalPathUnpacker(thread)
 	var dockerfileContentsVal,
 		cacheVal,
 		liveUpdateVal,
+		publishedVal,
 		ignoreVal,
 		onlyVal,
-		entrypoint starlark.Value
-	var buildArgs value.StringStringMap
+		entrypoint,
+		buildArgsVal starlark.Value
 	var network, platform value.Stringable
 	var ssh, secret, extraTags, cacheFrom, extraHosts value.StringOrStringList
 	var matchInEnvVars, pullParent bool
 	var overrideArgsVal starlark.Sequence
+	var buildTimeoutVal starlark.Value
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
 		"context", &contextVal,
-		"build_args?", &buildArgs,
+		"build_args?", &buildArgsVal,
 		"dockerfile??", &dockerfilePathVal,
 		"dockerfile_contents?", &dockerfileContentsVal,
 		"cache?", &cacheVal,
@@ -141,6 +160,9 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		"pull?", &pullParent,
 		"platform?", &platform,
 		"extra_hosts?", &extraHosts,
+		"go_main?", &goMainVal,
+		"published?", &publishedVal,
+		"build_timeout_secs?", &buildTimeoutVal,
 	); err != nil {
 		return nil, err
 	}
@@ -180,6 +202,14 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		dockerfileContents = string(bs)
 	}
 
+	df, err := dockerfile.ResolveIncludes(dockerfile.Dockerfile(dockerfileContents), dockerfilePath,
+		func(p string) ([]byte, error) {
+			return io.ReadFile(thread, p)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
 	if cacheVal != nil {
 		s.logger.Warnf("%s", cacheObsoleteWarning)
 	}
@@ -226,24 +256,40 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		platform.Value = os.Getenv(dockerPlatformEnv)
 	}
 
-	buildArgsList := []string{}
-	for k, v := range buildArgs.AsMap() {
-		if v == "" {
-			buildArgsList = append(buildArgsList, k)
-		} else {
-			buildArgsList = append(buildArgsList, fmt.Sprintf("%s=%s", k, v))
+	buildArgsList, dynamicBuildArgs, err := parseBuildArgs(buildArgsVal)
+	if err != nil {
+		return nil, err
+	}
+
+	s.warnInvalidFromRefs(ref, df, buildArgsList, dynamicBuildArgs)
+
+	var goDeps *golist.Deps
+	if goMainVal.IsSet {
+		goDeps, err = s.goDepsForImage(thread, context, goMainVal.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Argument 'go_main': %v", err)
 		}
 	}
-	sort.Strings(buildArgsList)
+
+	publishPath, err := publishPathFromValue(thread, ref, publishedVal)
+	if err != nil {
+		return nil, err
+	}
+
+	buildTimeout, err := buildTimeoutFromValue(buildTimeoutVal)
+	if err != nil {
+		return nil, err
+	}
 
 	r := &dockerImage{
 		buildType:        DockerBuild,
 		workDir:          starkit.CurrentExecPath(thread),
 		dbDockerfilePath: dockerfilePath,
-		dbDockerfile:     dockerfile.Dockerfile(dockerfileContents),
+		dbDockerfile:     df,
 		dbBuildPath:      context,
 		configurationRef: container.NewRefSelector(ref),
 		dbBuildArgs:      buildArgsList,
+		dbDynamicArgs:    dynamicBuildArgs,
 		liveUpdate:       liveUpdate,
 		matchInEnvVars:   matchInEnvVars,
 		sshSpecs:         ssh.Values,
@@ -260,6 +306,9 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 		platform:         platform.Value,
 		tiltfilePath:     starkit.CurrentExecPath(thread),
 		extraHosts:       extraHosts.Values,
+		goDeps:           goDeps,
+		publishPath:      publishPath,
+		buildTimeout:     buildTimeout,
 	}
 	err = s.buildIndex.addImage(r)
 	if err != nil {
@@ -269,6 +318,75 @@ func (s *tiltfileState) dockerBuild(thread *starlark.Thread, fn *starlark.Builti
 	return starlark.None, nil
 }
 
+// Warns about FROMs that expand to an empty or invalid image, so that the
+// user finds out at load time instead of when Docker fails the build.
+//
+// Build args from arg_from_cmd() or arg_from_file() aren't known until the
+// build, so FROMs that use them aren't checked.
+func (s *tiltfileState) warnInvalidFromRefs(ref reference.Named, df dockerfile.Dockerfile, buildArgs []string, dynamicArgs []v1alpha1.DockerImageDynamicArg) {
+	ast, err := dockerfile.ParseAST(df)
+	if err != nil {
+		return
+	}
+	problems, err := ast.InvalidFromRefs(buildArgs)
+	if err != nil {
+		return
+	}
+
+	isDynamic := make(map[string]bool, len(dynamicArgs))
+	for _, arg := range dynamicArgs {
+		isDynamic[arg.Name] = true
+	}
+
+	for _, p := range problems {
+		usesDynamic := false
+		for _, arg := range p.Args {
+			usesDynamic = usesDynamic || isDynamic[arg]
+		}
+		if !usesDynamic {
+			s.logger.Warnf("docker_build(%s): %s", container.FamiliarString(ref), p.Message())
+		}
+	}
+}
+
+// Finds the Go packages that the image's main package depends on, so that
+// edits to other Go files in the context don't trigger a build.
+//
+// If `go list` fails, we warn and return nil, so that the image falls back
+// to watching the whole context.
+func (s *tiltfileState) goDepsForImage(thread *starlark.Thread, context string, goMain string) (*golist.Deps, error) {
+	rel, ok := ospath.Child(context, goMain)
+	if !ok {
+		return nil, fmt.Errorf("%s is not inside the build context %s", goMain, context)
+	}
+	main := "."
+	if rel != "." {
+		main = "./" + filepath.ToSlash(rel)
+	}
+
+	ctx, err := starkit.ContextFromThread(thread)
+	if err != nil {
+		return nil, err
+	}
+
+	deps, depsErr := s.goDeps.Deps(ctx, context, main)
+
+	// Reload the Tiltfile when the modules change, so that we pick up new
+	// dependencies (or retry, if go list failed).
+	for _, p := range (golist.Deps{Dir: context}).ModuleFiles() {
+		err := io.RecordReadPath(thread, io.WatchFileOnly, p)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if depsErr != nil {
+		s.logger.Warnf("go_main: %v\nWatching every file in %s instead.", depsErr, context)
+		return nil, nil
+	}
+	return &deps, nil
+}
+
 func (s *tiltfileState) parseOnly(val starlark.Value) ([]string, error) {
 	paths, err := parseValuesToStrings(val, "only")
 	if err != nil {
@@ -299,6 +417,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 	var skipsLocalDocker bool
 	var imageDeps value.ImageList
 	outputsImageRefTo := value.NewLocalPathUnpacker(thread)
+	var buildTimeoutVal starlark.Value
 
 	err := s.unpackArgs(fn.Name(), args, kwargs,
 		"ref", &dockerRef,
@@ -320,6 +439,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		"command_bat", &commandBat,
 
 		"image_deps", &imageDeps,
+		"build_timeout_secs?", &buildTimeoutVal,
 	)
 	if err != nil {
 		return nil, err
@@ -369,6 +489,11 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		return nil, fmt.Errorf("Cannot specify both tag= and outputs_image_ref_to=")
 	}
 
+	buildTimeout, err := buildTimeoutFromValue(buildTimeoutVal)
+	if err != nil {
+		return nil, err
+	}
+
 	img := &dockerImage{
 		buildType:         CustomBuild,
 		workDir:           starkit.AbsWorkingDir(thread),
@@ -386,6 +511,7 @@ func (s *tiltfileState) customBuild(thread *starlark.Thread, fn *starlark.Builti
 		overrideArgs:      overrideArgs,
 		outputsImageRefTo: outputsImageRefTo.Value,
 		tiltfilePath:      starkit.CurrentExecPath(thread),
+		buildTimeout:      buildTimeout,
 	}
 
 	err = s.buildIndex.addImage(img)
@@ -425,6 +551,27 @@ func (b *customBuild) AttrNames() []string {
 	return []string{}
 }
 
+// Parses build_timeout_secs=. Returns nil if it wasn't passed, so that the
+// image uses the default from update_settings. 0 means no timeout.
+func buildTimeoutFromValue(v starlark.Value) (*time.Duration, error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return nil, nil
+	case starlark.Int:
+		secs, err := starlark.AsInt32(x)
+		if err != nil {
+			return nil, fmt.Errorf("Argument 'build_timeout_secs': %v", err)
+		}
+		if secs < 0 {
+			return nil, fmt.Errorf("Argument 'build_timeout_secs' must be >= 0 (0 means no timeout); got %d", secs)
+		}
+		timeout := time.Duration(secs) * time.Second
+		return &timeout, nil
+	default:
+		return nil, fmt.Errorf("Argument 'build_timeout_secs': got %T, want int", x)
+	}
+}
+
 func parseValuesToStrings(value starlark.Value, param string) ([]string, error) {
 
 	tempIgnores := starlarkValueOrSequenceToSlice(value)
@@ -469,6 +616,11 @@ func repoIgnoresForPaths(paths []string) []v1alpha1.IgnoreDef {
 }
 
 func (s *tiltfileState) repoIgnoresForImage(image *dockerImage) []v1alpha1.IgnoreDef {
+	return repoIgnoresForPaths(repoPathsForImage(image))
+}
+
+// The paths that may be in the image's repos.
+func repoPathsForImage(image *dockerImage) []string {
 	var paths []string
 	paths = append(paths, image.dbDockerfilePath)
 	if image.dbBuildPath != "" {
@@ -476,8 +628,7 @@ func (s *tiltfileState) repoIgnoresForImage(image *dockerImage) []v1alpha1.Ignor
 	}
 	paths = append(paths, image.workDir)
 	paths = append(paths, image.customDeps...)
-
-	return repoIgnoresForPaths(paths)
+	return paths
 }
 
 func (s *tiltfileState) defaultRegistry(t *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
@@ -485,14 +636,33 @@ func (s *tiltfileState) defaultRegistry(t *starlark.Thread, fn *starlark.Builtin
 		return starlark.None, errors.New("default registry already defined")
 	}
 
-	var host, hostFromCluster, singleName string
+	var host, hostFromCluster, singleName, pullSecretFrom, pullSecretTarget string
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"host", &host,
 		"host_from_cluster?", &hostFromCluster,
-		"single_name?", &singleName); err != nil {
+		"single_name?", &singleName,
+		"create_pull_secret_from?", &pullSecretFrom,
+		"pull_secret_target?", &pullSecretTarget); err != nil {
 		return nil, err
 	}
 
+	switch pullSecretFrom {
+	case "", k8s.PullSecretFromDockerConfig:
+	default:
+		return nil, fmt.Errorf("%s: create_pull_secret_from must be %q, got %q",
+			fn.Name(), k8s.PullSecretFromDockerConfig, pullSecretFrom)
+	}
+
+	switch pullSecretTarget {
+	case "", k8s.PullSecretTargetPod, k8s.PullSecretTargetServiceAccount:
+	default:
+		return nil, fmt.Errorf("%s: pull_secret_target must be %q or %q, got %q",
+			fn.Name(), k8s.PullSecretTargetPod, k8s.PullSecretTargetServiceAccount, pullSecretTarget)
+	}
+	if pullSecretTarget != "" && pullSecretFrom == "" {
+		return nil, fmt.Errorf("%s: pull_secret_target requires create_pull_secret_from", fn.Name())
+	}
+
 	reg := &v1alpha1.RegistryHosting{
 		Host:                     host,
 		HostFromContainerRuntime: hostFromCluster,
@@ -511,6 +681,12 @@ func (s *tiltfileState) defaultRegistry(t *starlark.Thread, fn *starlark.Builtin
 	reg.SingleName = singleName
 
 	s.defaultReg = reg
+	if pullSecretFrom != "" {
+		s.defaultRegPullSecret = &k8s.PullSecretSettings{
+			From:   pullSecretFrom,
+			Target: pullSecretTarget,
+		}
+	}
 
 	return starlark.None, nil
 }
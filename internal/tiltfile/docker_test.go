@@ -257,3 +257,104 @@ func TestExtraHosts(t *testing.T) {
 		)
 	}
 }
+
+func TestDockerBuildGoMain(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "FROM golang")
+	f.file("go.mod", "module example.com/app\n\ngo 1.20\n")
+	f.file("cmd/api/main.go", "package main\n\nimport _ \"example.com/app/pkg/db\"\n\nfunc main() {}\n")
+	f.file("pkg/db/db.go", "package db\n")
+	f.file("pkg/queue/queue.go", "package queue\n")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', go_main='./cmd/api')
+`)
+
+	f.load()
+	m := f.assertNextManifest("fe",
+		fileChangeMatches("cmd/api/main.go"),
+		fileChangeMatches("pkg/db/db.go"),
+		fileChangeFilters("pkg/db/db_test.go"),
+		fileChangeFilters("pkg/queue/queue.go"),
+		fileChangeMatches("pkg/queue/schema.sql"),
+		fileChangeMatches("go.mod"),
+	)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "Dockerfile", ".dockerignore", "fe.yaml", "go.mod")
+
+	goDeps := m.ImageTargetAt(0).DockerBuildInfo().GoDeps
+	require.NotNil(t, goDeps)
+	assert.Equal(t, "./cmd/api", goDeps.Main)
+	assert.Equal(t, int32(2), goDeps.Packages)
+	assert.False(t, goDeps.RefreshedAt.IsZero())
+
+	// The build context is unchanged.
+	assert.NotContains(t, m.ImageTargetAt(0).DockerBuildInfo().ContextIgnores,
+		v1alpha1.IgnoreDef{BasePath: f.Path(), Patterns: []string{"**/*.go"}})
+}
+
+func TestDockerBuildGoMainFallsBackToContext(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "FROM golang")
+	f.file("go.mod", "module example.com/app\n\ngo 1.20\n")
+	f.file("pkg/queue/queue.go", "package queue\n")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', go_main='./cmd/api')
+`)
+
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	assert.Contains(t, f.warnings[0], "go_main: go list -deps ./cmd/api")
+	assert.Contains(t, f.warnings[0], "Watching every file in")
+
+	m := f.assertNextManifest("fe",
+		fileChangeMatches("pkg/queue/queue.go"),
+	)
+	assert.Nil(t, m.ImageTargetAt(0).DockerBuildInfo().GoDeps)
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "Dockerfile", ".dockerignore", "fe.yaml", "go.mod")
+}
+
+func TestDockerBuildGoMainOutsideContext(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("fe/Dockerfile", "FROM golang")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', 'fe', go_main='./cmd/api')
+`)
+
+	f.loadErrString("Argument 'go_main'", "is not inside the build context")
+}
+
+func TestDockerBuildFromEmptyArg(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "ARG BASE\nFROM ${BASE}\n")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.')
+`)
+
+	f.loadAssertWarnings("docker_build(gcr.io/fe): FROM at line 2 expands to an empty image " +
+		"because ARG BASE has no value; pass build_args={'BASE': ...}")
+}
+
+func TestDockerBuildFromArgPassed(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("fe.yaml", deployment("fe", image("gcr.io/fe")))
+	f.file("Dockerfile", "ARG BASE\nFROM ${BASE}\n")
+	f.file("Tiltfile", `
+k8s_yaml('fe.yaml')
+docker_build('gcr.io/fe', '.', build_args={'BASE': 'alpine'})
+`)
+
+	f.load()
+	f.assertNextManifest("fe")
+}
@@ -11,7 +11,9 @@ import (
 
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
+	"github.com/tilt-dev/tilt/internal/statefile"
 	tiltfile_io "github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -60,6 +62,14 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 		return nil, err
 	}
 
+	allowed, err := s.checkSandbox(thread, fn.Name(), cmd, false)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return tiltfile_io.NewBlob("", fmt.Sprintf("local: %s", cmd)), nil
+	}
+
 	execOptions := execCommandOptions{
 		logOutput:        !quiet,
 		logCommand:       !echoOff,
@@ -77,6 +87,27 @@ func (s *tiltfileState) local(thread *starlark.Thread, fn *starlark.Builtin, arg
 	return tiltfile_io.NewBlob(out, fmt.Sprintf("local: %s", cmd)), nil
 }
 
+// checkSandbox asks the sandbox whether a command may run, and remembers
+// blocked commands so that the load can fail with the full list.
+func (s *tiltfileState) checkSandbox(t *starlark.Thread, source string, cmd model.Cmd, localResource bool) (bool, error) {
+	if cmd.Empty() || !s.sandbox.Enabled(localResource) {
+		return true, nil
+	}
+
+	r := sandbox.Request{Source: source, Tiltfile: starkit.CurrentExecPath(t), Cmd: cmd}
+	allowed, err := s.sandbox.Check(r)
+	if statefile.IsCorrupt(err) {
+		s.logger.Warnf("Sandbox approvals: %v", err)
+	} else if err != nil {
+		return false, err
+	}
+	if !allowed {
+		s.logger.Warnf("Sandbox blocked %s: %s", source, cmd)
+		s.sandboxBlocked = append(s.sandboxBlocked, r)
+	}
+	return allowed, nil
+}
+
 func (s *tiltfileState) execLocalCmd(t *starlark.Thread, cmd model.Cmd, options execCommandOptions) (string, error) {
 	var stdoutBuf, stderrBuf bytes.Buffer
 	ctx, err := starkit.ContextFromThread(t)
@@ -91,6 +122,7 @@ func (s *tiltfileState) execLocalCmd(t *starlark.Thread, cmd model.Cmd, options
 		}
 		s.logger.Infof("%s %s", prefix, cmd)
 	}
+	s.progress.onCommand(t, cmd)
 
 	var runIO localexec.RunIO
 	if options.logOutput {
@@ -0,0 +1,301 @@
+package tiltfile
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"go.starlark.net/starlark"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/io"
+	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+const (
+	// The hash of the data of a ConfigMap or Secret made by configmap_create()
+	// or secret_create().
+	annotationContentHash = "tilt.dev/content-hash"
+
+	// The hashes of the generated ConfigMaps and Secrets that a pod template
+	// uses. When any of them changes, the pods roll.
+	annotationConfigHash = "tilt.dev/config-hash"
+)
+
+// A ConfigMap or Secret made from files by configmap_create() or
+// secret_create().
+type generatedConfig struct {
+	entity k8s.K8sEntity
+	kind   string
+
+	// The name that pod specs use to refer to the object. With
+	// append_hash=True, the object's name has the hash appended.
+	name      string
+	namespace string
+	hash      string
+}
+
+func (s *tiltfileState) configmapCreate(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return s.createGeneratedConfig(thread, fn, args, kwargs, "ConfigMap")
+}
+
+func (s *tiltfileState) secretCreate(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return s.createGeneratedConfig(thread, fn, args, kwargs, "Secret")
+}
+
+func (s *tiltfileState) createGeneratedConfig(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple, kind string) (starlark.Value, error) {
+	var name, namespace string
+	var fromFiles value.StringStringMap
+	var appendHash bool
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"from_files", &fromFiles,
+		"namespace?", &namespace,
+		"append_hash?", &appendHash,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(fromFiles) == 0 {
+		return nil, fmt.Errorf("%s: from_files must not be empty", fn.Name())
+	}
+
+	data := make(map[string][]byte, len(fromFiles))
+	for key, p := range fromFiles {
+		bs, err := io.ReadFile(thread, starkit.AbsPath(thread, p))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+		data[key] = bs
+	}
+
+	hash := configDataHash(data)
+	meta := metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   namespace,
+		Annotations: map[string]string{annotationContentHash: hash},
+	}
+	if appendHash {
+		meta.Name = fmt.Sprintf("%s-%s", name, hash)
+	}
+
+	var obj runtime.Object
+	if kind == "Secret" {
+		obj = &v1.Secret{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: kind},
+			ObjectMeta: meta,
+			Type:       v1.SecretTypeOpaque,
+			Data:       data,
+		}
+	} else {
+		cm := &v1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: kind},
+			ObjectMeta: meta,
+		}
+		for key, bs := range data {
+			if utf8.Valid(bs) {
+				if cm.Data == nil {
+					cm.Data = make(map[string]string)
+				}
+				cm.Data[key] = string(bs)
+			} else {
+				if cm.BinaryData == nil {
+					cm.BinaryData = make(map[string][]byte)
+				}
+				cm.BinaryData[key] = bs
+			}
+		}
+		obj = cm
+	}
+
+	entity := k8s.NewK8sEntity(obj)
+	err := s.k8sObjectIndex.Append(thread, []k8s.K8sEntity{entity}, false)
+	if err != nil {
+		return nil, err
+	}
+	s.k8sUnresourced = append(s.k8sUnresourced, entity)
+	s.generatedConfigs = append(s.generatedConfigs, generatedConfig{
+		entity:    entity,
+		kind:      kind,
+		name:      name,
+		namespace: namespace,
+		hash:      hash,
+	})
+
+	return starlark.None, nil
+}
+
+// A short hash of the data, stable across key order.
+func configDataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(h, "%s\x00%d\x00", key, len(data[key]))
+		_, _ = h.Write(data[key])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))[:10]
+}
+
+// linkGeneratedConfigs points each workload at the generated ConfigMaps and
+// Secrets it uses: it rewrites references to names with hashes appended,
+// and annotates the pod template with their hashes, so that the pods roll
+// when the files change.
+//
+// Returns the generated objects that each workload uses.
+func (s *tiltfileState) linkGeneratedConfigs() (map[k8s.K8sEntity][]k8s.K8sEntity, error) {
+	result := make(map[k8s.K8sEntity][]k8s.K8sEntity)
+	if len(s.generatedConfigs) == 0 {
+		return result, nil
+	}
+
+	for _, e := range s.k8sUnresourced {
+		podSpecs, err := k8s.ExtractPods(&e)
+		if err != nil {
+			return nil, err
+		}
+		if len(podSpecs) == 0 {
+			continue
+		}
+
+		var used []generatedConfig
+		for _, gc := range s.generatedConfigs {
+			if !namespacesMatch(gc.namespace, e.Namespace().String()) {
+				continue
+			}
+			newName := gc.entity.Name()
+			found := false
+			for _, podSpec := range podSpecs {
+				if renameConfigRefs(podSpec, gc.kind, gc.name, newName) {
+					found = true
+				}
+			}
+			if found {
+				used = append(used, gc)
+				result[e] = append(result[e], gc.entity)
+			}
+		}
+		if len(used) == 0 {
+			continue
+		}
+
+		templates, err := k8s.ExtractPodTemplateSpec(&e)
+		if err != nil {
+			return nil, err
+		}
+		hash := usedConfigHash(used)
+		for _, t := range templates {
+			if t.Annotations == nil {
+				t.Annotations = make(map[string]string)
+			}
+			t.Annotations[annotationConfigHash] = hash
+		}
+	}
+	return result, nil
+}
+
+// Objects without a namespace go in the default namespace, which might not
+// be named "default", so match them with anything.
+func namespacesMatch(a, b string) bool {
+	return a == "" || b == "" || a == b
+}
+
+func usedConfigHash(used []generatedConfig) string {
+	data := make(map[string][]byte, len(used))
+	for _, gc := range used {
+		data[gc.kind+"/"+gc.name] = []byte(gc.hash)
+	}
+	return configDataHash(data)
+}
+
+// renameConfigRefs finds the references to the ConfigMap or Secret in the
+// pod spec, and renames them. Reports whether there were any.
+func renameConfigRefs(spec *v1.PodSpec, kind, oldName, newName string) bool {
+	found := false
+	visitConfigRefs(spec, func(refKind string, name *string) {
+		if refKind == kind && *name == oldName {
+			*name = newName
+			found = true
+		}
+	})
+	return found
+}
+
+// visitConfigRefs calls visit with the kind ("ConfigMap" or "Secret") and
+// a pointer to the name of each ConfigMap and Secret that the pod spec
+// uses.
+func visitConfigRefs(spec *v1.PodSpec, visit func(kind string, name *string)) {
+	for i := range spec.Volumes {
+		vol := &spec.Volumes[i]
+		if vol.ConfigMap != nil {
+			visit("ConfigMap", &vol.ConfigMap.Name)
+		}
+		if vol.Secret != nil {
+			visit("Secret", &vol.Secret.SecretName)
+		}
+		if vol.Projected != nil {
+			for j := range vol.Projected.Sources {
+				src := &vol.Projected.Sources[j]
+				if src.ConfigMap != nil {
+					visit("ConfigMap", &src.ConfigMap.Name)
+				}
+				if src.Secret != nil {
+					visit("Secret", &src.Secret.Name)
+				}
+			}
+		}
+	}
+
+	for _, cs := range [][]v1.Container{spec.InitContainers, spec.Containers} {
+		for i := range cs {
+			c := &cs[i]
+			for j := range c.EnvFrom {
+				envFrom := &c.EnvFrom[j]
+				if envFrom.ConfigMapRef != nil {
+					visit("ConfigMap", &envFrom.ConfigMapRef.Name)
+				}
+				if envFrom.SecretRef != nil {
+					visit("Secret", &envFrom.SecretRef.Name)
+				}
+			}
+			for j := range c.Env {
+				from := c.Env[j].ValueFrom
+				if from == nil {
+					continue
+				}
+				if from.ConfigMapKeyRef != nil {
+					visit("ConfigMap", &from.ConfigMapKeyRef.Name)
+				}
+				if from.SecretKeyRef != nil {
+					visit("Secret", &from.SecretKeyRef.Name)
+				}
+			}
+		}
+	}
+}
+
+// groupGeneratedConfigs moves each generated ConfigMap and Secret into the
+// resource of the first workload that uses it.
+func (s *tiltfileState) groupGeneratedConfigs(used map[k8s.K8sEntity][]k8s.K8sEntity) {
+	for _, r := range s.k8s {
+		for _, e := range r.entities {
+			for _, cfg := range used[e] {
+				for _, ur := range s.k8sUnresourced {
+					if ur == cfg {
+						s.addEntityToResourceAndRemoveFromUnresourced(cfg, r)
+						break
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const generatedConfigDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+      - name: app
+        image: busybox
+        envFrom:
+        - secretRef:
+            name: app-secret
+      volumes:
+      - name: config
+        configMap:
+          name: app-config
+`
+
+func TestConfigMapCreate(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("config/dev.yaml", "debug: true\n")
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+k8s_yaml('app.yaml')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("app")
+	f.assertNoMoreManifests()
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "app.yaml", "config/dev.yaml")
+
+	entities := f.entities(m.K8sTarget().YAML)
+	require.Len(t, entities, 2)
+
+	cm := entities[0].Obj.(*v1.ConfigMap)
+	assert.Equal(t, "app-config", cm.Name)
+	assert.Equal(t, "debug: true\n", cm.Data["dev.yaml"])
+	hash := cm.Annotations[annotationContentHash]
+	assert.Len(t, hash, 10)
+
+	d := entities[1].Obj.(*appsv1.Deployment)
+	assert.NotEmpty(t, d.Spec.Template.Annotations[annotationConfigHash])
+	assert.Equal(t, "app-config", d.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+}
+
+func TestConfigMapCreateHashChangesWithContents(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("config/dev.yaml", "debug: true\n")
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+k8s_yaml('app.yaml')
+`)
+
+	f.load()
+	before := f.entities(f.assertNextManifest("app").K8sTarget().YAML)[1].Obj.(*appsv1.Deployment)
+
+	f.file("config/dev.yaml", "debug: false\n")
+	f.load()
+	after := f.entities(f.assertNextManifest("app").K8sTarget().YAML)[1].Obj.(*appsv1.Deployment)
+
+	assert.NotEqual(t,
+		before.Spec.Template.Annotations[annotationConfigHash],
+		after.Spec.Template.Annotations[annotationConfigHash])
+}
+
+func TestSecretCreateAppendHash(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("secret.txt", "hunter2")
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+secret_create('app-secret', from_files={'password': 'secret.txt'}, append_hash=True)
+k8s_yaml('app.yaml')
+`)
+
+	f.load()
+
+	entities := f.entities(f.assertNextManifest("app").K8sTarget().YAML)
+	require.Len(t, entities, 2)
+
+	secret := entities[0].Obj.(*v1.Secret)
+	hash := secret.Annotations[annotationContentHash]
+	assert.Equal(t, "app-secret-"+hash, secret.Name)
+	assert.Equal(t, "hunter2", string(secret.Data["password"]))
+
+	d := entities[1].Obj.(*appsv1.Deployment)
+	assert.Equal(t, "app-secret-"+hash, d.Spec.Template.Spec.Containers[0].EnvFrom[0].SecretRef.Name)
+
+	// Generated secrets are scrubbed from logs like any other.
+	assert.Equal(t, "password", f.loadResult.Secrets["hunter2"].Key)
+}
+
+func TestConfigMapCreateUnused(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("config/dev.yaml", "debug: true\n")
+	f.file("Tiltfile", `
+configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+`)
+
+	f.load()
+
+	f.assertNextManifestUnresourced("app-config")
+}
+
+func TestConfigMapCreateMissingFile(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+`)
+
+	f.loadErrString("configmap_create", "no such file or directory")
+}
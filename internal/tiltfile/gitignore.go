@@ -0,0 +1,170 @@
+package tiltfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/dockerignore"
+	"github.com/tilt-dev/tilt/internal/ospath"
+	"github.com/tilt-dev/tilt/internal/sliceutils"
+	"github.com/tilt-dev/tilt/internal/tiltfile/watch"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Reads the .gitignore files of every repo that contains the Tiltfile, an
+// image's paths, or a local resource's deps, for watch_settings(use_gitignore=True).
+//
+// Then checks that they don't ignore anything a Dockerfile copies in.
+func (s *tiltfileState) loadGitignores(tiltfilePath string) error {
+	paths := []string{filepath.Dir(tiltfilePath)}
+	for _, image := range s.buildIndex.images {
+		paths = append(paths, repoPathsForImage(image)...)
+	}
+	for _, lr := range s.localResources {
+		paths = append(paths, lr.deps...)
+	}
+
+	seen := map[string]bool{}
+	for _, p := range paths {
+		root, ok := watch.GitRepoRoot(p)
+		if !ok || seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		gi, files, err := watch.ReadGitignores(root)
+		if err != nil {
+			return err
+		}
+		s.postExecReadFiles = sliceutils.AppendWithoutDupes(s.postExecReadFiles, files...)
+		if !gi.Empty() {
+			s.gitignores = append(s.gitignores, gi)
+		}
+	}
+
+	for _, image := range s.buildIndex.images {
+		if image.Type() == DockerBuild {
+			s.keepGitignoredCopies(image)
+		}
+	}
+	return nil
+}
+
+// The .gitignore patterns of the repos that contain any of the paths.
+func (s *tiltfileState) gitignoresForPaths(paths []string) []model.Dockerignore {
+	var result []model.Dockerignore
+	for _, gi := range s.gitignores {
+		for _, p := range paths {
+			if ospath.IsChild(gi.LocalPath, p) {
+				result = append(result, gi)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Warns when a .gitignore ignores something that the Dockerfile copies from
+// the build context, since the build would fail (or, worse, use a stale
+// file) without it. Adds an exception for it, so that it stays in the build
+// context and changes to it still trigger a build.
+//
+// Only sources that COPY names are checked. `COPY . .` is expected to leave
+// ignored files behind.
+func (s *tiltfileState) keepGitignoredCopies(image *dockerImage) {
+	ast, err := dockerfile.ParseAST(image.dbDockerfile)
+	if err != nil {
+		return
+	}
+	sources, err := ast.ContextSources(image.dbBuildArgs)
+	if err != nil {
+		return
+	}
+
+	for i := range s.gitignores {
+		gi := &s.gitignores[i]
+		if !ospath.IsChild(gi.LocalPath, image.dbBuildPath) {
+			continue
+		}
+
+		matcher, err := dockerignore.NewDockerPatternMatcher(gi.LocalPath, gi.Patterns)
+		if err != nil {
+			continue
+		}
+
+		for _, src := range sources {
+			if src.Path == "." {
+				continue
+			}
+			warned := false
+			for _, p := range contextSourcePaths(image.dbBuildPath, src.Path) {
+				idx := matchingGitignorePattern(matcher, *gi, p)
+				if idx == -1 {
+					continue
+				}
+
+				ref := image.configurationRef.RefFamiliarString()
+				if !warned {
+					warned = true
+					s.logger.Warnf("docker_build(%q): %s ignores %s, which the Dockerfile copies on line %d. "+
+						"Keeping it in the build context, and watching it for changes",
+						ref, gi.PatternSource(idx), src.Path, src.Line)
+				}
+				gi.Patterns = append(gi.Patterns, "!"+p)
+				gi.PatternSources = append(gi.PatternSources,
+					fmt.Sprintf("docker_build(%q) Dockerfile line %d", ref, src.Line))
+			}
+		}
+	}
+}
+
+// Keeps the files that the Tiltfile read watched, even if they're
+// gitignored, so that editing them still reloads the Tiltfile. Settings
+// files like tilt_config.json are often gitignored.
+func (s *tiltfileState) keepGitignoredConfigFiles(configFiles []string) {
+	for i := range s.gitignores {
+		gi := &s.gitignores[i]
+		matcher, err := dockerignore.NewDockerPatternMatcher(gi.LocalPath, gi.Patterns)
+		if err != nil {
+			continue
+		}
+		for _, f := range configFiles {
+			if matchingGitignorePattern(matcher, *gi, f) != -1 {
+				gi.Patterns = append(gi.Patterns, "!"+f)
+				gi.PatternSources = append(gi.PatternSources, "Tiltfile config file")
+			}
+		}
+	}
+}
+
+// The index of the pattern that ignores f, or -1. Checks the compiled
+// matcher first, because finding the pattern compiles every pattern again.
+func matchingGitignorePattern(matcher model.PathMatcher, gi model.Dockerignore, f string) int {
+	if !ospath.IsChild(gi.LocalPath, f) {
+		return -1
+	}
+	ok, err := matcher.Matches(f)
+	if err != nil || !ok {
+		return -1
+	}
+	idx, err := dockerignore.MatchingPattern(gi.Patterns, f)
+	if err != nil {
+		return -1
+	}
+	return idx
+}
+
+// The absolute paths that a COPY source names, with globs expanded.
+func contextSourcePaths(context string, src string) []string {
+	p := filepath.Join(context, filepath.FromSlash(src))
+	if !strings.ContainsAny(src, "*?[") {
+		return []string{p}
+	}
+	matches, err := filepath.Glob(p)
+	if err != nil {
+		return nil
+	}
+	return matches
+}
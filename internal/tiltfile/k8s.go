@@ -13,10 +13,12 @@ import (
 	"go.starlark.net/syntax"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 
 	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/imagemap"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	tiltfile_k8s "github.com/tilt-dev/tilt/internal/tiltfile/k8s"
@@ -71,6 +73,24 @@ type k8sResource struct {
 	labels map[string]string
 
 	customDeploy *k8sCustomDeploy
+
+	// If non-empty, the resource is deployed once to each of these
+	// namespaces, as a separate resource.
+	instances          []string
+	instancePortOffset int
+
+	// If set, the ConfigMaps and Secrets that restart the workloads.
+	restartOn *restartOn
+
+	// If set, replaces the live_update steps of the image this resource
+	// deploys, for this resource only.
+	liveUpdateOverride *v1alpha1.LiveUpdateSpec
+
+	// Container name patterns that scope which containers' logs are shown,
+	// and which containers' images Tilt injects.
+	logContainers       []string
+	ignoreLogContainers []string
+	imageContainers     []string
 }
 
 // holds options passed to `k8s_resource` until assembly happens
@@ -90,6 +110,17 @@ type k8sResourceOptions struct {
 	discoveryStrategy v1alpha1.KubernetesDiscoveryStrategy
 	links             []model.Link
 	labels            map[string]string
+
+	instances          []string
+	instancePortOffset int
+
+	restartOn *restartOn
+
+	liveUpdateOverride *v1alpha1.LiveUpdateSpec
+
+	logContainers       []string
+	ignoreLogContainers []string
+	imageContainers     []string
 }
 
 // Count image injection for analytics.
@@ -139,6 +170,43 @@ func (r *k8sResource) addEntities(entities []k8s.K8sEntity,
 	return nil
 }
 
+// Add dependencies on the images referenced by image_ref() placeholders.
+//
+// The images must be built by Tilt, since there's no image to fall back on.
+func (r *k8sResource) addImageRefPlaceholderDeps() error {
+	for _, entity := range r.entities {
+		images, err := findImageRefPlaceholders(entity)
+		if err != nil {
+			return errors.Wrapf(err, "finding image_ref() in %s/%s", entity.GVK().Kind, entity.Name())
+		}
+		for _, image := range images {
+			r.addImageDep(image, true)
+		}
+	}
+	return nil
+}
+
+func findImageRefPlaceholders(entity k8s.K8sEntity) ([]reference.Named, error) {
+	yaml, err := k8s.SerializeSpecYAML([]k8s.K8sEntity{entity})
+	if err != nil {
+		return nil, err
+	}
+	return imagemap.FindRefPlaceholders(yaml)
+}
+
+func (s *tiltfileState) imageRef(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var refStr string
+	if err := s.unpackArgs(fn.Name(), args, kwargs, "ref", &refStr); err != nil {
+		return nil, err
+	}
+
+	ref, err := container.ParseNamed(refStr)
+	if err != nil {
+		return nil, fmt.Errorf("Argument 1 (ref): can't parse %q: %v", refStr, err)
+	}
+	return starlark.String(imagemap.RefPlaceholder(ref)), nil
+}
+
 func (s *tiltfileState) k8sYaml(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var yamlValue starlark.Value
 	var allowDuplicates bool
@@ -305,6 +373,13 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 	var autoInit = value.Optional[starlark.Bool]{Value: true}
 	var labels value.LabelSet
 	var discoveryStrategy tiltfile_k8s.DiscoveryStrategy
+	var instancesVal starlark.Sequence
+	var instancePortOffset = 1
+	var restartOnVal starlark.Value
+	var liveUpdateOverrideVal starlark.Value
+	var logContainersVal starlark.Sequence
+	var ignoreLogContainersVal starlark.Sequence
+	var imageContainersVal starlark.Sequence
 
 	if err := s.unpackArgs(fn.Name(), args, kwargs,
 		"workload?", &workload,
@@ -319,6 +394,13 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		"links?", &links,
 		"labels?", &labels,
 		"discovery_strategy?", &discoveryStrategy,
+		"instances?", &instancesVal,
+		"instance_port_offset?", &instancePortOffset,
+		"restart_on?", &restartOnVal,
+		"live_update_override?", &liveUpdateOverrideVal,
+		"log_containers?", &logContainersVal,
+		"ignore_log_containers?", &ignoreLogContainersVal,
+		"image_containers?", &imageContainersVal,
 	); err != nil {
 		return nil, err
 	}
@@ -355,6 +437,51 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 		return nil, errors.Wrapf(err, "%s: resource_deps", fn.Name())
 	}
 
+	instances, err := value.SequenceToStringSlice(instancesVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: instances", fn.Name())
+	}
+	seenInstances := make(map[string]bool, len(instances))
+	for _, ns := range instances {
+		if errs := validation.IsDNS1123Label(ns); len(errs) != 0 {
+			return nil, fmt.Errorf("%s: instances: invalid namespace %q: %s", fn.Name(), ns, strings.Join(errs, ", "))
+		}
+		if seenInstances[ns] {
+			return nil, fmt.Errorf("%s: instances: duplicate namespace %q", fn.Name(), ns)
+		}
+		seenInstances[ns] = true
+	}
+	if instancePortOffset < 0 {
+		return nil, fmt.Errorf("%s: instance_port_offset must not be negative", fn.Name())
+	}
+
+	restartOn, err := restartOnFromStarlark(restartOnVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: restart_on", fn.Name())
+	}
+
+	var liveUpdateOverride *v1alpha1.LiveUpdateSpec
+	if liveUpdateOverrideVal != nil && liveUpdateOverrideVal != starlark.None {
+		spec, err := s.liveUpdateFromSteps(thread, liveUpdateOverrideVal)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: live_update_override", fn.Name())
+		}
+		liveUpdateOverride = &spec
+	}
+
+	logContainers, err := containerPatternsFromStarlark(logContainersVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: log_containers", fn.Name())
+	}
+	ignoreLogContainers, err := containerPatternsFromStarlark(ignoreLogContainersVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: ignore_log_containers", fn.Name())
+	}
+	imageContainers, err := containerPatternsFromStarlark(imageContainersVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: image_containers", fn.Name())
+	}
+
 	if manuallyGrouped && len(objects) == 0 {
 		return nil, fmt.Errorf("k8s_resource doesn't specify a workload or any objects. All non-workload resources must specify 1 or more objects")
 	}
@@ -365,25 +492,46 @@ func (s *tiltfileState) k8sResource(thread *starlark.Thread, fn *starlark.Builti
 	}
 
 	s.k8sResourceOptions = append(s.k8sResourceOptions, k8sResourceOptions{
-		workload:          resourceName,
-		newName:           string(newName),
-		portForwards:      portForwards,
-		extraPodSelectors: extraPodSelectors,
-		tiltfilePosition:  thread.CallFrame(1).Pos,
-		triggerMode:       triggerMode,
-		autoInit:          autoInit,
-		resourceDeps:      resourceDeps,
-		objects:           objects,
-		manuallyGrouped:   manuallyGrouped,
-		podReadinessMode:  podReadinessMode.Value,
-		links:             links.Links,
-		labels:            labelMap,
-		discoveryStrategy: v1alpha1.KubernetesDiscoveryStrategy(discoveryStrategy),
+		workload:            resourceName,
+		newName:             string(newName),
+		portForwards:        portForwards,
+		extraPodSelectors:   extraPodSelectors,
+		tiltfilePosition:    thread.CallFrame(1).Pos,
+		triggerMode:         triggerMode,
+		autoInit:            autoInit,
+		resourceDeps:        resourceDeps,
+		objects:             objects,
+		manuallyGrouped:     manuallyGrouped,
+		podReadinessMode:    podReadinessMode.Value,
+		links:               links.Links,
+		labels:              labelMap,
+		discoveryStrategy:   v1alpha1.KubernetesDiscoveryStrategy(discoveryStrategy),
+		instances:           instances,
+		instancePortOffset:  instancePortOffset,
+		restartOn:           restartOn,
+		liveUpdateOverride:  liveUpdateOverride,
+		logContainers:       logContainers,
+		ignoreLogContainers: ignoreLogContainers,
+		imageContainers:     imageContainers,
 	})
 
 	return starlark.None, nil
 }
 
+// containerPatternsFromStarlark converts a list of container name patterns,
+// like "app" or "worker-*", checking that each is a valid pattern.
+func containerPatternsFromStarlark(v starlark.Sequence) ([]string, error) {
+	patterns, err := value.SequenceToStringSlice(v)
+	if err != nil {
+		return nil, err
+	}
+	err = container.ValidateNamePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
 func labelSetFromStarlarkDict(d *starlark.Dict) (labels.Set, error) {
 	ret := make(labels.Set)
 
@@ -667,6 +815,102 @@ func (s *tiltfileState) makeK8sResource(name string) (*k8sResource, error) {
 	return r, nil
 }
 
+// Kinds that can't be deployed once per namespace.
+var clusterScopedKinds = map[string]bool{
+	"Namespace":                      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"PersistentVolume":               true,
+	"StorageClass":                   true,
+	"PriorityClass":                  true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// Replace each resource with `instances` by one resource per namespace.
+//
+// Each instance is named <resource>-<namespace>, shares the image deps of the
+// original resource, and shifts its local port-forwards by instance_port_offset
+// for each instance after the first. Dependencies on the original resource
+// become dependencies on all of its instances.
+func (s *tiltfileState) expandK8sInstances() error {
+	result := make([]*k8sResource, 0, len(s.k8s))
+	for _, r := range s.k8s {
+		if len(r.instances) == 0 {
+			result = append(result, r)
+			continue
+		}
+
+		if r.customDeploy != nil {
+			return fmt.Errorf("k8s_resource %q: instances are not supported with k8s_custom_deploy", r.name)
+		}
+		for _, e := range r.entities {
+			if clusterScopedKinds[e.GVK().Kind] {
+				return fmt.Errorf("k8s_resource %q: cannot deploy cluster-scoped %s %q to multiple namespaces. "+
+					"Move it to a separate resource", r.name, e.GVK().Kind, e.Name())
+			}
+		}
+
+		delete(s.k8sByName, r.name)
+		names := make([]string, 0, len(r.instances))
+		for i, ns := range r.instances {
+			inst := r.instance(i, ns)
+			err := s.checkResourceConflict(inst.name)
+			if err != nil {
+				return fmt.Errorf("k8s_resource %q: instance %q: %v", r.name, ns, err)
+			}
+			s.k8sByName[inst.name] = inst
+			result = append(result, inst)
+			names = append(names, inst.name)
+		}
+		s.k8sInstances[r.name] = names
+	}
+	s.k8s = result
+	return nil
+}
+
+// Copy the resource for the i-th instance, deployed to namespace ns.
+func (r *k8sResource) instance(i int, ns string) *k8sResource {
+	inst := *r
+	inst.name = fmt.Sprintf("%s-%s", r.name, ns)
+	inst.instances = nil
+
+	inst.entities = make([]k8s.K8sEntity, len(r.entities))
+	for j, e := range r.entities {
+		inst.entities[j] = e.WithNamespace(ns)
+	}
+
+	inst.imageRefs = append(referenceList(nil), r.imageRefs...)
+	inst.imageDepsMetadata = make(map[string]*imageDepMetadata, len(r.imageDepsMetadata))
+	for k, v := range r.imageDepsMetadata {
+		metadata := *v
+		inst.imageDepsMetadata[k] = &metadata
+	}
+
+	inst.portForwards = make([]model.PortForward, len(r.portForwards))
+	for j, pf := range r.portForwards {
+		if pf.LocalPort != 0 {
+			pf.LocalPort += i * r.instancePortOffset
+		}
+		inst.portForwards[j] = pf
+	}
+
+	inst.resourceDeps = append([]string(nil), r.resourceDeps...)
+	inst.links = append([]model.Link(nil), r.links...)
+
+	// Group the instances together in the UI.
+	inst.labels = make(map[string]string, len(r.labels)+1)
+	for k, v := range r.labels {
+		inst.labels[k] = v
+	}
+	if len(validation.IsQualifiedName(r.name)) == 0 && !strings.Contains(r.name, "/") {
+		inst.labels[r.name] = r.name
+	}
+	return &inst
+}
+
 func (s *tiltfileState) yamlEntitiesFromSkylarkValueOrList(thread *starlark.Thread, v starlark.Value) ([]k8s.K8sEntity, error) {
 	values := starlarkValueOrSequenceToSlice(v)
 
@@ -691,12 +935,37 @@ func parseYAMLFromBlob(blob io.Blob) ([]k8s.K8sEntity, error) {
 	return ret, nil
 }
 
+// Warn about fields that the apiserver will reject or drop, so that typos
+// show up now instead of after the image builds.
+func (s *tiltfileState) warnOnSchemaViolations(source string, yaml string) {
+	validate := k8s.ValidateYAMLSchemas
+	if s.schemaValidator != nil {
+		validate = s.schemaValidator.ValidateYAML
+	}
+	violations, err := validate(yaml)
+	if err != nil || len(violations) == 0 {
+		return
+	}
+	for _, v := range violations {
+		s.logger.Warnf("%s: %s %q: %s", source, v.Kind, v.Name, v.Message)
+	}
+	s.logger.Warnf("To skip schema validation of an object, set the annotation %s: \"true\"",
+		k8s.AnnotationSkipSchemaValidation)
+}
+
 func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v starlark.Value) ([]k8s.K8sEntity, error) {
 	switch v := v.(type) {
 	case nil:
 		return nil, nil
 	case io.Blob:
-		return parseYAMLFromBlob(v)
+		// Validate before parsing, so that a value of the wrong type is
+		// reported with its field path alongside the parse error.
+		s.warnOnSchemaViolations(v.Source, v.String())
+		entities, err := parseYAMLFromBlob(v)
+		if err != nil {
+			return nil, err
+		}
+		return entities, nil
 	default:
 		yamlPath, err := value.ValueToAbsPath(thread, v)
 		if err != nil {
@@ -707,6 +976,7 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 			return nil, errors.Wrap(err, "error reading yaml file")
 		}
 
+		s.warnOnSchemaViolations(yamlPath, string(bs))
 		entities, err := k8s.ParseYAMLFromString(string(bs))
 		if err != nil {
 			if strings.Contains(err.Error(), "json parse error: ") {
@@ -714,7 +984,6 @@ func (s *tiltfileState) yamlEntitiesFromSkylarkValue(thread *starlark.Thread, v
 			}
 			return entities, err
 		}
-
 		return entities, nil
 	}
 }
@@ -21,10 +21,12 @@ func (ds *DiscoveryStrategy) Unpack(v starlark.Value) error {
 	kdStrategy := v1alpha1.KubernetesDiscoveryStrategy(s)
 	if !(kdStrategy == "" ||
 		kdStrategy == v1alpha1.KubernetesDiscoveryStrategyDefault ||
-		kdStrategy == v1alpha1.KubernetesDiscoveryStrategySelectorsOnly) {
-		return fmt.Errorf("Invalid. Must be one of: %q, %q",
+		kdStrategy == v1alpha1.KubernetesDiscoveryStrategySelectorsOnly ||
+		kdStrategy == v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners) {
+		return fmt.Errorf("Invalid. Must be one of: %q, %q, %q",
 			v1alpha1.KubernetesDiscoveryStrategyDefault,
-			v1alpha1.KubernetesDiscoveryStrategySelectorsOnly)
+			v1alpha1.KubernetesDiscoveryStrategySelectorsOnly,
+			v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners)
 	}
 
 	*ds = DiscoveryStrategy(kdStrategy)
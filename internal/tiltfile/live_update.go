@@ -2,6 +2,7 @@ package tiltfile
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -10,6 +11,10 @@ import (
 
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/container"
+	"github.com/tilt-dev/tilt/internal/controllers/apis/liveupdate"
+	"github.com/tilt-dev/tilt/internal/dockerfile"
+	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/value"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
@@ -316,3 +321,127 @@ func (s *tiltfileState) checkForUnconsumedLiveUpdateSteps() error {
 
 	return nil
 }
+
+// applyLiveUpdateOverride swaps in the live_update_override steps from
+// k8s_resource() for the image that the resource deploys.
+//
+// Image targets are copied per-manifest, so the override only affects
+// this resource. Other resources that deploy the same image keep the
+// steps from the image's build.
+func applyLiveUpdateOverride(mn model.ManifestName, r *k8sResource, iTargets []model.ImageTarget) ([]model.ImageTarget, error) {
+	if r.liveUpdateOverride == nil {
+		return iTargets, nil
+	}
+
+	var deployed []int
+	for i, iTarget := range iTargets {
+		for _, name := range r.imageMapDeps {
+			if iTarget.ImageMapName() == name {
+				deployed = append(deployed, i)
+				break
+			}
+		}
+	}
+
+	switch len(deployed) {
+	case 0:
+		return nil, fmt.Errorf("k8s_resource %q: live_update_override: resource does not deploy any images built by this Tiltfile", r.name)
+	case 1:
+	default:
+		var refs []string
+		for _, i := range deployed {
+			refs = append(refs, iTargets[i].ImageMapSpec.Selector)
+		}
+		return nil, fmt.Errorf("k8s_resource %q: live_update_override: resource deploys more than one image (%s), so the override is ambiguous",
+			r.name, strings.Join(refs, ", "))
+	}
+
+	iTarget := iTargets[deployed[0]]
+	iTarget.LiveUpdateSpec = *r.liveUpdateOverride
+	iTarget.LiveUpdateName = ""
+	iTarget.LiveUpdateSource = ""
+	if !liveupdate.IsEmptySpec(iTarget.LiveUpdateSpec) {
+		iTarget.LiveUpdateName = liveupdate.GetName(mn, iTarget.ID())
+		iTarget.LiveUpdateSource = fmt.Sprintf("k8s_resource(%q)", mn)
+	}
+
+	result := append([]model.ImageTarget{}, iTargets...)
+	result[deployed[0]] = iTarget
+	return result, nil
+}
+
+// warnOnShadowedVolumePaths warns when a volume mounted into a container
+// hides files that we put there, either with a live_update sync or
+// in the image's WORKDIR.
+//
+// Anything synced underneath a mount path lands in the volume (if it's
+// synced after the mount) or vanishes from view (if it was in the image).
+func (s *tiltfileState) warnOnShadowedVolumePaths(mn model.ManifestName, entities []k8s.K8sEntity, iTargets []model.ImageTarget) {
+	for _, iTarget := range iTargets {
+		selector, err := container.SelectorFromImageMap(iTarget.ImageMapSpec)
+		if err != nil {
+			continue
+		}
+
+		workDir := ""
+		if iTarget.IsDockerBuild() {
+			workDir = dockerfileWorkDir(iTarget.DockerBuildInfo())
+		}
+		syncs := liveupdate.SyncSteps(iTarget.LiveUpdateSpec)
+
+		for _, e := range entities {
+			mounts, err := k8s.VolumeMountsForImage(e, selector)
+			if err != nil {
+				continue
+			}
+
+			for _, m := range mounts {
+				volume := fmt.Sprintf("volume %q (%s) mounted at %q in container %q of %s %s",
+					m.VolumeName, m.VolumeSource, m.MountPath, m.Container, e.GVK().Kind, e.Name())
+				for _, sync := range syncs {
+					if isContainerPathChild(m.MountPath, sync.ContainerPath) {
+						s.logger.Warnf("resource %s: live_update syncs %q to %q, which is inside %s. "+
+							"Synced files will be written to the volume instead of the image filesystem",
+							mn, sync.LocalPath, sync.ContainerPath, volume)
+					} else if isContainerPathChild(sync.ContainerPath, m.MountPath) {
+						s.logger.Warnf("resource %s: live_update syncs %q to %q, which contains %s. "+
+							"Files synced under the mount path will be shadowed by the volume",
+							mn, sync.LocalPath, sync.ContainerPath, volume)
+					}
+				}
+
+				if workDir != "" && workDir != "/" && isContainerPathChild(m.MountPath, workDir) {
+					s.logger.Warnf("resource %s: image %s has WORKDIR %q, which is entirely shadowed by %s. "+
+						"The image contents at that path will never be used",
+						mn, iTarget.ImageMapSpec.Selector, workDir, volume)
+				}
+			}
+		}
+	}
+}
+
+// dockerfileWorkDir returns the WORKDIR of the final stage of a docker_build
+// Dockerfile, or empty if it can't be determined.
+func dockerfileWorkDir(db model.DockerBuild) string {
+	ast, err := dockerfile.ParseAST(dockerfile.Dockerfile(db.DockerfileContents))
+	if err != nil {
+		return ""
+	}
+	workDir, err := ast.WorkDir(db.Args)
+	if err != nil {
+		return ""
+	}
+	return workDir
+}
+
+// isContainerPathChild returns true if file is dir or underneath dir.
+//
+// Container paths are always slash-separated, regardless of the host OS.
+func isContainerPathChild(dir, file string) bool {
+	dir = path.Clean(dir)
+	file = path.Clean(file)
+	if dir == file || dir == "/" {
+		return true
+	}
+	return strings.HasPrefix(file, dir+"/")
+}
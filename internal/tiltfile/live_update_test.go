@@ -457,3 +457,174 @@ func newLiveUpdateFixture(t *testing.T) *liveUpdateFixture {
 
 	return f
 }
+
+const volumeMountDeploymentYAML = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: gcr.io/foo
+        volumeMounts:
+        - name: uploads
+          mountPath: /app/uploads
+      volumes:
+      - name: uploads
+        emptyDir: {}
+`
+
+func TestLiveUpdateSyncInsideVolumeMount(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo/Dockerfile", "FROM golang:1.10")
+	f.file("foo.yaml", volumeMountDeploymentYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo', live_update=[sync('foo/uploads', '/app/uploads/tmp')])
+`)
+
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	assert.Contains(t, f.warnings[0], `live_update syncs`)
+	assert.Contains(t, f.warnings[0], `to "/app/uploads/tmp", which is inside volume "uploads" (emptyDir) mounted at "/app/uploads" in container "foo" of Deployment foo`)
+}
+
+func TestLiveUpdateSyncContainsVolumeMount(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo/Dockerfile", "FROM golang:1.10")
+	f.file("foo.yaml", volumeMountDeploymentYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo', live_update=[sync('foo', '/app')])
+`)
+
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	assert.Contains(t, f.warnings[0], `to "/app", which contains volume "uploads" (emptyDir) mounted at "/app/uploads"`)
+}
+
+func TestLiveUpdateSyncOutsideVolumeMount(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo/Dockerfile", "FROM golang:1.10")
+	f.file("foo.yaml", volumeMountDeploymentYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo', live_update=[sync('foo', '/app/src')])
+`)
+
+	f.load()
+}
+
+func TestWorkDirShadowedByVolumeMount(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo/Dockerfile", "FROM golang:1.10\nWORKDIR /app/uploads\n")
+	f.file("foo.yaml", volumeMountDeploymentYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	assert.Contains(t, f.warnings[0], `image gcr.io/foo has WORKDIR "/app/uploads", which is entirely shadowed by volume "uploads"`)
+}
+
+func TestLiveUpdateOverride(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.dockerfile("foo/Dockerfile")
+	f.yaml("foo.yaml",
+		deployment("foo", image("gcr.io/foo")),
+		deployment("worker", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+steps = [sync('foo/src', '/app/src')]
+docker_build('gcr.io/foo', 'foo', live_update=steps)
+k8s_resource('worker', live_update_override=steps + [run('make worker')])
+`)
+
+	f.load()
+
+	foo := f.assertNextManifest("foo").ImageTargets[0]
+	worker := f.assertNextManifest("worker").ImageTargets[0]
+
+	assert.Empty(t, foo.LiveUpdateSource)
+	assert.Empty(t, foo.LiveUpdateSpec.Execs)
+	assert.Equal(t, `k8s_resource("worker")`, worker.LiveUpdateSource)
+	assert.Equal(t, []v1alpha1.LiveUpdateExec{{Args: model.ToUnixCmd("make worker").Argv}},
+		worker.LiveUpdateSpec.Execs)
+	assert.Equal(t, foo.LiveUpdateSpec.Syncs, worker.LiveUpdateSpec.Syncs)
+	assert.NotEqual(t, foo.LiveUpdateName, worker.LiveUpdateName)
+	assert.True(t, worker.LiveUpdateReconciler)
+}
+
+func TestLiveUpdateOverrideEmptyDisables(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo', live_update=[sync('foo/src', '/app/src')])
+k8s_resource('foo', live_update_override=[])
+`)
+
+	f.load()
+
+	iTarget := f.assertNextManifest("foo").ImageTargets[0]
+	assert.Empty(t, iTarget.LiveUpdateName)
+	assert.Empty(t, iTarget.LiveUpdateSpec.Syncs)
+}
+
+func TestLiveUpdateOverrideOutsideOfBuildContext(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+k8s_resource('foo', live_update_override=[sync('bar', '/baz')])
+`)
+
+	f.loadErrString(`k8s_resource("foo"): live_update_override`, "sync step source", f.JoinPath("bar"), "any watched filepaths")
+}
+
+func TestLiveUpdateOverrideAmbiguous(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.dockerfile("foo/Dockerfile")
+	f.dockerfile("bar/Dockerfile")
+	f.file("foo.yaml", `apiVersion: v1
+kind: Pod
+metadata:
+  name: foo
+spec:
+  containers:
+  - name: foo
+    image: gcr.io/foo
+  - name: bar
+    image: gcr.io/bar
+`)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_resource('foo', live_update_override=[sync('foo', '/app')])
+`)
+
+	f.loadErrString(`k8s_resource "foo": live_update_override`, "more than one image", "gcr.io/foo", "gcr.io/bar")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"go.starlark.net/starlark"
 
+	"github.com/tilt-dev/tilt/internal/sliceutils"
 	"github.com/tilt-dev/tilt/internal/tiltfile/links"
 	"github.com/tilt-dev/tilt/internal/tiltfile/probe"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
@@ -35,19 +36,27 @@ type localResource struct {
 	labels        map[string]string
 
 	readinessProbe *v1alpha1.Probe
+
+	resourceDepsOnUpdate []string
+	degradeOnFailure     bool
+
+	// Env vars read from other resources with from_resource().
+	updateEnvRefs map[string]resourceValue
+	serveEnvRefs  map[string]resourceValue
 }
 
 func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	var name value.Name
 	var updateCmdVal, updateCmdBatVal, serveCmdVal, serveCmdBatVal starlark.Value
-	var updateEnv, serveEnv value.StringStringMap
+	var updateEnv, serveEnv resourceEnv
 	var triggerMode triggerMode
 	var readinessProbe probe.Probe
 	var updateCmdDirVal, serveCmdDirVal starlark.Value
 
 	deps := value.NewLocalPathListUnpacker(thread)
 
-	var resourceDepsVal starlark.Sequence
+	var resourceDepsVal, resourceDepsOnUpdateVal starlark.Sequence
+	var degradeOnFailure bool
 	var ignoresVal starlark.Value
 	var allowParallel bool
 	var links links.LinkList
@@ -78,6 +87,8 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		"readiness_probe?", &readinessProbe,
 		"dir?", &updateCmdDirVal,
 		"serve_dir?", &serveCmdDirVal,
+		"resource_deps_on_update?", &resourceDepsOnUpdateVal,
+		"degrade_on_failure?", &degradeOnFailure,
 	); err != nil {
 		return nil, err
 	}
@@ -87,16 +98,30 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		return nil, errors.Wrapf(err, "%s: resource_deps", fn.Name())
 	}
 
+	resourceDepsOnUpdate, err := value.SequenceToStringSlice(resourceDepsOnUpdateVal)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: resource_deps_on_update", fn.Name())
+	}
+
+	// A resource that runs on update of another resource
+	// shouldn't run until that resource is up.
+	resourceDeps = sliceutils.AppendWithoutDupes(resourceDeps, resourceDepsOnUpdate...)
+
+	// Likewise, a resource that reads values from another resource
+	// shouldn't start until the values exist.
+	resourceDeps = sliceutils.AppendWithoutDupes(resourceDeps, updateEnv.resourceDeps()...)
+	resourceDeps = sliceutils.AppendWithoutDupes(resourceDeps, serveEnv.resourceDeps()...)
+
 	ignores, err := parseValuesToStrings(ignoresVal, "ignore")
 	if err != nil {
 		return nil, err
 	}
 
-	updateCmd, err := value.ValueGroupToCmdHelper(thread, updateCmdVal, updateCmdBatVal, updateCmdDirVal, updateEnv)
+	updateCmd, err := value.ValueGroupToCmdHelper(thread, updateCmdVal, updateCmdBatVal, updateCmdDirVal, updateEnv.static)
 	if err != nil {
 		return nil, err
 	}
-	serveCmd, err := value.ValueGroupToCmdHelper(thread, serveCmdVal, serveCmdBatVal, serveCmdDirVal, serveEnv)
+	serveCmd, err := value.ValueGroupToCmdHelper(thread, serveCmdVal, serveCmdBatVal, serveCmdDirVal, serveEnv.static)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +130,23 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		return nil, fmt.Errorf("local_resource must have a cmd and/or a serve_cmd, but both were empty")
 	}
 
+	for _, c := range []struct {
+		arg string
+		cmd model.Cmd
+	}{{"cmd", updateCmd}, {"serve_cmd", serveCmd}} {
+		_, err := s.checkSandbox(thread, fmt.Sprintf("%s(%s).%s", fn.Name(), name, c.arg), c.cmd, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if updateCmd.Empty() && len(resourceDepsOnUpdate) > 0 {
+		return nil, fmt.Errorf("local_resource with resource_deps_on_update must have a cmd")
+	}
+	if degradeOnFailure && len(resourceDepsOnUpdate) == 0 {
+		return nil, fmt.Errorf("local_resource with degrade_on_failure must have resource_deps_on_update")
+	}
+
 	probeSpec := readinessProbe.Spec()
 	if probeSpec != nil && serveCmd.Empty() {
 		s.logger.Warnf("Ignoring readiness probe for local resource %q (no serve_cmd was defined)", name)
@@ -125,6 +167,12 @@ func (s *tiltfileState) localResource(thread *starlark.Thread, fn *starlark.Buil
 		links:          links.Links,
 		labels:         labels.Values,
 		readinessProbe: probeSpec,
+
+		resourceDepsOnUpdate: resourceDepsOnUpdate,
+		degradeOnFailure:     degradeOnFailure,
+
+		updateEnvRefs: updateEnv.refs,
+		serveEnvRefs:  serveEnv.refs,
 	}
 
 	// check for duplicate resources by name and throw error if found
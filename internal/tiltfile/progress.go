@@ -0,0 +1,240 @@
+package tiltfile
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/tilt-dev/tilt/pkg/logger"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Fast Tiltfile loads don't need a progress report.
+var progressDelay = time.Second
+
+var progressInterval = 500 * time.Millisecond
+
+const progressID = "tiltfile-progress"
+
+// loadProgress tracks how far along a Tiltfile load is, and reports it as a
+// single log line that the terminal and web UI update in place.
+//
+// Steps are the top-level statements of the main Tiltfile. Authors can
+// describe what the Tiltfile is doing with progress(), which shows until
+// the next call.
+type loadProgress struct {
+	mu sync.Mutex
+
+	start time.Time
+
+	// The start line of each top-level statement of the main Tiltfile.
+	stmtLines []int
+	parsed    bool
+
+	step     int
+	message  string
+	activity string
+
+	lastLine string
+	done     chan struct{}
+	finished chan struct{}
+}
+
+func newLoadProgress() *loadProgress {
+	return &loadProgress{}
+}
+
+// run reports progress until stop is called.
+func (p *loadProgress) run(l logger.Logger) {
+	p.mu.Lock()
+	p.start = time.Now()
+	p.done = make(chan struct{})
+	p.finished = make(chan struct{})
+	done, finished := p.done, p.finished
+	p.mu.Unlock()
+
+	go func() {
+		defer close(finished)
+		select {
+		case <-time.After(progressDelay):
+		case <-done:
+			return
+		}
+
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			p.print(l, p.line(time.Now()))
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+}
+
+// stop ends the progress report. If any progress was printed, the
+// progress line is updated one last time with the outcome.
+func (p *loadProgress) stop(l logger.Logger, err error) {
+	p.mu.Lock()
+	if p.done == nil {
+		p.mu.Unlock()
+		return
+	}
+	close(p.done)
+	p.done = nil
+	finished := p.finished
+	p.mu.Unlock()
+
+	// Wait for the last report, so that it doesn't overwrite the outcome.
+	<-finished
+
+	p.mu.Lock()
+	if p.lastLine == "" {
+		p.mu.Unlock()
+		return
+	}
+	elapsed := time.Since(p.start).Round(time.Second)
+	counter := p.counter()
+	steps := len(p.stmtLines)
+	p.mu.Unlock()
+
+	if err != nil {
+		p.print(l, fmt.Sprintf("Stopped at step %s (%s)", counter, elapsed))
+	} else {
+		p.print(l, fmt.Sprintf("Finished %d steps (%s)", steps, elapsed))
+	}
+}
+
+func (p *loadProgress) print(l logger.Logger, line string) {
+	p.mu.Lock()
+	if line == p.lastLine {
+		p.mu.Unlock()
+		return
+	}
+	p.lastLine = line
+	p.mu.Unlock()
+
+	l.WithFields(logger.Fields{logger.FieldNameProgressID: progressID}).Infof("%s", line)
+}
+
+// The progress line, e.g.,
+// Step 3/17: rendering charts (local: helm template ./chart, 12s)
+func (p *loadProgress) line(now time.Time) string {
+	p.mu.Lock()
+	counter := p.counter()
+	message := p.message
+	activity := p.activity
+	elapsed := now.Sub(p.start).Round(time.Second)
+	p.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString("Step ")
+	sb.WriteString(counter)
+	if message != "" {
+		sb.WriteString(": ")
+		sb.WriteString(message)
+	}
+	sb.WriteString(" (")
+	if activity != "" {
+		sb.WriteString(activity)
+		sb.WriteString(", ")
+	}
+	sb.WriteString(elapsed.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// e.g., "3/17". Must hold the lock.
+func (p *loadProgress) counter() string {
+	if len(p.stmtLines) == 0 {
+		return fmt.Sprintf("%d", p.step)
+	}
+	return fmt.Sprintf("%d/%d", p.step, len(p.stmtLines))
+}
+
+// onExec parses the main Tiltfile to count its top-level statements.
+func (p *loadProgress) onExec(path string, contents []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The first file executed is the main Tiltfile.
+	if p.parsed {
+		return
+	}
+	p.parsed = true
+
+	f, err := syntax.Parse(path, contents, 0)
+	if err != nil {
+		// Execution will report the error.
+		return
+	}
+	for _, stmt := range f.Stmts {
+		start, _ := stmt.Span()
+		p.stmtLines = append(p.stmtLines, int(start.Line))
+	}
+}
+
+// onBuiltinCall finds the top-level statement of the main Tiltfile that's
+// executing, and records the builtin it called.
+func (p *loadProgress) onBuiltinCall(t *starlark.Thread, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if name != progressN {
+		p.activity = name
+	}
+	p.updateStep(t)
+}
+
+// onCommand records a command that's running on behalf of the Tiltfile.
+func (p *loadProgress) onCommand(t *starlark.Thread, cmd model.Cmd) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.activity = fmt.Sprintf("local: %s", cmd)
+	p.updateStep(t)
+}
+
+func (p *loadProgress) setMessage(t *starlark.Thread, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.message = message
+	p.updateStep(t)
+}
+
+// Must hold the lock.
+func (p *loadProgress) updateStep(t *starlark.Thread) {
+	depth := t.CallStackDepth()
+	if depth == 0 {
+		return
+	}
+
+	// The outermost frame is the top level of the main Tiltfile.
+	line := int(t.CallFrame(depth - 1).Pos.Line)
+	step := 0
+	for i, stmtLine := range p.stmtLines {
+		if stmtLine > line {
+			break
+		}
+		step = i + 1
+	}
+	p.step = step
+}
+
+func (s *tiltfileState) progressFn(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var message string
+	err := s.unpackArgs(fn.Name(), args, kwargs, "message", &message)
+	if err != nil {
+		return nil, err
+	}
+
+	s.progress.setMessage(thread, message)
+	return starlark.None, nil
+}
@@ -0,0 +1,113 @@
+package tiltfile
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
+	"github.com/tilt-dev/tilt/pkg/logger"
+)
+
+func TestProgressReportsStepsAndMessage(t *testing.T) {
+	f := newFixture(t)
+	out := f.reportProgressImmediately()
+
+	f.file("Tiltfile", `
+def render():
+  progress('rendering charts')
+  local('sleep 0.3', quiet=True, echo_off=True)
+
+x = 1
+render()
+`)
+
+	f.load()
+
+	assert.Contains(t, out.String(), "Step 3/3: rendering charts (local: sleep 0.3, ")
+	assert.Contains(t, out.String(), "Finished 3 steps (")
+}
+
+func TestProgressReportsFailedStep(t *testing.T) {
+	f := newFixture(t)
+	out := f.reportProgressImmediately()
+
+	f.file("Tiltfile", `
+local('sleep 0.3', quiet=True, echo_off=True)
+fail('oops')
+`)
+
+	f.loadErrString("oops")
+
+	assert.Contains(t, out.String(), "Stopped at step 2/2 (")
+}
+
+func TestProgressQuietForFastLoads(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+progress('rendering charts')
+`)
+
+	f.load()
+
+	assert.NotContains(t, f.out.String(), "Step ")
+}
+
+func TestLoadCanceled(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+def spin():
+  for i in range(1000000000):
+    pass
+
+spin()
+`)
+
+	ctx, cancel := context.WithCancel(f.ctx)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	tlr := f.newTiltfileLoader().Load(ctx, ctrltiltfile.MainTiltfile(f.JoinPath("Tiltfile"), nil), nil)
+	if assert.Error(t, tlr.Error) {
+		assert.Contains(t, tlr.Error.Error(), "context canceled")
+	}
+}
+
+// Report progress as soon as the load starts, and collect the logs with
+// a lock because progress is reported from another goroutine.
+func (f *fixture) reportProgressImmediately() *lockedBuffer {
+	oldDelay, oldInterval := progressDelay, progressInterval
+	progressDelay, progressInterval = 0, 10*time.Millisecond
+	f.t.Cleanup(func() {
+		progressDelay, progressInterval = oldDelay, oldInterval
+	})
+
+	out := &lockedBuffer{}
+	f.ctx = logger.WithLogger(f.ctx, logger.NewLogger(logger.InfoLvl, out))
+	return out
+}
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,143 @@
+package tiltfile
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const resourceGroupN = "resource_group"
+
+type resourceGroup struct {
+	name    string
+	members []string
+	labels  map[string]string
+}
+
+func (s *tiltfileState) resourceGroup(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name value.Name
+	var members value.StringList
+	var labels value.LabelSet
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"name", &name,
+		"members", &members,
+		"labels?", &labels,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		return nil, fmt.Errorf("%s %q: members must not be empty", fn.Name(), name)
+	}
+
+	for _, g := range s.resourceGroups {
+		if g.name == name.String() {
+			return nil, fmt.Errorf("%s %q defined twice", fn.Name(), name)
+		}
+	}
+
+	seen := make(map[string]bool, len(members))
+	for _, m := range members {
+		if seen[m] {
+			return nil, fmt.Errorf("%s %q: %q is listed twice in members", fn.Name(), name, m)
+		}
+		seen[m] = true
+	}
+
+	s.resourceGroups = append(s.resourceGroups, resourceGroup{
+		name:    name.String(),
+		members: append([]string{}, members...),
+		labels:  labels.Values,
+	})
+	return starlark.None, nil
+}
+
+// Checks the resource groups against the manifests, and expands groups that
+// are members of other groups into their resources.
+//
+// Each resource or group can only be a member of one group, and groups
+// can't contain themselves.
+func (s *tiltfileState) resolveResourceGroups(manifests []model.Manifest) ([]model.ResourceGroup, error) {
+	if len(s.resourceGroups) == 0 {
+		return nil, nil
+	}
+
+	isManifest := make(map[string]bool, len(manifests))
+	for _, m := range manifests {
+		isManifest[m.Name.String()] = true
+	}
+
+	groupsByName := make(map[string]resourceGroup, len(s.resourceGroups))
+	parents := make(map[string]string)
+	for _, g := range s.resourceGroups {
+		if isManifest[g.name] {
+			return nil, fmt.Errorf("%s %q has the same name as a resource", resourceGroupN, g.name)
+		}
+		groupsByName[g.name] = g
+	}
+
+	for _, g := range s.resourceGroups {
+		for _, m := range g.members {
+			_, isGroup := groupsByName[m]
+			if !isGroup && !isManifest[m] {
+				return nil, fmt.Errorf("%s %q: no resource or group named %q", resourceGroupN, g.name, m)
+			}
+			if other, ok := parents[m]; ok {
+				return nil, fmt.Errorf("%q is a member of both resource groups %q and %q", m, other, g.name)
+			}
+			parents[m] = g.name
+		}
+	}
+
+	// Since each name has at most one parent, following the parents from
+	// any group either ends at a top-level group or loops.
+	for _, g := range s.resourceGroups {
+		path := []string{g.name}
+		visited := map[string]bool{g.name: true}
+		for p, ok := parents[g.name]; ok; p, ok = parents[p] {
+			path = append(path, p)
+			if p == g.name {
+				reverse(path)
+				return nil, fmt.Errorf("%s cycle: %s", resourceGroupN, strings.Join(path, " -> "))
+			}
+			if visited[p] {
+				// A loop that doesn't include g. It's reported when we check p.
+				break
+			}
+			visited[p] = true
+		}
+	}
+
+	var expand func(name string) []model.ManifestName
+	expand = func(name string) []model.ManifestName {
+		g, ok := groupsByName[name]
+		if !ok {
+			return []model.ManifestName{model.ManifestName(name)}
+		}
+		var result []model.ManifestName
+		for _, m := range g.members {
+			result = append(result, expand(m)...)
+		}
+		return result
+	}
+
+	result := make([]model.ResourceGroup, 0, len(s.resourceGroups))
+	for _, g := range s.resourceGroups {
+		result = append(result, model.ResourceGroup{
+			Name:    model.ManifestName(g.name),
+			Members: expand(g.name),
+			Labels:  g.labels,
+		})
+	}
+	return result, nil
+}
+
+func reverse(strs []string) {
+	for i, j := 0, len(strs)-1; i < j; i, j = i+1, j-1 {
+		strs[i], strs[j] = strs[j], strs[i]
+	}
+}
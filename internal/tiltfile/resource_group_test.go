@@ -0,0 +1,104 @@
+package tiltfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestResourceGroup(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('auth-api', 'echo api')
+local_resource('auth-redis', 'echo redis')
+local_resource('auth-refresher', serve_cmd='echo refresher')
+resource_group('auth', members=['auth-api', 'auth-redis', 'auth-refresher'], labels=['backend'])
+`)
+	f.load()
+
+	// Members are still resources in their own right.
+	f.assertNumManifests(3)
+	assert.Equal(t, []model.ResourceGroup{
+		{
+			Name:    "auth",
+			Members: []model.ManifestName{"auth-api", "auth-redis", "auth-refresher"},
+			Labels:  map[string]string{"backend": "backend"},
+		},
+	}, f.loadResult.ResourceGroups)
+}
+
+func TestResourceGroupNested(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+local_resource('b', 'echo b')
+local_resource('c', 'echo c')
+resource_group('outer', members=['inner', 'c'])
+resource_group('inner', members=['a', 'b'])
+`)
+	f.load()
+
+	require.Len(t, f.loadResult.ResourceGroups, 2)
+	assert.Equal(t, []model.ManifestName{"a", "b", "c"}, f.loadResult.ResourceGroups[0].Members)
+	assert.Equal(t, []model.ManifestName{"a", "b"}, f.loadResult.ResourceGroups[1].Members)
+}
+
+func TestResourceGroupUnknownMember(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+resource_group('g', members=['a', 'b'])
+`)
+	f.loadErrString(`resource_group "g": no resource or group named "b"`)
+}
+
+func TestResourceGroupOverlap(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+local_resource('b', 'echo b')
+resource_group('g1', members=['a'])
+resource_group('g2', members=['b', 'a'])
+`)
+	f.loadErrString(`"a" is a member of both resource groups "g1" and "g2"`)
+}
+
+func TestResourceGroupCycle(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+resource_group('g1', members=['a', 'g2'])
+resource_group('g2', members=['g3'])
+resource_group('g3', members=['g1'])
+`)
+	f.loadErrString("resource_group cycle: g1 -> g2 -> g3 -> g1")
+}
+
+func TestResourceGroupNameConflict(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+resource_group('a', members=['a'])
+`)
+	f.loadErrString(`resource_group "a" has the same name as a resource`)
+}
+
+func TestResourceGroupDefinedTwice(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('a', 'echo a')
+resource_group('g', members=['a'])
+resource_group('g', members=['a'])
+`)
+	f.loadErrString(`resource_group "g" defined twice`)
+}
@@ -0,0 +1,185 @@
+package tiltfile
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.starlark.net/starlark"
+
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// The values that a resource exports to other resources.
+const (
+	// The URL of the resource's first port forward.
+	resourceValuePortForwardURL = "port_forward_url"
+
+	// The local port of the resource's first port forward.
+	resourceValuePortForwardPort = "port_forward_port"
+
+	// The URL of the resource's first link.
+	resourceValueLinkURL = "link_url"
+)
+
+var resourceValueKeys = []string{resourceValuePortForwardURL, resourceValuePortForwardPort, resourceValueLinkURL}
+
+// A value exported by another resource, like the URL of its port forward.
+//
+// Resolved when the Tiltfile is assembled, so the consumer picks up the new
+// value (and restarts) whenever the Tiltfile changes it.
+type resourceValue struct {
+	resource string
+	key      string
+}
+
+var _ starlark.Value = resourceValue{}
+
+func (v resourceValue) String() string {
+	return fmt.Sprintf("%s(%q, %q)", fromResourceN, v.resource, v.key)
+}
+func (v resourceValue) Type() string         { return "resource_value" }
+func (v resourceValue) Freeze()              {}
+func (v resourceValue) Truth() starlark.Bool { return true }
+func (v resourceValue) Hash() (uint32, error) {
+	return 0, fmt.Errorf("unhashable type: %s", v.Type())
+}
+
+func (s *tiltfileState) fromResource(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var resource value.Name
+	var key string
+	if err := s.unpackArgs(fn.Name(), args, kwargs,
+		"resource", &resource,
+		"key", &key,
+	); err != nil {
+		return nil, err
+	}
+
+	valid := false
+	for _, k := range resourceValueKeys {
+		if key == k {
+			valid = true
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("%s: unknown key %q. Valid keys are: %s",
+			fn.Name(), key, strings.Join(resourceValueKeys, ", "))
+	}
+
+	return resourceValue{resource: string(resource), key: key}, nil
+}
+
+// An env dict, where values can be strings or from_resource() values.
+type resourceEnv struct {
+	static map[string]string
+	refs   map[string]resourceValue
+}
+
+var _ starlark.Unpacker = &resourceEnv{}
+
+func (e *resourceEnv) Unpack(v starlark.Value) error {
+	e.static = make(map[string]string)
+	e.refs = make(map[string]resourceValue)
+	if v == nil || v == starlark.None {
+		return nil
+	}
+
+	d, ok := v.(*starlark.Dict)
+	if !ok {
+		return fmt.Errorf("expected dict, got %T", v)
+	}
+
+	for _, tuple := range d.Items() {
+		k, ok := value.AsString(tuple[0])
+		if !ok {
+			return fmt.Errorf("key is not a string: %T (%v)", tuple[0], tuple[0])
+		}
+
+		if ref, ok := tuple[1].(resourceValue); ok {
+			e.refs[k] = ref
+			continue
+		}
+
+		v, ok := value.AsString(tuple[1])
+		if !ok {
+			return fmt.Errorf("value is not a string or %s(): %T (%v)", fromResourceN, tuple[1], tuple[1])
+		}
+		e.static[k] = v
+	}
+	return nil
+}
+
+// The resources that the env reads values from.
+func (e resourceEnv) resourceDeps() []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, ref := range e.refs {
+		if !seen[ref.resource] {
+			seen[ref.resource] = true
+			result = append(result, ref.resource)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Add the resolved from_resource() values to the command's env.
+func (s *tiltfileState) withResourceEnv(cmd model.Cmd, refs map[string]resourceValue) (model.Cmd, error) {
+	if cmd.Empty() || len(refs) == 0 {
+		return cmd, nil
+	}
+
+	env := append([]string{}, cmd.Env...)
+	for k, ref := range refs {
+		v, err := s.resolveResourceValue(ref)
+		if err != nil {
+			return model.Cmd{}, err
+		}
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	cmd.Env = env
+	return cmd, nil
+}
+
+func (s *tiltfileState) resolveResourceValue(v resourceValue) (string, error) {
+	var portForwards []model.PortForward
+	var links []model.Link
+	if r, ok := s.k8sByName[v.resource]; ok {
+		portForwards = s.defaultedPortForwards(r.portForwards)
+		links = r.links
+	} else if r, ok := s.localByName[v.resource]; ok {
+		links = r.links
+	} else if instances, ok := s.k8sInstances[v.resource]; ok {
+		return "", fmt.Errorf("%s: %q is deployed to multiple namespaces. Use one of its instances: %s",
+			v, v.resource, strings.Join(instances, ", "))
+	} else {
+		return "", fmt.Errorf("%s: no k8s_resource or local_resource named %q", v, v.resource)
+	}
+
+	switch v.key {
+	case resourceValuePortForwardURL, resourceValuePortForwardPort:
+		if len(portForwards) == 0 {
+			return "", fmt.Errorf("%s: %q has no port_forwards", v, v.resource)
+		}
+		pf := portForwards[0]
+		if v.key == resourceValuePortForwardPort {
+			return strconv.Itoa(pf.LocalPort), nil
+		}
+
+		host := pf.Host
+		if host == "" || host == "0.0.0.0" {
+			host = "localhost"
+		}
+		return fmt.Sprintf("http://%s:%d/%s", host, pf.LocalPort, pf.PathForAppend()), nil
+
+	case resourceValueLinkURL:
+		if len(links) == 0 {
+			return "", fmt.Errorf("%s: %q has no links", v, v.resource)
+		}
+		return links[0].URLString(), nil
+	}
+	return "", fmt.Errorf("%s: unknown key", v)
+}
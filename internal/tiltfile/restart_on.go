@@ -0,0 +1,175 @@
+package tiltfile
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+	"github.com/tilt-dev/tilt/internal/tiltfile/value"
+)
+
+// The restart_on argument of k8s_resource(): which ConfigMaps and Secrets
+// restart the resource's workloads when they change.
+type restartOn struct {
+	// Restart on any ConfigMap or Secret in the Tiltfile that the workloads use.
+	auto bool
+
+	// Otherwise, restart on these. If empty, never restart, even for
+	// configmap_create() and secret_create().
+	refs []k8s.ConfigRef
+}
+
+func restartOnFromStarlark(v starlark.Value) (*restartOn, error) {
+	if v == nil || v == starlark.None {
+		return nil, nil
+	}
+	if s, ok := v.(starlark.String); ok {
+		if s.GoString() != "auto" {
+			return nil, fmt.Errorf("expected 'auto' or a list of configmap:NAME and secret:NAME. Got: %q", s.GoString())
+		}
+		return &restartOn{auto: true}, nil
+	}
+
+	seq, ok := v.(starlark.Sequence)
+	if !ok {
+		return nil, fmt.Errorf("expected 'auto' or a list of configmap:NAME and secret:NAME. Got: %s", v.Type())
+	}
+	strs, err := value.SequenceToStringSlice(seq)
+	if err != nil {
+		return nil, err
+	}
+	result := &restartOn{refs: []k8s.ConfigRef{}}
+	for _, s := range strs {
+		ref, err := k8s.ParseConfigRef(s)
+		if err != nil {
+			return nil, err
+		}
+		result.refs = append(result.refs, ref)
+	}
+	return result, nil
+}
+
+// A ConfigMap or Secret that this Tiltfile deploys.
+type managedConfig struct {
+	ref       k8s.ConfigRef
+	namespace string
+	hash      string
+
+	// For configmap_create(append_hash=True), the name without the hash.
+	alias string
+}
+
+func (s *tiltfileState) managedConfigs() []managedConfig {
+	aliases := make(map[k8s.K8sEntity]string, len(s.generatedConfigs))
+	for _, gc := range s.generatedConfigs {
+		aliases[gc.entity] = gc.name
+	}
+
+	var entities []k8s.K8sEntity
+	for _, r := range s.k8s {
+		entities = append(entities, r.entities...)
+	}
+	entities = append(entities, s.k8sUnresourced...)
+
+	var result []managedConfig
+	for _, e := range entities {
+		data := make(map[string][]byte)
+		switch obj := e.Obj.(type) {
+		case *v1.ConfigMap:
+			for k, v := range obj.Data {
+				data[k] = []byte(v)
+			}
+			for k, v := range obj.BinaryData {
+				data[k] = v
+			}
+		case *v1.Secret:
+			for k, v := range obj.Data {
+				data[k] = v
+			}
+			for k, v := range obj.StringData {
+				data[k] = []byte(v)
+			}
+		default:
+			continue
+		}
+		result = append(result, managedConfig{
+			ref:       k8s.ConfigRef{Kind: strings.ToLower(e.GVK().Kind), Name: e.Name()},
+			namespace: e.Namespace().String(),
+			hash:      configDataHash(data),
+			alias:     aliases[e],
+		})
+	}
+	return result
+}
+
+// applyRestartOn annotates the pod templates of each resource with
+// restart_on with the hashes of the configs it restarts on, so that a
+// change to one of them rolls the pods.
+func (s *tiltfileState) applyRestartOn() error {
+	var configs []managedConfig
+	for _, r := range s.k8s {
+		if r.restartOn == nil {
+			continue
+		}
+		if configs == nil {
+			configs = s.managedConfigs()
+		}
+
+		for _, e := range r.entities {
+			templates, err := k8s.ExtractPodTemplateSpec(&e)
+			if err != nil {
+				return err
+			}
+			if len(templates) == 0 {
+				continue
+			}
+
+			hashes := make(map[k8s.ConfigRef]string)
+			ns := e.Namespace().String()
+			if r.restartOn.auto {
+				for _, t := range templates {
+					visitConfigRefs(&t.Spec, func(kind string, name *string) {
+						ref := k8s.ConfigRef{Kind: strings.ToLower(kind), Name: *name}
+						for _, c := range configs {
+							if c.ref == ref && namespacesMatch(c.namespace, ns) {
+								hashes[c.ref] = c.hash
+							}
+						}
+					})
+				}
+			}
+			for _, ref := range r.restartOn.refs {
+				found := false
+				for _, c := range configs {
+					if c.ref.Kind == ref.Kind && (c.ref.Name == ref.Name || c.alias == ref.Name) &&
+						namespacesMatch(c.namespace, ns) {
+						hashes[c.ref] = c.hash
+						found = true
+					}
+				}
+				if !found {
+					return fmt.Errorf("k8s_resource %q: restart_on: %s %s is not deployed by this Tiltfile",
+						r.name, ref.Kind, ref.Name)
+				}
+			}
+
+			// restart_on replaces the automatic rolls for configmap_create()
+			// and secret_create().
+			for _, t := range templates {
+				delete(t.Annotations, annotationConfigHash)
+				delete(t.Annotations, k8s.AnnotationRestartOn)
+				if len(hashes) == 0 {
+					continue
+				}
+				if t.Annotations == nil {
+					t.Annotations = make(map[string]string)
+				}
+				t.Annotations[k8s.AnnotationRestartOn] = k8s.FormatRestartOn(hashes)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package tiltfile
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+
+	"github.com/tilt-dev/tilt/internal/k8s"
+)
+
+const restartOnConfigYAML = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+data:
+  debug: "true"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secret
+stringData:
+  password: hunter2
+`
+
+func TestRestartOnAuto(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("config.yaml", restartOnConfigYAML)
+	f.file("Tiltfile", `
+k8s_yaml(['app.yaml', 'config.yaml'])
+k8s_resource('app', restart_on='auto')
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("app")
+	hashes := f.deploymentRestartOn(m.K8sTarget().YAML)
+	assert.Equal(t, []string{"configmap/app-config", "secret/app-secret"}, sortedConfigRefs(hashes))
+}
+
+func TestRestartOnExplicit(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("config.yaml", restartOnConfigYAML)
+	f.file("Tiltfile", `
+k8s_yaml(['app.yaml', 'config.yaml'])
+k8s_resource('app', restart_on=['configmap:app-config'])
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("app")
+	hashes := f.deploymentRestartOn(m.K8sTarget().YAML)
+	assert.Equal(t, []string{"configmap/app-config"}, sortedConfigRefs(hashes))
+}
+
+func TestRestartOnHashChangesWithData(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("config.yaml", restartOnConfigYAML)
+	f.file("Tiltfile", `
+k8s_yaml(['app.yaml', 'config.yaml'])
+k8s_resource('app', restart_on=['configmap:app-config'])
+`)
+
+	f.load()
+	before := f.deploymentRestartOn(f.assertNextManifest("app").K8sTarget().YAML)
+
+	f.file("config.yaml", strings.Replace(restartOnConfigYAML, `debug: "true"`, `debug: "false"`, 1))
+	f.load()
+	after := f.deploymentRestartOn(f.assertNextManifest("app").K8sTarget().YAML)
+
+	assert.Equal(t, []k8s.ConfigRef{{Kind: "configmap", Name: "app-config"}}, k8s.ChangedConfigs(before, after))
+}
+
+func TestRestartOnOptOut(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("config/dev.yaml", "debug: true\n")
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+configmap_create('app-config', from_files={'dev.yaml': 'config/dev.yaml'})
+k8s_yaml('app.yaml')
+k8s_resource('app', restart_on=[])
+`)
+
+	f.load()
+
+	m := f.assertNextManifest("app")
+	for _, e := range f.entities(m.K8sTarget().YAML) {
+		if d, ok := e.Obj.(*appsv1.Deployment); ok {
+			assert.NotContains(t, d.Spec.Template.Annotations, annotationConfigHash)
+			assert.NotContains(t, d.Spec.Template.Annotations, k8s.AnnotationRestartOn)
+		}
+	}
+}
+
+func TestRestartOnNotDeployed(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+k8s_yaml('app.yaml')
+k8s_resource('app', restart_on=['configmap:app-config'])
+`)
+
+	f.loadErrString(`k8s_resource "app": restart_on: configmap app-config is not deployed by this Tiltfile`)
+}
+
+func TestRestartOnInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("app.yaml", generatedConfigDeployment)
+	f.file("Tiltfile", `
+k8s_yaml('app.yaml')
+k8s_resource('app', restart_on=['deployment:app'])
+`)
+
+	f.loadErrString(`invalid config reference "deployment:app"`)
+}
+
+func (f *fixture) deploymentRestartOn(yaml string) map[k8s.ConfigRef]string {
+	for _, e := range f.entities(yaml) {
+		if _, ok := e.Obj.(*appsv1.Deployment); ok {
+			hashes, err := k8s.ReadRestartOn(e)
+			require.NoError(f.t, err)
+			return hashes
+		}
+	}
+	f.t.Fatalf("no deployment in YAML:\n%s", yaml)
+	return nil
+}
+
+func sortedConfigRefs(hashes map[k8s.ConfigRef]string) []string {
+	var result []string
+	for ref := range hashes {
+		result = append(result, ref.String())
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// Prompts on the terminal that Tilt was started from.
+type TerminalPrompter struct {
+	in  io.Reader
+	out io.Writer
+}
+
+var _ Prompter = TerminalPrompter{}
+
+func NewTerminalPrompter(in io.Reader, out io.Writer) TerminalPrompter {
+	return TerminalPrompter{in: in, out: out}
+}
+
+func (p TerminalPrompter) Prompt(r Request) (Answer, error) {
+	// If there's no one to ask, the answer is no.
+	if f, ok := p.in.(*os.File); ok && !isatty.IsTerminal(f.Fd()) {
+		_, _ = fmt.Fprintf(p.out, "Not running %s %q: can't prompt for approval without a terminal\n",
+			r.Source, r.Cmd.String())
+		return AnswerDeny, nil
+	}
+
+	_, _ = fmt.Fprintf(p.out, "\n%s wants to run:\n  %s\n", r.Tiltfile, r.Cmd.String())
+	if r.Cmd.Dir != "" {
+		_, _ = fmt.Fprintf(p.out, "in %s\n", r.Cmd.Dir)
+	}
+	_, _ = fmt.Fprintf(p.out, "Allow? [y]es / [a]lways / [N]o: ")
+
+	line, err := bufio.NewReader(p.in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return AnswerDeny, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return AnswerAllowOnce, nil
+	case "a", "always":
+		return AnswerAllowAlways, nil
+	}
+	return AnswerDeny, nil
+}
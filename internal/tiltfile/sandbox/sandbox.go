@@ -0,0 +1,304 @@
+// Package sandbox decides whether a Tiltfile may run commands on the host.
+//
+// Tiltfiles can run arbitrary commands with local(). When you load
+// Tiltfiles from other teams, the sandbox adds a check before each command
+// runs: deny it, ask first, or allow it and record it in an audit log.
+package sandbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// Where the sandbox keeps its state, in the Tilt data dir.
+const (
+	AuditLogFile  = "local-commands.log"
+	ApprovalsFile = "local-command-approvals.json"
+)
+
+type Mode string
+
+const (
+	// Commands run as usual, with no checks.
+	ModeOff Mode = ""
+
+	// Commands are blocked, and the Tiltfile fails to load.
+	ModeDeny Mode = "deny"
+
+	// The user is asked to approve each command that hasn't been approved before.
+	ModePrompt Mode = "prompt"
+
+	// Commands run, and are recorded in the audit log.
+	ModeAllow Mode = "allow"
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case ModeOff, ModeDeny, ModePrompt, ModeAllow:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("invalid sandbox mode %q: must be one of %s, %s, %s", s, ModeDeny, ModePrompt, ModeAllow)
+}
+
+type Config struct {
+	Mode Mode
+
+	// By default, only local() commands that run while the Tiltfile loads
+	// are checked. If true, the cmd and serve_cmd of each local_resource are
+	// also checked when the Tiltfile declares them.
+	IncludeLocalResources bool
+}
+
+// A command that the Tiltfile wants to run.
+type Request struct {
+	// What's running the command, e.g., "local" or "local_resource(foo)".
+	Source string
+
+	// The Tiltfile (or extension) that declared the command.
+	Tiltfile string
+
+	Cmd model.Cmd
+}
+
+// A stable identifier for a command, used to remember approvals.
+func (r Request) Hash() string {
+	h := sha256.New()
+	for _, arg := range r.Cmd.Argv {
+		_, _ = fmt.Fprintf(h, "%s\x00", arg)
+	}
+	_, _ = fmt.Fprintf(h, "\x01%s\x01", r.Cmd.Dir)
+	env := append([]string{}, r.Cmd.Env...)
+	sort.Strings(env)
+	for _, e := range env {
+		_, _ = fmt.Fprintf(h, "%s\x00", e)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// How the user answered a prompt.
+type Answer int
+
+const (
+	AnswerDeny Answer = iota
+	AnswerAllowOnce
+	AnswerAllowAlways
+)
+
+// Asks the user whether to run a command.
+type Prompter interface {
+	Prompt(r Request) (Answer, error)
+}
+
+type Sandbox struct {
+	config   Config
+	base     xdg.Base
+	prompter Prompter
+
+	mu sync.Mutex
+}
+
+func ProvideSandbox(config Config, base xdg.Base) *Sandbox {
+	return NewSandbox(config, base, NewTerminalPrompter(os.Stdin, os.Stderr))
+}
+
+func NewSandbox(config Config, base xdg.Base, prompter Prompter) *Sandbox {
+	return &Sandbox{config: config, base: base, prompter: prompter}
+}
+
+// Enabled reports whether commands from the given source are checked.
+func (s *Sandbox) Enabled(localResource bool) bool {
+	if s == nil || s.config.Mode == ModeOff {
+		return false
+	}
+	return !localResource || s.config.IncludeLocalResources
+}
+
+func (s *Sandbox) Mode() Mode {
+	if s == nil {
+		return ModeOff
+	}
+	return s.config.Mode
+}
+
+// Check decides whether the command may run, and records the decision in
+// the audit log.
+//
+// If the remembered approvals were corrupt, Check quarantines them and asks
+// again, and returns a *statefile.CorruptError along with the decision.
+func (s *Sandbox) Check(r Request) (bool, error) {
+	if s == nil || s.config.Mode == ModeOff {
+		return true, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allowed, decision, err := s.decide(r)
+	if err != nil && !statefile.IsCorrupt(err) {
+		return false, err
+	}
+
+	auditErr := s.audit(r, decision)
+	if auditErr != nil {
+		return false, auditErr
+	}
+	return allowed, err
+}
+
+func (s *Sandbox) decide(r Request) (bool, string, error) {
+	switch s.config.Mode {
+	case ModeDeny:
+		return false, "denied", nil
+	case ModeAllow:
+		return true, "allowed", nil
+	}
+
+	approvals, loadErr := s.loadApprovals()
+	if loadErr != nil && !statefile.IsCorrupt(loadErr) {
+		return false, "", loadErr
+	}
+
+	allowed, decision, err := s.prompt(r, approvals)
+	if err != nil {
+		return false, "", err
+	}
+	return allowed, decision, loadErr
+}
+
+func (s *Sandbox) prompt(r Request, approvals map[string]approval) (bool, string, error) {
+	if _, ok := approvals[r.Hash()]; ok {
+		return true, "approved (remembered)", nil
+	}
+
+	answer, err := s.prompter.Prompt(r)
+	if err != nil {
+		return false, "", err
+	}
+	switch answer {
+	case AnswerAllowOnce:
+		return true, "approved", nil
+	case AnswerAllowAlways:
+		approvals[r.Hash()] = approval{Command: r.Cmd.String(), Dir: r.Cmd.Dir, ApprovedAt: time.Now()}
+		err := s.saveApprovals(approvals)
+		if err != nil {
+			return false, "", err
+		}
+		return true, "approved (always)", nil
+	}
+	return false, "declined", nil
+}
+
+type auditEntry struct {
+	Time     time.Time `json:"time"`
+	Mode     Mode      `json:"mode"`
+	Decision string    `json:"decision"`
+	Source   string    `json:"source"`
+	Tiltfile string    `json:"tiltfile"`
+	Dir      string    `json:"dir"`
+	Command  string    `json:"command"`
+	Hash     string    `json:"hash"`
+}
+
+// audit appends the decision to the audit log, one JSON object per line.
+func (s *Sandbox) audit(r Request, decision string) error {
+	p, err := s.base.DataFile(AuditLogFile)
+	if err != nil {
+		return fmt.Errorf("sandbox audit log: %v", err)
+	}
+
+	line, err := json.Marshal(auditEntry{
+		Time:     time.Now(),
+		Mode:     s.config.Mode,
+		Decision: decision,
+		Source:   r.Source,
+		Tiltfile: r.Tiltfile,
+		Dir:      r.Cmd.Dir,
+		Command:  r.Cmd.String(),
+		Hash:     r.Hash(),
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("sandbox audit log: %v", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	if err != nil {
+		return fmt.Errorf("sandbox audit log: %v", err)
+	}
+	return nil
+}
+
+type approval struct {
+	Command    string    `json:"command"`
+	Dir        string    `json:"dir"`
+	ApprovedAt time.Time `json:"approvedAt"`
+}
+
+func (s *Sandbox) loadApprovals() (map[string]approval, error) {
+	p, err := s.base.DataFile(ApprovalsFile)
+	if err != nil {
+		return nil, fmt.Errorf("sandbox approvals: %v", err)
+	}
+
+	result := map[string]approval{}
+	contents, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return result, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("sandbox approvals: %v", err)
+	}
+	err = json.Unmarshal(contents, &result)
+	if err != nil {
+		return map[string]approval{}, statefile.Recover(p, err)
+	}
+	return result, nil
+}
+
+func (s *Sandbox) saveApprovals(approvals map[string]approval) error {
+	p, err := s.base.DataFile(ApprovalsFile)
+	if err != nil {
+		return fmt.Errorf("sandbox approvals: %v", err)
+	}
+	contents, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(p, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("sandbox approvals: %v", err)
+	}
+	return nil
+}
+
+// BlockedError lists the commands that the sandbox didn't allow.
+type BlockedError struct {
+	Mode     Mode
+	Requests []Request
+}
+
+func (e BlockedError) Error() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, "--sandbox-local-commands=%s blocked %d command(s) that the Tiltfile would have run:", e.Mode, len(e.Requests))
+	for _, r := range e.Requests {
+		_, _ = fmt.Fprintf(&sb, "\n  %s: %s", r.Source, r.Cmd)
+		if r.Cmd.Dir != "" {
+			_, _ = fmt.Fprintf(&sb, " (in %s)", r.Cmd.Dir)
+		}
+	}
+	return sb.String()
+}
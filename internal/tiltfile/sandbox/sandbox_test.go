@@ -0,0 +1,136 @@
+package sandbox
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/statefile"
+	"github.com/tilt-dev/tilt/internal/xdg"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+type fakePrompter struct {
+	answer  Answer
+	prompts []Request
+}
+
+func (p *fakePrompter) Prompt(r Request) (Answer, error) {
+	p.prompts = append(p.prompts, r)
+	return p.answer, nil
+}
+
+func TestPromptRemembersAlwaysApprovals(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	prompter := &fakePrompter{answer: AnswerAllowAlways}
+	s := NewSandbox(Config{Mode: ModePrompt}, base, prompter)
+	r := Request{Source: "local", Tiltfile: "/src/Tiltfile", Cmd: model.ToHostCmd("make gen")}
+
+	allowed, err := s.Check(r)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// A new session doesn't ask again.
+	s = NewSandbox(Config{Mode: ModePrompt}, base, prompter)
+	allowed, err = s.Check(r)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Len(t, prompter.prompts, 1)
+
+	// A different command does.
+	prompter.answer = AnswerDeny
+	allowed, err = s.Check(Request{Source: "local", Tiltfile: "/src/Tiltfile", Cmd: model.ToHostCmd("make clean")})
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Len(t, prompter.prompts, 2)
+
+	p, err := base.DataFile(AuditLogFile)
+	require.NoError(t, err)
+	log, err := os.ReadFile(p)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(log)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], `"decision":"approved (always)"`)
+	assert.Contains(t, lines[1], `"decision":"approved (remembered)"`)
+	assert.Contains(t, lines[2], `"decision":"declined"`)
+}
+
+func TestCorruptApprovalsAskAgain(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	p, err := base.DataFile(ApprovalsFile)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(p, []byte("{\"abc"), 0600))
+
+	prompter := &fakePrompter{answer: AnswerAllowAlways}
+	s := NewSandbox(Config{Mode: ModePrompt}, base, prompter)
+	r := Request{Source: "local", Tiltfile: "/src/Tiltfile", Cmd: model.ToHostCmd("make gen")}
+
+	allowed, err := s.Check(r)
+	assert.True(t, statefile.IsCorrupt(err))
+	assert.True(t, allowed)
+	assert.Len(t, prompter.prompts, 1)
+
+	// The new approval was saved over the corrupt file.
+	allowed, err = s.Check(r)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Len(t, prompter.prompts, 1)
+}
+
+func TestPromptAllowOnceIsNotRemembered(t *testing.T) {
+	base := xdg.FakeBase{Dir: t.TempDir()}
+	prompter := &fakePrompter{answer: AnswerAllowOnce}
+	s := NewSandbox(Config{Mode: ModePrompt}, base, prompter)
+	r := Request{Source: "local", Cmd: model.ToHostCmd("make gen")}
+
+	for i := 0; i < 2; i++ {
+		allowed, err := s.Check(r)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	assert.Len(t, prompter.prompts, 2)
+}
+
+func TestHashDependsOnDirAndEnv(t *testing.T) {
+	cmd := model.Cmd{Argv: []string{"make", "gen"}, Dir: "/a", Env: []string{"A=1", "B=2"}}
+	r := Request{Cmd: cmd}
+
+	reordered := cmd
+	reordered.Env = []string{"B=2", "A=1"}
+	assert.Equal(t, r.Hash(), Request{Cmd: reordered}.Hash())
+
+	otherDir := cmd
+	otherDir.Dir = "/b"
+	assert.NotEqual(t, r.Hash(), Request{Cmd: otherDir}.Hash())
+
+	otherEnv := cmd
+	otherEnv.Env = []string{"A=1"}
+	assert.NotEqual(t, r.Hash(), Request{Cmd: otherEnv}.Hash())
+}
+
+func TestTerminalPrompter(t *testing.T) {
+	var out bytes.Buffer
+	p := NewTerminalPrompter(strings.NewReader("a\n"), &out)
+	answer, err := p.Prompt(Request{Source: "local", Tiltfile: "/src/Tiltfile", Cmd: model.ToHostCmd("make gen")})
+	require.NoError(t, err)
+	assert.Equal(t, AnswerAllowAlways, answer)
+	assert.Contains(t, out.String(), "/src/Tiltfile wants to run:\n  make gen\n")
+
+	p = NewTerminalPrompter(strings.NewReader("\n"), &out)
+	answer, err = p.Prompt(Request{Source: "local", Cmd: model.ToHostCmd("make gen")})
+	require.NoError(t, err)
+	assert.Equal(t, AnswerDeny, answer)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("prompt")
+	require.NoError(t, err)
+	assert.Equal(t, ModePrompt, mode)
+
+	_, err = ParseMode("yes")
+	assert.EqualError(t, err, `invalid sandbox mode "yes": must be one of deny, prompt, allow`)
+}
@@ -106,7 +106,7 @@ func (e *Environment) AddBuiltin(name string, f Function) error {
 		for _, ext := range e.plugins {
 			onBuiltinCallExt, ok := ext.(OnBuiltinCallPlugin)
 			if ok {
-				onBuiltinCallExt.OnBuiltinCall(name, fn)
+				onBuiltinCallExt.OnBuiltinCall(thread, name, fn)
 			}
 		}
 
@@ -202,6 +202,20 @@ func (e *Environment) start(tf *v1alpha1.Tiltfile) (Model, error) {
 	}
 
 	t := e.newThread(model)
+	if e.ctx != nil {
+		// Stop execution between statements if the load is canceled.
+		// Builtins that run commands watch the context themselves.
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-e.ctx.Done():
+				t.Cancel(e.ctx.Err().Error())
+			case <-done:
+			}
+		}()
+	}
+
 	_, err = e.exec(t, path)
 	model.BuiltinCalls = e.builtinCalls
 	if errors.Is(err, ErrStopExecution) {
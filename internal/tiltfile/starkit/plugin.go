@@ -19,7 +19,7 @@ type OnBuiltinCallPlugin interface {
 	Plugin
 
 	// Called before each builtin is called
-	OnBuiltinCall(name string, fn *starlark.Builtin)
+	OnBuiltinCall(t *starlark.Thread, name string, fn *starlark.Builtin)
 }
 
 // Starkit plugins are not allowed to have mutable state.
@@ -47,11 +47,16 @@ func NewFakePlugin(repoReconciler ExtRepoReconciler, extReconciler ExtReconciler
 
 type State struct {
 	ExtsLoaded map[string]bool
+
+	// The local directories of extension repos that Tilt downloaded
+	// (as opposed to repos that point at a local path with file://).
+	FetchedRepoPaths map[string]bool
 }
 
 func (e Plugin) NewState() interface{} {
 	return State{
-		ExtsLoaded: make(map[string]bool),
+		ExtsLoaded:       make(map[string]bool),
+		FetchedRepoPaths: make(map[string]bool),
 	}
 }
 
@@ -70,6 +75,16 @@ func (e *Plugin) recordExtensionLoaded(ctx context.Context, t *starlark.Thread,
 	}
 }
 
+func (e *Plugin) recordFetchedRepo(ctx context.Context, t *starlark.Thread, path string) {
+	err := starkit.SetState(t, func(existing State) (State, error) {
+		existing.FetchedRepoPaths[path] = true
+		return existing, nil
+	})
+	if err != nil {
+		logger.Get(ctx).Debugf("error updating state on Tilt extensions loader: %v", err)
+	}
+}
+
 func (e *Plugin) LocalPath(t *starlark.Thread, arg string) (localPath string, err error) {
 	if !strings.HasPrefix(arg, extensionPrefix) {
 		return "", nil
@@ -108,6 +123,9 @@ func (e *Plugin) LocalPath(t *starlark.Thread, arg string) (localPath string, er
 	if repoStatus.Path == "" {
 		return "", fmt.Errorf("extension repo not resolved: %s", repo.Name)
 	}
+	if !strings.HasPrefix(repo.Spec.URL, "file://") {
+		e.recordFetchedRepo(ctx, t, repoStatus.Path)
+	}
 
 	repoResolved := repo.DeepCopy()
 	repoResolved.Status = repoStatus
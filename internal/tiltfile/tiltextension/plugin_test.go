@@ -26,6 +26,8 @@ printFoo()
 
 	res := f.assertExecOutput("foo")
 	f.assertLoadRecorded(res, "fetchable")
+	assert.Equal(t, map[string]bool{f.tmp.JoinPath("tilt-extensions"): true},
+		MustState(res).FetchedRepoPaths)
 }
 
 func TestAlreadyPresentWorks(t *testing.T) {
@@ -161,6 +163,9 @@ printFoo()
 
 	res := f.assertExecOutput("foo")
 	f.assertLoadRecorded(res, "my-extension")
+
+	// Local repos aren't part of the extension cache.
+	assert.Empty(t, MustState(res).FetchedRepoPaths)
 }
 
 func TestLoadedExtensionTwiceDifferentFiles(t *testing.T) {
@@ -15,6 +15,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/controllers/apiset"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/golist"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/localexec"
 	"github.com/tilt-dev/tilt/internal/ospath"
@@ -26,6 +27,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/hasher"
 	"github.com/tilt-dev/tilt/internal/tiltfile/io"
 	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
 	"github.com/tilt-dev/tilt/internal/tiltfile/telemetry"
@@ -61,6 +63,10 @@ type TiltfileLoadResult struct {
 	ObjectSet           apiset.ObjectSet
 	Hashes              hasher.Hashes
 	CISettings          *corev1alpha1.SessionCISpec
+	ResourceGroups      []model.ResourceGroup
+
+	// Set if Tilt should create an image pull secret for the default registry.
+	DefaultRegistryPullSecret *k8s.PullSecretSettings
 
 	// For diagnostic purposes only
 	BuiltinCalls []starkit.BuiltinCall `json:"-"`
@@ -106,7 +112,9 @@ func ProvideTiltfileLoader(
 	webHost model.WebHost,
 	execer localexec.Execer,
 	fDefaults feature.Defaults,
-	env clusterid.Product) TiltfileLoader {
+	env clusterid.Product,
+	sandbox *sandbox.Sandbox,
+	schemaValidator *k8s.SchemaValidator) TiltfileLoader {
 	return tiltfileLoader{
 		analytics:        analytics,
 		k8sContextPlugin: k8sContextPlugin,
@@ -119,6 +127,9 @@ func ProvideTiltfileLoader(
 		execer:           execer,
 		fDefaults:        fDefaults,
 		env:              env,
+		sandbox:          sandbox,
+		schemaValidator:  schemaValidator,
+		goDeps:           golist.NewAnalyzer(execer),
 	}
 }
 
@@ -135,6 +146,12 @@ type tiltfileLoader struct {
 	ciSettingsPlugin cisettings.Plugin
 	fDefaults        feature.Defaults
 	env              clusterid.Product
+	sandbox          *sandbox.Sandbox
+	schemaValidator  *k8s.SchemaValidator
+
+	// Shared across loads, so that Go dependencies are only recomputed
+	// when they might have changed.
+	goDeps *golist.Analyzer
 }
 
 var _ TiltfileLoader = &tiltfileLoader{}
@@ -175,11 +192,22 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 
 	s := newTiltfileState(ctx, tfl.dcCli, tfl.webHost, tfl.execer, tfl.k8sContextPlugin, tfl.versionPlugin,
 		tfl.configPlugin, tfl.extensionPlugin, tfl.ciSettingsPlugin, feature.FromDefaults(tfl.fDefaults))
+	s.sandbox = tfl.sandbox
+	s.schemaValidator = tfl.schemaValidator
+	s.goDeps = tfl.goDeps
 
+	s.progress.run(s.logger)
 	manifests, result, err := s.loadManifests(tf)
+	s.progress.stop(s.logger, err)
+	if len(s.sandboxBlocked) > 0 {
+		// Blocked commands return no output, so any other error is
+		// likely a consequence of the block.
+		err = sandbox.BlockedError{Mode: tfl.sandbox.Mode(), Requests: s.sandboxBlocked}
+	}
 
 	tlr.BuiltinCalls = result.BuiltinCalls
 	tlr.DefaultRegistry = s.defaultReg
+	tlr.DefaultRegistryPullSecret = s.defaultRegPullSecret
 
 	// All data models are loaded with GetState. We ignore the error if the state
 	// isn't properly loaded. This is necessary for handling partial Tiltfile
@@ -194,10 +222,30 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 
 	ioState, _ := io.GetState(result)
 
-	tlr.ConfigFiles = append(tlr.ConfigFiles, ioState.Paths...)
+	extState, _ := tiltextension.GetState(result)
+
+	readPaths := ioState.Paths
+	if ws.IgnoreExtensionCache {
+		// Don't watch the files in extension repos that Tilt downloaded.
+		var repoDirs []string
+		for dir := range extState.FetchedRepoPaths {
+			repoDirs = append(repoDirs, dir)
+		}
+		readPaths = nil
+		for _, p := range ioState.Paths {
+			if !ospath.IsChildOfOne(repoDirs, p) {
+				readPaths = append(readPaths, p)
+			}
+		}
+	}
+
+	tlr.ConfigFiles = append(tlr.ConfigFiles, readPaths...)
 	tlr.ConfigFiles = append(tlr.ConfigFiles, s.postExecReadFiles...)
 	tlr.ConfigFiles = sliceutils.DedupedAndSorted(tlr.ConfigFiles)
 
+	s.keepGitignoredConfigFiles(tlr.ConfigFiles)
+	tlr.WatchSettings.Ignores = append(tlr.WatchSettings.Ignores, s.gitignores...)
+
 	dps, _ := dockerprune.GetState(result)
 	tlr.DockerPruneSettings = dps
 
@@ -208,6 +256,7 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 	tlr.FeatureFlags = s.features.ToEnabled()
 	tlr.Error = err
 	tlr.Manifests = manifests
+	tlr.ResourceGroups = s.resolvedResourceGroups
 	tlr.TeamID = s.teamID
 
 	objectSet, _ := v1alpha1.GetState(result)
@@ -234,7 +283,6 @@ func (tfl tiltfileLoader) Load(ctx context.Context, tf *corev1alpha1.Tiltfile, p
 	if tlr.Error == nil {
 		s.logger.Infof("Successfully loaded Tiltfile (%s)", duration)
 	}
-	extState, _ := tiltextension.GetState(result)
 	hashState, _ := hasher.GetState(result)
 
 	var prevHashes hasher.Hashes
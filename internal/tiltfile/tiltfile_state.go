@@ -31,6 +31,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/container"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
 	"github.com/tilt-dev/tilt/internal/feature"
+	"github.com/tilt-dev/tilt/internal/golist"
 	"github.com/tilt-dev/tilt/internal/k8s"
 	"github.com/tilt-dev/tilt/internal/ospath"
 	"github.com/tilt-dev/tilt/internal/sliceutils"
@@ -46,6 +47,7 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/loaddynamic"
 	"github.com/tilt-dev/tilt/internal/tiltfile/metrics"
 	"github.com/tilt-dev/tilt/internal/tiltfile/os"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/internal/tiltfile/secretsettings"
 	"github.com/tilt-dev/tilt/internal/tiltfile/shlex"
 	"github.com/tilt-dev/tilt/internal/tiltfile/starkit"
@@ -87,6 +89,12 @@ type tiltfileState struct {
 	extensionPlugin  *tiltextension.Plugin
 	ciSettingsPlugin cisettings.Plugin
 	features         feature.FeatureSet
+	sandbox          *sandbox.Sandbox
+	goDeps           *golist.Analyzer
+
+	// Validates YAML against the cluster's schema. If nil, YAML is validated
+	// against the schemas compiled into Tilt.
+	schemaValidator *k8s.SchemaValidator
 
 	// added to during execution
 	buildIndex     *buildIndex
@@ -103,15 +111,33 @@ type tiltfileState struct {
 	k8sByName      map[string]*k8sResource
 	k8sUnresourced []k8s.K8sEntity
 
+	// ConfigMaps and Secrets made by configmap_create() and secret_create().
+	generatedConfigs []generatedConfig
+
+	// Resources deployed to multiple namespaces, mapped to the names of
+	// their instances.
+	k8sInstances map[string][]string
+
 	dc dcResourceMap
 
 	k8sResourceOptions []k8sResourceOptions
 	localResources     []*localResource
 	localByName        map[string]*localResource
 
+	// Groups from resource_group(), and the groups resolved against the
+	// manifests once they're assembled.
+	resourceGroups         []resourceGroup
+	resolvedResourceGroups []model.ResourceGroup
+
+	// Base images that other Tilt sessions publish.
+	baseImageDeps []*baseImageDependency
+
 	// ensure that any images are pushed to/pulled from this registry, rewriting names if needed
 	defaultReg *v1alpha1.RegistryHosting
 
+	// how pods get the credentials to pull from the default registry, if Tilt manages them
+	defaultRegPullSecret *k8s.PullSecretSettings
+
 	k8sKinds map[k8s.ObjectSelector]*tiltfile_k8s.KindInfo
 
 	workloadToResourceFunction workloadToResourceFunction
@@ -146,10 +172,18 @@ type tiltfileState struct {
 
 	logger logger.Logger
 
+	// Commands that the sandbox didn't allow to run.
+	sandboxBlocked []sandbox.Request
+
+	progress *loadProgress
+
 	// postExecReadFiles is generally a mistake -- it means that if tiltfile execution fails,
 	// these will never be read. Remove these when you can!!!
 	postExecReadFiles []string
 
+	// Patterns from .gitignore files, one per repo, if watch_settings(use_gitignore=True).
+	gitignores []model.Dockerignore
+
 	// Temporary directory for storing generated artifacts during the lifetime of the tiltfile context.
 	// The directory is recursively deleted when the context is done.
 	scratchDir *fwatch.TempDir
@@ -179,10 +213,12 @@ func newTiltfileState(
 		buildIndex:                newBuildIndex(),
 		k8sObjectIndex:            tiltfile_k8s.NewState(),
 		k8sByName:                 make(map[string]*k8sResource),
+		k8sInstances:              make(map[string][]string),
 		dc:                        make(map[string]*dcResourceSet),
 		localByName:               make(map[string]*localResource),
 		usedImages:                make(map[string]bool),
 		logger:                    logger.Get(ctx),
+		progress:                  newLoadProgress(),
 		builtinCallCounts:         make(map[string]int),
 		builtinArgCounts:          make(map[string]map[string]int),
 		unconsumedLiveUpdateSteps: make(map[string]liveUpdateStep),
@@ -243,6 +279,17 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 		return nil, result, starkit.UnpackBacktrace(err)
 	}
 
+	ws, err := watch.GetState(result)
+	if err != nil {
+		return nil, result, err
+	}
+	if ws.UseGitignore {
+		err = s.loadGitignores(tf.Spec.Path)
+		if err != nil {
+			return nil, result, err
+		}
+	}
+
 	resources, unresourced, err := s.assemble()
 	if err != nil {
 		return nil, result, err
@@ -257,6 +304,7 @@ func (s *tiltfileState) loadManifests(tf *v1alpha1.Tiltfile) ([]model.Manifest,
 	if err != nil {
 		s.logger.Warnf("%s", err.Error())
 	}
+	s.warnUnusedBaseImageDependencies()
 
 	manifests := []model.Manifest{}
 	k8sContextState, err := k8scontext.GetState(result)
@@ -287,7 +335,7 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		}
 
 		for _, dc := range resources.dc {
-			ms, err := s.translateDC(dc)
+			ms, err := s.translateDC(dc, us)
 			if err != nil {
 				return nil, result, err
 			}
@@ -312,6 +360,18 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 	manifests = append(manifests, localManifests...)
 
 	if len(unresourced) > 0 {
+		for _, e := range unresourced {
+			images, err := findImageRefPlaceholders(e)
+			if err != nil {
+				return nil, starkit.Model{}, err
+			}
+			if len(images) > 0 {
+				return nil, starkit.Model{}, fmt.Errorf("%s uses image_ref(%q), but isn't part of a resource. "+
+					"Group it into a resource with k8s_resource(objects=...) so that the image is built first",
+					fullNameFromK8sEntity(e), container.FamiliarString(images[0]))
+			}
+		}
+
 		mn := model.UnresourcedYAMLManifestName
 		r := &k8sResource{
 			name:             mn.String(),
@@ -332,6 +392,11 @@ to your Tiltfile. Otherwise, switch k8s contexts and restart Tilt.`, kubeContext
 		return nil, starkit.Model{}, err
 	}
 
+	s.resolvedResourceGroups, err = s.resolveResourceGroups(manifests)
+	if err != nil {
+		return nil, result, err
+	}
+
 	for i := range manifests {
 		// ensure all manifests have a label indicating they're owned
 		// by the Tiltfile - some reconcilers have special handling
@@ -359,6 +424,8 @@ const (
 	dockerBuildN     = "docker_build"
 	customBuildN     = "custom_build"
 	defaultRegistryN = "default_registry"
+	argFromCmdN      = "arg_from_cmd"
+	argFromFileN     = "arg_from_file"
 
 	// docker compose functions
 	dockerComposeN = "docker_compose"
@@ -373,10 +440,14 @@ const (
 	k8sImageJSONPathN           = "k8s_image_json_path"
 	workloadToResourceFunctionN = "workload_to_resource_function"
 	k8sCustomDeployN            = "k8s_custom_deploy"
+	imageRefN                   = "image_ref"
+	configmapCreateN            = "configmap_create"
+	secretCreateN               = "secret_create"
 
 	// local resource functions
 	localResourceN = "local_resource"
 	testN          = "test" // a deprecated fork of local resource
+	fromResourceN  = "from_resource"
 
 	// file functions
 	localN     = "local"
@@ -401,7 +472,8 @@ const (
 	disableSnapshotsN = "disable_snapshots"
 
 	// other functions
-	setTeamN = "set_team"
+	setTeamN  = "set_team"
+	progressN = "progress"
 )
 
 type triggerMode int
@@ -467,12 +539,15 @@ func starlarkTriggerModeToModel(triggerMode triggerMode, autoInit bool) (model.T
 	}
 }
 
-// count how many times each Builtin is called, for analytics
-func (s *tiltfileState) OnBuiltinCall(name string, fn *starlark.Builtin) {
+// count how many times each Builtin is called, for analytics,
+// and track the load's progress
+func (s *tiltfileState) OnBuiltinCall(t *starlark.Thread, name string, fn *starlark.Builtin) {
 	s.builtinCallCounts[name]++
+	s.progress.onBuiltinCall(t, name)
 }
 
 func (s *tiltfileState) OnExec(t *starlark.Thread, tiltfilePath string, contents []byte) error {
+	s.progress.onExec(tiltfilePath, contents)
 	return nil
 }
 
@@ -549,14 +624,22 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		{dockerBuildN, s.dockerBuild},
 		{customBuildN, s.customBuild},
 		{defaultRegistryN, s.defaultRegistry},
+		{argFromCmdN, s.argFromCmd},
+		{argFromFileN, s.argFromFile},
+		{baseImageDependencyN, s.baseImageDependency},
 		{dockerComposeN, s.dockerCompose},
 		{dcResourceN, s.dcResource},
 		{k8sYamlN, s.k8sYaml},
 		{filterYamlN, s.filterYaml},
 		{k8sResourceN, s.k8sResource},
 		{k8sCustomDeployN, s.k8sCustomDeploy},
+		{imageRefN, s.imageRef},
+		{configmapCreateN, s.configmapCreate},
+		{secretCreateN, s.secretCreate},
 		{localResourceN, s.localResource},
 		{testN, s.localResource},
+		{fromResourceN, s.fromResource},
+		{resourceGroupN, s.resourceGroup},
 		{portForwardN, s.portForward},
 		{k8sKindN, s.k8sKind},
 		{k8sImageJSONPathN, s.k8sImageJsonPath},
@@ -572,6 +655,7 @@ func (s *tiltfileState) OnStart(e *starkit.Environment) error {
 		{disableFeatureN, s.disableFeature},
 		{disableSnapshotsN, s.disableSnapshots},
 		{setTeamN, s.setTeam},
+		{progressN, s.progressFn},
 	} {
 		err := e.AddBuiltin(b.name, b.builtin)
 		if err != nil {
@@ -672,7 +756,13 @@ func (s *tiltfileState) assembleImages() error {
 				depBuilder := s.buildIndex.findBuilderForConsumedImage(depImage)
 				if depBuilder == nil {
 					// Images in the Dockerfile that don't have docker_build
-					// instructions are OK. We'll pull them as prebuilt images.
+					// instructions are OK. We'll pull them as prebuilt images,
+					// or use the image that another Tilt session published.
+					baseDep := s.findBaseImageDependency(depImage)
+					if baseDep != nil && !containsBaseImageDep(imageBuilder.baseImageDeps, baseDep) {
+						baseDep.used = true
+						imageBuilder.baseImageDeps = append(imageBuilder.baseImageDeps, baseDep)
+					}
 					continue
 				}
 
@@ -763,7 +853,12 @@ func (s *tiltfileState) maybeAddDockerComposeImageBuilder(svc *dcService) error
 }
 
 func (s *tiltfileState) assembleK8s() error {
-	err := s.assembleK8sByWorkload()
+	usedConfigs, err := s.linkGeneratedConfigs()
+	if err != nil {
+		return err
+	}
+
+	err = s.assembleK8sByWorkload()
 	if err != nil {
 		return err
 	}
@@ -773,8 +868,10 @@ func (s *tiltfileState) assembleK8s() error {
 		return err
 	}
 
+	s.groupGeneratedConfigs(usedConfigs)
+
 	resourcedEntities := []k8s.K8sEntity{}
-	for _, r := range s.k8sByName {
+	for _, r := range s.k8s {
 		resourcedEntities = append(resourcedEntities, r.entities...)
 	}
 
@@ -817,6 +914,25 @@ func (s *tiltfileState) assembleK8s() error {
 			for k, v := range opts.labels {
 				r.labels[k] = v
 			}
+			if len(opts.instances) > 0 {
+				r.instances = opts.instances
+				r.instancePortOffset = opts.instancePortOffset
+			}
+			if opts.restartOn != nil {
+				r.restartOn = opts.restartOn
+			}
+			if opts.liveUpdateOverride != nil {
+				r.liveUpdateOverride = opts.liveUpdateOverride
+			}
+			if len(opts.logContainers) > 0 {
+				r.logContainers = opts.logContainers
+			}
+			if len(opts.ignoreLogContainers) > 0 {
+				r.ignoreLogContainers = opts.ignoreLogContainers
+			}
+			if len(opts.imageContainers) > 0 {
+				r.imageContainers = opts.imageContainers
+			}
 			if opts.newName != "" && opts.newName != r.name {
 				err := s.checkResourceConflict(opts.newName)
 				if err != nil {
@@ -868,14 +984,24 @@ func (s *tiltfileState) assembleK8s() error {
 
 		} else {
 			var knownResources []string
-			for name := range s.k8sByName {
-				knownResources = append(knownResources, name)
+			for _, r := range s.k8s {
+				knownResources = append(knownResources, r.name)
 			}
 			return fmt.Errorf("%s: k8s_resource specified unknown resource %q. known k8s resources: %s",
 				opts.tiltfilePosition.String(), opts.workload, strings.Join(knownResources, ", "))
 		}
 	}
 
+	err = s.applyRestartOn()
+	if err != nil {
+		return err
+	}
+
+	err = s.expandK8sInstances()
+	if err != nil {
+		return err
+	}
+
 	for _, r := range s.k8s {
 		if err := s.validateK8s(r); err != nil {
 			return err
@@ -1037,15 +1163,41 @@ func (s *tiltfileState) validateK8s(r *k8sResource) error {
 		return fmt.Errorf("resource %q: could not associate any k8s_yaml() or k8s_custom_deploy() with this resource", r.name)
 	}
 
+	err := r.addImageRefPlaceholderDeps()
+	if err != nil {
+		return fmt.Errorf("resource %q: %v", r.name, err)
+	}
+
+	// If image_containers scopes injection, an image only used by other
+	// containers (e.g., a sidecar that runs the same image) isn't a dep.
+	var candidates map[string]bool
+	if len(r.imageContainers) > 0 {
+		candidates = make(map[string]bool)
+		for _, e := range r.entities {
+			images, err := e.FindImagesInContainers(r.imageContainers, s.k8sImageLocatorsList(), s.envVarImages())
+			if err != nil {
+				return fmt.Errorf("resource %q: %v", r.name, err)
+			}
+			for _, image := range images {
+				candidates[image.String()] = true
+			}
+		}
+	}
+
 	for _, ref := range r.imageRefs {
+		metadata, ok := r.imageDepsMetadata[ref.String()]
+		required := ok && metadata.required
+		if candidates != nil && !candidates[ref.String()] && !required {
+			continue
+		}
+
 		builder := s.buildIndex.findBuilderForConsumedImage(ref)
 		if builder != nil {
 			r.imageMapDeps = append(r.imageMapDeps, builder.ImageMapName())
 			continue
 		}
 
-		metadata, ok := r.imageDepsMetadata[ref.String()]
-		if ok && metadata.required {
+		if required {
 			return fmt.Errorf("resource %q: image build %q not found", r.name, container.FamiliarString(ref))
 		}
 	}
@@ -1136,11 +1288,16 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings mo
 
 		m = m.WithLabels(r.labels)
 
-		iTargets, err := s.imgTargetsForDeps(mn, r.imageMapDeps)
+		iTargets, err := s.imgTargetsForDeps(mn, r.imageMapDeps, updateSettings)
 		if err != nil {
 			return nil, errors.Wrapf(err, "getting image build info for %s", r.name)
 		}
 
+		iTargets, err = applyLiveUpdateOverride(mn, r, iTargets)
+		if err != nil {
+			return nil, err
+		}
+
 		for i, iTarget := range iTargets {
 			if liveupdate.IsEmptySpec(iTarget.LiveUpdateSpec) {
 				continue
@@ -1150,6 +1307,7 @@ func (s *tiltfileState) translateK8s(resources []*k8sResource, updateSettings mo
 		}
 
 		m = m.WithImageTargets(iTargets)
+		s.warnOnShadowedVolumePaths(mn, r.entities, iTargets)
 
 		k8sTarget, err := s.k8sDeployTarget(mn.TargetName(), r, iTargets, updateSettings)
 		if err != nil {
@@ -1176,6 +1334,12 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 		}
 	}
 
+	ignoreContainers := make([]string, 0, len(container.DefaultIgnoredLogContainers)+len(r.ignoreLogContainers))
+	for _, name := range container.DefaultIgnoredLogContainers {
+		ignoreContainers = append(ignoreContainers, string(name))
+	}
+	ignoreContainers = append(ignoreContainers, r.ignoreLogContainers...)
+
 	sinceTime := apis.NewTime(pkgInitTime)
 	applySpec := v1alpha1.KubernetesApplySpec{
 		Cluster:                         v1alpha1.ClusterNameDefault,
@@ -1184,14 +1348,11 @@ func (s *tiltfileState) k8sDeployTarget(targetName model.TargetName, r *k8sResou
 		DiscoveryStrategy:               r.discoveryStrategy,
 		KubernetesDiscoveryTemplateSpec: kdTemplateSpec,
 		PodLogStreamTemplateSpec: &v1alpha1.PodLogStreamTemplateSpec{
-			SinceTime: &sinceTime,
-			IgnoreContainers: []string{
-				string(container.IstioInitContainerName),
-				string(container.IstioSidecarContainerName),
-				string(container.LinkerdSidecarContainerName),
-				string(container.LinkerdInitContainerName),
-			},
+			SinceTime:        &sinceTime,
+			OnlyContainers:   r.logContainers,
+			IgnoreContainers: ignoreContainers,
 		},
+		ImageContainers: r.imageContainers,
 	}
 
 	var deps []string
@@ -1291,6 +1452,9 @@ func (s *tiltfileState) validateLiveUpdatesForManifest(m model.Manifest) error {
 
 		err = s.validateLiveUpdate(iTarg, g)
 		if err != nil {
+			if iTarg.LiveUpdateSource != "" {
+				return errors.Wrapf(err, "%s: live_update_override", iTarg.LiveUpdateSource)
+			}
 			return err
 		}
 	}
@@ -1391,12 +1555,12 @@ func needsRestartContainerDeprecationError(m model.Manifest) bool {
 
 // Grabs all image targets for the given references,
 // as well as any of their transitive dependencies.
-func (s *tiltfileState) imgTargetsForDeps(mn model.ManifestName, imageMapDeps []string) ([]model.ImageTarget, error) {
+func (s *tiltfileState) imgTargetsForDeps(mn model.ManifestName, imageMapDeps []string, updateSettings model.UpdateSettings) ([]model.ImageTarget, error) {
 	claimStatus := make(map[string]claim, len(imageMapDeps))
-	return s.imgTargetsForDepsHelper(mn, imageMapDeps, claimStatus)
+	return s.imgTargetsForDepsHelper(mn, imageMapDeps, updateSettings, claimStatus)
 }
 
-func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapDeps []string, claimStatus map[string]claim) ([]model.ImageTarget, error) {
+func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapDeps []string, updateSettings model.UpdateSettings, claimStatus map[string]claim) ([]model.ImageTarget, error) {
 	iTargets := make([]model.ImageTarget, 0, len(imageMapDeps))
 	for _, imName := range imageMapDeps {
 		image := s.buildIndex.findBuilderByImageMapName(imName)
@@ -1429,6 +1593,10 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 				OverrideArgs:    image.overrideArgs,
 			},
 			LiveUpdateSpec: image.liveUpdate,
+			BuildTimeout:   updateSettings.BuildTimeout(),
+		}
+		if image.buildTimeout != nil {
+			iTarget.BuildTimeout = *image.buildTimeout
 		}
 		if !liveupdate.IsEmptySpec(image.liveUpdate) {
 			iTarget.LiveUpdateName = liveupdate.GetName(mn, iTarget.ID())
@@ -1447,6 +1615,7 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 				DockerfileContents: image.dbDockerfile.String(),
 				Context:            image.dbBuildPath,
 				Args:               image.dbBuildArgs,
+				DynamicArgs:        image.dbDynamicArgs,
 				Target:             image.targetStage,
 				SSHAgentConfigs:    image.sshSpecs,
 				Secrets:            image.secretSpecs,
@@ -1458,7 +1627,21 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 				ContextIgnores:     contextIgnores,
 				ExtraHosts:         image.extraHosts,
 			}
-			iTarget = iTarget.WithBuildDetails(model.DockerBuild{DockerImageSpec: spec})
+			if image.goDeps != nil {
+				spec.GoDeps = &v1alpha1.DockerImageGoDeps{
+					Main:        image.goDeps.Main,
+					Packages:    int32(len(image.goDeps.PackageDirs)),
+					RefreshedAt: metav1.NewTime(image.goDeps.RefreshedAt),
+				}
+			}
+			for _, dep := range image.baseImageDeps {
+				spec.BaseImages = append(spec.BaseImages, dep.spec())
+			}
+			db := model.DockerBuild{
+				DockerImageSpec: spec,
+				PublishPath:     image.publishPath,
+			}
+			iTarget = iTarget.WithBuildDetails(db)
 		case CustomBuild:
 			iTarget.CmdImageName = cmdimage.GetName(mn, iTarget.ID())
 
@@ -1493,7 +1676,7 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 		iTarget = iTarget.WithImageMapDeps(image.imageMapDeps).
 			WithFileWatchIgnores(fileWatchIgnores)
 
-		depTargets, err := s.imgTargetsForDepsHelper(mn, image.imageMapDeps, claimStatus)
+		depTargets, err := s.imgTargetsForDepsHelper(mn, image.imageMapDeps, updateSettings, claimStatus)
 		if err != nil {
 			return nil, err
 		}
@@ -1506,12 +1689,12 @@ func (s *tiltfileState) imgTargetsForDepsHelper(mn model.ManifestName, imageMapD
 	return iTargets, nil
 }
 
-func (s *tiltfileState) translateDC(dc *dcResourceSet) ([]model.Manifest, error) {
+func (s *tiltfileState) translateDC(dc *dcResourceSet, updateSettings model.UpdateSettings) ([]model.Manifest, error) {
 	var result []model.Manifest
 
 	for _, name := range dc.serviceNames {
 		svc := dc.services[name]
-		iTargets, err := s.imgTargetsForDeps(model.ManifestName(svc.Name), svc.ImageMapDeps)
+		iTargets, err := s.imgTargetsForDeps(model.ManifestName(svc.Name), svc.ImageMapDeps, updateSettings)
 		if err != nil {
 			return nil, errors.Wrapf(err, "getting image build info for %s", svc.Name)
 		}
@@ -1601,12 +1784,29 @@ func (s *tiltfileState) translateLocal() ([]model.Manifest, error) {
 			})
 		}
 
-		lt := model.NewLocalTarget(model.TargetName(r.name), r.updateCmd, r.serveCmd, r.deps).
+		updateCmd, err := s.withResourceEnv(r.updateCmd, r.updateEnvRefs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "local_resource %s: env", mn)
+		}
+		serveCmd, err := s.withResourceEnv(r.serveCmd, r.serveEnvRefs)
+		if err != nil {
+			return nil, errors.Wrapf(err, "local_resource %s: serve_env", mn)
+		}
+
+		lt := model.NewLocalTarget(model.TargetName(r.name), updateCmd, serveCmd, r.deps).
 			WithAllowParallel(r.allowParallel || r.updateCmd.Empty()).
 			WithLinks(r.links).
 			WithReadinessProbe(r.readinessProbe)
 		lt.FileWatchIgnores = ignores
 
+		if len(r.resourceDepsOnUpdate) > 0 {
+			var runOnUpdateOf []model.ManifestName
+			for _, dep := range r.resourceDepsOnUpdate {
+				runOnUpdateOf = append(runOnUpdateOf, model.ManifestName(dep))
+			}
+			lt = lt.WithRunOnUpdateOf(runOnUpdateOf, r.degradeOnFailure)
+		}
+
 		var mds []model.ManifestName
 		for _, md := range r.resourceDeps {
 			mds = append(mds, model.ManifestName(md))
@@ -1652,8 +1852,19 @@ func (s *tiltfileState) sanitizeDependencies(ms []model.Manifest) error {
 	// construct the graph and make sure all edges are valid
 	edges := make(map[interface{}][]interface{})
 	for i, m := range ms {
-		var sanitizedDeps []model.ManifestName
+		var deps []model.ManifestName
 		for _, b := range m.ResourceDependencies {
+			if instances, ok := s.k8sInstances[string(b)]; ok {
+				for _, inst := range instances {
+					deps = append(deps, model.ManifestName(inst))
+				}
+				continue
+			}
+			deps = append(deps, b)
+		}
+
+		var sanitizedDeps []model.ManifestName
+		for _, b := range deps {
 			if m.Name == b {
 				return fmt.Errorf("resource %s specified a dependency on itself", m.Name)
 			}
@@ -1706,6 +1917,7 @@ func (s *tiltfileState) ignoresForImage(image *dockerImage) (contextIgnores []v1
 	}
 	contextIgnores = append(contextIgnores, s.repoIgnoresForImage(image)...)
 	contextIgnores = append(contextIgnores, model.DockerignoresToIgnores(dockerignores)...)
+	contextIgnores = append(contextIgnores, model.DockerignoresToIgnores(s.gitignoresForPaths(repoPathsForImage(image)))...)
 
 	for i := range contextIgnores {
 		fileWatchIgnores = append(fileWatchIgnores, *contextIgnores[i].DeepCopy())
@@ -1726,6 +1938,26 @@ func (s *tiltfileState) ignoresForImage(image *dockerImage) (contextIgnores []v1
 		fileWatchIgnores = append(fileWatchIgnores, v1alpha1.IgnoreDef{BasePath: image.dbDockerfilePath})
 	}
 
+	// Writing a published image shouldn't trigger a build, or put it in
+	// the build context.
+	for _, img := range s.buildIndex.images {
+		if img.publishPath != "" {
+			for _, p := range []string{img.publishPath, img.publishPath + ".tmp"} {
+				contextIgnores = append(contextIgnores, v1alpha1.IgnoreDef{BasePath: p})
+				fileWatchIgnores = append(fileWatchIgnores, v1alpha1.IgnoreDef{BasePath: p})
+			}
+		}
+	}
+
+	if image.goDeps != nil {
+		// Only watch the Go files that the binary imports. The build
+		// context itself is unchanged.
+		fileWatchIgnores = append(fileWatchIgnores, v1alpha1.IgnoreDef{
+			BasePath: image.dbBuildPath,
+			Patterns: image.goDeps.IgnorePatterns(),
+		})
+	}
+
 	if image.Type() == DockerComposeBuild {
 		// Docker Compose local volumes are mounted into the running container,
 		// so we don't want to watch these paths, as that'd trigger rebuilds
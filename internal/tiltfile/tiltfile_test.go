@@ -27,6 +27,7 @@ import (
 	ctrltiltfile "github.com/tilt-dev/tilt/internal/controllers/apis/tiltfile"
 	"github.com/tilt-dev/tilt/internal/docker"
 	"github.com/tilt-dev/tilt/internal/dockercompose"
+	"github.com/tilt-dev/tilt/internal/dockerignore"
 	"github.com/tilt-dev/tilt/internal/feature"
 	"github.com/tilt-dev/tilt/internal/ignore"
 	"github.com/tilt-dev/tilt/internal/k8s"
@@ -41,9 +42,11 @@ import (
 	"github.com/tilt-dev/tilt/internal/tiltfile/hasher"
 	tiltfile_k8s "github.com/tilt-dev/tilt/internal/tiltfile/k8s"
 	"github.com/tilt-dev/tilt/internal/tiltfile/k8scontext"
+	"github.com/tilt-dev/tilt/internal/tiltfile/sandbox"
 	"github.com/tilt-dev/tilt/internal/tiltfile/testdata"
 	"github.com/tilt-dev/tilt/internal/tiltfile/tiltextension"
 	"github.com/tilt-dev/tilt/internal/tiltfile/version"
+	"github.com/tilt-dev/tilt/internal/xdg"
 	"github.com/tilt-dev/tilt/internal/yaml"
 	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 	"github.com/tilt-dev/tilt/pkg/logger"
@@ -205,6 +208,37 @@ k8s_yaml('foo.yaml')
 	f.assertNextManifest("foo", db(image("gcr.io/foo")))
 }
 
+func TestDockerfileInclude(t *testing.T) {
+	f := newFixture(t)
+	f.setupFoo()
+	f.file("dockerfiles/base-deps.inc", "RUN apk add curl\n")
+	f.file("foo/Dockerfile", `FROM alpine
+# tilt:include ../dockerfiles/base-deps.inc
+COPY . /app
+`)
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.load()
+	f.assertConfigFiles("Tiltfile", ".tiltignore", "foo.yaml", "foo/Dockerfile", "dockerfiles/base-deps.inc", "foo/.dockerignore")
+
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, "FROM alpine\nRUN apk add curl\nCOPY . /app\n",
+		m.ImageTargets[0].DockerBuildInfo().DockerfileContents)
+}
+
+func TestDockerfileIncludeMissing(t *testing.T) {
+	f := newFixture(t)
+	f.setupFoo()
+	f.file("foo/Dockerfile", "FROM alpine\n# tilt:include base.inc\n")
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+`)
+	f.loadErrString("docker_build: ", "foo/Dockerfile:2: including base.inc: open ", "base.inc: no such file or directory")
+}
+
 func TestCantSpecifyDFPathAndContents(t *testing.T) {
 	f := newFixture(t)
 	f.setupFoo()
@@ -699,6 +733,71 @@ docker_build("gcr.io/foo", "foo", pull=True)
 	assert.True(t, m.ImageTargets[0].BuildDetails.(model.DockerBuild).Pull)
 }
 
+func TestDockerBuildTimeout(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_timeout_secs=600)
+`)
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, 10*time.Minute, m.ImageTargets[0].BuildTimeout)
+}
+
+func TestDockerBuildTimeoutDefault(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo")
+`)
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, model.DefaultBuildTimeout, m.ImageTargets[0].BuildTimeout)
+}
+
+func TestDockerBuildTimeoutOverridesUpdateSettings(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+update_settings(build_timeout_secs=60)
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_timeout_secs=0)
+`)
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, time.Duration(0), m.ImageTargets[0].BuildTimeout)
+}
+
+func TestDockerBuildTimeoutNegative(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+docker_build("gcr.io/foo", "foo", build_timeout_secs=-1)
+`)
+	f.loadErrString("Argument 'build_timeout_secs' must be >= 0")
+}
+
+func TestCustomBuildTimeout(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+update_settings(build_timeout_secs=60)
+k8s_yaml('foo.yaml')
+custom_build('gcr.io/foo', 'docker build -t $EXPECTED_REF foo', ['./foo'], build_timeout_secs=120)
+`)
+	f.load()
+	m := f.assertNextManifest("foo")
+	assert.Equal(t, 2*time.Minute, m.ImageTargets[0].BuildTimeout)
+}
+
 func TestDockerBuildCacheFrom(t *testing.T) {
 	f := newFixture(t)
 
@@ -1485,6 +1584,82 @@ docker_build("gcr.io/foo", "foo", cache='/paths/to/cache')
 	}
 }
 
+func TestK8sYAMLUnknownFields(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  replica: 2
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: foo
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: skipped
+  annotations:
+    tilt.dev/skip-schema-validation: "true"
+extra: true
+`)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_yaml(blob("""apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: bar
+datta:
+  key: value"""))
+`)
+
+	f.loadAssertWarnings(
+		fmt.Sprintf(`%s: Deployment "foo": unknown field "spec.replica"`, f.JoinPath("foo.yaml")),
+		`Tiltfile blob() call: ConfigMap "bar": unknown field "datta"`,
+		`To skip schema validation of an object, set the annotation tilt.dev/skip-schema-validation: "true"`,
+		`To skip schema validation of an object, set the annotation tilt.dev/skip-schema-validation: "true"`)
+}
+
+func TestK8sYAMLTypeMismatch(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo.yaml", `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  replica: 2
+  replicas: "two"
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: foo
+`)
+	f.file("Tiltfile", `k8s_yaml('foo.yaml')`)
+
+	f.loadErrString("cannot unmarshal string")
+	out := f.out.String()
+	assert.Contains(t, out, `Deployment "foo": unknown field "spec.replica"`)
+	assert.Contains(t, out, `Deployment "foo": invalid type for "spec.replicas": got string, expected integer`)
+}
+
 func TestK8sYAMLInvalid(t *testing.T) {
 	f := newFixture(t)
 
@@ -1788,6 +1963,104 @@ k8s_yaml('foo.yaml')
 	)
 }
 
+func TestK8sYAMLRenderingIsDeterministic(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("k8s/b.yaml", `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: prod
+data:
+  b: "2"
+  a: "1"
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gear
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: dev
+data:
+  a: "1"
+`)
+	f.file("k8s/a.yaml", `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: flags
+  namespace: dev
+---
+apiVersion: example.com/v1
+kind: Gadget
+metadata:
+  name: sprocket
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: dev
+`)
+	f.file("Tiltfile", `
+for path in listdir('k8s'):
+  k8s_yaml(path)
+k8s_resource(new_name='config', objects=['settings:configmap:prod', 'flags', 'settings:configmap:dev', 'dev:namespace'])
+k8s_resource(new_name='custom', objects=['sprocket', 'gear', 'web'])
+`)
+
+	render := func() string {
+		var b strings.Builder
+		for _, m := range f.loadResult.Manifests {
+			b.WriteString(fmt.Sprintf("# %s\n%s\n", m.Name, m.K8sTarget().YAML))
+		}
+		return b.String()
+	}
+
+	f.load()
+	expected := render()
+	for i := 1; i < 50; i++ {
+		f.load()
+		require.Equal(t, expected, render(), "load %d", i)
+	}
+
+	// Namespaces go first, then the objects keep the order they were
+	// declared in, and keys are sorted.
+	config := f.assertNextManifest("config")
+	entities, err := k8s.ParseYAMLFromString(config.K8sTarget().YAML)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entities {
+		names = append(names, fmt.Sprintf("%s:%s:%s", e.GVK().Kind, e.Namespace(), e.Name()))
+	}
+	assert.Equal(t, []string{
+		"Namespace:default:dev",
+		"ConfigMap:prod:settings",
+		"ConfigMap:dev:flags",
+		"ConfigMap:dev:settings",
+	}, names)
+	assert.Contains(t, config.K8sTarget().YAML, "data:\n  a: \"1\"\n  b: \"2\"\n")
+
+	custom := f.assertNextManifest("custom")
+	entities, err = k8s.ParseYAMLFromString(custom.K8sTarget().YAML)
+	require.NoError(t, err)
+	names = nil
+	for _, e := range entities {
+		names = append(names, fmt.Sprintf("%s:%s", e.GVK().Kind, e.Name()))
+	}
+	assert.Equal(t, []string{"Service:web", "Gadget:sprocket", "Widget:gear"}, names)
+}
+
 func TestPodReadinessDefaultConfigMap(t *testing.T) {
 	f := newFixture(t)
 
@@ -1809,6 +2082,73 @@ k8s_resource(new_name='config', objects=['config'])
 	)
 }
 
+func TestImageRef(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile("api/Dockerfile")
+	f.file("Tiltfile", `
+docker_build('gcr.io/acme/api', 'api')
+k8s_yaml(blob("""apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  api-image: %s
+""" % image_ref('gcr.io/acme/api')))
+k8s_resource(new_name='config', objects=['config'])
+`)
+
+	f.load("config")
+	m := f.assertNextManifest("config", db(image("gcr.io/acme/api")))
+	assert.Contains(t, m.K8sTarget().YAML, "api-image: tilt-image-ref[gcr.io/acme/api]")
+	assert.Equal(t, []string{"gcr.io_acme_api"}, m.K8sTarget().ImageMaps)
+}
+
+func TestImageRefMissingBuild(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+k8s_yaml(blob("""apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  api-image: %s
+""" % image_ref('gcr.io/acme/api')))
+k8s_resource(new_name='config', objects=['config'])
+`)
+
+	f.loadErrString(`resource "config": image build "gcr.io/acme/api" not found`)
+}
+
+func TestImageRefUnresourced(t *testing.T) {
+	f := newFixture(t)
+
+	f.dockerfile("api/Dockerfile")
+	f.file("Tiltfile", `
+docker_build('gcr.io/acme/api', 'api')
+k8s_yaml(blob("""apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  api-image: %s
+""" % image_ref('gcr.io/acme/api')))
+`)
+
+	f.loadErrString(`config:ConfigMap:default uses image_ref("gcr.io/acme/api"), but isn't part of a resource`)
+}
+
+func TestImageRefInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+image_ref('gcr.io/Acme/API')
+`)
+
+	f.loadErrString(`image_ref: Argument 1 (ref): can't parse "gcr.io/Acme/API"`)
+}
+
 func TestPodReadinessDefaultJob(t *testing.T) {
 	f := newFixture(t)
 
@@ -1852,7 +2192,25 @@ k8s_yaml('foo.yaml')
 k8s_resource('foo', discovery_strategy='typo')
 `)
 
-	f.loadErrString("Invalid. Must be one of: \"default\", \"selectors-only\"")
+	f.loadErrString("Invalid. Must be one of: \"default\", \"selectors-only\", \"selectors-and-owners\"")
+}
+
+func TestK8sDiscoveryStrategySelectorsAndOwners(t *testing.T) {
+	f := newFixture(t)
+
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo:stable")))
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', extra_pod_selectors=[{'workflows.argoproj.io/workflow': 'dev-pipeline'}],
+             discovery_strategy='selectors-and-owners')
+`)
+
+	f.load("foo")
+	f.assertNextManifest("foo",
+		deployment("foo"),
+		extraPodSelectors(labels.Set{"workflows.argoproj.io/workflow": "dev-pipeline"}),
+		v1alpha1.KubernetesDiscoveryStrategySelectorsAndOwners,
+	)
 }
 
 func TestPodReadinessOverrideDeployment(t *testing.T) {
@@ -3064,6 +3422,44 @@ default_registry('123.dkr.ecr.us-east-1.amazonaws.com', single_name='team-a/dev'
 		beTaggedRefs.LocalRef.String())
 }
 
+func TestDefaultRegistryPullSecret(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+default_registry('gcr.io/myrepo', create_pull_secret_from='docker-config', pull_secret_target='serviceaccount')
+k8s_yaml('foo.yaml')
+docker_build('gcr.io/foo', 'foo')
+`)
+
+	f.load()
+
+	assert.Equal(t, &k8s.PullSecretSettings{
+		From:   k8s.PullSecretFromDockerConfig,
+		Target: k8s.PullSecretTargetServiceAccount,
+	}, f.loadResult.DefaultRegistryPullSecret)
+}
+
+func TestDefaultRegistryPullSecretInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+default_registry('gcr.io/myrepo', create_pull_secret_from='keychain')
+`)
+
+	f.loadErrString(`create_pull_secret_from must be "docker-config", got "keychain"`)
+}
+
+func TestDefaultRegistryPullSecretTargetWithoutSource(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+default_registry('gcr.io/myrepo', pull_secret_target='pod')
+`)
+
+	f.loadErrString("pull_secret_target requires create_pull_secret_from")
+}
+
 func TestDefaultReadFile(t *testing.T) {
 	f := newFixture(t)
 	f.setupFooAndBar()
@@ -4029,6 +4425,50 @@ k8s_yaml('foo.yaml')
 		m.ImageTargets[0].DockerBuildInfo().Args)
 }
 
+func TestDockerBuild_dynamicBuildArgs(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', build_args={
+  'VERSION': arg_from_cmd('git describe --tags'),
+  'NPMRC': arg_from_file('.npmrc.ci'),
+  'STATIC': 'hello',
+})
+k8s_yaml('foo.yaml')
+`)
+
+	f.load("foo")
+
+	m := f.assertNextManifest("foo")
+	iTarget := m.ImageTargets[0]
+	db := iTarget.DockerBuildInfo()
+	assert.Equal(t, []string{"STATIC=hello"}, db.Args)
+	assert.Equal(t, []v1alpha1.DockerImageDynamicArg{
+		{Name: "NPMRC", Path: f.JoinPath(".npmrc.ci")},
+		{
+			Name:    "VERSION",
+			Command: model.ToHostCmd("git describe --tags").Argv,
+			Dir:     f.Path(),
+		},
+	}, db.DynamicArgs)
+	assert.Contains(t, iTarget.Dependencies(), f.JoinPath(".npmrc.ci"))
+}
+
+func TestDockerBuild_buildArgsBadValue(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo', build_args={'VERSION': 3})
+k8s_yaml('foo.yaml')
+`)
+
+	f.loadErrString(`value for "VERSION" must be a string, arg_from_cmd(), or arg_from_file(), got int`)
+}
+
 func TestCustomBuildEntrypoint(t *testing.T) {
 	f := newFixture(t)
 
@@ -4470,7 +4910,119 @@ local_resource("test", "echo hi", serve_cmd="sleep 1000", serve_env={"KEY1": "va
 	))
 }
 
-func TestLocalResourceUpdateCmdDir(t *testing.T) {
+func TestSandboxDenyListsBlockedCommands(t *testing.T) {
+	f := newFixture(t)
+	f.sandbox = sandbox.NewSandbox(sandbox.Config{Mode: sandbox.ModeDeny},
+		xdg.FakeBase{Dir: f.JoinPath(".data")}, nil)
+
+	f.file("Tiltfile", `
+version = str(local('git describe --tags')).strip()
+local('echo ' + version + ' > version.txt')
+local_resource('web', serve_cmd='npm start')
+`)
+
+	f.loadErrString(`--sandbox-local-commands=deny blocked 2 command(s) that the Tiltfile would have run:
+  local: git describe --tags (in ` + f.Path() + `)
+  local: echo  > version.txt (in ` + f.Path() + `)`)
+	assert.NoFileExists(t, f.JoinPath("version.txt"))
+}
+
+func TestSandboxAllowRecordsCommands(t *testing.T) {
+	f := newFixture(t)
+	base := xdg.FakeBase{Dir: f.JoinPath(".data")}
+	f.sandbox = sandbox.NewSandbox(sandbox.Config{Mode: sandbox.ModeAllow}, base, nil)
+
+	f.file("Tiltfile", `
+local('echo hi')
+local_resource('web', serve_cmd='npm start')
+`)
+	f.load()
+
+	p, err := base.DataFile("local-commands.log")
+	require.NoError(t, err)
+	log, err := os.ReadFile(p)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(log), "\n"))
+	assert.Contains(t, string(log), `"source":"local","tiltfile":"`+f.JoinPath("Tiltfile")+`"`)
+	assert.Contains(t, string(log), `"command":"echo hi"`)
+}
+
+func TestSandboxIncludeLocalResources(t *testing.T) {
+	f := newFixture(t)
+	f.sandbox = sandbox.NewSandbox(sandbox.Config{Mode: sandbox.ModeDeny, IncludeLocalResources: true},
+		xdg.FakeBase{Dir: f.JoinPath(".data")}, nil)
+
+	f.file("Tiltfile", `
+local_resource('web', cmd='npm install', serve_cmd='npm start')
+`)
+
+	f.loadErrString("blocked 2 command(s)",
+		"local_resource(web).cmd: npm install",
+		"local_resource(web).serve_cmd: npm start")
+}
+
+func TestLocalResourceEnvFromResource(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', port_forwards=port_forward(8000, 80, link_path='/api'))
+local_resource('docs', serve_cmd='mkdocs serve', links=['http://localhost:8001/docs'])
+local_resource("web", serve_cmd="npm start", serve_env={
+  "API_URL": from_resource("foo", "port_forward_url"),
+  "API_PORT": from_resource("foo", "port_forward_port"),
+  "DOCS_URL": from_resource("docs", "link_url"),
+  "NODE_ENV": "development",
+})
+`)
+
+	f.load()
+	f.assertNextManifest("foo")
+	f.assertNextManifest("docs")
+	f.assertNextManifest("web",
+		localTarget(serveCmd(f.Path(), "npm start", []string{
+			"API_PORT=8000",
+			"API_URL=http://localhost:8000/api",
+			"DOCS_URL=http://localhost:8001/docs",
+			"NODE_ENV=development",
+		})),
+		resourceDeps("docs", "foo"))
+}
+
+func TestLocalResourceEnvFromResourceErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		tiltfile string
+		expected string
+	}{
+		{
+			name:     "unknown key",
+			tiltfile: `local_resource("web", serve_cmd="npm start", serve_env={"X": from_resource("api", "url")})`,
+			expected: `from_resource: unknown key "url". Valid keys are: port_forward_url, port_forward_port, link_url`,
+		},
+		{
+			name:     "unknown resource",
+			tiltfile: `local_resource("web", serve_cmd="npm start", serve_env={"X": from_resource("api", "link_url")})`,
+			expected: `local_resource web: serve_env: from_resource("api", "link_url"): no k8s_resource or local_resource named "api"`,
+		},
+		{
+			name: "no port forwards",
+			tiltfile: `
+local_resource("api", serve_cmd="./api")
+local_resource("web", serve_cmd="npm start", serve_env={"X": from_resource("api", "port_forward_url")})`,
+			expected: `from_resource("api", "port_forward_url"): "api" has no port_forwards`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.file("Tiltfile", tc.tiltfile)
+			f.loadErrString(tc.expected)
+		})
+	}
+}
+
+func TestLocalResourceUpdateCmdDir(t *testing.T) {
 	f := newFixture(t)
 
 	f.file("nested/inside.txt", "inside the nested directory")
@@ -4713,6 +5265,50 @@ local_resource('bar', 'echo bar', resource_deps=['foo'])
 	f.assertNextManifest("bar", resourceDeps("foo"))
 }
 
+func TestLocalResourceDepsOnUpdate(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('api', 'echo api')
+local_resource('api-tests', 'go test ./...', resource_deps_on_update=['api'], degrade_on_failure=True)
+local_resource('lint', 'make lint', resource_deps_on_update=['api'])
+`)
+
+	f.load()
+	f.assertNextManifest("api", resourceDeps())
+
+	m := f.assertNextManifest("api-tests", resourceDeps("api"))
+	lt := m.LocalTarget()
+	assert.Equal(t, []model.ManifestName{"api"}, lt.RunOnUpdateOf)
+	assert.True(t, lt.DegradeOnFailure)
+
+	m = f.assertNextManifest("lint", resourceDeps("api"))
+	lt = m.LocalTarget()
+	assert.Equal(t, []model.ManifestName{"api"}, lt.RunOnUpdateOf)
+	assert.False(t, lt.DegradeOnFailure)
+}
+
+func TestLocalResourceDepsOnUpdateNoCmd(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('api', 'echo api')
+local_resource('api-tests', serve_cmd='watch-tests', resource_deps_on_update=['api'])
+`)
+
+	f.loadErrString("local_resource with resource_deps_on_update must have a cmd")
+}
+
+func TestLocalResourceDegradeOnFailureWithoutDeps(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+local_resource('api-tests', 'go test ./...', degrade_on_failure=True)
+`)
+
+	f.loadErrString("local_resource with degrade_on_failure must have resource_deps_on_update")
+}
+
 func TestDependsOnMissingResource(t *testing.T) {
 	f := newFixture(t)
 
@@ -4934,6 +5530,115 @@ func TestK8sUpsertTimeout(t *testing.T) {
 	}
 }
 
+func TestK8sLiveUpdateTransport(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		tiltfile            string
+		expectErrorContains string
+		expected            model.LiveUpdateTransport
+	}{
+		{
+			name:     "default value if func not called",
+			tiltfile: "print('hello world')",
+			expected: model.LiveUpdateTransportAuto,
+		},
+		{
+			name:     "set agent",
+			tiltfile: "update_settings(k8s_live_update_transport='agent')",
+			expected: model.LiveUpdateTransportAgent,
+		},
+		{
+			name:                "invalid value",
+			tiltfile:            "update_settings(k8s_live_update_transport='ssh')",
+			expectErrorContains: `for parameter "k8s_live_update_transport": must be one of [auto exec agent] (got: "ssh")`,
+		},
+		{
+			name:     "set for this context",
+			tiltfile: "update_settings(k8s_live_update_transport={'fake-context': 'agent', 'other': 'exec'})",
+			expected: model.LiveUpdateTransportAgent,
+		},
+		{
+			name: "set for other context",
+			tiltfile: `update_settings(k8s_live_update_transport='exec')
+update_settings(k8s_live_update_transport={'other': 'agent'})`,
+			expected: model.LiveUpdateTransportExec,
+		},
+		{
+			name:                "invalid value for context",
+			tiltfile:            "update_settings(k8s_live_update_transport={'other': 'ssh'})",
+			expectErrorContains: `for parameter "k8s_live_update_transport": for context "other": must be one of [auto exec agent] (got: "ssh")`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			f.file("Tiltfile", tc.tiltfile)
+
+			if tc.expectErrorContains != "" {
+				f.loadErrString(tc.expectErrorContains)
+				return
+			}
+
+			f.load()
+			assert.Equal(t, tc.expected, f.loadResult.UpdateSettings.LiveUpdateTransportFor("fake-context"))
+		})
+	}
+}
+
+func TestUpdateSettingsTimeouts(t *testing.T) {
+	for _, tc := range []struct {
+		name                      string
+		tiltfile                  string
+		expectErrorContains       string
+		expectedBuildTimeout      time.Duration
+		expectedLiveUpdateTimeout time.Duration
+	}{
+		{
+			name:                      "default values if func not called",
+			tiltfile:                  "print('hello world')",
+			expectedBuildTimeout:      model.DefaultBuildTimeout,
+			expectedLiveUpdateTimeout: model.DefaultLiveUpdateExecTimeout,
+		},
+		{
+			name:                      "set timeouts",
+			tiltfile:                  "update_settings(build_timeout_secs=600, live_update_timeout_secs=30)",
+			expectedBuildTimeout:      10 * time.Minute,
+			expectedLiveUpdateTimeout: 30 * time.Second,
+		},
+		{
+			name:                      "0 means no timeout",
+			tiltfile:                  "update_settings(build_timeout_secs=0, live_update_timeout_secs=0)",
+			expectedBuildTimeout:      0,
+			expectedLiveUpdateTimeout: 0,
+		},
+		{
+			name:                "NaN error",
+			tiltfile:            "update_settings(build_timeout_secs='boop')",
+			expectErrorContains: "got starlark.String, want int",
+		},
+		{
+			name:                "must not be negative",
+			tiltfile:            "update_settings(live_update_timeout_secs=-1)",
+			expectErrorContains: "live update timeout must be >= 0",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFixture(t)
+
+			f.file("Tiltfile", tc.tiltfile)
+
+			if tc.expectErrorContains != "" {
+				f.loadErrString(tc.expectErrorContains)
+				return
+			}
+
+			f.load()
+			assert.Equal(t, tc.expectedBuildTimeout, f.loadResult.UpdateSettings.BuildTimeout())
+			assert.Equal(t, tc.expectedLiveUpdateTimeout, f.loadResult.UpdateSettings.LiveUpdateExecTimeout())
+		})
+	}
+}
+
 func TestUpdateSettingsCalledTwice(t *testing.T) {
 	f := newFixture(t)
 
@@ -4963,6 +5668,160 @@ print("fact: %d" % (fact(10)))
 	require.Contains(t, f.out.String(), fmt.Sprintf("fact: %d", 10*9*8*7*6*5*4*3*2*1))
 }
 
+func TestLoadedFilesAreConfigFiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+load('./lib/helpers.star', 'helper')
+load('ext://fooExt', 'printFoo')
+`)
+	f.file("lib/helpers.star", `
+load('./more.star', 'more')
+def helper():
+  more()
+`)
+	f.file("lib/more.star", `
+def more():
+  pass
+`)
+	f.file("tilt-extensions/fooExt/Tiltfile", `
+load('./util.star', 'util')
+def printFoo():
+  util()
+`)
+	f.file("tilt-extensions/fooExt/util.star", `
+def util():
+  print("foo")
+`)
+
+	f.load()
+	f.assertConfigFiles(
+		"Tiltfile",
+		".tiltignore",
+		"lib/helpers.star",
+		"lib/more.star",
+		"tilt-extensions/fooExt/Tiltfile",
+		"tilt-extensions/fooExt/util.star",
+	)
+}
+
+func TestWatchSettingsIgnoreExtensionCache(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("Tiltfile", `
+watch_settings(ignore_extension_cache=True)
+load('./lib/helpers.star', 'helper')
+load('ext://fooExt', 'printFoo')
+`)
+	f.file("lib/helpers.star", `
+def helper():
+  pass
+`)
+	f.file("tilt-extensions/fooExt/Tiltfile", `
+def printFoo():
+  print("foo")
+`)
+
+	f.load()
+	f.assertConfigFiles(
+		"Tiltfile",
+		".tiltignore",
+		"lib/helpers.star",
+	)
+}
+
+func TestWatchSettingsUseGitignore(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.file(".gitignore", "# deps\nnode_modules/\n*.log\n!keep.log\n")
+	f.file("sub/.gitignore", "/gen\n")
+	f.file("Dockerfile", "FROM golang:1.10\nCOPY . .")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+watch_settings(use_gitignore=True)
+docker_build('gcr.io/foo', '.')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load("foo")
+	f.assertNextManifest("foo",
+		buildFilters("node_modules/react/index.js"),
+		fileChangeFilters("node_modules/react/index.js"),
+		buildFilters("app.log"),
+		buildFilters("sub/app.log"),
+		buildMatches("keep.log"),
+		buildFilters("sub/gen/main.go"),
+		buildMatches("gen/main.go"),
+		buildMatches("main.go"),
+		fileChangeMatches("main.go"),
+	)
+
+	ws := f.loadResult.WatchSettings
+	require.Len(t, ws.Ignores, 1)
+	assert.Equal(t, []string{
+		"gitignore:" + f.JoinPath(".gitignore") + ":2",
+		"gitignore:" + f.JoinPath(".gitignore") + ":3",
+		"gitignore:" + f.JoinPath(".gitignore") + ":4",
+		"gitignore:" + f.JoinPath("sub", ".gitignore") + ":1",
+	}, ws.Ignores[0].PatternSources)
+	assert.Contains(t, f.loadResult.ConfigFiles, f.JoinPath(".gitignore"))
+	assert.Contains(t, f.loadResult.ConfigFiles, f.JoinPath("sub", ".gitignore"))
+}
+
+func TestWatchSettingsGitignoreOffByDefault(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.file(".gitignore", "*.log\n")
+	f.file("Dockerfile", "FROM golang:1.10")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', '.')
+k8s_yaml('foo.yaml')
+`)
+
+	f.load("foo")
+	f.assertNextManifest("foo",
+		buildMatches("app.log"),
+		fileChangeMatches("app.log"),
+	)
+	assert.Empty(t, f.loadResult.WatchSettings.Ignores)
+}
+
+func TestWatchSettingsGitignoreKeepsCopiedFiles(t *testing.T) {
+	f := newFixture(t)
+
+	f.gitInit("")
+	f.file(".gitignore", "dist\ntilt_config.json\n")
+	f.file("dist/app.js", "")
+	f.file("Dockerfile", "FROM alpine\nCOPY dist /app/dist")
+	f.yaml("foo.yaml", deployment("foo", image("gcr.io/foo")))
+	f.file("tilt_config.json", "{}")
+	f.file("Tiltfile", `
+watch_settings(use_gitignore=True)
+read_file('tilt_config.json')
+docker_build('gcr.io/foo', '.')
+k8s_yaml('foo.yaml')
+`)
+
+	f.loadAllowWarnings("foo")
+	f.assertWarnings(fmt.Sprintf(`docker_build("gcr.io/foo"): gitignore:%s:1 ignores dist, `+
+		`which the Dockerfile copies on line 2. Keeping it in the build context, and watching it for changes`,
+		f.JoinPath(".gitignore")))
+	f.assertNextManifest("foo",
+		buildMatches("dist/app.js"),
+		fileChangeMatches("dist/app.js"),
+	)
+
+	// Files the Tiltfile reads still reload it.
+	matcher, err := dockerignore.NewDockerPatternMatcher(f.Path(), f.loadResult.WatchSettings.Ignores[0].Patterns)
+	require.NoError(t, err)
+	ignored, err := matcher.Matches(f.JoinPath("tilt_config.json"))
+	require.NoError(t, err)
+	assert.False(t, ignored)
+}
+
 func TestBuiltinAnalytics(t *testing.T) {
 	f := newFixture(t)
 
@@ -5452,6 +6311,136 @@ k8s_resource('foo', objects=['baz:namespace'])
 	f.assertNoMoreManifests()
 }
 
+func TestK8sResourceInstances(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', instances=['team-a', 'team-b', 'team-c'], port_forwards=[8000, '9000:80'], instance_port_offset=10)
+local_resource('smoke', 'echo smoke', resource_deps=['foo'])
+`)
+
+	f.load()
+	f.assertNextManifest("foo-team-a",
+		db(image("gcr.io/foo")),
+		deployment("foo", namespace("team-a")),
+		[]model.PortForward{{LocalPort: 8000}, {LocalPort: 9000, ContainerPort: 80}},
+		resourceLabels("foo"))
+	f.assertNextManifest("foo-team-b",
+		db(image("gcr.io/foo")),
+		deployment("foo", namespace("team-b")),
+		[]model.PortForward{{LocalPort: 8010}, {LocalPort: 9010, ContainerPort: 80}},
+		resourceLabels("foo"))
+	f.assertNextManifest("foo-team-c",
+		db(image("gcr.io/foo")),
+		deployment("foo", namespace("team-c")),
+		[]model.PortForward{{LocalPort: 8020}, {LocalPort: 9020, ContainerPort: 80}},
+		resourceLabels("foo"))
+	f.assertNextManifest("smoke", resourceDeps("foo-team-a", "foo-team-b", "foo-team-c"))
+	f.assertNoMoreManifests()
+}
+
+func TestK8sResourceInstancesClusterScoped(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.yaml("namespace.yaml", namespace("baz"))
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+k8s_yaml(['foo.yaml', 'namespace.yaml'])
+k8s_resource('foo', objects=['baz:namespace'], instances=['team-a', 'team-b'])
+`)
+
+	f.loadErrString(`k8s_resource "foo": cannot deploy cluster-scoped Namespace "baz" to multiple namespaces`)
+}
+
+func TestK8sResourceInstancesInvalidNamespace(t *testing.T) {
+	f := newFixture(t)
+
+	f.setupFoo()
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', instances=['Team_A'])
+`)
+
+	f.loadErrString(`k8s_resource: instances: invalid namespace "Team_A"`)
+}
+
+const fooWithSidecarYAML = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: foo
+spec:
+  selector:
+    matchLabels:
+      app: foo
+  template:
+    metadata:
+      labels:
+        app: foo
+    spec:
+      containers:
+      - name: foo
+        image: gcr.io/foo
+      - name: worker
+        image: gcr.io/bar
+      - name: envoy
+        image: envoyproxy/envoy
+`
+
+func TestK8sResourceLogContainers(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo.yaml", fooWithSidecarYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', log_containers=['foo', 'work*'], ignore_log_containers=['envoy'])
+`)
+
+	f.load()
+	m := f.assertNextManifest("foo")
+	spec := m.K8sTarget().PodLogStreamTemplateSpec
+	assert.Equal(t, []string{"foo", "work*"}, spec.OnlyContainers)
+	assert.Equal(t, []string{"istio-init", "istio-proxy", "linkerd-proxy", "linkerd-init", "envoy"},
+		spec.IgnoreContainers)
+}
+
+func TestK8sResourceLogContainersInvalid(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo.yaml", fooWithSidecarYAML)
+	f.file("Tiltfile", `
+k8s_yaml('foo.yaml')
+k8s_resource('foo', log_containers=['worker-['])
+`)
+
+	f.loadErrString(`k8s_resource: log_containers: invalid container name pattern "worker-["`)
+}
+
+func TestK8sResourceImageContainers(t *testing.T) {
+	f := newFixture(t)
+
+	f.file("foo.yaml", fooWithSidecarYAML)
+	f.file("foo/Dockerfile", "FROM golang:1.10")
+	f.file("bar/Dockerfile", "FROM golang:1.10")
+	f.file("Tiltfile", `
+docker_build('gcr.io/foo', 'foo')
+docker_build('gcr.io/bar', 'bar')
+k8s_yaml('foo.yaml')
+k8s_resource('foo', image_containers=['foo'])
+`)
+
+	// gcr.io/bar is only in a container that's left out of injection.
+	f.loadAllowWarnings()
+	require.Len(t, f.warnings, 1)
+	require.Contains(t, f.warnings[0], "Image not used in any Kubernetes config")
+	m := f.assertNextManifest("foo", db(image("gcr.io/foo")))
+	assert.Equal(t, []string{"foo"}, m.K8sTarget().ImageContainers)
+	assert.Equal(t, []string{"gcr.io_foo"}, m.K8sTarget().ImageMaps)
+}
+
 // TODO(dmiller): I'm not sure if this makes sense ... cluster scoped things like namespaces _can't_ have
 // namespaces, so should we allow you to specify namespaces for them?
 // For now we just leave them as "default"
@@ -5784,10 +6773,11 @@ type fixture struct {
 	loadResult TiltfileLoadResult
 	warnings   []string
 	features   feature.Defaults
+	sandbox    *sandbox.Sandbox
 }
 
 func (f *fixture) newTiltfileLoader() TiltfileLoader {
-	dcc := dockercompose.NewDockerComposeClient(docker.LocalEnv{})
+	dcc := dockercompose.NewDockerComposeClient(docker.LocalEnv{}, localexec.EmptyEnv())
 
 	k8sContextPlugin := k8scontext.NewPlugin(f.k8sContext, f.k8sNamespace, f.k8sEnv)
 	versionPlugin := version.NewPlugin(model.TiltBuild{Version: "0.5.0"})
@@ -5799,7 +6789,7 @@ func (f *fixture) newTiltfileLoader() TiltfileLoader {
 	extPlugin := tiltextension.NewFakePlugin(extrr, extr)
 	ciSettingsPlugin := cisettings.NewPlugin(0)
 	return ProvideTiltfileLoader(f.ta, k8sContextPlugin, versionPlugin, configPlugin,
-		extPlugin, ciSettingsPlugin, dcc, f.webHost, execer, f.features, f.k8sEnv)
+		extPlugin, ciSettingsPlugin, dcc, f.webHost, execer, f.features, f.k8sEnv, f.sandbox, nil)
 }
 
 func newFixture(t *testing.T) *fixture {
@@ -29,12 +29,16 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 }
 
 func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
-	var maxParallelUpdates, k8sUpsertTimeoutSecs starlark.Value
+	var maxParallelUpdates, k8sUpsertTimeoutSecs, buildTimeoutSecs, liveUpdateTimeoutSecs starlark.Value
 	var unusedImageWarnings value.StringOrStringList
+	var liveUpdateTransport starlark.Value
 	if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 		"max_parallel_updates?", &maxParallelUpdates,
 		"k8s_upsert_timeout_secs?", &k8sUpsertTimeoutSecs,
-		"suppress_unused_image_warnings?", &unusedImageWarnings); err != nil {
+		"suppress_unused_image_warnings?", &unusedImageWarnings,
+		"k8s_live_update_transport?", &liveUpdateTransport,
+		"build_timeout_secs?", &buildTimeoutSecs,
+		"live_update_timeout_secs?", &liveUpdateTimeoutSecs); err != nil {
 		return nil, err
 	}
 
@@ -56,6 +60,27 @@ func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, a
 			k8sUpsertTimeoutSecs)
 	}
 
+	bts, btsPassed, err := valueToInt(buildTimeoutSecs)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"build_timeout_secs\"")
+	}
+	if btsPassed && bts < 0 {
+		return nil, fmt.Errorf("build timeout must be >= 0 (0 means no timeout); got %ds", bts)
+	}
+
+	luts, lutsPassed, err := valueToInt(liveUpdateTimeoutSecs)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"live_update_timeout_secs\"")
+	}
+	if lutsPassed && luts < 0 {
+		return nil, fmt.Errorf("live update timeout must be >= 0 (0 means no timeout); got %ds", luts)
+	}
+
+	transport, transportByContext, err := parseLiveUpdateTransport(liveUpdateTransport)
+	if err != nil {
+		return nil, errors.Wrap(err, "update_settings: for parameter \"k8s_live_update_transport\"")
+	}
+
 	err = starkit.SetState(thread, func(settings model.UpdateSettings) model.UpdateSettings {
 		if mpuPassed {
 			settings = settings.WithMaxParallelUpdates(mpu)
@@ -63,13 +88,77 @@ func (e *Plugin) updateSettings(thread *starlark.Thread, fn *starlark.Builtin, a
 		if kutsPassed {
 			settings = settings.WithK8sUpsertTimeout(time.Duration(kuts) * time.Second)
 		}
+		if btsPassed {
+			settings = settings.WithBuildTimeout(time.Duration(bts) * time.Second)
+		}
+		if lutsPassed {
+			settings = settings.WithLiveUpdateExecTimeout(time.Duration(luts) * time.Second)
+		}
 		settings.SuppressUnusedImageWarnings = append(settings.SuppressUnusedImageWarnings, unusedImageWarnings.Values...)
+		if transport != "" {
+			settings.LiveUpdateTransport = transport
+		}
+		if len(transportByContext) > 0 {
+			merged := make(map[string]model.LiveUpdateTransport, len(settings.LiveUpdateTransportByContext)+len(transportByContext))
+			for k, v := range settings.LiveUpdateTransportByContext {
+				merged[k] = v
+			}
+			for k, v := range transportByContext {
+				merged[k] = v
+			}
+			settings.LiveUpdateTransportByContext = merged
+		}
 		return settings
 	})
 
 	return starlark.None, err
 }
 
+// Accepts either a transport for every cluster, or a dict from kube context
+// name to transport.
+func parseLiveUpdateTransport(v starlark.Value) (model.LiveUpdateTransport, map[string]model.LiveUpdateTransport, error) {
+	switch x := v.(type) {
+	case nil, starlark.NoneType:
+		return "", nil, nil
+	case starlark.String:
+		t, err := toLiveUpdateTransport(x)
+		return t, nil, err
+	case *starlark.Dict:
+		result := make(map[string]model.LiveUpdateTransport, x.Len())
+		for _, item := range x.Items() {
+			ctx, ok := starlark.AsString(item[0])
+			if !ok {
+				return "", nil, fmt.Errorf("dict keys must be kube context names (got: %s)", item[0].Type())
+			}
+			t, err := toLiveUpdateTransport(item[1])
+			if err != nil {
+				return "", nil, fmt.Errorf("for context %q: %v", ctx, err)
+			}
+			result[ctx] = t
+		}
+		return "", result, nil
+	default:
+		return "", nil, fmt.Errorf("got %s, want string or dict", x.Type())
+	}
+}
+
+func toLiveUpdateTransport(v starlark.Value) (model.LiveUpdateTransport, error) {
+	s, ok := starlark.AsString(v)
+	if !ok || !validLiveUpdateTransport(s) {
+		return "", fmt.Errorf("must be one of %v (got: %s)", model.AllLiveUpdateTransports, v.String())
+	}
+	return model.LiveUpdateTransport(s), nil
+}
+
+func validLiveUpdateTransport(s string) bool {
+	for _, t := range model.AllLiveUpdateTransports {
+		if string(t) == s {
+			return true
+		}
+	}
+	return false
+}
+
 func valueToInt(v starlark.Value) (val int, wasPassed bool, err error) {
 	switch x := v.(type) {
 	case nil, starlark.NoneType:
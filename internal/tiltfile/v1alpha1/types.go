@@ -409,6 +409,7 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 	var extraSelectors LabelSelectorList = LabelSelectorList{t: t}
 	var portForwardTemplateSpec PortForwardTemplateSpec = PortForwardTemplateSpec{t: t}
 	var podLogStreamTemplateSpec PodLogStreamTemplateSpec = PodLogStreamTemplateSpec{t: t}
+	var discoveryStrategy string
 	var labels value.StringStringMap
 	var annotations value.StringStringMap
 	err = starkit.UnpackArgs(t, fn.Name(), args, kwargs,
@@ -420,6 +421,7 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 		"port_forward_template_spec?", &portForwardTemplateSpec,
 		"pod_log_stream_template_spec?", &podLogStreamTemplateSpec,
 		"cluster?", &obj.Spec.Cluster,
+		"discovery_strategy?", &discoveryStrategy,
 	)
 	if err != nil {
 		return nil, err
@@ -433,6 +435,7 @@ func (p Plugin) kubernetesDiscovery(t *starlark.Thread, fn *starlark.Builtin, ar
 	if podLogStreamTemplateSpec.isUnpacked {
 		obj.Spec.PodLogStreamTemplateSpec = (*v1alpha1.PodLogStreamTemplateSpec)(&podLogStreamTemplateSpec.Value)
 	}
+	obj.Spec.DiscoveryStrategy = v1alpha1.KubernetesDiscoveryStrategy(discoveryStrategy)
 	obj.ObjectMeta.Labels = labels
 	obj.ObjectMeta.Annotations = annotations
 	return p.register(t, obj)
@@ -0,0 +1,260 @@
+package watch
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tiltDockerignore "github.com/tilt-dev/dockerignore"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const GitignoreFileName = ".gitignore"
+
+// GitRepoRoot finds the root of the git repo that contains path.
+func GitRepoRoot(path string) (string, bool) {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		}
+		if filepath.Dir(dir) == dir {
+			return "", false
+		}
+	}
+}
+
+// ReadGitignores reads every .gitignore in the repo at repoRoot, and
+// translates their patterns into absolute .dockerignore patterns, so that
+// they're matched like any other ignore.
+//
+// Git's rules carry over:
+//   - A pattern without a slash matches at any depth under its .gitignore.
+//   - A pattern with a slash is relative to its .gitignore.
+//   - Patterns in deeper .gitignores come later, so they win.
+//   - A .gitignore in an ignored directory is never read.
+//   - A negation can't re-include a file whose parent directory is ignored.
+//
+// A trailing slash (directories only) is dropped, because a path that was
+// just deleted can't be checked for being a directory. Nested repos are
+// skipped; they have their own .gitignores.
+//
+// Also returns the .gitignore files it read.
+func ReadGitignores(repoRoot string) (model.Dockerignore, []string, error) {
+	r := &gitignoreReader{byDir: map[string]gitignoreMatcher{}}
+	err := filepath.WalkDir(repoRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == repoRoot {
+				return err
+			}
+			// Unreadable directories can't have .gitignores we can read.
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != repoRoot {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+			ignored, err := r.matches(path)
+			if err != nil {
+				return err
+			}
+			if ignored {
+				return filepath.SkipDir
+			}
+		}
+		return r.read(path)
+	})
+	if err != nil {
+		return model.Dockerignore{}, nil, fmt.Errorf("Reading .gitignore files in %s: %v", repoRoot, err)
+	}
+
+	return model.Dockerignore{
+		LocalPath:      repoRoot,
+		Source:         "gitignore:" + repoRoot,
+		Patterns:       r.patterns,
+		PatternSources: r.sources,
+	}, r.files, nil
+}
+
+type gitignoreReader struct {
+	files    []string
+	patterns []string
+	sources  []string
+
+	// The compiled patterns of each .gitignore read so far, by directory.
+	byDir map[string]gitignoreMatcher
+}
+
+// The patterns of one .gitignore.
+type gitignoreMatcher struct {
+	matcher *tiltDockerignore.PatternMatcher
+
+	// The negations as plain patterns, or nil if there are none.
+	negations *tiltDockerignore.PatternMatcher
+}
+
+func newGitignoreMatcher(patterns []string) (gitignoreMatcher, error) {
+	m, err := tiltDockerignore.NewPatternMatcher(patterns)
+	if err != nil {
+		return gitignoreMatcher{}, err
+	}
+	result := gitignoreMatcher{matcher: m}
+
+	var negations []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
+			negations = append(negations, p[1:])
+		}
+	}
+	if len(negations) > 0 {
+		result.negations, err = tiltDockerignore.NewPatternMatcher(negations)
+		if err != nil {
+			return gitignoreMatcher{}, err
+		}
+	}
+	return result, nil
+}
+
+// Whether the .gitignore ignores path, and whether it has a say at all.
+func (m gitignoreMatcher) decide(path string) (ignored bool, decided bool, err error) {
+	ok, err := m.matcher.Matches(path)
+	if err != nil || ok {
+		return ok, ok, err
+	}
+	if m.negations == nil {
+		return false, false, nil
+	}
+	negated, err := m.negations.Matches(path)
+	return false, negated, err
+}
+
+// Whether path is ignored. Only the .gitignores in path's ancestors can
+// match it, and deeper ones win.
+func (r *gitignoreReader) matches(path string) (bool, error) {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		if m, ok := r.byDir[dir]; ok {
+			ignored, decided, err := m.decide(path)
+			if err != nil || decided {
+				return ignored, err
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return false, nil
+		}
+	}
+}
+
+// Reads the .gitignore in dir, if there is one.
+func (r *gitignoreReader) read(dir string) error {
+	path := filepath.Join(dir, GitignoreFileName)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	r.files = append(r.files, path)
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		pattern, ok := translateGitignorePattern(dir, scanner.Text())
+		if !ok {
+			continue
+		}
+
+		if strings.HasPrefix(pattern, "!") {
+			parent := literalParent(pattern[1:])
+			if parent != dir && len(patterns) > 0 {
+				// The lines before this one may ignore a subdirectory.
+				m, err := newGitignoreMatcher(patterns)
+				if err != nil {
+					return err
+				}
+				r.byDir[dir] = m
+			}
+			excluded, err := r.matches(parent)
+			if err != nil {
+				return err
+			}
+			if excluded {
+				continue
+			}
+		}
+
+		patterns = append(patterns, pattern)
+		r.patterns = append(r.patterns, pattern)
+		r.sources = append(r.sources, fmt.Sprintf("gitignore:%s:%d", path, lineNum))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(patterns) == 0 {
+		delete(r.byDir, dir)
+		return nil
+	}
+	m, err := newGitignoreMatcher(patterns)
+	if err != nil {
+		return err
+	}
+	r.byDir[dir] = m
+	return nil
+}
+
+// Translates a line of the .gitignore in dir into an absolute .dockerignore
+// pattern. Returns false for blank lines and comments.
+func translateGitignorePattern(dir string, line string) (string, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+
+	negate := false
+	if strings.HasPrefix(line, "!") {
+		negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	line = strings.TrimSuffix(line, "/")
+	if line == "" {
+		return "", false
+	}
+
+	var pattern string
+	if strings.Contains(line, "/") {
+		pattern = filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(line, "/")))
+	} else {
+		pattern = filepath.Join(dir, "**", line)
+	}
+	if negate {
+		pattern = "!" + pattern
+	}
+	return pattern, true
+}
+
+// The deepest directory in the pattern that can't match more than one path.
+func literalParent(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for strings.ContainsAny(dir, "*?[") {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
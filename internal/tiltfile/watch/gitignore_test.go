@@ -0,0 +1,183 @@
+package watch
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/internal/ignore"
+	"github.com/tilt-dev/tilt/internal/testutils/tempdir"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func TestUseGitignore(t *testing.T) {
+	f := NewFixture(t)
+	f.File("Tiltfile", `
+watch_settings(use_gitignore=True)
+`)
+	result, err := f.ExecFile("Tiltfile")
+	require.NoError(t, err)
+	require.Equal(t, model.WatchSettings{UseGitignore: true}, MustState(result))
+}
+
+func TestGitRepoRoot(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll(filepath.Join("repo", ".git"))
+	f.MkdirAll(filepath.Join("repo", "a", "b"))
+
+	root, ok := GitRepoRoot(f.JoinPath("repo", "a", "b"))
+	assert.True(t, ok)
+	assert.Equal(t, f.JoinPath("repo"), root)
+
+	_, ok = GitRepoRoot(f.Path())
+	assert.False(t, ok)
+}
+
+func TestReadGitignores(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll(".git")
+	f.WriteFile(".gitignore", `# build outputs
+dist/
+*.log
+!keep.log
+/root-only.txt
+`)
+	f.WriteFile("web/.gitignore", "node_modules\nsrc/gen/\n!dist\n")
+
+	gi, files, err := ReadGitignores(f.Path())
+	require.NoError(t, err)
+	assert.Equal(t, []string{f.JoinPath(".gitignore"), f.JoinPath("web", ".gitignore")}, files)
+	assert.Equal(t, []string{
+		"gitignore:" + f.JoinPath(".gitignore") + ":2",
+		"gitignore:" + f.JoinPath(".gitignore") + ":3",
+		"gitignore:" + f.JoinPath(".gitignore") + ":4",
+		"gitignore:" + f.JoinPath(".gitignore") + ":5",
+		"gitignore:" + f.JoinPath("web", ".gitignore") + ":1",
+		"gitignore:" + f.JoinPath("web", ".gitignore") + ":2",
+		"gitignore:" + f.JoinPath("web", ".gitignore") + ":3",
+	}, gi.PatternSources)
+
+	m := ignore.CreateBuildContextFilter(model.DockerignoresToIgnores([]model.Dockerignore{gi}))
+	for path, expected := range map[string]bool{
+		"dist/app.js":                  true,
+		"api/dist/app.js":              true,
+		"app.log":                      true,
+		"api/app.log":                  true,
+		"keep.log":                     false,
+		"root-only.txt":                true,
+		"api/root-only.txt":            false,
+		"web/node_modules/react/a.js":  true,
+		"api/node_modules/react/a.js":  false,
+		"web/src/gen/api.go":           true,
+		"src/gen/api.go":               false,
+		"web/dist/app.js":              false, // re-included by web/.gitignore
+		"web/src/main.go":              false,
+		"web/src/node_modules/react/a": true,
+	} {
+		actual, err := m.Matches(f.JoinPath(path))
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual, path)
+	}
+}
+
+func TestReadGitignoresSkipsIgnoredDirs(t *testing.T) {
+	f := tempdir.NewTempDirFixture(t)
+	f.MkdirAll(".git")
+	f.WriteFile(".gitignore", "vendor\nbuild/\n!build/keep.txt\n")
+	f.WriteFile("web/.gitignore", "!/build/keep.txt\n")
+	f.WriteFile("vendor/.gitignore", "*.go\n")
+	f.WriteFile("nested/.git/HEAD", "")
+	f.WriteFile("nested/.gitignore", "*.go\n")
+
+	gi, files, err := ReadGitignores(f.Path())
+	require.NoError(t, err)
+
+	// A .gitignore in an ignored dir or a nested repo isn't read, and a
+	// file in an ignored dir can't be re-included.
+	assert.Equal(t, []string{f.JoinPath(".gitignore"), f.JoinPath("web", ".gitignore")}, files)
+	assert.Equal(t, []string{
+		filepath.Join(f.Path(), "**", "vendor"),
+		filepath.Join(f.Path(), "**", "build"),
+	}, gi.Patterns)
+}
+
+func TestTranslateGitignorePattern(t *testing.T) {
+	dir := filepath.FromSlash("/repo/web")
+	for line, expected := range map[string]string{
+		"":                "",
+		"   ":             "",
+		"# comment":       "",
+		`\#not-a-comment`: filepath.FromSlash("/repo/web/**/#not-a-comment"),
+		`\!important`:     filepath.FromSlash("/repo/web/**/!important"),
+		"!keep":           "!" + filepath.FromSlash("/repo/web/**/keep"),
+		"node_modules/":   filepath.FromSlash("/repo/web/**/node_modules"),
+		"/dist":           filepath.FromSlash("/repo/web/dist"),
+		"src/gen":         filepath.FromSlash("/repo/web/src/gen"),
+		"**/tmp":          filepath.FromSlash("/repo/web/**/tmp"),
+		"*.log  ":         filepath.FromSlash("/repo/web/**/*.log"),
+		"build\r":         filepath.FromSlash("/repo/web/**/build"),
+	} {
+		actual, ok := translateGitignorePattern(dir, line)
+		assert.Equal(t, expected != "", ok, line)
+		assert.Equal(t, expected, actual, line)
+	}
+}
+
+// A repo with a .gitignore in each of its packages, like a big monorepo.
+func setUpLargeRepo(b *testing.B) *tempdir.TempDirFixture {
+	f := tempdir.NewTempDirFixture(b)
+	f.MkdirAll(".git")
+	f.WriteFile(".gitignore", "node_modules/\ndist/\n*.log\n.venv/\n__pycache__/\n*.pyc\ncoverage/\n")
+	for i := 0; i < 200; i++ {
+		var lines []string
+		for j := 0; j < 10; j++ {
+			lines = append(lines, fmt.Sprintf("gen-%d/", j), fmt.Sprintf("*.out%d", j))
+		}
+		lines = append(lines, "!important.out0")
+		f.WriteFile(filepath.Join("pkg", fmt.Sprintf("p%d", i), ".gitignore"), strings.Join(lines, "\n"))
+		for _, dir := range []string{"src/api", "src/web", "node_modules/react", "gen-3"} {
+			f.WriteFile(filepath.Join("pkg", fmt.Sprintf("p%d", i), dir, "main.go"), "")
+		}
+	}
+	return f
+}
+
+func BenchmarkReadGitignores(b *testing.B) {
+	f := setUpLargeRepo(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := ReadGitignores(f.Path())
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Matching happens on every file event, so this is the hot path.
+func BenchmarkGitignoreMatches(b *testing.B) {
+	f := setUpLargeRepo(b)
+	gi, _, err := ReadGitignores(f.Path())
+	if err != nil {
+		b.Fatal(err)
+	}
+	m := ignore.CreateFileChangeFilter(model.DockerignoresToIgnores([]model.Dockerignore{gi}))
+	paths := []string{
+		f.JoinPath("pkg", "p150", "main.go"),
+		f.JoinPath("pkg", "p150", "gen-3", "api.go"),
+		f.JoinPath("pkg", "p7", "node_modules", "react", "index.js"),
+		f.JoinPath("pkg", "p99", "important.out0"),
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range paths {
+			_, err := m.Matches(p)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -26,11 +26,17 @@ func (e Plugin) OnStart(env *starkit.Environment) error {
 func (e Plugin) setWatchSettings(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 	err := starkit.SetState(thread, func(settings model.WatchSettings) (model.WatchSettings, error) {
 		var ignores value.StringOrStringList
+		ignoreExtensionCache := settings.IgnoreExtensionCache
+		useGitignore := settings.UseGitignore
 		if err := starkit.UnpackArgs(thread, fn.Name(), args, kwargs,
 			"ignore?", &ignores,
+			"ignore_extension_cache?", &ignoreExtensionCache,
+			"use_gitignore?", &useGitignore,
 		); err != nil {
 			return settings, err
 		}
+		settings.IgnoreExtensionCache = ignoreExtensionCache
+		settings.UseGitignore = useGitignore
 
 		if len(ignores.Values) != 0 {
 			settings.Ignores = append(settings.Ignores, model.Dockerignore{
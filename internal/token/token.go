@@ -2,6 +2,7 @@ package token
 
 import (
 	"os"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -18,7 +19,8 @@ func (t Token) String() string {
 
 func GetOrCreateToken(dir *dirs.TiltDevDir) (Token, error) {
 	token, err := getExistingToken(dir)
-	if os.IsNotExist(err) {
+	if os.IsNotExist(err) || (err == nil && strings.TrimSpace(token.String()) == "") {
+		// An empty token is left over from a crash mid-write, so replace it.
 		u := uuid.New()
 		newtoken := Token(u.String())
 		err := writeToken(dir, newtoken)
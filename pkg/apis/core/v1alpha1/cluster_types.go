@@ -32,6 +32,12 @@ import (
 const ClusterNameDefault = "default"
 const ClusterNameDocker = "docker"
 
+// AnnotationRefreshConnection asks Tilt to reconnect to the cluster whenever
+// its value changes, re-reading the kubeconfig or docker config.
+//
+// `tilt refresh-connections` sets it to the current time.
+const AnnotationRefreshConnection = "tilt.dev/refresh-connection"
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
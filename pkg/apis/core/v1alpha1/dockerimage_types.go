@@ -81,6 +81,14 @@ type DockerImageSpec struct {
 	// tiltfile, and more.
 	ContextIgnores []IgnoreDef `json:"contextIgnores,omitempty" protobuf:"bytes,16,rep,name=contextIgnores"`
 
+	// The Go packages that the image's main package depends on.
+	//
+	// Set if the image builds a Go binary whose main package is known.
+	// ContextIgnores already ignores the packages that it doesn't import.
+	//
+	// +optional
+	GoDeps *DockerImageGoDeps `json:"goDeps,omitempty" protobuf:"bytes,19,opt,name=goDeps"`
+
 	// Args specifies the build arguments to the Dockerfile.
 	//
 	// Equivalent to `--build-arg` in the docker CLI.
@@ -90,6 +98,22 @@ type DockerImageSpec struct {
 	// +optional
 	Args []string `json:"args,omitempty" protobuf:"bytes,3,rep,name=args"`
 
+	// Build arguments whose values are read from a file or from the output
+	// of a command each time the image is built.
+	//
+	// The resolved values are passed to the build after Args.
+	//
+	// +optional
+	DynamicArgs []DockerImageDynamicArg `json:"dynamicArgs,omitempty" protobuf:"bytes,18,rep,name=dynamicArgs"`
+
+	// Base images that other Tilt sessions build and publish to a file.
+	//
+	// Each build rewrites the Dockerfile to use the image most recently
+	// published to the file, if any.
+	//
+	// +optional
+	BaseImages []DockerImageBaseImage `json:"baseImages,omitempty" protobuf:"bytes,20,rep,name=baseImages"`
+
 	// Target specifies the name of the stage in the Dockerfile to build.
 	//
 	// Equivalent to `--target` in the docker CLI.
@@ -183,6 +207,63 @@ type DockerImageSpec struct {
 	ExtraHosts []string `json:"extraHosts,omitempty" protobuf:"bytes,17,opt,name=extraHosts"`
 }
 
+// DockerImageBaseImage describes a base image that another Tilt session
+// builds and publishes.
+type DockerImageBaseImage struct {
+	// The image, as the Dockerfile refers to it (e.g., gcr.io/acme/base).
+	Ref string `json:"ref" protobuf:"bytes,1,opt,name=ref"`
+
+	// The file that the other session publishes the image to.
+	//
+	// +tilt:local-path=true
+	Path string `json:"path" protobuf:"bytes,2,opt,name=path"`
+}
+
+// DockerImageDynamicArg describes a build argument whose value is resolved
+// when the image is built, rather than when the spec is created.
+//
+// Exactly one of Command or Path must be set.
+type DockerImageDynamicArg struct {
+	// The name of the build argument.
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+
+	// A command to run. Its stdout, with surrounding whitespace trimmed,
+	// is the value.
+	//
+	// +optional
+	Command []string `json:"command,omitempty" protobuf:"bytes,2,rep,name=command"`
+
+	// The working directory of Command.
+	//
+	// +optional
+	// +tilt:local-path=true
+	Dir string `json:"dir,omitempty" protobuf:"bytes,3,opt,name=dir"`
+
+	// A file to read. Its contents, with trailing newlines trimmed, are the
+	// value.
+	//
+	// +optional
+	// +tilt:local-path=true
+	Path string `json:"path,omitempty" protobuf:"bytes,4,opt,name=path"`
+}
+
+// DockerImageGoDeps describes the Go packages that an image's main package
+// depends on.
+type DockerImageGoDeps struct {
+	// The main package, relative to the build context (e.g., ./cmd/api).
+	Main string `json:"main" protobuf:"bytes,1,opt,name=main"`
+
+	// The number of packages in the build context that Main depends on.
+	//
+	// +optional
+	Packages int32 `json:"packages,omitempty" protobuf:"varint,2,opt,name=packages"`
+
+	// When the dependencies were last computed.
+	//
+	// +optional
+	RefreshedAt metav1.Time `json:"refreshedAt,omitempty" protobuf:"bytes,3,opt,name=refreshedAt"`
+}
+
 var _ resource.Object = &DockerImage{}
 var _ resourcerest.SingularNameProvider = &DockerImage{}
 var _ resourcestrategy.Validater = &DockerImage{}
@@ -181,6 +181,15 @@ type KubernetesApplySpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,13,opt,name=cluster"`
+
+	// The names of the containers whose images may be replaced by the
+	// images in ImageMaps, e.g., to leave alone a sidecar that happens to
+	// use the same image. A name may be a glob, like "api-*".
+	//
+	// If not provided, all containers are candidates.
+	//
+	// +optional
+	ImageContainers []string `json:"imageContainers,omitempty" protobuf:"bytes,14,rep,name=imageContainers"`
 }
 
 var _ resource.Object = &KubernetesApply{}
@@ -241,13 +250,15 @@ func (in *KubernetesApply) Validate(ctx context.Context) field.ErrorList {
 	kdStrategy := in.Spec.DiscoveryStrategy
 	if !(kdStrategy == "" ||
 		kdStrategy == KubernetesDiscoveryStrategyDefault ||
-		kdStrategy == KubernetesDiscoveryStrategySelectorsOnly) {
+		kdStrategy == KubernetesDiscoveryStrategySelectorsOnly ||
+		kdStrategy == KubernetesDiscoveryStrategySelectorsAndOwners) {
 		fieldErrors = append(fieldErrors, field.NotSupported(
 			field.NewPath("spec.discoveryStrategy"),
 			kdStrategy,
 			[]string{
 				string(KubernetesDiscoveryStrategyDefault),
 				string(KubernetesDiscoveryStrategySelectorsOnly),
+				string(KubernetesDiscoveryStrategySelectorsAndOwners),
 			}))
 	}
 
@@ -408,6 +419,19 @@ var (
 	// the ones we want to track for readiness or live-update. You want the ones
 	// from the deployment.
 	KubernetesDiscoveryStrategySelectorsOnly KubernetesDiscoveryStrategy = "selectors-only"
+
+	// In the selectors-and-owners strategy, we traverse owner references like
+	// the default strategy, and also adopt pods that match the extra selectors
+	// in the namespaces of the applied resources.
+	//
+	// For example, an operator might create pods for a CR without setting an
+	// owner reference to it. The pods that we adopt by selector are reported
+	// as "discovered", and the pods we trace by owner reference as "owned".
+	//
+	// A pod that's owned by another resource stays with that resource. If
+	// the extra selectors of more than one resource match a pod, it's
+	// assigned to the resource whose name sorts first.
+	KubernetesDiscoveryStrategySelectorsAndOwners KubernetesDiscoveryStrategy = "selectors-and-owners"
 )
 
 type KubernetesApplyCmd struct {
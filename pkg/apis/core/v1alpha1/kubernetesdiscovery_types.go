@@ -97,6 +97,20 @@ type KubernetesDiscoverySpec struct {
 	//
 	// +optional
 	Cluster string `json:"cluster" protobuf:"bytes,5,opt,name=cluster"`
+
+	// DiscoveryStrategy describes how the ExtraSelectors are matched.
+	//
+	// With the selectors-and-owners strategy, ExtraSelectors only match Pods
+	// in the namespaces of the Watches, and a Pod that's traced to a WatchRef
+	// of another KubernetesDiscovery object is left to that object.
+	//
+	// If a Pod only matches the ExtraSelectors of more than one object, it's
+	// assigned to the object that sorts first by name.
+	//
+	// Every other strategy matches ExtraSelectors against all known Pods.
+	//
+	// +optional
+	DiscoveryStrategy KubernetesDiscoveryStrategy `json:"discoveryStrategy,omitempty" protobuf:"bytes,6,opt,name=discoveryStrategy,casttype=KubernetesDiscoveryStrategy"`
 }
 
 // KubernetesWatchRef is similar to v1.ObjectReference from the Kubernetes API and is used to determine
@@ -125,6 +125,8 @@ const AnnotationButtonType = "tilt.dev/uibutton-type"
 
 const ButtonTypeDisableToggle = "DisableToggle"
 const ButtonTypeStopBuild = "StopBuild"
+const ButtonTypeReset = "Reset"
+const ButtonTypeSetArgs = "SetArgs"
 
 var _ resource.Object = &UIButton{}
 var _ resourcerest.SingularNameProvider = &UIButton{}
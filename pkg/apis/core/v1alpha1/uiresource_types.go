@@ -361,6 +361,12 @@ type UIResourceKubernetes struct {
 	// for this resource.
 	// +optional
 	DisplayNames []string `json:"displayNames,omitempty" protobuf:"bytes,9,rep,name=displayNames"`
+
+	// The containers of the active pod, with their own status: the app
+	// containers, and any init containers that run for the life of the pod,
+	// like native sidecars.
+	// +optional
+	Containers []Container `json:"containers,omitempty" protobuf:"bytes,10,rep,name=containers"`
 }
 
 // UIResourceLocal contains status information specific to local commands.
@@ -414,6 +420,10 @@ const UIResourceReady UIResourceConditionType = "Ready"
 // its components. Runtime checks may not be passing yet.
 const UIResourceUpToDate UIResourceConditionType = "UpToDate"
 
+// Degraded means that a resource that runs on each update of the UI Resource
+// (e.g., its tests) failed, and was configured to report the failure here.
+const UIResourceDegraded UIResourceConditionType = "Degraded"
+
 type UIResourceCondition struct {
 	// Type of UI Resource condition.
 	Type UIResourceConditionType `json:"type" protobuf:"bytes,1,opt,name=type,casttype=UIResourceConditionType"`
@@ -8,6 +8,13 @@ type Fields map[string]string
 const FieldNameProgressID = "progressID"
 const FieldNameBuildEvent = "buildEvent"
 
+// The Dockerfile stage and step (e.g., "builder" and "2/5") that a line of
+// build output came from, so that the log can be split into sections.
+//
+// The stage is empty for a Dockerfile with one stage.
+const FieldNameBuildStage = "buildStage"
+const FieldNameBuildStep = "buildStep"
+
 // Most progress lines are optional. For example, if a bunch
 // of little upload updates come in, it's ok to skip some.
 //
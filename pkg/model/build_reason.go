@@ -1,6 +1,9 @@
 package model
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 type BuildReason int
 
@@ -38,6 +41,17 @@ const (
 	// Building manifestA will mark imageB
 	// with changed dependencies.
 	BuildReasonFlagChangedDeps
+
+	// An external tool (like an editor plugin) called /api/trigger.
+	BuildReasonFlagTriggerAPI
+
+	// The trigger asked for a full build, even if the changes could
+	// be live-updated.
+	BuildReasonFlagFullBuild
+
+	// The user reset the resource. Tilt forgets what it built, rebuilds
+	// without the builder's cache, and re-creates the resource's objects.
+	BuildReasonFlagReset
 )
 
 func (r BuildReason) With(flag BuildReason) BuildReason {
@@ -78,6 +92,9 @@ var translations = map[BuildReason]string{
 	BuildReasonFlagTriggerUnknown:  "Unknown Trigger",
 	BuildReasonFlagTiltfileArgs:    "Tilt Args",
 	BuildReasonFlagChangedDeps:     "Dependency Updated",
+	BuildReasonFlagTriggerAPI:      "API Trigger",
+	BuildReasonFlagFullBuild:       "Full Build",
+	BuildReasonFlagReset:           "Reset",
 }
 
 var triggerBuildReasons = []BuildReason{
@@ -85,6 +102,7 @@ var triggerBuildReasons = []BuildReason{
 	BuildReasonFlagTriggerCLI,
 	BuildReasonFlagTriggerHUD,
 	BuildReasonFlagTriggerUnknown,
+	BuildReasonFlagTriggerAPI,
 }
 
 var allBuildReasons = []BuildReason{
@@ -98,6 +116,9 @@ var allBuildReasons = []BuildReason{
 	BuildReasonFlagChangedDeps,
 	BuildReasonFlagTriggerUnknown,
 	BuildReasonFlagTiltfileArgs,
+	BuildReasonFlagTriggerAPI,
+	BuildReasonFlagFullBuild,
+	BuildReasonFlagReset,
 }
 
 func (r BuildReason) String() string {
@@ -105,8 +126,13 @@ func (r BuildReason) String() string {
 
 	// The trigger build reasons should never be used in conjunction with another
 	// build reason, because it was explicitly specified by the user rather than implicit.
+	// A reset is listed with its trigger, so that the build log says why
+	// the build started from scratch.
 	for _, v := range triggerBuildReasons {
 		if r.Has(v) {
+			if r.Has(BuildReasonFlagReset) {
+				return fmt.Sprintf("%s (%s)", translations[BuildReasonFlagReset], translations[v])
+			}
 			return translations[v]
 		}
 	}
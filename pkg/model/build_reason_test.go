@@ -9,4 +9,7 @@ import (
 func TestBuildReasonString(t *testing.T) {
 	assert.Equal(t, "Changed Files | Config Changed", BuildReasonFlagChangedFiles.With(BuildReasonFlagConfig).String())
 	assert.Equal(t, "Web Trigger", BuildReasonFlagInit.With(BuildReasonFlagTriggerWeb).String())
+	assert.Equal(t, "API Trigger", BuildReasonFlagTriggerAPI.With(BuildReasonFlagFullBuild).String())
+	assert.Equal(t, BuildReasonFlagFullBuild, BuildReasonFlagTriggerAPI.With(BuildReasonFlagFullBuild).WithoutTriggers())
+	assert.Equal(t, "Reset (CLI Trigger)", BuildReasonFlagTriggerCLI.With(BuildReasonFlagReset).String())
 }
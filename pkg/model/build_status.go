@@ -29,6 +29,46 @@ type BuildRecord struct {
 	// We count the warnings by looking up all the logs with Level=WARNING
 	// in the logstore. We store this number separately for ease of use.
 	WarningCount int
+
+	// The requests from external tools that this build ran, if any.
+	Triggers []TriggerRequest
+
+	// If this is a Tiltfile build that ran because its args changed,
+	// the old and new args.
+	ArgsChange *TiltfileArgsChange
+}
+
+// A change to the args of a running Tiltfile (e.g., with `tilt args`).
+type TiltfileArgsChange struct {
+	From []string
+	To   []string
+
+	// Whether the Tiltfile failed to load with the new args, so Tilt went
+	// back to the old ones.
+	Reverted bool
+}
+
+// A request from an external tool (e.g., an editor plugin) to build a
+// resource, made through /api/trigger.
+type TriggerRequest struct {
+	// Identifies the request, so that the caller can find the build that ran it.
+	ID string
+
+	// The resource the caller triggered. For a resource group, this is the
+	// group, and each member's build runs the request.
+	Resource ManifestName
+
+	// Why the caller wants the build, in their own words (e.g., "file saved").
+	Message string
+}
+
+func (bs BuildRecord) Trigger(id string) (TriggerRequest, bool) {
+	for _, t := range bs.Triggers {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return TriggerRequest{}, false
 }
 
 func (bs BuildRecord) Empty() bool {
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -20,6 +21,10 @@ type ImageTarget struct {
 	LiveUpdateSpec       v1alpha1.LiveUpdateSpec
 	LiveUpdateReconciler bool
 
+	// If the live-update steps came from somewhere other than the image
+	// itself (e.g., a k8s_resource override), a description of where.
+	LiveUpdateSource string
+
 	// An apiserver-driven data model for using docker to build images.
 	DockerImageName string
 
@@ -28,6 +33,13 @@ type ImageTarget struct {
 
 	BuildDetails BuildDetails
 
+	// How long the build may run before it's canceled. 0 means no timeout.
+	BuildTimeout time.Duration
+
+	// Build without the builder's cache. Set for the build after the user
+	// resets the resource, not by the Tiltfile.
+	NoCache bool
+
 	// In a live-update-only image, we don't inject the image into the Kubernetes
 	// deploy, we only live-update to the deployed object. See this issue:
 	//
@@ -245,7 +257,16 @@ func (i ImageTarget) WithOverrideCommand(cmd Cmd) ImageTarget {
 func (i ImageTarget) LocalPaths() []string {
 	switch bd := i.BuildDetails.(type) {
 	case DockerBuild:
-		return []string{bd.Context}
+		result := []string{bd.Context}
+		for _, arg := range bd.DynamicArgs {
+			if arg.Path != "" {
+				result = append(result, arg.Path)
+			}
+		}
+		for _, dep := range bd.BaseImages {
+			result = append(result, dep.Path)
+		}
+		return result
 	case CustomBuild:
 		return append([]string(nil), bd.Deps...)
 	case DockerComposeBuild:
@@ -343,6 +364,10 @@ func ImageTargetsByID(iTargets []ImageTarget) map[TargetID]ImageTarget {
 
 type DockerBuild struct {
 	v1alpha1.DockerImageSpec
+
+	// If set, the ref and digest of each build are written to this file,
+	// so that other Tilt sessions can build on the image.
+	PublishPath string
 }
 
 func (DockerBuild) buildDetails() {}
@@ -26,6 +26,14 @@ type LocalTarget struct {
 
 	// Move this to CmdServerSpec when we move CmdServer to API
 	ServeCmdDisableSource *v1alpha1.DisableSource
+
+	// Re-run the update cmd after each successful build or live update
+	// of these resources (e.g., to run the tests that cover them).
+	RunOnUpdateOf []ManifestName
+
+	// When true, a failed update cmd marks the resources in RunOnUpdateOf
+	// as degraded. Otherwise, the result is only informational.
+	DegradeOnFailure bool
 }
 
 var _ TargetSpec = LocalTarget{}
@@ -83,6 +91,22 @@ func (lt LocalTarget) WithReadinessProbe(probeSpec *v1alpha1.Probe) LocalTarget
 	return lt
 }
 
+func (lt LocalTarget) WithRunOnUpdateOf(mns []ManifestName, degradeOnFailure bool) LocalTarget {
+	lt.RunOnUpdateOf = mns
+	lt.DegradeOnFailure = degradeOnFailure
+	return lt
+}
+
+// Whether this target re-runs when the given resource updates.
+func (lt LocalTarget) RunsOnUpdateOf(mn ManifestName) bool {
+	for _, dep := range lt.RunOnUpdateOf {
+		if dep == mn {
+			return true
+		}
+	}
+	return false
+}
+
 func (lt LocalTarget) ID() TargetID {
 	return TargetID{
 		Name: lt.Name,
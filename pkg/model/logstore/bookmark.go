@@ -0,0 +1,29 @@
+package logstore
+
+import (
+	"fmt"
+	"time"
+)
+
+// A named point in the log, e.g., "repro happened here", so that people
+// can link to it.
+type Bookmark struct {
+	Name string    `json:"name"`
+	Time time.Time `json:"time"`
+
+	// The first log segment at or after Time.
+	Checkpoint Checkpoint `json:"checkpoint"`
+}
+
+// Bookmark creates a bookmark for the log at time t. If name is empty,
+// the bookmark is named after the time.
+func (s *LogStore) Bookmark(name string, t time.Time) Bookmark {
+	if name == "" {
+		name = fmt.Sprintf("Bookmark at %s", t.Format("15:04:05"))
+	}
+	return Bookmark{
+		Name:       name,
+		Time:       t,
+		Checkpoint: s.CheckpointAt(t),
+	}
+}
@@ -130,6 +130,9 @@ type LogEvent interface {
 // ask questions like "give me all logs since checkpoint X" and
 // "scrub everything since checkpoint Y". In practice, this
 // is just an index into the segment slice.
+//
+// Checkpoints count segments since the log store was created, so a
+// checkpoint still refers to the same segment after the log is truncated.
 type Checkpoint int
 
 // A central place for storing logs. Not thread-safe.
@@ -176,6 +179,19 @@ func (s *LogStore) Checkpoint() Checkpoint {
 	return s.checkpointFromIndex(len(s.segments))
 }
 
+// CheckpointAt returns the checkpoint of the first segment logged at or
+// after t, or the current checkpoint if nothing has been logged since.
+//
+// If t is before the start of the (possibly truncated) log, returns the
+// first checkpoint still in the log.
+func (s *LogStore) CheckpointAt(t time.Time) Checkpoint {
+	index := len(s.segments)
+	for index > 0 && !s.segments[index-1].Time.Before(t) {
+		index--
+	}
+	return s.checkpointFromIndex(index)
+}
+
 func (s *LogStore) checkpointFromIndex(index int) Checkpoint {
 	return Checkpoint(index) + s.checkpointOffset
 }
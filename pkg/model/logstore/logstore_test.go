@@ -306,6 +306,27 @@ func TestContinuingStringAfterLimit(t *testing.T) {
 	assert.Equal(t, "jklmnopqr\n", l.ContinuingString(c3))
 }
 
+func TestCheckpointAt(t *testing.T) {
+	l := NewLogStore()
+	l.maxLogLengthInBytes = 20
+
+	start := time.Now()
+	assert.Equal(t, Checkpoint(0), l.CheckpointAt(start))
+
+	l.Append(newTestLogEvent("", start, "123456789\n"), nil)
+	l.Append(newTestLogEvent("", start.Add(time.Second), "abcdefghi\n"), nil)
+	assert.Equal(t, Checkpoint(0), l.CheckpointAt(start))
+	assert.Equal(t, Checkpoint(1), l.CheckpointAt(start.Add(time.Millisecond)))
+	assert.Equal(t, Checkpoint(2), l.CheckpointAt(start.Add(time.Minute)))
+
+	// Checkpoints still refer to the same segments after truncation.
+	l.Append(newTestLogEvent("", start.Add(2*time.Second), "jklmnopqr\n"), nil)
+	c := l.CheckpointAt(start.Add(2 * time.Second))
+	assert.Equal(t, Checkpoint(2), c)
+	assert.Equal(t, "jklmnopqr\n", l.ContinuingString(c))
+	assert.Equal(t, c, l.CheckpointAt(start))
+}
+
 func TestManifestLog(t *testing.T) {
 	l := NewLogStore()
 	l.Append(newGlobalTestLogEvent("1\n2\n"), nil)
@@ -0,0 +1,29 @@
+package model
+
+// A group of resources that show up as one logical resource (e.g., a
+// service's Deployment, its database, and a local process that it needs).
+//
+// The group's status is the worst of its members' statuses. Triggering or
+// disabling the group triggers or disables every member. The members are
+// still resources in their own right.
+type ResourceGroup struct {
+	Name ManifestName
+
+	// The resources in the group. Groups nested in this group are expanded
+	// to their members, so every name here is a manifest.
+	Members []ManifestName
+
+	Labels map[string]string
+
+	// The Tiltfile that defined the group.
+	SourceTiltfile ManifestName
+}
+
+func (g ResourceGroup) HasMember(mn ManifestName) bool {
+	for _, m := range g.Members {
+		if m == mn {
+			return true
+		}
+	}
+	return false
+}
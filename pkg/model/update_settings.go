@@ -8,14 +8,53 @@ import (
 
 const (
 	DefaultMaxParallelUpdates = 3
+
+	// How long an image build may run before Tilt cancels it.
+	DefaultBuildTimeout = 30 * time.Minute
+
+	// How long each command of a live update may run before Tilt cancels
+	// it. Shorter than a build, since these are meant to be quick.
+	DefaultLiveUpdateExecTimeout = 5 * time.Minute
+)
+
+// How live update copies files and runs commands in Kubernetes containers.
+type LiveUpdateTransport string
+
+const (
+	// Use the Kubernetes exec API if the user may exec into pods, and the Tilt
+	// agent otherwise.
+	LiveUpdateTransportAuto LiveUpdateTransport = "auto"
+
+	// Use the Kubernetes exec API.
+	LiveUpdateTransportExec LiveUpdateTransport = "exec"
+
+	// Use the Tilt agent daemonset, which talks to the container runtime on
+	// each node.
+	LiveUpdateTransportAgent LiveUpdateTransport = "agent"
 )
 
+var AllLiveUpdateTransports = []LiveUpdateTransport{
+	LiveUpdateTransportAuto,
+	LiveUpdateTransportExec,
+	LiveUpdateTransportAgent,
+}
+
 type UpdateSettings struct {
 	maxParallelUpdates int           // max number of updates to run concurrently
 	k8sUpsertTimeout   time.Duration // timeout for k8s upsert operations
 
+	// Timeouts for image builds, and for live update commands. 0 means no
+	// timeout.
+	buildTimeout          time.Duration
+	liveUpdateExecTimeout time.Duration
+
 	// A list of images to suppress the warning for.
 	SuppressUnusedImageWarnings []string
+
+	// How live update reaches containers in the Kubernetes cluster, and
+	// overrides by kube context name.
+	LiveUpdateTransport          LiveUpdateTransport
+	LiveUpdateTransportByContext map[string]LiveUpdateTransport
 }
 
 func (us UpdateSettings) MaxParallelUpdates() int {
@@ -52,9 +91,50 @@ func (us UpdateSettings) WithK8sUpsertTimeout(timeout time.Duration) UpdateSetti
 	return us
 }
 
+// The default timeout of image builds. Images may override it.
+func (us UpdateSettings) BuildTimeout() time.Duration {
+	return us.buildTimeout
+}
+
+func (us UpdateSettings) WithBuildTimeout(timeout time.Duration) UpdateSettings {
+	if timeout < 0 {
+		timeout = 0
+	}
+	us.buildTimeout = timeout
+	return us
+}
+
+// The timeout of each command that a live update runs in a container.
+func (us UpdateSettings) LiveUpdateExecTimeout() time.Duration {
+	return us.liveUpdateExecTimeout
+}
+
+func (us UpdateSettings) WithLiveUpdateExecTimeout(timeout time.Duration) UpdateSettings {
+	if timeout < 0 {
+		timeout = 0
+	}
+	us.liveUpdateExecTimeout = timeout
+	return us
+}
+
+// How live update reaches containers in the cluster of the given kube context.
+func (us UpdateSettings) LiveUpdateTransportFor(kubeContext string) LiveUpdateTransport {
+	t, ok := us.LiveUpdateTransportByContext[kubeContext]
+	if ok {
+		return t
+	}
+	if us.LiveUpdateTransport == "" {
+		return LiveUpdateTransportAuto
+	}
+	return us.LiveUpdateTransport
+}
+
 func DefaultUpdateSettings() UpdateSettings {
 	return UpdateSettings{
-		maxParallelUpdates: DefaultMaxParallelUpdates,
-		k8sUpsertTimeout:   v1alpha1.KubernetesApplyTimeoutDefault,
+		maxParallelUpdates:    DefaultMaxParallelUpdates,
+		k8sUpsertTimeout:      v1alpha1.KubernetesApplyTimeoutDefault,
+		buildTimeout:          DefaultBuildTimeout,
+		liveUpdateExecTimeout: DefaultLiveUpdateExecTimeout,
+		LiveUpdateTransport:   LiveUpdateTransportAuto,
 	}
 }
@@ -4,6 +4,13 @@ import "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
 
 type WatchSettings struct {
 	Ignores []Dockerignore
+
+	// Don't reload the Tiltfile when files in downloaded extension repos change.
+	IgnoreExtensionCache bool
+
+	// Read .gitignore files in the repos that contain watched paths, and
+	// ignore what they ignore.
+	UseGitignore bool
 }
 
 func (ws WatchSettings) Empty() bool {
@@ -19,6 +26,18 @@ type Dockerignore struct {
 
 	// Patterns parsed out of the .dockerignore file.
 	Patterns []string
+
+	// Optional. Where each pattern comes from, when the patterns come from
+	// more than one place. Parallel to Patterns.
+	PatternSources []string
+}
+
+// PatternSource returns where the i-th pattern comes from.
+func (d Dockerignore) PatternSource(i int) string {
+	if i < len(d.PatternSources) {
+		return d.PatternSources[i]
+	}
+	return d.Source
 }
 
 func (d Dockerignore) Empty() bool {
@@ -0,0 +1,177 @@
+package openapi
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+
+	"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1"
+)
+
+var update = flag.Bool("update", false, "Add new fields to the schema conformance file")
+
+const v1alpha1Pkg = "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1."
+
+// Scripts depend on the shape of `tilt get -o json`, so the fields of
+// the API types can only be added to within an API version.
+//
+// testdata/<version>.fields records the type of every field. This test
+// fails if a field is removed or changes type. New fields need to be
+// recorded with:
+//
+//	go test ./pkg/openapi -update
+//
+// To make a breaking change, bump the API version.
+func TestSchemaConformance(t *testing.T) {
+	defs := GetOpenAPIDefinitions(func(path string) spec.Ref {
+		return spec.MustCreateRef(path)
+	})
+
+	actual := map[string]string{}
+	for _, obj := range v1alpha1.AllResourceObjects() {
+		kind := reflect.TypeOf(obj).Elem().Name()
+		collectFieldTypes(defs, v1alpha1Pkg+kind, kind, map[string]bool{}, actual)
+	}
+
+	goldenPath := filepath.Join("testdata", v1alpha1.Version+".fields")
+	expected, err := readFieldTypes(goldenPath)
+	require.NoError(t, err)
+
+	var breaking []string
+	for field, typ := range expected {
+		actualTyp, ok := actual[field]
+		if !ok {
+			breaking = append(breaking, fmt.Sprintf("%s: removed (was %s)", field, typ))
+		} else if actualTyp != typ {
+			breaking = append(breaking, fmt.Sprintf("%s: changed from %s to %s", field, typ, actualTyp))
+		}
+	}
+	sort.Strings(breaking)
+	require.Empty(t, breaking, "breaking changes to the %s API; bump the API version instead",
+		v1alpha1.SchemeGroupVersion)
+
+	var added []string
+	for field := range actual {
+		if _, ok := expected[field]; !ok {
+			added = append(added, field)
+		}
+	}
+	sort.Strings(added)
+	if len(added) == 0 {
+		return
+	}
+
+	if *update {
+		require.NoError(t, writeFieldTypes(goldenPath, actual))
+		return
+	}
+	t.Errorf("new fields missing from %s (run `go test ./pkg/openapi -update`):\n%s",
+		goldenPath, strings.Join(added, "\n"))
+}
+
+// collectFieldTypes records the type of every field under the named
+// definition, following references to other types in the API package.
+func collectFieldTypes(defs map[string]common.OpenAPIDefinition, name, prefix string, visiting map[string]bool, result map[string]string) {
+	if visiting[name] {
+		return
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	def, ok := defs[name]
+	if !ok {
+		return
+	}
+
+	for field, schema := range def.Schema.Properties {
+		schema := schema
+		collectSchemaType(defs, &schema, prefix+"."+field, visiting, result)
+	}
+}
+
+func collectSchemaType(defs map[string]common.OpenAPIDefinition, schema *spec.Schema, path string, visiting map[string]bool, result map[string]string) {
+	result[path] = schemaType(schema)
+
+	if ref := schemaRef(schema); strings.HasPrefix(ref, v1alpha1Pkg) {
+		collectFieldTypes(defs, ref, path, visiting, result)
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		collectSchemaType(defs, schema.Items.Schema, path+"[]", visiting, result)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		collectSchemaType(defs, schema.AdditionalProperties.Schema, path+"{}", visiting, result)
+	}
+}
+
+func schemaRef(schema *spec.Schema) string {
+	if ref := schema.Ref.String(); ref != "" {
+		return ref
+	}
+	if len(schema.AllOf) == 1 {
+		return schema.AllOf[0].Ref.String()
+	}
+	return ""
+}
+
+func schemaType(schema *spec.Schema) string {
+	if ref := schemaRef(schema); ref != "" {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		return "[]" + schemaType(schema.Items.Schema)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil {
+		return "map[string]" + schemaType(schema.AdditionalProperties.Schema)
+	}
+
+	typ := strings.Join(schema.Type, "|")
+	if schema.Format != "" {
+		typ += "(" + schema.Format + ")"
+	}
+	return typ
+}
+
+func readFieldTypes(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		field, typ, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("%s: malformed line %q", path, line)
+		}
+		result[field] = typ
+	}
+	return result, scanner.Err()
+}
+
+func writeFieldTypes(path string, fields map[string]string) error {
+	lines := make([]string, 0, len(fields))
+	for field, typ := range fields {
+		lines = append(lines, field+" "+typ)
+	}
+	sort.Strings(lines)
+
+	header := "# The type of every field in the API, checked by TestSchemaConformance.\n" +
+		"# Fields can be added, but not removed or changed, without bumping the API version.\n"
+	return os.WriteFile(path, []byte(header+strings.Join(lines, "\n")+"\n"), 0644)
+}
@@ -76,6 +76,9 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerComposeServiceStatus":        schema_pkg_apis_core_v1alpha1_DockerComposeServiceStatus(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerContainerState":              schema_pkg_apis_core_v1alpha1_DockerContainerState(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImage":                       schema_pkg_apis_core_v1alpha1_DockerImage(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageBaseImage":              schema_pkg_apis_core_v1alpha1_DockerImageBaseImage(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageDynamicArg":             schema_pkg_apis_core_v1alpha1_DockerImageDynamicArg(ref),
+		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageGoDeps":                 schema_pkg_apis_core_v1alpha1_DockerImageGoDeps(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageList":                   schema_pkg_apis_core_v1alpha1_DockerImageList(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageSpec":                   schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref),
 		"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageStageStatus":            schema_pkg_apis_core_v1alpha1_DockerImageStageStatus(ref),
@@ -2254,6 +2257,125 @@ func schema_pkg_apis_core_v1alpha1_DockerImage(ref common.ReferenceCallback) com
 	}
 }
 
+func schema_pkg_apis_core_v1alpha1_DockerImageBaseImage(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerImageBaseImage describes a base image that another Tilt session builds and publishes.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"ref": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The image, as the Dockerfile refers to it (e.g., gcr.io/acme/base).",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The file that the other session publishes the image to.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"ref", "path"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_DockerImageDynamicArg(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerImageDynamicArg describes a build argument whose value is resolved when the image is built, rather than when the spec is created.\n\nExactly one of Command or Path must be set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The name of the build argument.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"command": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A command to run. Its stdout, with surrounding whitespace trimmed, is the value.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"dir": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The working directory of Command.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A file to read. Its contents, with trailing newlines trimmed, are the value.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_core_v1alpha1_DockerImageGoDeps(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "DockerImageGoDeps describes the Go packages that an image's main package depends on.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"main": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The main package, relative to the build context (e.g., ./cmd/api).",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"packages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The number of packages in the build context that Main depends on.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"refreshedAt": {
+						SchemaProps: spec.SchemaProps{
+							Description: "When the dependencies were last computed.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+				},
+				Required: []string{"main"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_core_v1alpha1_DockerImageList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -2346,6 +2468,12 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"goDeps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The Go packages that the image's main package depends on.\n\nSet if the image builds a Go binary whose main package is known. ContextIgnores already ignores the packages that it doesn't import.",
+							Ref:         ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageGoDeps"),
+						},
+					},
 					"args": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Args specifies the build arguments to the Dockerfile.\n\nEquivalent to `--build-arg` in the docker CLI.\n\nEach item should take the form \"KEY\" or \"KEY=VALUE\".",
@@ -2361,6 +2489,34 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 							},
 						},
 					},
+					"dynamicArgs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Build arguments whose values are read from a file or from the output of a command each time the image is built.\n\nThe resolved values are passed to the build after Args.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageDynamicArg"),
+									},
+								},
+							},
+						},
+					},
+					"baseImages": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Base images that other Tilt sessions build and publish to a file.\n\nEach build rewrites the Dockerfile to use the image most recently published to the file, if any.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageBaseImage"),
+									},
+								},
+							},
+						},
+					},
 					"target": {
 						SchemaProps: spec.SchemaProps{
 							Description: "Target specifies the name of the stage in the Dockerfile to build.\n\nEquivalent to `--target` in the docker CLI.",
@@ -2498,7 +2654,7 @@ func schema_pkg_apis_core_v1alpha1_DockerImageSpec(ref common.ReferenceCallback)
 			},
 		},
 		Dependencies: []string{
-			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageBaseImage", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageDynamicArg", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.DockerImageGoDeps", "github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.IgnoreDef"},
 	}
 }
 
@@ -4112,6 +4268,21 @@ func schema_pkg_apis_core_v1alpha1_KubernetesApplySpec(ref common.ReferenceCallb
 							Format:      "",
 						},
 					},
+					"imageContainers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The names of the containers whose images may be replaced by the images in ImageMaps, e.g., to leave alone a sidecar that happens to use the same image. A name may be a glob, like \"api-*\".\n\nIf not provided, all containers are candidates.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
@@ -4419,6 +4590,13 @@ func schema_pkg_apis_core_v1alpha1_KubernetesDiscoverySpec(ref common.ReferenceC
 							Format:      "",
 						},
 					},
+					"discoveryStrategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DiscoveryStrategy describes how the ExtraSelectors are matched.\n\nWith the selectors-and-owners strategy, ExtraSelectors only match Pods in the namespaces of the Watches, and a Pod that's traced to a WatchRef of another KubernetesDiscovery object is left to that object.\n\nIf a Pod only matches the ExtraSelectors of more than one object, it's assigned to the object that sorts first by name.\n\nEvery other strategy matches ExtraSelectors against all known Pods.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"watches"},
 			},
@@ -8018,11 +8196,25 @@ func schema_pkg_apis_core_v1alpha1_UIResourceKubernetes(ref common.ReferenceCall
 							},
 						},
 					},
+					"containers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The containers of the active pod, with their own status: the app containers, and any init containers that run for the life of the pod, like native sidecars.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Container"),
+									},
+								},
+							},
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+			"github.com/tilt-dev/tilt/pkg/apis/core/v1alpha1.Container", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
 
@@ -0,0 +1,103 @@
+import React from "react"
+import styled from "styled-components"
+import { Color, Font, FontSize, SizeUnit } from "./style-helpers"
+import { UIResource } from "./types"
+
+type Container = Proto.v1alpha1Container
+
+type ContainerListProps = {
+  resource?: UIResource
+}
+
+const ContainerListRoot = styled.ul`
+  list-style: none;
+  margin: 0;
+  padding: ${SizeUnit(0.25)} ${SizeUnit(0.5)};
+  border-bottom: 1px solid ${Color.gray40};
+  color: ${Color.gray70};
+  font-size: ${FontSize.smallest};
+`
+
+const ContainerItem = styled.li`
+  display: flex;
+  align-items: baseline;
+  gap: ${SizeUnit(0.5)};
+  padding: ${SizeUnit(0.1)} 0;
+`
+
+const ContainerName = styled.span`
+  font-family: ${Font.monospace};
+  color: ${Color.white};
+`
+
+const ContainerStatus = styled.span`
+  white-space: nowrap;
+
+  &.is-ready {
+    color: ${Color.green};
+  }
+  &.is-pending {
+    color: ${Color.yellow};
+  }
+  &.is-error {
+    color: ${Color.red};
+  }
+`
+
+const ContainerRestarts = styled.span`
+  white-space: nowrap;
+`
+
+const ContainerImage = styled.span`
+  font-family: ${Font.monospace};
+  color: ${Color.gray50};
+  overflow: hidden;
+  text-overflow: ellipsis;
+  white-space: nowrap;
+  min-width: 0;
+`
+
+// A short description of the container's state, and the class to color it.
+export function containerStatus(c: Container): [string, string] {
+  let state = c.state
+  if (state?.terminated) {
+    let t = state.terminated
+    let text = t.reason || `Exited (${t.exitCode ?? 0})`
+    return [text, t.exitCode ? "is-error" : "is-pending"]
+  }
+  if (state?.waiting) {
+    return [state.waiting.reason || "Waiting", "is-pending"]
+  }
+  if (state?.running) {
+    return c.ready ? ["Ready", "is-ready"] : ["Not ready", "is-pending"]
+  }
+  return ["Unknown", "is-pending"]
+}
+
+// Lists each container in the resource's pod, with its readiness, restarts,
+// and image, so that sidecars are easy to tell apart from the app.
+export default function ContainerList(props: ContainerListProps) {
+  let containers = props.resource?.status?.k8sResourceInfo?.containers || []
+  if (containers.length === 0) {
+    return null
+  }
+
+  let items = containers.map((c) => {
+    let [status, statusClass] = containerStatus(c)
+    let restarts = c.restarts || 0
+    return (
+      <ContainerItem key={c.name} aria-label={`Container ${c.name}`}>
+        <ContainerName>{c.name}</ContainerName>
+        <ContainerStatus className={statusClass}>{status}</ContainerStatus>
+        {restarts > 0 ? (
+          <ContainerRestarts>
+            {restarts} {restarts === 1 ? "restart" : "restarts"}
+          </ContainerRestarts>
+        ) : null}
+        <ContainerImage title={c.image}>{c.image}</ContainerImage>
+      </ContainerItem>
+    )
+  })
+
+  return <ContainerListRoot aria-label="Containers">{items}</ContainerListRoot>
+}
@@ -0,0 +1,48 @@
+import React from "react"
+import styled from "styled-components"
+import { AnalyticsAction, incr } from "./analytics"
+import {
+  AnimDuration,
+  Color,
+  FontSize,
+  mixinResetButtonStyle,
+} from "./style-helpers"
+
+const ExportBundleLink = styled.a`
+  ${mixinResetButtonStyle};
+  margin-left: 1rem;
+  font-size: ${FontSize.small};
+  color: ${Color.white};
+  text-decoration: none;
+  transition: color ${AnimDuration.default} ease;
+
+  &:hover {
+    color: ${Color.blue};
+  }
+`
+
+export interface ExportBundleProps {
+  resourceName: string
+}
+
+// The same bundle that `tilt export` writes.
+export function exportBundleURL(resourceName: string): string {
+  return `/api/export?resource=${encodeURIComponent(resourceName)}`
+}
+
+const ExportBundle: React.FC<ExportBundleProps> = ({ resourceName }) => {
+  return (
+    <ExportBundleLink
+      href={exportBundleURL(resourceName)}
+      download={`${resourceName}-bundle.tgz`}
+      title="Download this resource's effective configuration, recent builds, and logs, with secrets scrubbed"
+      onClick={() =>
+        void incr("ui.web.exportBundle", { action: AnalyticsAction.Click })
+      }
+    >
+      Export
+    </ExportBundleLink>
+  )
+}
+
+export default ExportBundle
@@ -7,6 +7,7 @@ import { ReactComponent as LogoWordmarkSvg } from "./assets/svg/logo-wordmark.sv
 import { ReactComponent as TableViewSvg } from "./assets/svg/table-view-icon.svg"
 import { CustomNav } from "./CustomNav"
 import { GlobalNav, GlobalNavProps } from "./GlobalNav"
+import { LogBookmarksMenu } from "./LogBookmarks"
 import { usePathBuilder } from "./PathBuilder"
 import {
   AllResourceStatusSummary,
@@ -175,6 +176,7 @@ export default function HeaderBar({
           isSocketConnected={isSocketConnected}
         />
         <CustomNav view={view} />
+        <LogBookmarksMenu view={view} />
         <GlobalNav {...globalNavProps} />
       </HeaderBarRoot>
     </>
@@ -2,6 +2,7 @@ import React, { useEffect } from "react"
 import { useStorageState } from "react-storage-hooks"
 import styled from "styled-components"
 import ClearLogs from "./ClearLogs"
+import ExportBundle from "./ExportBundle"
 import { InstrumentedButton } from "./instrumentedComponents"
 import {
   AnimDuration,
@@ -9,6 +10,7 @@ import {
   FontSize,
   mixinResetButtonStyle,
 } from "./style-helpers"
+import { ResourceName } from "./types"
 
 export const LogFontSizeScaleLocalStorageKey = "tilt.global.log-font-scale"
 export const LogFontSizeScaleCSSProperty = "--log-font-scale"
@@ -117,9 +119,16 @@ const LogActions: React.FC<LogActionsProps> = ({
   resourceName,
   isSnapshot,
 }) => {
+  // The Tiltfile and the combined log views have no configuration to export.
+  const exportable =
+    !isSnapshot &&
+    resourceName !== ResourceName.all &&
+    resourceName !== ResourceName.starred &&
+    resourceName !== ResourceName.tiltfile
   return (
     <LogActionsGroup>
       <LogsFontSize />
+      {exportable && <ExportBundle resourceName={resourceName} />}
       {isSnapshot || <ClearLogs resourceName={resourceName} />}
     </LogActionsGroup>
   )
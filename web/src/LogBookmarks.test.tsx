@@ -0,0 +1,74 @@
+import { render, screen } from "@testing-library/react"
+import userEvent from "@testing-library/user-event"
+import React from "react"
+import { MemoryRouter } from "react-router-dom"
+import {
+  LogBookmark,
+  LogBookmarksAnnotation,
+  logBookmarks,
+  LogBookmarksMenu,
+} from "./LogBookmarks"
+import { nResourceView } from "./testdata"
+
+function viewWithBookmarks(bookmarks: LogBookmark[]): Proto.webviewView {
+  let view = nResourceView(1)
+  view.uiSession = {
+    ...view.uiSession,
+    metadata: {
+      annotations: { [LogBookmarksAnnotation]: JSON.stringify(bookmarks) },
+    },
+  }
+  return view
+}
+
+function customRender(view: Proto.webviewView) {
+  return render(
+    <MemoryRouter initialEntries={["/"]}>
+      <LogBookmarksMenu view={view} />
+    </MemoryRouter>
+  )
+}
+
+describe("LogBookmarks", () => {
+  it("parses bookmarks from the session", () => {
+    let bookmarks = [
+      { name: "bookmark-1", time: "2021-01-01T00:00:00Z", checkpoint: 5 },
+    ]
+    expect(logBookmarks(viewWithBookmarks(bookmarks))).toEqual(bookmarks)
+    expect(logBookmarks(nResourceView(1))).toEqual([])
+  })
+
+  it("ignores malformed bookmarks", () => {
+    let view = nResourceView(1)
+    view.uiSession = {
+      ...view.uiSession,
+      metadata: { annotations: { [LogBookmarksAnnotation]: "{" } },
+    }
+    expect(logBookmarks(view)).toEqual([])
+  })
+
+  it("renders nothing without bookmarks", () => {
+    const { container } = customRender(nResourceView(1))
+    expect(container).toBeEmptyDOMElement()
+  })
+
+  it("links each bookmark to its checkpoint", () => {
+    customRender(
+      viewWithBookmarks([
+        { name: "bookmark-1", time: "2021-01-01T00:00:00Z", checkpoint: 5 },
+        { name: "bookmark-2", time: "2021-01-01T00:01:00Z", checkpoint: 12 },
+      ])
+    )
+
+    userEvent.click(screen.getByRole("menuitem", { name: "Log bookmarks" }))
+
+    expect(screen.getByRole("link", { name: /bookmark-1/ })).toHaveAttribute(
+      "href",
+      "/r/(all)/overview?at=5"
+    )
+    expect(screen.getByRole("link", { name: /bookmark-2/ })).toHaveAttribute(
+      "href",
+      "/r/(all)/overview?at=12"
+    )
+  })
+})
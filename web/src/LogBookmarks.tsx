@@ -0,0 +1,129 @@
+import moment from "moment"
+import React, { useRef, useState } from "react"
+import { Link } from "react-router-dom"
+import styled from "styled-components"
+import { AnalyticsAction, AnalyticsType, incr } from "./analytics"
+import { annotations } from "./annotations"
+import { ReactComponent as BookmarkIcon } from "./assets/svg/link.svg"
+import FloatDialog from "./FloatDialog"
+import { GlobalNavRoot, MenuButton, MenuButtonLabeled } from "./GlobalNav"
+import { usePathBuilder } from "./PathBuilder"
+import { AnimDuration, Color, FontSize, SizeUnit } from "./style-helpers"
+
+// The UISession annotation that the server stores log bookmarks in.
+// Keep in sync with webview.AnnotationLogBookmarks.
+export const LogBookmarksAnnotation = "tilt.dev/log-bookmarks"
+
+export type LogBookmark = {
+  name: string
+  time: string
+
+  // The position in the log stream when the bookmark was added.
+  checkpoint: number
+}
+
+export function logBookmarks(view: Proto.webviewView): LogBookmark[] {
+  let value = annotations(view?.uiSession ?? {})[LogBookmarksAnnotation]
+  if (!value) {
+    return []
+  }
+
+  try {
+    let bookmarks = JSON.parse(value)
+    return Array.isArray(bookmarks) ? bookmarks : []
+  } catch (e) {
+    return []
+  }
+}
+
+const BookmarkList = styled.ul`
+  list-style: none;
+  margin: 0;
+  padding: 0;
+`
+
+const BookmarkLink = styled(Link)`
+  display: flex;
+  justify-content: space-between;
+  gap: ${SizeUnit(0.5)};
+  padding: ${SizeUnit(0.25)} 0;
+  color: ${Color.gray70};
+  text-decoration: none;
+  transition: color ${AnimDuration.default} ease;
+
+  &:hover {
+    color: ${Color.blue};
+  }
+`
+
+const BookmarkTime = styled.span`
+  color: ${Color.gray50};
+  font-size: ${FontSize.smallest};
+  white-space: nowrap;
+`
+
+type LogBookmarksMenuProps = {
+  view: Proto.webviewView
+}
+
+// Lists the log bookmarks (added by pressing `b` in the terminal or via
+// /api/bookmarks), and links each one to its place in the log.
+export function LogBookmarksMenu(props: LogBookmarksMenuProps) {
+  const button = useRef<HTMLButtonElement | null>(null)
+  const [open, setOpen] = useState(false)
+  const pb = usePathBuilder()
+
+  let bookmarks = logBookmarks(props.view)
+  if (bookmarks.length === 0) {
+    return null
+  }
+
+  function toggle() {
+    if (!open) {
+      incr("ui.web.menu", {
+        type: AnalyticsType.Bookmarks,
+        action: AnalyticsAction.Click,
+      })
+    }
+    setOpen(!open)
+  }
+
+  let items = bookmarks.map((b, i) => (
+    <li key={`${b.checkpoint}-${i}`}>
+      <BookmarkLink
+        to={pb.encpath`/r/(all)/overview` + `?at=${b.checkpoint}`}
+        onClick={() => setOpen(false)}
+      >
+        <span>{b.name}</span>
+        <BookmarkTime>{moment(b.time).format("lll")}</BookmarkTime>
+      </BookmarkLink>
+    </li>
+  ))
+
+  return (
+    <GlobalNavRoot role="menu" aria-label="Log bookmarks menu">
+      <MenuButtonLabeled label="Bookmarks">
+        <MenuButton
+          ref={button}
+          onClick={toggle}
+          data-open={open}
+          aria-expanded={open}
+          aria-label="Log bookmarks"
+          aria-haspopup="true"
+          role="menuitem"
+        >
+          <BookmarkIcon width="24" height="24" />
+        </MenuButton>
+      </MenuButtonLabeled>
+      <FloatDialog
+        id="log-bookmarks"
+        title="Log Bookmarks"
+        open={open}
+        anchorEl={button.current}
+        onClose={() => setOpen(false)}
+      >
+        <BookmarkList aria-label="Log bookmarks">{items}</BookmarkList>
+      </FloatDialog>
+    </GlobalNavRoot>
+  )
+}
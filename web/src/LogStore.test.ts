@@ -573,6 +573,47 @@ describe("LogStore", () => {
     )
   })
 
+  it("finds the line nearest a server checkpoint", () => {
+    let logs = new LogStore()
+    logs.append({
+      spans: {
+        "build:1": { manifestName: "fe" },
+        "build:2": { manifestName: "be" },
+        "build:3": { manifestName: "db" },
+        "": {},
+      },
+      segments: [
+        newManifestSegment("build:1", "build 1\n"),
+        newManifestSegment("build:2", "build 2\n"),
+        newManifestSegment("build:2", "build 3\n"),
+        newManifestSegment("build:3", "build 4\n"),
+        newGlobalSegment("global line 1\n"),
+      ],
+      fromCheckpoint: 0,
+      toCheckpoint: 5,
+    })
+
+    // Checkpoints still refer to the segments the server sent after we
+    // drop some of them.
+    logs.removeSpans(["build:2"])
+
+    let indexOf = (text: string) =>
+      logs.allLog().find((line) => line.text === text)?.storedLineIndex
+    expect(logs.storedLineIndexNearCheckpoint(1, "", [])).toEqual(
+      indexOf("build 4")
+    )
+    expect(logs.storedLineIndexNearCheckpoint(4, "", [])).toEqual(
+      indexOf("global line 1")
+    )
+    expect(logs.storedLineIndexNearCheckpoint(1, "fe", [])).toEqual(
+      indexOf("build 1")
+    )
+    expect(logs.storedLineIndexNearCheckpoint(0, "(starred)", ["db"])).toEqual(
+      indexOf("build 4")
+    )
+    expect(logs.storedLineIndexNearCheckpoint(0, "be", [])).toEqual(-1)
+  })
+
   it("handles starred resource logs", () => {
     let logs = new LogStore()
     logs.append({
@@ -6,7 +6,7 @@
 
 import React, { useContext } from "react"
 import { isBuildSpanId } from "./logs"
-import { LogLevel, LogLine, LogPatchSet } from "./types"
+import { LogLevel, LogLine, LogPatchSet, ResourceName } from "./types"
 
 // Firestore doesn't properly handle maps with keys equal to the empty string, so
 // we normalize all empty span ids to '_' client-side.
@@ -101,6 +101,10 @@ class LogStore implements LogAlertIndex {
   // A map of segment indices to the line indices that they rendered.
   segmentToLine: number[]
 
+  // A map of segment indices to their checkpoints on the server, which
+  // still point at the same segment after the log is truncated.
+  segmentToServerCheckpoint: number[]
+
   // As segments are appended, we fold them into our internal line-by-line model
   // for rendering.
   lines: StoredLine[]
@@ -118,6 +122,7 @@ class LogStore implements LogAlertIndex {
     this.spans = {}
     this.segments = []
     this.segmentToLine = []
+    this.segmentToServerCheckpoint = []
     this.lines = []
     this.checkpoint = 0
     this.lineCache = {}
@@ -190,6 +195,7 @@ class LogStore implements LogAlertIndex {
       let deleteCount = this.checkpoint - fromCheckpoint
       newSegments = newSegments.slice(deleteCount)
     }
+    let firstServerCheckpoint = Math.max(fromCheckpoint, this.checkpoint)
 
     if (toCheckpoint > this.checkpoint) {
       this.checkpoint = toCheckpoint
@@ -209,7 +215,9 @@ class LogStore implements LogAlertIndex {
       }
     }
 
-    newSegments.forEach((segment) => this.addSegment(segment))
+    newSegments.forEach((segment, i) =>
+      this.addSegment(segment, firstServerCheckpoint + i)
+    )
 
     this.invokeUpdateCallbacks({
       action: LogUpdateAction.append,
@@ -241,10 +249,14 @@ class LogStore implements LogAlertIndex {
     })
   }
 
-  private addSegment(newSegment: Proto.webviewLogSegment) {
+  private addSegment(
+    newSegment: Proto.webviewLogSegment,
+    serverCheckpoint: number
+  ) {
     // workaround firestore bug. see comments on defaultSpanId.
     newSegment.spanId = newSegment.spanId || defaultSpanId
     this.segments.push(newSegment)
+    this.segmentToServerCheckpoint.push(serverCheckpoint)
     this.logLength += newSegment.text?.length || 0
 
     let candidate = new StoredLine(newSegment)
@@ -326,14 +338,16 @@ class LogStore implements LogAlertIndex {
     }
 
     const currentSegments = this.segments
+    const currentServerCheckpoints = this.segmentToServerCheckpoint
     this.segments = []
-    for (const segment of currentSegments) {
+    this.segmentToServerCheckpoint = []
+    currentSegments.forEach((segment, i) => {
       const spanId = segment.spanId
       if (spanId && !spansToDelete.has(spanId)) {
         // re-add any non-deleted segments
-        this.addSegment(segment)
+        this.addSegment(segment, currentServerCheckpoints[i])
       }
-    }
+    })
 
     this.invokeUpdateCallbacks({
       action: LogUpdateAction.truncate,
@@ -514,6 +528,10 @@ class LogStore implements LogAlertIndex {
   }
 
   starredLogPatchSet(stars: string[], checkpoint: number): LogPatchSet {
+    return this.logHelper(this.spansForStars(stars), checkpoint)
+  }
+
+  private spansForStars(stars: string[]): { [key: string]: LogSpan } {
     let result: { [key: string]: LogSpan } = {}
     for (let spanId in this.spans) {
       let span = this.spans[spanId]
@@ -521,7 +539,40 @@ class LogStore implements LogAlertIndex {
         result[spanId] = span
       }
     }
-    return this.logHelper(result, checkpoint)
+    return result
+  }
+
+  // Finds the line nearest to a checkpoint on the server (e.g., from a log
+  // bookmark) in the log of a manifest, the starred manifests, or all
+  // manifests if mn is empty.
+  //
+  // Returns the stored line index of the first line at or after the
+  // checkpoint, or of the last line before it if there isn't one, or -1 if
+  // the log is empty.
+  storedLineIndexNearCheckpoint(
+    checkpoint: number,
+    mn: string,
+    stars: string[]
+  ): number {
+    let spans = !mn
+      ? this.spans
+      : mn === ResourceName.starred
+      ? this.spansForStars(stars)
+      : this.spansForManifest(mn)
+
+    let lineBefore = -1
+    for (let i = 0; i < this.segments.length; i++) {
+      let lineIndex = this.segmentToLine[i]
+      let spanId = this.segments[i].spanId || defaultSpanId
+      if (lineIndex === -1 || !spans[spanId]) {
+        continue
+      }
+      if (this.segmentToServerCheckpoint[i] >= checkpoint) {
+        return lineIndex
+      }
+      lineBefore = lineIndex
+    }
+    return lineBefore
   }
 
   // Return all the logs for the given options.
@@ -627,6 +678,9 @@ class LogStore implements LogAlertIndex {
           level: storedLine.level,
           manifestName: span.manifestName,
           buildEvent: storedLine.fields?.buildEvent,
+          buildStage: storedLine.fields?.buildStage,
+          buildStep: storedLine.fields?.buildStep,
+          time: storedLine.time,
           spanId: spanId,
           storedLineIndex: i,
         }
@@ -690,6 +744,7 @@ class LogStore implements LogAlertIndex {
     // Lastly, go through all the segments, and truncate the manifests
     // where we said we would.
     let newSegments = []
+    let newServerCheckpoints = []
     let trimmedSegmentCount = 0
     for (let i = this.segments.length - 1; i >= 0; i--) {
       let segment = this.segments[i]
@@ -703,9 +758,11 @@ class LogStore implements LogAlertIndex {
       }
 
       newSegments.push(segment)
+      newServerCheckpoints.push(this.segmentToServerCheckpoint[i])
     }
 
     newSegments.reverse()
+    newServerCheckpoints.reverse()
 
     // Reset the state of the logstore.
     this.logLength = 0
@@ -720,9 +777,10 @@ class LogStore implements LogAlertIndex {
     }
 
     this.segments = []
-    for (const segment of newSegments) {
-      this.addSegment(segment)
-    }
+    this.segmentToServerCheckpoint = []
+    newSegments.forEach((segment, i) =>
+      this.addSegment(segment, newServerCheckpoints[i])
+    )
 
     this.invokeUpdateCallbacks({
       action: LogUpdateAction.truncate,
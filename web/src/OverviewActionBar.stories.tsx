@@ -69,6 +69,26 @@ export const FullBar = () => {
   )
 }
 
+export const WithContainers = () => {
+  let filterSet = useFilterSet()
+  let res = oneResource({ name: "my-deadbeef", endpoints: 1 })
+  res.status!.k8sResourceInfo!.containers = [
+    {
+      name: "app",
+      image: "gcr.io/my-deadbeef:tilt-1a2b3c4d",
+      ready: true,
+      state: { running: {} },
+    },
+    {
+      name: "proxy",
+      image: "envoyproxy/envoy:v1.27",
+      restarts: 2,
+      state: { waiting: { reason: "CrashLoopBackOff" } },
+    },
+  ]
+  return <OverviewActionBar resource={res} filterSet={filterSet} />
+}
+
 export const EmptyBar = () => {
   let filterSet = useFilterSet()
   let res = oneResource({ isBuilding: true, endpoints: 0 })
@@ -56,10 +56,16 @@ describe("OverviewActionBar", () => {
   it("renders the top row with endpoints", () => {
     customRender(<FullBar />, { history })
 
-    expect(
-      screen.getByLabelText(/links and custom buttons/i)
-    ).toBeInTheDocument()
-    expect(screen.getAllByRole("link")).toHaveLength(2)
+    const topRow = screen.getByLabelText(/links and custom buttons/i)
+    expect(topRow).toBeInTheDocument()
+    expect(within(topRow).getAllByRole("link")).toHaveLength(2)
+  })
+
+  it("renders a link to export the resource", () => {
+    customRender(<FullBar />, { history })
+
+    const link = screen.getByRole("link", { name: /export/i })
+    expect(link).toHaveAttribute("href", "/api/export?resource=my-deadbeef")
   })
 
   it("renders the top row with pod ID", () => {
@@ -153,6 +159,61 @@ describe("OverviewActionBar", () => {
     })
   })
 
+  describe("containers", () => {
+    function renderWithContainers(disabled?: boolean) {
+      const resource = oneResource({ name: "vigoda", disabled })
+      resource.status!.k8sResourceInfo!.containers = [
+        {
+          name: "app",
+          image: "gcr.io/vigoda:tilt-abc123",
+          ready: true,
+          restarts: 0,
+          state: { running: { startedAt: new Date().toISOString() } },
+        },
+        {
+          name: "proxy",
+          image: "envoyproxy/envoy:v1.27",
+          ready: false,
+          restarts: 3,
+          state: { waiting: { reason: "CrashLoopBackOff" } },
+        },
+      ]
+      customRender(
+        <OverviewActionBar
+          resource={resource}
+          filterSet={DEFAULT_FILTER_SET}
+        />,
+        { history }
+      )
+    }
+
+    it("lists each container with its status, restarts, and image", () => {
+      renderWithContainers()
+
+      const app = screen.getByLabelText("Container app")
+      expect(app).toHaveTextContent("Ready")
+      expect(app).toHaveTextContent("gcr.io/vigoda:tilt-abc123")
+      expect(app).not.toHaveTextContent("restart")
+
+      const proxy = screen.getByLabelText("Container proxy")
+      expect(proxy).toHaveTextContent("CrashLoopBackOff")
+      expect(proxy).toHaveTextContent("3 restarts")
+      expect(proxy).toHaveTextContent("envoyproxy/envoy:v1.27")
+    })
+
+    it("does NOT list containers of a disabled resource", () => {
+      renderWithContainers(true)
+
+      expect(screen.queryByLabelText("Containers")).toBeNull()
+    })
+
+    it("does NOT list containers when there are none", () => {
+      customRender(<FullBar />, { history })
+
+      expect(screen.queryByLabelText("Containers")).toBeNull()
+    })
+  })
+
   describe("custom buttons", () => {
     const customButtons = [
       oneUIButton({ componentID: "vigoda", disabled: true }),
@@ -17,6 +17,7 @@ import { ReactComponent as CloseSvg } from "./assets/svg/close.svg"
 import { ReactComponent as CopySvg } from "./assets/svg/copy.svg"
 import { ReactComponent as FilterSvg } from "./assets/svg/filter.svg"
 import { ReactComponent as LinkSvg } from "./assets/svg/link.svg"
+import ContainerList from "./ContainerList"
 import {
   InstrumentedButton,
   InstrumentedTextField,
@@ -845,6 +846,7 @@ export default function OverviewActionBar(props: OverviewActionBarProps) {
         <ResourceNameTitleRow>Resource: {name}</ResourceNameTitleRow>
       )}
       {topRow}
+      {!isDisabled && <ContainerList resource={resource} />}
       <ActionBarBottomRow>{bottomRow}</ActionBarBottomRow>
     </ActionBarRoot>
   )
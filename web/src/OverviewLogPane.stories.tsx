@@ -9,7 +9,7 @@ import {
 import LogStore, { LogStoreProvider } from "./LogStore"
 import OverviewLogPane from "./OverviewLogPane"
 import { StarredResourceMemoryProvider } from "./StarredResourcesContext"
-import { appendLines } from "./testlogs"
+import { appendLines, appendLinesForManifestAndSpan } from "./testlogs"
 import { LogLevel } from "./types"
 
 export default {
@@ -251,6 +251,40 @@ ProgressLines.argTypes = {
   layer4: { control: { type: "number", min: 0, max: 100 } },
 }
 
+export const MultistageBuildFailure = () => {
+  let logStore = new LogStore()
+  let builder = { buildStage: "builder" }
+  let final = { buildStage: "stage-1" }
+  appendLinesForManifestAndSpan(
+    logStore,
+    "fe",
+    "build:1",
+    { text: "── stage builder ──\n", fields: builder },
+    {
+      text: "[builder 1/2] FROM docker.io/library/busybox\n",
+      fields: { ...builder, buildStep: "1/2" },
+    },
+    {
+      text: "[builder 2/2] WORKDIR /src [cached]\n",
+      fields: { ...builder, buildStep: "2/2" },
+    },
+    { text: "── stage stage-1 ──\n", fields: final },
+    {
+      text: "[stage-1 2/2] RUN cat hi.txt && exit 1\n",
+      fields: { ...final, buildStep: "2/2" },
+    },
+    {
+      text: "ERROR IN: [stage-1 2/2] RUN cat hi.txt && exit 1\n",
+      fields: { ...final, buildStep: "2/2" },
+    }
+  )
+  return (
+    <LogStoreProvider value={logStore}>
+      <OverviewLogPane manifestName="fe" filterSet={defaultFilter} />
+    </LogStoreProvider>
+  )
+}
+
 type ForeverLogProps = {
   // Starting lines in the component.
   startCount: number
@@ -17,6 +17,7 @@ import OverviewLogPane, {
 import {
   BuildLogAndRunLog,
   ManyLines,
+  MultistageBuildFailure,
   StyledLines,
   ThreeLines,
   ThreeLinesAllLog,
@@ -174,6 +175,83 @@ describe("OverviewLogPane", () => {
    * which is not possible to do with React Testing Library.
    */
 
+  describe("build sections", () => {
+    it("expands the failing stage and collapses the rest", () => {
+      const { container } = customRender(<MultistageBuildFailure />)
+      let headers = container.querySelectorAll(".is-buildSectionHeader")
+      expect(headers).toHaveLength(2)
+      expect(headers[0]).toHaveTextContent("stage builder")
+      expect(headers[0]).toHaveTextContent("cached")
+      expect(headers[1]).toHaveTextContent("failed")
+
+      let collapsed = container.querySelectorAll(".is-buildSectionCollapsed")
+      expect(collapsed).toHaveLength(2)
+      expect(collapsed[0]).toHaveTextContent("[builder 1/2]")
+      expect(collapsed[1]).toHaveTextContent("[builder 2/2]")
+    })
+
+    it("toggles a stage", () => {
+      const { container } = customRender(<MultistageBuildFailure />)
+      screen.getByRole("button", { name: "Expand stage builder" }).click()
+      expect(
+        container.querySelectorAll(".is-buildSectionCollapsed")
+      ).toHaveLength(0)
+
+      screen.getByRole("button", { name: "Collapse stage stage-1" }).click()
+      expect(
+        container.querySelectorAll(".is-buildSectionCollapsed")
+      ).toHaveLength(2)
+    })
+  })
+
+  describe("log bookmarks", () => {
+    let defaultFilter = {
+      source: FilterSource.all,
+      level: FilterLevel.all,
+      term: EMPTY_FILTER_TERM,
+    }
+
+    function renderAt(logStore: LogStore, manifestName: string, at: number) {
+      return render(
+        <LogStoreProvider value={logStore}>
+          <OverviewLogPane
+            manifestName={manifestName}
+            filterSet={defaultFilter}
+          />
+        </LogStoreProvider>,
+        {
+          wrapper: ({ children }) => (
+            <MemoryRouter initialEntries={[`/r/(all)/overview?at=${at}`]}>
+              <SyncRafProvider>{children}</SyncRafProvider>
+            </MemoryRouter>
+          ),
+        }
+      )
+    }
+
+    it("highlights the line at the checkpoint", () => {
+      let logStore = new LogStore()
+      appendLines(logStore, "fe", "fe line 1\n", "fe line 2\n")
+      appendLines(logStore, "be", "be line 1\n", "be line 2\n")
+
+      const { container } = renderAt(logStore, "", 2)
+      let highlighted = container.querySelectorAll(".LogLine.is-highlighted")
+      expect(highlighted).toHaveLength(1)
+      expect(highlighted[0]).toHaveTextContent("be line 1")
+    })
+
+    it("highlights the nearest line of the resource", () => {
+      let logStore = new LogStore()
+      appendLines(logStore, "fe", "fe line 1\n", "fe line 2\n")
+      appendLines(logStore, "be", "be line 1\n", "be line 2\n")
+
+      const { container } = renderAt(logStore, "fe", 2)
+      let highlighted = container.querySelectorAll(".LogLine.is-highlighted")
+      expect(highlighted).toHaveLength(1)
+      expect(highlighted[0]).toHaveTextContent("fe line 2")
+    })
+  })
+
   describe("log rendering", () => {
     function getLogElements(container: HTMLElement) {
       return container.querySelectorAll(".LogLine")
@@ -2,6 +2,11 @@ import { History } from "history"
 import React, { Component } from "react"
 import { useHistory, useLocation } from "react-router"
 import styled, { keyframes } from "styled-components"
+import {
+  BuildSection,
+  BuildSectionIndex,
+  buildSectionSummary,
+} from "./buildsections"
 import {
   FilterLevel,
   FilterSet,
@@ -34,6 +39,7 @@ type OverviewLogComponentProps = {
   filterSet: FilterSet
   history: History
   scrollToStoredLineIndex: number | null
+  scrollToCheckpoint: number | null
   starredResources: string[]
 }
 
@@ -189,6 +195,11 @@ export const renderWindow = 250
 export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
   autoscroll: boolean = true
   needsScrollToLine: boolean = false
+  needsScrollToCheckpoint: boolean = false
+
+  // The stored line index of the line nearest to scrollToCheckpoint, which
+  // we highlight, or -1 if there isn't one.
+  private highlightedStoredLineIndex: number = -1
 
   // The element containing all the log lines.
   rootRef: React.RefObject<any> = React.createRef()
@@ -219,6 +230,9 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
   // N lines before the error. So we keep track of the last N lines for each span.
   private prologuesBySpanId: { [key: string]: LogLine[] } = {}
 
+  // The collapsible sections of multi-stage Docker builds.
+  private buildSections: BuildSectionIndex = new BuildSectionIndex()
+
   constructor(props: OverviewLogComponentProps) {
     super(props)
 
@@ -256,29 +270,36 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
       !filterSetsEqual(prevProps.filterSet, this.props.filterSet)
     ) {
       this.resetRender()
-
-      if (typeof this.props.scrollToStoredLineIndex === "number") {
-        this.needsScrollToLine = true
-      }
-      this.autoscroll = !this.needsScrollToLine
-
+      this.initScrollTarget()
       this.readLogsFromLogStore()
     } else if (prevProps.logStore !== this.props.logStore) {
       this.resetRender()
       this.readLogsFromLogStore()
+    } else if (prevProps.scrollToCheckpoint !== this.props.scrollToCheckpoint) {
+      this.setHighlightedLine(-1)
+      this.initScrollTarget()
+      this.maybeScrollToCheckpoint()
     }
   }
 
+  // Decides whether we need to scroll to a line (from a permalink) or a
+  // checkpoint (from a log bookmark) instead of autoscrolling.
+  private initScrollTarget() {
+    this.needsScrollToLine =
+      typeof this.props.scrollToStoredLineIndex === "number"
+    this.needsScrollToCheckpoint =
+      !this.needsScrollToLine &&
+      typeof this.props.scrollToCheckpoint === "number"
+    this.autoscroll = !this.needsScrollToLine && !this.needsScrollToCheckpoint
+  }
+
   componentDidMount() {
     let rootEl = this.rootRef.current
     if (!rootEl) {
       return
     }
 
-    if (typeof this.props.scrollToStoredLineIndex == "number") {
-      this.needsScrollToLine = true
-    }
-    this.autoscroll = !this.needsScrollToLine
+    this.initScrollTarget()
 
     rootEl.addEventListener("scroll", this.onScroll, {
       passive: true,
@@ -351,7 +372,7 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
       return
     }
 
-    if (this.needsScrollToLine) {
+    if (this.needsScrollToLine || this.needsScrollToCheckpoint) {
       return
     }
 
@@ -403,6 +424,8 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
 
     this.lineHashList = new LineHashList()
     this.prologuesBySpanId = {}
+    this.buildSections = new BuildSectionIndex()
+    this.highlightedStoredLineIndex = -1
     this.logCheckpoint = 0
     this.scrollTop = -1
 
@@ -500,6 +523,8 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
     let shouldDisplayPrologues = this.props.filterSet.level !== FilterLevel.all
 
     patch.lines.forEach((line) => {
+      this.buildSections.ingest(line)
+
       let matches = this.matchesFilter(line)
       if (matches) {
         if (shouldDisplayPrologues) {
@@ -581,10 +606,13 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
       return
     }
 
+    this.updateBuildSections()
+
     if (
       !this.shouldRenderForwardBuffer() &&
       !this.shouldRenderBackwardBuffer()
     ) {
+      this.maybeScrollToCheckpoint()
       return
     }
 
@@ -621,6 +649,8 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
       }
     }
 
+    this.maybeScrollToCheckpoint()
+
     if (this.shouldRenderForwardBuffer() || this.shouldRenderBackwardBuffer()) {
       this.renderBufferRafId = this.props.raf.requestAnimationFrame(
         this.renderBuffer
@@ -628,6 +658,69 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
     }
   }
 
+  // Scrolls to and highlights the line nearest to scrollToCheckpoint, once
+  // it's rendered. If the pane filters out that line, falls back to the next
+  // rendered line once there's nothing left to render.
+  private maybeScrollToCheckpoint() {
+    if (!this.needsScrollToCheckpoint) {
+      return
+    }
+
+    let storedLineIndex = this.props.logStore.storedLineIndexNearCheckpoint(
+      this.props.scrollToCheckpoint as number,
+      this.props.manifestName,
+      this.props.starredResources
+    )
+    if (storedLineIndex === -1) {
+      // We haven't received the logs yet.
+      return
+    }
+
+    let el = this.lineHashList.lookupByStoredLineIndex(storedLineIndex)?.el
+    let doneRendering =
+      !this.shouldRenderForwardBuffer() && !this.shouldRenderBackwardBuffer()
+    if (!el && doneRendering) {
+      el = this.firstRenderedLineElAtOrAfter(storedLineIndex)
+    }
+    if (!el) {
+      return
+    }
+
+    this.setHighlightedLine(Number(el.getAttribute("data-sl-index")))
+    el.scrollIntoView?.({ block: "center" })
+    this.needsScrollToCheckpoint = false
+  }
+
+  private firstRenderedLineElAtOrAfter(
+    storedLineIndex: number
+  ): Element | null {
+    let root = this.rootRef.current
+    let cursor = this.cursorRef.current
+    let lastEl = null
+    for (let i = 0; i < root.children.length; i++) {
+      let child = root.children[i]
+      if (child == cursor) {
+        break
+      }
+      lastEl = child
+      if (Number(child.getAttribute("data-sl-index")) >= storedLineIndex) {
+        return child
+      }
+    }
+    return lastEl
+  }
+
+  private setHighlightedLine(storedLineIndex: number) {
+    let oldEl = this.lineHashList.lookupByStoredLineIndex(
+      this.highlightedStoredLineIndex
+    )?.el
+    oldEl?.classList.remove("is-highlighted")
+
+    this.highlightedStoredLineIndex = storedLineIndex
+    let newEl = this.lineHashList.lookupByStoredLineIndex(storedLineIndex)?.el
+    newEl?.classList.add("is-highlighted")
+  }
+
   // Creates a DOM element with a permalink to an alert.
   newAlertNavEl(line: LogLine) {
     let div = document.createElement("button")
@@ -644,6 +737,85 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
     return div
   }
 
+  // Build sections only collapse when every line is shown. With a level or
+  // term filter, the lines that match should never be hidden.
+  buildSectionsEnabled(): boolean {
+    let { level, term } = this.props.filterSet
+    return (
+      level === FilterLevel.all && (!term || term.state !== TermState.Parsed)
+    )
+  }
+
+  // Tags the line with its build section, and adds a toggle and summary to
+  // the section header.
+  decorateBuildSectionLine(line: LogLine, lineEl: Element) {
+    let section = this.buildSections.sectionForLine(line)
+    if (!section || !this.buildSectionsEnabled()) {
+      return
+    }
+
+    lineEl.setAttribute("data-build-section", String(section.id))
+    if (section.headerStoredLineIndex !== line.storedLineIndex) {
+      lineEl.classList.toggle("is-buildSectionCollapsed", section.collapsed)
+      return
+    }
+
+    let sectionId = section.id
+    let toggle = document.createElement("button")
+    toggle.className = "LogLine-buildSectionToggle"
+    toggle.onclick = () => {
+      this.buildSections.toggle(sectionId)
+      this.updateBuildSections()
+    }
+    lineEl.insertBefore(toggle, lineEl.querySelector(".LogLine-content"))
+
+    let summary = document.createElement("span")
+    summary.className = "LogLine-buildSectionSummary"
+    lineEl.appendChild(summary)
+
+    lineEl.classList.add("is-buildSectionHeader")
+    this.updateBuildSectionHeader(lineEl, section)
+  }
+
+  updateBuildSectionHeader(headerEl: Element, section: BuildSection) {
+    let toggle = headerEl.querySelector(".LogLine-buildSectionToggle")
+    if (toggle) {
+      toggle.innerHTML = section.collapsed ? "▸" : "▾"
+      toggle.setAttribute("aria-expanded", String(!section.collapsed))
+      toggle.setAttribute(
+        "aria-label",
+        `${section.collapsed ? "Expand" : "Collapse"} stage ${section.stage}`
+      )
+    }
+
+    let summary = headerEl.querySelector(".LogLine-buildSectionSummary")
+    if (summary) {
+      summary.textContent = buildSectionSummary(section)
+      summary.classList.toggle("is-failed", section.failed)
+    }
+  }
+
+  // Re-renders the headers and visibility of sections that changed since the
+  // last update, e.g., because more lines came in or a stage failed.
+  updateBuildSections() {
+    let root = this.rootRef.current
+    let sections = this.buildSections.takeDirty()
+    if (!root || !this.buildSectionsEnabled()) {
+      return
+    }
+
+    sections.forEach((section) => {
+      let els = root.querySelectorAll(`[data-build-section="${section.id}"]`)
+      els.forEach((el: Element) => {
+        if (el.classList.contains("is-buildSectionHeader")) {
+          this.updateBuildSectionHeader(el, section)
+        } else {
+          el.classList.toggle("is-buildSectionCollapsed", section.collapsed)
+        }
+      })
+    })
+  }
+
   // Helper function for rendering lines. Returns true if the line was
   // successfully rendered.
   //
@@ -692,10 +864,15 @@ export class OverviewLogComponent extends Component<OverviewLogComponentProps> {
       extraClasses.push("is-startOfAlert")
     }
 
+    if (line.storedLineIndex === this.highlightedStoredLineIndex) {
+      extraClasses.push("is-highlighted")
+    }
+
     let lineEl = newLineEl(entry.line, showManifestName, extraClasses)
     if (isStartOfAlert) {
       lineEl.appendChild(this.newAlertNavEl(entry.line))
     }
+    this.decorateBuildSectionLine(entry.line, lineEl)
 
     let root = this.rootRef.current
     let existingLineEl = entry.el
@@ -753,6 +930,8 @@ type OverviewLogPaneProps = {
 export default function OverviewLogPane(props: OverviewLogPaneProps) {
   let history = useHistory()
   let location = useLocation() as any
+  let at = new URLSearchParams(location?.search).get("at")
+  let scrollToCheckpoint = at && !isNaN(Number(at)) ? Number(at) : null
   let pathBuilder = usePathBuilder()
   let logStore = useLogStore()
   let raf = useRaf()
@@ -766,6 +945,7 @@ export default function OverviewLogPane(props: OverviewLogPaneProps) {
       filterSet={props.filterSet}
       history={history}
       scrollToStoredLineIndex={location?.state?.storedLineIndex}
+      scrollToCheckpoint={scrollToCheckpoint}
       starredResources={starredContext.starredResources}
     />
   )
@@ -0,0 +1,122 @@
+import { BuildSectionIndex, buildSectionSummary } from "./buildsections"
+import { LogLine } from "./types"
+
+let storedLineIndex = 0
+
+function newLine(
+  text: string,
+  buildStage?: string,
+  buildStep?: string,
+  time?: string
+): LogLine {
+  return {
+    text,
+    buildStage,
+    buildStep,
+    time,
+    manifestName: "fe",
+    level: "INFO",
+    spanId: "build:1",
+    storedLineIndex: storedLineIndex++,
+  }
+}
+
+describe("BuildSectionIndex", () => {
+  it("groups lines by stage", () => {
+    let index = new BuildSectionIndex()
+    let header = newLine("── stage builder ──", "builder")
+    let step = newLine("[builder 1/2] FROM busybox", "builder", "1/2")
+    let other = newLine("Building Dockerfile")
+    ;[header, step, other].forEach((line) => index.ingest(line))
+
+    let section = index.sectionForLine(step)
+    expect(section?.stage).toEqual("builder")
+    expect(section?.headerStoredLineIndex).toEqual(header.storedLineIndex)
+    expect(index.sectionForLine(header)).toBe(section)
+    expect(index.sectionForLine(other)).toBeNull()
+  })
+
+  it("summarizes durations and cached steps", () => {
+    let index = new BuildSectionIndex()
+    let lines = [
+      newLine(
+        "── stage builder ──",
+        "builder",
+        undefined,
+        "2021-01-01T00:00:00Z"
+      ),
+      newLine(
+        "[builder 1/3] FROM busybox",
+        "builder",
+        "1/3",
+        "2021-01-01T00:00:00Z"
+      ),
+      newLine(
+        "[builder 2/3] WORKDIR /src [cached]",
+        "builder",
+        "2/3",
+        "2021-01-01T00:00:01Z"
+      ),
+      newLine(
+        "[builder 3/3] RUN make [done: 2s]",
+        "builder",
+        "3/3",
+        "2021-01-01T00:00:03Z"
+      ),
+    ]
+    lines.forEach((line) => index.ingest(line))
+
+    let section = index.sectionForLine(lines[0])!
+    expect(buildSectionSummary(section)).toEqual("3.0s • 1/3 cached")
+  })
+
+  it("marks a fully cached stage", () => {
+    let index = new BuildSectionIndex()
+    let lines = [
+      newLine("── stage builder ──", "builder"),
+      newLine("[builder 2/2] WORKDIR /src [cached]", "builder", "2/2"),
+    ]
+    lines.forEach((line) => index.ingest(line))
+
+    expect(buildSectionSummary(index.sectionForLine(lines[0])!)).toEqual(
+      "cached"
+    )
+  })
+
+  it("expands the failing stage and collapses the rest", () => {
+    let index = new BuildSectionIndex()
+    let builderHeader = newLine("── stage builder ──", "builder")
+    let builderStep = newLine("[builder 1/1] RUN make", "builder", "1/1")
+    let finalHeader = newLine("── stage stage-1 ──", "stage-1")
+    let finalStep = newLine("[stage-1 2/2] RUN exit 1", "stage-1", "2/2")
+    let error = newLine("ERROR IN: [stage-1 2/2] RUN exit 1", "stage-1", "2/2")
+    ;[builderHeader, builderStep, finalHeader, finalStep].forEach((line) =>
+      index.ingest(line)
+    )
+    expect(index.isHidden(builderStep)).toEqual(false)
+    index.takeDirty()
+
+    index.ingest(error)
+    expect(index.isHidden(builderStep)).toEqual(true)
+    expect(index.isHidden(builderHeader)).toEqual(false)
+    expect(index.isHidden(finalStep)).toEqual(false)
+    expect(index.sectionForLine(error)?.failed).toEqual(true)
+    expect(index.takeDirty().map((s) => s.stage)).toEqual([
+      "builder",
+      "stage-1",
+    ])
+  })
+
+  it("leaves sections that the user toggled alone", () => {
+    let index = new BuildSectionIndex()
+    let builderStep = newLine("[builder 1/1] RUN make", "builder", "1/1")
+    let error = newLine("ERROR IN: [stage-1 2/2] RUN exit 1", "stage-1", "2/2")
+    index.ingest(builderStep)
+
+    let builder = index.sectionForLine(builderStep)!
+    index.toggle(builder.id)
+    index.toggle(builder.id)
+    index.ingest(error)
+    expect(index.isHidden(builderStep)).toEqual(false)
+  })
+})
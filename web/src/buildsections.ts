@@ -0,0 +1,192 @@
+// Splits build logs into collapsible sections, one for each stage of a
+// multi-stage Dockerfile.
+//
+// The server tags each line of BuildKit output with the stage and step it
+// came from (the buildStage and buildStep log fields), and logs a header line
+// with only a buildStage before the first output of each stage.
+//
+// BuildKit runs independent stages in parallel, so the lines of a section
+// aren't necessarily contiguous.
+
+import { formatBuildDuration, timeDiff } from "./time"
+import { LogLine } from "./types"
+
+const errorPrefix = "ERROR IN:"
+const cachedSuffix = " [cached]"
+
+export type BuildSection = {
+  // A unique id for the section, for tagging its DOM elements.
+  id: number
+
+  spanId: string
+  stage: string
+
+  // The stored line index of the section header, or -1 if we haven't seen it.
+  headerStoredLineIndex: number
+
+  startTime: string
+  endTime: string
+
+  // Whether each step of the stage was cached.
+  steps: { [step: string]: boolean }
+
+  failed: boolean
+  collapsed: boolean
+
+  // Once the user expands or collapses a section, we stop doing it for them.
+  userToggled: boolean
+}
+
+function sectionKey(spanId: string, stage: string): string {
+  return `${spanId}\u0000${stage}`
+}
+
+// Lines from a single-stage Dockerfile don't have a stage, so they aren't
+// split into sections.
+export function isBuildSectionLine(line: LogLine): boolean {
+  return !!line.buildStage
+}
+
+export function isBuildSectionHeader(line: LogLine): boolean {
+  return !!line.buildStage && !line.buildStep
+}
+
+// A short summary of a section for its header, e.g., "1.2s • cached".
+export function buildSectionSummary(section: BuildSection): string {
+  let parts = []
+  if (section.startTime && section.endTime) {
+    parts.push(
+      formatBuildDuration(timeDiff(section.startTime, section.endTime))
+    )
+  }
+
+  let steps = Object.values(section.steps)
+  let cachedCount = steps.filter((cached) => cached).length
+  if (steps.length > 0 && cachedCount === steps.length) {
+    parts.push("cached")
+  } else if (cachedCount > 0) {
+    parts.push(`${cachedCount}/${steps.length} cached`)
+  }
+
+  if (section.failed) {
+    parts.push("failed")
+  }
+  return parts.join(" • ")
+}
+
+export class BuildSectionIndex {
+  private nextId = 1
+  private sections: { [key: string]: BuildSection } = {}
+  private sectionsById: { [id: number]: BuildSection } = {}
+  private keysBySpanId: { [spanId: string]: string[] } = {}
+  private dirty: { [id: number]: BuildSection } = {}
+
+  // Returns the section of the line, if it has one.
+  sectionForLine(line: LogLine): BuildSection | null {
+    if (!isBuildSectionLine(line)) {
+      return null
+    }
+    return this.sections[sectionKey(line.spanId, line.buildStage ?? "")] ?? null
+  }
+
+  sectionById(id: number): BuildSection | null {
+    return this.sectionsById[id] ?? null
+  }
+
+  // Updates the sections with a new (or continued) line of the log.
+  ingest(line: LogLine) {
+    if (!isBuildSectionLine(line)) {
+      return
+    }
+
+    let stage = line.buildStage ?? ""
+    let key = sectionKey(line.spanId, stage)
+    let section = this.sections[key]
+    if (!section) {
+      section = {
+        id: this.nextId++,
+        spanId: line.spanId,
+        stage: stage,
+        headerStoredLineIndex: -1,
+        startTime: "",
+        endTime: "",
+        steps: {},
+        failed: false,
+        collapsed: false,
+        userToggled: false,
+      }
+      this.sections[key] = section
+      this.sectionsById[section.id] = section
+      if (!this.keysBySpanId[line.spanId]) {
+        this.keysBySpanId[line.spanId] = []
+      }
+      this.keysBySpanId[line.spanId].push(key)
+    }
+
+    if (isBuildSectionHeader(line) && section.headerStoredLineIndex === -1) {
+      section.headerStoredLineIndex = line.storedLineIndex
+    }
+
+    if (line.time) {
+      if (!section.startTime) {
+        section.startTime = line.time
+      }
+      section.endTime = line.time
+    }
+
+    let step = line.buildStep
+    if (step && !(step in section.steps)) {
+      section.steps[step] = line.text.endsWith(cachedSuffix)
+    }
+    this.dirty[section.id] = section
+
+    if (step && !section.failed && line.text.startsWith(errorPrefix)) {
+      section.failed = true
+      this.expandOnly(line.spanId, key)
+    }
+  }
+
+  // Expands the failing section of a build and collapses the rest, except
+  // for the ones the user has already toggled.
+  private expandOnly(spanId: string, failingKey: string) {
+    for (let key of this.keysBySpanId[spanId] ?? []) {
+      let section = this.sections[key]
+      if (section.userToggled) {
+        continue
+      }
+      let collapsed = key !== failingKey
+      if (section.collapsed !== collapsed) {
+        section.collapsed = collapsed
+        this.dirty[section.id] = section
+      }
+    }
+  }
+
+  toggle(id: number) {
+    let section = this.sectionsById[id]
+    if (!section) {
+      return
+    }
+    section.collapsed = !section.collapsed
+    section.userToggled = true
+    this.dirty[section.id] = section
+  }
+
+  // Whether the line is hidden inside a collapsed section.
+  // The header of a collapsed section is still shown.
+  isHidden(line: LogLine): boolean {
+    let section = this.sectionForLine(line)
+    return (
+      !!section &&
+      section.collapsed &&
+      section.headerStoredLineIndex !== line.storedLineIndex
+    )
+  }
+
+  // Returns the sections that have changed since the last call.
+  takeDirty(): BuildSection[] {
+    let result = Object.values(this.dirty)
+    this.dirty = {}
+    return result
+  }
+}
@@ -113,6 +113,14 @@ export type LogLine = {
   buildEvent?: string
   spanId: string
 
+  // The Dockerfile stage and step (e.g., "builder" and "2/5") that a line
+  // of build output came from.
+  buildStage?: string
+  buildStep?: string
+
+  // When the line was logged, as an RFC 3339 timestamp.
+  time?: string
+
   // The index of this line in the LogStore StoredLine list.
   storedLineIndex: number
 }
@@ -552,6 +552,7 @@ declare namespace Proto {
     podRestarts?: number;
     spanID?: string;
     displayNames?: string[];
+    containers?: v1alpha1Container[];
   }
   export interface v1alpha1UIResourceCondition {
     /**
@@ -862,6 +863,86 @@ declare namespace Proto {
      */
     sources?: v1alpha1DisableSource[];
   }
+  export interface v1alpha1ContainerStateWaiting {
+    /**
+     * Reason is a (brief) reason the container is not yet running.
+     */
+    reason?: string;
+  }
+  export interface v1alpha1ContainerStateTerminated {
+    /**
+     * StartedAt is the time the container began running.
+     */
+    startedAt?: string;
+    /**
+     * FinishedAt is the time the container stopped running.
+     */
+    finishedAt?: string;
+    /**
+     * Reason is a (brief) reason the container stopped running.
+     */
+    reason?: string;
+    /**
+     * ExitCode is the exit status from the termination of the container.
+     *
+     * Any non-zero value indicates an error during termination.
+     */
+    exitCode?: number;
+  }
+  export interface v1alpha1ContainerStateRunning {
+    /**
+     * StartedAt is the time the container began running.
+     */
+    startedAt?: string;
+  }
+  export interface v1alpha1ContainerState {
+    /**
+     * Waiting provides details about a container that is not yet running.
+     */
+    waiting?: v1alpha1ContainerStateWaiting;
+    /**
+     * Running provides details about a currently executing container.
+     */
+    running?: v1alpha1ContainerStateRunning;
+    /**
+     * Terminated provides details about an exited container.
+     */
+    terminated?: v1alpha1ContainerStateTerminated;
+  }
+  export interface v1alpha1Container {
+    /**
+     * Name is the name of the container as defined in Kubernetes.
+     */
+    name?: string;
+    /**
+     * ID is the normalized container ID (the `docker://` prefix is stripped).
+     */
+    id?: string;
+    /**
+     * Ready is true if the container is passing readiness checks (or has none defined).
+     */
+    ready?: boolean;
+    /**
+     * Image is the image the container is running.
+     */
+    image?: string;
+    /**
+     * Restarts is the number of times the container has restarted.
+     *
+     * This includes restarts before the Tilt daemon was started if the container was already running.
+     */
+    restarts?: number;
+    /**
+     * State provides details about the container's current condition.
+     */
+    state?: v1alpha1ContainerState;
+    /**
+     * Ports are exposed ports as extracted from the Pod spec.
+     *
+     * This is added by Tilt for convenience when managing port forwards.
+     */
+    ports?: number[];
+  }
   export interface v1alpha1ConfigMapDisableSource {
     name?: string;
     /**